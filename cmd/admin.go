@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/masoodkamyab/gitea/models"
@@ -135,6 +136,8 @@ var (
 			cmdAuthUpdateLdapBindDn,
 			cmdAuthAddLdapSimpleAuth,
 			cmdAuthUpdateLdapSimpleAuth,
+			cmdAuthAddSAML,
+			cmdAuthUpdateSAML,
 			microcmdAuthList,
 			microcmdAuthDelete,
 		},
@@ -208,6 +211,45 @@ var (
 			Value: "",
 			Usage: "Use a custom Email URL (option for GitHub)",
 		},
+		cli.StringFlag{
+			Name:  "scopes",
+			Value: "",
+			Usage: "Space separated scopes requested in addition to openid (option for OpenID Connect)",
+		},
+		cli.StringFlag{
+			Name:  "username-claim",
+			Value: "",
+			Usage: "Claim used as username (option for OpenID Connect)",
+		},
+		cli.StringFlag{
+			Name:  "email-claim",
+			Value: "",
+			Usage: "Claim used as email address (option for OpenID Connect)",
+		},
+		cli.StringFlag{
+			Name:  "full-name-claim",
+			Value: "",
+			Usage: "Claim used as full name (option for OpenID Connect)",
+		},
+		cli.StringFlag{
+			Name:  "group-claim-name",
+			Value: "",
+			Usage: "Claim containing the groups of the user (option for OpenID Connect)",
+		},
+		cli.StringFlag{
+			Name:  "admin-group",
+			Value: "",
+			Usage: "Group whose members are administrators (option for OpenID Connect)",
+		},
+		cli.StringFlag{
+			Name:  "group-team-map",
+			Value: "",
+			Usage: "JSON mapping of groups to organization teams, e.g. '{\"developers\": {\"myorg\": [\"coders\"]}}' (option for OpenID Connect)",
+		},
+		cli.BoolFlag{
+			Name:  "group-team-map-removal",
+			Usage: "Remove users from mapped teams when they are not in the group anymore (option for OpenID Connect)",
+		},
 	}
 
 	microcmdAuthUpdateOauth = cli.Command{
@@ -414,7 +456,7 @@ func runRegenerateKeys(c *cli.Context) error {
 	return models.RewriteAllPublicKeys()
 }
 
-func parseOAuth2Config(c *cli.Context) *models.OAuth2Config {
+func parseOAuth2Config(c *cli.Context) (*models.OAuth2Config, error) {
 	var customURLMapping *oauth2.CustomURLMapping
 	if c.IsSet("use-custom-urls") {
 		customURLMapping = &oauth2.CustomURLMapping{
@@ -426,16 +468,38 @@ func parseOAuth2Config(c *cli.Context) *models.OAuth2Config {
 	} else {
 		customURLMapping = nil
 	}
+	var claimMapping *oauth2.ClaimMapping
+	if c.IsSet("username-claim") || c.IsSet("email-claim") || c.IsSet("full-name-claim") {
+		claimMapping = &oauth2.ClaimMapping{
+			Username: c.String("username-claim"),
+			Email:    c.String("email-claim"),
+			FullName: c.String("full-name-claim"),
+		}
+	}
+	if _, err := models.ParseGroupTeamMap(c.String("group-team-map")); err != nil {
+		return nil, err
+	}
 	return &models.OAuth2Config{
 		Provider:                      c.String("provider"),
 		ClientID:                      c.String("key"),
 		ClientSecret:                  c.String("secret"),
 		OpenIDConnectAutoDiscoveryURL: c.String("auto-discover-url"),
 		CustomURLMapping:              customURLMapping,
-	}
+		Scopes:                        strings.Fields(c.String("scopes")),
+		ClaimMapping:                  claimMapping,
+		GroupClaimName:                c.String("group-claim-name"),
+		AdminGroup:                    c.String("admin-group"),
+		GroupTeamMap:                  c.String("group-team-map"),
+		GroupTeamMapRemoval:           c.Bool("group-team-map-removal"),
+	}, nil
 }
 
 func runAddOauth(c *cli.Context) error {
+	oAuth2Config, err := parseOAuth2Config(c)
+	if err != nil {
+		return err
+	}
+
 	if err := initDB(); err != nil {
 		return err
 	}
@@ -444,7 +508,7 @@ func runAddOauth(c *cli.Context) error {
 		Type:      models.LoginOAuth2,
 		Name:      c.String("name"),
 		IsActived: true,
-		Cfg:       parseOAuth2Config(c),
+		Cfg:       oAuth2Config,
 	})
 }
 
@@ -510,6 +574,46 @@ func runUpdateOauth(c *cli.Context) error {
 	}
 
 	oAuth2Config.CustomURLMapping = customURLMapping
+
+	if c.IsSet("scopes") {
+		oAuth2Config.Scopes = strings.Fields(c.String("scopes"))
+	}
+
+	// update claim mapping
+	if c.IsSet("username-claim") || c.IsSet("email-claim") || c.IsSet("full-name-claim") {
+		if oAuth2Config.ClaimMapping == nil {
+			oAuth2Config.ClaimMapping = &oauth2.ClaimMapping{}
+		}
+		if c.IsSet("username-claim") {
+			oAuth2Config.ClaimMapping.Username = c.String("username-claim")
+		}
+		if c.IsSet("email-claim") {
+			oAuth2Config.ClaimMapping.Email = c.String("email-claim")
+		}
+		if c.IsSet("full-name-claim") {
+			oAuth2Config.ClaimMapping.FullName = c.String("full-name-claim")
+		}
+	}
+
+	if c.IsSet("group-claim-name") {
+		oAuth2Config.GroupClaimName = c.String("group-claim-name")
+	}
+
+	if c.IsSet("admin-group") {
+		oAuth2Config.AdminGroup = c.String("admin-group")
+	}
+
+	if c.IsSet("group-team-map") {
+		if _, err := models.ParseGroupTeamMap(c.String("group-team-map")); err != nil {
+			return err
+		}
+		oAuth2Config.GroupTeamMap = c.String("group-team-map")
+	}
+
+	if c.IsSet("group-team-map-removal") {
+		oAuth2Config.GroupTeamMapRemoval = c.Bool("group-team-map-removal")
+	}
+
 	source.Cfg = oAuth2Config
 
 	return models.UpdateSource(source)
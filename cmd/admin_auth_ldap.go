@@ -81,6 +81,34 @@ var (
 			Name:  "public-ssh-key-attribute",
 			Usage: "The attribute of the user’s LDAP record containing the user’s public ssh key.",
 		},
+		cli.StringFlag{
+			Name:  "member-of-attribute",
+			Usage: "The attribute of the user’s LDAP record listing the DNs of the user’s groups.",
+		},
+		cli.StringFlag{
+			Name:  "group-search-base",
+			Usage: "The LDAP base at which groups listing their members will be searched for.",
+		},
+		cli.StringFlag{
+			Name:  "group-filter",
+			Usage: "An LDAP filter declaring which records below the group search base are groups.",
+		},
+		cli.StringFlag{
+			Name:  "group-member-attribute",
+			Usage: "The attribute of a group’s LDAP record listing its members.",
+		},
+		cli.StringFlag{
+			Name:  "group-member-uid",
+			Usage: "The attribute of the user’s LDAP record listed by the groups, the DN if it is not set.",
+		},
+		cli.StringFlag{
+			Name:  "group-team-map",
+			Usage: "JSON mapping of group DNs to organization teams, e.g. '{\"cn=developers,ou=groups,dc=example,dc=com\": {\"myorg\": [\"coders\"]}}'.",
+		},
+		cli.BoolFlag{
+			Name:  "group-team-map-removal",
+			Usage: "Remove users from mapped teams when they are not in the group anymore.",
+		},
 	}
 
 	ldapBindDnCLIFlags = append(commonLdapCLIFlags,
@@ -231,6 +259,30 @@ func parseLdapConfig(c *cli.Context, config *models.LDAPConfig) error {
 	if c.IsSet("admin-filter") {
 		config.Source.AdminFilter = c.String("admin-filter")
 	}
+	if c.IsSet("member-of-attribute") {
+		config.Source.AttributeMemberOf = c.String("member-of-attribute")
+	}
+	if c.IsSet("group-search-base") {
+		config.Source.GroupSearchBase = c.String("group-search-base")
+	}
+	if c.IsSet("group-filter") {
+		config.Source.GroupFilter = c.String("group-filter")
+	}
+	if c.IsSet("group-member-attribute") {
+		config.Source.GroupMemberAttribute = c.String("group-member-attribute")
+	}
+	if c.IsSet("group-member-uid") {
+		config.Source.GroupMemberUID = c.String("group-member-uid")
+	}
+	if c.IsSet("group-team-map") {
+		if _, err := models.ParseGroupTeamMap(c.String("group-team-map")); err != nil {
+			return err
+		}
+		config.GroupTeamMap = c.String("group-team-map")
+	}
+	if c.IsSet("group-team-map-removal") {
+		config.GroupTeamMapRemoval = c.Bool("group-team-map-removal")
+	}
 	return nil
 }
 
@@ -49,6 +49,13 @@ func TestAddLdapBindDn(t *testing.T) {
 				"--attributes-in-bind",
 				"--synchronize-users",
 				"--page-size", "99",
+				"--member-of-attribute", "memberOf-bind full",
+				"--group-search-base", "ou=Groups,dc=full-domain-bind,dc=org",
+				"--group-filter", "(objectClass=groupOfNames)",
+				"--group-member-attribute", "member-bind full",
+				"--group-member-uid", "uid-bind full",
+				"--group-team-map", `{"cn=developers,ou=Groups,dc=full-domain-bind,dc=org": {"myorg": ["coders"]}}`,
+				"--group-team-map-removal",
 			},
 			loginSource: &models.LoginSource{
 				Type:          models.LoginLDAP,
@@ -74,8 +81,15 @@ func TestAddLdapBindDn(t *testing.T) {
 						SearchPageSize:        99,
 						Filter:                "(memberOf=cn=user-group,ou=example,dc=full-domain-bind,dc=org)",
 						AdminFilter:           "(memberOf=cn=admin-group,ou=example,dc=full-domain-bind,dc=org)",
+						AttributeMemberOf:     "memberOf-bind full",
+						GroupSearchBase:       "ou=Groups,dc=full-domain-bind,dc=org",
+						GroupFilter:           "(objectClass=groupOfNames)",
+						GroupMemberAttribute:  "member-bind full",
+						GroupMemberUID:        "uid-bind full",
 						Enabled:               true,
 					},
+					GroupTeamMap:        `{"cn=developers,ou=Groups,dc=full-domain-bind,dc=org": {"myorg": ["coders"]}}`,
+					GroupTeamMapRemoval: true,
 				},
 			},
 		},
@@ -901,6 +915,33 @@ func TestUpdateLdapBindDn(t *testing.T) {
 			},
 			errMsg: "Invalid authentication type. expected: LDAP (via BindDN), actual: OAuth2",
 		},
+		// case 24
+		{
+			args: []string{
+				"ldap-test",
+				"--id", "1",
+				"--member-of-attribute", "memberOf",
+				"--group-team-map", `{"cn=developers,ou=Groups,dc=domain,dc=org": {"myorg": ["coders"]}}`,
+			},
+			loginSource: &models.LoginSource{
+				Type: models.LoginLDAP,
+				Cfg: &models.LDAPConfig{
+					Source: &ldap.Source{
+						AttributeMemberOf: "memberOf",
+					},
+					GroupTeamMap: `{"cn=developers,ou=Groups,dc=domain,dc=org": {"myorg": ["coders"]}}`,
+				},
+			},
+		},
+		// case 25
+		{
+			args: []string{
+				"ldap-test",
+				"--id", "1",
+				"--group-team-map", `{"cn=developers,ou=Groups,dc=domain,dc=org": "coders"}`,
+			},
+			errMsg: "invalid group to team mapping: json: cannot unmarshal string into Go struct field GroupTeamMap.cn=developers,ou=Groups,dc=domain,dc=org of type map[string][]string",
+		},
 	}
 
 	for n, c := range cases {
@@ -0,0 +1,174 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"errors"
+	"io/ioutil"
+	"strings"
+
+	"github.com/masoodkamyab/gitea/models"
+
+	"github.com/urfave/cli"
+)
+
+var (
+	samlCLIFlags = []cli.Flag{
+		cli.StringFlag{
+			Name:  "name",
+			Usage: "Authentication name.",
+		},
+		cli.BoolFlag{
+			Name:  "not-active",
+			Usage: "Deactivate the authentication source.",
+		},
+		cli.StringFlag{
+			Name:  "metadata-url",
+			Usage: "The URL the metadata of the identity provider is fetched from.",
+		},
+		cli.StringFlag{
+			Name:  "metadata-file",
+			Usage: "A file containing the metadata of the identity provider, it is used instead of the metadata URL.",
+		},
+		cli.StringFlag{
+			Name:  "username-attribute",
+			Usage: "The attribute of the assertion containing the user name, the NameID is used if it is not set.",
+		},
+		cli.StringFlag{
+			Name:  "email-attribute",
+			Usage: "The attribute of the assertion containing the user’s email address.",
+		},
+		cli.StringFlag{
+			Name:  "full-name-attribute",
+			Usage: "The attribute of the assertion containing the user’s full name.",
+		},
+		cli.StringFlag{
+			Name:  "group-attribute",
+			Usage: "The attribute of the assertion containing the user’s groups.",
+		},
+		cli.StringFlag{
+			Name:  "admin-group",
+			Usage: "The group whose members are administrators.",
+		},
+		cli.StringFlag{
+			Name:  "group-team-map",
+			Usage: "JSON mapping of groups to organization teams, e.g. '{\"developers\": {\"myorg\": [\"coders\"]}}'.",
+		},
+		cli.BoolFlag{
+			Name:  "group-team-map-removal",
+			Usage: "Remove users from mapped teams when they are not in the group anymore.",
+		},
+		cli.BoolFlag{
+			Name:  "auto-register",
+			Usage: "Create unknown users instead of asking them to link an existing account.",
+		},
+	}
+
+	cmdAuthAddSAML = cli.Command{
+		Name:  "add-saml",
+		Usage: "Add new SAML authentication source",
+		Action: func(c *cli.Context) error {
+			return newAuthService().addSAML(c)
+		},
+		Flags: samlCLIFlags,
+	}
+
+	cmdAuthUpdateSAML = cli.Command{
+		Name:  "update-saml",
+		Usage: "Update existing SAML authentication source",
+		Action: func(c *cli.Context) error {
+			return newAuthService().updateSAML(c)
+		},
+		Flags: append([]cli.Flag{idFlag}, samlCLIFlags...),
+	}
+)
+
+// parseSAMLConfig assigns values on config according to command line flags.
+func parseSAMLConfig(c *cli.Context, config *models.SAMLConfig) error {
+	if c.IsSet("metadata-url") {
+		config.IdentityProviderMetadataURL = c.String("metadata-url")
+	}
+	if c.IsSet("metadata-file") {
+		data, err := ioutil.ReadFile(c.String("metadata-file"))
+		if err != nil {
+			return err
+		}
+		config.IdentityProviderMetadata = strings.TrimSpace(string(data))
+	}
+	if c.IsSet("username-attribute") {
+		config.UsernameAttribute = c.String("username-attribute")
+	}
+	if c.IsSet("email-attribute") {
+		config.EmailAttribute = c.String("email-attribute")
+	}
+	if c.IsSet("full-name-attribute") {
+		config.FullNameAttribute = c.String("full-name-attribute")
+	}
+	if c.IsSet("group-attribute") {
+		config.GroupAttribute = c.String("group-attribute")
+	}
+	if c.IsSet("admin-group") {
+		config.AdminGroup = c.String("admin-group")
+	}
+	if c.IsSet("group-team-map") {
+		if _, err := models.ParseGroupTeamMap(c.String("group-team-map")); err != nil {
+			return err
+		}
+		config.GroupTeamMap = c.String("group-team-map")
+	}
+	if c.IsSet("group-team-map-removal") {
+		config.GroupTeamMapRemoval = c.Bool("group-team-map-removal")
+	}
+	if c.IsSet("auto-register") {
+		config.EnableAutoRegister = c.Bool("auto-register")
+	}
+	return nil
+}
+
+// addSAML adds a new SAML authentication source.
+func (a *authService) addSAML(c *cli.Context) error {
+	if err := argsSet(c, "name"); err != nil {
+		return err
+	}
+	if !c.IsSet("metadata-url") && !c.IsSet("metadata-file") {
+		return errors.New("metadata-url or metadata-file has to be set")
+	}
+
+	if err := a.initDB(); err != nil {
+		return err
+	}
+
+	loginSource := &models.LoginSource{
+		Type:      models.LoginSAML,
+		IsActived: true, // active by default
+		Cfg:       &models.SAMLConfig{},
+	}
+
+	parseLoginSource(c, loginSource)
+	if err := parseSAMLConfig(c, loginSource.SAML()); err != nil {
+		return err
+	}
+
+	return a.createLoginSource(loginSource)
+}
+
+// updateSAML updates an existing SAML authentication source.
+func (a *authService) updateSAML(c *cli.Context) error {
+	if err := a.initDB(); err != nil {
+		return err
+	}
+
+	loginSource, err := a.getLoginSource(c, models.LoginSAML)
+	if err != nil {
+		return err
+	}
+
+	parseLoginSource(c, loginSource)
+	if err := parseSAMLConfig(c, loginSource.SAML()); err != nil {
+		return err
+	}
+
+	return a.updateLoginSource(loginSource)
+}
@@ -0,0 +1,258 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/masoodkamyab/gitea/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli"
+)
+
+func TestAddSAML(t *testing.T) {
+	// Mock cli functions to do not exit on error
+	var osExiter = cli.OsExiter
+	defer func() { cli.OsExiter = osExiter }()
+	cli.OsExiter = func(code int) {}
+
+	dir, err := ioutil.TempDir("", "saml")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	metadataFile := filepath.Join(dir, "metadata.xml")
+	assert.NoError(t, ioutil.WriteFile(metadataFile, []byte("<EntityDescriptor/>\n"), 0600))
+
+	// Test cases
+	var cases = []struct {
+		args        []string
+		loginSource *models.LoginSource
+		errMsg      string
+	}{
+		// case 0
+		{
+			args: []string{
+				"saml-test",
+				"--name", "saml source full",
+				"--not-active",
+				"--metadata-url", "https://idp.example.com/metadata",
+				"--metadata-file", metadataFile,
+				"--username-attribute", "uid",
+				"--email-attribute", "mail",
+				"--full-name-attribute", "displayName",
+				"--group-attribute", "memberOf",
+				"--admin-group", "admins",
+				"--group-team-map", `{"developers": {"myorg": ["coders"]}}`,
+				"--group-team-map-removal",
+				"--auto-register",
+			},
+			loginSource: &models.LoginSource{
+				Type:      models.LoginSAML,
+				Name:      "saml source full",
+				IsActived: false,
+				Cfg: &models.SAMLConfig{
+					IdentityProviderMetadataURL: "https://idp.example.com/metadata",
+					IdentityProviderMetadata:    "<EntityDescriptor/>",
+					UsernameAttribute:           "uid",
+					EmailAttribute:              "mail",
+					FullNameAttribute:           "displayName",
+					GroupAttribute:              "memberOf",
+					AdminGroup:                  "admins",
+					GroupTeamMap:                `{"developers": {"myorg": ["coders"]}}`,
+					GroupTeamMapRemoval:         true,
+					EnableAutoRegister:          true,
+				},
+			},
+		},
+		// case 1
+		{
+			args: []string{
+				"saml-test",
+				"--name", "saml source min",
+				"--metadata-url", "https://idp.example.com/metadata",
+			},
+			loginSource: &models.LoginSource{
+				Type:      models.LoginSAML,
+				Name:      "saml source min",
+				IsActived: true,
+				Cfg: &models.SAMLConfig{
+					IdentityProviderMetadataURL: "https://idp.example.com/metadata",
+				},
+			},
+		},
+		// case 2
+		{
+			args: []string{
+				"saml-test",
+				"--metadata-url", "https://idp.example.com/metadata",
+			},
+			errMsg: "name is not set",
+		},
+		// case 3
+		{
+			args: []string{
+				"saml-test",
+				"--name", "saml source",
+			},
+			errMsg: "metadata-url or metadata-file has to be set",
+		},
+		// case 4
+		{
+			args: []string{
+				"saml-test",
+				"--name", "saml source",
+				"--metadata-url", "https://idp.example.com/metadata",
+				"--group-team-map", `{"developers": "coders"}`,
+			},
+			errMsg: "invalid group to team mapping: json: cannot unmarshal string into Go struct field GroupTeamMap.developers of type map[string][]string",
+		},
+	}
+
+	for n, c := range cases {
+		// Mock functions.
+		var createdLoginSource *models.LoginSource
+		service := &authService{
+			initDB: func() error {
+				return nil
+			},
+			createLoginSource: func(loginSource *models.LoginSource) error {
+				createdLoginSource = loginSource
+				return nil
+			},
+			updateLoginSource: func(loginSource *models.LoginSource) error {
+				assert.FailNow(t, "case %d: should not call updateLoginSource", n)
+				return nil
+			},
+			getLoginSourceByID: func(id int64) (*models.LoginSource, error) {
+				assert.FailNow(t, "case %d: should not call getLoginSourceByID", n)
+				return nil, nil
+			},
+		}
+
+		// Create a copy of command to test
+		app := cli.NewApp()
+		app.Flags = cmdAuthAddSAML.Flags
+		app.Action = service.addSAML
+
+		// Run it
+		err := app.Run(c.args)
+		if c.errMsg != "" {
+			assert.EqualError(t, err, c.errMsg, "case %d: error should match", n)
+		} else {
+			assert.NoError(t, err, "case %d: should have no errors", n)
+			assert.Equal(t, c.loginSource, createdLoginSource, "case %d: wrong loginSource", n)
+		}
+	}
+}
+
+func TestUpdateSAML(t *testing.T) {
+	// Mock cli functions to do not exit on error
+	var osExiter = cli.OsExiter
+	defer func() { cli.OsExiter = osExiter }()
+	cli.OsExiter = func(code int) {}
+
+	// Test cases
+	var cases = []struct {
+		args                []string
+		id                  int64
+		existingLoginSource *models.LoginSource
+		loginSource         *models.LoginSource
+		errMsg              string
+	}{
+		// case 0
+		{
+			args: []string{
+				"saml-test",
+				"--id", "7",
+				"--name", "saml source renamed",
+				"--email-attribute", "email",
+				"--auto-register=false",
+			},
+			id: 7,
+			existingLoginSource: &models.LoginSource{
+				Type:      models.LoginSAML,
+				Name:      "saml source",
+				IsActived: true,
+				Cfg: &models.SAMLConfig{
+					IdentityProviderMetadataURL: "https://idp.example.com/metadata",
+					EmailAttribute:              "mail",
+					GroupAttribute:              "memberOf",
+					EnableAutoRegister:          true,
+				},
+			},
+			loginSource: &models.LoginSource{
+				Type:      models.LoginSAML,
+				Name:      "saml source renamed",
+				IsActived: true,
+				Cfg: &models.SAMLConfig{
+					IdentityProviderMetadataURL: "https://idp.example.com/metadata",
+					EmailAttribute:              "email",
+					GroupAttribute:              "memberOf",
+				},
+			},
+		},
+		// case 1
+		{
+			args: []string{
+				"saml-test",
+				"--name", "saml source",
+			},
+			errMsg: "id is not set",
+		},
+		// case 2
+		{
+			args: []string{
+				"saml-test",
+				"--id", "1",
+			},
+			existingLoginSource: &models.LoginSource{
+				Type: models.LoginOAuth2,
+				Cfg:  &models.OAuth2Config{},
+			},
+			errMsg: "Invalid authentication type. expected: SAML, actual: OAuth2",
+		},
+	}
+
+	for n, c := range cases {
+		// Mock functions.
+		var updatedLoginSource *models.LoginSource
+		service := &authService{
+			initDB: func() error {
+				return nil
+			},
+			createLoginSource: func(loginSource *models.LoginSource) error {
+				assert.FailNow(t, "case %d: should not call createLoginSource", n)
+				return nil
+			},
+			updateLoginSource: func(loginSource *models.LoginSource) error {
+				updatedLoginSource = loginSource
+				return nil
+			},
+			getLoginSourceByID: func(id int64) (*models.LoginSource, error) {
+				if c.id != 0 {
+					assert.Equal(t, c.id, id, "case %d: wrong id", n)
+				}
+				return c.existingLoginSource, nil
+			},
+		}
+
+		// Create a copy of command to test
+		app := cli.NewApp()
+		app.Flags = cmdAuthUpdateSAML.Flags
+		app.Action = service.updateSAML
+
+		// Run it
+		err := app.Run(c.args)
+		if c.errMsg != "" {
+			assert.EqualError(t, err, c.errMsg, "case %d: error should match", n)
+		} else {
+			assert.NoError(t, err, "case %d: should have no errors", n)
+			assert.Equal(t, c.loginSource, updatedLoginSource, "case %d: wrong loginSource", n)
+		}
+	}
+}
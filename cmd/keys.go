@@ -10,14 +10,16 @@ import (
 	"strings"
 
 	"github.com/masoodkamyab/gitea/models"
+	"github.com/masoodkamyab/gitea/modules/log"
 
 	"github.com/urfave/cli"
+	"golang.org/x/crypto/ssh"
 )
 
 // CmdKeys represents the available keys sub-command
 var CmdKeys = cli.Command{
 	Name:   "keys",
-	Usage:  "This command queries the Gitea database to get the authorized command for a given ssh key fingerprint",
+	Usage:  "This command queries the Gitea database to get the authorized command for a given ssh key fingerprint or certificate",
 	Action: runKeys,
 	Flags: []cli.Flag{
 		cli.StringFlag{
@@ -66,6 +68,27 @@ func runKeys(c *cli.Context) error {
 		return err
 	}
 
+	// SSH certificates are checked against the trusted certificate authorities,
+	// making this usable as AuthorizedPrincipalsCommand as well
+	if strings.HasSuffix(strings.TrimSpace(c.String("type")), "-cert-v01@openssh.com") {
+		key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(content))
+		if err != nil {
+			return err
+		}
+		cert, ok := key.(*ssh.Certificate)
+		if !ok {
+			return fmt.Errorf("Not an SSH certificate: %s", c.String("type"))
+		}
+		user, principal, err := models.CheckSSHCertificate(cert, nil)
+		if err != nil {
+			log.Warn("SSH: Certificate %q (serial %d) rejected: %v", cert.KeyId, cert.Serial, err)
+			return err
+		}
+		log.Info("SSH: Certificate %q (serial %d) accepted for principal %s as user %s", cert.KeyId, cert.Serial, principal, user.Name)
+		fmt.Print(models.AuthorizedPrincipalString(user, principal))
+		return nil
+	}
+
 	publicKey, err := models.SearchPublicKeyByContent(content)
 	if err != nil {
 		return err
@@ -83,6 +83,33 @@ func TestAPICreateComment(t *testing.T) {
 	models.AssertExistsAndLoadBean(t, &models.Comment{ID: updatedComment.ID, IssueID: issue.ID, Content: commentBody})
 }
 
+func TestAPICreateCommentEstimate(t *testing.T) {
+	prepareTestEnv(t)
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+	urlStr := fmt.Sprintf("/api/v1/repos/user2/repo1/issues/1/comments?token=%s", token)
+
+	req := NewRequestWithJSON(t, "POST", urlStr, &api.CreateIssueCommentOption{
+		Body: "/estimate 1d 2h\nStarting now\n```\n/estimate 1h\n```",
+	})
+	resp := session.MakeRequest(t, req, http.StatusCreated)
+	var apiComment api.Comment
+	DecodeJSON(t, resp, &apiComment)
+	assert.EqualValues(t, "Starting now\n```\n/estimate 1h\n```", apiComment.Body)
+	models.AssertExistsAndLoadBean(t, &models.Issue{ID: 1, EstimatedTime: 26 * 60 * 60})
+
+	// a body of quick actions only creates no comment
+	req = NewRequestWithJSON(t, "POST", urlStr, &api.CreateIssueCommentOption{Body: "/estimate 3h"})
+	session.MakeRequest(t, req, http.StatusNoContent)
+	models.AssertExistsAndLoadBean(t, &models.Issue{ID: 1, EstimatedTime: 3 * 60 * 60})
+	models.AssertNotExistsBean(t, &models.Comment{IssueID: 1, Content: "/estimate 3h"})
+
+	req = NewRequestWithJSON(t, "POST", urlStr, &api.CreateIssueCommentOption{Body: "/estimate soon"})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+	models.AssertExistsAndLoadBean(t, &models.Issue{ID: 1, EstimatedTime: 3 * 60 * 60})
+}
+
 func TestAPIEditComment(t *testing.T) {
 	prepareTestEnv(t)
 	const newCommentBody = "This is the new comment body"
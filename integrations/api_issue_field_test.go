@@ -0,0 +1,116 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/masoodkamyab/gitea/models"
+	api "github.com/masoodkamyab/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIIssueFields(t *testing.T) {
+	prepareTestEnv(t)
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+	urlStr := fmt.Sprintf("/api/v1/repos/user2/repo1/issue_fields?token=%s", token)
+
+	req := NewRequestWithJSON(t, "POST", urlStr, &api.CreateIssueFieldOption{Name: "Severity", Type: "single_select"})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+	req = NewRequestWithJSON(t, "POST", urlStr, &api.CreateIssueFieldOption{Name: "Priority", Type: "text"})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequestWithJSON(t, "POST", urlStr, &api.CreateIssueFieldOption{Name: "Estimate", Type: "number"})
+	resp := session.MakeRequest(t, req, http.StatusCreated)
+	var field api.IssueField
+	DecodeJSON(t, resp, &field)
+	assert.EqualValues(t, "number", field.Type)
+	assert.EqualValues(t, 1, field.RepoID)
+
+	req = NewRequest(t, "GET", urlStr)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var fields []*api.IssueField
+	DecodeJSON(t, resp, &fields)
+	assert.Len(t, fields, 2)
+
+	req = NewRequestWithJSON(t, "PATCH", fmt.Sprintf("/api/v1/repos/user2/repo1/issue_fields/%d?token=%s", field.ID, token), &api.EditIssueFieldOption{Name: "Story points"})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &field)
+	assert.EqualValues(t, "Story points", field.Name)
+
+	// fields of the organization cannot be modified through its repositories
+	req = NewRequest(t, "DELETE", fmt.Sprintf("/api/v1/repos/user3/repo3/issue_fields/2?token=%s", token))
+	session.MakeRequest(t, req, http.StatusNotFound)
+
+	req = NewRequestWithJSON(t, "PUT", fmt.Sprintf("/api/v1/repos/user2/repo1/issues/1/fields/%d?token=%s", field.ID, token), &api.SetIssueFieldValueOption{Values: []string{"abc"}})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+	req = NewRequestWithJSON(t, "PUT", fmt.Sprintf("/api/v1/repos/user2/repo1/issues/1/fields/%d?token=%s", field.ID, token), &api.SetIssueFieldValueOption{Values: []string{"3"}})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var apiIssue api.Issue
+	DecodeJSON(t, resp, &apiIssue)
+	if assert.Len(t, apiIssue.Fields, 2) {
+		assert.EqualValues(t, "Priority", apiIssue.Fields[0].Name)
+		assert.EqualValues(t, []string{"High"}, apiIssue.Fields[0].Values)
+		assert.EqualValues(t, []string{"3"}, apiIssue.Fields[1].Values)
+	}
+
+	req = NewRequest(t, "GET", fmt.Sprintf("/api/v1/repos/issues/search?state=all&q=%s&token=%s", "repo:user2/repo1+field:Priority=High", token))
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var apiIssues []*api.Issue
+	DecodeJSON(t, resp, &apiIssues)
+	if assert.Len(t, apiIssues, 1) {
+		assert.EqualValues(t, 1, apiIssues[0].Index)
+	}
+
+	req = NewRequest(t, "DELETE", fmt.Sprintf("/api/v1/repos/user2/repo1/issue_fields/%d?token=%s", field.ID, token))
+	session.MakeRequest(t, req, http.StatusNoContent)
+	models.AssertNotExistsBean(t, &models.IssueField{ID: field.ID})
+	models.AssertNotExistsBean(t, &models.IssueFieldValue{FieldID: field.ID})
+
+	// user4 can only read the repository
+	session = loginUser(t, "user4")
+	token = getTokenForLoggedInUser(t, session)
+	req = NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/user2/repo1/issue_fields?token=%s", token), &api.CreateIssueFieldOption{Name: "Estimate", Type: "number"})
+	session.MakeRequest(t, req, http.StatusForbidden)
+	req = NewRequestWithJSON(t, "PUT", fmt.Sprintf("/api/v1/repos/user2/repo1/issues/1/fields/1?token=%s", token), &api.SetIssueFieldValueOption{Values: []string{"Low"}})
+	session.MakeRequest(t, req, http.StatusForbidden)
+}
+
+func TestAPIOrgIssueFields(t *testing.T) {
+	prepareTestEnv(t)
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+	urlStr := fmt.Sprintf("/api/v1/orgs/user3/issue_fields?token=%s", token)
+
+	req := NewRequestWithJSON(t, "POST", urlStr, &api.CreateIssueFieldOption{Name: "Owner", Type: "user"})
+	resp := session.MakeRequest(t, req, http.StatusCreated)
+	var field api.IssueField
+	DecodeJSON(t, resp, &field)
+	assert.EqualValues(t, 3, field.OwnerID)
+
+	// organization fields apply to all of its repositories
+	req = NewRequestWithJSON(t, "PUT", fmt.Sprintf("/api/v1/repos/user3/repo3/issues/1/fields/%d?token=%s", field.ID, token), &api.SetIssueFieldValueOption{Values: []string{"user2"}})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var apiIssue api.Issue
+	DecodeJSON(t, resp, &apiIssue)
+	if assert.Len(t, apiIssue.Fields, 1) {
+		assert.EqualValues(t, []string{"user2"}, apiIssue.Fields[0].Values)
+	}
+
+	req = NewRequest(t, "DELETE", fmt.Sprintf("/api/v1/orgs/user3/issue_fields/%d?token=%s", field.ID, token))
+	session.MakeRequest(t, req, http.StatusNoContent)
+
+	// user5 is not an owner of the organization
+	session = loginUser(t, "user5")
+	token = getTokenForLoggedInUser(t, session)
+	req = NewRequest(t, "DELETE", fmt.Sprintf("/api/v1/orgs/user3/issue_fields/2?token=%s", token))
+	session.MakeRequest(t, req, http.StatusForbidden)
+}
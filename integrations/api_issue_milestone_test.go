@@ -0,0 +1,36 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	api "github.com/masoodkamyab/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIMilestoneBurndown(t *testing.T) {
+	prepareTestEnv(t)
+
+	req := NewRequest(t, "GET", "/api/v1/repos/user2/repo1/milestones/1/burndown")
+	resp := MakeRequest(t, req, http.StatusOK)
+	var burndown api.MilestoneBurndown
+	DecodeJSON(t, resp, &burndown)
+	if assert.Len(t, burndown.Snapshots, 1) {
+		assert.EqualValues(t, 1, burndown.Snapshots[0].OpenIssues)
+		assert.EqualValues(t, 3662, burndown.Snapshots[0].TrackedTime)
+	}
+	assert.Nil(t, burndown.Forecast)
+
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/milestones/3/burndown")
+	resp = MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &burndown)
+	assert.Len(t, burndown.Snapshots, 0)
+
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/milestones/1000/burndown")
+	MakeRequest(t, req, http.StatusNotFound)
+}
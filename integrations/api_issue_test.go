@@ -7,6 +7,7 @@ package integrations
 import (
 	"fmt"
 	"net/http"
+	"net/url"
 	"testing"
 
 	"github.com/masoodkamyab/gitea/models"
@@ -34,6 +35,27 @@ func TestAPIListIssues(t *testing.T) {
 	}
 }
 
+func TestAPISearchIssues(t *testing.T) {
+	prepareTestEnv(t)
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+	req := NewRequestf(t, "GET", "/api/v1/repos/issues/search?q=%s&token=%s",
+		url.QueryEscape("is:open is:issue repo:user2/repo1 label:label1"), token)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var apiIssues []*api.Issue
+	DecodeJSON(t, resp, &apiIssues)
+	if assert.Len(t, apiIssues, 1) {
+		assert.EqualValues(t, 1, apiIssues[0].ID)
+	}
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/issues/search?q=%s&token=%s",
+		url.QueryEscape("repo:user2/nonexistent"), token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &apiIssues)
+	assert.Len(t, apiIssues, 0)
+}
+
 func TestAPICreateIssue(t *testing.T) {
 	prepareTestEnv(t)
 	const body, title = "apiTestBody", "apiTestTitle"
@@ -62,3 +84,99 @@ func TestAPICreateIssue(t *testing.T) {
 		Title:      title,
 	})
 }
+
+func TestAPITransferIssue(t *testing.T) {
+	prepareTestEnv(t)
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+	urlStr := fmt.Sprintf("/api/v1/repos/user2/repo1/issues/1/transfer?token=%s", token)
+
+	req := NewRequestWithJSON(t, "POST", urlStr, &api.TransferIssueOption{NewOwner: "user2", NewRepo: "nonexistent"})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequestWithJSON(t, "POST", urlStr, &api.TransferIssueOption{NewOwner: "user3", NewRepo: "repo3"})
+	resp := session.MakeRequest(t, req, http.StatusCreated)
+	var apiIssue api.Issue
+	DecodeJSON(t, resp, &apiIssue)
+	assert.EqualValues(t, 1, apiIssue.ID)
+	assert.EqualValues(t, 2, apiIssue.Index)
+	assert.Contains(t, apiIssue.URL, "/repos/user3/repo3/issues/2")
+	models.AssertExistsAndLoadBean(t, &models.Issue{ID: 1, RepoID: 3, Index: 2})
+
+	// the former URLs redirect to the transferred issue
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/issues/1?token=%s", token)
+	resp = session.MakeRequest(t, req, http.StatusMovedPermanently)
+	assert.Contains(t, resp.HeaderMap.Get("Location"), "/api/v1/repos/user3/repo3/issues/2")
+
+	req = NewRequest(t, "GET", "/user2/repo1/issues/1")
+	resp = session.MakeRequest(t, req, http.StatusMovedPermanently)
+	assert.Contains(t, resp.HeaderMap.Get("Location"), "/user3/repo3/issues/2")
+
+	// user5 cannot read the private repository the issue moved to
+	req = NewRequest(t, "GET", "/user2/repo1/issues/1")
+	loginUser(t, "user5").MakeRequest(t, req, http.StatusNotFound)
+}
+
+func TestAPIBulkEditIssues(t *testing.T) {
+	prepareTestEnv(t)
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+	urlStr := fmt.Sprintf("/api/v1/repos/user2/repo1/issues?token=%s", token)
+
+	req := NewRequestWithJSON(t, "PATCH", urlStr, &api.EditIssuesOption{Indices: []int64{1, 4}, AddAssignees: []string{"nonexistent"}})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+	req = NewRequestWithJSON(t, "PATCH", urlStr, &api.EditIssuesOption{Indices: []int64{1, 4}, AddLabels: []int64{1}, RemoveLabels: []int64{1}})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+	req = NewRequestWithJSON(t, "PATCH", urlStr, &api.EditIssuesOption{Indices: []int64{1, 9999}})
+	session.MakeRequest(t, req, http.StatusNotFound)
+
+	state, isLocked := "closed", true
+	req = NewRequestWithJSON(t, "PATCH", urlStr, &api.EditIssuesOption{
+		Indices:      []int64{1, 4},
+		AddLabels:    []int64{2},
+		AddAssignees: []string{"user2"},
+		State:        &state,
+		Lock:         &isLocked,
+	})
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var apiIssues []*api.Issue
+	DecodeJSON(t, resp, &apiIssues)
+	if assert.Len(t, apiIssues, 2) {
+		assert.EqualValues(t, api.StateClosed, apiIssues[0].State)
+	}
+	for _, id := range []int64{1, 5} {
+		issue := models.AssertExistsAndLoadBean(t, &models.Issue{ID: id}).(*models.Issue)
+		assert.True(t, issue.IsClosed)
+		assert.True(t, issue.IsLocked)
+		models.AssertExistsAndLoadBean(t, &models.IssueLabel{IssueID: id, LabelID: 2})
+		models.AssertExistsAndLoadBean(t, &models.IssueAssignees{IssueID: id, AssigneeID: 2})
+	}
+
+	// user4 cannot write to the repository
+	session = loginUser(t, "user4")
+	token = getTokenForLoggedInUser(t, session)
+	req = NewRequestWithJSON(t, "PATCH", fmt.Sprintf("/api/v1/repos/user2/repo1/issues?token=%s", token), &api.EditIssuesOption{Indices: []int64{1}, State: &state})
+	session.MakeRequest(t, req, http.StatusNotFound)
+}
+
+func TestAPIEditIssueEstimate(t *testing.T) {
+	prepareTestEnv(t)
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+	urlStr := fmt.Sprintf("/api/v1/repos/user2/repo1/issues/1?token=%s", token)
+
+	estimate := int64(-1)
+	req := NewRequestWithJSON(t, "PATCH", urlStr, &api.EditIssueOption{EstimatedTime: &estimate})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	estimate = 3 * 60 * 60
+	req = NewRequestWithJSON(t, "PATCH", urlStr, &api.EditIssueOption{EstimatedTime: &estimate})
+	resp := session.MakeRequest(t, req, http.StatusCreated)
+	var apiIssue api.Issue
+	DecodeJSON(t, resp, &apiIssue)
+	assert.EqualValues(t, estimate, apiIssue.EstimatedTime)
+	models.AssertExistsAndLoadBean(t, &models.Issue{ID: 1, EstimatedTime: estimate})
+}
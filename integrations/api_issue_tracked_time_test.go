@@ -0,0 +1,93 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/masoodkamyab/gitea/models"
+	api "github.com/masoodkamyab/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIListRepoTrackedTimes(t *testing.T) {
+	prepareTestEnv(t)
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/times?token=%s", token)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var times []*api.TrackedTime
+	DecodeJSON(t, resp, &times)
+	assert.Len(t, times, 4)
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/times?token=%s&user=user2&since=2000-01-01T00:00:02Z", token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &times)
+	if assert.Len(t, times, 2) {
+		assert.EqualValues(t, 3, times[0].ID)
+		assert.EqualValues(t, 5, times[1].ID)
+	}
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/times?token=%s&before=2000-01-01T00:00:01Z", token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &times)
+	if assert.Len(t, times, 1) {
+		assert.EqualValues(t, 1, times[0].ID)
+	}
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/times?token=%s&since=yesterday", token)
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/times?token=%s&user=unknown", token)
+	session.MakeRequest(t, req, http.StatusNotFound)
+}
+
+func TestAPIEditAndDeleteTrackedTime(t *testing.T) {
+	prepareTestEnv(t)
+
+	// user4 can only change their own times
+	session := loginUser(t, "user4")
+	token := getTokenForLoggedInUser(t, session)
+	req := NewRequestWithJSON(t, "PATCH", fmt.Sprintf("/api/v1/repos/user2/repo1/issues/2/times/2?token=%s", token), &api.EditTimeOption{Time: 60})
+	session.MakeRequest(t, req, http.StatusForbidden)
+
+	session = loginUser(t, "user2")
+	token = getTokenForLoggedInUser(t, session)
+
+	// the time must belong to the issue
+	req = NewRequestWithJSON(t, "PATCH", fmt.Sprintf("/api/v1/repos/user2/repo1/issues/1/times/2?token=%s", token), &api.EditTimeOption{Time: 60})
+	session.MakeRequest(t, req, http.StatusNotFound)
+	req = NewRequestWithJSON(t, "PATCH", fmt.Sprintf("/api/v1/repos/user2/repo1/issues/2/times/2?token=%s", token), &api.EditTimeOption{Time: -1})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequestWithJSON(t, "PATCH", fmt.Sprintf("/api/v1/repos/user2/repo1/issues/2/times/2?token=%s", token), &api.EditTimeOption{Time: 60})
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var trackedTime api.TrackedTime
+	DecodeJSON(t, resp, &trackedTime)
+	assert.EqualValues(t, 60, trackedTime.Time)
+	models.AssertExistsAndLoadBean(t, &models.TrackedTime{ID: 2, Time: 60})
+
+	req = NewRequestf(t, "DELETE", "/api/v1/repos/user2/repo1/issues/2/times/2?token=%s", token)
+	session.MakeRequest(t, req, http.StatusNoContent)
+	models.AssertNotExistsBean(t, &models.TrackedTime{ID: 2})
+}
+
+func TestAPIListOrgTrackedTimes(t *testing.T) {
+	prepareTestEnv(t)
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	// time tracking is disabled in the only repository of user3
+	req := NewRequestf(t, "GET", "/api/v1/orgs/user3/times?token=%s", token)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var times []*api.TrackedTime
+	DecodeJSON(t, resp, &times)
+	assert.Len(t, times, 0)
+}
@@ -0,0 +1,199 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/masoodkamyab/gitea/models"
+	api "github.com/masoodkamyab/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testAPIEnableProjects(t *testing.T, session *TestSession, token string) {
+	// Units which are not listed get disabled
+	enable := true
+	req := NewRequestWithJSON(t, "PATCH", "/api/v1/repos/user2/repo1?token="+token, &api.EditRepoOption{
+		HasIssues:       &enable,
+		HasWiki:         &enable,
+		HasPullRequests: &enable,
+		HasProjects:     &enable,
+	})
+	session.MakeRequest(t, req, http.StatusOK)
+}
+
+func TestAPIProjects(t *testing.T) {
+	prepareTestEnv(t)
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+	testAPIEnableProjects(t, session, token)
+
+	req := NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/projects?token=%s", token)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var apiProjects []*api.Project
+	DecodeJSON(t, resp, &apiProjects)
+	if assert.Len(t, apiProjects, 1) {
+		assert.EqualValues(t, 1, apiProjects[0].ID)
+	}
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/projects?token="+token, &api.CreateProjectOption{
+		Title:       "Roadmap",
+		Description: "what comes next",
+	})
+	resp = session.MakeRequest(t, req, http.StatusCreated)
+	var apiProject api.Project
+	DecodeJSON(t, resp, &apiProject)
+	assert.EqualValues(t, "Roadmap", apiProject.Title)
+	assert.EqualValues(t, 1, apiProject.RepoID)
+	assert.EqualValues(t, 2, apiProject.Creator.ID)
+	models.AssertExistsAndLoadBean(t, &models.Project{ID: apiProject.ID, RepoID: 1, Title: "Roadmap"})
+
+	closed := "closed"
+	req = NewRequestWithJSON(t, "PATCH", fmt.Sprintf("/api/v1/projects/%d?token=%s", apiProject.ID, token), &api.EditProjectOption{
+		Title: "Roadmap 2020",
+		State: &closed,
+	})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &apiProject)
+	assert.EqualValues(t, "Roadmap 2020", apiProject.Title)
+	assert.EqualValues(t, api.StateClosed, apiProject.State)
+	assert.NotNil(t, apiProject.Closed)
+
+	req = NewRequestf(t, "GET", "/api/v1/projects/%d?token=%s", apiProject.ID, token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &apiProject)
+	assert.EqualValues(t, "Roadmap 2020", apiProject.Title)
+
+	req = NewRequestf(t, "DELETE", "/api/v1/projects/%d?token=%s", apiProject.ID, token)
+	session.MakeRequest(t, req, http.StatusNoContent)
+	models.AssertNotExistsBean(t, &models.Project{ID: apiProject.ID})
+
+	req = NewRequestf(t, "GET", "/api/v1/projects/%d?token=%s", apiProject.ID, token)
+	session.MakeRequest(t, req, http.StatusNotFound)
+}
+
+func TestAPIProjectBoards(t *testing.T) {
+	prepareTestEnv(t)
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+	testAPIEnableProjects(t, session, token)
+
+	req := NewRequestf(t, "GET", "/api/v1/projects/1/boards?token=%s", token)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var apiBoards []*api.ProjectBoard
+	DecodeJSON(t, resp, &apiBoards)
+	if assert.Len(t, apiBoards, 2) {
+		assert.EqualValues(t, "To Do", apiBoards[0].Title)
+		assert.EqualValues(t, "Done", apiBoards[1].Title)
+	}
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/projects/1/boards?token="+token, &api.CreateProjectBoardOption{
+		Title:   "In Progress",
+		Sorting: 2,
+	})
+	resp = session.MakeRequest(t, req, http.StatusCreated)
+	var apiBoard api.ProjectBoard
+	DecodeJSON(t, resp, &apiBoard)
+	assert.EqualValues(t, "In Progress", apiBoard.Title)
+	assert.EqualValues(t, 1, apiBoard.ProjectID)
+
+	sorting := 1
+	req = NewRequestWithJSON(t, "PATCH", fmt.Sprintf("/api/v1/projects/1/boards/%d?token=%s", apiBoard.ID, token), &api.EditProjectBoardOption{
+		Title:   "Doing",
+		Sorting: &sorting,
+	})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &apiBoard)
+	assert.EqualValues(t, "Doing", apiBoard.Title)
+	assert.EqualValues(t, 1, apiBoard.Sorting)
+
+	req = NewRequestf(t, "DELETE", "/api/v1/projects/1/boards/%d?token=%s", apiBoard.ID, token)
+	session.MakeRequest(t, req, http.StatusNoContent)
+	models.AssertNotExistsBean(t, &models.ProjectBoard{ID: apiBoard.ID})
+
+	// Only writers of the repository may change the project
+	session = loginUser(t, "user4")
+	token = getTokenForLoggedInUser(t, session)
+	req = NewRequestWithJSON(t, "POST", "/api/v1/projects/1/boards?token="+token, &api.CreateProjectBoardOption{
+		Title: "Not mine",
+	})
+	session.MakeRequest(t, req, http.StatusForbidden)
+}
+
+func TestAPIProjectIssues(t *testing.T) {
+	prepareTestEnv(t)
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+	testAPIEnableProjects(t, session, token)
+
+	req := NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/hooks?token="+token, &api.CreateHookOption{
+		Type: "gitea",
+		Config: map[string]string{
+			"content_type": "json",
+			"url":          "http://example.com/project-hook",
+		},
+		Events: []string{"issues"},
+		Active: true,
+	})
+	session.MakeRequest(t, req, http.StatusCreated)
+
+	req = NewRequestf(t, "GET", "/api/v1/projects/1/issues?token=%s", token)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var apiIssues []*api.ProjectIssue
+	DecodeJSON(t, resp, &apiIssues)
+	assert.Len(t, apiIssues, 2)
+
+	issue := models.AssertExistsAndLoadBean(t, &models.Issue{RepoID: 1, Index: 4}).(*models.Issue)
+	req = NewRequestWithJSON(t, "POST", "/api/v1/projects/1/issues?token="+token, &api.AddProjectIssueOption{
+		IssueID: issue.ID,
+		BoardID: 1,
+	})
+	resp = session.MakeRequest(t, req, http.StatusCreated)
+	var apiIssue api.ProjectIssue
+	DecodeJSON(t, resp, &apiIssue)
+	assert.EqualValues(t, 1, apiIssue.BoardID)
+	assert.EqualValues(t, issue.ID, apiIssue.Issue.ID)
+	models.AssertExistsAndLoadBean(t, &models.ProjectIssue{ProjectID: 1, IssueID: issue.ID, ProjectBoardID: 1})
+
+	// Reordering within a board is not announced
+	req = NewRequestWithJSON(t, "PATCH", fmt.Sprintf("/api/v1/projects/1/issues/%d?token=%s", issue.ID, token), &api.MoveProjectIssueOption{
+		BoardID: 1,
+		Sorting: 0,
+	})
+	session.MakeRequest(t, req, http.StatusOK)
+	models.AssertNotExistsBean(t, &models.HookTask{RepoID: 1, EventType: models.HookEventIssues})
+
+	req = NewRequestWithJSON(t, "PATCH", fmt.Sprintf("/api/v1/projects/1/issues/%d?token=%s", issue.ID, token), &api.MoveProjectIssueOption{
+		BoardID: 2,
+		Sorting: 0,
+	})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &apiIssue)
+	assert.EqualValues(t, 2, apiIssue.BoardID)
+	models.AssertExistsAndLoadBean(t, &models.ProjectIssue{ProjectID: 1, IssueID: issue.ID, ProjectBoardID: 2})
+
+	task := models.AssertExistsAndLoadBean(t, &models.HookTask{RepoID: 1, EventType: models.HookEventIssues}).(*models.HookTask)
+	assert.Contains(t, task.PayloadContent, `"action": "project_board_changed"`)
+	assert.Contains(t, task.PayloadContent, `"from": "To Do"`)
+	assert.Contains(t, task.PayloadContent, `"title": "Done"`)
+
+	req = NewRequestWithJSON(t, "PATCH", fmt.Sprintf("/api/v1/projects/1/issues/%d?token=%s", issue.ID, token), &api.MoveProjectIssueOption{
+		BoardID: 999,
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequestf(t, "DELETE", "/api/v1/projects/1/issues/%d?token=%s", issue.ID, token)
+	session.MakeRequest(t, req, http.StatusNoContent)
+	models.AssertNotExistsBean(t, &models.ProjectIssue{ProjectID: 1, IssueID: issue.ID})
+
+	req = NewRequestf(t, "DELETE", "/api/v1/projects/1/issues/%d?token=%s", issue.ID, token)
+	session.MakeRequest(t, req, http.StatusNotFound)
+}
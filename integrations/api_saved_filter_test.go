@@ -0,0 +1,70 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/masoodkamyab/gitea/models"
+	api "github.com/masoodkamyab/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPISavedFilters(t *testing.T) {
+	prepareTestEnv(t)
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestWithJSON(t, "POST", "/api/v1/user/filters?token="+token, &api.CreateSavedFilterOption{
+		Name:  "Open pulls",
+		Query: "is:open is:pr",
+		Sort:  "oldest",
+	})
+	resp := session.MakeRequest(t, req, http.StatusCreated)
+	var apiFilter api.SavedFilter
+	DecodeJSON(t, resp, &apiFilter)
+	assert.Equal(t, "Open pulls", apiFilter.Name)
+	assert.EqualValues(t, 2, apiFilter.OwnerID)
+	models.AssertExistsAndLoadBean(t, &models.SavedFilter{ID: apiFilter.ID, Query: "is:open is:pr"})
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/user/filters?token="+token, &api.CreateSavedFilterOption{
+		Name: "Invalid",
+		Sort: "random",
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequest(t, "GET", "/api/v1/user/filters?token="+token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var apiFilters []*api.SavedFilter
+	DecodeJSON(t, resp, &apiFilters)
+	assert.Len(t, apiFilters, 3)
+
+	query := "is:closed"
+	req = NewRequestWithJSON(t, "PATCH", fmt.Sprintf("/api/v1/user/filters/%d?token=%s", apiFilter.ID, token), &api.EditSavedFilterOption{
+		Query: &query,
+	})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &apiFilter)
+	assert.Equal(t, "is:closed", apiFilter.Query)
+	assert.Equal(t, "Open pulls", apiFilter.Name)
+
+	// user4 sees the filter shared with its team but may not change it
+	session4 := loginUser(t, "user4")
+	token4 := getTokenForLoggedInUser(t, session4)
+	req = NewRequest(t, "GET", "/api/v1/user/filters/2?token="+token4)
+	session4.MakeRequest(t, req, http.StatusOK)
+	req = NewRequest(t, "DELETE", "/api/v1/user/filters/2?token="+token4)
+	session4.MakeRequest(t, req, http.StatusForbidden)
+	req = NewRequest(t, "GET", "/api/v1/user/filters/1?token="+token4)
+	session4.MakeRequest(t, req, http.StatusNotFound)
+
+	req = NewRequest(t, "DELETE", fmt.Sprintf("/api/v1/user/filters/%d?token=%s", apiFilter.ID, token))
+	session.MakeRequest(t, req, http.StatusNoContent)
+	models.AssertNotExistsBean(t, &models.SavedFilter{ID: apiFilter.ID})
+}
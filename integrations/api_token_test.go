@@ -7,9 +7,13 @@ package integrations
 import (
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/masoodkamyab/gitea/models"
 	api "github.com/masoodkamyab/gitea/modules/structs"
+	"github.com/masoodkamyab/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
 )
 
 // TestAPICreateAndDeleteToken tests that token that was just created can be deleted
@@ -48,3 +52,100 @@ func TestAPIDeleteMissingToken(t *testing.T) {
 	req = AddBasicAuthHeader(req, user.Name)
 	MakeRequest(t, req, http.StatusNotFound)
 }
+
+func TestAPITokenScopes(t *testing.T) {
+	prepareTestEnv(t)
+	session := loginUser(t, "user2")
+
+	// without a scope the web form refuses to create a token
+	req := NewRequestWithValues(t, "POST", "/user/settings/applications", map[string]string{
+		"_csrf": GetCSRF(t, session, "/user/settings/applications"),
+		"name":  "unscoped",
+	})
+	session.MakeRequest(t, req, http.StatusOK)
+	models.AssertNotExistsBean(t, &models.AccessToken{UID: 2, Name: "unscoped"})
+
+	// repo:read can read but not change repositories and issues
+	token := getScopedTokenForLoggedInUser(t, session, "repo:read")
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo2?token=%s", token)
+	MakeRequest(t, req, http.StatusOK)
+	req = NewRequestf(t, "GET", "/api/v1/user?token=%s", token)
+	MakeRequest(t, req, http.StatusOK)
+	title := "scoped"
+	req = NewRequestWithJSON(t, "PATCH", "/api/v1/repos/user2/repo1/issues/1?token="+token, &api.EditIssueOption{Title: title})
+	MakeRequest(t, req, http.StatusForbidden)
+	req = NewRequestf(t, "GET", "/api/v1/user/emails?token=%s", token)
+	MakeRequest(t, req, http.StatusForbidden)
+
+	// issue can change issues but not the repository
+	token = getScopedTokenForLoggedInUser(t, session, "issue,user")
+	req = NewRequestWithJSON(t, "PATCH", "/api/v1/repos/user2/repo1/issues/1?token="+token, &api.EditIssueOption{Title: title})
+	MakeRequest(t, req, http.StatusCreated)
+	models.AssertExistsAndLoadBean(t, &models.Issue{ID: 1, Title: title})
+	req = NewRequestWithJSON(t, "PATCH", "/api/v1/repos/user2/repo1?token="+token, &api.EditRepoOption{Description: &title})
+	MakeRequest(t, req, http.StatusForbidden)
+	req = NewRequestWithJSON(t, "POST", "/api/v1/orgs?token="+token, &api.CreateOrgOption{UserName: "scoped"})
+	MakeRequest(t, req, http.StatusForbidden)
+
+	// a token only creates tokens within its own scopes
+	req = NewRequestWithJSON(t, "POST", "/api/v1/users/user2/tokens", &api.CreateAccessTokenOption{Name: "child", Scopes: []string{"repo:write"}})
+	req.SetBasicAuth(token, "x-oauth-basic")
+	MakeRequest(t, req, http.StatusForbidden)
+	req = NewRequestWithJSON(t, "POST", "/api/v1/users/user2/tokens", &api.CreateAccessTokenOption{Name: "child"})
+	req.SetBasicAuth(token, "x-oauth-basic")
+	resp := MakeRequest(t, req, http.StatusCreated)
+	var child api.AccessToken
+	DecodeJSON(t, resp, &child)
+	assert.EqualValues(t, []string{"issue", "user"}, child.Scopes)
+
+	// expired tokens are rejected
+	expired, err := models.GetAccessTokenBySHA(token)
+	assert.NoError(t, err)
+	expired.ExpiresUnix = util.TimeStampNow().Add(-60)
+	assert.NoError(t, models.UpdateAccessToken(expired))
+	req = NewRequestf(t, "GET", "/api/v1/user?token=%s", token)
+	MakeRequest(t, req, http.StatusUnauthorized)
+
+	// git and web requests are limited to reading repositories
+	token = getScopedTokenForLoggedInUser(t, session, "user")
+	req = NewRequest(t, "GET", "/user2/repo2/info/refs")
+	req.SetBasicAuth(token, "x-oauth-basic")
+	MakeRequest(t, req, http.StatusForbidden)
+	req = NewRequest(t, "GET", "/user2/repo2")
+	req.SetBasicAuth(token, "x-oauth-basic")
+	MakeRequest(t, req, http.StatusNotFound)
+
+	// sudo is limited to tokens with the sudo scope
+	session = loginUser(t, "user1")
+	token = getScopedTokenForLoggedInUser(t, session, "user")
+	req = NewRequestf(t, "GET", "/api/v1/user?sudo=user2&token=%s", token)
+	MakeRequest(t, req, http.StatusForbidden)
+	req = NewRequestf(t, "GET", "/api/v1/admin/orgs?token=%s", token)
+	MakeRequest(t, req, http.StatusForbidden)
+	token = getScopedTokenForLoggedInUser(t, session, "sudo")
+	req = NewRequestf(t, "GET", "/api/v1/admin/orgs?token=%s", token)
+	MakeRequest(t, req, http.StatusOK)
+}
+
+func TestAPICreateTokenWithScopes(t *testing.T) {
+	prepareTestEnv(t)
+
+	expires := time.Now().Add(-time.Hour)
+	req := NewRequestWithJSON(t, "POST", "/api/v1/users/user1/tokens", &api.CreateAccessTokenOption{Name: "expired", ExpiresAt: &expires})
+	MakeRequest(t, AddBasicAuthHeader(req, "user1"), http.StatusUnprocessableEntity)
+	req = NewRequestWithJSON(t, "POST", "/api/v1/users/user1/tokens", &api.CreateAccessTokenOption{Name: "invalid", Scopes: []string{"repo:delete"}})
+	MakeRequest(t, AddBasicAuthHeader(req, "user1"), http.StatusUnprocessableEntity)
+
+	expires = time.Now().Add(time.Hour)
+	req = NewRequestWithJSON(t, "POST", "/api/v1/users/user1/tokens", &api.CreateAccessTokenOption{
+		Name:      "scoped",
+		Scopes:    []string{"user", "repo:read"},
+		ExpiresAt: &expires,
+	})
+	resp := MakeRequest(t, AddBasicAuthHeader(req, "user1"), http.StatusCreated)
+	var token api.AccessToken
+	DecodeJSON(t, resp, &token)
+	assert.EqualValues(t, []string{"repo:read", "user"}, token.Scopes)
+	assert.NotNil(t, token.ExpiresAt)
+	models.AssertExistsAndLoadBean(t, &models.AccessToken{ID: token.ID, Scope: "repo:read,user"})
+}
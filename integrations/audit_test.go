@@ -0,0 +1,98 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/masoodkamyab/gitea/models"
+	api "github.com/masoodkamyab/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditLog(t *testing.T) {
+	prepareTestEnv(t)
+
+	// user2 is an owner of the organization user3
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+	req := NewRequest(t, "PUT", "/api/v1/teams/2/members/user5?token="+token)
+	session.MakeRequest(t, req, http.StatusNoContent)
+	// Adding a member again changes nothing, so it is not recorded
+	session.MakeRequest(t, req, http.StatusNoContent)
+
+	adminSession := loginUser(t, "user1")
+	adminToken := getTokenForLoggedInUser(t, adminSession)
+	admin := true
+	req = NewRequestWithJSON(t, "PATCH", "/api/v1/admin/users/user5?token="+adminToken, &api.EditUserOption{
+		Email: "user5@example.com",
+		Admin: &admin,
+	})
+	adminSession.MakeRequest(t, req, http.StatusOK)
+	assert.True(t, models.AssertExistsAndLoadBean(t, &models.User{Name: "user5"}).(*models.User).IsAdmin)
+
+	// Organization owners only see the changes of their organization
+	req = NewRequest(t, "GET", "/api/v1/orgs/user3/audit?token="+token)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var events []*api.AuditEvent
+	DecodeJSON(t, resp, &events)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, "org_team_member_add", events[0].Action)
+		assert.Equal(t, "user2", events[0].ActorName)
+		assert.Equal(t, "team1", events[0].Target)
+		assert.Equal(t, "user5", events[0].Diff["member"].New)
+	}
+	assert.Equal(t, "1", resp.Header().Get("X-Total-Count"))
+
+	resp = session.MakeRequest(t, NewRequest(t, "GET", "/org/user3/settings/audit"), http.StatusOK)
+	assert.Contains(t, resp.Body.String(), "team1")
+
+	// Site administrators see all changes
+	req = NewRequest(t, "GET", "/api/v1/admin/audit?token="+adminToken)
+	resp = adminSession.MakeRequest(t, req, http.StatusOK)
+	events = nil
+	DecodeJSON(t, resp, &events)
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, "user_admin", events[0].Action)
+		assert.Equal(t, "user1", events[0].ActorName)
+		assert.Equal(t, "user5", events[0].Target)
+		assert.Equal(t, false, events[0].Diff["is_admin"].Old)
+		assert.Equal(t, true, events[0].Diff["is_admin"].New)
+		assert.Equal(t, "org_team_member_add", events[1].Action)
+	}
+
+	req = NewRequest(t, "GET", "/api/v1/admin/audit?limit=1&page=2&token="+adminToken)
+	resp = adminSession.MakeRequest(t, req, http.StatusOK)
+	events = nil
+	DecodeJSON(t, resp, &events)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, "org_team_member_add", events[0].Action)
+	}
+
+	resp = adminSession.MakeRequest(t, NewRequest(t, "GET", "/admin/audit"), http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	assert.EqualValues(t, 2, htmlDoc.doc.Find(".admin.audit tbody tr").Length())
+}
+
+func TestAuditLogPermissions(t *testing.T) {
+	prepareTestEnv(t)
+
+	// user4 is a member but not an owner of the organization user3
+	session := loginUser(t, "user4")
+	token := getTokenForLoggedInUser(t, session)
+	session.MakeRequest(t, NewRequest(t, "GET", "/api/v1/orgs/user3/audit?token="+token), http.StatusForbidden)
+	session.MakeRequest(t, NewRequest(t, "GET", "/api/v1/admin/audit?token="+token), http.StatusForbidden)
+	session.MakeRequest(t, NewRequest(t, "GET", "/org/user3/settings/audit"), http.StatusNotFound)
+	session.MakeRequest(t, NewRequest(t, "GET", "/admin/audit"), http.StatusForbidden)
+
+	// The owner needs a token allowed to administrate organizations
+	session = loginUser(t, "user2")
+	token = getScopedTokenForLoggedInUser(t, session, "repo:read")
+	session.MakeRequest(t, NewRequest(t, "GET", "/api/v1/orgs/user3/audit?token="+token), http.StatusForbidden)
+	token = getScopedTokenForLoggedInUser(t, session, "admin:org")
+	session.MakeRequest(t, NewRequest(t, "GET", "/api/v1/orgs/user3/audit?token="+token), http.StatusOK)
+}
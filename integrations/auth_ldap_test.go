@@ -5,6 +5,7 @@
 package integrations
 
 import (
+	"fmt"
 	"net/http"
 	"os"
 	"strings"
@@ -94,10 +95,10 @@ func getLDAPServerHost() string {
 	return host
 }
 
-func addAuthSourceLDAP(t *testing.T, sshKeyAttribute string) {
+func addAuthSourceLDAP(t *testing.T, sshKeyAttribute string, groupMapParams ...string) {
 	session := loginUser(t, "user1")
 	csrf := GetCSRF(t, session, "/admin/auths/new")
-	req := NewRequestWithValues(t, "POST", "/admin/auths/new", map[string]string{
+	values := map[string]string{
 		"_csrf":                    csrf,
 		"type":                     "2",
 		"name":                     "ldap",
@@ -115,7 +116,15 @@ func addAuthSourceLDAP(t *testing.T, sshKeyAttribute string) {
 		"attribute_ssh_public_key": sshKeyAttribute,
 		"is_sync_enabled":          "on",
 		"is_active":                "on",
-	})
+	}
+	if len(groupMapParams) > 0 {
+		values["attribute_member_of"] = "memberOf"
+		values["group_team_map"] = groupMapParams[0]
+	}
+	if len(groupMapParams) > 1 {
+		values["group_team_map_removal"] = groupMapParams[1]
+	}
+	req := NewRequestWithValues(t, "POST", "/admin/auths/new", values)
 	session.MakeRequest(t, req, http.StatusFound)
 }
 
@@ -230,3 +239,83 @@ func TestLDAPUserSSHKeySync(t *testing.T) {
 		assert.ElementsMatch(t, u.SSHKeys, syncedKeys)
 	}
 }
+
+func TestLDAPGroupTeamSync(t *testing.T) {
+	if skipLDAPTests() {
+		t.Skip()
+		return
+	}
+	prepareTestEnv(t)
+	addAuthSourceLDAP(t, "", `{"cn=ship_crew,ou=people,dc=planetexpress,dc=com": {"user3": ["team1"]}}`, "on")
+	source := models.AssertExistsAndLoadBean(t, &models.LoginSource{Name: "ldap"}).(*models.LoginSource)
+
+	// the preview lists the new crew members without changing the teams
+	session := loginUser(t, "user1")
+	req := NewRequestf(t, "GET", "/admin/auths/%d/group_sync_preview", source.ID)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	assert.Equal(t, 3, htmlDoc.doc.Find("table.table tbody tr").Length())
+
+	models.SyncExternalUsers()
+	for _, u := range gitLDAPUsers {
+		user := models.AssertExistsAndLoadBean(t, &models.User{Name: u.UserName}).(*models.User)
+		isMember, err := models.IsTeamMember(3, 2, user.ID)
+		assert.NoError(t, err)
+		switch u.UserName {
+		case "fry", "leela", "bender":
+			assert.True(t, isMember, u.UserName)
+		default:
+			assert.False(t, isMember, u.UserName)
+		}
+	}
+
+	// the teams are synchronized at login too
+	fry := models.AssertExistsAndLoadBean(t, &models.User{Name: "fry"}).(*models.User)
+	team := models.AssertExistsAndLoadBean(t, &models.Team{ID: 2}).(*models.Team)
+	assert.NoError(t, models.RemoveTeamMember(team, fry.ID))
+	loginUserWithPassword(t, "fry", "fry")
+	models.AssertExistsAndLoadBean(t, &models.TeamUser{TeamID: 2, UID: fry.ID})
+
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc = NewHTMLParser(t, resp.Body)
+	assert.Equal(t, 0, htmlDoc.doc.Find("table.table tbody tr").Length())
+}
+
+func TestLDAPGroupSyncPreviewFailed(t *testing.T) {
+	prepareTestEnv(t)
+	session := loginUser(t, "user1")
+	csrf := GetCSRF(t, session, "/admin/auths/new")
+	req := NewRequestWithValues(t, "POST", "/admin/auths/new", map[string]string{
+		"_csrf":               csrf,
+		"type":                "2",
+		"name":                "ldap-unreachable",
+		"host":                "127.0.0.1",
+		"port":                "1",
+		"user_base":           "ou=people,dc=planetexpress,dc=com",
+		"filter":              "(uid=%s)",
+		"attribute_mail":      "mail",
+		"attribute_member_of": "memberOf",
+		"group_team_map":      `{"cn=ship_crew,ou=people,dc=planetexpress,dc=com": {"user3": ["team1"]}}`,
+		"is_active":           "on",
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	source := models.AssertExistsAndLoadBean(t, &models.LoginSource{Name: "ldap-unreachable"}).(*models.LoginSource)
+	assert.Equal(t, "memberOf", source.LDAP().AttributeMemberOf)
+
+	req = NewRequestf(t, "GET", "/admin/auths/%d/group_sync_preview", source.ID)
+	resp := session.MakeRequest(t, req, http.StatusFound)
+	assert.Equal(t, fmt.Sprintf("/admin/auths/%d", source.ID), resp.Header().Get("Location"))
+
+	// only LDAP via BindDN sources can be previewed
+	req = NewRequestWithValues(t, "POST", "/admin/auths/new", map[string]string{
+		"_csrf":            csrf,
+		"type":             "4",
+		"name":             "pam",
+		"pam_service_name": "gitea",
+		"is_active":        "on",
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	source = models.AssertExistsAndLoadBean(t, &models.LoginSource{Name: "pam"}).(*models.LoginSource)
+	req = NewRequestf(t, "GET", "/admin/auths/%d/group_sync_preview", source.ID)
+	session.MakeRequest(t, req, http.StatusNotFound)
+}
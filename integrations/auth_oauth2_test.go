@@ -0,0 +1,63 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/masoodkamyab/gitea/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddAuthSourceOAuth2GroupTeamMap(t *testing.T) {
+	prepareTestEnv(t)
+	session := loginUser(t, "user1")
+	csrf := GetCSRF(t, session, "/admin/auths/new")
+
+	values := map[string]string{
+		"_csrf":                   csrf,
+		"type":                    "6",
+		"name":                    "oauth2-groups",
+		"oauth2_provider":         "github",
+		"oauth2_key":              "key",
+		"oauth2_secret":           "secret",
+		"oauth2_scopes":           "profile  groups",
+		"oauth2_username_claim":   "preferred_username",
+		"oauth2_group_claim_name": "groups",
+		"oauth2_admin_group":      "admins",
+		"oauth2_group_team_map":   `{"devs": ["user3"]}`,
+		"is_active":               "on",
+	}
+	req := NewRequestWithValues(t, "POST", "/admin/auths/new", values)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	assert.Contains(t, htmlDoc.doc.Find(".ui.negative.message").Text(), "invalid group to team mapping")
+	models.AssertNotExistsBean(t, &models.LoginSource{Name: "oauth2-groups"})
+
+	values["oauth2_group_team_map"] = `{"devs": {"user3": ["team1"]}}`
+	values["oauth2_group_team_map_removal"] = "on"
+	req = NewRequestWithValues(t, "POST", "/admin/auths/new", values)
+	session.MakeRequest(t, req, http.StatusFound)
+
+	source := models.AssertExistsAndLoadBean(t, &models.LoginSource{Name: "oauth2-groups"}).(*models.LoginSource)
+	cfg := source.OAuth2()
+	assert.Equal(t, []string{"profile", "groups"}, cfg.Scopes)
+	if assert.NotNil(t, cfg.ClaimMapping) {
+		assert.Equal(t, "preferred_username", cfg.ClaimMapping.Username)
+	}
+	assert.Equal(t, "groups", cfg.GroupClaimName)
+	assert.Equal(t, "admins", cfg.AdminGroup)
+	assert.Equal(t, `{"devs": {"user3": ["team1"]}}`, cfg.GroupTeamMap)
+	assert.True(t, cfg.GroupTeamMapRemoval)
+
+	req = NewRequestf(t, "GET", "/admin/auths/%d", source.ID)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc = NewHTMLParser(t, resp.Body)
+	assert.Equal(t, "profile groups", htmlDoc.GetInputValueByName("oauth2_scopes"))
+	assert.Equal(t, "admins", htmlDoc.GetInputValueByName("oauth2_admin_group"))
+	assert.Equal(t, `{"devs": {"user3": ["team1"]}}`, htmlDoc.doc.Find("textarea[name=oauth2_group_team_map]").Text())
+}
@@ -0,0 +1,173 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/masoodkamyab/gitea/models"
+	"github.com/masoodkamyab/gitea/modules/auth/saml/samltest"
+	"github.com/masoodkamyab/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func addAuthSourceSAML(t *testing.T, session *TestSession, name, metadataURL string, autoRegister bool) *models.LoginSource {
+	csrf := GetCSRF(t, session, "/admin/auths/new")
+	values := map[string]string{
+		"_csrf":                    csrf,
+		"type":                     "7",
+		"name":                     name,
+		"saml_metadata_url":        metadataURL,
+		"saml_username_attribute":  "uid",
+		"saml_email_attribute":     "mail",
+		"saml_full_name_attribute": "displayName",
+		"saml_group_attribute":     "memberOf",
+		"saml_group_team_map":      `{"writers": {"user3": ["team1"]}}`,
+		"is_active":                "on",
+	}
+	if autoRegister {
+		values["saml_enable_auto_register"] = "on"
+	}
+	req := NewRequestWithValues(t, "POST", "/admin/auths/new", values)
+	session.MakeRequest(t, req, http.StatusFound)
+	return models.AssertExistsAndLoadBean(t, &models.LoginSource{Name: name}).(*models.LoginSource)
+}
+
+// samlProviderURL returns the base URL of the service provider of a SAML login source
+func samlProviderURL(provider string) string {
+	return setting.AppURL + "user/saml/" + provider
+}
+
+// samlLogin signs in at the identity provider stub and returns the response of the assertion consumer service
+func samlLogin(t *testing.T, session *TestSession, idp *samltest.IdentityProvider, provider, nameID string, attributes map[string][]string) *httptest.ResponseRecorder {
+	req := NewRequest(t, "GET", "/user/saml/"+provider)
+	resp := session.MakeRequest(t, req, http.StatusFound)
+	authnRequest, err := samltest.ParseAuthnRequestURL(resp.Header().Get("Location"))
+	assert.NoError(t, err)
+
+	req = NewRequestWithValues(t, "POST", "/user/saml/"+provider+"/acs", map[string]string{
+		"SAMLResponse": idp.Response(&samltest.Response{
+			InResponseTo: authnRequest.ID,
+			Destination:  authnRequest.AssertionConsumerServiceURL,
+			Audience:     authnRequest.Issuer,
+			NameID:       nameID,
+			Attributes:   attributes,
+		}),
+	})
+	return session.MakeRequest(t, req, http.StatusFound)
+}
+
+func TestSAMLLogin(t *testing.T) {
+	prepareTestEnv(t)
+
+	idp, err := samltest.NewIdentityProvider("https://idp.example.com/metadata", "https://idp.example.com/sso")
+	assert.NoError(t, err)
+	metadataServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(idp.Metadata())
+	}))
+	defer metadataServer.Close()
+
+	admin := loginUser(t, "user1")
+	source := addAuthSourceSAML(t, admin, "samltest", metadataServer.URL, true)
+	cfg := source.SAML()
+	assert.Equal(t, metadataServer.URL, cfg.IdentityProviderMetadataURL)
+	assert.Equal(t, "uid", cfg.UsernameAttribute)
+	assert.True(t, cfg.EnableAutoRegister)
+
+	// the metadata of the service provider is public
+	req := NewRequest(t, "GET", "/user/saml/samltest/metadata")
+	resp := MakeRequest(t, req, http.StatusOK)
+	assert.Equal(t, "application/samlmetadata+xml", resp.Header().Get("Content-Type"))
+	assert.Contains(t, resp.Body.String(), `entityID="`+samlProviderURL("samltest")+`/metadata"`)
+	assert.Contains(t, resp.Body.String(), `Location="`+samlProviderURL("samltest")+`/acs"`)
+	MakeRequest(t, NewRequest(t, "GET", "/user/saml/unknown/metadata"), http.StatusNotFound)
+
+	// the sign in page offers the source
+	resp = MakeRequest(t, NewRequest(t, "GET", "/user/login"), http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	assert.Equal(t, 1, htmlDoc.doc.Find(`.saml a[href$="/user/saml/samltest"]`).Length())
+
+	// an unknown user is registered
+	session := emptyTestSession(t)
+	resp = samlLogin(t, session, idp, "samltest", "jdoe-id", map[string][]string{
+		"uid":         {"jdoe"},
+		"mail":        {"jdoe@example.com"},
+		"displayName": {"John Doe"},
+		"memberOf":    {"writers"},
+	})
+	assert.Equal(t, "/", resp.Header().Get("Location"))
+	user := models.AssertExistsAndLoadBean(t, &models.User{Name: "jdoe"}).(*models.User)
+	assert.Equal(t, models.LoginSAML, user.LoginType)
+	assert.Equal(t, source.ID, user.LoginSource)
+	assert.Equal(t, "jdoe-id", user.LoginName)
+	assert.Equal(t, "jdoe@example.com", user.Email)
+	assert.Equal(t, "John Doe", user.FullName)
+	models.AssertExistsAndLoadBean(t, &models.TeamUser{TeamID: 2, UID: user.ID})
+	session.MakeRequest(t, NewRequest(t, "GET", "/user/settings"), http.StatusOK)
+
+	// the user is found again by the NameID and synchronized
+	session = emptyTestSession(t)
+	resp = samlLogin(t, session, idp, "samltest", "jdoe-id", map[string][]string{
+		"uid":         {"renamed"},
+		"displayName": {"John Renamed"},
+	})
+	assert.Equal(t, "/", resp.Header().Get("Location"))
+	models.AssertExistsAndLoadBean(t, &models.User{ID: user.ID, Name: "jdoe", FullName: "John Renamed"})
+
+	// responses without a pending request and of other identity providers are rejected
+	other, err := samltest.NewIdentityProvider(idp.EntityID, idp.SingleSignOnURL)
+	assert.NoError(t, err)
+	session = emptyTestSession(t)
+	resp = samlLogin(t, session, other, "samltest", "jdoe-id", nil)
+	assert.Equal(t, "/user/login", resp.Header().Get("Location"))
+	req = NewRequestWithValues(t, "POST", "/user/saml/samltest/acs", map[string]string{
+		"SAMLResponse": idp.Response(&samltest.Response{
+			InResponseTo: "_unsolicited",
+			Destination:  samlProviderURL("samltest") + "/acs",
+			Audience:     samlProviderURL("samltest") + "/metadata",
+			NameID:       "jdoe-id",
+		}),
+	})
+	resp = session.MakeRequest(t, req, http.StatusFound)
+	assert.Equal(t, "/user/login", resp.Header().Get("Location"))
+	session.MakeRequest(t, NewRequest(t, "GET", "/user/settings"), http.StatusFound)
+}
+
+func TestSAMLLoginLinkAccount(t *testing.T) {
+	prepareTestEnv(t)
+
+	idp, err := samltest.NewIdentityProvider("https://idp.example.com/metadata", "https://idp.example.com/sso")
+	assert.NoError(t, err)
+	metadataServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(idp.Metadata())
+	}))
+	defer metadataServer.Close()
+
+	source := addAuthSourceSAML(t, loginUser(t, "user1"), "samllink", metadataServer.URL, false)
+
+	// without auto registration the user links an existing account
+	session := emptyTestSession(t)
+	resp := samlLogin(t, session, idp, "samllink", "user2-id", map[string][]string{"uid": {"user2"}})
+	assert.Equal(t, "/user/link_account", resp.Header().Get("Location"))
+	models.AssertNotExistsBean(t, &models.User{LoginType: models.LoginSAML})
+
+	req := NewRequestWithValues(t, "POST", "/user/link_account_signin", map[string]string{
+		"_csrf":     GetCSRF(t, session, "/user/link_account"),
+		"user_name": "user2",
+		"password":  userPassword,
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	models.AssertExistsAndLoadBean(t, &models.ExternalLoginUser{ExternalID: "user2-id", UserID: 2, LoginSourceID: source.ID})
+
+	// the linked account is used for the next login
+	session = emptyTestSession(t)
+	resp = samlLogin(t, session, idp, "samllink", "user2-id", nil)
+	assert.Equal(t, "/", resp.Header().Get("Location"))
+	resp = session.MakeRequest(t, NewRequest(t, "GET", "/user/settings"), http.StatusOK)
+	assert.Equal(t, "user2", NewHTMLParser(t, resp.Body).GetInputValueByName("name"))
+}
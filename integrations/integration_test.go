@@ -278,12 +278,17 @@ func loginUserWithPassword(t testing.TB, userName, password string) *TestSession
 }
 
 func getTokenForLoggedInUser(t testing.TB, session *TestSession) string {
+	return getScopedTokenForLoggedInUser(t, session, string(models.AccessTokenScopeAll))
+}
+
+func getScopedTokenForLoggedInUser(t testing.TB, session *TestSession, scope string) string {
 	req := NewRequest(t, "GET", "/user/settings/applications")
 	resp := session.MakeRequest(t, req, http.StatusOK)
 	doc := NewHTMLParser(t, resp.Body)
 	req = NewRequestWithValues(t, "POST", "/user/settings/applications", map[string]string{
 		"_csrf": doc.GetCSRF(),
 		"name":  "api-testing-token",
+		"scope": scope,
 	})
 	resp = session.MakeRequest(t, req, http.StatusFound)
 	req = NewRequest(t, "GET", "/user/settings/applications")
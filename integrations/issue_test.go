@@ -5,6 +5,7 @@
 package integrations
 
 import (
+	"encoding/csv"
 	"net/http"
 	"path"
 	"strconv"
@@ -184,3 +185,64 @@ func TestIssueCommentClose(t *testing.T) {
 	val := htmlDoc.doc.Find(".comment-list .comments .comment .render-content p").First().Text()
 	assert.Equal(t, "Description", val)
 }
+
+func TestIssueBulkEdit(t *testing.T) {
+	prepareTestEnv(t)
+	session := loginUser(t, "user2")
+
+	req := NewRequest(t, "GET", "/user2/repo1/issues")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	link, exists := htmlDoc.doc.Find("#bulk-edit-issues-form").Attr("action")
+	assert.True(t, exists, "The template has changed")
+
+	req = NewRequestWithValues(t, "POST", link, map[string]string{
+		"_csrf":         htmlDoc.GetCSRF(),
+		"issue_ids":     "1,5",
+		"add_label_ids": "2",
+		"milestone_id":  "1",
+		"state":         "closed",
+	})
+	resp = session.MakeRequest(t, req, http.StatusFound)
+	assert.EqualValues(t, "/user2/repo1/issues", test.RedirectURL(resp))
+
+	for _, id := range []int64{1, 5} {
+		issue := models.AssertExistsAndLoadBean(t, &models.Issue{ID: id, MilestoneID: 1}).(*models.Issue)
+		assert.True(t, issue.IsClosed)
+		models.AssertExistsAndLoadBean(t, &models.IssueLabel{IssueID: id, LabelID: 2})
+	}
+}
+
+func TestExportIssues(t *testing.T) {
+	prepareTestEnv(t)
+	session := loginUser(t, "user2")
+
+	req := NewRequest(t, "GET", "/user2/repo1/issues/export?q=field:Priority=High")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	assert.EqualValues(t, "text/csv; charset=utf-8", resp.HeaderMap.Get("Content-Type"))
+
+	records, err := csv.NewReader(resp.Body).ReadAll()
+	assert.NoError(t, err)
+	if assert.Len(t, records, 2) {
+		assert.EqualValues(t, "Priority", records[0][len(records[0])-1])
+		assert.EqualValues(t, "1", records[1][0])
+		assert.EqualValues(t, "High", records[1][len(records[1])-1])
+	}
+}
+
+func TestMilestoneBurndown(t *testing.T) {
+	prepareTestEnv(t)
+	session := loginUser(t, "user2")
+
+	req := NewRequest(t, "GET", "/user2/repo1/milestone/1")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	htmlDoc.AssertElement(t, "#milestone-burndown polyline.open", true)
+	htmlDoc.AssertElement(t, "#milestone-burnup polyline.closed", true)
+
+	// closed milestones without snapshots have no history
+	req = NewRequest(t, "GET", "/user2/repo1/milestone/3")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc = NewHTMLParser(t, resp.Body)
+	htmlDoc.AssertElement(t, "#milestone-burndown", false)
+}
@@ -78,6 +78,8 @@ func testLinksAsUser(userName string, t *testing.T) {
 		"/issues?type=your_repositories&repo=0&sort=&state=closed",
 		"/issues?type=assigned&repo=0&sort=&state=closed",
 		"/issues?type=created_by&repo=0&sort=&state=closed",
+		"/issues?type=your_repositories&repo=0&sort=&state=open&q=is%3Aopen+label%3Alabel1",
+		"/issues?filter=1",
 		"/pulls",
 		"/pulls?type=your_repositories&repo=0&sort=&state=open",
 		"/pulls?type=assigned&repo=0&sort=&state=open",
@@ -5,11 +5,17 @@
 package integrations
 
 import (
+	"crypto/rsa"
+	"encoding/base64"
 	"encoding/json"
+	"math/big"
+	"strings"
 	"testing"
 
+	"github.com/masoodkamyab/gitea/models"
 	"github.com/masoodkamyab/gitea/modules/setting"
 
+	"github.com/dgrijalva/jwt-go"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -50,6 +56,48 @@ func TestRedirectWithExistingGrant(t *testing.T) {
 	assert.Truef(t, len(u.Query().Get("code")) > 30, "authorization code '%s' should be longer then 30", u.Query().Get("code"))
 }
 
+func TestRedirectWithBroaderGrant(t *testing.T) {
+	prepareTestEnv(t)
+	// user1 granted all scopes before, the client asks for less now
+	ctx := loginUser(t, "user1")
+	req := NewRequest(t, "GET", defaultAuthorize+"&scope=repo:read")
+	resp := ctx.MakeRequest(t, req, 302)
+	u, err := resp.Result().Location()
+	assert.NoError(t, err)
+
+	req = NewRequestWithValues(t, "POST", "/login/oauth/access_token", map[string]string{
+		"grant_type":    "authorization_code",
+		"client_id":     "da7da3ba-9a13-4167-856f-3899de0b0138",
+		"client_secret": "4MK8Na6R55smdCY0WuCCumZ6hjRPnGY5saWVRHHjJiA=",
+		"redirect_uri":  "a",
+		"code":          u.Query().Get("code"),
+	})
+	resp = MakeRequest(t, req, 200)
+	var parsed struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+	}
+	DecodeJSON(t, resp, &parsed)
+
+	// the token only has the requested scope, also after refreshing it
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1?access_token=%s", parsed.AccessToken)
+	MakeRequest(t, req, 200)
+	req = NewRequestf(t, "GET", "/api/v1/user/emails?access_token=%s", parsed.AccessToken)
+	MakeRequest(t, req, 403)
+
+	req = NewRequestWithValues(t, "POST", "/login/oauth/access_token", map[string]string{
+		"grant_type":    "refresh_token",
+		"client_id":     "da7da3ba-9a13-4167-856f-3899de0b0138",
+		"client_secret": "4MK8Na6R55smdCY0WuCCumZ6hjRPnGY5saWVRHHjJiA=",
+		"redirect_uri":  "a",
+		"refresh_token": parsed.RefreshToken,
+	})
+	resp = MakeRequest(t, req, 200)
+	DecodeJSON(t, resp, &parsed)
+	req = NewRequestf(t, "GET", "/api/v1/user/emails?access_token=%s", parsed.AccessToken)
+	MakeRequest(t, req, 403)
+}
+
 func TestAccessTokenExchange(t *testing.T) {
 	prepareTestEnv(t)
 	req := NewRequestWithValues(t, "POST", "/login/oauth/access_token", map[string]string{
@@ -241,3 +289,149 @@ func TestRefreshTokenInvalidation(t *testing.T) {
 	MakeRequest(t, refreshReq, 200)
 	MakeRequest(t, refreshReq, 400)
 }
+
+func TestAuthorizeScope(t *testing.T) {
+	prepareTestEnv(t)
+	ctx := loginUser(t, "user4")
+	req := NewRequest(t, "GET", defaultAuthorize+"&scope=openid%20repo:read")
+	resp := ctx.MakeRequest(t, req, 200)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	assert.EqualValues(t, "openidrepo:read", htmlDoc.doc.Find(".ui.list .item code").Text())
+
+	req = NewRequestWithValues(t, "POST", "/login/oauth/grant", map[string]string{
+		"_csrf":        htmlDoc.GetCSRF(),
+		"client_id":    "da7da3ba-9a13-4167-856f-3899de0b0138",
+		"state":        "thestate",
+		"redirect_uri": "a",
+	})
+	ctx.MakeRequest(t, req, 302)
+	models.AssertExistsAndLoadBean(t, &models.OAuth2Grant{UserID: 4, ApplicationID: 1, Scope: models.AccessTokenScopeRepoRead, OpenIDScope: models.OpenIDScopeOpenID})
+
+	// the granted scope is not asked for again, a broader one is
+	req = NewRequest(t, "GET", defaultAuthorize+"&scope=repo:read")
+	ctx.MakeRequest(t, req, 302)
+	req = NewRequest(t, "GET", defaultAuthorize+"&scope=repo:write")
+	ctx.MakeRequest(t, req, 200)
+}
+
+func TestOpenIDConnect(t *testing.T) {
+	prepareTestEnv(t)
+
+	req := NewRequest(t, "GET", "/.well-known/openid-configuration")
+	resp := MakeRequest(t, req, 200)
+	var configuration map[string]interface{}
+	DecodeJSON(t, resp, &configuration)
+	assert.Equal(t, strings.TrimSuffix(setting.AppURL, "/"), configuration["issuer"])
+	assert.Equal(t, setting.AppURL+"login/oauth/keys", configuration["jwks_uri"])
+
+	req = NewRequest(t, "GET", "/login/oauth/keys")
+	resp = MakeRequest(t, req, 200)
+	var keySet models.JSONWebKeySet
+	DecodeJSON(t, resp, &keySet)
+	assert.Len(t, keySet.Keys, 1)
+	modulus, err := base64.RawURLEncoding.DecodeString(keySet.Keys[0].Modulus)
+	assert.NoError(t, err)
+	exponent, err := base64.RawURLEncoding.DecodeString(keySet.Keys[0].Exponent)
+	assert.NoError(t, err)
+	publicKey := &rsa.PublicKey{N: new(big.Int).SetBytes(modulus), E: int(new(big.Int).SetBytes(exponent).Int64())}
+
+	// the PKCE code challenge is kept while the user grants access
+	ctx := loginUser(t, "user4")
+	req = NewRequest(t, "GET", defaultAuthorize+"&scope=openid%20profile%20email&nonce=thenonce"+
+		"&code_challenge_method=S256&code_challenge=CjvyTLSdR47G5zYenDA-eDWW4lRrO8yvjcWwbD_deOg")
+	resp = ctx.MakeRequest(t, req, 200)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	assert.EqualValues(t, "openidprofileemail", htmlDoc.doc.Find(".ui.list .item code").Text())
+	req = NewRequestWithValues(t, "POST", "/login/oauth/grant", map[string]string{
+		"_csrf":        htmlDoc.GetCSRF(),
+		"client_id":    "da7da3ba-9a13-4167-856f-3899de0b0138",
+		"state":        "thestate",
+		"redirect_uri": "a",
+	})
+	resp = ctx.MakeRequest(t, req, 302)
+	u, err := resp.Result().Location()
+	assert.NoError(t, err)
+	models.AssertExistsAndLoadBean(t, &models.OAuth2AuthorizationCode{Code: u.Query().Get("code"), CodeChallengeMethod: "S256", Nonce: "thenonce"})
+
+	tokenValues := map[string]string{
+		"grant_type":    "authorization_code",
+		"client_id":     "da7da3ba-9a13-4167-856f-3899de0b0138",
+		"client_secret": "4MK8Na6R55smdCY0WuCCumZ6hjRPnGY5saWVRHHjJiA=",
+		"redirect_uri":  "a",
+		"code":          u.Query().Get("code"),
+		"code_verifier": "wrong",
+	}
+	req = NewRequestWithValues(t, "POST", "/login/oauth/access_token", tokenValues)
+	MakeRequest(t, req, 400)
+	tokenValues["code_verifier"] = "N1Zo9-8Rfwhkt68r1r29ty8YwIraXR8eh_1Qwxg7yQXsonBt"
+	req = NewRequestWithValues(t, "POST", "/login/oauth/access_token", tokenValues)
+	resp = MakeRequest(t, req, 200)
+	var parsed struct {
+		AccessToken string `json:"access_token"`
+		IDToken     string `json:"id_token"`
+	}
+	DecodeJSON(t, resp, &parsed)
+
+	// the id_token is signed with the published key
+	idToken, err := jwt.Parse(parsed.IDToken, func(token *jwt.Token) (interface{}, error) {
+		assert.Equal(t, keySet.Keys[0].KeyID, token.Header["kid"])
+		return publicKey, nil
+	})
+	assert.NoError(t, err)
+	claims := idToken.Claims.(jwt.MapClaims)
+	assert.Equal(t, configuration["issuer"], claims["iss"])
+	assert.Equal(t, "da7da3ba-9a13-4167-856f-3899de0b0138", claims["aud"])
+	assert.Equal(t, "4", claims["sub"])
+	assert.Equal(t, "thenonce", claims["nonce"])
+	assert.Equal(t, "user4", claims["preferred_username"])
+	assert.Equal(t, "user4@example.com", claims["email"])
+
+	req = NewRequest(t, "GET", "/login/oauth/userinfo")
+	MakeRequest(t, req, 401)
+	req = NewRequest(t, "GET", "/login/oauth/userinfo")
+	req.Header.Set("Authorization", "Bearer "+parsed.AccessToken)
+	resp = MakeRequest(t, req, 200)
+	var userInfo models.OIDCClaims
+	DecodeJSON(t, resp, &userInfo)
+	assert.Equal(t, "4", userInfo.Subject)
+	assert.Equal(t, "user4", userInfo.PreferredUsername)
+	assert.Equal(t, "user4@example.com", userInfo.Email)
+	assert.Nil(t, userInfo.Groups)
+
+	// an OpenID Connect request without other scopes only signs the user in
+	req = NewRequestf(t, "GET", "/api/v1/user?access_token=%s", parsed.AccessToken)
+	MakeRequest(t, req, 200)
+	req = NewRequestf(t, "GET", "/api/v1/user/emails?access_token=%s", parsed.AccessToken)
+	MakeRequest(t, req, 403)
+	req = NewRequest(t, "GET", "/user3/repo3/info/refs")
+	req.SetBasicAuth(parsed.AccessToken, "x-oauth-basic")
+	MakeRequest(t, req, 403)
+}
+
+func TestOAuth2PublicClient(t *testing.T) {
+	prepareTestEnv(t)
+	assert.NoError(t, models.UpdateOAuth2Application(models.UpdateOAuth2ApplicationOptions{
+		ID:           1,
+		Name:         "Test",
+		UserID:       1,
+		RedirectURIs: []string{"a"},
+	}))
+
+	// public clients have to use PKCE
+	ctx := loginUser(t, "user1")
+	req := NewRequest(t, "GET", defaultAuthorize)
+	resp := ctx.MakeRequest(t, req, 302)
+	u, err := resp.Result().Location()
+	assert.NoError(t, err)
+	assert.Equal(t, "invalid_request", u.Query().Get("error"))
+
+	// and authenticate with the code verifier instead of the client secret
+	req = NewRequestWithValues(t, "POST", "/login/oauth/access_token", map[string]string{
+		"grant_type":    "authorization_code",
+		"client_id":     "da7da3ba-9a13-4167-856f-3899de0b0138",
+		"redirect_uri":  "a",
+		"code":          "authcode",
+		"code_verifier": "N1Zo9-8Rfwhkt68r1r29ty8YwIraXR8eh_1Qwxg7yQXsonBt",
+	})
+	MakeRequest(t, req, 200)
+}
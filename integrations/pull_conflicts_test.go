@@ -0,0 +1,144 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/masoodkamyab/gitea/models"
+	"github.com/masoodkamyab/gitea/modules/git"
+	"github.com/masoodkamyab/gitea/modules/repofiles"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testConflictsCommitFile(t *testing.T, doer *models.User, repo *models.Repository, oldBranch, newBranch, treePath, content string, isNew bool) string {
+	gitRepo, err := git.OpenRepository(repo.RepoPath())
+	assert.NoError(t, err)
+	lastCommitID, err := gitRepo.GetBranchCommitID(oldBranch)
+	assert.NoError(t, err)
+
+	resp, err := repofiles.CreateOrUpdateRepoFile(repo, doer, &repofiles.UpdateRepoFileOptions{
+		LastCommitID: lastCommitID,
+		OldBranch:    oldBranch,
+		NewBranch:    newBranch,
+		TreePath:     treePath,
+		Message:      "Update " + treePath,
+		Content:      content,
+		IsNewFile:    isNew,
+	})
+	assert.NoError(t, err)
+	return resp.Commit.SHA
+}
+
+// testPrepareConflicts creates a branch conflicting of user2/repo1 which conflicts with master
+// in conflict.txt, and which deletes deleted.txt while master leaves it unchanged.
+func testPrepareConflicts(t *testing.T) (*models.User, *models.Repository, string) {
+	user := models.AssertExistsAndLoadBean(t, &models.User{Name: "user2"}).(*models.User)
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{OwnerID: user.ID, Name: "repo1"}).(*models.Repository)
+
+	testConflictsCommitFile(t, user, repo, "master", "master", "conflict.txt", "a\nb\nc\n", true)
+	testConflictsCommitFile(t, user, repo, "master", "master", "deleted.txt", "x\n", true)
+	testConflictsCommitFile(t, user, repo, "master", "conflicting", "conflict.txt", "a\nconflicting\nc\n", false)
+
+	gitRepo, err := git.OpenRepository(repo.RepoPath())
+	assert.NoError(t, err)
+	lastCommitID, err := gitRepo.GetBranchCommitID("conflicting")
+	assert.NoError(t, err)
+	_, err = repofiles.DeleteRepoFile(repo, user, &repofiles.DeleteRepoFileOptions{
+		LastCommitID: lastCommitID,
+		OldBranch:    "conflicting",
+		NewBranch:    "conflicting",
+		TreePath:     "deleted.txt",
+		Message:      "Delete deleted.txt",
+	})
+	assert.NoError(t, err)
+
+	mergeCommitID := testConflictsCommitFile(t, user, repo, "master", "master", "conflict.txt", "a\nmaster\nc\n", false)
+	return user, repo, mergeCommitID
+}
+
+func TestGetMergeConflicts(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		_, repo, mergeCommitID := testPrepareConflicts(t)
+
+		conflicts, err := repofiles.GetMergeConflicts(repo, &repofiles.MergeOptions{
+			Branch:        "conflicting",
+			MergeRepo:     repo,
+			MergeBranch:   "master",
+			MergeCommitID: mergeCommitID,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, mergeCommitID, conflicts.MergeCommitID)
+		// A file deleted on one side and unchanged on the other merges cleanly
+		if assert.Len(t, conflicts.Files, 1) {
+			file := conflicts.Files[0]
+			assert.Equal(t, "conflict.txt", file.TreePath)
+			assert.True(t, file.CanResolve)
+			assert.Equal(t, "a\n<<<<<<< conflicting\nconflicting\n=======\nmaster\n>>>>>>> master\nc\n", file.Content)
+			assert.Len(t, file.Sections, 3)
+		}
+	})
+}
+
+func TestResolveMergeConflicts(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		user, repo, mergeCommitID := testPrepareConflicts(t)
+		gitRepo, err := git.OpenRepository(repo.RepoPath())
+		assert.NoError(t, err)
+		headCommitID, err := gitRepo.GetBranchCommitID("conflicting")
+		assert.NoError(t, err)
+
+		opts := func(resolutions map[string]string) *repofiles.MergeOptions {
+			return &repofiles.MergeOptions{
+				Branch:        "conflicting",
+				LastCommitID:  headCommitID,
+				MergeRepo:     repo,
+				MergeBranch:   "master",
+				MergeCommitID: mergeCommitID,
+				Resolutions:   resolutions,
+			}
+		}
+
+		_, err = repofiles.ResolveMergeConflicts(repo, user, opts(nil))
+		assert.True(t, models.IsErrMergeConflictUnresolved(err))
+		_, err = repofiles.ResolveMergeConflicts(repo, user, opts(map[string]string{
+			"conflict.txt": "a\n<<<<<<< conflicting\nconflicting\n=======\nmaster\n>>>>>>> master\nc\n",
+		}))
+		assert.True(t, models.IsErrMergeConflictUnresolved(err))
+
+		commitID, err := repofiles.ResolveMergeConflicts(repo, user, opts(map[string]string{
+			"conflict.txt": "a\nresolved\nc\n",
+		}))
+		assert.NoError(t, err)
+
+		// The merge commit has been pushed to the branch
+		commit, err := gitRepo.GetBranchCommit("conflicting")
+		assert.NoError(t, err)
+		assert.Equal(t, commitID, commit.ID.String())
+		if assert.Equal(t, 2, commit.ParentCount()) {
+			parentID, err := commit.ParentID(0)
+			assert.NoError(t, err)
+			assert.Equal(t, headCommitID, parentID.String())
+			parentID, err = commit.ParentID(1)
+			assert.NoError(t, err)
+			assert.Equal(t, mergeCommitID, parentID.String())
+		}
+		entry, err := commit.GetTreeEntryByPath("conflict.txt")
+		assert.NoError(t, err)
+		content, err := entry.Blob().GetBlobContent()
+		assert.NoError(t, err)
+		assert.Equal(t, "a\nresolved\nc\n", content)
+		_, err = commit.GetTreeEntryByPath("deleted.txt")
+		assert.True(t, git.IsErrNotExist(err))
+
+		// The head has moved on
+		_, err = repofiles.ResolveMergeConflicts(repo, user, opts(map[string]string{
+			"conflict.txt": "a\nresolved\nc\n",
+		}))
+		assert.True(t, models.IsErrCommitIDDoesNotMatch(err))
+	})
+}
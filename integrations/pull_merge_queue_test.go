@@ -0,0 +1,129 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/masoodkamyab/gitea/models"
+	"github.com/masoodkamyab/gitea/modules/git"
+	"github.com/masoodkamyab/gitea/modules/pull"
+	api "github.com/masoodkamyab/gitea/modules/structs"
+	"github.com/masoodkamyab/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testPullEnqueue enables the merge queue of the master branch of user2/repo1 and queues
+// a pull request from the fork of user1, it returns the queue entry once the speculative
+// merge commit has been created.
+func testPullEnqueue(t *testing.T) (*models.Repository, *models.PullRequest, *models.MergeQueueEntry) {
+	ownerSession := loginUser(t, "user2")
+	req := NewRequestWithValues(t, "POST", "/user2/repo1/settings/branches/master", map[string]string{
+		"_csrf":              GetCSRF(t, ownerSession, "/user2/repo1/settings/branches"),
+		"protected":          "on",
+		"enable_merge_queue": "on",
+	})
+	ownerSession.MakeRequest(t, req, http.StatusFound)
+
+	session := loginUser(t, "user1")
+	testRepoFork(t, session, "user2", "repo1", "user1", "repo1")
+	testEditFile(t, session, "user1", "repo1", "master", "README.md", "Hello, World (Queued)\n")
+	resp := testPullCreate(t, session, "user1", "repo1", "master", "This is a queued pull")
+	elem := strings.Split(test.RedirectURL(resp), "/")
+	testPullMerge(t, session, elem[1], elem[2], elem[4], models.MergeStyleMerge)
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{OwnerName: "user2", Name: "repo1"}).(*models.Repository)
+	issue := models.AssertExistsAndLoadBean(t, &models.Issue{RepoID: repo.ID, Title: "This is a queued pull"}).(*models.Issue)
+	pr := models.AssertExistsAndLoadBean(t, &models.PullRequest{IssueID: issue.ID}).(*models.PullRequest)
+	assert.False(t, pr.HasMerged, "queued pull requests are merged once their checks pass")
+
+	// Wait for the speculative merge
+	pull.ProcessMergeQueue(repo, "master")
+	entry, err := models.GetMergeQueueEntryByPullID(pr.ID)
+	assert.NoError(t, err)
+	if assert.NotNil(t, entry) {
+		assert.NotEmpty(t, entry.CommitID)
+		gitRepo, err := git.OpenRepository(repo.RepoPath())
+		assert.NoError(t, err)
+		commitID, err := gitRepo.GetRefCommitID(pr.GetMergeQueueRefName())
+		assert.NoError(t, err)
+		assert.Equal(t, entry.CommitID, commitID)
+	}
+	return repo, pr, entry
+}
+
+func testMergeQueueStatus(t *testing.T, commitID string, state models.CommitStatusState) {
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+	req := NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/user2/repo1/statuses/%s?token=%s", commitID, token),
+		api.CreateStatusOption{
+			State:   api.StatusState(state),
+			Context: "testci",
+		},
+	)
+	session.MakeRequest(t, req, http.StatusCreated)
+}
+
+func TestPullMergeQueueLand(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, giteaURL *url.URL) {
+		repo, pr, entry := testPullEnqueue(t)
+		if entry == nil {
+			return
+		}
+
+		testMergeQueueStatus(t, entry.CommitID, models.CommitStatusPending)
+		pull.ProcessMergeQueue(repo, "master")
+		models.AssertExistsAndLoadBean(t, &models.MergeQueueEntry{PullID: pr.ID})
+
+		testMergeQueueStatus(t, entry.CommitID, models.CommitStatusSuccess)
+		pull.ProcessMergeQueue(repo, "master")
+		models.AssertNotExistsBean(t, &models.MergeQueueEntry{PullID: pr.ID})
+		pr = models.AssertExistsAndLoadBean(t, &models.PullRequest{ID: pr.ID}).(*models.PullRequest)
+		assert.True(t, pr.HasMerged)
+		assert.Equal(t, entry.CommitID, pr.MergedCommitID)
+
+		gitRepo, err := git.OpenRepository(repo.RepoPath())
+		assert.NoError(t, err)
+		commitID, err := gitRepo.GetBranchCommitID("master")
+		assert.NoError(t, err)
+		assert.Equal(t, entry.CommitID, commitID)
+		assert.False(t, git.IsReferenceExist(repo.RepoPath(), pr.GetMergeQueueRefName()))
+	})
+}
+
+func TestPullMergeQueueEject(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, giteaURL *url.URL) {
+		repo, pr, entry := testPullEnqueue(t)
+		if entry == nil {
+			return
+		}
+
+		gitRepo, err := git.OpenRepository(repo.RepoPath())
+		assert.NoError(t, err)
+		baseCommitID, err := gitRepo.GetBranchCommitID("master")
+		assert.NoError(t, err)
+
+		testMergeQueueStatus(t, entry.CommitID, models.CommitStatusFailure)
+		pull.ProcessMergeQueue(repo, "master")
+		models.AssertNotExistsBean(t, &models.MergeQueueEntry{PullID: pr.ID})
+		models.AssertExistsAndLoadBean(t, &models.Comment{
+			IssueID: pr.IssueID,
+			Type:    models.CommentTypeMergeQueueEject,
+			Content: models.MergeQueueEjectReasonChecksFailed,
+		})
+		pr = models.AssertExistsAndLoadBean(t, &models.PullRequest{ID: pr.ID}).(*models.PullRequest)
+		assert.False(t, pr.HasMerged)
+
+		commitID, err := gitRepo.GetBranchCommitID("master")
+		assert.NoError(t, err)
+		assert.Equal(t, baseCommitID, commitID)
+		assert.False(t, git.IsReferenceExist(repo.RepoPath(), pr.GetMergeQueueRefName()))
+	})
+}
@@ -0,0 +1,114 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/masoodkamyab/gitea/models"
+	"github.com/masoodkamyab/gitea/modules/git"
+	"github.com/masoodkamyab/gitea/modules/pull"
+	"github.com/masoodkamyab/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testPullUpdate(t *testing.T, session *TestSession, user, repo, pullnum string, style models.MergeStyle) {
+	req := NewRequestWithValues(t, "POST", path.Join(user, repo, "pulls", pullnum, "update"), map[string]string{
+		"_csrf": GetCSRF(t, session, "/user/settings"),
+		"style": string(style),
+	})
+	resp := session.MakeRequest(t, req, http.StatusFound)
+	assert.EqualValues(t, "/"+path.Join(user, repo, "pulls", pullnum), test.RedirectURL(resp))
+}
+
+func TestPullUpdate(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, giteaURL *url.URL) {
+		session := loginUser(t, "user1")
+		testRepoFork(t, session, "user2", "repo1", "user1", "repo1")
+		testEditFile(t, session, "user1", "repo1", "master", "README.md", "Hello, World (Edited)\n")
+		resp := testPullCreate(t, session, "user1", "repo1", "master", "This is a pull title")
+		elem := strings.Split(test.RedirectURL(resp), "/")
+
+		// Add a commit to the base branch the head branch lacks
+		baseOwner := models.AssertExistsAndLoadBean(t, &models.User{Name: "user2"}).(*models.User)
+		baseRepo := models.AssertExistsAndLoadBean(t, &models.Repository{OwnerID: baseOwner.ID, Name: "repo1"}).(*models.Repository)
+		_, err := createFile(baseOwner, baseRepo, "update.txt")
+		assert.NoError(t, err)
+
+		issue := models.AssertExistsAndLoadBean(t, &models.Issue{RepoID: baseRepo.ID, Title: "This is a pull title"}).(*models.Issue)
+		pr := models.AssertExistsAndLoadBean(t, &models.PullRequest{IssueID: issue.ID}).(*models.PullRequest)
+		behind, err := pull.CommitsBehind(pr)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, behind)
+
+		// The pull request page offers to update the branch
+		req := NewRequest(t, "GET", path.Join(elem[1], elem[2], "pulls", elem[4]))
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		htmlDoc := NewHTMLParser(t, resp.Body)
+		assert.EqualValues(t, 1, htmlDoc.doc.Find(`form[action$="/update"]`).Length())
+
+		testPullUpdate(t, session, elem[1], elem[2], elem[4], models.MergeStyleMerge)
+		flashCookie := session.GetCookie("macaron_flash")
+		if assert.NotNil(t, flashCookie) {
+			assert.Contains(t, flashCookie.Value, "success")
+		}
+		headOwner := models.AssertExistsAndLoadBean(t, &models.User{Name: "user1"}).(*models.User)
+		headRepo := models.AssertExistsAndLoadBean(t, &models.Repository{OwnerID: headOwner.ID, Name: "repo1"}).(*models.Repository)
+		headGitRepo, err := git.OpenRepository(headRepo.RepoPath())
+		assert.NoError(t, err)
+		headCommit, err := headGitRepo.GetBranchCommit("master")
+		assert.NoError(t, err)
+		_, err = headCommit.GetTreeEntryByPath("update.txt")
+		assert.NoError(t, err, "the base branch has been merged into the head branch")
+
+		// The head repository is gone, so the branch cannot be updated any more
+		_, err = createFile(baseOwner, baseRepo, "update2.txt")
+		assert.NoError(t, err)
+		assert.NoError(t, models.DeleteRepository(headOwner, headOwner.ID, headRepo.ID))
+
+		testPullUpdate(t, session, elem[1], elem[2], elem[4], models.MergeStyleMerge)
+		flashCookie = session.GetCookie("macaron_flash")
+		if assert.NotNil(t, flashCookie) {
+			assert.Contains(t, flashCookie.Value, "error")
+			assert.Contains(t, flashCookie.Value, url.QueryEscape(url.QueryEscape("repository has been deleted")))
+		}
+	})
+}
+
+func TestPullUpdateRebase(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, giteaURL *url.URL) {
+		session := loginUser(t, "user1")
+		testRepoFork(t, session, "user2", "repo1", "user1", "repo1")
+		testEditFile(t, session, "user1", "repo1", "master", "README.md", "Hello, World (Edited)\n")
+		resp := testPullCreate(t, session, "user1", "repo1", "master", "This is a pull title")
+		elem := strings.Split(test.RedirectURL(resp), "/")
+
+		baseOwner := models.AssertExistsAndLoadBean(t, &models.User{Name: "user2"}).(*models.User)
+		baseRepo := models.AssertExistsAndLoadBean(t, &models.Repository{OwnerID: baseOwner.ID, Name: "repo1"}).(*models.Repository)
+		_, err := createFile(baseOwner, baseRepo, "rebase.txt")
+		assert.NoError(t, err)
+
+		// Rebasing replaces the commits of the head branch
+		testPullUpdate(t, session, elem[1], elem[2], elem[4], models.MergeStyleRebase)
+		flashCookie := session.GetCookie("macaron_flash")
+		if assert.NotNil(t, flashCookie) {
+			assert.Contains(t, flashCookie.Value, "success")
+		}
+		headOwner := models.AssertExistsAndLoadBean(t, &models.User{Name: "user1"}).(*models.User)
+		headRepo := models.AssertExistsAndLoadBean(t, &models.Repository{OwnerID: headOwner.ID, Name: "repo1"}).(*models.Repository)
+		headGitRepo, err := git.OpenRepository(headRepo.RepoPath())
+		assert.NoError(t, err)
+		headCommit, err := headGitRepo.GetBranchCommit("master")
+		assert.NoError(t, err)
+		_, err = headCommit.GetTreeEntryByPath("rebase.txt")
+		assert.NoError(t, err, "the head branch has been rebased onto the base branch")
+		assert.EqualValues(t, 1, headCommit.ParentCount(), "the head branch has no merge commit")
+	})
+}
@@ -0,0 +1,91 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/masoodkamyab/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+)
+
+func newSSHCertificateAuthority(t *testing.T) ssh.Signer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(key)
+	assert.NoError(t, err)
+	return signer
+}
+
+// writeSSHCertificate signs the public key of keyFile and stores the
+// certificate next to it, where ssh picks it up automatically.
+func writeSSHCertificate(t *testing.T, keyFile string, ca ssh.Signer, principal string) {
+	content, err := ioutil.ReadFile(keyFile + ".pub")
+	assert.NoError(t, err)
+	key, _, _, _, err := ssh.ParseAuthorizedKey(content)
+	assert.NoError(t, err)
+
+	cert := &ssh.Certificate{
+		Key:             key,
+		Serial:          1,
+		CertType:        ssh.UserCert,
+		KeyId:           "integration-test-" + principal,
+		ValidPrincipals: []string{principal},
+		ValidAfter:      uint64(time.Now().Add(-time.Hour).Unix()),
+		ValidBefore:     uint64(time.Now().Add(time.Hour).Unix()),
+		Permissions: ssh.Permissions{
+			Extensions: map[string]string{"permit-pty": ""},
+		},
+	}
+	assert.NoError(t, cert.SignCert(rand.Reader, ca))
+	assert.NoError(t, ioutil.WriteFile(keyFile+"-cert.pub", ssh.MarshalAuthorizedKey(cert), 0600))
+}
+
+func TestSSHCertificateClone(t *testing.T) {
+	onGiteaRun(t, testSSHCertificateClone)
+}
+
+func testSSHCertificateClone(t *testing.T, u *url.URL) {
+	ca := newSSHCertificateAuthority(t)
+	defer func(keys []string) {
+		setting.SSH.TrustedUserCAKeys = keys
+	}(setting.SSH.TrustedUserCAKeys)
+	setting.SSH.TrustedUserCAKeys = []string{strings.TrimSpace(string(ssh.MarshalAuthorizedKey(ca.PublicKey())))}
+
+	// user3/repo3 is private, user2 has access to it through the Owners team
+	sshURL := createSSHUrl("user3/repo3.git", u)
+
+	withKeyFile(t, "ssh-cert-user2", func(keyFile string) {
+		defer os.RemoveAll(keyFile + "-cert.pub")
+
+		dstPath, err := ioutil.TempDir("", "repo-tmp-ssh-cert")
+		assert.NoError(t, err)
+		defer os.RemoveAll(dstPath)
+
+		t.Run("Untrusted", func(t *testing.T) {
+			writeSSHCertificate(t, keyFile, newSSHCertificateAuthority(t), "user2")
+			doGitCloneFail(dstPath, sshURL)(t)
+		})
+
+		t.Run("Unauthorized", func(t *testing.T) {
+			writeSSHCertificate(t, keyFile, ca, "user5")
+			doGitCloneFail(dstPath, sshURL)(t)
+		})
+
+		t.Run("Clone", func(t *testing.T) {
+			writeSSHCertificate(t, keyFile, ca, "user2@example.com")
+			doGitClone(dstPath, sshURL)(t)
+		})
+	})
+}
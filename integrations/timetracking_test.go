@@ -5,11 +5,14 @@
 package integrations
 
 import (
+	"encoding/csv"
 	"net/http"
 	"path"
 	"testing"
 	"time"
 
+	"github.com/masoodkamyab/gitea/models"
+	api "github.com/masoodkamyab/gitea/modules/structs"
 	"github.com/masoodkamyab/gitea/modules/test"
 
 	"github.com/stretchr/testify/assert"
@@ -78,3 +81,119 @@ func testViewTimetrackingControls(t *testing.T, session *TestSession, user, repo
 		session.MakeRequest(t, req, http.StatusNotFound)
 	}
 }
+
+func TestTimetrackingReport(t *testing.T) {
+	prepareTestEnv(t)
+	session := loginUser(t, "user2")
+
+	req := NewRequest(t, "GET", "/user2/repo1/times")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	assert.EqualValues(t, 4, htmlDoc.doc.Find("#tracked-times tbody tr").Length())
+	assert.EqualValues(t, 2, htmlDoc.doc.Find("#tracked-time-groups tbody tr").Length())
+
+	req = NewRequest(t, "GET", "/user2/repo1/times?group=label&user=user2&format=json")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var report api.TrackedTimeReport
+	DecodeJSON(t, resp, &report)
+	assert.EqualValues(t, "label", report.GroupBy)
+	assert.EqualValues(t, 3663, report.Total)
+	assert.Len(t, report.Times, 3)
+	assert.Len(t, report.Groups, 2)
+
+	req = NewRequest(t, "GET", "/user2/repo1/times?since=1999-12-31&until=1999-12-31&format=csv")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	records, err := csv.NewReader(resp.Body).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+
+	req = NewRequest(t, "GET", "/user/times?format=json")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &report)
+	assert.Len(t, report.Times, 3)
+
+	req = NewRequest(t, "GET", "/org/user3/times")
+	session.MakeRequest(t, req, http.StatusOK)
+
+	// time tracking is disabled in user3/repo3
+	req = NewRequest(t, "GET", "/user3/repo3/times")
+	session.MakeRequest(t, req, http.StatusNotFound)
+}
+
+func TestTimetrackingEditAndDelete(t *testing.T) {
+	prepareTestEnv(t)
+
+	// user4 can only change their own times
+	session := loginUser(t, "user4")
+	req := NewRequestWithValues(t, "POST", "/user2/repo1/times/2/edit", map[string]string{
+		"_csrf": GetCSRF(t, session, "/user/settings"),
+		"hours": "1",
+	})
+	session.MakeRequest(t, req, http.StatusForbidden)
+
+	// administrators can change all times
+	session = loginUser(t, "user2")
+	csrf := GetCSRF(t, session, "/user2/repo1/times")
+	req = NewRequestWithValues(t, "POST", "/user2/repo1/times/1/edit", map[string]string{
+		"_csrf":       csrf,
+		"hours":       "1",
+		"minutes":     "30",
+		"redirect_to": "/user2/repo1/times?group=issue",
+	})
+	resp := session.MakeRequest(t, req, http.StatusFound)
+	assert.EqualValues(t, "/user2/repo1/times?group=issue", test.RedirectURL(resp))
+	models.AssertExistsAndLoadBean(t, &models.TrackedTime{ID: 1, Time: 5400})
+
+	req = NewRequestWithValues(t, "POST", "/user2/repo1/times/1/delete", map[string]string{
+		"_csrf": csrf,
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	models.AssertNotExistsBean(t, &models.TrackedTime{ID: 1})
+}
+
+func TestIssueEstimate(t *testing.T) {
+	prepareTestEnv(t)
+	session := loginUser(t, "user2")
+
+	req := NewRequest(t, "GET", "/user2/repo1/issues/1")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	htmlDoc.AssertElement(t, "#issue-estimate form", true)
+
+	req = NewRequestWithValues(t, "POST", "/user2/repo1/issues/1/estimate", map[string]string{
+		"_csrf":    htmlDoc.GetCSRF(),
+		"estimate": "1h 30min",
+	})
+	session.MakeRequest(t, req, http.StatusSeeOther)
+	models.AssertExistsAndLoadBean(t, &models.Issue{ID: 1, EstimatedTime: 5400})
+	models.AssertExistsAndLoadBean(t, &models.Comment{IssueID: 1, Type: models.CommentTypeChangeEstimate, Content: "1h 30min"})
+
+	req = NewRequestWithValues(t, "POST", "/user2/repo1/issues/1/estimate", map[string]string{
+		"_csrf":    htmlDoc.GetCSRF(),
+		"estimate": "soon",
+	})
+	session.MakeRequest(t, req, http.StatusSeeOther)
+	models.AssertExistsAndLoadBean(t, &models.Issue{ID: 1, EstimatedTime: 5400})
+
+	// a comment consisting only of the quick action only changes the estimate
+	req = NewRequestWithValues(t, "POST", "/user2/repo1/issues/1/comments", map[string]string{
+		"_csrf":   htmlDoc.GetCSRF(),
+		"content": "/estimate 2h",
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	models.AssertExistsAndLoadBean(t, &models.Issue{ID: 1, EstimatedTime: 7200})
+	models.AssertNotExistsBean(t, &models.Comment{IssueID: 1, Type: models.CommentTypeComment, Content: "/estimate 2h"})
+
+	req = NewRequest(t, "GET", "/user2/repo1/issues")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc = NewHTMLParser(t, resp.Body)
+	htmlDoc.AssertElement(t, ".issue.list .estimate", true)
+
+	// user4 can only read the repository
+	session = loginUser(t, "user4")
+	req = NewRequestWithValues(t, "POST", "/user2/repo1/issues/1/estimate", map[string]string{
+		"_csrf":    GetCSRF(t, session, "/user/settings"),
+		"estimate": "1h",
+	})
+	session.MakeRequest(t, req, http.StatusNotFound)
+}
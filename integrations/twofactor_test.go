@@ -0,0 +1,173 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/masoodkamyab/gitea/models"
+	"github.com/masoodkamyab/gitea/modules/setting"
+	"github.com/masoodkamyab/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTwoFactorScratchCodeLogin(t *testing.T) {
+	prepareTestEnv(t)
+
+	twofa := &models.TwoFactor{UID: 5}
+	assert.NoError(t, twofa.SetSecret("JBSWY3DPEHPK3PXP"))
+	codes, err := twofa.GenerateScratchCodes()
+	assert.NoError(t, err)
+	assert.NoError(t, models.NewTwoFactor(twofa))
+	defer func() {
+		assert.NoError(t, models.DeleteTwoFactorByID(twofa.ID, 5))
+	}()
+
+	loginWithScratchCode := func(code string, expectedStatus int) *httptest.ResponseRecorder {
+		session := emptyTestSession(t)
+		req := NewRequestWithValues(t, "POST", "/user/login", map[string]string{
+			"_csrf":     GetCSRF(t, session, "/user/login"),
+			"user_name": "user5",
+			"password":  userPassword,
+		})
+		resp := session.MakeRequest(t, req, http.StatusFound)
+		assert.Equal(t, "/user/two_factor", resp.Header().Get("Location"))
+
+		req = NewRequestWithValues(t, "POST", "/user/two_factor/scratch", map[string]string{
+			"_csrf": GetCSRF(t, session, "/user/two_factor/scratch"),
+			"token": code,
+		})
+		return session.MakeRequest(t, req, expectedStatus)
+	}
+
+	resp := loginWithScratchCode(codes[0], http.StatusFound)
+	assert.Equal(t, "/user/settings/security", resp.Header().Get("Location"))
+	loginWithScratchCode(codes[1], http.StatusFound)
+
+	// Scratch codes are single-use
+	loginWithScratchCode(codes[0], http.StatusOK)
+
+	left, err := twofa.CountScratchCodes()
+	assert.NoError(t, err)
+	assert.EqualValues(t, models.TwoFactorScratchCodeCount-2, left)
+}
+
+func TestOrgRequireTwoFactor(t *testing.T) {
+	prepareTestEnv(t)
+
+	// Owners must be enrolled themselves to enable the policy
+	session := loginUser(t, "user2")
+	values := map[string]string{
+		"_csrf":              GetCSRF(t, session, "/org/user3/settings"),
+		"name":               "user3",
+		"visibility":         "0",
+		"require_two_factor": "on",
+	}
+	session.MakeRequest(t, NewRequestWithValues(t, "POST", "/org/user3/settings", values), http.StatusOK)
+	models.AssertExistsAndLoadBean(t, &models.User{ID: 3, RequireTwoFactor: false})
+
+	twofa := enrollTwoFactor(t, 2)
+	defer func() {
+		assert.NoError(t, models.DeleteTwoFactorByID(twofa.ID, 2))
+	}()
+	session.MakeRequest(t, NewRequestWithValues(t, "POST", "/org/user3/settings", values), http.StatusFound)
+	org := models.AssertExistsAndLoadBean(t, &models.User{ID: 3, RequireTwoFactor: true}).(*models.User)
+	assert.True(t, org.TwoFactorDeadlineUnix > util.TimeStampNow())
+
+	// Members keep their access during the grace period
+	member := loginUser(t, "user4")
+	member.MakeRequest(t, NewRequest(t, "GET", "/user3/repo3"), http.StatusOK)
+
+	org.TwoFactorDeadlineUnix = util.TimeStampNow().Add(-1)
+	assert.NoError(t, models.UpdateUserCols(org, "two_factor_deadline_unix"))
+	resp := member.MakeRequest(t, NewRequest(t, "GET", "/user3/repo3"), http.StatusFound)
+	assert.Equal(t, "/user/settings/security", resp.Header().Get("Location"))
+	member.MakeRequest(t, NewRequest(t, "GET", "/api/v1/repos/user3/repo3"), http.StatusNotFound)
+
+	// Enrolled members are not affected
+	session.MakeRequest(t, NewRequest(t, "GET", "/user3/repo3"), http.StatusOK)
+
+	// Owners see who has not enabled two-factor authentication yet
+	resp = session.MakeRequest(t, NewRequest(t, "GET", "/org/user3/members"), http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	assert.EqualValues(t, 1, htmlDoc.doc.Find(".octicon-alert").Length())
+}
+
+func TestSiteRequireTwoFactor(t *testing.T) {
+	prepareTestEnv(t)
+
+	defer func(required bool) {
+		setting.TwoFactorRequired = required
+	}(setting.TwoFactorRequired)
+	setting.TwoFactorRequired = true
+
+	session := loginUser(t, "user5")
+	resp := session.MakeRequest(t, NewRequest(t, "GET", "/"), http.StatusOK)
+	assert.Contains(t, resp.Body.String(), "/user/settings/security")
+	user := models.AssertExistsAndLoadBean(t, &models.User{ID: 5}).(*models.User)
+	assert.True(t, user.TwoFactorDeadlineUnix > util.TimeStampNow())
+	token := getTokenForLoggedInUser(t, session)
+	key, err := models.AddPublicKey(5, "twofa", "ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAAAgQDAu7tvIvX6ZHrRXuZNfkR3XLHSsuCK9Zn3X58lxBcQzuo5xZgB6vRwwm/QtJuF+zZPtY5hsQILBLmF+BZ5WpKZp1jBeSjH2G7lxet9kbcH+kIVj0tPFEoyKI9wvWqIwC4prx/WVk2wLTJjzBAhyNxfEq7C9CeiX9pQEbEqJfkKCQ== nocomment\n", 0)
+	assert.NoError(t, err)
+	servReq := NewRequest(t, "GET", fmt.Sprintf("/api/internal/serv/command/%d/user5/repo4?mode=%d&verb=git-upload-pack", key.ID, models.AccessModeRead))
+	servReq.Header.Set("Authorization", "Bearer "+setting.InternalToken)
+
+	user.TwoFactorDeadlineUnix = util.TimeStampNow().Add(-1)
+	assert.NoError(t, models.UpdateUserCols(user, "two_factor_deadline_unix"))
+	resp = session.MakeRequest(t, NewRequest(t, "GET", "/"), http.StatusFound)
+	assert.Equal(t, "/user/settings/security", resp.Header().Get("Location"))
+	session.MakeRequest(t, NewRequest(t, "GET", "/user/settings/security"), http.StatusOK)
+
+	// The API and git over HTTP and SSH cannot be used either
+	MakeRequest(t, NewRequest(t, "GET", "/api/v1/user?token="+token), http.StatusForbidden)
+	req := NewRequest(t, "GET", "/api/v1/user")
+	req.SetBasicAuth("user5", userPassword)
+	MakeRequest(t, req, http.StatusForbidden)
+	req = NewRequest(t, "GET", "/user5/repo4.git/info/refs?service=git-receive-pack")
+	req.SetBasicAuth(token, "x-oauth-basic")
+	MakeRequest(t, req, http.StatusForbidden)
+	MakeRequest(t, servReq, http.StatusUnauthorized)
+
+	// Enrolled users cannot disable two-factor authentication
+	twofa := enrollTwoFactor(t, 5)
+	defer func() {
+		assert.NoError(t, models.DeleteTwoFactorByID(twofa.ID, 5))
+	}()
+	session.MakeRequest(t, NewRequest(t, "GET", "/"), http.StatusOK)
+	MakeRequest(t, NewRequest(t, "GET", "/api/v1/user?token="+token), http.StatusOK)
+	MakeRequest(t, servReq, http.StatusOK)
+	req = NewRequestWithValues(t, "POST", "/user/settings/security/two_factor/disable", map[string]string{
+		"_csrf": GetCSRF(t, session, "/user/settings/security"),
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	models.AssertExistsAndLoadBean(t, &models.TwoFactor{UID: 5})
+}
+
+func TestAdminTwoFactor(t *testing.T) {
+	prepareTestEnv(t)
+
+	twofa := enrollTwoFactor(t, 5)
+	session := loginUser(t, "user1")
+
+	resp := session.MakeRequest(t, NewRequest(t, "GET", "/admin/users?twofa=enrolled"), http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	assert.EqualValues(t, 1, htmlDoc.doc.Find("table tbody tr").Length())
+	assert.Contains(t, htmlDoc.doc.Find("table tbody tr").Text(), "user5")
+
+	req := NewRequestWithValues(t, "POST", "/admin/users/5/reset_twofa", map[string]string{
+		"_csrf": GetCSRF(t, session, "/admin/users/5"),
+	})
+	session.MakeRequest(t, req, http.StatusOK)
+	models.AssertNotExistsBean(t, &models.TwoFactor{ID: twofa.ID})
+	models.AssertNotExistsBean(t, &models.TwoFactorScratchCode{UID: 5})
+
+	resp = session.MakeRequest(t, NewRequest(t, "GET", "/admin/users?twofa=enrolled"), http.StatusOK)
+	htmlDoc = NewHTMLParser(t, resp.Body)
+	assert.EqualValues(t, 0, htmlDoc.doc.Find("table tbody tr").Length())
+}
@@ -0,0 +1,169 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/masoodkamyab/gitea/models"
+	"github.com/masoodkamyab/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// resetUserSessions signs the user out of all sessions, including the cached one
+func resetUserSessions(t *testing.T, userName string) {
+	user := models.AssertExistsAndLoadBean(t, &models.User{Name: userName}).(*models.User)
+	assert.NoError(t, models.DeleteUserSessionsByUID(user.ID, ""))
+	delete(loginSessionCache, userName)
+}
+
+func loginUserRemembered(t *testing.T, userName string) *TestSession {
+	session := emptyTestSession(t)
+	req := NewRequestWithValues(t, "POST", "/user/login", map[string]string{
+		"_csrf":     GetCSRF(t, session, "/user/login"),
+		"user_name": userName,
+		"password":  userPassword,
+		"remember":  "on",
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	return session
+}
+
+// browserUserSession returns the session the user is signed in with in the
+// given browser, which is the only one it cannot revoke
+func browserUserSession(t *testing.T, browser *TestSession, uid int64) *models.UserSession {
+	sessions, err := models.GetUserSessionsByUID(uid)
+	assert.NoError(t, err)
+	resp := browser.MakeRequest(t, NewRequest(t, "GET", "/user/settings/security"), http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	for _, s := range sessions {
+		if htmlDoc.doc.Find(fmt.Sprintf(`#revoke-session[data-id="%d"]`, s.ID)).Length() == 0 {
+			return s
+		}
+	}
+	assert.FailNow(t, "no session of the browser")
+	return nil
+}
+
+func TestUserSessions(t *testing.T) {
+	prepareTestEnv(t)
+	resetUserSessions(t, "user5")
+
+	session := loginUserWithPassword(t, "user5", userPassword)
+	remembered := loginUserRemembered(t, "user5")
+	remembered.MakeRequest(t, NewRequest(t, "GET", "/user/settings"), http.StatusOK)
+	other := loginUserRemembered(t, "user5")
+	other.MakeRequest(t, NewRequest(t, "GET", "/user/settings"), http.StatusOK)
+
+	resp := session.MakeRequest(t, NewRequest(t, "GET", "/user/settings/security"), http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	assert.EqualValues(t, 2, htmlDoc.doc.Find("#revoke-session.delete-button").Length())
+	s := browserUserSession(t, other, 5)
+	baseURL, err := url.Parse(setting.AppURL)
+	assert.NoError(t, err)
+	stolen := emptyTestSession(t)
+	stolen.jar.SetCookies(baseURL, []*http.Cookie{
+		other.GetCookie(setting.CookieUserName),
+		other.GetCookie(setting.CookieRememberName),
+	})
+
+	// Sessions of other users cannot be revoked
+	req := NewRequestWithValues(t, "POST", "/user/settings/security/sessions/delete", map[string]string{
+		"_csrf": GetCSRF(t, loginUser(t, "user2"), "/user/settings"),
+		"id":    fmt.Sprint(s.ID),
+	})
+	loginUser(t, "user2").MakeRequest(t, req, http.StatusUnauthorized)
+	other.MakeRequest(t, NewRequest(t, "GET", "/user/settings"), http.StatusOK)
+
+	req = NewRequestWithValues(t, "POST", "/user/settings/security/sessions/delete", map[string]string{
+		"_csrf": GetCSRF(t, session, "/user/settings"),
+		"id":    fmt.Sprint(s.ID),
+	})
+	session.MakeRequest(t, req, http.StatusOK)
+	models.AssertNotExistsBean(t, &models.UserSession{ID: s.ID})
+
+	// The revoked browser cannot sign in again with the remembered sign in
+	resp = other.MakeRequest(t, NewRequest(t, "GET", "/user/settings"), http.StatusFound)
+	assert.Equal(t, "/user/login", resp.Header().Get("Location"))
+	other.MakeRequest(t, NewRequest(t, "GET", "/user/login"), http.StatusOK)
+	stolen.MakeRequest(t, NewRequest(t, "GET", "/user/login"), http.StatusOK)
+	session.MakeRequest(t, NewRequest(t, "GET", "/user/settings"), http.StatusOK)
+
+	// The remembered sign in of another browser still works
+	remembered.MakeRequest(t, NewRequest(t, "GET", "/user/settings"), http.StatusOK)
+	restored := emptyTestSession(t)
+	restored.jar.SetCookies(baseURL, []*http.Cookie{
+		remembered.GetCookie(setting.CookieUserName),
+		remembered.GetCookie(setting.CookieRememberName),
+	})
+	restored.MakeRequest(t, NewRequest(t, "GET", "/user/login"), http.StatusFound)
+	restored.MakeRequest(t, NewRequest(t, "GET", "/user/settings"), http.StatusOK)
+
+	// Signing out forgets the session
+	s = browserUserSession(t, session, 5)
+	session.MakeRequest(t, NewRequest(t, "GET", "/user/logout"), http.StatusFound)
+	models.AssertNotExistsBean(t, &models.UserSession{ID: s.ID})
+}
+
+func TestUserSessionsPasswordChange(t *testing.T) {
+	prepareTestEnv(t)
+	resetUserSessions(t, "user5")
+
+	session := loginUserWithPassword(t, "user5", userPassword)
+	other := loginUserWithPassword(t, "user5", userPassword)
+	third := loginUserWithPassword(t, "user5", userPassword)
+	third.MakeRequest(t, NewRequest(t, "GET", "/user/settings"), http.StatusOK)
+
+	req := NewRequestWithValues(t, "POST", "/user/settings/security/sessions/delete_others", map[string]string{
+		"_csrf": GetCSRF(t, other, "/user/settings"),
+	})
+	other.MakeRequest(t, req, http.StatusOK)
+	session.MakeRequest(t, NewRequest(t, "GET", "/user/settings"), http.StatusFound)
+	third.MakeRequest(t, NewRequest(t, "GET", "/user/settings"), http.StatusFound)
+	other.MakeRequest(t, NewRequest(t, "GET", "/user/settings"), http.StatusOK)
+
+	session = loginUserWithPassword(t, "user5", userPassword)
+	session.MakeRequest(t, NewRequest(t, "GET", "/user/settings"), http.StatusOK)
+	req = NewRequestWithValues(t, "POST", "/user/settings/account", map[string]string{
+		"_csrf":        GetCSRF(t, other, "/user/settings/account"),
+		"old_password": userPassword,
+		"password":     userPassword,
+		"retype":       userPassword,
+	})
+	other.MakeRequest(t, req, http.StatusFound)
+	session.MakeRequest(t, NewRequest(t, "GET", "/user/settings"), http.StatusFound)
+	other.MakeRequest(t, NewRequest(t, "GET", "/user/settings"), http.StatusOK)
+}
+
+func TestAdminUserSessions(t *testing.T) {
+	prepareTestEnv(t)
+	resetUserSessions(t, "user5")
+
+	session := loginUserWithPassword(t, "user5", userPassword)
+	session.MakeRequest(t, NewRequest(t, "GET", "/user/settings"), http.StatusOK)
+	sessions, err := models.GetUserSessionsByUID(5)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, sessions)
+
+	admin := loginUser(t, "user1")
+	resp := admin.MakeRequest(t, NewRequest(t, "GET", "/admin/sessions"), http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	assert.EqualValues(t, 1, htmlDoc.doc.Find(fmt.Sprintf(`.delete-button[data-id="%d"]`, sessions[0].ID)).Length())
+
+	for _, s := range sessions {
+		req := NewRequestWithValues(t, "POST", "/admin/sessions/delete", map[string]string{
+			"_csrf": GetCSRF(t, admin, "/user/settings"),
+			"id":    fmt.Sprint(s.ID),
+		})
+		admin.MakeRequest(t, req, http.StatusOK)
+	}
+	session.MakeRequest(t, NewRequest(t, "GET", "/user/settings"), http.StatusFound)
+
+	loginUser(t, "user2").MakeRequest(t, NewRequest(t, "GET", "/admin/sessions"), http.StatusForbidden)
+}
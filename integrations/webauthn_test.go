@@ -0,0 +1,137 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/masoodkamyab/gitea/models"
+	"github.com/masoodkamyab/gitea/modules/setting"
+	"github.com/masoodkamyab/gitea/modules/webauthn"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func enrollTwoFactor(t *testing.T, uid int64) *models.TwoFactor {
+	twofa := &models.TwoFactor{UID: uid}
+	assert.NoError(t, twofa.SetSecret("JBSWY3DPEHPK3PXP"))
+	_, err := twofa.GenerateScratchCodes()
+	assert.NoError(t, err)
+	assert.NoError(t, models.NewTwoFactor(twofa))
+	return twofa
+}
+
+func registerWebAuthnCredential(t *testing.T, session *TestSession, authenticator *webauthn.SoftAuthenticator, name string) {
+	req := NewRequestWithValues(t, "POST", "/user/settings/security/webauthn/request_register", map[string]string{
+		"_csrf": GetCSRF(t, session, "/user/settings/security"),
+		"name":  name,
+	})
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var options webauthn.CredentialCreationOptions
+	DecodeJSON(t, resp, &options)
+
+	credential, err := authenticator.Create(&options)
+	assert.NoError(t, err)
+	req = NewRequestWithJSON(t, "POST", "/user/settings/security/webauthn/register", credential)
+	req.Header.Add("X-Csrf-Token", GetCSRF(t, session, "/user/settings/security"))
+	session.MakeRequest(t, req, http.StatusOK)
+}
+
+func loginUserWithWebAuthn(t *testing.T, userName string, authenticator *webauthn.SoftAuthenticator, expectedStatus int) *TestSession {
+	session := emptyTestSession(t)
+	req := NewRequestWithValues(t, "POST", "/user/login", map[string]string{
+		"_csrf":     GetCSRF(t, session, "/user/login"),
+		"user_name": userName,
+		"password":  userPassword,
+	})
+	resp := session.MakeRequest(t, req, http.StatusFound)
+	assert.Equal(t, "/user/webauthn", resp.Header().Get("Location"))
+
+	csrf := GetCSRF(t, session, "/user/webauthn")
+	req = NewRequest(t, "GET", "/user/webauthn/assertion")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var options webauthn.CredentialAssertionOptions
+	DecodeJSON(t, resp, &options)
+
+	credential, err := authenticator.Get(&options)
+	assert.NoError(t, err)
+	req = NewRequestWithJSON(t, "POST", "/user/webauthn/assertion", credential)
+	req.Header.Add("X-Csrf-Token", csrf)
+	session.MakeRequest(t, req, expectedStatus)
+	return session
+}
+
+func TestWebAuthn(t *testing.T) {
+	prepareTestEnv(t)
+
+	session := loginUser(t, "user5")
+	twofa := enrollTwoFactor(t, 5)
+	defer func() {
+		assert.NoError(t, models.DeleteTwoFactorByID(twofa.ID, 5))
+	}()
+
+	authenticator := webauthn.NewSoftAuthenticator(setting.WebAuthn.RPOrigin)
+	registerWebAuthnCredential(t, session, authenticator, "Soft Key")
+	cred := models.AssertExistsAndLoadBean(t, &models.WebAuthnCredential{UserID: 5, LowerName: "soft key"}).(*models.WebAuthnCredential)
+	assert.False(t, cred.LegacyU2F)
+
+	// Names are unique per user
+	req := NewRequestWithValues(t, "POST", "/user/settings/security/webauthn/request_register", map[string]string{
+		"_csrf": GetCSRF(t, session, "/user/settings/security"),
+		"name":  "SOFT KEY",
+	})
+	session.MakeRequest(t, req, http.StatusConflict)
+
+	// Several authenticators can be registered
+	platform := webauthn.NewSoftAuthenticator(setting.WebAuthn.RPOrigin)
+	registerWebAuthnCredential(t, session, platform, "Laptop")
+	models.AssertExistsAndLoadBean(t, &models.WebAuthnCredential{UserID: 5, LowerName: "laptop"})
+
+	loggedIn := loginUserWithWebAuthn(t, "user5", platform, http.StatusOK)
+	loggedIn.MakeRequest(t, NewRequest(t, "GET", "/user/settings"), http.StatusOK)
+	cred = models.AssertExistsAndLoadBean(t, &models.WebAuthnCredential{UserID: 5, LowerName: "laptop"}).(*models.WebAuthnCredential)
+	assert.EqualValues(t, 1, cred.SignCount)
+
+	req = NewRequestWithValues(t, "POST", "/user/settings/security/webauthn/delete", map[string]string{
+		"_csrf": GetCSRF(t, session, "/user/settings/security"),
+		"id":    "1",
+	})
+	session.MakeRequest(t, req, http.StatusUnauthorized)
+	models.AssertExistsAndLoadBean(t, &models.WebAuthnCredential{ID: 1})
+
+	req = NewRequestWithValues(t, "POST", "/user/settings/security/webauthn/delete", map[string]string{
+		"_csrf": GetCSRF(t, session, "/user/settings/security"),
+		"id":    "2",
+	})
+	session.MakeRequest(t, req, http.StatusOK)
+	models.AssertNotExistsBean(t, &models.WebAuthnCredential{UserID: 5, LowerName: "soft key"})
+}
+
+func TestWebAuthnMigratedU2F(t *testing.T) {
+	prepareTestEnv(t)
+
+	twofa := enrollTwoFactor(t, 5)
+	defer func() {
+		assert.NoError(t, models.DeleteTwoFactorByID(twofa.ID, 5))
+	}()
+
+	// Keys registered through U2F are scoped to the AppID instead of the RP ID
+	authenticator := webauthn.NewSoftAuthenticator(setting.WebAuthn.RPOrigin)
+	id, pub, err := authenticator.RegisterU2F(setting.WebAuthn.AppID)
+	assert.NoError(t, err)
+	publicKey, err := webauthn.MarshalECDSAPublicKey(pub)
+	assert.NoError(t, err)
+	_, err = models.CreateCredential(5, "U2F Key", &webauthn.Credential{
+		ID:              id,
+		PublicKey:       publicKey,
+		AttestationType: "fido-u2f",
+		U2F:             true,
+	})
+	assert.NoError(t, err)
+
+	loggedIn := loginUserWithWebAuthn(t, "user5", authenticator, http.StatusOK)
+	loggedIn.MakeRequest(t, NewRequest(t, "GET", "/user/settings"), http.StatusOK)
+}
@@ -0,0 +1,315 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/masoodkamyab/gitea/modules/log"
+	"github.com/masoodkamyab/gitea/modules/setting"
+	api "github.com/masoodkamyab/gitea/modules/structs"
+	"github.com/masoodkamyab/gitea/modules/util"
+
+	"xorm.io/builder"
+)
+
+// AuditAction is the type of a recorded administrative or permission change
+type AuditAction int
+
+// Possible audit actions
+const (
+	AuditRepoDelete             AuditAction = iota + 1 // 1
+	AuditRepoCollaboratorAdd                           // 2
+	AuditRepoCollaboratorMode                          // 3
+	AuditRepoCollaboratorRemove                        // 4
+	AuditRepoDeployKeyAdd                              // 5
+	AuditRepoDeployKeyRemove                           // 6
+	AuditRepoBranchProtect                             // 7
+	AuditRepoBranchUnprotect                           // 8
+	AuditOrgTeamCreate                                 // 9
+	AuditOrgTeamUpdate                                 // 10
+	AuditOrgTeamDelete                                 // 11
+	AuditOrgTeamMemberAdd                              // 12
+	AuditOrgTeamMemberRemove                           // 13
+	AuditOrgTeamRepoAdd                                // 14
+	AuditOrgTeamRepoRemove                             // 15
+	AuditUserAdmin                                     // 16
+	AuditUserDelete                                    // 17
+)
+
+var auditActionNames = map[AuditAction]string{
+	AuditRepoDelete:             "repo_delete",
+	AuditRepoCollaboratorAdd:    "repo_collaborator_add",
+	AuditRepoCollaboratorMode:   "repo_collaborator_mode",
+	AuditRepoCollaboratorRemove: "repo_collaborator_remove",
+	AuditRepoDeployKeyAdd:       "repo_deploy_key_add",
+	AuditRepoDeployKeyRemove:    "repo_deploy_key_remove",
+	AuditRepoBranchProtect:      "repo_branch_protect",
+	AuditRepoBranchUnprotect:    "repo_branch_unprotect",
+	AuditOrgTeamCreate:          "org_team_create",
+	AuditOrgTeamUpdate:          "org_team_update",
+	AuditOrgTeamDelete:          "org_team_delete",
+	AuditOrgTeamMemberAdd:       "org_team_member_add",
+	AuditOrgTeamMemberRemove:    "org_team_member_remove",
+	AuditOrgTeamRepoAdd:         "org_team_repo_add",
+	AuditOrgTeamRepoRemove:      "org_team_repo_remove",
+	AuditUserAdmin:              "user_admin",
+	AuditUserDelete:             "user_delete",
+}
+
+func (a AuditAction) String() string {
+	if name, ok := auditActionNames[a]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// AuditChange is the value of a field before and after a change, either is
+// nil if the field did not exist before or does not exist any more.
+type AuditChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+func formatAuditValue(value interface{}) string {
+	if value == nil {
+		return "-"
+	}
+	return fmt.Sprint(value)
+}
+
+// OldValue returns the formatted value before the change
+func (c AuditChange) OldValue() string {
+	return formatAuditValue(c.Old)
+}
+
+// NewValue returns the formatted value after the change
+func (c AuditChange) NewValue() string {
+	return formatAuditValue(c.New)
+}
+
+// AuditDiff maps the names of the changed fields to their changes
+type AuditDiff map[string]AuditChange
+
+// NewAuditDiff returns the fields whose values differ between the old and the
+// new state, either state can be nil.
+func NewAuditDiff(old, new map[string]interface{}) AuditDiff {
+	diff := make(AuditDiff)
+	for name, value := range new {
+		if oldValue, ok := old[name]; !ok || fmt.Sprint(oldValue) != fmt.Sprint(value) {
+			diff[name] = AuditChange{Old: old[name], New: value}
+		}
+	}
+	for name, value := range old {
+		if _, ok := new[name]; !ok {
+			diff[name] = AuditChange{Old: value}
+		}
+	}
+	return diff
+}
+
+// AuditFields returns the settings of the branch protection recorded in the
+// audit log, nil if the branch is not protected.
+func (protectBranch *ProtectedBranch) AuditFields() map[string]interface{} {
+	if protectBranch == nil || !protectBranch.IsProtected() {
+		return nil
+	}
+	return map[string]interface{}{
+		"enable_push_whitelist":        protectBranch.EnableWhitelist,
+		"push_whitelist_user_ids":      protectBranch.WhitelistUserIDs,
+		"push_whitelist_team_ids":      protectBranch.WhitelistTeamIDs,
+		"enable_merge_whitelist":       protectBranch.EnableMergeWhitelist,
+		"merge_whitelist_user_ids":     protectBranch.MergeWhitelistUserIDs,
+		"merge_whitelist_team_ids":     protectBranch.MergeWhitelistTeamIDs,
+		"required_approvals":           protectBranch.RequiredApprovals,
+		"approvals_whitelist_user_ids": protectBranch.ApprovalsWhitelistUserIDs,
+		"approvals_whitelist_team_ids": protectBranch.ApprovalsWhitelistTeamIDs,
+		"enable_merge_queue":           protectBranch.EnableMergeQueue,
+	}
+}
+
+// AuditFields returns the properties of the deploy key recorded in the audit
+// log.
+func (key *DeployKey) AuditFields() map[string]interface{} {
+	return map[string]interface{}{
+		"fingerprint": key.Fingerprint,
+		"mode":        key.Mode.String(),
+	}
+}
+
+// AuditFields returns the settings of the team recorded in the audit log.
+func (t *Team) AuditFields() map[string]interface{} {
+	if err := t.GetUnits(); err != nil {
+		log.Error("Error loading units of team %d: %v", t.ID, err)
+	}
+	units := t.GetUnitNames()
+	sort.Strings(units)
+	return map[string]interface{}{
+		"name":        t.Name,
+		"description": t.Description,
+		"permission":  t.Authorize.String(),
+		"units":       units,
+	}
+}
+
+// AuditEvent represents an administrative or permission change made by a
+// user. Names are copied into the event, so that it stays readable after the
+// actor or the target have been deleted.
+type AuditEvent struct {
+	ID        int64       `xorm:"pk autoincr"`
+	Action    AuditAction `xorm:"INDEX NOT NULL"`
+	ActorID   int64       `xorm:"INDEX"`
+	ActorName string
+	IP        string `xorm:"VARCHAR(64)"`
+	// OwnerID is the organization or user the target belongs to, it is zero
+	// for site wide changes.
+	OwnerID     int64 `xorm:"INDEX"`
+	RepoID      int64 `xorm:"INDEX"`
+	RepoName    string
+	Target      string
+	Diff        string         `xorm:"TEXT"`
+	CreatedUnix util.TimeStamp `xorm:"INDEX created"`
+}
+
+func newAuditEvent(action AuditAction, ownerID int64, target string, diff AuditDiff) *AuditEvent {
+	evt := &AuditEvent{
+		Action:  action,
+		OwnerID: ownerID,
+		Target:  target,
+	}
+	if len(diff) > 0 {
+		data, err := json.Marshal(diff)
+		if err != nil {
+			log.Error("Unable to marshal audit diff of %s: %v", action, err)
+		}
+		evt.Diff = string(data)
+	}
+	return evt
+}
+
+// NewRepoAuditEvent returns an audit event for a change of the repository
+// settings.
+func NewRepoAuditEvent(action AuditAction, repo *Repository, target string, diff AuditDiff) *AuditEvent {
+	evt := newAuditEvent(action, repo.OwnerID, target, diff)
+	evt.RepoID = repo.ID
+	evt.RepoName = repo.FullName()
+	return evt
+}
+
+// NewTeamAuditEvent returns an audit event for a change of the organization
+// team.
+func NewTeamAuditEvent(action AuditAction, t *Team, diff AuditDiff) *AuditEvent {
+	return newAuditEvent(action, t.OrgID, t.Name, diff)
+}
+
+// NewSiteAuditEvent returns an audit event for a site wide change, only site
+// administrators can see these.
+func NewSiteAuditEvent(action AuditAction, target string, diff AuditDiff) *AuditEvent {
+	return newAuditEvent(action, 0, target, diff)
+}
+
+// CreateAuditEvent records the audit event as done by the given user.
+func CreateAuditEvent(doer *User, ip string, evt *AuditEvent) error {
+	if doer != nil {
+		evt.ActorID = doer.ID
+		evt.ActorName = doer.Name
+	}
+	evt.IP = ip
+	_, err := x.Insert(evt)
+	return err
+}
+
+// Changes returns the changed fields of the event.
+func (evt *AuditEvent) Changes() AuditDiff {
+	diff := make(AuditDiff)
+	if len(evt.Diff) > 0 {
+		if err := json.Unmarshal([]byte(evt.Diff), &diff); err != nil {
+			log.Error("Unable to unmarshal audit diff of event %d: %v", evt.ID, err)
+		}
+	}
+	return diff
+}
+
+// APIFormat converts the audit event to its API format
+func (evt *AuditEvent) APIFormat() *api.AuditEvent {
+	diff := make(map[string]*api.AuditChange)
+	for name, change := range evt.Changes() {
+		diff[name] = &api.AuditChange{Old: change.Old, New: change.New}
+	}
+	return &api.AuditEvent{
+		ID:        evt.ID,
+		Action:    evt.Action.String(),
+		ActorID:   evt.ActorID,
+		ActorName: evt.ActorName,
+		IP:        evt.IP,
+		OwnerID:   evt.OwnerID,
+		RepoID:    evt.RepoID,
+		RepoName:  evt.RepoName,
+		Target:    evt.Target,
+		Diff:      diff,
+		Created:   evt.CreatedUnix.AsTime(),
+	}
+}
+
+// FindAuditEventsOptions represents the conditions to find audit events
+type FindAuditEventsOptions struct {
+	// OwnerID limits the events to the organization or user, zero for all
+	OwnerID int64
+	// Since and Before limit the events to the given time range, zero for no
+	// limit
+	Since    int64
+	Before   int64
+	Page     int
+	PageSize int
+}
+
+func (opts *FindAuditEventsOptions) toConds() builder.Cond {
+	cond := builder.NewCond()
+	if opts.OwnerID > 0 {
+		cond = cond.And(builder.Eq{"owner_id": opts.OwnerID})
+	}
+	if opts.Since > 0 {
+		cond = cond.And(builder.Gte{"created_unix": opts.Since})
+	}
+	if opts.Before > 0 {
+		cond = cond.And(builder.Lt{"created_unix": opts.Before})
+	}
+	return cond
+}
+
+// FindAuditEvents returns the audit events matching the options, most recent
+// first.
+func FindAuditEvents(opts *FindAuditEventsOptions) ([]*AuditEvent, error) {
+	sess := x.Where(opts.toConds()).Desc("id")
+	if opts.PageSize > 0 {
+		sess.Limit(opts.PageSize, (util.Max(opts.Page, 1)-1)*opts.PageSize)
+	}
+	events := make([]*AuditEvent, 0, opts.PageSize)
+	return events, sess.Find(&events)
+}
+
+// CountAuditEvents returns the number of audit events matching the options.
+func CountAuditEvents(opts *FindAuditEventsOptions) (int64, error) {
+	return x.Where(opts.toConds()).Count(new(AuditEvent))
+}
+
+// DeleteOldAuditEvents deletes the audit events older than the configured
+// retention period.
+func DeleteOldAuditEvents() {
+	if !taskStatusTable.StartIfNotRunning(`audit_log_cleanup`) {
+		return
+	}
+	defer taskStatusTable.Stop(`audit_log_cleanup`)
+
+	log.Trace("Doing: AuditLogCleanup")
+
+	deleteBefore := time.Now().Add(-setting.Cron.AuditLogCleanup.OlderThan)
+	if _, err := x.Where("created_unix < ?", deleteBefore.Unix()).Delete(new(AuditEvent)); err != nil {
+		log.Error("AuditLogCleanup: %v", err)
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/masoodkamyab/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setAuditEventCreated(t *testing.T, evt *AuditEvent, created int64) {
+	_, err := x.Exec("UPDATE audit_event SET created_unix = ? WHERE id = ?", created, evt.ID)
+	assert.NoError(t, err)
+}
+
+func TestNewAuditDiff(t *testing.T) {
+	diff := NewAuditDiff(map[string]interface{}{
+		"name":  "team",
+		"units": []string{"code"},
+		"gone":  true,
+	}, map[string]interface{}{
+		"name":  "team",
+		"units": []string{"code", "issues"},
+		"added": 1,
+	})
+	assert.Equal(t, AuditDiff{
+		"units": {Old: []string{"code"}, New: []string{"code", "issues"}},
+		"gone":  {Old: true},
+		"added": {New: 1},
+	}, diff)
+
+	assert.Len(t, NewAuditDiff(nil, nil), 0)
+	assert.Equal(t, AuditDiff{"mode": {New: "read"}}, NewAuditDiff(nil, map[string]interface{}{"mode": "read"}))
+}
+
+func TestCreateAuditEvent(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	evt := NewRepoAuditEvent(AuditRepoCollaboratorMode, repo, "user4", NewAuditDiff(
+		map[string]interface{}{"mode": "write"},
+		map[string]interface{}{"mode": "admin"},
+	))
+	assert.NoError(t, CreateAuditEvent(doer, "10.0.0.1", evt))
+
+	got := AssertExistsAndLoadBean(t, &AuditEvent{ID: evt.ID}).(*AuditEvent)
+	assert.Equal(t, AuditRepoCollaboratorMode, got.Action)
+	assert.EqualValues(t, 2, got.ActorID)
+	assert.Equal(t, "user2", got.ActorName)
+	assert.Equal(t, "10.0.0.1", got.IP)
+	assert.Equal(t, repo.OwnerID, got.OwnerID)
+	assert.Equal(t, "user2/repo1", got.RepoName)
+	assert.Equal(t, AuditDiff{"mode": {Old: "write", New: "admin"}}, got.Changes())
+
+	apiEvt := got.APIFormat()
+	assert.Equal(t, "repo_collaborator_mode", apiEvt.Action)
+	assert.Equal(t, "admin", apiEvt.Diff["mode"].New)
+}
+
+func TestFindAuditEvents(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 1}).(*User)
+	team := AssertExistsAndLoadBean(t, &Team{ID: 1}).(*Team)
+	teamEvt := NewTeamAuditEvent(AuditOrgTeamMemberAdd, team, nil)
+	assert.NoError(t, CreateAuditEvent(doer, "", teamEvt))
+	siteEvt := NewSiteAuditEvent(AuditUserAdmin, "user5", nil)
+	assert.NoError(t, CreateAuditEvent(doer, "", siteEvt))
+	setAuditEventCreated(t, teamEvt, 1000)
+	setAuditEventCreated(t, siteEvt, 2000)
+
+	events, err := FindAuditEvents(&FindAuditEventsOptions{})
+	assert.NoError(t, err)
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, siteEvt.ID, events[0].ID)
+		assert.Equal(t, teamEvt.ID, events[1].ID)
+	}
+
+	opts := &FindAuditEventsOptions{OwnerID: team.OrgID}
+	events, err = FindAuditEvents(opts)
+	assert.NoError(t, err)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, teamEvt.ID, events[0].ID)
+	}
+	count, err := CountAuditEvents(opts)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+
+	events, err = FindAuditEvents(&FindAuditEventsOptions{Since: 1500})
+	assert.NoError(t, err)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, siteEvt.ID, events[0].ID)
+	}
+	events, err = FindAuditEvents(&FindAuditEventsOptions{Before: 1500})
+	assert.NoError(t, err)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, teamEvt.ID, events[0].ID)
+	}
+
+	events, err = FindAuditEvents(&FindAuditEventsOptions{Page: 2, PageSize: 1})
+	assert.NoError(t, err)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, teamEvt.ID, events[0].ID)
+	}
+}
+
+func TestDeleteOldAuditEvents(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	setting.Cron.AuditLogCleanup.OlderThan = 24 * time.Hour
+
+	old := NewSiteAuditEvent(AuditUserDelete, "user10", nil)
+	assert.NoError(t, CreateAuditEvent(nil, "", old))
+	setAuditEventCreated(t, old, time.Now().Add(-48*time.Hour).Unix())
+	recent := NewSiteAuditEvent(AuditUserDelete, "user11", nil)
+	assert.NoError(t, CreateAuditEvent(nil, "", recent))
+
+	DeleteOldAuditEvents()
+	AssertNotExistsBean(t, &AuditEvent{ID: old.ID})
+	AssertExistsAndLoadBean(t, &AuditEvent{ID: recent.ID})
+}
@@ -38,6 +38,7 @@ type ProtectedBranch struct {
 	ApprovalsWhitelistUserIDs []int64        `xorm:"JSON TEXT"`
 	ApprovalsWhitelistTeamIDs []int64        `xorm:"JSON TEXT"`
 	RequiredApprovals         int64          `xorm:"NOT NULL DEFAULT 0"`
+	EnableMergeQueue          bool           `xorm:"NOT NULL DEFAULT false"`
 	CreatedUnix               util.TimeStamp `xorm:"created"`
 	UpdatedUnix               util.TimeStamp `xorm:"updated"`
 }
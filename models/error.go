@@ -304,6 +304,22 @@ func (err ErrKeyNotExist) Error() string {
 	return fmt.Sprintf("public key does not exist [id: %d]", err.ID)
 }
 
+// ErrSSHCertificateInvalid represents a "SSHCertificateInvalid" kind of error.
+type ErrSSHCertificateInvalid struct {
+	KeyID  string
+	Reason string
+}
+
+// IsErrSSHCertificateInvalid checks if an error is a ErrSSHCertificateInvalid.
+func IsErrSSHCertificateInvalid(err error) bool {
+	_, ok := err.(ErrSSHCertificateInvalid)
+	return ok
+}
+
+func (err ErrSSHCertificateInvalid) Error() string {
+	return fmt.Sprintf("ssh certificate is invalid [key_id: %s, reason: %s]", err.KeyID, err.Reason)
+}
+
 // ErrKeyAlreadyExist represents a "KeyAlreadyExist" kind of error.
 type ErrKeyAlreadyExist struct {
 	OwnerID     int64
@@ -534,6 +550,21 @@ func (err ErrAccessTokenEmpty) Error() string {
 	return fmt.Sprintf("access token is empty")
 }
 
+// ErrInvalidAccessTokenScope represents a "InvalidAccessTokenScope" kind of error.
+type ErrInvalidAccessTokenScope struct {
+	Scope string
+}
+
+// IsErrInvalidAccessTokenScope checks if an error is a ErrInvalidAccessTokenScope.
+func IsErrInvalidAccessTokenScope(err error) bool {
+	_, ok := err.(ErrInvalidAccessTokenScope)
+	return ok
+}
+
+func (err ErrInvalidAccessTokenScope) Error() string {
+	return fmt.Sprintf("invalid access token scope [scope: %s]", err.Scope)
+}
+
 // ________                            .__                __  .__
 // \_____  \_______  _________    ____ |__|____________ _/  |_|__| ____   ____
 //  /   |   \_  __ \/ ___\__  \  /    \|  \___   /\__  \\   __\  |/  _ \ /    \
@@ -936,6 +967,21 @@ func (err ErrNotAllowedToMerge) Error() string {
 	return fmt.Sprintf("not allowed to merge [reason: %s]", err.Reason)
 }
 
+// ErrNotAllowedToUpdate represents an error that the current user is not allowed to update the head branch of a pull request.
+type ErrNotAllowedToUpdate struct {
+	Reason string
+}
+
+// IsErrNotAllowedToUpdate checks if an error is an ErrNotAllowedToUpdate.
+func IsErrNotAllowedToUpdate(err error) bool {
+	_, ok := err.(ErrNotAllowedToUpdate)
+	return ok
+}
+
+func (err ErrNotAllowedToUpdate) Error() string {
+	return fmt.Sprintf("not allowed to update [reason: %s]", err.Reason)
+}
+
 // ErrTagAlreadyExists represents an error that tag with such name already exists.
 type ErrTagAlreadyExists struct {
 	TagName string
@@ -983,6 +1029,21 @@ func (err ErrSHANotFound) Error() string {
 	return fmt.Sprintf("sha not found [%s]", err.SHA)
 }
 
+// ErrMergeConflictUnresolved represents a "MergeConflictUnresolved" kind of error.
+type ErrMergeConflictUnresolved struct {
+	TreePath string
+}
+
+// IsErrMergeConflictUnresolved checks if an error is a ErrMergeConflictUnresolved.
+func IsErrMergeConflictUnresolved(err error) bool {
+	_, ok := err.(ErrMergeConflictUnresolved)
+	return ok
+}
+
+func (err ErrMergeConflictUnresolved) Error() string {
+	return fmt.Sprintf("merge conflict is not resolved [path: %s]", err.TreePath)
+}
+
 // ErrCommitIDDoesNotMatch represents a "CommitIDDoesNotMatch" kind of error.
 type ErrCommitIDDoesNotMatch struct {
 	GivenCommitID   string
@@ -1058,6 +1119,99 @@ func (err ErrIssueNotExist) Error() string {
 	return fmt.Sprintf("issue does not exist [id: %d, repo_id: %d, index: %d]", err.ID, err.RepoID, err.Index)
 }
 
+// ErrIssueCannotTransfer represents a "IssueCannotTransfer" kind of error.
+type ErrIssueCannotTransfer struct {
+	ID     int64
+	Reason string
+}
+
+// IsErrIssueCannotTransfer checks if an error is a ErrIssueCannotTransfer.
+func IsErrIssueCannotTransfer(err error) bool {
+	_, ok := err.(ErrIssueCannotTransfer)
+	return ok
+}
+
+func (err ErrIssueCannotTransfer) Error() string {
+	return fmt.Sprintf("issue cannot be transferred [id: %d]: %s", err.ID, err.Reason)
+}
+
+// ErrIssueBulkEditInvalid represents a "IssueBulkEditInvalid" kind of error.
+type ErrIssueBulkEditInvalid struct {
+	Reason string
+}
+
+// IsErrIssueBulkEditInvalid checks if an error is a ErrIssueBulkEditInvalid.
+func IsErrIssueBulkEditInvalid(err error) bool {
+	_, ok := err.(ErrIssueBulkEditInvalid)
+	return ok
+}
+
+func (err ErrIssueBulkEditInvalid) Error() string {
+	return fmt.Sprintf("invalid bulk edit of issues: %s", err.Reason)
+}
+
+// ErrIssueFieldNotExist represents a "IssueFieldNotExist" kind of error.
+type ErrIssueFieldNotExist struct {
+	ID int64
+}
+
+// IsErrIssueFieldNotExist checks if an error is a ErrIssueFieldNotExist.
+func IsErrIssueFieldNotExist(err error) bool {
+	_, ok := err.(ErrIssueFieldNotExist)
+	return ok
+}
+
+func (err ErrIssueFieldNotExist) Error() string {
+	return fmt.Sprintf("issue field does not exist [id: %d]", err.ID)
+}
+
+// ErrIssueFieldAlreadyExist represents a "IssueFieldAlreadyExist" kind of error.
+type ErrIssueFieldAlreadyExist struct {
+	Name string
+}
+
+// IsErrIssueFieldAlreadyExist checks if an error is a ErrIssueFieldAlreadyExist.
+func IsErrIssueFieldAlreadyExist(err error) bool {
+	_, ok := err.(ErrIssueFieldAlreadyExist)
+	return ok
+}
+
+func (err ErrIssueFieldAlreadyExist) Error() string {
+	return fmt.Sprintf("issue field already exists [name: %s]", err.Name)
+}
+
+// ErrIssueFieldInvalid represents a "IssueFieldInvalid" kind of error.
+type ErrIssueFieldInvalid struct {
+	Name   string
+	Reason string
+}
+
+// IsErrIssueFieldInvalid checks if an error is a ErrIssueFieldInvalid.
+func IsErrIssueFieldInvalid(err error) bool {
+	_, ok := err.(ErrIssueFieldInvalid)
+	return ok
+}
+
+func (err ErrIssueFieldInvalid) Error() string {
+	return fmt.Sprintf("invalid issue field [name: %s]: %s", err.Name, err.Reason)
+}
+
+// ErrIssueFieldValueInvalid represents a "IssueFieldValueInvalid" kind of error.
+type ErrIssueFieldValueInvalid struct {
+	FieldID int64
+	Value   string
+}
+
+// IsErrIssueFieldValueInvalid checks if an error is a ErrIssueFieldValueInvalid.
+func IsErrIssueFieldValueInvalid(err error) bool {
+	_, ok := err.(ErrIssueFieldValueInvalid)
+	return ok
+}
+
+func (err ErrIssueFieldValueInvalid) Error() string {
+	return fmt.Sprintf("invalid value of issue field [field_id: %d]: %s", err.FieldID, err.Value)
+}
+
 // __________      .__  .__ __________                                     __
 // \______   \__ __|  | |  |\______   \ ____  ________ __   ____   _______/  |_
 //  |     ___/  |  \  | |  | |       _// __ \/ ____/  |  \_/ __ \ /  ___/\   __\
@@ -1143,6 +1297,54 @@ func (err ErrInvalidMergeStyle) Error() string {
 		err.ID, err.Style)
 }
 
+// ErrMergeNotFastForward represents an error if a fast-forward-only merge is not possible
+type ErrMergeNotFastForward struct {
+	ID int64
+}
+
+// IsErrMergeNotFastForward checks if an error is a ErrMergeNotFastForward.
+func IsErrMergeNotFastForward(err error) bool {
+	_, ok := err.(ErrMergeNotFastForward)
+	return ok
+}
+
+func (err ErrMergeNotFastForward) Error() string {
+	return fmt.Sprintf("base branch cannot be fast-forwarded to the head branch [pull_id: %d]", err.ID)
+}
+
+// ErrPullRequestAlreadyQueued represents a "PullRequestAlreadyQueued"-error
+type ErrPullRequestAlreadyQueued struct {
+	PullID int64
+}
+
+// IsErrPullRequestAlreadyQueued checks if an error is a ErrPullRequestAlreadyQueued.
+func IsErrPullRequestAlreadyQueued(err error) bool {
+	_, ok := err.(ErrPullRequestAlreadyQueued)
+	return ok
+}
+
+func (err ErrPullRequestAlreadyQueued) Error() string {
+	return fmt.Sprintf("pull request is already in the merge queue [pull_id: %d]", err.PullID)
+}
+
+// ErrPullRequestUpdateConflict represents an error if the base branch cannot be merged into the head branch
+type ErrPullRequestUpdateConflict struct {
+	ID     int64
+	Style  MergeStyle
+	StdErr string
+}
+
+// IsErrPullRequestUpdateConflict checks if an error is a ErrPullRequestUpdateConflict.
+func IsErrPullRequestUpdateConflict(err error) bool {
+	_, ok := err.(ErrPullRequestUpdateConflict)
+	return ok
+}
+
+func (err ErrPullRequestUpdateConflict) Error() string {
+	return fmt.Sprintf("pull request cannot be updated without conflicts [id: %d, strategy: %s]: %s",
+		err.ID, err.Style, err.StdErr)
+}
+
 // _________                                       __
 // \_   ___ \  ____   _____   _____   ____   _____/  |_
 // /    \  \/ /  _ \ /     \ /     \_/ __ \ /    \   __\
@@ -1210,6 +1412,21 @@ func (err ErrTrackedTimeNotExist) Error() string {
 	return fmt.Sprintf("tracked time does not exist [id: %d]", err.ID)
 }
 
+// ErrInvalidEstimate represents an "InvalidEstimate" kind of error.
+type ErrInvalidEstimate struct {
+	Estimate string
+}
+
+// IsErrInvalidEstimate checks if an error is a ErrInvalidEstimate.
+func IsErrInvalidEstimate(err error) bool {
+	_, ok := err.(ErrInvalidEstimate)
+	return ok
+}
+
+func (err ErrInvalidEstimate) Error() string {
+	return fmt.Sprintf("invalid estimate [estimate: %s]", err.Estimate)
+}
+
 // .____          ___.          .__
 // |    |   _____ \_ |__   ____ |  |
 // |    |   \__  \ | __ \_/ __ \|  |
@@ -1256,6 +1473,130 @@ func (err ErrMilestoneNotExist) Error() string {
 	return fmt.Sprintf("milestone does not exist [id: %d, repo_id: %d]", err.ID, err.RepoID)
 }
 
+// __________                   __               __
+// \______   \_______  ____    |__| ____   _____/  |_
+//  |     ___/\_  __ \/  _ \   |  |/ __ \_/ ___\   __\
+//  |    |     |  | \(  <_> )  |  \  ___/\  \___|  |
+//  |____|     |__|   \____/\__|  |\___  >\___  >__|
+//                         \______|    \/     \/
+
+// ErrProjectNotExist represents a "ProjectNotExist" kind of error.
+type ErrProjectNotExist struct {
+	ID int64
+}
+
+// IsErrProjectNotExist checks if an error is a ErrProjectNotExist.
+func IsErrProjectNotExist(err error) bool {
+	_, ok := err.(ErrProjectNotExist)
+	return ok
+}
+
+func (err ErrProjectNotExist) Error() string {
+	return fmt.Sprintf("project does not exist [id: %d]", err.ID)
+}
+
+// ErrProjectBoardNotExist represents a "ProjectBoardNotExist" kind of error.
+type ErrProjectBoardNotExist struct {
+	ID        int64
+	ProjectID int64
+}
+
+// IsErrProjectBoardNotExist checks if an error is a ErrProjectBoardNotExist.
+func IsErrProjectBoardNotExist(err error) bool {
+	_, ok := err.(ErrProjectBoardNotExist)
+	return ok
+}
+
+func (err ErrProjectBoardNotExist) Error() string {
+	return fmt.Sprintf("project board does not exist [id: %d, project_id: %d]", err.ID, err.ProjectID)
+}
+
+// ErrProjectIssueNotExist represents a "ProjectIssueNotExist" kind of error.
+type ErrProjectIssueNotExist struct {
+	ProjectID int64
+	IssueID   int64
+}
+
+// IsErrProjectIssueNotExist checks if an error is a ErrProjectIssueNotExist.
+func IsErrProjectIssueNotExist(err error) bool {
+	_, ok := err.(ErrProjectIssueNotExist)
+	return ok
+}
+
+func (err ErrProjectIssueNotExist) Error() string {
+	return fmt.Sprintf("issue is not on project [project_id: %d, issue_id: %d]", err.ProjectID, err.IssueID)
+}
+
+// ErrProjectIssueAlreadyExist represents a "ProjectIssueAlreadyExist" kind of error.
+type ErrProjectIssueAlreadyExist struct {
+	ProjectID int64
+	IssueID   int64
+}
+
+// IsErrProjectIssueAlreadyExist checks if an error is a ErrProjectIssueAlreadyExist.
+func IsErrProjectIssueAlreadyExist(err error) bool {
+	_, ok := err.(ErrProjectIssueAlreadyExist)
+	return ok
+}
+
+func (err ErrProjectIssueAlreadyExist) Error() string {
+	return fmt.Sprintf("issue is already on project [project_id: %d, issue_id: %d]", err.ProjectID, err.IssueID)
+}
+
+// ErrProjectIssueNotAllowed represents a "ProjectIssueNotAllowed" kind of error,
+// the issue belongs to a repository outside of the project.
+type ErrProjectIssueNotAllowed struct {
+	ProjectID int64
+	IssueID   int64
+}
+
+// IsErrProjectIssueNotAllowed checks if an error is a ErrProjectIssueNotAllowed.
+func IsErrProjectIssueNotAllowed(err error) bool {
+	_, ok := err.(ErrProjectIssueNotAllowed)
+	return ok
+}
+
+func (err ErrProjectIssueNotAllowed) Error() string {
+	return fmt.Sprintf("issue cannot be put on project [project_id: %d, issue_id: %d]", err.ProjectID, err.IssueID)
+}
+
+// ___________.__.__   __
+// \_   _____/|__|  |_/  |_  ___________
+//  |    __)  |  |  |\   __\/ __ \_  __ \
+//  |     \   |  |  |_|  | \  ___/|  | \/
+//  \___  /   |__|____/__|  \___  >__|
+//      \/                      \/
+
+// ErrSavedFilterNotExist represents a "SavedFilterNotExist" kind of error.
+type ErrSavedFilterNotExist struct {
+	ID int64
+}
+
+// IsErrSavedFilterNotExist checks if an error is a ErrSavedFilterNotExist.
+func IsErrSavedFilterNotExist(err error) bool {
+	_, ok := err.(ErrSavedFilterNotExist)
+	return ok
+}
+
+func (err ErrSavedFilterNotExist) Error() string {
+	return fmt.Sprintf("saved filter does not exist [id: %d]", err.ID)
+}
+
+// ErrSavedFilterInvalid represents a "SavedFilterInvalid" kind of error.
+type ErrSavedFilterInvalid struct {
+	Reason string
+}
+
+// IsErrSavedFilterInvalid checks if an error is a ErrSavedFilterInvalid.
+func IsErrSavedFilterInvalid(err error) bool {
+	_, ok := err.(ErrSavedFilterInvalid)
+	return ok
+}
+
+func (err ErrSavedFilterInvalid) Error() string {
+	return fmt.Sprintf("saved filter is invalid: %s", err.Reason)
+}
+
 //    _____   __    __                .__                           __
 //   /  _  \_/  |__/  |______    ____ |  |__   _____   ____   _____/  |_
 //  /  /_\  \   __\   __\__  \ _/ ___\|  |  \ /     \_/ __ \ /    \   __\
@@ -1444,18 +1785,34 @@ func (err ErrExternalLoginUserNotExist) Error() string {
 // |______/ \_______ \___  /    |____|_  /\___  >___  /|__/____  > |__|  |__|  (____  /__| |__|\____/|___|  /
 // \/   \/            \/     \/_____/         \/                   \/                    \/
 
-// ErrU2FRegistrationNotExist represents a "ErrU2FRegistrationNotExist" kind of error.
-type ErrU2FRegistrationNotExist struct {
+// ErrWebAuthnCredentialNotExist represents a "ErrWebAuthnCredentialNotExist" kind of error.
+type ErrWebAuthnCredentialNotExist struct {
+	ID           int64
+	CredentialID string
+}
+
+func (err ErrWebAuthnCredentialNotExist) Error() string {
+	return fmt.Sprintf("WebAuthn credential does not exist [id: %d, credential_id: %s]", err.ID, err.CredentialID)
+}
+
+// IsErrWebAuthnCredentialNotExist checks if an error is a ErrWebAuthnCredentialNotExist.
+func IsErrWebAuthnCredentialNotExist(err error) bool {
+	_, ok := err.(ErrWebAuthnCredentialNotExist)
+	return ok
+}
+
+// ErrUserSessionNotExist represents a "UserSessionNotExist" kind of error.
+type ErrUserSessionNotExist struct {
 	ID int64
 }
 
-func (err ErrU2FRegistrationNotExist) Error() string {
-	return fmt.Sprintf("U2F registration does not exist [id: %d]", err.ID)
+func (err ErrUserSessionNotExist) Error() string {
+	return fmt.Sprintf("user session does not exist [id: %d]", err.ID)
 }
 
-// IsErrU2FRegistrationNotExist checks if an error is a ErrU2FRegistrationNotExist.
-func IsErrU2FRegistrationNotExist(err error) bool {
-	_, ok := err.(ErrU2FRegistrationNotExist)
+// IsErrUserSessionNotExist checks if an error is a ErrUserSessionNotExist.
+func IsErrUserSessionNotExist(err error) bool {
+	_, ok := err.(ErrUserSessionNotExist)
 	return ok
 }
 
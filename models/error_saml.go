@@ -0,0 +1,27 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import "fmt"
+
+// ErrSAMLInitialize represents a "SAMLInitialize" kind of error.
+type ErrSAMLInitialize struct {
+	ProviderName string
+	MetadataURL  string
+	Cause        error
+}
+
+// IsErrSAMLInitialize checks if an error is a ErrSAMLInitialize.
+func IsErrSAMLInitialize(err error) bool {
+	_, ok := err.(ErrSAMLInitialize)
+	return ok
+}
+
+func (err ErrSAMLInitialize) Error() string {
+	if len(err.MetadataURL) > 0 {
+		return fmt.Sprintf("Failed to initialize SAML Provider with name '%s' with metadata url '%s': %v", err.ProviderName, err.MetadataURL, err.Cause)
+	}
+	return fmt.Sprintf("Failed to initialize SAML Provider with name '%s': %v", err.ProviderName, err.Cause)
+}
@@ -4,7 +4,13 @@
 
 package models
 
-import "github.com/markbates/goth"
+import (
+	"strings"
+
+	"github.com/masoodkamyab/gitea/modules/log"
+
+	"github.com/markbates/goth"
+)
 
 // ExternalLoginUser makes the connecting between some existing user and additional external login sources
 type ExternalLoginUser struct {
@@ -32,9 +38,24 @@ func ListAccountLinks(user *User) ([]*ExternalLoginUser, error) {
 	return externalAccounts, nil
 }
 
+// GetActiveExternalLoginSourceByName returns the active OAuth2 or SAML LoginSource with the given name,
+// it is the provider of the goth users of both types of sources
+func GetActiveExternalLoginSourceByName(name string) (*LoginSource, error) {
+	loginSource := new(LoginSource)
+	has, err := x.Where("name = ? and is_actived = ?", name, true).
+		In("type", LoginOAuth2, LoginSAML).
+		Get(loginSource)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrLoginSourceNotExist{}
+	}
+	return loginSource, nil
+}
+
 // LinkAccountToUser link the gothUser to the user
 func LinkAccountToUser(user *User, gothUser goth.User) error {
-	loginSource, err := GetActiveOAuth2LoginSourceByName(gothUser.Provider)
+	loginSource, err := GetActiveExternalLoginSourceByName(gothUser.Provider)
 	if err != nil {
 		return err
 	}
@@ -72,3 +93,95 @@ func removeAllAccountLinks(e Engine, user *User) error {
 	_, err := e.Delete(&ExternalLoginUser{UserID: user.ID})
 	return err
 }
+
+// SyncExternalUser synchronizes a user with the goth user of an OAuth2 or SAML login source
+func SyncExternalUser(source *LoginSource, user *User, gothUser goth.User) error {
+	if source.IsSAML() {
+		return SyncSAMLUser(source, user, gothUser)
+	}
+	return SyncOAuth2User(source, user, gothUser)
+}
+
+// syncExternalUserProfile updates the full name and email address of a user with the ones of an external login,
+// the email address is kept if it is used by another user
+func syncExternalUserProfile(user *User, fullName, email string) error {
+	var cols []string
+	if len(fullName) > 0 && user.FullName != fullName {
+		user.FullName = fullName
+		cols = append(cols, "full_name")
+	}
+	if email = strings.ToLower(email); len(email) > 0 && user.Email != email {
+		used, err := x.
+			Where("id!=?", user.ID).
+			And("email=?", email).
+			Exist(new(User))
+		if err != nil {
+			return err
+		}
+		if !used {
+			used, err = x.
+				Where("uid!=?", user.ID).
+				And("email=?", email).
+				Exist(new(EmailAddress))
+			if err != nil {
+				return err
+			}
+		}
+		if used {
+			log.Warn("syncExternalUserProfile: email %s of %s is already used", email, user.Name)
+		} else {
+			user.Email = email
+			cols = append(cols, "email")
+		}
+	}
+
+	if len(cols) == 0 {
+		return nil
+	}
+	return UpdateUserCols(user, cols...)
+}
+
+// syncExternalUserGroups updates the administrator status and team memberships of a user
+// with the groups of an external login
+func syncExternalUserGroups(user *User, groups []string, adminGroup, groupTeamMap string, groupTeamMapRemoval bool) error {
+	if len(adminGroup) > 0 {
+		isAdmin := false
+		for _, group := range groups {
+			if group == adminGroup {
+				isAdmin = true
+				break
+			}
+		}
+		if user.IsAdmin != isAdmin {
+			user.IsAdmin = isAdmin
+			if err := UpdateUserCols(user, "is_admin"); err != nil {
+				return err
+			}
+		}
+	}
+
+	teamMap, err := ParseGroupTeamMap(groupTeamMap)
+	if err != nil {
+		return err
+	}
+	return SyncGroupsToTeams(user, groups, teamMap, groupTeamMapRemoval)
+}
+
+// getExternalUserGroups returns the groups of a claim or attribute, it is either a list or a single group
+func getExternalUserGroups(claim interface{}) []string {
+	switch v := claim.(type) {
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	case []interface{}:
+		groups := make([]string, 0, len(v))
+		for _, group := range v {
+			if s, ok := group.(string); ok {
+				groups = append(groups, s)
+			}
+		}
+		return groups
+	}
+	return nil
+}
@@ -48,16 +48,19 @@ type Issue struct {
 	Ref             string
 
 	DeadlineUnix util.TimeStamp `xorm:"INDEX"`
+	// EstimatedTime is the planned effort in seconds
+	EstimatedTime int64 `xorm:"NOT NULL DEFAULT 0"`
 
 	CreatedUnix util.TimeStamp `xorm:"INDEX created"`
 	UpdatedUnix util.TimeStamp `xorm:"INDEX updated"`
 	ClosedUnix  util.TimeStamp `xorm:"INDEX"`
 
-	Attachments      []*Attachment `xorm:"-"`
-	Comments         []*Comment    `xorm:"-"`
-	Reactions        ReactionList  `xorm:"-"`
-	TotalTrackedTime int64         `xorm:"-"`
-	Assignees        []*User       `xorm:"-"`
+	Attachments      []*Attachment       `xorm:"-"`
+	Comments         []*Comment          `xorm:"-"`
+	Reactions        ReactionList        `xorm:"-"`
+	TotalTrackedTime int64               `xorm:"-"`
+	Assignees        []*User             `xorm:"-"`
+	FieldValues      []*IssueFieldValues `xorm:"-"`
 
 	// IsLocked limits commenting abilities to users on an issue
 	// with write access
@@ -258,6 +261,10 @@ func (issue *Issue) loadAttributes(e Engine) (err error) {
 		return
 	}
 
+	if err = issue.loadFieldValues(e); err != nil {
+		return
+	}
+
 	if err = issue.loadPullRequest(e); err != nil && !IsErrPullRequestNotExist(err) {
 		// It is possible pull request is not yet created.
 		return err
@@ -401,6 +408,12 @@ func (issue *Issue) apiFormat(e Engine) *api.Issue {
 	if issue.DeadlineUnix != 0 {
 		apiIssue.Deadline = issue.DeadlineUnix.AsTimePtr()
 	}
+	apiIssue.EstimatedTime = issue.EstimatedTime
+	issue.loadFieldValues(e)
+	apiIssue.Fields = make([]*api.IssueFieldValue, len(issue.FieldValues))
+	for i, values := range issue.FieldValues {
+		apiIssue.Fields[i] = values.APIFormat()
+	}
 
 	return apiIssue
 }
@@ -791,9 +804,13 @@ func (issue *Issue) ChangeStatus(doer *User, isClosed bool) (err error) {
 	}
 	sess.Close()
 
+	return issue.sendStatusChangedWebhook(doer, isClosed)
+}
+
+func (issue *Issue) sendStatusChangedWebhook(doer *User, isClosed bool) (err error) {
 	mode, _ := AccessLevel(issue.Poster, issue.Repo)
 	if issue.IsPull {
-		if err = issue.loadPullRequest(sess); err != nil {
+		if err = issue.loadPullRequest(x); err != nil {
 			return err
 		}
 		// Merge pull request calls issue.changeStatus so we need to handle separately.
@@ -1034,6 +1051,19 @@ func getMaxIndexOfIssue(e Engine, repoID int64) (int64, error) {
 	} else if !has {
 		return 0, errors.New("Retrieve Max index from issue failed")
 	}
+
+	// Indexes of issues transferred to another repository stay reserved,
+	// they keep redirecting to the transferred issue.
+	var maxRedirectIndex int64
+	has, err = e.SQL("SELECT COALESCE((SELECT MAX(old_index) FROM issue_redirect WHERE old_repo_id = ?),0)", repoID).Get(&maxRedirectIndex)
+	if err != nil {
+		return 0, err
+	} else if !has {
+		return 0, errors.New("Retrieve Max index from issue redirect failed")
+	}
+	if maxRedirectIndex > maxIndex {
+		maxIndex = maxRedirectIndex
+	}
 	return maxIndex, nil
 }
 
@@ -1303,6 +1333,16 @@ type IssuesOptions struct {
 	LabelIDs    []int64
 	SortType    string
 	IssueIDs    []int64
+
+	// labels are matched by name, which may span several repositories
+	LabelNames         []string
+	ExcludedLabelNames []string
+	MilestoneIDs       []int64
+	CreatedAfterUnix   util.TimeStamp
+	CreatedBeforeUnix  util.TimeStamp
+	UpdatedAfterUnix   util.TimeStamp
+	UpdatedBeforeUnix  util.TimeStamp
+	FieldValues        []IssueFieldFilter
 }
 
 // sortIssuesSession sort an issues-related session based on the provided
@@ -1330,6 +1370,16 @@ func sortIssuesSession(sess *xorm.Session, sortType string) {
 	}
 }
 
+// isIssueSortType returns true if sortType is one of the sort types of the issue lists
+func isIssueSortType(sortType string) bool {
+	switch sortType {
+	case "latest", "newest", "oldest", "recentupdate", "leastupdate", "mostcomment", "leastcomment",
+		"priority", "nearduedate", "farduedate":
+		return true
+	}
+	return false
+}
+
 func (opts *IssuesOptions) setupSession(sess *xorm.Session) {
 	if opts.Page >= 0 && opts.PageSize > 0 {
 		var start int
@@ -1389,6 +1439,50 @@ func (opts *IssuesOptions) setupSession(sess *xorm.Session) {
 				fmt.Sprintf("issue.id = il%[1]d.issue_id AND il%[1]d.label_id = %[2]d", i, labelID))
 		}
 	}
+
+	for _, name := range opts.LabelNames {
+		sess.In("issue.id", issueIDsByLabelName(name))
+	}
+	for _, name := range opts.ExcludedLabelNames {
+		sess.NotIn("issue.id", issueIDsByLabelName(name))
+	}
+	for _, filter := range opts.FieldValues {
+		sess.In("issue.id", issueIDsByFieldValue(filter))
+	}
+
+	if len(opts.MilestoneIDs) > 0 {
+		sess.In("issue.milestone_id", opts.MilestoneIDs)
+	}
+
+	if opts.CreatedAfterUnix > 0 {
+		sess.And("issue.created_unix >= ?", opts.CreatedAfterUnix)
+	}
+	if opts.CreatedBeforeUnix > 0 {
+		sess.And("issue.created_unix < ?", opts.CreatedBeforeUnix)
+	}
+	if opts.UpdatedAfterUnix > 0 {
+		sess.And("issue.updated_unix >= ?", opts.UpdatedAfterUnix)
+	}
+	if opts.UpdatedBeforeUnix > 0 {
+		sess.And("issue.updated_unix < ?", opts.UpdatedBeforeUnix)
+	}
+}
+
+func issueIDsByLabelName(name string) *builder.Builder {
+	return builder.Select("issue_label.issue_id").From("issue_label").
+		Join("INNER", "label", "label.id = issue_label.label_id").
+		Where(builder.Eq{"label.name": name})
+}
+
+// CountIssues returns the number of issues matching the options, paging is ignored
+func CountIssues(opts *IssuesOptions) (int64, error) {
+	sess := x.NewSession()
+	defer sess.Close()
+
+	countOpts := *opts
+	countOpts.PageSize = 0
+	countOpts.setupSession(sess)
+	return sess.Count(new(Issue))
 }
 
 // CountIssuesByRepo map from repoID to number of issues matching the options
@@ -1732,9 +1826,13 @@ func GetRepoIssueStats(repoID, uid int64, filterMode int, isPull bool) (numOpen
 	return openResult, closedResult
 }
 
-// SearchIssueIDsByKeyword search issues on database
-func SearchIssueIDsByKeyword(kw string, repoID int64, limit, start int) (int64, []int64, error) {
-	var repoCond = builder.Eq{"repo_id": repoID}
+// SearchIssueIDsByKeyword search issues on database within the repositories of repoIDs,
+// an empty repoIDs searches all repositories
+func SearchIssueIDsByKeyword(kw string, repoIDs []int64, limit, start int) (int64, []int64, error) {
+	var repoCond = builder.NewCond()
+	if len(repoIDs) > 0 {
+		repoCond = builder.In("repo_id", repoIDs)
+	}
 	var subQuery = builder.Select("id").From("issue").Where(repoCond)
 	var cond = builder.And(
 		repoCond,
@@ -0,0 +1,328 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+
+	"github.com/masoodkamyab/gitea/modules/log"
+
+	"github.com/go-xorm/xorm"
+)
+
+// BulkEditIssuesOptions defines the changes applied to every issue of a bulk edit,
+// nil and empty fields leave the issues unchanged
+type BulkEditIssuesOptions struct {
+	AddLabelIDs       []int64
+	RemoveLabelIDs    []int64
+	MilestoneID       *int64 // zero removes the milestone
+	AddAssigneeIDs    []int64
+	RemoveAssigneeIDs []int64
+	AddProjectID      int64
+	RemoveProjectID   int64
+	IsClosed          *bool
+	IsLocked          *bool
+	LockReason        string
+	TransferTo        *Repository
+}
+
+// IssueChange records what a bulk edit changed on an issue
+type IssueChange struct {
+	Issue            *Issue
+	AddedLabels      []*Label
+	RemovedLabels    []*Label
+	MilestoneChanged bool
+	AddedAssignees   []*User
+	RemovedAssignees []*User
+	AddedProject     *Project
+	StatusChanged    bool
+	LockChanged      bool
+	TransferredFrom  *Repository
+}
+
+// bulkEditTargets holds the labels, assignees and projects of a bulk edit after validation
+type bulkEditTargets struct {
+	addLabels       []*Label
+	removeLabels    []*Label
+	addAssignees    []*User
+	removeAssignees []*User
+	addProject      *Project
+}
+
+func getBulkEditLabels(e Engine, repoID int64, labelIDs []int64) ([]*Label, error) {
+	labels := make([]*Label, 0, len(labelIDs))
+	for _, id := range labelIDs {
+		label, err := getLabelInRepoByID(e, repoID, id)
+		if err != nil {
+			return nil, err
+		}
+		labels = append(labels, label)
+	}
+	return labels, nil
+}
+
+func getBulkEditAssignees(e Engine, repo *Repository, userIDs []int64, checkAssignable bool) ([]*User, error) {
+	users := make([]*User, 0, len(userIDs))
+	for _, id := range userIDs {
+		user, err := getUserByID(e, id)
+		if err != nil {
+			return nil, err
+		}
+		if checkAssignable {
+			if ok, err := canBeAssigned(e, user, repo); err != nil {
+				return nil, err
+			} else if !ok {
+				return nil, ErrIssueBulkEditInvalid{Reason: fmt.Sprintf("%s cannot be assigned", user.Name)}
+			}
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+func containsInt64(ids []int64, id int64) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (opts *BulkEditIssuesOptions) validate(e Engine, doer *User, repo *Repository) (targets *bulkEditTargets, err error) {
+	for _, id := range opts.AddLabelIDs {
+		if containsInt64(opts.RemoveLabelIDs, id) {
+			return nil, ErrIssueBulkEditInvalid{Reason: "a label cannot be added and removed at once"}
+		}
+	}
+	for _, id := range opts.AddAssigneeIDs {
+		if containsInt64(opts.RemoveAssigneeIDs, id) {
+			return nil, ErrIssueBulkEditInvalid{Reason: "an assignee cannot be added and removed at once"}
+		}
+	}
+	if opts.AddProjectID > 0 && opts.AddProjectID == opts.RemoveProjectID {
+		return nil, ErrIssueBulkEditInvalid{Reason: "a project cannot be added and removed at once"}
+	}
+
+	targets = new(bulkEditTargets)
+	if targets.addLabels, err = getBulkEditLabels(e, repo.ID, opts.AddLabelIDs); err != nil {
+		return nil, err
+	}
+	if targets.removeLabels, err = getBulkEditLabels(e, repo.ID, opts.RemoveLabelIDs); err != nil {
+		return nil, err
+	}
+	if opts.MilestoneID != nil && *opts.MilestoneID > 0 {
+		if _, err = getMilestoneByRepoID(e, repo.ID, *opts.MilestoneID); err != nil {
+			return nil, err
+		}
+	}
+	if targets.addAssignees, err = getBulkEditAssignees(e, repo, opts.AddAssigneeIDs, true); err != nil {
+		return nil, err
+	}
+	if targets.removeAssignees, err = getBulkEditAssignees(e, repo, opts.RemoveAssigneeIDs, false); err != nil {
+		return nil, err
+	}
+	for _, projectID := range []int64{opts.AddProjectID, opts.RemoveProjectID} {
+		if projectID == 0 {
+			continue
+		}
+		p, err := getProjectByID(e, projectID)
+		if err != nil {
+			return nil, err
+		}
+		if mode, err := p.userAccessMode(e, doer); err != nil {
+			return nil, err
+		} else if mode < AccessModeWrite {
+			return nil, ErrProjectNotExist{ID: projectID}
+		}
+		if projectID == opts.AddProjectID {
+			targets.addProject = p
+		}
+	}
+	if opts.TransferTo != nil {
+		if ok, err := canTransferIssueTo(e, doer, opts.TransferTo); err != nil {
+			return nil, err
+		} else if !ok {
+			return nil, ErrIssueBulkEditInvalid{Reason: "issues cannot be transferred to the repository"}
+		}
+	}
+	return targets, nil
+}
+
+func bulkEditIssue(e *xorm.Session, doer *User, issue *Issue, opts *BulkEditIssuesOptions, targets *bulkEditTargets) (*IssueChange, error) {
+	change := &IssueChange{Issue: issue}
+
+	// The issue counts of the labels change with every issue, so the labels are reloaded each time
+	issue.Labels = nil
+	for _, target := range targets.addLabels {
+		if issue.hasLabel(e, target.ID) {
+			continue
+		}
+		label, err := getLabelInRepoByID(e, target.RepoID, target.ID)
+		if err != nil {
+			return nil, err
+		}
+		if err = issue.addLabel(e, label, doer); err != nil {
+			return nil, fmt.Errorf("addLabel: %v", err)
+		}
+		change.AddedLabels = append(change.AddedLabels, label)
+	}
+	for _, target := range targets.removeLabels {
+		if !issue.hasLabel(e, target.ID) {
+			continue
+		}
+		label, err := getLabelInRepoByID(e, target.RepoID, target.ID)
+		if err != nil {
+			return nil, err
+		}
+		if err = issue.removeLabel(e, doer, label); err != nil {
+			return nil, fmt.Errorf("removeLabel: %v", err)
+		}
+		change.RemovedLabels = append(change.RemovedLabels, label)
+	}
+
+	if opts.MilestoneID != nil && *opts.MilestoneID != issue.MilestoneID {
+		oldMilestoneID := issue.MilestoneID
+		issue.MilestoneID = *opts.MilestoneID
+		if err := changeMilestoneAssign(e, doer, issue, oldMilestoneID); err != nil {
+			return nil, fmt.Errorf("changeMilestoneAssign: %v", err)
+		}
+		change.MilestoneChanged = true
+	}
+
+	// changeAssignee toggles the assignee, so the current assignees decide whether anything changes
+	for _, assignee := range targets.addAssignees {
+		if isAssigned, err := e.Exist(&IssueAssignees{IssueID: issue.ID, AssigneeID: assignee.ID}); err != nil {
+			return nil, err
+		} else if isAssigned {
+			continue
+		}
+		if err := issue.loadAssignees(e); err != nil {
+			return nil, err
+		}
+		if err := issue.changeAssignee(e, doer, assignee.ID, false); err != nil {
+			return nil, fmt.Errorf("changeAssignee: %v", err)
+		}
+		change.AddedAssignees = append(change.AddedAssignees, assignee)
+	}
+	for _, assignee := range targets.removeAssignees {
+		if isAssigned, err := e.Exist(&IssueAssignees{IssueID: issue.ID, AssigneeID: assignee.ID}); err != nil {
+			return nil, err
+		} else if !isAssigned {
+			continue
+		}
+		if err := issue.loadAssignees(e); err != nil {
+			return nil, err
+		}
+		if err := issue.changeAssignee(e, doer, assignee.ID, false); err != nil {
+			return nil, fmt.Errorf("changeAssignee: %v", err)
+		}
+		change.RemovedAssignees = append(change.RemovedAssignees, assignee)
+	}
+
+	if opts.RemoveProjectID > 0 {
+		if _, err := e.Delete(&ProjectIssue{ProjectID: opts.RemoveProjectID, IssueID: issue.ID}); err != nil {
+			return nil, err
+		}
+	}
+	if p := targets.addProject; p != nil {
+		if !p.CanContainIssue(issue) {
+			return nil, ErrProjectIssueNotAllowed{ProjectID: p.ID, IssueID: issue.ID}
+		}
+		if _, err := getProjectIssue(e, p.ID, issue.ID); err != nil && !IsErrProjectIssueNotExist(err) {
+			return nil, err
+		} else if IsErrProjectIssueNotExist(err) {
+			sorting, err := nextProjectIssueSorting(e, p.ID, 0)
+			if err != nil {
+				return nil, fmt.Errorf("nextProjectIssueSorting: %v", err)
+			}
+			if _, err = e.Insert(&ProjectIssue{ProjectID: p.ID, IssueID: issue.ID, Sorting: sorting}); err != nil {
+				return nil, err
+			}
+			change.AddedProject = p
+		}
+	}
+
+	if opts.IsClosed != nil && *opts.IsClosed != issue.IsClosed {
+		if err := issue.changeStatus(e, doer, *opts.IsClosed); err != nil {
+			return nil, err
+		}
+		change.StatusChanged = true
+	}
+
+	if opts.IsLocked != nil && *opts.IsLocked != issue.IsLocked {
+		if err := setIssueLock(e, &IssueLockOptions{Doer: doer, Issue: issue, Reason: opts.LockReason}, *opts.IsLocked); err != nil {
+			return nil, fmt.Errorf("setIssueLock: %v", err)
+		}
+		change.LockChanged = true
+	}
+
+	if opts.TransferTo != nil {
+		change.TransferredFrom = issue.Repo
+		if err := transferIssue(e, doer, issue, opts.TransferTo); err != nil {
+			return nil, err
+		}
+	}
+	return change, nil
+}
+
+// BulkEditIssues applies the same changes to all given issues of the repository in one transaction,
+// it returns what changed on each issue. Webhooks are sent after the changes have been committed.
+func BulkEditIssues(doer *User, repo *Repository, issues []*Issue, opts *BulkEditIssuesOptions) ([]*IssueChange, error) {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return nil, err
+	}
+
+	targets, err := opts.validate(sess, doer, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]*IssueChange, 0, len(issues))
+	for _, issue := range issues {
+		if issue.RepoID != repo.ID {
+			return nil, ErrIssueNotExist{ID: issue.ID}
+		}
+		issue.Repo = repo
+		if err = issue.loadPoster(sess); err != nil {
+			return nil, err
+		}
+		change, err := bulkEditIssue(sess, doer, issue, opts, targets)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+
+	if err = sess.Commit(); err != nil {
+		return nil, err
+	}
+	sess.Close()
+
+	for _, change := range changes {
+		change.sendWebhooks(doer)
+	}
+	return changes, nil
+}
+
+// sendWebhooks sends the webhooks of the changes which are not sent within the transaction
+func (change *IssueChange) sendWebhooks(doer *User) {
+	issue := change.Issue
+	if len(change.AddedLabels) > 0 || len(change.RemovedLabels) > 0 {
+		issue.sendLabelUpdatedWebhook(doer)
+	}
+	if change.MilestoneChanged {
+		if err := issue.sendMilestoneChangedWebhook(doer); err != nil {
+			log.Error("sendMilestoneChangedWebhook: %v", err)
+		}
+	}
+	if change.StatusChanged {
+		if err := issue.sendStatusChangedWebhook(doer, issue.IsClosed); err != nil {
+			log.Error("sendStatusChangedWebhook: %v", err)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBulkEditIssues(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	issue1 := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	issue5 := AssertExistsAndLoadBean(t, &Issue{ID: 5}).(*Issue)
+	label1 := AssertExistsAndLoadBean(t, &Label{ID: 1}).(*Label)
+	label2 := AssertExistsAndLoadBean(t, &Label{ID: 2}).(*Label)
+	_, err := x.Insert(&RepoUnit{RepoID: repo.ID, Type: UnitTypeProjects, Config: new(UnitConfig)})
+	assert.NoError(t, err)
+
+	milestoneID := int64(1)
+	isClosed, isLocked := true, true
+	changes, err := BulkEditIssues(doer, repo, []*Issue{issue1, issue5}, &BulkEditIssuesOptions{
+		AddLabelIDs:    []int64{2},
+		RemoveLabelIDs: []int64{1},
+		MilestoneID:    &milestoneID,
+		AddAssigneeIDs: []int64{2},
+		AddProjectID:   1,
+		IsClosed:       &isClosed,
+		IsLocked:       &isLocked,
+		LockReason:     "Spam",
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, changes, 2) {
+		// issue 1 had label 1 and was open, issue 5 had label 2 and was closed
+		assert.Len(t, changes[0].AddedLabels, 1)
+		assert.Len(t, changes[0].RemovedLabels, 1)
+		assert.True(t, changes[0].StatusChanged)
+		assert.Nil(t, changes[0].AddedProject)
+		assert.Len(t, changes[1].AddedLabels, 0)
+		assert.Len(t, changes[1].RemovedLabels, 0)
+		assert.False(t, changes[1].StatusChanged)
+		assert.NotNil(t, changes[1].AddedProject)
+		for _, change := range changes {
+			assert.True(t, change.MilestoneChanged)
+			assert.True(t, change.LockChanged)
+			assert.Len(t, change.AddedAssignees, 1)
+		}
+	}
+
+	for _, id := range []int64{1, 5} {
+		issue := AssertExistsAndLoadBean(t, &Issue{ID: id, MilestoneID: 1}).(*Issue)
+		assert.True(t, issue.IsClosed)
+		assert.True(t, issue.IsLocked)
+		AssertExistsAndLoadBean(t, &IssueLabel{IssueID: id, LabelID: 2})
+		AssertNotExistsBean(t, &IssueLabel{IssueID: id, LabelID: 1})
+		AssertExistsAndLoadBean(t, &IssueAssignees{IssueID: id, AssigneeID: 2})
+		AssertExistsAndLoadBean(t, &ProjectIssue{IssueID: id, ProjectID: 1})
+	}
+	AssertExistsAndLoadBean(t, &Label{ID: 1, NumIssues: label1.NumIssues - 1})
+	AssertExistsAndLoadBean(t, &Label{ID: 2, NumIssues: label2.NumIssues + 1, NumClosedIssues: label2.NumClosedIssues + 1})
+	CheckConsistencyFor(t, &Repository{ID: 1}, &Milestone{ID: 1})
+}
+
+func TestBulkEditIssues_Transfer(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	newRepo := AssertExistsAndLoadBean(t, &Repository{ID: 3}).(*Repository)
+	issue1 := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	issue5 := AssertExistsAndLoadBean(t, &Issue{ID: 5}).(*Issue)
+
+	changes, err := BulkEditIssues(doer, repo, []*Issue{issue1, issue5}, &BulkEditIssuesOptions{TransferTo: newRepo})
+	assert.NoError(t, err)
+	if assert.Len(t, changes, 2) {
+		assert.EqualValues(t, repo.ID, changes[0].TransferredFrom.ID)
+	}
+	AssertExistsAndLoadBean(t, &Issue{ID: 1, RepoID: 3, Index: 2})
+	AssertExistsAndLoadBean(t, &Issue{ID: 5, RepoID: 3, Index: 3})
+	CheckConsistencyFor(t, &Repository{ID: 1}, &Repository{ID: 3})
+}
+
+func TestBulkEditIssues_Invalid(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+
+	test := func(opts *BulkEditIssuesOptions, check func(error) bool) {
+		_, err := BulkEditIssues(doer, repo, []*Issue{issue}, opts)
+		assert.True(t, check(err), "unexpected error: %v", err)
+	}
+	test(&BulkEditIssuesOptions{AddLabelIDs: []int64{1}, RemoveLabelIDs: []int64{1}}, IsErrIssueBulkEditInvalid)
+	test(&BulkEditIssuesOptions{AddAssigneeIDs: []int64{2}, RemoveAssigneeIDs: []int64{2}}, IsErrIssueBulkEditInvalid)
+	test(&BulkEditIssuesOptions{AddLabelIDs: []int64{NonexistentID}}, IsErrLabelNotExist)
+	milestoneID := int64(NonexistentID)
+	test(&BulkEditIssuesOptions{MilestoneID: &milestoneID}, IsErrMilestoneNotExist)
+	test(&BulkEditIssuesOptions{AddAssigneeIDs: []int64{NonexistentID}}, IsErrUserNotExist)
+	// user5 has no write access to repository 1
+	test(&BulkEditIssuesOptions{AddAssigneeIDs: []int64{5}}, IsErrIssueBulkEditInvalid)
+	test(&BulkEditIssuesOptions{AddProjectID: NonexistentID}, IsErrProjectNotExist)
+	test(&BulkEditIssuesOptions{TransferTo: AssertExistsAndLoadBean(t, &Repository{ID: 4}).(*Repository)}, IsErrIssueBulkEditInvalid)
+
+	// nothing is changed if a single issue cannot be edited
+	pull := AssertExistsAndLoadBean(t, &Issue{ID: 2}).(*Issue)
+	_, err := BulkEditIssues(doer, repo, []*Issue{issue, pull}, &BulkEditIssuesOptions{
+		AddLabelIDs: []int64{2},
+		TransferTo:  AssertExistsAndLoadBean(t, &Repository{ID: 3}).(*Repository),
+	})
+	assert.True(t, IsErrIssueCannotTransfer(err))
+	AssertNotExistsBean(t, &IssueLabel{IssueID: 1, LabelID: 2})
+	AssertExistsAndLoadBean(t, &Issue{ID: 1, RepoID: 1})
+}
@@ -84,6 +84,16 @@ const (
 	CommentTypeLock
 	// Unlocks a previously locked issue
 	CommentTypeUnlock
+	// Pull request ejected from the merge queue
+	CommentTypeMergeQueueEject
+	// Draft pull request marked as ready for review
+	CommentTypePullReadyForReview
+	// Pull request converted to a draft
+	CommentTypePullConvertToDraft
+	// Issue transferred from another repository
+	CommentTypeIssueTransfer
+	// Estimate of an issue set or removed
+	CommentTypeChangeEstimate
 )
 
 // CommentTag defines comment tag type
@@ -0,0 +1,138 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-xorm/xorm"
+)
+
+// issueEstimateQuickActionPattern matches a comment line setting the estimate of an issue, like `/estimate 3h`
+var issueEstimateQuickActionPattern = regexp.MustCompile(`^/estimate[ \t]+(.*?)[ \t]*$`)
+
+// estimateReplacer turns estimates formatted by SecToTime into durations understood by time.ParseDuration
+var estimateReplacer = strings.NewReplacer(" ", "", "min", "m")
+
+// estimateDaysPattern matches the day and week units, which time.ParseDuration does not know
+var estimateDaysPattern = regexp.MustCompile(`([0-9]+(?:\.[0-9]+)?)([dw])`)
+
+// ParseEstimate parses an estimate like `3h`, `1h 30min`, `45m` or `1w 2d` into seconds, `0` means no estimate.
+// A day is 24 hours and a week 7 days, like SecToTime counts them.
+func ParseEstimate(estimate string) (int64, error) {
+	duration := estimateDaysPattern.ReplaceAllStringFunc(estimateReplacer.Replace(estimate), func(s string) string {
+		parts := estimateDaysPattern.FindStringSubmatch(s)
+		// the pattern only matches valid numbers
+		n, _ := strconv.ParseFloat(parts[1], 64)
+		hours := n * 24
+		if parts[2] == "w" {
+			hours *= 7
+		}
+		return strconv.FormatFloat(hours, 'f', -1, 64) + "h"
+	})
+	d, err := time.ParseDuration(duration)
+	if err != nil || d < 0 {
+		return 0, ErrInvalidEstimate{estimate}
+	}
+	return int64(d / time.Second), nil
+}
+
+// ExtractEstimateQuickAction removes the `/estimate <duration>` lines from the content of a comment,
+// lines within fenced code blocks are left alone.
+// It returns the remaining content and the estimate of the last of these lines, or -1 if there is none.
+func ExtractEstimateQuickAction(content string) (string, int64, error) {
+	lines := strings.Split(content, "\n")
+	kept := make([]string, 0, len(lines))
+	var (
+		fence, last string
+		found       bool
+	)
+	for _, line := range lines {
+		trimmed := strings.TrimRight(line, "\r")
+		if marker := codeFenceMarker(trimmed); len(marker) > 0 {
+			if len(fence) == 0 {
+				fence = marker
+			} else if marker[0] == fence[0] && len(marker) >= len(fence) {
+				fence = ""
+			}
+		} else if len(fence) == 0 {
+			if matches := issueEstimateQuickActionPattern.FindStringSubmatch(trimmed); matches != nil {
+				last, found = matches[1], true
+				continue
+			}
+		}
+		kept = append(kept, line)
+	}
+	if !found {
+		return content, -1, nil
+	}
+	estimate, err := ParseEstimate(last)
+	if err != nil {
+		return content, -1, err
+	}
+	return strings.TrimSpace(strings.Join(kept, "\n")), estimate, nil
+}
+
+// codeFenceMarker returns the backticks or tildes opening or closing a fenced code block on the line, if any
+func codeFenceMarker(line string) string {
+	line = strings.TrimLeft(line, " ")
+	for _, c := range []byte{'`', '~'} {
+		n := 0
+		for n < len(line) && line[n] == c {
+			n++
+		}
+		if n >= 3 {
+			return line[:n]
+		}
+	}
+	return ""
+}
+
+// UpdateIssueEstimate updates the estimate of an issue in seconds and adds a comment. Setting it to 0 removes it.
+func UpdateIssueEstimate(issue *Issue, estimate int64, doer *User) (err error) {
+	// if the estimate hasn't changed do nothing
+	if issue.EstimatedTime == estimate {
+		return nil
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if err = updateIssueCols(sess, &Issue{ID: issue.ID, EstimatedTime: estimate}, "estimated_time"); err != nil {
+		return err
+	}
+
+	if _, err = createEstimateComment(sess, doer, issue, estimate); err != nil {
+		return fmt.Errorf("createEstimateComment: %v", err)
+	}
+
+	if err = sess.Commit(); err != nil {
+		return err
+	}
+	issue.EstimatedTime = estimate
+	return nil
+}
+
+func createEstimateComment(e *xorm.Session, doer *User, issue *Issue, estimate int64) (*Comment, error) {
+	if err := issue.loadRepo(e); err != nil {
+		return nil, err
+	}
+
+	// an empty content means the estimate was removed
+	return createComment(e, &CreateCommentOptions{
+		Type:    CommentTypeChangeEstimate,
+		Doer:    doer,
+		Repo:    issue.Repo,
+		Issue:   issue,
+		Content: SecToTime(estimate),
+	})
+}
@@ -0,0 +1,83 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseEstimate(t *testing.T) {
+	for estimate, expected := range map[string]int64{
+		"3h":          3 * 60 * 60,
+		"45m":         45 * 60,
+		"1h 30min":    90 * 60,
+		"1h 30min 2s": 90*60 + 2,
+		"0":           0,
+		"2d":          2 * 24 * 60 * 60,
+		"1w 1d 2h":    (8*24 + 2) * 60 * 60,
+		"1.5d":        36 * 60 * 60,
+	} {
+		seconds, err := ParseEstimate(estimate)
+		assert.NoError(t, err)
+		assert.EqualValues(t, expected, seconds, estimate)
+	}
+
+	for _, estimate := range []string{"", "3", "-1h", "three hours", "2dd", "-1w"} {
+		_, err := ParseEstimate(estimate)
+		assert.True(t, IsErrInvalidEstimate(err), estimate)
+	}
+}
+
+func TestExtractEstimateQuickAction(t *testing.T) {
+	content, estimate, err := ExtractEstimateQuickAction("Looks good")
+	assert.NoError(t, err)
+	assert.EqualValues(t, "Looks good", content)
+	assert.EqualValues(t, -1, estimate)
+
+	content, estimate, err = ExtractEstimateQuickAction("/estimate 1h\r\nLooks good\n/estimate 2h 30min ")
+	assert.NoError(t, err)
+	assert.EqualValues(t, "Looks good", content)
+	assert.EqualValues(t, 150*60, estimate)
+
+	// the command has to start a line
+	content, estimate, err = ExtractEstimateQuickAction("Use /estimate 1h")
+	assert.NoError(t, err)
+	assert.EqualValues(t, "Use /estimate 1h", content)
+	assert.EqualValues(t, -1, estimate)
+
+	// commands within fenced code blocks are left alone
+	content, estimate, err = ExtractEstimateQuickAction("/estimate 2d\n```\n/estimate 1h\n```\n~~~~\n```\n/estimate 3h\n~~~~")
+	assert.NoError(t, err)
+	assert.EqualValues(t, "```\n/estimate 1h\n```\n~~~~\n```\n/estimate 3h\n~~~~", content)
+	assert.EqualValues(t, 2*24*60*60, estimate)
+
+	_, _, err = ExtractEstimateQuickAction("/estimate soon")
+	assert.True(t, IsErrInvalidEstimate(err))
+}
+
+func TestUpdateIssueEstimate(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 2}).(*Issue)
+
+	assert.NoError(t, UpdateIssueEstimate(issue, 3600, doer))
+	assert.EqualValues(t, 3600, issue.EstimatedTime)
+	AssertExistsAndLoadBean(t, &Issue{ID: 2, EstimatedTime: 3600})
+	AssertExistsAndLoadBean(t, &Comment{IssueID: 2, Type: CommentTypeChangeEstimate, Content: "1h"})
+
+	// an unchanged estimate adds no comment
+	assert.NoError(t, UpdateIssueEstimate(issue, 3600, doer))
+	AssertCount(t, &Comment{IssueID: 2, Type: CommentTypeChangeEstimate}, 1)
+
+	milestones := MilestoneList{AssertExistsAndLoadBean(t, &Milestone{ID: 1}).(*Milestone)}
+	assert.NoError(t, milestones.LoadTotalEstimatedTimes())
+	assert.EqualValues(t, 3600, milestones[0].TotalEstimatedTime)
+
+	assert.NoError(t, UpdateIssueEstimate(issue, 0, doer))
+	AssertExistsAndLoadBean(t, &Issue{ID: 2}, "estimated_time = 0")
+	AssertExistsAndLoadBean(t, &Comment{IssueID: 2, Type: CommentTypeChangeEstimate}, "content = ''")
+}
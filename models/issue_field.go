@@ -0,0 +1,506 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	api "github.com/masoodkamyab/gitea/modules/structs"
+	"github.com/masoodkamyab/gitea/modules/util"
+
+	"xorm.io/builder"
+)
+
+// IssueFieldType is the kind of values a custom issue field holds
+type IssueFieldType int
+
+const (
+	// IssueFieldTypeText is a field holding free text
+	IssueFieldTypeText IssueFieldType = iota + 1
+	// IssueFieldTypeNumber is a field holding a number
+	IssueFieldTypeNumber
+	// IssueFieldTypeDate is a field holding a day
+	IssueFieldTypeDate
+	// IssueFieldTypeSingleSelect is a field holding one of its options
+	IssueFieldTypeSingleSelect
+	// IssueFieldTypeMultiSelect is a field holding any number of its options
+	IssueFieldTypeMultiSelect
+	// IssueFieldTypeUser is a field holding a user
+	IssueFieldTypeUser
+)
+
+// IssueFieldDateFormat is the layout of the values of date fields
+const IssueFieldDateFormat = "2006-01-02"
+
+var issueFieldTypeNames = map[IssueFieldType]string{
+	IssueFieldTypeText:         "text",
+	IssueFieldTypeNumber:       "number",
+	IssueFieldTypeDate:         "date",
+	IssueFieldTypeSingleSelect: "single_select",
+	IssueFieldTypeMultiSelect:  "multi_select",
+	IssueFieldTypeUser:         "user",
+}
+
+// Name returns the name of the field type used by forms and the API
+func (t IssueFieldType) Name() string {
+	return issueFieldTypeNames[t]
+}
+
+// IsSelect returns true if the values of the field are restricted to its options
+func (t IssueFieldType) IsSelect() bool {
+	return t == IssueFieldTypeSingleSelect || t == IssueFieldTypeMultiSelect
+}
+
+// ParseIssueFieldType returns the field type of the given name
+func ParseIssueFieldType(name string) (IssueFieldType, bool) {
+	for t, n := range issueFieldTypeNames {
+		if n == name {
+			return t, true
+		}
+	}
+	return 0, false
+}
+
+// IssueField represents a custom field of the issues of a repository or of all repositories of an organization
+type IssueField struct {
+	ID          int64  `xorm:"pk autoincr"`
+	OwnerID     int64  `xorm:"INDEX"` // set for the fields of an organization
+	RepoID      int64  `xorm:"INDEX"` // set for the fields of a repository
+	Name        string `xorm:"NOT NULL"`
+	Description string
+	Type        IssueFieldType
+	Options     []string `xorm:"TEXT JSON"`
+
+	CreatedUnix util.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix util.TimeStamp `xorm:"INDEX updated"`
+}
+
+// IsOrgField returns true if the field is defined by an organization
+func (f *IssueField) IsOrgField() bool {
+	return f.OwnerID > 0
+}
+
+// HasOption returns true if option is a choice of the field
+func (f *IssueField) HasOption(option string) bool {
+	for _, o := range f.Options {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+// appliesTo returns true if the issues of the repository can hold values of the field
+func (f *IssueField) appliesTo(repo *Repository) bool {
+	return f.RepoID == repo.ID || (f.IsOrgField() && f.OwnerID == repo.OwnerID)
+}
+
+// APIFormat returns this field in API format
+func (f *IssueField) APIFormat() *api.IssueField {
+	options := f.Options
+	if options == nil {
+		options = []string{}
+	}
+	return &api.IssueField{
+		ID:          f.ID,
+		Name:        f.Name,
+		Description: f.Description,
+		Type:        f.Type.Name(),
+		Options:     options,
+		RepoID:      f.RepoID,
+		OwnerID:     f.OwnerID,
+		Created:     f.CreatedUnix.AsTime(),
+		Updated:     f.UpdatedUnix.AsTime(),
+	}
+}
+
+// validate checks the definition of the field and trims its name and options
+func (f *IssueField) validate(e Engine) error {
+	f.Name = strings.TrimSpace(f.Name)
+	if len(f.Name) == 0 || len(f.Name) > 50 {
+		return ErrIssueFieldInvalid{Name: f.Name, Reason: "the name must have 1 to 50 characters"}
+	} else if len(f.Description) > 255 {
+		return ErrIssueFieldInvalid{Name: f.Name, Reason: "the description must not exceed 255 characters"}
+	} else if len(f.Type.Name()) == 0 {
+		return ErrIssueFieldInvalid{Name: f.Name, Reason: "unknown type"}
+	}
+
+	if f.Type.IsSelect() {
+		options := make([]string, 0, len(f.Options))
+		for _, option := range f.Options {
+			option = strings.TrimSpace(option)
+			if len(option) == 0 {
+				continue
+			}
+			for _, o := range options {
+				if o == option {
+					return ErrIssueFieldInvalid{Name: f.Name, Reason: fmt.Sprintf("duplicate option %q", option)}
+				}
+			}
+			options = append(options, option)
+		}
+		if len(options) == 0 {
+			return ErrIssueFieldInvalid{Name: f.Name, Reason: "select fields need options"}
+		}
+		f.Options = options
+	} else {
+		f.Options = nil
+	}
+
+	// Names are unique within the fields of a repository or an organization
+	cond := builder.Eq{"name": f.Name, "owner_id": f.OwnerID, "repo_id": f.RepoID}.And(builder.Neq{"id": f.ID})
+	if has, err := e.Where(cond).Exist(new(IssueField)); err != nil {
+		return err
+	} else if has {
+		return ErrIssueFieldAlreadyExist{Name: f.Name}
+	}
+	return nil
+}
+
+// NewIssueField creates a custom issue field of a repository or an organization
+func NewIssueField(f *IssueField) error {
+	if (f.RepoID > 0) == (f.OwnerID > 0) {
+		return fmt.Errorf("an issue field belongs either to a repository or an organization")
+	}
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+	if err := f.validate(sess); err != nil {
+		return err
+	}
+	if _, err := sess.Insert(f); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+func getIssueFieldByID(e Engine, id int64) (*IssueField, error) {
+	f := new(IssueField)
+	has, err := e.ID(id).Get(f)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrIssueFieldNotExist{ID: id}
+	}
+	return f, nil
+}
+
+// GetIssueFieldByID returns the custom issue field by given ID
+func GetIssueFieldByID(id int64) (*IssueField, error) {
+	return getIssueFieldByID(x, id)
+}
+
+// GetIssueFieldsByRepoID returns the custom issue fields defined by a repository
+func GetIssueFieldsByRepoID(repoID int64) ([]*IssueField, error) {
+	fields := make([]*IssueField, 0, 5)
+	return fields, x.Where("repo_id = ?", repoID).Asc("id").Find(&fields)
+}
+
+// GetIssueFieldsByOwnerID returns the custom issue fields defined by an organization
+func GetIssueFieldsByOwnerID(ownerID int64) ([]*IssueField, error) {
+	fields := make([]*IssueField, 0, 5)
+	return fields, x.Where("owner_id = ?", ownerID).Asc("id").Find(&fields)
+}
+
+func getIssueFieldsOfRepo(e Engine, repo *Repository) ([]*IssueField, error) {
+	fields := make([]*IssueField, 0, 5)
+	return fields, e.Where(builder.Eq{"repo_id": repo.ID}.Or(builder.Eq{"owner_id": repo.OwnerID})).
+		Asc("repo_id", "id").
+		Find(&fields)
+}
+
+// GetIssueFieldsOfRepo returns the custom fields the issues of a repository can hold,
+// the fields of the organization owning the repository come first
+func GetIssueFieldsOfRepo(repo *Repository) ([]*IssueField, error) {
+	return getIssueFieldsOfRepo(x, repo)
+}
+
+// UpdateIssueField updates the name, description and options of a custom issue field,
+// the values of removed options are removed from the issues
+func UpdateIssueField(f *IssueField) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+	if err := f.validate(sess); err != nil {
+		return err
+	}
+	if _, err := sess.ID(f.ID).Cols("name", "description", "options").Update(f); err != nil {
+		return err
+	}
+	if f.Type.IsSelect() {
+		if _, err := sess.Where("field_id = ?", f.ID).NotIn("value", f.Options).Delete(new(IssueFieldValue)); err != nil {
+			return err
+		}
+	}
+	return sess.Commit()
+}
+
+func deleteIssueFields(e Engine, cond builder.Cond) error {
+	if _, err := e.In("field_id", builder.Select("id").From("issue_field").Where(cond)).Delete(new(IssueFieldValue)); err != nil {
+		return err
+	}
+	_, err := e.Where(cond).Delete(new(IssueField))
+	return err
+}
+
+// DeleteIssueField deletes a custom issue field with all its values
+func DeleteIssueField(id int64) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+	if err := deleteIssueFields(sess, builder.Eq{"id": id}); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// IssueFieldValue represents a value of a custom field set on an issue,
+// multi select fields have a row for each of their options
+type IssueFieldValue struct {
+	ID      int64  `xorm:"pk autoincr"`
+	IssueID int64  `xorm:"INDEX NOT NULL"`
+	FieldID int64  `xorm:"INDEX NOT NULL"`
+	Value   string `xorm:"TEXT"` // the ID of the user for user fields
+}
+
+// IssueFieldValues holds the values of a custom field set on an issue
+type IssueFieldValues struct {
+	Field  *IssueField
+	Values []string // users are given by their name
+}
+
+// APIFormat returns the values in API format
+func (v *IssueFieldValues) APIFormat() *api.IssueFieldValue {
+	return &api.IssueFieldValue{
+		FieldID: v.Field.ID,
+		Name:    v.Field.Name,
+		Type:    v.Field.Type.Name(),
+		Values:  v.Values,
+	}
+}
+
+// toStoredValues validates values given as in the API and converts them to the values stored in the database
+func (f *IssueField) toStoredValues(e Engine, values []string) ([]string, error) {
+	stored := make([]string, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if len(value) == 0 {
+			continue
+		}
+		invalid := ErrIssueFieldValueInvalid{FieldID: f.ID, Value: value}
+
+		switch f.Type {
+		case IssueFieldTypeNumber:
+			number, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, invalid
+			}
+			value = strconv.FormatFloat(number, 'f', -1, 64)
+		case IssueFieldTypeDate:
+			if _, err := time.Parse(IssueFieldDateFormat, value); err != nil {
+				return nil, invalid
+			}
+		case IssueFieldTypeSingleSelect, IssueFieldTypeMultiSelect:
+			if !f.HasOption(value) {
+				return nil, invalid
+			}
+		case IssueFieldTypeUser:
+			u, err := getUserByName(e, value)
+			if IsErrUserNotExist(err) || (err == nil && u.IsOrganization()) {
+				return nil, invalid
+			} else if err != nil {
+				return nil, err
+			}
+			value = strconv.FormatInt(u.ID, 10)
+		}
+
+		duplicate := false
+		for _, v := range stored {
+			duplicate = duplicate || v == value
+		}
+		if !duplicate {
+			stored = append(stored, value)
+		}
+	}
+	if len(stored) > 1 && f.Type != IssueFieldTypeMultiSelect {
+		return nil, ErrIssueFieldValueInvalid{FieldID: f.ID, Value: strings.Join(values, ", ")}
+	}
+	return stored, nil
+}
+
+// SetIssueFieldValues replaces the values of a custom field on an issue, no values remove the field from the issue.
+// Values are given as in the API, users by their name.
+func SetIssueFieldValues(issue *Issue, field *IssueField, values []string) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if err := issue.loadRepo(sess); err != nil {
+		return err
+	}
+	if !field.appliesTo(issue.Repo) {
+		return ErrIssueFieldNotExist{ID: field.ID}
+	}
+	stored, err := field.toStoredValues(sess, values)
+	if err != nil {
+		return err
+	}
+
+	if _, err = sess.Delete(&IssueFieldValue{IssueID: issue.ID, FieldID: field.ID}); err != nil {
+		return err
+	}
+	for _, value := range stored {
+		if _, err = sess.Insert(&IssueFieldValue{IssueID: issue.ID, FieldID: field.ID, Value: value}); err != nil {
+			return err
+		}
+	}
+	if err = sess.Commit(); err != nil {
+		return err
+	}
+	issue.FieldValues = nil
+	return nil
+}
+
+func (issue *Issue) loadFieldValues(e Engine) error {
+	if issue.FieldValues != nil {
+		return nil
+	}
+	return IssueList{issue}.loadFieldValues(e)
+}
+
+// LoadFieldValues loads the values of the custom fields set on the issue
+func (issue *Issue) LoadFieldValues() error {
+	return issue.loadFieldValues(x)
+}
+
+// FieldValuesOf returns the values of a custom field set on the issue, LoadFieldValues must have been called before
+func (issue *Issue) FieldValuesOf(fieldID int64) []string {
+	for _, v := range issue.FieldValues {
+		if v.Field.ID == fieldID {
+			return v.Values
+		}
+	}
+	return nil
+}
+
+// HasFieldValue returns true if value is a value of the custom field on the issue
+func (issue *Issue) HasFieldValue(fieldID int64, value string) bool {
+	for _, v := range issue.FieldValuesOf(fieldID) {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (issues IssueList) loadFieldValues(e Engine) error {
+	if len(issues) == 0 {
+		return nil
+	}
+
+	rows := make([]*IssueFieldValue, 0, len(issues))
+	if err := e.In("issue_id", issues.getIssueIDs()).Asc("id").Find(&rows); err != nil {
+		return fmt.Errorf("find issue field values: %v", err)
+	}
+	fieldIDs := make([]int64, 0, len(rows))
+	for _, row := range rows {
+		fieldIDs = append(fieldIDs, row.FieldID)
+	}
+	fields := make(map[int64]*IssueField, len(fieldIDs))
+	if len(fieldIDs) > 0 {
+		if err := e.In("id", fieldIDs).Find(&fields); err != nil {
+			return fmt.Errorf("find issue fields: %v", err)
+		}
+	}
+	var userIDs []int64
+	for _, row := range rows {
+		if f := fields[row.FieldID]; f != nil && f.Type == IssueFieldTypeUser {
+			if id, err := strconv.ParseInt(row.Value, 10, 64); err == nil {
+				userIDs = append(userIDs, id)
+			}
+		}
+	}
+	users := make(map[int64]*User, len(userIDs))
+	if len(userIDs) > 0 {
+		if err := e.In("id", userIDs).Find(&users); err != nil {
+			return fmt.Errorf("find users: %v", err)
+		}
+	}
+
+	valuesByIssue := make(map[int64]map[int64]*IssueFieldValues, len(issues))
+	for _, row := range rows {
+		f := fields[row.FieldID]
+		if f == nil {
+			continue
+		}
+		value := row.Value
+		if f.Type == IssueFieldTypeUser {
+			id, _ := strconv.ParseInt(row.Value, 10, 64)
+			u := users[id]
+			if u == nil {
+				// the user has been deleted
+				continue
+			}
+			value = u.Name
+		}
+		if valuesByIssue[row.IssueID] == nil {
+			valuesByIssue[row.IssueID] = make(map[int64]*IssueFieldValues)
+		}
+		values := valuesByIssue[row.IssueID][f.ID]
+		if values == nil {
+			values = &IssueFieldValues{Field: f}
+			valuesByIssue[row.IssueID][f.ID] = values
+		}
+		values.Values = append(values.Values, value)
+	}
+
+	for _, issue := range issues {
+		issue.FieldValues = make([]*IssueFieldValues, 0, len(valuesByIssue[issue.ID]))
+		for _, values := range valuesByIssue[issue.ID] {
+			issue.FieldValues = append(issue.FieldValues, values)
+		}
+		// organization fields come first like in GetIssueFieldsOfRepo
+		sort.Slice(issue.FieldValues, func(i, j int) bool {
+			a, b := issue.FieldValues[i].Field, issue.FieldValues[j].Field
+			if a.RepoID != b.RepoID {
+				return a.RepoID < b.RepoID
+			}
+			return a.ID < b.ID
+		})
+	}
+	return nil
+}
+
+// IssueFieldFilter matches the issues holding a value of a custom field, fields are matched by name
+type IssueFieldFilter struct {
+	Name   string
+	Value  string
+	UserID int64 // the user the value names, matched by user fields
+}
+
+func issueIDsByFieldValue(filter IssueFieldFilter) *builder.Builder {
+	cond := builder.Neq{"issue_field.type": IssueFieldTypeUser}.And(builder.Eq{"issue_field_value.value": filter.Value})
+	if filter.UserID > 0 {
+		cond = cond.Or(builder.Eq{
+			"issue_field.type":        IssueFieldTypeUser,
+			"issue_field_value.value": strconv.FormatInt(filter.UserID, 10),
+		})
+	}
+	return builder.Select("issue_field_value.issue_id").From("issue_field_value").
+		Join("INNER", "issue_field", "issue_field.id = issue_field_value.field_id").
+		Where(builder.Eq{"issue_field.name": filter.Name}.And(cond))
+}
@@ -0,0 +1,121 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewIssueField(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	field := &IssueField{RepoID: 1, Name: " Severity ", Type: IssueFieldTypeMultiSelect, Options: []string{"minor", " ", "major "}}
+	assert.NoError(t, NewIssueField(field))
+	AssertExistsAndLoadBean(t, &IssueField{ID: field.ID, RepoID: 1, Name: "Severity"})
+	assert.Equal(t, []string{"minor", "major"}, field.Options)
+
+	// the same name may be used by another repository but not twice by one
+	assert.True(t, IsErrIssueFieldAlreadyExist(NewIssueField(&IssueField{RepoID: 1, Name: "Priority", Type: IssueFieldTypeText})))
+	assert.NoError(t, NewIssueField(&IssueField{RepoID: 2, Name: "Priority", Type: IssueFieldTypeText}))
+
+	assert.True(t, IsErrIssueFieldInvalid(NewIssueField(&IssueField{RepoID: 1, Name: "Component", Type: IssueFieldTypeSingleSelect})))
+	assert.True(t, IsErrIssueFieldInvalid(NewIssueField(&IssueField{RepoID: 1, Name: "Component", Type: IssueFieldTypeSingleSelect, Options: []string{"a", "a"}})))
+	assert.True(t, IsErrIssueFieldInvalid(NewIssueField(&IssueField{RepoID: 1, Name: " ", Type: IssueFieldTypeText})))
+	assert.True(t, IsErrIssueFieldInvalid(NewIssueField(&IssueField{RepoID: 1, Name: "Unknown", Type: 42})))
+	assert.Error(t, NewIssueField(&IssueField{RepoID: 1, OwnerID: 3, Name: "Both", Type: IssueFieldTypeText}))
+}
+
+func TestGetIssueFieldsOfRepo(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	fields, err := GetIssueFieldsOfRepo(AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository))
+	assert.NoError(t, err)
+	if assert.Len(t, fields, 1) {
+		assert.EqualValues(t, 1, fields[0].ID)
+	}
+
+	// the fields of the organization come first
+	assert.NoError(t, NewIssueField(&IssueField{RepoID: 3, Name: "Version", Type: IssueFieldTypeText}))
+	fields, err = GetIssueFieldsOfRepo(AssertExistsAndLoadBean(t, &Repository{ID: 3}).(*Repository))
+	assert.NoError(t, err)
+	if assert.Len(t, fields, 2) {
+		assert.EqualValues(t, 2, fields[0].ID)
+		assert.Equal(t, "Version", fields[1].Name)
+	}
+}
+
+func TestSetIssueFieldValues(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	newField := func(name string, fieldType IssueFieldType, options ...string) *IssueField {
+		field := &IssueField{RepoID: 1, Name: name, Type: fieldType, Options: options}
+		assert.NoError(t, NewIssueField(field))
+		return field
+	}
+	number := newField("Estimate", IssueFieldTypeNumber)
+	date := newField("Due", IssueFieldTypeDate)
+	multi := newField("Platforms", IssueFieldTypeMultiSelect, "linux", "windows", "mac")
+	user := newField("Reviewer", IssueFieldTypeUser)
+
+	assert.NoError(t, SetIssueFieldValues(issue, number, []string{"1.50"}))
+	assert.NoError(t, SetIssueFieldValues(issue, date, []string{"2019-10-01"}))
+	assert.NoError(t, SetIssueFieldValues(issue, multi, []string{"linux", "mac", "linux"}))
+	assert.NoError(t, SetIssueFieldValues(issue, user, []string{"user4"}))
+	AssertExistsAndLoadBean(t, &IssueFieldValue{IssueID: 1, FieldID: number.ID, Value: "1.5"})
+	AssertExistsAndLoadBean(t, &IssueFieldValue{IssueID: 1, FieldID: user.ID, Value: "4"})
+	AssertCount(t, &IssueFieldValue{IssueID: 1, FieldID: multi.ID}, 2)
+
+	assert.NoError(t, issue.LoadFieldValues())
+	assert.Len(t, issue.FieldValues, 5)
+	assert.Equal(t, []string{"High"}, issue.FieldValuesOf(1))
+	assert.Equal(t, []string{"linux", "mac"}, issue.FieldValuesOf(multi.ID))
+	assert.Equal(t, []string{"user4"}, issue.FieldValuesOf(user.ID))
+	apiIssue := issue.APIFormat()
+	if assert.Len(t, apiIssue.Fields, 5) {
+		assert.Equal(t, "Priority", apiIssue.Fields[0].Name)
+		assert.Equal(t, "single_select", apiIssue.Fields[0].Type)
+	}
+
+	invalid := func(field *IssueField, values ...string) {
+		assert.True(t, IsErrIssueFieldValueInvalid(SetIssueFieldValues(issue, field, values)), "%s: %v", field.Name, values)
+	}
+	invalid(number, "many")
+	invalid(date, "yesterday")
+	invalid(multi, "bsd")
+	invalid(user, "nonexistent")
+	invalid(user, "user3") // an organization
+	invalid(number, "1", "2")
+
+	// fields of other repositories do not apply
+	orgField := AssertExistsAndLoadBean(t, &IssueField{ID: 2}).(*IssueField)
+	assert.True(t, IsErrIssueFieldNotExist(SetIssueFieldValues(issue, orgField, []string{"ACME"})))
+
+	// no values remove the field from the issue
+	assert.NoError(t, SetIssueFieldValues(issue, multi, nil))
+	AssertNotExistsBean(t, &IssueFieldValue{IssueID: 1, FieldID: multi.ID})
+}
+
+func TestUpdateIssueField(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	field := AssertExistsAndLoadBean(t, &IssueField{ID: 1}).(*IssueField)
+	field.Name = "Urgency"
+	field.Options = []string{"Low", "Medium"}
+	assert.NoError(t, UpdateIssueField(field))
+	AssertExistsAndLoadBean(t, &IssueField{ID: 1, Name: "Urgency"})
+	// the value of the removed option is gone
+	AssertNotExistsBean(t, &IssueFieldValue{ID: 1})
+}
+
+func TestDeleteIssueField(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	assert.NoError(t, DeleteIssueField(1))
+	AssertNotExistsBean(t, &IssueField{ID: 1})
+	AssertNotExistsBean(t, &IssueFieldValue{FieldID: 1})
+}
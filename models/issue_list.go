@@ -483,6 +483,10 @@ func (issues IssueList) loadAttributes(e Engine) error {
 		return fmt.Errorf("issue.loadAttributes: loadAssignees: %v", err)
 	}
 
+	if err := issues.loadFieldValues(e); err != nil {
+		return fmt.Errorf("issue.loadAttributes: loadFieldValues: %v", err)
+	}
+
 	if err := issues.loadPullRequests(e); err != nil {
 		return fmt.Errorf("issue.loadAttributes: loadPullRequests: %v", err)
 	}
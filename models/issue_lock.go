@@ -4,6 +4,8 @@
 
 package models
 
+import "github.com/go-xorm/xorm"
+
 // IssueLockOptions defines options for locking and/or unlocking an issue/PR
 type IssueLockOptions struct {
 	Doer   *User
@@ -23,6 +25,19 @@ func UnlockIssue(opts *IssueLockOptions) error {
 }
 
 func updateIssueLock(opts *IssueLockOptions, lock bool) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if err := setIssueLock(sess, opts, lock); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+func setIssueLock(e *xorm.Session, opts *IssueLockOptions, lock bool) error {
 	if opts.Issue.IsLocked == lock {
 		return nil
 	}
@@ -36,11 +51,11 @@ func updateIssueLock(opts *IssueLockOptions, lock bool) error {
 		commentType = CommentTypeUnlock
 	}
 
-	if err := UpdateIssueCols(opts.Issue, "is_locked"); err != nil {
+	if err := updateIssueCols(e, opts.Issue, "is_locked"); err != nil {
 		return err
 	}
 
-	_, err := CreateComment(&CreateCommentOptions{
+	_, err := createComment(e, &CreateCommentOptions{
 		Doer:    opts.Doer,
 		Issue:   opts.Issue,
 		Repo:    opts.Issue.Repo,
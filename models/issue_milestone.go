@@ -12,6 +12,7 @@ import (
 	api "github.com/masoodkamyab/gitea/modules/structs"
 	"github.com/masoodkamyab/gitea/modules/util"
 	"github.com/go-xorm/xorm"
+	"xorm.io/builder"
 )
 
 // Milestone represents a milestone of repository.
@@ -32,7 +33,8 @@ type Milestone struct {
 	DeadlineUnix   util.TimeStamp
 	ClosedDateUnix util.TimeStamp
 
-	TotalTrackedTime int64 `xorm:"-"`
+	TotalTrackedTime   int64 `xorm:"-"`
+	TotalEstimatedTime int64 `xorm:"-"`
 }
 
 // BeforeUpdate is invoked from XORM before updating this object.
@@ -181,6 +183,48 @@ func (milestones MilestoneList) LoadTotalTrackedTimes() error {
 	return milestones.loadTotalTrackedTimes(x)
 }
 
+func (milestones MilestoneList) loadTotalEstimatedTimes(e Engine) error {
+	type totalEstimatesByMilestone struct {
+		MilestoneID   int64
+		EstimatedTime int64
+	}
+	if len(milestones) == 0 {
+		return nil
+	}
+	var estimatedTimes = make(map[int64]int64, len(milestones))
+
+	// Get total estimated time by milestone_id
+	rows, err := e.Table("issue").
+		Select("milestone_id, sum(estimated_time) as estimated_time").
+		In("milestone_id", milestones.getMilestoneIDs()).
+		GroupBy("milestone_id").
+		Rows(new(totalEstimatesByMilestone))
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var totalEstimate totalEstimatesByMilestone
+		err = rows.Scan(&totalEstimate)
+		if err != nil {
+			return err
+		}
+		estimatedTimes[totalEstimate.MilestoneID] = totalEstimate.EstimatedTime
+	}
+
+	for _, milestone := range milestones {
+		milestone.TotalEstimatedTime = estimatedTimes[milestone.ID]
+	}
+	return nil
+}
+
+// LoadTotalEstimatedTimes loads for every milestone in the list the TotalEstimatedTime by a batch request
+func (milestones MilestoneList) LoadTotalEstimatedTimes() error {
+	return milestones.loadTotalEstimatedTimes(x)
+}
+
 func (milestones MilestoneList) getMilestoneIDs() []int64 {
 	var ids = make([]int64, 0, len(milestones))
 	for _, ms := range milestones {
@@ -293,6 +337,12 @@ func ChangeMilestoneStatus(m *Milestone, isClosed bool) (err error) {
 	if err = updateMilestone(sess, m); err != nil {
 		return err
 	}
+	// the burndown of a closed milestone ends with its state when it was closed
+	if isClosed {
+		if err = saveMilestoneSnapshot(sess, m); err != nil {
+			return err
+		}
+	}
 
 	numMilestones, err := countRepoMilestones(sess, repo.ID)
 	if err != nil {
@@ -394,6 +444,10 @@ func ChangeMilestoneAssign(issue *Issue, doer *User, oldMilestoneID int64) (err
 		return fmt.Errorf("Commit: %v", err)
 	}
 
+	return issue.sendMilestoneChangedWebhook(doer)
+}
+
+func (issue *Issue) sendMilestoneChangedWebhook(doer *User) (err error) {
 	var hookAction api.HookIssueAction
 	if issue.MilestoneID > 0 {
 		hookAction = api.HookIssueMilestoned
@@ -457,6 +511,10 @@ func DeleteMilestoneByRepoID(repoID, id int64) error {
 		return err
 	}
 
+	if err = deleteMilestoneSnapshots(sess, builder.Eq{"id": m.ID}); err != nil {
+		return err
+	}
+
 	if _, err = sess.ID(m.ID).Delete(new(Milestone)); err != nil {
 		return err
 	}
@@ -0,0 +1,182 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"math"
+	"time"
+
+	"github.com/masoodkamyab/gitea/modules/log"
+	api "github.com/masoodkamyab/gitea/modules/structs"
+	"github.com/masoodkamyab/gitea/modules/util"
+
+	"xorm.io/builder"
+)
+
+// MilestoneSnapshot records the state of a milestone on a day
+type MilestoneSnapshot struct {
+	ID              int64          `xorm:"pk autoincr"`
+	MilestoneID     int64          `xorm:"UNIQUE(s) NOT NULL"`
+	DayUnix         util.TimeStamp `xorm:"UNIQUE(s) NOT NULL"`
+	NumIssues       int
+	NumClosedIssues int
+	NumOpenIssues   int `xorm:"-"`
+	TrackedTime     int64
+	EstimatedTime   int64 `xorm:"NOT NULL DEFAULT 0"`
+}
+
+// AfterLoad is invoked from XORM after setting the value of a field of
+// this object.
+func (s *MilestoneSnapshot) AfterLoad() {
+	s.NumOpenIssues = s.NumIssues - s.NumClosedIssues
+}
+
+// APIFormat returns this MilestoneSnapshot in API format.
+func (s *MilestoneSnapshot) APIFormat() *api.MilestoneSnapshot {
+	return &api.MilestoneSnapshot{
+		Date:          s.DayUnix.AsTime(),
+		OpenIssues:    s.NumOpenIssues,
+		ClosedIssues:  s.NumClosedIssues,
+		TrackedTime:   s.TrackedTime,
+		EstimatedTime: s.EstimatedTime,
+	}
+}
+
+// snapshotDay returns the start of the day of t, snapshots are taken once per day
+func snapshotDay(t time.Time) util.TimeStamp {
+	year, month, day := t.Date()
+	return util.TimeStamp(time.Date(year, month, day, 0, 0, 0, 0, t.Location()).Unix())
+}
+
+// newMilestoneSnapshot returns the current state of a milestone
+func newMilestoneSnapshot(e Engine, m *Milestone) (*MilestoneSnapshot, error) {
+	milestones := MilestoneList{m}
+	if err := milestones.loadTotalTrackedTimes(e); err != nil {
+		return nil, err
+	}
+	if err := milestones.loadTotalEstimatedTimes(e); err != nil {
+		return nil, err
+	}
+	s := &MilestoneSnapshot{
+		MilestoneID:     m.ID,
+		DayUnix:         snapshotDay(time.Now()),
+		NumIssues:       m.NumIssues,
+		NumClosedIssues: m.NumClosedIssues,
+		TrackedTime:     m.TotalTrackedTime,
+		EstimatedTime:   m.TotalEstimatedTime,
+	}
+	s.AfterLoad()
+	return s, nil
+}
+
+// saveMilestoneSnapshot records the current state of a milestone, replacing the snapshot of the same day
+func saveMilestoneSnapshot(e Engine, m *Milestone) error {
+	s, err := newMilestoneSnapshot(e, m)
+	if err != nil {
+		return err
+	}
+	if _, err = e.Delete(&MilestoneSnapshot{MilestoneID: s.MilestoneID, DayUnix: s.DayUnix}); err != nil {
+		return err
+	}
+	_, err = e.Insert(s)
+	return err
+}
+
+// CreateMilestoneSnapshots records the state of all open milestones for the burndown charts
+func CreateMilestoneSnapshots() {
+	if !taskStatusTable.StartIfNotRunning(`milestone_snapshots`) {
+		return
+	}
+	defer taskStatusTable.Stop(`milestone_snapshots`)
+
+	log.Trace("Doing: CreateMilestoneSnapshots")
+
+	if err := x.Where("is_closed = ?", false).Iterate(new(Milestone), func(idx int, bean interface{}) error {
+		m := bean.(*Milestone)
+		if err := saveMilestoneSnapshot(x, m); err != nil {
+			log.Error("saveMilestoneSnapshot [%d]: %v", m.ID, err)
+		}
+		return nil
+	}); err != nil {
+		log.Error("CreateMilestoneSnapshots: %v", err)
+	}
+}
+
+func deleteMilestoneSnapshots(e Engine, milestoneCond builder.Cond) error {
+	_, err := e.In("milestone_id", builder.Select("id").From("milestone").Where(milestoneCond)).
+		Delete(new(MilestoneSnapshot))
+	return err
+}
+
+// MilestoneBurndown represents the history of a milestone
+type MilestoneBurndown struct {
+	Milestone *Milestone
+	// Snapshots are ordered by day, the last one of an open milestone is its current state
+	Snapshots []*MilestoneSnapshot
+	// Forecast is the day all issues are expected to be closed at the closing rate so far,
+	// it is zero if the milestone is closed or no issue was closed yet
+	Forecast util.TimeStamp
+}
+
+// GetMilestoneBurndown returns the history of a milestone
+func GetMilestoneBurndown(m *Milestone) (*MilestoneBurndown, error) {
+	burndown := &MilestoneBurndown{Milestone: m}
+	if err := x.Where("milestone_id = ?", m.ID).
+		Asc("day_unix").
+		Find(&burndown.Snapshots); err != nil {
+		return nil, err
+	}
+	if m.IsClosed {
+		return burndown, nil
+	}
+
+	current, err := newMilestoneSnapshot(x, m)
+	if err != nil {
+		return nil, err
+	}
+	if n := len(burndown.Snapshots); n > 0 && burndown.Snapshots[n-1].DayUnix == current.DayUnix {
+		burndown.Snapshots = burndown.Snapshots[:n-1]
+	}
+	burndown.Snapshots = append(burndown.Snapshots, current)
+	burndown.Forecast = burndown.forecast()
+	return burndown, nil
+}
+
+// forecast extrapolates the closing rate between the first and the last snapshot
+func (b *MilestoneBurndown) forecast() util.TimeStamp {
+	first, last := b.Snapshots[0], b.Snapshots[len(b.Snapshots)-1]
+	if last.NumOpenIssues <= 0 {
+		return last.DayUnix
+	}
+	days := float64(last.DayUnix-first.DayUnix) / (24 * 60 * 60)
+	closed := float64(last.NumClosedIssues - first.NumClosedIssues)
+	if days <= 0 || closed <= 0 {
+		return 0
+	}
+	remaining := math.Ceil(float64(last.NumOpenIssues) * days / closed)
+	return util.TimeStamp(last.DayUnix.AsTime().AddDate(0, 0, int(remaining)).Unix())
+}
+
+// IsForecastOverdue returns true if the milestone is expected to be completed after its deadline
+func (b *MilestoneBurndown) IsForecastOverdue() bool {
+	return b.Forecast > 0 && b.Milestone.DeadlineUnix.Year() < 9999 && b.Forecast > b.Milestone.DeadlineUnix
+}
+
+// APIFormat returns this MilestoneBurndown in API format.
+func (b *MilestoneBurndown) APIFormat() *api.MilestoneBurndown {
+	apiBurndown := &api.MilestoneBurndown{
+		Snapshots: make([]*api.MilestoneSnapshot, len(b.Snapshots)),
+	}
+	for i, s := range b.Snapshots {
+		apiBurndown.Snapshots[i] = s.APIFormat()
+	}
+	if b.Milestone.DeadlineUnix.Year() < 9999 {
+		apiBurndown.Deadline = b.Milestone.DeadlineUnix.AsTimePtr()
+	}
+	if b.Forecast > 0 {
+		apiBurndown.Forecast = b.Forecast.AsTimePtr()
+	}
+	return apiBurndown
+}
@@ -0,0 +1,81 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/masoodkamyab/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateMilestoneSnapshots(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	CreateMilestoneSnapshots()
+	// a second run on the same day replaces the snapshots
+	CreateMilestoneSnapshots()
+
+	snapshot := AssertExistsAndLoadBean(t, &MilestoneSnapshot{MilestoneID: 1}).(*MilestoneSnapshot)
+	assert.EqualValues(t, snapshotDay(time.Now()), snapshot.DayUnix)
+	assert.EqualValues(t, 1, snapshot.NumIssues)
+	assert.EqualValues(t, 1, snapshot.NumOpenIssues)
+	assert.EqualValues(t, 3662, snapshot.TrackedTime)
+	AssertExistsAndLoadBean(t, &MilestoneSnapshot{MilestoneID: 2})
+	AssertNotExistsBean(t, &MilestoneSnapshot{MilestoneID: 3})
+	AssertCount(t, &MilestoneSnapshot{}, 2)
+
+	assert.NoError(t, DeleteMilestoneByRepoID(1, 1))
+	AssertNotExistsBean(t, &MilestoneSnapshot{MilestoneID: 1})
+}
+
+func TestGetMilestoneBurndown(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	now := time.Now()
+	milestone := AssertExistsAndLoadBean(t, &Milestone{ID: 1}).(*Milestone)
+	milestone.NumIssues = 4
+	milestone.NumClosedIssues = 2
+	milestone.DeadlineUnix = util.TimeStamp(now.AddDate(0, 0, 5).Unix())
+	assert.NoError(t, UpdateMilestone(milestone))
+	_, err := x.Insert(&MilestoneSnapshot{
+		MilestoneID: milestone.ID,
+		DayUnix:     snapshotDay(now.AddDate(0, 0, -10)),
+		NumIssues:   4,
+	})
+	assert.NoError(t, err)
+
+	burndown, err := GetMilestoneBurndown(milestone)
+	assert.NoError(t, err)
+	if assert.Len(t, burndown.Snapshots, 2) {
+		assert.EqualValues(t, 4, burndown.Snapshots[0].NumOpenIssues)
+		assert.EqualValues(t, 2, burndown.Snapshots[1].NumOpenIssues)
+		assert.EqualValues(t, snapshotDay(now), burndown.Snapshots[1].DayUnix)
+	}
+	// two issues were closed in ten days, the two open ones take another ten days
+	assert.EqualValues(t, snapshotDay(now.AddDate(0, 0, 10)), burndown.Forecast)
+	assert.True(t, burndown.IsForecastOverdue())
+
+	apiBurndown := burndown.APIFormat()
+	assert.Len(t, apiBurndown.Snapshots, 2)
+	assert.NotNil(t, apiBurndown.Deadline)
+	assert.NotNil(t, apiBurndown.Forecast)
+
+	// closing a milestone records its final state
+	assert.NoError(t, ChangeMilestoneStatus(milestone, true))
+	burndown, err = GetMilestoneBurndown(milestone)
+	assert.NoError(t, err)
+	assert.Len(t, burndown.Snapshots, 2)
+	assert.EqualValues(t, 0, burndown.Forecast)
+
+	// a milestone without open issues is complete
+	milestone = AssertExistsAndLoadBean(t, &Milestone{ID: 2}).(*Milestone)
+	burndown, err = GetMilestoneBurndown(milestone)
+	assert.NoError(t, err)
+	assert.Len(t, burndown.Snapshots, 1)
+	assert.EqualValues(t, snapshotDay(now), burndown.Forecast)
+}
@@ -0,0 +1,333 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/masoodkamyab/gitea/modules/util"
+
+	"github.com/Unknwon/com"
+	"xorm.io/builder"
+)
+
+// IssueQueryCurrentUser stands for the signed in user in the user qualifiers of an issue query
+const IssueQueryCurrentUser = "@me"
+
+// IssueQuery is a parsed issue search query such as
+// `is:open is:pr label:bug -label:wontfix author:alice assignee:@me milestone:"v1.2" field:"Customer=ACME" created:>2019-01-01 sort:updated-desc text`
+type IssueQuery struct {
+	Keyword  string
+	IsClosed util.OptionalBool
+	IsPull   util.OptionalBool
+
+	Labels         []string
+	ExcludedLabels []string
+	Author         string
+	Assignee       string
+	Mentions       string
+	Milestone      string
+	Repo           string
+	Fields         []IssueFieldFilter
+
+	CreatedAfter  util.TimeStamp
+	CreatedBefore util.TimeStamp
+	UpdatedAfter  util.TimeStamp
+	UpdatedBefore util.TimeStamp
+
+	SortType string
+}
+
+var issueQuerySortTypes = map[string]string{
+	"created":       "newest",
+	"created-desc":  "newest",
+	"created-asc":   "oldest",
+	"updated":       "recentupdate",
+	"updated-desc":  "recentupdate",
+	"updated-asc":   "leastupdate",
+	"comments":      "mostcomment",
+	"comments-desc": "mostcomment",
+	"comments-asc":  "leastcomment",
+	"due":           "nearduedate",
+	"due-asc":       "nearduedate",
+	"due-desc":      "farduedate",
+	"priority":      "priority",
+}
+
+// splitIssueQuery splits a query at white spaces outside of double quotes, the quotes are removed
+func splitIssueQuery(query string) []string {
+	var (
+		tokens  []string
+		current strings.Builder
+		quoted  bool
+		started bool
+	)
+	for _, r := range query {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			started = true
+		case unicode.IsSpace(r) && !quoted:
+			if started {
+				tokens = append(tokens, current.String())
+				current.Reset()
+				started = false
+			}
+		default:
+			current.WriteRune(r)
+			started = true
+		}
+	}
+	if started {
+		tokens = append(tokens, current.String())
+	}
+	return tokens
+}
+
+// parseIssueQueryDate parses a day of the server time zone
+func parseIssueQueryDate(value string) (util.TimeStamp, bool) {
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return 0, false
+	}
+	return util.TimeStamp(t.Unix()), true
+}
+
+// parseIssueQueryDateRange parses `>D`, `>=D`, `<D`, `<=D`, `D` and `D1..D2` into
+// an inclusive lower and an exclusive upper bound, zero for an open bound
+func parseIssueQueryDateRange(value string) (after, before util.TimeStamp, ok bool) {
+	const day = 24 * 60 * 60
+	var date util.TimeStamp
+	switch {
+	case strings.HasPrefix(value, ">="):
+		after, ok = parseIssueQueryDate(value[2:])
+	case strings.HasPrefix(value, ">"):
+		date, ok = parseIssueQueryDate(value[1:])
+		after = date + day
+	case strings.HasPrefix(value, "<="):
+		date, ok = parseIssueQueryDate(value[2:])
+		before = date + day
+	case strings.HasPrefix(value, "<"):
+		before, ok = parseIssueQueryDate(value[1:])
+	case strings.Contains(value, ".."):
+		bounds := strings.SplitN(value, "..", 2)
+		ok = true
+		if bounds[0] != "*" {
+			after, ok = parseIssueQueryDate(bounds[0])
+		}
+		if ok && bounds[1] != "*" {
+			date, ok = parseIssueQueryDate(bounds[1])
+			before = date + day
+		}
+	default:
+		after, ok = parseIssueQueryDate(value)
+		before = after + day
+	}
+	return after, before, ok
+}
+
+// ParseIssueQuery parses an issue search query, the words which are no known qualifier make up its keyword
+func ParseIssueQuery(query string) *IssueQuery {
+	q := &IssueQuery{}
+	var keywords []string
+	for _, token := range splitIssueQuery(query) {
+		negated := strings.HasPrefix(token, "-")
+		qualifier, value := token, ""
+		if idx := strings.IndexByte(token, ':'); idx > 0 {
+			qualifier, value = strings.ToLower(strings.TrimPrefix(token[:idx], "-")), token[idx+1:]
+		}
+		if len(value) == 0 || (negated && qualifier != "label") {
+			keywords = append(keywords, token)
+			continue
+		}
+
+		ok := true
+		switch qualifier {
+		case "is":
+			switch strings.ToLower(value) {
+			case "open":
+				q.IsClosed = util.OptionalBoolFalse
+			case "closed":
+				q.IsClosed = util.OptionalBoolTrue
+			case "issue":
+				q.IsPull = util.OptionalBoolFalse
+			case "pr", "pull":
+				q.IsPull = util.OptionalBoolTrue
+			default:
+				ok = false
+			}
+		case "label":
+			if negated {
+				q.ExcludedLabels = append(q.ExcludedLabels, value)
+			} else {
+				q.Labels = append(q.Labels, value)
+			}
+		case "author":
+			q.Author = value
+		case "assignee":
+			q.Assignee = value
+		case "mentions":
+			q.Mentions = value
+		case "milestone":
+			q.Milestone = value
+		case "repo":
+			q.Repo = value
+		case "field":
+			idx := strings.IndexByte(value, '=')
+			if ok = idx > 0 && idx < len(value)-1; ok {
+				q.Fields = append(q.Fields, IssueFieldFilter{Name: value[:idx], Value: value[idx+1:]})
+			}
+		case "created":
+			q.CreatedAfter, q.CreatedBefore, ok = parseIssueQueryDateRange(value)
+		case "updated":
+			q.UpdatedAfter, q.UpdatedBefore, ok = parseIssueQueryDateRange(value)
+		case "sort":
+			q.SortType, ok = issueQuerySortTypes[strings.ToLower(value)]
+		default:
+			ok = false
+		}
+		if !ok {
+			keywords = append(keywords, token)
+		}
+	}
+	q.Keyword = strings.Join(keywords, " ")
+	return q
+}
+
+// resolveIssueQueryUser returns the ID of the user named in a qualifier, zero if there is no such user
+func resolveIssueQueryUser(name string, doer *User) (int64, error) {
+	if strings.EqualFold(name, IssueQueryCurrentUser) {
+		if doer == nil {
+			return 0, nil
+		}
+		return doer.ID, nil
+	}
+	u, err := GetUserByName(strings.TrimPrefix(name, "@"))
+	if IsErrUserNotExist(err) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	return u.ID, nil
+}
+
+// applyIssueQueryUser narrows a user filter of the options, it returns false if no issue can match
+func applyIssueQueryUser(id *int64, name string, doer *User) (bool, error) {
+	if len(name) == 0 {
+		return true, nil
+	}
+	userID, err := resolveIssueQueryUser(name, doer)
+	if err != nil || userID == 0 {
+		return false, err
+	}
+	if *id > 0 && *id != userID {
+		return false, nil
+	}
+	*id = userID
+	return true, nil
+}
+
+// Apply narrows opts down to the issues matching the qualifiers of the query, the keyword is
+// left to the issue indexer. It returns false if the query cannot match any issue.
+func (q *IssueQuery) Apply(opts *IssuesOptions, doer *User) (bool, error) {
+	if q.IsClosed != util.OptionalBoolNone {
+		opts.IsClosed = q.IsClosed
+	}
+	if q.IsPull != util.OptionalBoolNone {
+		if opts.IsPull != util.OptionalBoolNone && opts.IsPull != q.IsPull {
+			return false, nil
+		}
+		opts.IsPull = q.IsPull
+	}
+	if len(q.SortType) > 0 {
+		opts.SortType = q.SortType
+	}
+
+	if len(q.Repo) > 0 {
+		fields := strings.SplitN(q.Repo, "/", 2)
+		if len(fields) != 2 {
+			return false, nil
+		}
+		repo, err := GetRepositoryByOwnerAndName(fields[0], fields[1])
+		if IsErrRepoNotExist(err) {
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+		// the qualifier only narrows the repositories, without any the doer
+		// has to be able to see the named one
+		if len(opts.RepoIDs) > 0 {
+			if !com.IsSliceContainsInt64(opts.RepoIDs, repo.ID) {
+				return false, nil
+			}
+		} else {
+			var doerID int64
+			if doer != nil {
+				doerID = doer.ID
+			}
+			if has, err := HasAccess(doerID, repo); err != nil || !has {
+				return false, err
+			}
+		}
+		opts.RepoIDs = []int64{repo.ID}
+	}
+
+	for _, user := range []struct {
+		id   *int64
+		name string
+	}{
+		{&opts.PosterID, q.Author},
+		{&opts.AssigneeID, q.Assignee},
+		{&opts.MentionedID, q.Mentions},
+	} {
+		if ok, err := applyIssueQueryUser(user.id, user.name, doer); !ok || err != nil {
+			return false, err
+		}
+	}
+
+	if len(q.Milestone) > 0 {
+		// milestones are only looked up in the repositories to search
+		if len(opts.RepoIDs) == 0 {
+			return false, nil
+		}
+		milestoneIDs := make([]int64, 0, len(opts.RepoIDs))
+		if err := x.Table("milestone").Cols("id").
+			Where(builder.Eq{"name": q.Milestone}.And(builder.In("repo_id", opts.RepoIDs))).
+			Find(&milestoneIDs); err != nil {
+			return false, err
+		} else if len(milestoneIDs) == 0 {
+			return false, nil
+		}
+		opts.MilestoneIDs = milestoneIDs
+	}
+
+	for _, filter := range q.Fields {
+		// the value may name a user for user fields
+		userID, err := resolveIssueQueryUser(filter.Value, doer)
+		if err != nil {
+			return false, err
+		}
+		filter.UserID = userID
+		opts.FieldValues = append(opts.FieldValues, filter)
+	}
+
+	opts.LabelNames = append(opts.LabelNames, q.Labels...)
+	opts.ExcludedLabelNames = append(opts.ExcludedLabelNames, q.ExcludedLabels...)
+	if q.CreatedAfter > opts.CreatedAfterUnix {
+		opts.CreatedAfterUnix = q.CreatedAfter
+	}
+	if q.CreatedBefore > 0 && (opts.CreatedBeforeUnix == 0 || q.CreatedBefore < opts.CreatedBeforeUnix) {
+		opts.CreatedBeforeUnix = q.CreatedBefore
+	}
+	if q.UpdatedAfter > opts.UpdatedAfterUnix {
+		opts.UpdatedAfterUnix = q.UpdatedAfter
+	}
+	if q.UpdatedBefore > 0 && (opts.UpdatedBeforeUnix == 0 || q.UpdatedBefore < opts.UpdatedBeforeUnix) {
+		opts.UpdatedBeforeUnix = q.UpdatedBefore
+	}
+	return true, nil
+}
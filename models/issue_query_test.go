@@ -0,0 +1,125 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/masoodkamyab/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseIssueQuery(t *testing.T) {
+	day := func(year int, month time.Month, d int) util.TimeStamp {
+		return util.TimeStamp(time.Date(year, month, d, 0, 0, 0, 0, time.Local).Unix())
+	}
+
+	q := ParseIssueQuery(`is:open is:pr label:bug -label:wontfix author:alice assignee:@me milestone:"v1.2 final" created:>2019-01-01 sort:updated-desc crash "on start"`)
+	assert.Equal(t, &IssueQuery{
+		Keyword:        "crash on start",
+		IsClosed:       util.OptionalBoolFalse,
+		IsPull:         util.OptionalBoolTrue,
+		Labels:         []string{"bug"},
+		ExcludedLabels: []string{"wontfix"},
+		Author:         "alice",
+		Assignee:       "@me",
+		Milestone:      "v1.2 final",
+		CreatedAfter:   day(2019, time.January, 2),
+		SortType:       "recentupdate",
+	}, q)
+
+	q = ParseIssueQuery("updated:2019-01-01..2019-01-31 created:<=2019-03-01")
+	assert.Equal(t, day(2019, time.January, 1), q.UpdatedAfter)
+	assert.Equal(t, day(2019, time.February, 1), q.UpdatedBefore)
+	assert.Equal(t, util.TimeStamp(0), q.CreatedAfter)
+	assert.Equal(t, day(2019, time.March, 2), q.CreatedBefore)
+
+	q = ParseIssueQuery(`field:"Customer=ACME Inc" field:Priority= field:=High`)
+	assert.Equal(t, []IssueFieldFilter{{Name: "Customer", Value: "ACME Inc"}}, q.Fields)
+	assert.Equal(t, "field:Priority= field:=High", q.Keyword)
+
+	// unknown qualifiers and invalid values are searched for as text
+	q = ParseIssueQuery("is:unknown foo:bar -author:alice created:yesterday sort:random label:")
+	assert.Equal(t, "is:unknown foo:bar -author:alice created:yesterday sort:random label:", q.Keyword)
+	assert.True(t, q.IsClosed.IsNone())
+	assert.Empty(t, q.SortType)
+}
+
+func TestIssueQuery_Apply(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+
+	test := func(query string, expectedIDs ...int64) {
+		opts := &IssuesOptions{RepoIDs: []int64{1}, SortType: "oldest"}
+		ok, err := ParseIssueQuery(query).Apply(opts, doer)
+		assert.NoError(t, err)
+		if !ok {
+			assert.Empty(t, expectedIDs, query)
+			return
+		}
+		issues, err := Issues(opts)
+		assert.NoError(t, err)
+		var ids []int64
+		for _, issue := range issues {
+			ids = append(ids, issue.ID)
+		}
+		assert.Equal(t, expectedIDs, ids, query)
+
+		count, err := CountIssues(opts)
+		assert.NoError(t, err)
+		assert.EqualValues(t, len(expectedIDs), count, query)
+	}
+
+	test("", 1, 2, 3, 5)
+	test("is:open is:issue", 1)
+	test("is:pr", 2, 3)
+	test("label:label1", 1, 2)
+	test("label:label1 -label:label2 is:issue", 1)
+	test("-label:label1", 3, 5)
+	test("author:@me", 5)
+	test("author:user1 is:closed")
+	test("author:nonexistent")
+	test("milestone:milestone1", 2)
+	test(`milestone:"no such milestone"`)
+	test("repo:user2/repo1 is:closed", 5)
+	test("repo:user3/repo3")
+	test("created:>=2000-01-01 sort:created-desc", 5, 3, 2, 1)
+	test("created:<2000-01-01")
+	test("field:Priority=High", 1)
+	test(`field:"Priority=Low"`)
+	test("field:Unknown=High")
+
+	reviewer := &IssueField{RepoID: 1, Name: "Reviewer", Type: IssueFieldTypeUser}
+	assert.NoError(t, NewIssueField(reviewer))
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 5}).(*Issue)
+	assert.NoError(t, SetIssueFieldValues(issue, reviewer, []string{"user2"}))
+	test("field:Reviewer=@me", 5)
+	test("field:Reviewer=user2 field:Priority=High")
+
+	// without repositories to search the qualifiers do not widen the search
+	apply := func(query string, doer *User) (*IssuesOptions, bool) {
+		opts := &IssuesOptions{}
+		ok, err := ParseIssueQuery(query).Apply(opts, doer)
+		assert.NoError(t, err)
+		return opts, ok
+	}
+	opts, ok := apply("repo:user2/repo1", nil)
+	assert.True(t, ok)
+	assert.Equal(t, []int64{1}, opts.RepoIDs)
+	_, ok = apply("repo:user2/repo2", nil)
+	assert.False(t, ok)
+	_, ok = apply("repo:user2/repo2", AssertExistsAndLoadBean(t, &User{ID: 4}).(*User))
+	assert.False(t, ok)
+	opts, ok = apply("repo:user2/repo2", doer)
+	assert.True(t, ok)
+	assert.Equal(t, []int64{2}, opts.RepoIDs)
+	_, ok = apply("milestone:milestone1", doer)
+	assert.False(t, ok)
+	opts, ok = apply("repo:user2/repo1 milestone:milestone1", doer)
+	assert.True(t, ok)
+	assert.Equal(t, []int64{1}, opts.MilestoneIDs)
+}
@@ -299,23 +299,23 @@ func TestIssue_loadTotalTimes(t *testing.T) {
 func TestIssue_SearchIssueIDsByKeyword(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 
-	total, ids, err := SearchIssueIDsByKeyword("issue2", 1, 10, 0)
+	total, ids, err := SearchIssueIDsByKeyword("issue2", []int64{1}, 10, 0)
 	assert.NoError(t, err)
 	assert.EqualValues(t, 1, total)
 	assert.EqualValues(t, []int64{2}, ids)
 
-	total, ids, err = SearchIssueIDsByKeyword("first", 1, 10, 0)
+	total, ids, err = SearchIssueIDsByKeyword("first", []int64{1}, 10, 0)
 	assert.NoError(t, err)
 	assert.EqualValues(t, 1, total)
 	assert.EqualValues(t, []int64{1}, ids)
 
-	total, ids, err = SearchIssueIDsByKeyword("for", 1, 10, 0)
+	total, ids, err = SearchIssueIDsByKeyword("for", []int64{1}, 10, 0)
 	assert.NoError(t, err)
 	assert.EqualValues(t, 4, total)
 	assert.EqualValues(t, []int64{1, 2, 3, 5}, ids)
 
 	// issue1's comment id 2
-	total, ids, err = SearchIssueIDsByKeyword("good", 1, 10, 0)
+	total, ids, err = SearchIssueIDsByKeyword("good", []int64{1}, 10, 0)
 	assert.NoError(t, err)
 	assert.EqualValues(t, 1, total)
 	assert.EqualValues(t, []int64{1}, ids)
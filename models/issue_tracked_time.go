@@ -18,7 +18,9 @@ import (
 type TrackedTime struct {
 	ID          int64     `xorm:"pk autoincr" json:"id"`
 	IssueID     int64     `xorm:"INDEX" json:"issue_id"`
+	Issue       *Issue    `xorm:"-" json:"-"`
 	UserID      int64     `xorm:"INDEX" json:"user_id"`
+	User        *User     `xorm:"-" json:"-"`
 	Created     time.Time `xorm:"-" json:"created"`
 	CreatedUnix int64     `xorm:"created" json:"-"`
 	Time        int64     `json:"time"`
@@ -45,7 +47,11 @@ type FindTrackedTimesOptions struct {
 	IssueID      int64
 	UserID       int64
 	RepositoryID int64
-	MilestoneID  int64
+	// RepositoryIDs restricts the times to the issues of these repositories if it is not nil
+	RepositoryIDs     []int64
+	MilestoneID       int64
+	CreatedAfterUnix  int64
+	CreatedBeforeUnix int64
 }
 
 // ToCond will convert each condition into a xorm-Cond
@@ -60,15 +66,24 @@ func (opts *FindTrackedTimesOptions) ToCond() builder.Cond {
 	if opts.RepositoryID != 0 {
 		cond = cond.And(builder.Eq{"issue.repo_id": opts.RepositoryID})
 	}
+	if opts.RepositoryIDs != nil {
+		cond = cond.And(builder.In("issue.repo_id", opts.RepositoryIDs))
+	}
 	if opts.MilestoneID != 0 {
 		cond = cond.And(builder.Eq{"issue.milestone_id": opts.MilestoneID})
 	}
+	if opts.CreatedAfterUnix != 0 {
+		cond = cond.And(builder.Gte{"tracked_time.created_unix": opts.CreatedAfterUnix})
+	}
+	if opts.CreatedBeforeUnix != 0 {
+		cond = cond.And(builder.Lt{"tracked_time.created_unix": opts.CreatedBeforeUnix})
+	}
 	return cond
 }
 
 // ToSession will convert the given options to a xorm Session by using the conditions from ToCond and joining with issue table if required
 func (opts *FindTrackedTimesOptions) ToSession(e Engine) *xorm.Session {
-	if opts.RepositoryID > 0 || opts.MilestoneID > 0 {
+	if opts.RepositoryID > 0 || opts.RepositoryIDs != nil || opts.MilestoneID > 0 {
 		return e.Join("INNER", "issue", "issue.id = tracked_time.issue_id").Where(opts.ToCond())
 	}
 	return x.Where(opts.ToCond())
@@ -80,6 +95,62 @@ func GetTrackedTimes(options FindTrackedTimesOptions) (trackedTimes []*TrackedTi
 	return
 }
 
+// GetTimetrackerRepoIDs returns the repositories of the organization with time tracking enabled
+// whose issues the user can read
+func GetTimetrackerRepoIDs(org, user *User) ([]int64, error) {
+	env, err := org.AccessibleReposEnv(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := env.RepoIDs(1, org.NumRepos)
+	if err != nil {
+		return nil, err
+	}
+	repos, err := GetRepositoriesMapByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	repoIDs := make([]int64, 0, len(repos))
+	for _, repo := range repos {
+		if !repo.IsTimetrackerEnabled() {
+			continue
+		}
+		perm, err := GetUserRepoPermission(repo, user)
+		if err != nil {
+			return nil, err
+		}
+		if perm.CanRead(UnitTypeIssues) {
+			repoIDs = append(repoIDs, repo.ID)
+		}
+	}
+	return repoIDs, nil
+}
+
+// GetTrackedTimeByID returns the tracked time by given ID.
+func GetTrackedTimeByID(id int64) (*TrackedTime, error) {
+	t := new(TrackedTime)
+	has, err := x.ID(id).Get(t)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrTrackedTimeNotExist{id}
+	}
+	return t, nil
+}
+
+// UpdateTrackedTime changes the amount of the tracked time (in seconds).
+func UpdateTrackedTime(t *TrackedTime) error {
+	_, err := x.ID(t.ID).Cols("time").Update(t)
+	return err
+}
+
+// DeleteTrackedTime deletes the tracked time.
+func DeleteTrackedTime(t *TrackedTime) error {
+	_, err := x.ID(t.ID).Delete(new(TrackedTime))
+	return err
+}
+
 // AddTime will add the given time (in seconds) to the issue
 func AddTime(user *User, issue *Issue, time int64) (*TrackedTime, error) {
 	tt := &TrackedTime{
@@ -0,0 +1,206 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"sort"
+
+	api "github.com/masoodkamyab/gitea/modules/structs"
+)
+
+// Groupings of a tracked time report
+const (
+	TrackedTimeGroupByUser      = "user"
+	TrackedTimeGroupByIssue     = "issue"
+	TrackedTimeGroupByMilestone = "milestone"
+	TrackedTimeGroupByLabel     = "label"
+)
+
+// IsValidTrackedTimeGroupBy reports whether tracked times can be grouped this way
+func IsValidTrackedTimeGroupBy(groupBy string) bool {
+	switch groupBy {
+	case TrackedTimeGroupByUser, TrackedTimeGroupByIssue, TrackedTimeGroupByMilestone, TrackedTimeGroupByLabel:
+		return true
+	}
+	return false
+}
+
+// TrackedTimeList defines a list of tracked times
+type TrackedTimeList []*TrackedTime
+
+func (tl TrackedTimeList) getIssueIDs() []int64 {
+	issueIDs := make(map[int64]struct{}, len(tl))
+	for _, t := range tl {
+		issueIDs[t.IssueID] = struct{}{}
+	}
+	return keysInt64(issueIDs)
+}
+
+func (tl TrackedTimeList) getUserIDs() []int64 {
+	userIDs := make(map[int64]struct{}, len(tl))
+	for _, t := range tl {
+		userIDs[t.UserID] = struct{}{}
+	}
+	return keysInt64(userIDs)
+}
+
+func (tl TrackedTimeList) loadAttributes(e Engine) error {
+	if len(tl) == 0 {
+		return nil
+	}
+
+	issueIDs := tl.getIssueIDs()
+	issueMaps := make(map[int64]*Issue, len(issueIDs))
+	for left := len(issueIDs); left > 0; {
+		var limit = defaultMaxInSize
+		if left < limit {
+			limit = left
+		}
+		if err := e.In("id", issueIDs[:limit]).Find(&issueMaps); err != nil {
+			return fmt.Errorf("find issue: %v", err)
+		}
+		left -= limit
+		issueIDs = issueIDs[limit:]
+	}
+	issues := make(IssueList, 0, len(issueMaps))
+	for _, issue := range issueMaps {
+		issues = append(issues, issue)
+	}
+	if _, err := issues.loadRepositories(e); err != nil {
+		return err
+	}
+	if err := issues.loadMilestones(e); err != nil {
+		return err
+	}
+	if err := issues.loadLabels(e); err != nil {
+		return err
+	}
+
+	userIDs := tl.getUserIDs()
+	userMaps := make(map[int64]*User, len(userIDs))
+	for left := len(userIDs); left > 0; {
+		var limit = defaultMaxInSize
+		if left < limit {
+			limit = left
+		}
+		if err := e.In("id", userIDs[:limit]).Find(&userMaps); err != nil {
+			return fmt.Errorf("find user: %v", err)
+		}
+		left -= limit
+		userIDs = userIDs[limit:]
+	}
+
+	for _, t := range tl {
+		t.Issue = issueMaps[t.IssueID]
+		if t.User = userMaps[t.UserID]; t.User == nil {
+			t.User = NewGhostUser()
+		}
+	}
+	return nil
+}
+
+// LoadAttributes loads the issues, with their repositories, milestones and labels, and the users of the tracked times
+func (tl TrackedTimeList) LoadAttributes() error {
+	return tl.loadAttributes(x)
+}
+
+// TrackedTimeGroup is the total time tracked for a user, an issue, a milestone or a label.
+// A group without an ID holds the times of issues without a milestone or a label.
+type TrackedTimeGroup struct {
+	ID   int64
+	Name string
+	Link string
+	Time int64
+}
+
+// TrackedTimeReport represents the tracked times matching some filters with their totals
+type TrackedTimeReport struct {
+	GroupBy string
+	Total   int64
+	Groups  []*TrackedTimeGroup
+	Times   TrackedTimeList
+}
+
+// GetTrackedTimeReport returns the tracked times matching the options, newest first, and their totals by the given grouping
+func GetTrackedTimeReport(opts FindTrackedTimesOptions, groupBy string) (*TrackedTimeReport, error) {
+	var times TrackedTimeList
+	if err := opts.ToSession(x).Desc("tracked_time.created_unix").Find(&times); err != nil {
+		return nil, err
+	}
+	if err := times.loadAttributes(x); err != nil {
+		return nil, err
+	}
+	report := &TrackedTimeReport{
+		GroupBy: groupBy,
+		Times:   make(TrackedTimeList, 0, len(times)),
+	}
+	// times of deleted issues cannot be attributed to anything
+	for _, t := range times {
+		if t.Issue != nil && t.Issue.Repo != nil {
+			report.Times = append(report.Times, t)
+		}
+	}
+
+	groups := make(map[int64]*TrackedTimeGroup)
+	add := func(id int64, name, link string, time int64) {
+		group, ok := groups[id]
+		if !ok {
+			group = &TrackedTimeGroup{ID: id, Name: name, Link: link}
+			groups[id] = group
+			report.Groups = append(report.Groups, group)
+		}
+		group.Time += time
+	}
+	for _, t := range report.Times {
+		report.Total += t.Time
+		switch groupBy {
+		case TrackedTimeGroupByUser:
+			add(t.User.ID, t.User.Name, t.User.HTMLURL(), t.Time)
+		case TrackedTimeGroupByIssue:
+			add(t.Issue.ID, fmt.Sprintf("%s#%d %s", t.Issue.Repo.FullName(), t.Issue.Index, t.Issue.Title), t.Issue.HTMLURL(), t.Time)
+		case TrackedTimeGroupByMilestone:
+			if t.Issue.Milestone == nil {
+				add(0, "", "", t.Time)
+				continue
+			}
+			add(t.Issue.Milestone.ID, t.Issue.Milestone.Name, fmt.Sprintf("%s/milestone/%d", t.Issue.Repo.HTMLURL(), t.Issue.Milestone.ID), t.Time)
+		case TrackedTimeGroupByLabel:
+			if len(t.Issue.Labels) == 0 {
+				add(0, "", "", t.Time)
+				continue
+			}
+			// a time counts for every label of its issue
+			for _, label := range t.Issue.Labels {
+				add(label.ID, label.Name, fmt.Sprintf("%s/issues?labels=%d", t.Issue.Repo.HTMLURL(), label.ID), t.Time)
+			}
+		}
+	}
+	sort.SliceStable(report.Groups, func(i, j int) bool {
+		return report.Groups[i].Time > report.Groups[j].Time
+	})
+	return report, nil
+}
+
+// APIFormat converts a TrackedTimeReport to the api.TrackedTimeReport format
+func (r *TrackedTimeReport) APIFormat() *api.TrackedTimeReport {
+	apiReport := &api.TrackedTimeReport{
+		GroupBy: r.GroupBy,
+		Total:   r.Total,
+		Groups:  make([]*api.TrackedTimeGroup, len(r.Groups)),
+		Times:   make([]*api.TrackedTime, len(r.Times)),
+	}
+	for i, group := range r.Groups {
+		apiReport.Groups[i] = &api.TrackedTimeGroup{
+			Name: group.Name,
+			URL:  group.Link,
+			Time: group.Time,
+		}
+	}
+	for i, t := range r.Times {
+		apiReport.Times[i] = t.APIFormat()
+	}
+	return apiReport
+}
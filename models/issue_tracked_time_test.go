@@ -101,3 +101,69 @@ func TestTotalTimes(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Len(t, total, 0)
 }
+
+func TestGetTrackedTimesByDate(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	times, err := GetTrackedTimes(FindTrackedTimesOptions{RepositoryID: 1, CreatedAfterUnix: 946684801, CreatedBeforeUnix: 946684802})
+	assert.NoError(t, err)
+	if assert.Len(t, times, 1) {
+		assert.EqualValues(t, 2, times[0].ID)
+	}
+
+	times, err = GetTrackedTimes(FindTrackedTimesOptions{RepositoryIDs: []int64{}})
+	assert.NoError(t, err)
+	assert.Len(t, times, 0)
+}
+
+func TestUpdateAndDeleteTrackedTime(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	tt, err := GetTrackedTimeByID(1)
+	assert.NoError(t, err)
+	tt.Time = 600
+	assert.NoError(t, UpdateTrackedTime(tt))
+	AssertExistsAndLoadBean(t, &TrackedTime{ID: 1, Time: 600, CreatedUnix: 946684800})
+
+	assert.NoError(t, DeleteTrackedTime(tt))
+	AssertNotExistsBean(t, &TrackedTime{ID: 1})
+	_, err = GetTrackedTimeByID(1)
+	assert.True(t, IsErrTrackedTimeNotExist(err))
+}
+
+func TestGetTrackedTimeReport(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	totals := func(report *TrackedTimeReport) map[int64]int64 {
+		m := make(map[int64]int64, len(report.Groups))
+		for _, group := range report.Groups {
+			m[group.ID] = group.Time
+		}
+		return m
+	}
+
+	report, err := GetTrackedTimeReport(FindTrackedTimesOptions{RepositoryID: 1}, TrackedTimeGroupByUser)
+	assert.NoError(t, err)
+	assert.Len(t, report.Times, 4)
+	assert.EqualValues(t, 4063, report.Total)
+	assert.EqualValues(t, map[int64]int64{1: 400, 2: 3663}, totals(report))
+	assert.EqualValues(t, 2, report.Groups[0].ID)
+
+	report, err = GetTrackedTimeReport(FindTrackedTimesOptions{RepositoryID: 1}, TrackedTimeGroupByIssue)
+	assert.NoError(t, err)
+	assert.EqualValues(t, map[int64]int64{1: 400, 2: 3662, 5: 1}, totals(report))
+	assert.EqualValues(t, "user2/repo1#2 issue2", report.Groups[0].Name)
+
+	report, err = GetTrackedTimeReport(FindTrackedTimesOptions{RepositoryID: 1}, TrackedTimeGroupByMilestone)
+	assert.NoError(t, err)
+	assert.EqualValues(t, map[int64]int64{0: 401, 1: 3662}, totals(report))
+
+	report, err = GetTrackedTimeReport(FindTrackedTimesOptions{RepositoryID: 1}, TrackedTimeGroupByLabel)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 4063, report.Total)
+	assert.EqualValues(t, map[int64]int64{1: 4062, 2: 1}, totals(report))
+
+	report, err = GetTrackedTimeReport(FindTrackedTimesOptions{UserID: 2, CreatedAfterUnix: 946684802}, TrackedTimeGroupByUser)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, report.Total)
+}
@@ -0,0 +1,299 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+	"xorm.io/builder"
+)
+
+// IssueRedirect points the former repository and index of a transferred issue to the issue
+type IssueRedirect struct {
+	ID        int64 `xorm:"pk autoincr"`
+	OldRepoID int64 `xorm:"UNIQUE(s) NOT NULL"`
+	OldIndex  int64 `xorm:"UNIQUE(s) NOT NULL"`
+	IssueID   int64 `xorm:"INDEX NOT NULL"`
+}
+
+// LookupIssueRedirect returns the issue which had the index in the repository before it was transferred
+func LookupIssueRedirect(repoID, index int64) (*Issue, error) {
+	redirect := &IssueRedirect{OldRepoID: repoID, OldIndex: index}
+	has, err := x.Get(redirect)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrIssueNotExist{0, repoID, index}
+	}
+	return GetIssueByID(redirect.IssueID)
+}
+
+// CanTransferIssueTo returns true if the user may transfer issues into the repository
+func CanTransferIssueTo(user *User, repo *Repository) (bool, error) {
+	return canTransferIssueTo(x, user, repo)
+}
+
+func canTransferIssueTo(e Engine, user *User, repo *Repository) (bool, error) {
+	if repo.IsArchived || repo.IsMirror {
+		return false, nil
+	}
+	perm, err := getUserRepoPermission(e, repo, user)
+	if err != nil {
+		return false, err
+	}
+	return perm.CanWrite(UnitTypeIssues), nil
+}
+
+// transferIssueLabels replaces the labels of the issue with the labels of the same names in the new repository
+func transferIssueLabels(e *xorm.Session, issue *Issue, newRepo *Repository) error {
+	if err := issue.loadLabels(e); err != nil {
+		return err
+	}
+	if _, err := e.Delete(&IssueLabel{IssueID: issue.ID}); err != nil {
+		return err
+	}
+
+	labels := make([]*Label, 0, len(issue.Labels))
+	for _, label := range issue.Labels {
+		label.NumIssues--
+		if issue.IsClosed {
+			label.NumClosedIssues--
+		}
+		if err := updateLabel(e, label); err != nil {
+			return err
+		}
+
+		newLabel, err := getLabelInRepoByName(e, newRepo.ID, label.Name)
+		if IsErrLabelNotExist(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		if _, err = e.Insert(&IssueLabel{IssueID: issue.ID, LabelID: newLabel.ID}); err != nil {
+			return err
+		}
+		newLabel.NumIssues++
+		if issue.IsClosed {
+			newLabel.NumClosedIssues++
+		}
+		if err = updateLabel(e, newLabel); err != nil {
+			return err
+		}
+		labels = append(labels, newLabel)
+	}
+	issue.Labels = labels
+	return nil
+}
+
+// transferIssueMilestone replaces the milestone of the issue with the milestone of the same name in the new repository
+func transferIssueMilestone(e *xorm.Session, issue *Issue, newRepo *Repository) error {
+	if issue.MilestoneID == 0 {
+		return nil
+	}
+
+	m, err := getMilestoneByRepoID(e, issue.RepoID, issue.MilestoneID)
+	if err != nil {
+		return err
+	}
+	m.NumIssues--
+	if issue.IsClosed {
+		m.NumClosedIssues--
+	}
+	if err = updateMilestone(e, m); err != nil {
+		return err
+	}
+
+	issue.MilestoneID = 0
+	issue.Milestone = nil
+	newMilestone := &Milestone{RepoID: newRepo.ID, Name: m.Name}
+	if has, err := e.Get(newMilestone); err != nil {
+		return err
+	} else if !has {
+		return nil
+	}
+	newMilestone.NumIssues++
+	if issue.IsClosed {
+		newMilestone.NumClosedIssues++
+	}
+	if err = updateMilestone(e, newMilestone); err != nil {
+		return err
+	}
+	issue.MilestoneID = newMilestone.ID
+	issue.Milestone = newMilestone
+	return nil
+}
+
+// transferIssueAssignees removes the assignees which cannot be assigned in the new repository
+func transferIssueAssignees(e *xorm.Session, issue *Issue, newRepo *Repository) error {
+	assignees := make([]*IssueAssignees, 0, 5)
+	if err := e.Where("issue_id = ?", issue.ID).Find(&assignees); err != nil {
+		return err
+	}
+	for _, assignee := range assignees {
+		user, err := getUserByID(e, assignee.AssigneeID)
+		if err != nil && !IsErrUserNotExist(err) {
+			return err
+		}
+		if err == nil {
+			if ok, err := canBeAssigned(e, user, newRepo); err != nil {
+				return err
+			} else if ok {
+				continue
+			}
+		}
+		if _, err = e.ID(assignee.ID).Delete(new(IssueAssignees)); err != nil {
+			return err
+		}
+	}
+	issue.Assignees = nil
+	return nil
+}
+
+// transferIssueWatchers removes the watches and notifications of the users who cannot read the issues of the
+// new repository, so that they are not notified about an issue they cannot see
+func transferIssueWatchers(e *xorm.Session, issue *Issue, newRepo *Repository) error {
+	watcherIDs := make([]int64, 0, 10)
+	if err := e.Table("issue_watch").Where("issue_id = ?", issue.ID).Cols("user_id").Find(&watcherIDs); err != nil {
+		return err
+	}
+	notifiedIDs := make([]int64, 0, 10)
+	if err := e.Table("notification").Where("issue_id = ?", issue.ID).Distinct("user_id").Find(&notifiedIDs); err != nil {
+		return err
+	}
+
+	checked := make(map[int64]bool, len(watcherIDs)+len(notifiedIDs))
+	for _, userID := range append(watcherIDs, notifiedIDs...) {
+		if checked[userID] {
+			continue
+		}
+		checked[userID] = true
+
+		user, err := getUserByID(e, userID)
+		if err != nil && !IsErrUserNotExist(err) {
+			return err
+		}
+		if err == nil {
+			if ok, err := hasAccessUnit(e, user, newRepo, UnitTypeIssues, AccessModeRead); err != nil {
+				return err
+			} else if ok {
+				continue
+			}
+		}
+		if _, err = e.Delete(&IssueWatch{UserID: userID, IssueID: issue.ID}); err != nil {
+			return err
+		}
+		if _, err = e.Delete(&Notification{UserID: userID, IssueID: issue.ID}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TransferIssue moves the issue to a new index of another repository. Comments, attachments, tracked times and
+// reactions stay with the issue, as do the subscriptions of users who can read the new repository. Labels and the
+// milestone are mapped by name and a redirect keeps the former URL of the issue working. Pull requests cannot be
+// transferred.
+func TransferIssue(doer *User, issue *Issue, newRepo *Repository) (err error) {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	if err = transferIssue(sess, doer, issue, newRepo); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+func transferIssue(sess *xorm.Session, doer *User, issue *Issue, newRepo *Repository) (err error) {
+	if issue.IsPull {
+		return ErrIssueCannotTransfer{ID: issue.ID, Reason: "pull requests cannot be transferred"}
+	} else if issue.RepoID == newRepo.ID {
+		return ErrIssueCannotTransfer{ID: issue.ID, Reason: "the issue already belongs to the repository"}
+	}
+
+	if err = issue.loadRepo(sess); err != nil {
+		return err
+	}
+	oldRepo := issue.Repo
+	oldIndex := issue.Index
+
+	if err = transferIssueLabels(sess, issue, newRepo); err != nil {
+		return fmt.Errorf("transferIssueLabels: %v", err)
+	}
+	if err = transferIssueMilestone(sess, issue, newRepo); err != nil {
+		return fmt.Errorf("transferIssueMilestone: %v", err)
+	}
+	if err = transferIssueAssignees(sess, issue, newRepo); err != nil {
+		return fmt.Errorf("transferIssueAssignees: %v", err)
+	}
+	if err = transferIssueWatchers(sess, issue, newRepo); err != nil {
+		return fmt.Errorf("transferIssueWatchers: %v", err)
+	}
+
+	// Dependencies only exist between issues of the same repository
+	if _, err = sess.Where("issue_id = ? OR dependency_id = ?", issue.ID, issue.ID).Delete(new(IssueDependency)); err != nil {
+		return err
+	}
+
+	// Only the projects of the organization owning the new repository can still hold the issue
+	if _, err = sess.Where(builder.Eq{"issue_id": issue.ID}).
+		And(builder.NotIn("project_id", builder.Select("id").From("project").
+			Where(builder.Eq{"type": ProjectTypeOrganization, "owner_id": newRepo.OwnerID}))).
+		Delete(new(ProjectIssue)); err != nil {
+		return err
+	}
+	// Likewise only the custom fields of that organization keep their values
+	if _, err = sess.Where(builder.Eq{"issue_id": issue.ID}).
+		And(builder.NotIn("field_id", builder.Select("id").From("issue_field").
+			Where(builder.Eq{"owner_id": newRepo.OwnerID}))).
+		Delete(new(IssueFieldValue)); err != nil {
+		return err
+	}
+
+	maxIndex, err := getMaxIndexOfIssue(sess, newRepo.ID)
+	if err != nil {
+		return err
+	}
+	issue.RepoID = newRepo.ID
+	issue.Repo = newRepo
+	issue.Index = maxIndex + 1
+	issue.Ref = ""
+	if err = updateIssueCols(sess, issue, "repo_id", "index", "milestone_id", "ref"); err != nil {
+		return err
+	}
+	if _, err = sess.Exec("UPDATE `notification` SET repo_id = ? WHERE issue_id = ?", newRepo.ID, issue.ID); err != nil {
+		return err
+	}
+
+	if _, err = sess.Insert(&IssueRedirect{OldRepoID: oldRepo.ID, OldIndex: oldIndex, IssueID: issue.ID}); err != nil {
+		return err
+	}
+
+	if _, err = sess.Exec("UPDATE `repository` SET num_issues = num_issues - 1 WHERE id = ?", oldRepo.ID); err != nil {
+		return err
+	}
+	if _, err = sess.Exec("UPDATE `repository` SET num_issues = num_issues + 1 WHERE id = ?", newRepo.ID); err != nil {
+		return err
+	}
+	for _, repoID := range []int64{oldRepo.ID, newRepo.ID} {
+		if err = (&Issue{RepoID: repoID}).updateClosedNum(sess); err != nil {
+			return err
+		}
+	}
+
+	if _, err = createComment(sess, &CreateCommentOptions{
+		Type:    CommentTypeIssueTransfer,
+		Doer:    doer,
+		Repo:    newRepo,
+		Issue:   issue,
+		Content: fmt.Sprintf("%s#%d", oldRepo.FullName(), oldIndex),
+	}); err != nil {
+		return fmt.Errorf("createComment: %v", err)
+	}
+	return nil
+}
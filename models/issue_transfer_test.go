@@ -0,0 +1,137 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransferIssue(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	newRepo := AssertExistsAndLoadBean(t, &Repository{ID: 3}).(*Repository)
+	label := &Label{RepoID: newRepo.ID, Name: "label1", Color: "#abcdef"}
+	assert.NoError(t, NewLabel(label))
+	milestone := &Milestone{RepoID: newRepo.ID, Name: "milestone1"}
+	assert.NoError(t, NewMilestone(milestone))
+
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	issue.MilestoneID = 1
+	assert.NoError(t, updateIssueCols(x, issue, "milestone_id"))
+	oldLabel := AssertExistsAndLoadBean(t, &Label{ID: 1}).(*Label)
+	oldMilestone := AssertExistsAndLoadBean(t, &Milestone{ID: 1}).(*Milestone)
+	assert.NoError(t, CreateOrUpdateIssueWatch(doer.ID, issue.ID, true))
+	_, err := x.Insert(&Notification{UserID: 9, RepoID: 1, IssueID: 1, Status: NotificationStatusUnread,
+		Source: NotificationSourceIssue, UpdatedBy: doer.ID})
+	assert.NoError(t, err)
+
+	assert.NoError(t, TransferIssue(doer, issue, newRepo))
+	assert.EqualValues(t, 2, issue.Index)
+
+	AssertExistsAndLoadBean(t, &Issue{ID: 1, RepoID: newRepo.ID, Index: 2, MilestoneID: milestone.ID})
+	AssertExistsAndLoadBean(t, &IssueLabel{IssueID: 1, LabelID: label.ID})
+	AssertNotExistsBean(t, &IssueLabel{IssueID: 1, LabelID: 1})
+	AssertExistsAndLoadBean(t, &Label{ID: oldLabel.ID, NumIssues: oldLabel.NumIssues - 1})
+	AssertExistsAndLoadBean(t, &Label{ID: label.ID, NumIssues: 1})
+	AssertExistsAndLoadBean(t, &Milestone{ID: milestone.ID, NumIssues: 1})
+	AssertExistsAndLoadBean(t, &Milestone{ID: oldMilestone.ID, NumIssues: oldMilestone.NumIssues - 1})
+	// the project of the old repository no longer holds the issue
+	AssertNotExistsBean(t, &ProjectIssue{IssueID: 1})
+	// nor has it the value of a field of the old repository
+	AssertNotExistsBean(t, &IssueFieldValue{IssueID: 1})
+	// comments, attachments and tracked times stay with the issue
+	AssertExistsAndLoadBean(t, &Comment{ID: 2, IssueID: 1})
+	AssertExistsAndLoadBean(t, &Attachment{ID: 1, IssueID: 1})
+	AssertExistsAndLoadBean(t, &TrackedTime{ID: 1, IssueID: 1})
+	// as do subscriptions of users who can read the new repository
+	AssertExistsAndLoadBean(t, &IssueWatch{UserID: doer.ID, IssueID: 1})
+	AssertNotExistsBean(t, &IssueWatch{ID: 1})
+	AssertExistsAndLoadBean(t, &Notification{ID: 1, RepoID: newRepo.ID})
+	AssertNotExistsBean(t, &Notification{UserID: 9, IssueID: 1})
+	AssertExistsAndLoadBean(t, &Comment{IssueID: 1, Type: CommentTypeIssueTransfer, Content: "user2/repo1#1"})
+
+	CheckConsistencyFor(t, &Repository{ID: 1}, &Repository{ID: newRepo.ID})
+
+	redirected, err := LookupIssueRedirect(1, 1)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, redirected.ID)
+	_, err = LookupIssueRedirect(1, 2)
+	assert.True(t, IsErrIssueNotExist(err))
+
+	// transferring the issue back does not reuse its former index
+	oldRepo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	assert.NoError(t, TransferIssue(doer, issue, oldRepo))
+	assert.NotEqual(t, int64(1), issue.Index)
+	AssertExistsAndLoadBean(t, &IssueRedirect{OldRepoID: newRepo.ID, OldIndex: 2, IssueID: 1})
+}
+
+func TestTransferIssue_ReservesIndex(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	newRepo := AssertExistsAndLoadBean(t, &Repository{ID: 3}).(*Repository)
+
+	maxIndex, err := GetMaxIndexOfIssue(repo.ID)
+	assert.NoError(t, err)
+	issue := AssertExistsAndLoadBean(t, &Issue{RepoID: repo.ID, Index: maxIndex}).(*Issue)
+	assert.NoError(t, TransferIssue(doer, issue, newRepo))
+
+	// the index of the transferred issue is not handed out again
+	reserved, err := GetMaxIndexOfIssue(repo.ID)
+	assert.NoError(t, err)
+	assert.EqualValues(t, maxIndex, reserved)
+
+	newIssue := &Issue{
+		RepoID:   repo.ID,
+		Repo:     repo,
+		Title:    "after transfer",
+		PosterID: doer.ID,
+		Poster:   doer,
+	}
+	assert.NoError(t, NewIssue(repo, newIssue, nil, nil, nil))
+	assert.EqualValues(t, maxIndex+1, newIssue.Index)
+
+	redirected, err := LookupIssueRedirect(repo.ID, maxIndex)
+	assert.NoError(t, err)
+	assert.EqualValues(t, issue.ID, redirected.ID)
+}
+
+func TestTransferIssue_Invalid(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+
+	pull := AssertExistsAndLoadBean(t, &Issue{ID: 2}).(*Issue)
+	assert.True(t, IsErrIssueCannotTransfer(TransferIssue(doer, pull, AssertExistsAndLoadBean(t, &Repository{ID: 3}).(*Repository))))
+
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	assert.True(t, IsErrIssueCannotTransfer(TransferIssue(doer, issue, repo)))
+}
+
+func TestCanTransferIssueTo(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	user2 := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	user5 := AssertExistsAndLoadBean(t, &User{ID: 5}).(*User)
+	repo3 := AssertExistsAndLoadBean(t, &Repository{ID: 3}).(*Repository)
+
+	ok, err := CanTransferIssueTo(user2, repo3)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = CanTransferIssueTo(user5, repo3)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	repo3.IsArchived = true
+	ok, err = CanTransferIssueTo(user2, repo3)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
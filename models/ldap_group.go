@@ -0,0 +1,142 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/masoodkamyab/gitea/modules/auth/ldap"
+	"github.com/masoodkamyab/gitea/modules/setting"
+)
+
+// groupTeamMap returns the group to team mapping of an LDAP source with lower case group DNs,
+// as DNs are compared case insensitively
+func (cfg *LDAPConfig) groupTeamMap() (GroupTeamMap, error) {
+	groupTeamMap, err := ParseGroupTeamMap(cfg.GroupTeamMap)
+	if err != nil {
+		return nil, err
+	}
+	lowerGroupTeamMap := make(GroupTeamMap, len(groupTeamMap))
+	for group, teams := range groupTeamMap {
+		lowerGroup := strings.ToLower(group)
+		if lowerGroupTeamMap[lowerGroup] == nil {
+			lowerGroupTeamMap[lowerGroup] = make(map[string][]string, len(teams))
+		}
+		for org, names := range teams {
+			lowerGroupTeamMap[lowerGroup][org] = append(lowerGroupTeamMap[lowerGroup][org], names...)
+		}
+	}
+	return lowerGroupTeamMap, nil
+}
+
+// lowerLDAPGroups returns the group DNs in lower case
+func lowerLDAPGroups(groups []string) []string {
+	lowerGroups := make([]string, len(groups))
+	for i, group := range groups {
+		lowerGroups[i] = strings.ToLower(group)
+	}
+	return lowerGroups
+}
+
+// hasGroupTeamMap returns if the team memberships of the users of an LDAP source are synchronized
+func (cfg *LDAPConfig) hasGroupTeamMap() bool {
+	return cfg.HasGroups() && len(cfg.GroupTeamMap) > 0
+}
+
+// ldapGroupTeamChanges returns the team memberships of a user to add and remove for its LDAP groups
+func ldapGroupTeamChanges(source *LoginSource, user *User, groups []string) ([]*TeamMembershipChange, error) {
+	cfg := source.LDAP()
+	groupTeamMap, err := cfg.groupTeamMap()
+	if err != nil {
+		return nil, err
+	}
+	return GroupTeamChanges(user, lowerLDAPGroups(groups), groupTeamMap, cfg.GroupTeamMapRemoval)
+}
+
+// syncLDAPGroupsToTeams synchronizes the team memberships of a user with its LDAP groups
+func syncLDAPGroupsToTeams(source *LoginSource, user *User, groups []string) error {
+	cfg := source.LDAP()
+	if !cfg.hasGroupTeamMap() {
+		return nil
+	}
+	groupTeamMap, err := cfg.groupTeamMap()
+	if err != nil {
+		return err
+	}
+	return SyncGroupsToTeams(user, lowerLDAPGroups(groups), groupTeamMap, cfg.GroupTeamMapRemoval)
+}
+
+// PreviewLDAPGroupSync returns the team memberships the next synchronization of an LDAP source
+// would add and remove. Users the synchronization would create are included with an ID of 0.
+func PreviewLDAPGroupSync(source *LoginSource) ([]*TeamMembershipChange, error) {
+	cfg := source.LDAP()
+	if !cfg.hasGroupTeamMap() {
+		return nil, nil
+	}
+
+	var users []*User
+	if err := x.Where("login_type = ?", source.Type).
+		And("login_source = ?", source.ID).
+		Find(&users); err != nil {
+		return nil, err
+	}
+
+	entries, err := cfg.SearchEntries()
+	if err != nil {
+		return nil, fmt.Errorf("cannot search LDAP server %s: %v", cfg.Host, err)
+	}
+	return ldapGroupSyncChanges(source, users, entries, setting.Cron.SyncExternalUsers.UpdateExisting)
+}
+
+// ldapGroupSyncChanges returns the team memberships a synchronization of the users of an LDAP source
+// with its entries would add and remove. With deactivateMissing the users missing from the entries
+// are deactivated and leave their mapped teams, like they are in no group.
+func ldapGroupSyncChanges(source *LoginSource, users []*User, entries []*ldap.SearchResult, deactivateMissing bool) ([]*TeamMembershipChange, error) {
+	changes := make([]*TeamMembershipChange, 0, 10)
+	found := make(map[int64]bool, len(users))
+	for _, entry := range entries {
+		if len(entry.Username) == 0 {
+			continue
+		}
+
+		var user *User
+		for _, u := range users {
+			if u.LowerName == strings.ToLower(entry.Username) {
+				user = u
+				break
+			}
+		}
+		if user == nil {
+			user = &User{Name: entry.Username, LowerName: strings.ToLower(entry.Username)}
+		} else {
+			found[user.ID] = true
+		}
+
+		userChanges, err := ldapGroupTeamChanges(source, user, entry.Groups)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, userChanges...)
+	}
+
+	if !deactivateMissing {
+		return changes, nil
+	}
+	for _, user := range users {
+		if found[user.ID] {
+			continue
+		}
+		userChanges, err := ldapGroupTeamChanges(source, user, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, change := range userChanges {
+			change.UserMissing = true
+		}
+		changes = append(changes, userChanges...)
+	}
+	return changes, nil
+}
@@ -0,0 +1,120 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/masoodkamyab/gitea/modules/auth/ldap"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncLDAPGroupsToTeams(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	source := &LoginSource{
+		Type: LoginLDAP,
+		Name: "ldap",
+		Cfg: &LDAPConfig{
+			Source: &ldap.Source{
+				AttributeMemberOf: "memberOf",
+			},
+			GroupTeamMap:        `{"CN=Writers,OU=Groups,DC=example,DC=com": {"user3": ["team1"]}}`,
+			GroupTeamMapRemoval: true,
+		},
+	}
+	user := AssertExistsAndLoadBean(t, &User{ID: 5}).(*User)
+
+	// group DNs are compared case insensitively
+	assert.NoError(t, syncLDAPGroupsToTeams(source, user, []string{"cn=writers,ou=groups,dc=example,dc=com"}))
+	AssertExistsAndLoadBean(t, &TeamUser{TeamID: 2, UID: user.ID})
+
+	assert.NoError(t, syncLDAPGroupsToTeams(source, user, []string{"cn=readers,ou=groups,dc=example,dc=com"}))
+	AssertNotExistsBean(t, &TeamUser{TeamID: 2, UID: user.ID})
+
+	// without group lookup the team memberships are kept
+	assert.NoError(t, syncLDAPGroupsToTeams(source, user, []string{"cn=writers,ou=groups,dc=example,dc=com"}))
+	source.LDAP().AttributeMemberOf = ""
+	assert.NoError(t, syncLDAPGroupsToTeams(source, user, nil))
+	AssertExistsAndLoadBean(t, &TeamUser{TeamID: 2, UID: user.ID})
+	CheckConsistencyFor(t, &Team{})
+}
+
+func TestLDAPGroupSyncChanges(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	source := &LoginSource{
+		Type: LoginLDAP,
+		Name: "ldap",
+		Cfg: &LDAPConfig{
+			Source: &ldap.Source{
+				AttributeMemberOf: "memberOf",
+			},
+			GroupTeamMap:        `{"cn=writers,ou=groups,dc=example,dc=com": {"user3": ["team1"]}}`,
+			GroupTeamMapRemoval: true,
+		},
+	}
+	user := AssertExistsAndLoadBean(t, &User{ID: 5}).(*User)
+	team := AssertExistsAndLoadBean(t, &Team{ID: 2}).(*Team)
+	assert.NoError(t, AddTeamMember(team, user.ID))
+
+	entries := []*ldap.SearchResult{
+		{Username: "user5", Groups: []string{"cn=writers,ou=groups,dc=example,dc=com"}},
+		{Username: "newbie", Groups: []string{"cn=writers,ou=groups,dc=example,dc=com"}},
+	}
+	changes, err := ldapGroupSyncChanges(source, []*User{user}, entries, true)
+	assert.NoError(t, err)
+	if assert.Len(t, changes, 1) {
+		assert.EqualValues(t, "newbie", changes[0].User.Name)
+		assert.EqualValues(t, 0, changes[0].User.ID)
+		assert.False(t, changes[0].Remove)
+	}
+
+	// a user missing from LDAP gets deactivated and leaves the mapped teams
+	changes, err = ldapGroupSyncChanges(source, []*User{user}, entries[1:], true)
+	assert.NoError(t, err)
+	if assert.Len(t, changes, 2) {
+		assert.EqualValues(t, user.ID, changes[1].User.ID)
+		assert.EqualValues(t, team.ID, changes[1].Team.ID)
+		assert.True(t, changes[1].Remove)
+		assert.True(t, changes[1].UserMissing)
+	}
+
+	// unless existing users are not updated
+	changes, err = ldapGroupSyncChanges(source, []*User{user}, entries[1:], false)
+	assert.NoError(t, err)
+	assert.Len(t, changes, 1)
+
+	// or removal is disabled
+	source.LDAP().GroupTeamMapRemoval = false
+	changes, err = ldapGroupSyncChanges(source, []*User{user}, entries[1:], true)
+	assert.NoError(t, err)
+	assert.Len(t, changes, 1)
+}
+
+func TestPreviewLDAPGroupSync_Unreachable(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	source := &LoginSource{
+		Type: LoginLDAP,
+		Name: "ldap",
+		Cfg: &LDAPConfig{
+			Source: &ldap.Source{
+				Host:              "127.0.0.1",
+				Port:              1,
+				Filter:            "(uid=%s)",
+				AttributeMemberOf: "memberOf",
+				Enabled:           true,
+			},
+			GroupTeamMap: `{"cn=writers,ou=groups,dc=example,dc=com": {"user3": ["team1"]}}`,
+		},
+	}
+
+	// a failed search is reported instead of previewing no changes
+	changes, err := PreviewLDAPGroupSync(source)
+	assert.Error(t, err)
+	assert.Nil(t, changes)
+}
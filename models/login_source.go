@@ -21,6 +21,7 @@ import (
 	"github.com/masoodkamyab/gitea/modules/auth/ldap"
 	"github.com/masoodkamyab/gitea/modules/auth/oauth2"
 	"github.com/masoodkamyab/gitea/modules/auth/pam"
+	"github.com/masoodkamyab/gitea/modules/auth/saml"
 	"github.com/masoodkamyab/gitea/modules/log"
 	"github.com/masoodkamyab/gitea/modules/util"
 )
@@ -37,6 +38,7 @@ const (
 	LoginPAM              // 4
 	LoginDLDAP            // 5
 	LoginOAuth2           // 6
+	LoginSAML             // 7
 )
 
 // LoginNames contains the name of LoginType values.
@@ -46,6 +48,7 @@ var LoginNames = map[LoginType]string{
 	LoginSMTP:   "SMTP",
 	LoginPAM:    "PAM",
 	LoginOAuth2: "OAuth2",
+	LoginSAML:   "SAML",
 }
 
 // SecurityProtocolNames contains the name of SecurityProtocol values.
@@ -61,11 +64,16 @@ var (
 	_ core.Conversion = &SMTPConfig{}
 	_ core.Conversion = &PAMConfig{}
 	_ core.Conversion = &OAuth2Config{}
+	_ core.Conversion = &SAMLConfig{}
 )
 
 // LDAPConfig holds configuration for LDAP login source.
 type LDAPConfig struct {
 	*ldap.Source
+	// GroupTeamMap maps the group DNs to teams as JSON, like {"cn=group,dc=example,dc=com": {"org": ["team"]}}
+	GroupTeamMap string
+	// GroupTeamMapRemoval removes the users from the mapped teams of the groups they left
+	GroupTeamMapRemoval bool
 }
 
 // FromDB fills up a LDAPConfig from serialized format.
@@ -126,6 +134,18 @@ type OAuth2Config struct {
 	ClientSecret                  string
 	OpenIDConnectAutoDiscoveryURL string
 	CustomURLMapping              *oauth2.CustomURLMapping
+
+	// OpenID Connect only
+	Scopes       []string
+	ClaimMapping *oauth2.ClaimMapping
+	// GroupClaimName is the claim listing the groups of the user, they are synchronized at each login
+	GroupClaimName string
+	// AdminGroup makes its members site administrators, the others lose the administrator status
+	AdminGroup string
+	// GroupTeamMap maps the groups to teams as JSON, like {"group": {"org": ["team"]}}
+	GroupTeamMap string
+	// GroupTeamMapRemoval removes the users from the mapped teams of the groups they left
+	GroupTeamMapRemoval bool
 }
 
 // FromDB fills up an OAuth2Config from serialized format.
@@ -138,6 +158,39 @@ func (cfg *OAuth2Config) ToDB() ([]byte, error) {
 	return json.Marshal(cfg)
 }
 
+// SAMLConfig holds configuration for the SAML login source.
+type SAMLConfig struct {
+	// IdentityProviderMetadataURL is only used if IdentityProviderMetadata is empty
+	IdentityProviderMetadataURL string
+	IdentityProviderMetadata    string
+
+	// The attributes of the assertion, the username defaults to the NameID
+	UsernameAttribute string
+	EmailAttribute    string
+	FullNameAttribute string
+	// GroupAttribute lists the groups of the user, they are synchronized at each login
+	GroupAttribute string
+	// AdminGroup makes its members site administrators, the others lose the administrator status
+	AdminGroup string
+	// GroupTeamMap maps the groups to teams as JSON, like {"group": {"org": ["team"]}}
+	GroupTeamMap string
+	// GroupTeamMapRemoval removes the users from the mapped teams of the groups they left
+	GroupTeamMapRemoval bool
+
+	// EnableAutoRegister creates unknown users instead of asking them to link an existing account
+	EnableAutoRegister bool
+}
+
+// FromDB fills up a SAMLConfig from serialized format.
+func (cfg *SAMLConfig) FromDB(bs []byte) error {
+	return json.Unmarshal(bs, cfg)
+}
+
+// ToDB exports a SAMLConfig to a serialized format.
+func (cfg *SAMLConfig) ToDB() ([]byte, error) {
+	return json.Marshal(cfg)
+}
+
 // LoginSource represents an external way for authorizing users.
 type LoginSource struct {
 	ID            int64 `xorm:"pk autoincr"`
@@ -174,6 +227,8 @@ func (source *LoginSource) BeforeSet(colName string, val xorm.Cell) {
 			source.Cfg = new(PAMConfig)
 		case LoginOAuth2:
 			source.Cfg = new(OAuth2Config)
+		case LoginSAML:
+			source.Cfg = new(SAMLConfig)
 		default:
 			panic("unrecognized login source type: " + com.ToStr(*val))
 		}
@@ -210,6 +265,11 @@ func (source *LoginSource) IsOAuth2() bool {
 	return source.Type == LoginOAuth2
 }
 
+// IsSAML returns true of this source is of the SAML type.
+func (source *LoginSource) IsSAML() bool {
+	return source.Type == LoginSAML
+}
+
 // HasTLS returns true of this source supports TLS.
 func (source *LoginSource) HasTLS() bool {
 	return ((source.IsLDAP() || source.IsDLDAP()) &&
@@ -262,6 +322,11 @@ func (source *LoginSource) OAuth2() *OAuth2Config {
 	return source.Cfg.(*OAuth2Config)
 }
 
+// SAML returns SAMLConfig for this source, if of SAML type.
+func (source *LoginSource) SAML() *SAMLConfig {
+	return source.Cfg.(*SAMLConfig)
+}
+
 // CreateLoginSource inserts a LoginSource in the DB if not already
 // existing with the given name.
 func CreateLoginSource(source *LoginSource) error {
@@ -279,7 +344,7 @@ func CreateLoginSource(source *LoginSource) error {
 	_, err = x.Insert(source)
 	if err == nil && source.IsOAuth2() && source.IsActived {
 		oAuth2Config := source.OAuth2()
-		err = oauth2.RegisterProvider(source.Name, oAuth2Config.Provider, oAuth2Config.ClientID, oAuth2Config.ClientSecret, oAuth2Config.OpenIDConnectAutoDiscoveryURL, oAuth2Config.CustomURLMapping)
+		err = oauth2.RegisterProvider(source.Name, oAuth2Config.Provider, oAuth2Config.ClientID, oAuth2Config.ClientSecret, oAuth2Config.OpenIDConnectAutoDiscoveryURL, oAuth2Config.CustomURLMapping, oAuth2Config.Scopes, oAuth2Config.ClaimMapping)
 		err = wrapOpenIDConnectInitializeError(err, source.Name, oAuth2Config)
 		if err != nil {
 			// remove the LoginSource in case of errors while registering OAuth2 providers
@@ -289,6 +354,16 @@ func CreateLoginSource(source *LoginSource) error {
 			return err
 		}
 	}
+	if err == nil && source.IsSAML() && source.IsActived {
+		samlConfig := source.SAML()
+		if err = saml.RegisterProvider(source.Name, samlConfig.IdentityProviderMetadataURL, samlConfig.IdentityProviderMetadata); err != nil {
+			// remove the LoginSource in case of errors while registering SAML providers
+			if _, err := x.Delete(source); err != nil {
+				log.Error("CreateLoginSource: Error while deleting SAML login source: %v", err)
+			}
+			return ErrSAMLInitialize{ProviderName: source.Name, MetadataURL: samlConfig.IdentityProviderMetadataURL, Cause: err}
+		}
+	}
 	return err
 }
 
@@ -313,8 +388,8 @@ func GetLoginSourceByID(id int64) (*LoginSource, error) {
 // UpdateSource updates a LoginSource record in DB.
 func UpdateSource(source *LoginSource) error {
 	var originalLoginSource *LoginSource
-	if source.IsOAuth2() {
-		// keep track of the original values so we can restore in case of errors while registering OAuth2 providers
+	if source.IsOAuth2() || source.IsSAML() {
+		// keep track of the original values so we can restore in case of errors while registering OAuth2 or SAML providers
 		var err error
 		if originalLoginSource, err = GetLoginSourceByID(source.ID); err != nil {
 			return err
@@ -324,7 +399,7 @@ func UpdateSource(source *LoginSource) error {
 	_, err := x.ID(source.ID).AllCols().Update(source)
 	if err == nil && source.IsOAuth2() && source.IsActived {
 		oAuth2Config := source.OAuth2()
-		err = oauth2.RegisterProvider(source.Name, oAuth2Config.Provider, oAuth2Config.ClientID, oAuth2Config.ClientSecret, oAuth2Config.OpenIDConnectAutoDiscoveryURL, oAuth2Config.CustomURLMapping)
+		err = oauth2.RegisterProvider(source.Name, oAuth2Config.Provider, oAuth2Config.ClientID, oAuth2Config.ClientSecret, oAuth2Config.OpenIDConnectAutoDiscoveryURL, oAuth2Config.CustomURLMapping, oAuth2Config.Scopes, oAuth2Config.ClaimMapping)
 		err = wrapOpenIDConnectInitializeError(err, source.Name, oAuth2Config)
 		if err != nil {
 			// restore original values since we cannot update the provider it self
@@ -334,6 +409,20 @@ func UpdateSource(source *LoginSource) error {
 			return err
 		}
 	}
+	if err == nil && source.IsSAML() {
+		if !source.IsActived {
+			saml.RemoveProvider(source.Name)
+			return nil
+		}
+		samlConfig := source.SAML()
+		if err = saml.RegisterProvider(source.Name, samlConfig.IdentityProviderMetadataURL, samlConfig.IdentityProviderMetadata); err != nil {
+			// restore original values since the provider keeps its previous metadata
+			if _, err := x.ID(source.ID).AllCols().Update(originalLoginSource); err != nil {
+				log.Error("UpdateSource: Error while restoring SAML login source: %v", err)
+			}
+			return ErrSAMLInitialize{ProviderName: source.Name, MetadataURL: samlConfig.IdentityProviderMetadataURL, Cause: err}
+		}
+	}
 	return err
 }
 
@@ -356,6 +445,9 @@ func DeleteSource(source *LoginSource) error {
 	if source.IsOAuth2() {
 		oauth2.RemoveProvider(source.Name)
 	}
+	if source.IsSAML() {
+		saml.RemoveProvider(source.Name)
+	}
 
 	_, err = x.ID(source.ID).Delete(new(LoginSource))
 	return err
@@ -403,6 +495,9 @@ func LoginViaLDAP(user *User, login, password string, source *LoginSource, autoR
 	var isAttributeSSHPublicKeySet = len(strings.TrimSpace(source.LDAP().AttributeSSHPublicKey)) > 0
 
 	if !autoRegister {
+		if err := syncLDAPGroupsToTeams(source, user, sr.Groups); err != nil {
+			return nil, err
+		}
 		if isAttributeSSHPublicKeySet && synchronizeLdapSSHPublicKeys(user, source, sr.SSHPublicKey) {
 			return user, RewriteAllPublicKeys()
 		}
@@ -437,6 +532,9 @@ func LoginViaLDAP(user *User, login, password string, source *LoginSource, autoR
 
 	err := CreateUser(user)
 
+	if err == nil {
+		err = syncLDAPGroupsToTeams(source, user, sr.Groups)
+	}
 	if err == nil && isAttributeSSHPublicKeySet && addLdapSSHPublicKeys(user, source, sr.SSHPublicKey) {
 		err = RewriteAllPublicKeys()
 	}
@@ -663,7 +761,7 @@ func UserSignIn(username, password string) (*User, error) {
 
 	if hasUser {
 		switch user.LoginType {
-		case LoginNoType, LoginPlain, LoginOAuth2:
+		case LoginNoType, LoginPlain, LoginOAuth2, LoginSAML:
 			if user.IsPasswordSet() && user.ValidatePassword(password) {
 				// WARN: DON'T check user.IsActive, that will be checked on reqSign so that
 				// user could be hint to resend confirm email.
@@ -695,8 +793,8 @@ func UserSignIn(username, password string) (*User, error) {
 	}
 
 	for _, source := range sources {
-		if source.IsOAuth2() {
-			// don't try to authenticate against OAuth2 sources
+		if source.IsOAuth2() || source.IsSAML() {
+			// don't try to authenticate against OAuth2 and SAML sources
 			continue
 		}
 		authUser, err := ExternalUserLogin(nil, username, password, source, true)
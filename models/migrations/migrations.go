@@ -232,6 +232,40 @@ var migrations = []Migration{
 	NewMigration("add avatar field to repository", addAvatarFieldToRepository),
 	// v88 -> v89
 	NewMigration("add commit status context field to commit_status", addCommitStatusContext),
+	// v89 -> v90
+	NewMigration("add merge queue", addMergeQueue),
+	// v90 -> v91
+	NewMigration("add is_draft to pull_request", addIsDraftToPullRequest),
+	// v91 -> v92
+	NewMigration("add projects", addProjects),
+	// v92 -> v93
+	NewMigration("add saved filters", addSavedFilters),
+	// v93 -> v94
+	NewMigration("add issue redirects", addIssueRedirects),
+	// v94 -> v95
+	NewMigration("add custom issue fields", addIssueFields),
+	// v95 -> v96
+	NewMigration("add milestone snapshots", addMilestoneSnapshots),
+	// v96 -> v97
+	NewMigration("add issue estimates", addIssueEstimates),
+	// v97 -> v98
+	NewMigration("add access token scopes", addAccessTokenScopes),
+	// v98 -> v99
+	NewMigration("add OpenID Connect support", addOpenIDConnect),
+	// v99 -> v100
+	NewMigration("migrate U2F registrations to WebAuthn credentials", migrateU2FToWebAuthn),
+	// v100 -> v101
+	NewMigration("add two-factor authentication policies and scratch codes", addTwoFactorPolicies),
+	// v101 -> v102
+	NewMigration("add user sessions", addUserSessions),
+	// v102 -> v103
+	NewMigration("add audit events", addAuditEvents),
+	// v103 -> v104
+	NewMigration("add join time to organization members", addOrgUserCreated),
+	// v104 -> v105
+	NewMigration("add scopes to OAuth2 authorization codes", addOAuth2AuthorizationCodeScopes),
+	// v105 -> v106
+	NewMigration("add remember tokens to user sessions", addUserSessionRememberTokens),
 }
 
 // Migrate database to current version
@@ -0,0 +1,60 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/masoodkamyab/gitea/modules/util"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addTwoFactorPolicies(x *xorm.Engine) error {
+	type User struct {
+		RequireTwoFactor      bool           `xorm:"NOT NULL DEFAULT false"`
+		TwoFactorDeadlineUnix util.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	type TwoFactor struct {
+		ID          int64 `xorm:"pk autoincr"`
+		UID         int64 `xorm:"UNIQUE"`
+		ScratchHash string
+	}
+
+	type TwoFactorScratchCode struct {
+		ID          int64          `xorm:"pk autoincr"`
+		UID         int64          `xorm:"INDEX"`
+		Hash        string         `xorm:"INDEX"`
+		CreatedUnix util.TimeStamp `xorm:"created"`
+	}
+
+	if err := x.Sync2(new(User), new(TwoFactorScratchCode)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+
+	tokens := make([]*TwoFactor, 0, 10)
+	if err := x.Where("scratch_hash <> ''").Find(&tokens); err != nil {
+		return fmt.Errorf("Find: %v", err)
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	// The former single scratch token becomes the only scratch code left
+	for _, t := range tokens {
+		if _, err := sess.Insert(&TwoFactorScratchCode{UID: t.UID, Hash: t.ScratchHash}); err != nil {
+			return fmt.Errorf("Insert: %v", err)
+		}
+	}
+
+	if err := dropTableColumns(sess, "two_factor", "scratch_hash"); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
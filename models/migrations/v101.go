@@ -0,0 +1,30 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/masoodkamyab/gitea/modules/util"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addUserSessions(x *xorm.Engine) error {
+	type UserSession struct {
+		ID           int64          `xorm:"pk autoincr"`
+		UID          int64          `xorm:"INDEX"`
+		SessionHash  string         `xorm:"UNIQUE VARCHAR(64)"`
+		IP           string         `xorm:"VARCHAR(64)"`
+		UserAgent    string         `xorm:"TEXT"`
+		CreatedUnix  util.TimeStamp `xorm:"INDEX created"`
+		LastSeenUnix util.TimeStamp `xorm:"INDEX"`
+	}
+
+	if err := x.Sync2(new(UserSession)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
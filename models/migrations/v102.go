@@ -0,0 +1,34 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/masoodkamyab/gitea/modules/util"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addAuditEvents(x *xorm.Engine) error {
+	type AuditEvent struct {
+		ID          int64 `xorm:"pk autoincr"`
+		Action      int   `xorm:"INDEX NOT NULL"`
+		ActorID     int64 `xorm:"INDEX"`
+		ActorName   string
+		IP          string `xorm:"VARCHAR(64)"`
+		OwnerID     int64  `xorm:"INDEX"`
+		RepoID      int64  `xorm:"INDEX"`
+		RepoName    string
+		Target      string
+		Diff        string         `xorm:"TEXT"`
+		CreatedUnix util.TimeStamp `xorm:"INDEX created"`
+	}
+
+	if err := x.Sync2(new(AuditEvent)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/masoodkamyab/gitea/modules/util"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addOrgUserCreated(x *xorm.Engine) error {
+	// Members who joined before have no join time and share the grace period of the organization
+	type OrgUser struct {
+		CreatedUnix util.TimeStamp `xorm:"NOT NULL DEFAULT 0 created"`
+	}
+
+	if err := x.Sync2(new(OrgUser)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
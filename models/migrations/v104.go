@@ -0,0 +1,30 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+// OAuth2AuthorizationCodeV104 describes the added fields for OAuth2AuthorizationCode
+type OAuth2AuthorizationCodeV104 struct {
+	Scope       string
+	OpenIDScope string `xorm:"openid_scope"`
+}
+
+// TableName will be invoked by XORM to customize the table name
+func (*OAuth2AuthorizationCodeV104) TableName() string {
+	return "oauth2_authorization_code"
+}
+
+func addOAuth2AuthorizationCodeScopes(x *xorm.Engine) error {
+	// codes generated before keep the scopes of their grant
+	if err := x.Sync2(new(OAuth2AuthorizationCodeV104)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/masoodkamyab/gitea/modules/util"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addUserSessionRememberTokens(x *xorm.Engine) error {
+	type UserSession struct {
+		RememberHash  string         `xorm:"INDEX VARCHAR(64)"`
+		RememberUntil util.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
+	}
+
+	if err := x.Sync2(new(UserSession)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/masoodkamyab/gitea/modules/util"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addMergeQueue(x *xorm.Engine) error {
+	type ProtectedBranch struct {
+		EnableMergeQueue bool `xorm:"NOT NULL DEFAULT false"`
+	}
+
+	type MergeQueueEntry struct {
+		ID           int64  `xorm:"pk autoincr"`
+		RepoID       int64  `xorm:"INDEX(s)"`
+		BaseBranch   string `xorm:"INDEX(s)"`
+		PullID       int64  `xorm:"UNIQUE"`
+		DoerID       int64
+		MergeStyle   string `xorm:"VARCHAR(20)"`
+		Message      string `xorm:"TEXT"`
+		BaseCommitID string `xorm:"VARCHAR(40)"`
+		HeadCommitID string `xorm:"VARCHAR(40)"`
+		CommitID     string `xorm:"VARCHAR(40) INDEX"`
+
+		CreatedUnix util.TimeStamp `xorm:"INDEX created"`
+		UpdatedUnix util.TimeStamp `xorm:"INDEX updated"`
+	}
+
+	if err := x.Sync2(new(ProtectedBranch)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return x.Sync2(new(MergeQueueEntry))
+}
@@ -0,0 +1,57 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/masoodkamyab/gitea/modules/util"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addProjects(x *xorm.Engine) error {
+	type Project struct {
+		ID          int64  `xorm:"pk autoincr"`
+		Title       string `xorm:"INDEX NOT NULL"`
+		Description string `xorm:"TEXT"`
+		RepoID      int64  `xorm:"INDEX"`
+		OwnerID     int64  `xorm:"INDEX"`
+		CreatorID   int64  `xorm:"NOT NULL"`
+		IsClosed    bool   `xorm:"INDEX"`
+		Type        uint8
+
+		CreatedUnix    util.TimeStamp `xorm:"INDEX created"`
+		UpdatedUnix    util.TimeStamp `xorm:"INDEX updated"`
+		ClosedDateUnix util.TimeStamp
+	}
+
+	type ProjectBoard struct {
+		ID        int64  `xorm:"pk autoincr"`
+		ProjectID int64  `xorm:"INDEX NOT NULL"`
+		Title     string `xorm:"NOT NULL"`
+		Sorting   int
+		CreatorID int64 `xorm:"NOT NULL"`
+
+		CreatedUnix util.TimeStamp `xorm:"INDEX created"`
+		UpdatedUnix util.TimeStamp `xorm:"INDEX updated"`
+	}
+
+	type ProjectIssue struct {
+		ID             int64 `xorm:"pk autoincr"`
+		ProjectID      int64 `xorm:"UNIQUE(s) NOT NULL"`
+		IssueID        int64 `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		ProjectBoardID int64 `xorm:"INDEX"`
+		Sorting        int
+	}
+
+	if err := x.Sync2(new(Project), new(ProjectBoard), new(ProjectIssue)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+
+	// Teams which may access issues get access to the projects unit as well
+	_, err := x.Exec("INSERT INTO `team_unit` (org_id, team_id, `type`) SELECT org_id, team_id, 8 FROM `team_unit` WHERE `type` = 2")
+	return err
+}
@@ -0,0 +1,34 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/masoodkamyab/gitea/modules/util"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addSavedFilters(x *xorm.Engine) error {
+	type SavedFilter struct {
+		ID     int64 `xorm:"pk autoincr"`
+		UserID int64 `xorm:"INDEX NOT NULL"`
+		RepoID int64 `xorm:"INDEX"`
+		TeamID int64 `xorm:"INDEX"`
+
+		Name     string `xorm:"NOT NULL"`
+		Query    string `xorm:"TEXT"`
+		SortType string
+
+		CreatedUnix util.TimeStamp `xorm:"INDEX created"`
+		UpdatedUnix util.TimeStamp `xorm:"INDEX updated"`
+	}
+
+	if err := x.Sync2(new(SavedFilter)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addIssueRedirects(x *xorm.Engine) error {
+	type IssueRedirect struct {
+		ID        int64 `xorm:"pk autoincr"`
+		OldRepoID int64 `xorm:"UNIQUE(s) NOT NULL"`
+		OldIndex  int64 `xorm:"UNIQUE(s) NOT NULL"`
+		IssueID   int64 `xorm:"INDEX NOT NULL"`
+	}
+
+	if err := x.Sync2(new(IssueRedirect)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/masoodkamyab/gitea/modules/util"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addIssueFields(x *xorm.Engine) error {
+	type IssueField struct {
+		ID          int64  `xorm:"pk autoincr"`
+		OwnerID     int64  `xorm:"INDEX"`
+		RepoID      int64  `xorm:"INDEX"`
+		Name        string `xorm:"NOT NULL"`
+		Description string
+		Type        int
+		Options     []string       `xorm:"TEXT JSON"`
+		CreatedUnix util.TimeStamp `xorm:"INDEX created"`
+		UpdatedUnix util.TimeStamp `xorm:"INDEX updated"`
+	}
+
+	type IssueFieldValue struct {
+		ID      int64  `xorm:"pk autoincr"`
+		IssueID int64  `xorm:"INDEX NOT NULL"`
+		FieldID int64  `xorm:"INDEX NOT NULL"`
+		Value   string `xorm:"TEXT"`
+	}
+
+	if err := x.Sync2(new(IssueField), new(IssueFieldValue)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/masoodkamyab/gitea/modules/util"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addMilestoneSnapshots(x *xorm.Engine) error {
+	type MilestoneSnapshot struct {
+		ID              int64          `xorm:"pk autoincr"`
+		MilestoneID     int64          `xorm:"UNIQUE(s) NOT NULL"`
+		DayUnix         util.TimeStamp `xorm:"UNIQUE(s) NOT NULL"`
+		NumIssues       int
+		NumClosedIssues int
+		TrackedTime     int64
+	}
+
+	if err := x.Sync2(new(MilestoneSnapshot)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
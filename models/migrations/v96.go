@@ -0,0 +1,26 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addIssueEstimates(x *xorm.Engine) error {
+	type Issue struct {
+		EstimatedTime int64 `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	type MilestoneSnapshot struct {
+		EstimatedTime int64 `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	if err := x.Sync2(new(Issue), new(MilestoneSnapshot)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+// OAuth2GrantV97 describes the added field for OAuth2Grant
+type OAuth2GrantV97 struct {
+	Scope string `xorm:"NOT NULL DEFAULT 'all'"`
+}
+
+// TableName will be invoked by XORM to customize the table name
+func (*OAuth2GrantV97) TableName() string {
+	return "oauth2_grant"
+}
+
+func addAccessTokenScopes(x *xorm.Engine) error {
+	// existing tokens and grants keep the full power of their user
+	type AccessToken struct {
+		Scope       string `xorm:"NOT NULL DEFAULT 'all'"`
+		ExpiresUnix int64
+	}
+
+	if err := x.Sync2(new(AccessToken), new(OAuth2GrantV97)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+// OAuth2ApplicationV98 describes the added field for OAuth2Application
+type OAuth2ApplicationV98 struct {
+	ConfidentialClient bool `xorm:"NOT NULL DEFAULT true"`
+}
+
+// TableName will be invoked by XORM to customize the table name
+func (*OAuth2ApplicationV98) TableName() string {
+	return "oauth2_application"
+}
+
+// OAuth2AuthorizationCodeV98 describes the added field for OAuth2AuthorizationCode
+type OAuth2AuthorizationCodeV98 struct {
+	Nonce string `xorm:"TEXT"`
+}
+
+// TableName will be invoked by XORM to customize the table name
+func (*OAuth2AuthorizationCodeV98) TableName() string {
+	return "oauth2_authorization_code"
+}
+
+// OAuth2GrantV98 describes the added field for OAuth2Grant
+type OAuth2GrantV98 struct {
+	OpenIDScope string `xorm:"openid_scope"`
+}
+
+// TableName will be invoked by XORM to customize the table name
+func (*OAuth2GrantV98) TableName() string {
+	return "oauth2_grant"
+}
+
+func addOpenIDConnect(x *xorm.Engine) error {
+	// existing applications keep authenticating with their client secret
+	if err := x.Sync2(new(OAuth2ApplicationV98), new(OAuth2AuthorizationCodeV98), new(OAuth2GrantV98)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/masoodkamyab/gitea/modules/log"
+	"github.com/masoodkamyab/gitea/modules/util"
+
+	"github.com/go-xorm/xorm"
+)
+
+// U2FRegistrationV99 describes the security keys registered through U2F
+type U2FRegistrationV99 struct {
+	ID          int64 `xorm:"pk autoincr"`
+	Name        string
+	UserID      int64 `xorm:"INDEX"`
+	Raw         []byte
+	Counter     uint32         `xorm:"BIGINT"`
+	CreatedUnix util.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix util.TimeStamp `xorm:"INDEX updated"`
+}
+
+// TableName will be invoked by XORM to customize the table name
+func (*U2FRegistrationV99) TableName() string {
+	return "u2f_registration"
+}
+
+// WebAuthnCredentialV99 describes the WebAuthn credentials replacing U2F registrations
+type WebAuthnCredentialV99 struct {
+	ID              int64 `xorm:"pk autoincr"`
+	Name            string
+	LowerName       string
+	UserID          int64  `xorm:"INDEX"`
+	CredentialID    string `xorm:"INDEX VARCHAR(410)"`
+	PublicKey       []byte
+	AttestationType string
+	AAGUID          []byte         `xorm:"'aaguid'"`
+	SignCount       uint32         `xorm:"BIGINT"`
+	LegacyU2F       bool           `xorm:"'legacy_u2f' NOT NULL DEFAULT false"`
+	CreatedUnix     util.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix     util.TimeStamp `xorm:"INDEX updated"`
+}
+
+// TableName will be invoked by XORM to customize the table name
+func (*WebAuthnCredentialV99) TableName() string {
+	return "webauthn_credential"
+}
+
+// parseU2FRegistrationV99 extracts the key handle and public key of a U2F
+// registration response: 0x05 | public key (65 bytes) | key handle length |
+// key handle | attestation certificate | signature
+func parseU2FRegistrationV99(raw []byte) ([]byte, *ecdsa.PublicKey, error) {
+	if len(raw) < 67 || raw[0] != 0x05 || raw[1] != 0x04 {
+		return nil, nil, fmt.Errorf("invalid registration data")
+	}
+	pub := &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(raw[2:34]),
+		Y:     new(big.Int).SetBytes(raw[34:66]),
+	}
+	if !pub.Curve.IsOnCurve(pub.X, pub.Y) {
+		return nil, nil, fmt.Errorf("invalid public key")
+	}
+	khLen := int(raw[66])
+	if len(raw) < 67+khLen {
+		return nil, nil, fmt.Errorf("invalid key handle")
+	}
+	return raw[67 : 67+khLen], pub, nil
+}
+
+// marshalCOSEPublicKeyV99 encodes a P-256 public key as COSE ES256 key, a CBOR
+// map of the key type (EC2), algorithm (ES256), curve (P-256) and coordinates
+func marshalCOSEPublicKeyV99(pub *ecdsa.PublicKey) []byte {
+	coord := func(n *big.Int) []byte {
+		b := n.Bytes()
+		return append(make([]byte, 32-len(b)), b...)
+	}
+	key := []byte{
+		0xa5,       // map of 5 pairs
+		0x01, 0x02, // kty: EC2
+		0x03, 0x26, // alg: ES256 (-7)
+		0x20, 0x01, // crv (-1): P-256
+		0x21, 0x58, 0x20, // x (-2): 32 bytes
+	}
+	key = append(key, coord(pub.X)...)
+	key = append(key, 0x22, 0x58, 0x20) // y (-3): 32 bytes
+	return append(key, coord(pub.Y)...)
+}
+
+func migrateU2FToWebAuthn(x *xorm.Engine) error {
+	if err := x.Sync2(new(WebAuthnCredentialV99)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+
+	if exist, err := x.IsTableExist(new(U2FRegistrationV99)); err != nil {
+		return fmt.Errorf("IsExist U2FRegistration: %v", err)
+	} else if !exist {
+		return nil
+	}
+
+	regs := make([]*U2FRegistrationV99, 0, 10)
+	if err := x.Find(&regs); err != nil {
+		return fmt.Errorf("Find: %v", err)
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	var dropped int
+	for _, reg := range regs {
+		keyHandle, pub, err := parseU2FRegistrationV99(reg.Raw)
+		if err != nil {
+			// The user has to register the security key again
+			log.Error("U2F registration %d (%q) of user %d cannot be migrated and is removed: %v", reg.ID, reg.Name, reg.UserID, err)
+			dropped++
+			continue
+		}
+		// Browsers scope these keys to the U2F AppID, requested with the appid extension
+		cred := &WebAuthnCredentialV99{
+			Name:            reg.Name,
+			LowerName:       strings.ToLower(reg.Name),
+			UserID:          reg.UserID,
+			CredentialID:    base64.RawURLEncoding.EncodeToString(keyHandle),
+			PublicKey:       marshalCOSEPublicKeyV99(pub),
+			AttestationType: "fido-u2f",
+			SignCount:       reg.Counter,
+			LegacyU2F:       true,
+			CreatedUnix:     reg.CreatedUnix,
+			UpdatedUnix:     reg.UpdatedUnix,
+		}
+		if _, err = sess.NoAutoTime().Insert(cred); err != nil {
+			return fmt.Errorf("Insert: %v", err)
+		}
+	}
+
+	if dropped > 0 {
+		log.Error("%d of %d U2F registrations were removed, their users have to register the security keys again", dropped, len(regs))
+	}
+
+	if err := sess.DropTable(new(U2FRegistrationV99)); err != nil {
+		return fmt.Errorf("DropTable: %v", err)
+	}
+	return sess.Commit()
+}
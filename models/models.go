@@ -103,11 +103,14 @@ func init() {
 		new(TeamUser),
 		new(TeamRepo),
 		new(Notice),
+		new(AuditEvent),
 		new(EmailAddress),
 		new(Notification),
 		new(IssueUser),
 		new(LFSMetaObject),
 		new(TwoFactor),
+		new(TwoFactorScratchCode),
+		new(UserSession),
 		new(GPGKey),
 		new(GPGKeyImport),
 		new(RepoUnit),
@@ -125,12 +128,21 @@ func init() {
 		new(LFSLock),
 		new(Reaction),
 		new(IssueAssignees),
-		new(U2FRegistration),
+		new(WebAuthnCredential),
 		new(TeamUnit),
 		new(Review),
 		new(OAuth2Application),
 		new(OAuth2AuthorizationCode),
 		new(OAuth2Grant),
+		new(MergeQueueEntry),
+		new(Project),
+		new(ProjectBoard),
+		new(ProjectIssue),
+		new(SavedFilter),
+		new(IssueRedirect),
+		new(IssueField),
+		new(IssueFieldValue),
+		new(MilestoneSnapshot),
 	)
 
 	gonicNames := []string{"SSL", "UID"}
@@ -8,6 +8,9 @@ import (
 	"sort"
 
 	"github.com/masoodkamyab/gitea/modules/auth/oauth2"
+	"github.com/masoodkamyab/gitea/modules/setting"
+
+	"github.com/markbates/goth"
 )
 
 // OAuth2Provider describes the display values of a single OAuth2 provider
@@ -102,11 +105,16 @@ func InitOAuth2() error {
 	if err := oauth2.Init(x); err != nil {
 		return err
 	}
+	if setting.OAuth2.Enable {
+		if err := initOAuth2SigningKey(setting.OAuth2.JWTSigningPrivateKeyFile); err != nil {
+			return err
+		}
+	}
 	loginSources, _ := GetActiveOAuth2ProviderLoginSources()
 
 	for _, source := range loginSources {
 		oAuth2Config := source.OAuth2()
-		err := oauth2.RegisterProvider(source.Name, oAuth2Config.Provider, oAuth2Config.ClientID, oAuth2Config.ClientSecret, oAuth2Config.OpenIDConnectAutoDiscoveryURL, oAuth2Config.CustomURLMapping)
+		err := oauth2.RegisterProvider(source.Name, oAuth2Config.Provider, oAuth2Config.ClientID, oAuth2Config.ClientSecret, oAuth2Config.OpenIDConnectAutoDiscoveryURL, oAuth2Config.CustomURLMapping, oAuth2Config.Scopes, oAuth2Config.ClaimMapping)
 		if err != nil {
 			return err
 		}
@@ -122,3 +130,20 @@ func wrapOpenIDConnectInitializeError(err error, providerName string, oAuth2Conf
 	}
 	return err
 }
+
+// SyncOAuth2User synchronizes the full name, email address, administrator status and team memberships
+// of a user with the claims of an OpenID Connect login, other providers are left untouched.
+func SyncOAuth2User(source *LoginSource, user *User, gothUser goth.User) error {
+	cfg := source.OAuth2()
+	if cfg.Provider != "openidConnect" {
+		return nil
+	}
+
+	if err := syncExternalUserProfile(user, gothUser.Name, gothUser.Email); err != nil {
+		return err
+	}
+	if len(cfg.GroupClaimName) == 0 {
+		return nil
+	}
+	return syncExternalUserGroups(user, getExternalUserGroups(gothUser.RawData[cfg.GroupClaimName]), cfg.AdminGroup, cfg.GroupTeamMap, cfg.GroupTeamMapRemoval)
+}
@@ -36,6 +36,10 @@ type OAuth2Application struct {
 
 	RedirectURIs []string `xorm:"redirect_uris JSON TEXT"`
 
+	// ConfidentialClient is false for clients which cannot keep a secret, like native or browser applications.
+	// They authenticate with PKCE instead of the client secret.
+	ConfidentialClient bool `xorm:"NOT NULL DEFAULT true"`
+
 	CreatedUnix util.TimeStamp `xorm:"INDEX created"`
 	UpdatedUnix util.TimeStamp `xorm:"INDEX updated"`
 }
@@ -103,15 +107,17 @@ func (app *OAuth2Application) getGrantByUserID(e Engine, userID int64) (grant *O
 	return grant, nil
 }
 
-// CreateGrant generates a grant for an user
-func (app *OAuth2Application) CreateGrant(userID int64) (*OAuth2Grant, error) {
-	return app.createGrant(x, userID)
+// CreateGrant generates a grant with the given scopes for an user
+func (app *OAuth2Application) CreateGrant(userID int64, scope AccessTokenScope, openIDScope OpenIDScope) (*OAuth2Grant, error) {
+	return app.createGrant(x, userID, scope, openIDScope)
 }
 
-func (app *OAuth2Application) createGrant(e Engine, userID int64) (*OAuth2Grant, error) {
+func (app *OAuth2Application) createGrant(e Engine, userID int64, scope AccessTokenScope, openIDScope OpenIDScope) (*OAuth2Grant, error) {
 	grant := &OAuth2Grant{
 		ApplicationID: app.ID,
 		UserID:        userID,
+		Scope:         scope,
+		OpenIDScope:   openIDScope,
 	}
 	_, err := e.Insert(grant)
 	if err != nil {
@@ -164,9 +170,10 @@ func getOAuth2ApplicationsByUserID(e Engine, userID int64) (apps []*OAuth2Applic
 
 // CreateOAuth2ApplicationOptions holds options to create an oauth2 application
 type CreateOAuth2ApplicationOptions struct {
-	Name         string
-	UserID       int64
-	RedirectURIs []string
+	Name               string
+	UserID             int64
+	RedirectURIs       []string
+	ConfidentialClient bool
 }
 
 // CreateOAuth2Application inserts a new oauth2 application
@@ -177,10 +184,11 @@ func CreateOAuth2Application(opts CreateOAuth2ApplicationOptions) (*OAuth2Applic
 func createOAuth2Application(e Engine, opts CreateOAuth2ApplicationOptions) (*OAuth2Application, error) {
 	clientID := uuid.NewV4().String()
 	app := &OAuth2Application{
-		UID:          opts.UserID,
-		Name:         opts.Name,
-		ClientID:     clientID,
-		RedirectURIs: opts.RedirectURIs,
+		UID:                opts.UserID,
+		Name:               opts.Name,
+		ClientID:           clientID,
+		RedirectURIs:       opts.RedirectURIs,
+		ConfidentialClient: opts.ConfidentialClient,
 	}
 	if _, err := e.Insert(app); err != nil {
 		return nil, err
@@ -190,10 +198,11 @@ func createOAuth2Application(e Engine, opts CreateOAuth2ApplicationOptions) (*OA
 
 // UpdateOAuth2ApplicationOptions holds options to update an oauth2 application
 type UpdateOAuth2ApplicationOptions struct {
-	ID           int64
-	Name         string
-	UserID       int64
-	RedirectURIs []string
+	ID                 int64
+	Name               string
+	UserID             int64
+	RedirectURIs       []string
+	ConfidentialClient bool
 }
 
 // UpdateOAuth2Application updates an oauth2 application
@@ -203,12 +212,13 @@ func UpdateOAuth2Application(opts UpdateOAuth2ApplicationOptions) error {
 
 func updateOAuth2Application(e Engine, opts UpdateOAuth2ApplicationOptions) error {
 	app := &OAuth2Application{
-		ID:           opts.ID,
-		UID:          opts.UserID,
-		Name:         opts.Name,
-		RedirectURIs: opts.RedirectURIs,
+		ID:                 opts.ID,
+		UID:                opts.UserID,
+		Name:               opts.Name,
+		RedirectURIs:       opts.RedirectURIs,
+		ConfidentialClient: opts.ConfidentialClient,
 	}
-	if _, err := e.ID(opts.ID).Update(app); err != nil {
+	if _, err := e.ID(opts.ID).UseBool("confidential_client").Update(app); err != nil {
 		return err
 	}
 	return nil
@@ -264,6 +274,9 @@ type OAuth2AuthorizationCode struct {
 	CodeChallenge       string
 	CodeChallengeMethod string
 	RedirectURI         string
+	Nonce               string `xorm:"TEXT"`
+	Scope               AccessTokenScope
+	OpenIDScope         OpenIDScope    `xorm:"openid_scope"`
 	ValidUntil          util.TimeStamp `xorm:"index"`
 }
 
@@ -286,6 +299,15 @@ func (code *OAuth2AuthorizationCode) GenerateRedirectURI(state string) (redirect
 	return
 }
 
+// Scopes returns the scopes the client asked for with this code. Codes
+// generated before they kept the scopes have the ones of the grant.
+func (code *OAuth2AuthorizationCode) Scopes() (AccessTokenScope, OpenIDScope) {
+	if code.Scope == "" && code.OpenIDScope == "" {
+		return code.Grant.Scope, code.Grant.OpenIDScope
+	}
+	return code.Scope, code.OpenIDScope
+}
+
 // Invalidate deletes the auth code from the database to invalidate this code
 func (code *OAuth2AuthorizationCode) Invalidate() error {
 	return code.invalidate(x)
@@ -348,6 +370,8 @@ type OAuth2Grant struct {
 	Application   *OAuth2Application `xorm:"-"`
 	ApplicationID int64              `xorm:"INDEX unique(user_application)"`
 	Counter       int64              `xorm:"NOT NULL DEFAULT 1"`
+	Scope         AccessTokenScope   `xorm:"NOT NULL DEFAULT 'all'"`
+	OpenIDScope   OpenIDScope        `xorm:"openid_scope"`
 	CreatedUnix   util.TimeStamp     `xorm:"created"`
 	UpdatedUnix   util.TimeStamp     `xorm:"updated"`
 }
@@ -357,12 +381,14 @@ func (grant *OAuth2Grant) TableName() string {
 	return "oauth2_grant"
 }
 
-// GenerateNewAuthorizationCode generates a new authorization code for a grant and saves it to the databse
-func (grant *OAuth2Grant) GenerateNewAuthorizationCode(redirectURI, codeChallenge, codeChallengeMethod string) (*OAuth2AuthorizationCode, error) {
-	return grant.generateNewAuthorizationCode(x, redirectURI, codeChallenge, codeChallengeMethod)
+// GenerateNewAuthorizationCode generates a new authorization code for a grant and saves it to the databse.
+// The nonce of an OpenID Connect request is returned in the id_token.
+// The code is limited to the given scopes, which the grant has to cover.
+func (grant *OAuth2Grant) GenerateNewAuthorizationCode(redirectURI, codeChallenge, codeChallengeMethod, nonce string, scope AccessTokenScope, openIDScope OpenIDScope) (*OAuth2AuthorizationCode, error) {
+	return grant.generateNewAuthorizationCode(x, redirectURI, codeChallenge, codeChallengeMethod, nonce, scope, openIDScope)
 }
 
-func (grant *OAuth2Grant) generateNewAuthorizationCode(e Engine, redirectURI, codeChallenge, codeChallengeMethod string) (code *OAuth2AuthorizationCode, err error) {
+func (grant *OAuth2Grant) generateNewAuthorizationCode(e Engine, redirectURI, codeChallenge, codeChallengeMethod, nonce string, scope AccessTokenScope, openIDScope OpenIDScope) (code *OAuth2AuthorizationCode, err error) {
 	var codeSecret string
 	if codeSecret, err = secret.New(); err != nil {
 		return &OAuth2AuthorizationCode{}, err
@@ -374,6 +400,9 @@ func (grant *OAuth2Grant) generateNewAuthorizationCode(e Engine, redirectURI, co
 		Code:                codeSecret,
 		CodeChallenge:       codeChallenge,
 		CodeChallengeMethod: codeChallengeMethod,
+		Nonce:               nonce,
+		Scope:               scope,
+		OpenIDScope:         openIDScope,
 	}
 	if _, err := e.Insert(code); err != nil {
 		return nil, err
@@ -399,6 +428,14 @@ func (grant *OAuth2Grant) increaseCount(e Engine) error {
 	return nil
 }
 
+// UpdateScope replaces the scopes of the grant, the next access tokens of the application are limited to them
+func (grant *OAuth2Grant) UpdateScope(scope AccessTokenScope, openIDScope OpenIDScope) error {
+	grant.Scope = scope
+	grant.OpenIDScope = openIDScope
+	_, err := x.ID(grant.ID).Cols("scope", "openid_scope").Update(grant)
+	return err
+}
+
 // GetOAuth2GrantByID returns the grant with the given ID
 func GetOAuth2GrantByID(id int64) (*OAuth2Grant, error) {
 	return getOAuth2GrantByID(x, id)
@@ -470,12 +507,23 @@ const (
 
 // OAuth2Token represents a JWT token used to authenticate a client
 type OAuth2Token struct {
-	GrantID int64           `json:"gnt"`
-	Type    OAuth2TokenType `json:"tt"`
-	Counter int64           `json:"cnt,omitempty"`
+	GrantID     int64            `json:"gnt"`
+	Type        OAuth2TokenType  `json:"tt"`
+	Counter     int64            `json:"cnt,omitempty"`
+	Scope       AccessTokenScope `json:"scp,omitempty"`
+	OpenIDScope OpenIDScope      `json:"oscp,omitempty"`
 	jwt.StandardClaims
 }
 
+// Scopes returns the scopes the token was issued for. Tokens issued before
+// they carried the scopes have the ones of the grant.
+func (token *OAuth2Token) Scopes(grant *OAuth2Grant) (AccessTokenScope, OpenIDScope) {
+	if token.Scope == "" && token.OpenIDScope == "" {
+		return grant.Scope, grant.OpenIDScope
+	}
+	return token.Scope, token.OpenIDScope
+}
+
 // ParseOAuth2Token parses a singed jwt string
 func ParseOAuth2Token(jwtToken string) (*OAuth2Token, error) {
 	parsedToken, err := jwt.ParseWithClaims(jwtToken, &OAuth2Token{}, func(token *jwt.Token) (interface{}, error) {
@@ -68,6 +68,19 @@ func TestCreateOAuth2Application(t *testing.T) {
 	AssertExistsAndLoadBean(t, &OAuth2Application{Name: "newapp"})
 }
 
+func TestUpdateOAuth2Application(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	assert.NoError(t, UpdateOAuth2Application(UpdateOAuth2ApplicationOptions{
+		ID:           1,
+		Name:         "Public",
+		UserID:       1,
+		RedirectURIs: []string{"b"},
+	}))
+	app := AssertExistsAndLoadBean(t, &OAuth2Application{ID: 1, Name: "Public"}).(*OAuth2Application)
+	assert.False(t, app.ConfidentialClient)
+	assert.EqualValues(t, []string{"b"}, app.RedirectURIs)
+}
+
 func TestOAuth2Application_LoadUser(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 	app := AssertExistsAndLoadBean(t, &OAuth2Application{ID: 1}).(*OAuth2Application)
@@ -94,11 +107,13 @@ func TestOAuth2Application_GetGrantByUserID(t *testing.T) {
 func TestOAuth2Application_CreateGrant(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 	app := AssertExistsAndLoadBean(t, &OAuth2Application{ID: 1}).(*OAuth2Application)
-	grant, err := app.CreateGrant(2)
+	grant, err := app.CreateGrant(2, AccessTokenScopeRepoRead, "openid email")
 	assert.NoError(t, err)
 	assert.NotNil(t, grant)
 	assert.Equal(t, int64(2), grant.UserID)
 	assert.Equal(t, int64(1), grant.ApplicationID)
+	assert.Equal(t, AccessTokenScopeRepoRead, grant.Scope)
+	AssertExistsAndLoadBean(t, &OAuth2Grant{ID: grant.ID, OpenIDScope: "openid email"})
 }
 
 //////////////////// Grant
@@ -125,10 +140,17 @@ func TestOAuth2Grant_IncreaseCounter(t *testing.T) {
 func TestOAuth2Grant_GenerateNewAuthorizationCode(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 	grant := AssertExistsAndLoadBean(t, &OAuth2Grant{ID: 1}).(*OAuth2Grant)
-	code, err := grant.GenerateNewAuthorizationCode("https://example2.com/callback", "CjvyTLSdR47G5zYenDA-eDWW4lRrO8yvjcWwbD_deOg", "S256")
+	code, err := grant.GenerateNewAuthorizationCode("https://example2.com/callback", "CjvyTLSdR47G5zYenDA-eDWW4lRrO8yvjcWwbD_deOg", "S256", "n-0S6_WzA2Mj",
+		AccessTokenScopeRepoRead, "")
 	assert.NoError(t, err)
 	assert.NotNil(t, code)
 	assert.True(t, len(code.Code) > 32) // secret length > 32
+	AssertExistsAndLoadBean(t, &OAuth2AuthorizationCode{Code: code.Code, Nonce: "n-0S6_WzA2Mj", Scope: AccessTokenScopeRepoRead})
+
+	// the code has the requested scope, not the broader one of the grant
+	scope, openIDScope := code.Scopes()
+	assert.Equal(t, AccessTokenScopeRepoRead, scope)
+	assert.EqualValues(t, "", openIDScope)
 }
 
 func TestOAuth2Grant_TableName(t *testing.T) {
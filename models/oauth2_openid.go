@@ -0,0 +1,315 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/masoodkamyab/gitea/modules/log"
+	"github.com/masoodkamyab/gitea/modules/setting"
+
+	"github.com/Unknwon/com"
+	"github.com/dgrijalva/jwt-go"
+)
+
+// OpenIDScope is a space separated set of the OpenID Connect scopes granted to an OAuth2 client
+type OpenIDScope string
+
+// The OpenID Connect scopes, the claims of the other scopes are only released with openid
+const (
+	// OpenIDScopeOpenID makes an authorization request an OpenID Connect request returning an id_token
+	OpenIDScopeOpenID OpenIDScope = "openid"
+	// OpenIDScopeProfile releases the name, username, profile, picture and website of the user
+	OpenIDScopeProfile OpenIDScope = "profile"
+	// OpenIDScopeEmail releases the primary email address of the user
+	OpenIDScopeEmail OpenIDScope = "email"
+	// OpenIDScopeGroups releases the organizations and teams of the user
+	OpenIDScopeGroups OpenIDScope = "groups"
+)
+
+// OpenIDScopes lists all single OpenID Connect scopes in the order they are shown
+var OpenIDScopes = []OpenIDScope{
+	OpenIDScopeOpenID,
+	OpenIDScopeProfile,
+	OpenIDScopeEmail,
+	OpenIDScopeGroups,
+}
+
+// ParseOpenIDScope extracts the OpenID Connect scopes from the scope requested by an OAuth2 client,
+// it returns an empty set if openid was not requested.
+func ParseOpenIDScope(scope string) OpenIDScope {
+	requested := make(map[OpenIDScope]bool)
+	for _, field := range strings.FieldsFunc(scope, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	}) {
+		requested[OpenIDScope(strings.ToLower(field))] = true
+	}
+	if !requested[OpenIDScopeOpenID] {
+		return ""
+	}
+
+	scopes := make([]string, 0, len(requested))
+	for _, s := range OpenIDScopes {
+		if requested[s] {
+			scopes = append(scopes, string(s))
+		}
+	}
+	return OpenIDScope(strings.Join(scopes, " "))
+}
+
+// LocaleKey returns the locale key of the description of a single scope
+func (s OpenIDScope) LocaleKey() string {
+	return "auth.openid_scope_" + string(s)
+}
+
+// List returns the single scopes of the set
+func (s OpenIDScope) List() []OpenIDScope {
+	fields := strings.Fields(string(s))
+	scopes := make([]OpenIDScope, len(fields))
+	for i := range fields {
+		scopes[i] = OpenIDScope(fields[i])
+	}
+	return scopes
+}
+
+// Has returns true if the set includes the scope
+func (s OpenIDScope) Has(scope OpenIDScope) bool {
+	for _, granted := range s.List() {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Contains returns true if the set includes all scopes of another set
+func (s OpenIDScope) Contains(other OpenIDScope) bool {
+	for _, scope := range other.List() {
+		if !s.Has(scope) {
+			return false
+		}
+	}
+	return true
+}
+
+//////////////////////////////////////////////////////////////
+
+// oauth2SigningKey signs the id_tokens, its public key is published as JSON Web Key Set
+var oauth2SigningKey *rsa.PrivateKey
+
+// oauth2SigningKeyID identifies the signing key in the key set by its RFC 7638 thumbprint
+var oauth2SigningKeyID string
+
+// initOAuth2SigningKey loads the private key signing the id_tokens and generates it if it does not exist
+func initOAuth2SigningKey(path string) error {
+	if !com.IsFile(path) {
+		log.Info("Generating OAuth2 signing key: %s", path)
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return fmt.Errorf("GenerateKey: %v", err)
+		}
+		if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return err
+		}
+		data := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+		if err = ioutil.WriteFile(path, data, 0600); err != nil {
+			return err
+		}
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return fmt.Errorf("no PEM data found in %s", path)
+	}
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return fmt.Errorf("ParsePKCS8PrivateKey: %v", err)
+		}
+		var ok bool
+		if key, ok = parsed.(*rsa.PrivateKey); !ok {
+			return fmt.Errorf("%s is not an RSA private key", path)
+		}
+	}
+
+	oauth2SigningKey = key
+	oauth2SigningKeyID, err = jsonWebKeyThumbprint(newJSONWebKey(&key.PublicKey, ""))
+	return err
+}
+
+// JSONWebKey is the public part of an RSA key as specified in RFC 7517
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	Algorithm string `json:"alg,omitempty"`
+	Use       string `json:"use,omitempty"`
+	KeyID     string `json:"kid,omitempty"`
+	Modulus   string `json:"n"`
+	Exponent  string `json:"e"`
+}
+
+// JSONWebKeySet is a set of public keys as specified in RFC 7517
+type JSONWebKeySet struct {
+	Keys []*JSONWebKey `json:"keys"`
+}
+
+func newJSONWebKey(key *rsa.PublicKey, keyID string) *JSONWebKey {
+	jwk := &JSONWebKey{
+		KeyType:  "RSA",
+		KeyID:    keyID,
+		Modulus:  base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		Exponent: base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+	if keyID != "" {
+		jwk.Algorithm = jwt.SigningMethodRS256.Alg()
+		jwk.Use = "sig"
+	}
+	return jwk
+}
+
+// jsonWebKeyThumbprint returns the RFC 7638 thumbprint of a key, its members have to be in lexicographic order
+func jsonWebKeyThumbprint(jwk *JSONWebKey) (string, error) {
+	data, err := json.Marshal(struct {
+		Exponent string `json:"e"`
+		KeyType  string `json:"kty"`
+		Modulus  string `json:"n"`
+	}{jwk.Exponent, jwk.KeyType, jwk.Modulus})
+	if err != nil {
+		return "", err
+	}
+	h := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(h[:]), nil
+}
+
+// GetOAuth2SigningKeySet returns the public keys verifying the id_tokens
+func GetOAuth2SigningKeySet() *JSONWebKeySet {
+	if oauth2SigningKey == nil {
+		return &JSONWebKeySet{Keys: []*JSONWebKey{}}
+	}
+	return &JSONWebKeySet{Keys: []*JSONWebKey{newJSONWebKey(&oauth2SigningKey.PublicKey, oauth2SigningKeyID)}}
+}
+
+//////////////////////////////////////////////////////////////
+
+// OIDCClaims are the claims about a user released for the granted OpenID Connect scopes
+type OIDCClaims struct {
+	Subject string `json:"sub"`
+
+	// profile scope
+	Name              string `json:"name,omitempty"`
+	PreferredUsername string `json:"preferred_username,omitempty"`
+	Profile           string `json:"profile,omitempty"`
+	Picture           string `json:"picture,omitempty"`
+	Website           string `json:"website,omitempty"`
+	UpdatedAt         int64  `json:"updated_at,omitempty"`
+
+	// email scope
+	Email         string `json:"email,omitempty"`
+	EmailVerified bool   `json:"email_verified,omitempty"`
+
+	// groups scope, the names of the organizations and the teams as `org:team`
+	Groups []string `json:"groups,omitempty"`
+}
+
+// GetOIDCClaims returns the claims about the user released for the scope
+func GetOIDCClaims(user *User, scope OpenIDScope) (*OIDCClaims, error) {
+	claims := &OIDCClaims{
+		Subject: strconv.FormatInt(user.ID, 10),
+	}
+	if scope.Has(OpenIDScopeProfile) {
+		claims.Name = user.DisplayName()
+		claims.PreferredUsername = user.Name
+		claims.Profile = user.HTMLURL()
+		claims.Picture = user.AvatarLink()
+		claims.Website = user.Website
+		claims.UpdatedAt = int64(user.UpdatedUnix)
+	}
+	if scope.Has(OpenIDScopeEmail) {
+		claims.Email = user.Email
+		claims.EmailVerified = user.IsActive
+	}
+	if scope.Has(OpenIDScopeGroups) {
+		groups, err := getOIDCGroups(user.ID)
+		if err != nil {
+			return nil, err
+		}
+		claims.Groups = groups
+	}
+	return claims, nil
+}
+
+func getOIDCGroups(userID int64) ([]string, error) {
+	orgs, err := GetOrgsByUserID(userID, true)
+	if err != nil {
+		return nil, err
+	}
+	teams, err := GetUserTeams(userID)
+	if err != nil {
+		return nil, err
+	}
+	orgNames := make(map[int64]string, len(orgs))
+	groups := make([]string, 0, len(orgs)+len(teams))
+	for _, org := range orgs {
+		orgNames[org.ID] = org.Name
+		groups = append(groups, org.Name)
+	}
+	for _, team := range teams {
+		if orgName, ok := orgNames[team.OrgID]; ok {
+			groups = append(groups, orgName+":"+team.Name)
+		}
+	}
+	return groups, nil
+}
+
+// OIDCToken is the id_token of an OpenID Connect authentication, it is signed with the RSA signing key
+type OIDCToken struct {
+	Issuer    string `json:"iss"`
+	Audience  string `json:"aud"`
+	ExpiresAt int64  `json:"exp"`
+	IssuedAt  int64  `json:"iat"`
+	AuthTime  int64  `json:"auth_time,omitempty"`
+	Nonce     string `json:"nonce,omitempty"`
+	*OIDCClaims
+}
+
+// Valid validates the time based claims of the token
+func (token *OIDCToken) Valid() error {
+	return (&jwt.StandardClaims{ExpiresAt: token.ExpiresAt, IssuedAt: token.IssuedAt}).Valid()
+}
+
+// SignToken signs the token with the RSA signing key
+func (token *OIDCToken) SignToken() (string, error) {
+	if oauth2SigningKey == nil {
+		return "", fmt.Errorf("OAuth2 signing key is not initialized")
+	}
+	token.IssuedAt = time.Now().Unix()
+	jwtToken := jwt.NewWithClaims(jwt.SigningMethodRS256, token)
+	jwtToken.Header["kid"] = oauth2SigningKeyID
+	return jwtToken.SignedString(oauth2SigningKey)
+}
+
+// OAuth2Issuer returns the issuer identifier of the id_tokens
+func OAuth2Issuer() string {
+	return strings.TrimSuffix(setting.AppURL, "/")
+}
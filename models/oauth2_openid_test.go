@@ -0,0 +1,88 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOpenIDScope(t *testing.T) {
+	assert.EqualValues(t, "", ParseOpenIDScope(""))
+	assert.EqualValues(t, "", ParseOpenIDScope("profile email repo:read"))
+	assert.EqualValues(t, "openid", ParseOpenIDScope("openid repo:read"))
+	assert.EqualValues(t, "openid profile email groups", ParseOpenIDScope("groups Email,profile openid"))
+}
+
+func TestOpenIDScope_Contains(t *testing.T) {
+	scope := OpenIDScope("openid profile email")
+	assert.True(t, scope.Has(OpenIDScopeEmail))
+	assert.False(t, scope.Has(OpenIDScopeGroups))
+	assert.True(t, scope.Contains(""))
+	assert.True(t, scope.Contains("openid email"))
+	assert.False(t, scope.Contains("openid groups"))
+}
+
+func TestInitOAuth2SigningKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "oauth2-signing-key")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "jwt", "private.pem")
+
+	// the key is generated once and loaded afterwards
+	assert.NoError(t, initOAuth2SigningKey(path))
+	key, keyID := oauth2SigningKey, oauth2SigningKeyID
+	assert.NoError(t, initOAuth2SigningKey(path))
+	assert.Equal(t, key.N, oauth2SigningKey.N)
+	assert.Equal(t, keyID, oauth2SigningKeyID)
+
+	keys := GetOAuth2SigningKeySet().Keys
+	assert.Len(t, keys, 1)
+	assert.Equal(t, "RSA", keys[0].KeyType)
+	assert.Equal(t, "RS256", keys[0].Algorithm)
+	assert.Equal(t, keyID, keys[0].KeyID)
+
+	// the id_token can be verified with the published key
+	signed, err := (&OIDCToken{
+		Issuer:     OAuth2Issuer(),
+		Audience:   "client",
+		ExpiresAt:  time.Now().Add(time.Minute).Unix(),
+		Nonce:      "nonce",
+		OIDCClaims: &OIDCClaims{Subject: "2"},
+	}).SignToken()
+	assert.NoError(t, err)
+	parsed, err := jwt.Parse(signed, func(token *jwt.Token) (interface{}, error) {
+		assert.Equal(t, keyID, token.Header["kid"])
+		return &key.PublicKey, nil
+	})
+	assert.NoError(t, err)
+	claims := parsed.Claims.(jwt.MapClaims)
+	assert.Equal(t, "2", claims["sub"])
+	assert.Equal(t, "client", claims["aud"])
+	assert.Equal(t, "nonce", claims["nonce"])
+}
+
+func TestGetOIDCClaims(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	user := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+
+	claims, err := GetOIDCClaims(user, "openid")
+	assert.NoError(t, err)
+	assert.Equal(t, &OIDCClaims{Subject: "2"}, claims)
+
+	claims, err = GetOIDCClaims(user, "openid profile email groups")
+	assert.NoError(t, err)
+	assert.Equal(t, user.Name, claims.PreferredUsername)
+	assert.Equal(t, user.HTMLURL(), claims.Profile)
+	assert.Equal(t, user.Email, claims.Email)
+	assert.Contains(t, claims.Groups, "user3")
+	assert.Contains(t, claims.Groups, "user3:team1")
+}
@@ -0,0 +1,49 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncOAuth2User(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	source := &LoginSource{Type: LoginOAuth2, Cfg: &OAuth2Config{
+		Provider:       "openidConnect",
+		GroupClaimName: "groups",
+		AdminGroup:     "admins",
+		GroupTeamMap:   `{"writers": {"user3": ["team1"]}}`,
+	}}
+	user := AssertExistsAndLoadBean(t, &User{ID: 5}).(*User)
+	gothUser := goth.User{
+		Name:    "Synced Name",
+		Email:   "Synced@Example.com",
+		RawData: map[string]interface{}{"groups": []interface{}{"admins", "writers"}},
+	}
+	assert.NoError(t, SyncOAuth2User(source, user, gothUser))
+	user = AssertExistsAndLoadBean(t, &User{ID: 5}).(*User)
+	assert.Equal(t, "Synced Name", user.FullName)
+	assert.Equal(t, "synced@example.com", user.Email)
+	assert.True(t, user.IsAdmin)
+	AssertExistsAndLoadBean(t, &TeamUser{TeamID: 2, UID: user.ID})
+
+	// the email of another user is not taken over and a single group is accepted
+	gothUser.Email = "user2@example.com"
+	gothUser.RawData = map[string]interface{}{"groups": "writers"}
+	assert.NoError(t, SyncOAuth2User(source, user, gothUser))
+	user = AssertExistsAndLoadBean(t, &User{ID: 5}).(*User)
+	assert.Equal(t, "synced@example.com", user.Email)
+	assert.False(t, user.IsAdmin)
+
+	// other providers are not synchronized
+	source.OAuth2().Provider = "github"
+	gothUser.Name = "Other Name"
+	assert.NoError(t, SyncOAuth2User(source, user, gothUser))
+	AssertExistsAndLoadBean(t, &User{ID: 5, FullName: "Synced Name"})
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/masoodkamyab/gitea/modules/log"
 	"github.com/masoodkamyab/gitea/modules/structs"
+	"github.com/masoodkamyab/gitea/modules/util"
 
 	"github.com/Unknwon/com"
 	"github.com/go-xorm/xorm"
@@ -257,6 +258,14 @@ func deleteOrg(e *xorm.Session, u *User) error {
 		return fmt.Errorf("deleteBeans: %v", err)
 	}
 
+	if err = deleteProjects(e, builder.Eq{"owner_id": u.ID, "type": ProjectTypeOrganization}); err != nil {
+		return fmt.Errorf("deleteProjects: %v", err)
+	}
+
+	if err = deleteIssueFields(e, builder.Eq{"owner_id": u.ID}); err != nil {
+		return fmt.Errorf("deleteIssueFields: %v", err)
+	}
+
 	if _, err = e.ID(u.ID).Delete(new(User)); err != nil {
 		return fmt.Errorf("Delete: %v", err)
 	}
@@ -295,6 +304,8 @@ type OrgUser struct {
 	UID      int64 `xorm:"INDEX UNIQUE(s)"`
 	OrgID    int64 `xorm:"INDEX UNIQUE(s)"`
 	IsPublic bool  `xorm:"INDEX"`
+	// CreatedUnix is when the user joined, 0 for members who joined before it was recorded
+	CreatedUnix util.TimeStamp `xorm:"NOT NULL DEFAULT 0 created"`
 }
 
 func isOrganizationOwner(e Engine, orgID, uid int64) (bool, error) {
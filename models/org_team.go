@@ -862,5 +862,12 @@ func UpdateTeamUnits(team *Team, units []TeamUnit) (err error) {
 		return err
 	}
 
-	return sess.Commit()
+	if err = sess.Commit(); err != nil {
+		return err
+	}
+	team.Units = make([]*TeamUnit, len(units))
+	for i := range units {
+		team.Units[i] = &units[i]
+	}
+	return nil
 }
@@ -0,0 +1,142 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/masoodkamyab/gitea/modules/log"
+)
+
+// GroupTeamMap maps the groups of an external login source to the teams of organizations,
+// like {"group": {"org": ["team"]}}
+type GroupTeamMap map[string]map[string][]string
+
+// ParseGroupTeamMap parses the JSON of a group to team mapping, an empty string is an empty mapping
+func ParseGroupTeamMap(mapping string) (GroupTeamMap, error) {
+	groupTeamMap := make(GroupTeamMap)
+	if len(mapping) == 0 {
+		return groupTeamMap, nil
+	}
+	if err := json.Unmarshal([]byte(mapping), &groupTeamMap); err != nil {
+		return nil, fmt.Errorf("invalid group to team mapping: %v", err)
+	}
+	return groupTeamMap, nil
+}
+
+// orgTeamName identifies a team by the name of its organization and its own name
+type orgTeamName struct {
+	Org  string
+	Team string
+}
+
+// teams returns the teams mapped from the groups
+func (m GroupTeamMap) teams(groups []string) map[orgTeamName]bool {
+	teams := make(map[orgTeamName]bool)
+	for _, group := range groups {
+		for org, names := range m[group] {
+			for _, name := range names {
+				teams[orgTeamName{org, name}] = true
+			}
+		}
+	}
+	return teams
+}
+
+// allTeams returns all mapped teams
+func (m GroupTeamMap) allTeams() map[orgTeamName]bool {
+	groups := make([]string, 0, len(m))
+	for group := range m {
+		groups = append(groups, group)
+	}
+	return m.teams(groups)
+}
+
+// TeamMembershipChange is a team membership of a user added or removed by a group to team mapping
+type TeamMembershipChange struct {
+	User   *User
+	Org    *User
+	Team   *Team
+	Remove bool
+	// UserMissing is set if the user is missing from the source and gets deactivated
+	UserMissing bool
+}
+
+// GroupTeamChanges returns the team memberships of the user to add for its groups. With removal
+// it also returns the mapped team memberships of the groups it is not a member of anymore to remove.
+// Missing organizations and teams are skipped.
+func GroupTeamChanges(user *User, groups []string, groupTeamMap GroupTeamMap, removal bool) ([]*TeamMembershipChange, error) {
+	member := groupTeamMap.teams(groups)
+	orgs := make(map[string]*User)
+	changes := make([]*TeamMembershipChange, 0, len(member))
+	for name := range groupTeamMap.allTeams() {
+		if !member[name] && !removal {
+			continue
+		}
+
+		org, ok := orgs[name.Org]
+		if !ok {
+			var err error
+			if org, err = GetOrgByName(name.Org); err != nil && !IsErrOrgNotExist(err) {
+				return nil, err
+			}
+			orgs[name.Org] = org
+		}
+		if org == nil {
+			log.Warn("GroupTeamChanges: organization %s does not exist", name.Org)
+			continue
+		}
+		team, err := org.GetTeam(name.Team)
+		if err == ErrTeamNotExist {
+			log.Warn("GroupTeamChanges: team %s of organization %s does not exist", name.Team, name.Org)
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		isMember := false
+		if user.ID > 0 {
+			if isMember, err = IsTeamMember(org.ID, team.ID, user.ID); err != nil {
+				return nil, err
+			}
+		}
+		if member[name] != isMember {
+			changes = append(changes, &TeamMembershipChange{User: user, Org: org, Team: team, Remove: isMember})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Org.LowerName != changes[j].Org.LowerName {
+			return changes[i].Org.LowerName < changes[j].Org.LowerName
+		}
+		return changes[i].Team.LowerName < changes[j].Team.LowerName
+	})
+	return changes, nil
+}
+
+// SyncGroupsToTeams adds the user to the teams mapped from its groups. With removal the user
+// is also removed from the mapped teams of the groups it is not a member of anymore.
+// Missing organizations and teams are skipped.
+func SyncGroupsToTeams(user *User, groups []string, groupTeamMap GroupTeamMap, removal bool) error {
+	changes, err := GroupTeamChanges(user, groups, groupTeamMap, removal)
+	if err != nil {
+		return err
+	}
+	for _, change := range changes {
+		if !change.Remove {
+			if err = AddTeamMember(change.Team, user.ID); err != nil {
+				return err
+			}
+		} else if err = RemoveTeamMember(change.Team, user.ID); err != nil {
+			if !IsErrLastOrgOwner(err) {
+				return err
+			}
+			log.Warn("SyncGroupsToTeams: %s is the last owner of organization %s", user.Name, change.Org.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGroupTeamMap(t *testing.T) {
+	m, err := ParseGroupTeamMap("")
+	assert.NoError(t, err)
+	assert.Empty(t, m)
+
+	m, err = ParseGroupTeamMap(`{"devs": {"user3": ["Owners", "team1"]}}`)
+	assert.NoError(t, err)
+	assert.Equal(t, GroupTeamMap{"devs": {"user3": {"Owners", "team1"}}}, m)
+
+	_, err = ParseGroupTeamMap(`{"devs": ["user3"]}`)
+	assert.Error(t, err)
+	_, err = ParseGroupTeamMap(`not json`)
+	assert.Error(t, err)
+}
+
+func TestSyncGroupsToTeams(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	groupTeamMap := GroupTeamMap{
+		"owners":  {"user3": {"Owners"}},
+		"writers": {"user3": {"team1"}},
+		"missing": {"nonexistent": {"team"}, "user3": {"nonexistent"}},
+	}
+	user := AssertExistsAndLoadBean(t, &User{ID: 4}).(*User)
+
+	assert.NoError(t, SyncGroupsToTeams(user, []string{"owners", "missing"}, groupTeamMap, false))
+	AssertExistsAndLoadBean(t, &TeamUser{TeamID: 1, UID: user.ID})
+	AssertExistsAndLoadBean(t, &TeamUser{TeamID: 2, UID: user.ID})
+
+	assert.NoError(t, SyncGroupsToTeams(user, []string{"owners"}, groupTeamMap, true))
+	AssertExistsAndLoadBean(t, &TeamUser{TeamID: 1, UID: user.ID})
+	AssertNotExistsBean(t, &TeamUser{TeamID: 2, UID: user.ID})
+
+	assert.NoError(t, SyncGroupsToTeams(user, nil, groupTeamMap, true))
+	AssertNotExistsBean(t, &TeamUser{TeamID: 1, UID: user.ID})
+	AssertNotExistsBean(t, &TeamUser{TeamID: 2, UID: user.ID})
+	CheckConsistencyFor(t, &Team{})
+}
+
+func TestGroupTeamChanges(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	groupTeamMap := GroupTeamMap{
+		"owners":  {"user3": {"Owners"}},
+		"writers": {"user3": {"team1"}},
+	}
+	// user2 is a member of both teams
+	user := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+
+	changes, err := GroupTeamChanges(user, []string{"writers"}, groupTeamMap, false)
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+
+	changes, err = GroupTeamChanges(user, []string{"writers"}, groupTeamMap, true)
+	assert.NoError(t, err)
+	if assert.Len(t, changes, 1) {
+		assert.EqualValues(t, 1, changes[0].Team.ID)
+		assert.Equal(t, "user3", changes[0].Org.Name)
+		assert.True(t, changes[0].Remove)
+	}
+
+	// new users are not a member of any team
+	changes, err = GroupTeamChanges(&User{Name: "new"}, []string{"owners", "writers"}, groupTeamMap, true)
+	assert.NoError(t, err)
+	if assert.Len(t, changes, 2) {
+		assert.EqualValues(t, 1, changes[0].Team.ID)
+		assert.EqualValues(t, 2, changes[1].Team.ID)
+		assert.False(t, changes[0].Remove)
+		assert.False(t, changes[1].Remove)
+	}
+
+	// the changes are not applied
+	AssertExistsAndLoadBean(t, &TeamUser{TeamID: 1, UID: user.ID})
+}
@@ -0,0 +1,280 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+
+	"github.com/masoodkamyab/gitea/modules/setting"
+	api "github.com/masoodkamyab/gitea/modules/structs"
+	"github.com/masoodkamyab/gitea/modules/util"
+
+	"xorm.io/builder"
+)
+
+// ProjectType is the owner kind of a project
+type ProjectType uint8
+
+const (
+	// ProjectTypeRepository is a project holding the issues of a single repository
+	ProjectTypeRepository ProjectType = iota + 1
+	// ProjectTypeOrganization is a project holding the issues of any repository of an organization
+	ProjectTypeOrganization
+)
+
+// Project represents a kanban board of a repository or an organization
+type Project struct {
+	ID          int64       `xorm:"pk autoincr"`
+	Title       string      `xorm:"INDEX NOT NULL"`
+	Description string      `xorm:"TEXT"`
+	RepoID      int64       `xorm:"INDEX"`
+	Repo        *Repository `xorm:"-"`
+	OwnerID     int64       `xorm:"INDEX"`
+	Owner       *User       `xorm:"-"`
+	CreatorID   int64       `xorm:"NOT NULL"`
+	Creator     *User       `xorm:"-"`
+	IsClosed    bool        `xorm:"INDEX"`
+	Type        ProjectType
+
+	RenderedContent string `xorm:"-"`
+
+	CreatedUnix    util.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix    util.TimeStamp `xorm:"INDEX updated"`
+	ClosedDateUnix util.TimeStamp
+}
+
+func (p *Project) loadAttributes(e Engine) (err error) {
+	if p.Type == ProjectTypeRepository && p.Repo == nil {
+		if p.Repo, err = getRepositoryByID(e, p.RepoID); err != nil {
+			return fmt.Errorf("getRepositoryByID [%d]: %v", p.RepoID, err)
+		}
+	}
+	if p.Type == ProjectTypeOrganization && p.Owner == nil {
+		if p.Owner, err = getUserByID(e, p.OwnerID); err != nil {
+			return fmt.Errorf("getUserByID [%d]: %v", p.OwnerID, err)
+		}
+	}
+	if p.Creator == nil {
+		p.Creator, err = getUserByID(e, p.CreatorID)
+		if IsErrUserNotExist(err) {
+			p.CreatorID = -1
+			p.Creator = NewGhostUser()
+		} else if err != nil {
+			return fmt.Errorf("getUserByID [%d]: %v", p.CreatorID, err)
+		}
+	}
+	return nil
+}
+
+// LoadAttributes loads the repository or organization and the creator of the project
+func (p *Project) LoadAttributes() error {
+	return p.loadAttributes(x)
+}
+
+// State returns string representation of project status.
+func (p *Project) State() api.StateType {
+	if p.IsClosed {
+		return api.StateClosed
+	}
+	return api.StateOpen
+}
+
+// Link returns the relative URL of the project
+func (p *Project) Link() string {
+	if p.Type == ProjectTypeOrganization {
+		return fmt.Sprintf("%s/org/%s/projects/%d", setting.AppSubURL, p.Owner.Name, p.ID)
+	}
+	return fmt.Sprintf("%s/projects/%d", p.Repo.Link(), p.ID)
+}
+
+// APIFormat returns this Project in API format. LoadAttributes must have been called before.
+func (p *Project) APIFormat() *api.Project {
+	apiProject := &api.Project{
+		ID:          p.ID,
+		Title:       p.Title,
+		Description: p.Description,
+		State:       p.State(),
+		RepoID:      p.RepoID,
+		OwnerID:     p.OwnerID,
+		Creator:     p.Creator.APIFormat(),
+		Created:     p.CreatedUnix.AsTime(),
+		Updated:     p.UpdatedUnix.AsTime(),
+	}
+	if p.IsClosed {
+		apiProject.Closed = p.ClosedDateUnix.AsTimePtr()
+	}
+	return apiProject
+}
+
+func (p *Project) userAccessMode(e Engine, user *User) (AccessMode, error) {
+	if err := p.loadAttributes(e); err != nil {
+		return AccessModeNone, err
+	}
+	if p.Type == ProjectTypeOrganization {
+		return orgProjectsAccessMode(e, p.Owner, user)
+	}
+
+	perm, err := getUserRepoPermission(e, p.Repo, user)
+	if err != nil {
+		return AccessModeNone, err
+	}
+	mode := perm.UnitAccessMode(UnitTypeProjects)
+	if p.Repo.IsArchived && mode > AccessModeRead {
+		mode = AccessModeRead
+	}
+	return mode, nil
+}
+
+// UserAccessMode returns the access mode of user to the project
+func (p *Project) UserAccessMode(user *User) (AccessMode, error) {
+	return p.userAccessMode(x, user)
+}
+
+func orgProjectsAccessMode(e Engine, org, user *User) (AccessMode, error) {
+	if user == nil {
+		return AccessModeNone, nil
+	}
+	if user.IsAdmin {
+		return AccessModeOwner, nil
+	}
+	isOwner, err := isOrganizationOwner(e, org.ID, user.ID)
+	if err != nil {
+		return AccessModeNone, err
+	} else if isOwner {
+		return AccessModeOwner, nil
+	}
+
+	teams, err := org.getUserTeams(e, user.ID)
+	if err != nil {
+		return AccessModeNone, err
+	}
+	mode := AccessModeNone
+	for _, team := range teams {
+		if team.Authorize > mode && team.unitEnabled(e, UnitTypeProjects) {
+			mode = team.Authorize
+		}
+	}
+	return mode, nil
+}
+
+// OrgProjectsAccessMode returns the access mode of user to the projects of an organization.
+// Owners manage the projects, other members need a team with the projects unit.
+func OrgProjectsAccessMode(org, user *User) (AccessMode, error) {
+	return orgProjectsAccessMode(x, org, user)
+}
+
+// NewProject creates a new project
+func NewProject(p *Project) error {
+	if p.Type != ProjectTypeRepository && p.Type != ProjectTypeOrganization {
+		return fmt.Errorf("invalid project type: %d", p.Type)
+	}
+	_, err := x.Insert(p)
+	return err
+}
+
+func getProjectByID(e Engine, id int64) (*Project, error) {
+	p := new(Project)
+	has, err := e.ID(id).Get(p)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrProjectNotExist{ID: id}
+	}
+	return p, nil
+}
+
+// GetProjectByID returns the project by given ID
+func GetProjectByID(id int64) (*Project, error) {
+	return getProjectByID(x, id)
+}
+
+// ProjectSearchOptions holds the options to list the projects of a repository or an organization
+type ProjectSearchOptions struct {
+	RepoID   int64
+	OwnerID  int64
+	IsClosed util.OptionalBool
+	Page     int
+}
+
+func (opts *ProjectSearchOptions) toConds() builder.Cond {
+	cond := builder.NewCond()
+	if opts.RepoID > 0 {
+		cond = cond.And(builder.Eq{"repo_id": opts.RepoID, "type": ProjectTypeRepository})
+	} else {
+		cond = cond.And(builder.Eq{"owner_id": opts.OwnerID, "type": ProjectTypeOrganization})
+	}
+	if !opts.IsClosed.IsNone() {
+		cond = cond.And(builder.Eq{"is_closed": opts.IsClosed.IsTrue()})
+	}
+	return cond
+}
+
+// GetProjects returns a page of the projects matching opts and the total number of matches
+func GetProjects(opts *ProjectSearchOptions) ([]*Project, int64, error) {
+	cond := opts.toConds()
+	count, err := x.Where(cond).Count(new(Project))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sess := x.Where(cond).Desc("id")
+	if opts.Page > 0 {
+		sess.Limit(setting.UI.IssuePagingNum, (opts.Page-1)*setting.UI.IssuePagingNum)
+	}
+	projects := make([]*Project, 0, setting.UI.IssuePagingNum)
+	return projects, count, sess.Find(&projects)
+}
+
+// UpdateProject updates the title and the description of a project
+func UpdateProject(p *Project) error {
+	_, err := x.ID(p.ID).Cols("title", "description").Update(p)
+	return err
+}
+
+// ChangeProjectStatus opens or closes a project
+func ChangeProjectStatus(p *Project, isClosed bool) error {
+	p.IsClosed = isClosed
+	if isClosed {
+		p.ClosedDateUnix = util.TimeStampNow()
+	}
+	_, err := x.ID(p.ID).Cols("is_closed", "closed_date_unix").Update(p)
+	return err
+}
+
+func deleteProjectByID(e Engine, id int64) error {
+	if _, err := e.Delete(&ProjectIssue{ProjectID: id}); err != nil {
+		return err
+	}
+	if _, err := e.Delete(&ProjectBoard{ProjectID: id}); err != nil {
+		return err
+	}
+	_, err := e.ID(id).Delete(new(Project))
+	return err
+}
+
+func deleteProjects(e Engine, cond builder.Cond) error {
+	projectIDs := builder.Select("id").From("project").Where(cond)
+	if _, err := e.In("project_id", projectIDs).Delete(new(ProjectIssue)); err != nil {
+		return err
+	}
+	if _, err := e.In("project_id", projectIDs).Delete(new(ProjectBoard)); err != nil {
+		return err
+	}
+	_, err := e.Where(cond).Delete(new(Project))
+	return err
+}
+
+// DeleteProjectByID deletes a project with its boards and cards
+func DeleteProjectByID(id int64) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+	if err := deleteProjectByID(sess, id); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
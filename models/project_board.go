@@ -0,0 +1,87 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	api "github.com/masoodkamyab/gitea/modules/structs"
+	"github.com/masoodkamyab/gitea/modules/util"
+)
+
+// ProjectBoard is a column of a project, issues which are on no board are uncategorized
+type ProjectBoard struct {
+	ID        int64  `xorm:"pk autoincr"`
+	ProjectID int64  `xorm:"INDEX NOT NULL"`
+	Title     string `xorm:"NOT NULL"`
+	Sorting   int
+	CreatorID int64 `xorm:"NOT NULL"`
+
+	Issues []*Issue `xorm:"-"`
+
+	CreatedUnix util.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix util.TimeStamp `xorm:"INDEX updated"`
+}
+
+// APIFormat returns this ProjectBoard in API format.
+func (b *ProjectBoard) APIFormat() *api.ProjectBoard {
+	return &api.ProjectBoard{
+		ID:        b.ID,
+		ProjectID: b.ProjectID,
+		Title:     b.Title,
+		Sorting:   b.Sorting,
+		Created:   b.CreatedUnix.AsTime(),
+		Updated:   b.UpdatedUnix.AsTime(),
+	}
+}
+
+// NewProjectBoard appends a board to a project
+func NewProjectBoard(b *ProjectBoard) error {
+	_, err := x.Insert(b)
+	return err
+}
+
+func getProjectBoard(e Engine, projectID, id int64) (*ProjectBoard, error) {
+	b := new(ProjectBoard)
+	has, err := e.Where("project_id = ? AND id = ?", projectID, id).Get(b)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrProjectBoardNotExist{ID: id, ProjectID: projectID}
+	}
+	return b, nil
+}
+
+// GetProjectBoard returns the board of a project by given ID
+func GetProjectBoard(projectID, id int64) (*ProjectBoard, error) {
+	return getProjectBoard(x, projectID, id)
+}
+
+// GetProjectBoards returns the boards of a project in display order
+func GetProjectBoards(projectID int64) ([]*ProjectBoard, error) {
+	boards := make([]*ProjectBoard, 0, 5)
+	return boards, x.Where("project_id = ?", projectID).Asc("sorting", "id").Find(&boards)
+}
+
+// UpdateProjectBoard updates the title and the position of a board
+func UpdateProjectBoard(b *ProjectBoard) error {
+	_, err := x.ID(b.ID).Cols("title", "sorting").Update(b)
+	return err
+}
+
+// DeleteProjectBoard deletes a board, its issues become uncategorized
+func DeleteProjectBoard(b *ProjectBoard) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if _, err := sess.Exec("UPDATE `project_issue` SET project_board_id = 0 WHERE project_board_id = ?", b.ID); err != nil {
+		return err
+	}
+	if _, err := sess.ID(b.ID).Delete(new(ProjectBoard)); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
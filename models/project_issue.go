@@ -0,0 +1,197 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+
+	api "github.com/masoodkamyab/gitea/modules/structs"
+)
+
+// ProjectIssue places an issue or a pull request on a board of a project
+type ProjectIssue struct {
+	ID             int64 `xorm:"pk autoincr"`
+	ProjectID      int64 `xorm:"UNIQUE(s) NOT NULL"`
+	IssueID        int64 `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	ProjectBoardID int64 `xorm:"INDEX"`
+	Sorting        int
+
+	Issue *Issue `xorm:"-"`
+}
+
+// APIFormat returns this ProjectIssue in API format. The issue must have been loaded before.
+func (pi *ProjectIssue) APIFormat() *api.ProjectIssue {
+	return &api.ProjectIssue{
+		ProjectID: pi.ProjectID,
+		BoardID:   pi.ProjectBoardID,
+		Sorting:   pi.Sorting,
+		Issue:     pi.Issue.APIFormat(),
+	}
+}
+
+// CanContainIssue returns whether issue may be put on the project, the repository of the issue
+// must have been loaded
+func (p *Project) CanContainIssue(issue *Issue) bool {
+	if p.Type == ProjectTypeOrganization {
+		return issue.Repo.OwnerID == p.OwnerID
+	}
+	return issue.RepoID == p.RepoID
+}
+
+func getProjectIssue(e Engine, projectID, issueID int64) (*ProjectIssue, error) {
+	pi := new(ProjectIssue)
+	has, err := e.Where("project_id = ? AND issue_id = ?", projectID, issueID).Get(pi)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrProjectIssueNotExist{ProjectID: projectID, IssueID: issueID}
+	}
+	return pi, nil
+}
+
+// GetProjectIssue returns the card of an issue on a project
+func GetProjectIssue(projectID, issueID int64) (*ProjectIssue, error) {
+	return getProjectIssue(x, projectID, issueID)
+}
+
+// GetProjectIssues returns the cards of a project in display order with their issues and repositories loaded
+func GetProjectIssues(projectID int64) ([]*ProjectIssue, error) {
+	pis := make([]*ProjectIssue, 0, 10)
+	if err := x.Where("project_id = ?", projectID).Asc("sorting", "id").Find(&pis); err != nil {
+		return nil, err
+	}
+
+	issueIDs := make([]int64, 0, len(pis))
+	for _, pi := range pis {
+		issueIDs = append(issueIDs, pi.IssueID)
+	}
+	issues, err := getIssuesByIDs(x, issueIDs)
+	if err != nil {
+		return nil, err
+	}
+	if err = IssueList(issues).LoadAttributes(); err != nil {
+		return nil, err
+	}
+	issueMap := make(map[int64]*Issue, len(issues))
+	for _, issue := range issues {
+		issueMap[issue.ID] = issue
+	}
+
+	loaded := pis[:0]
+	for _, pi := range pis {
+		if pi.Issue = issueMap[pi.IssueID]; pi.Issue != nil {
+			loaded = append(loaded, pi)
+		}
+	}
+	return loaded, nil
+}
+
+// FilterProjectIssuesByUser returns the cards of the issues user may read
+func FilterProjectIssuesByUser(pis []*ProjectIssue, user *User) ([]*ProjectIssue, error) {
+	perms := make(map[int64]Permission)
+	filtered := make([]*ProjectIssue, 0, len(pis))
+	for _, pi := range pis {
+		perm, ok := perms[pi.Issue.RepoID]
+		if !ok {
+			var err error
+			if perm, err = GetUserRepoPermission(pi.Issue.Repo, user); err != nil {
+				return nil, err
+			}
+			perms[pi.Issue.RepoID] = perm
+		}
+		if perm.CanReadIssuesOrPulls(pi.Issue.IsPull) {
+			filtered = append(filtered, pi)
+		}
+	}
+	return filtered, nil
+}
+
+func nextProjectIssueSorting(e Engine, projectID, boardID int64) (int, error) {
+	var max int
+	if _, err := e.Table("project_issue").Where("project_id = ? AND project_board_id = ?", projectID, boardID).
+		Select("COALESCE(MAX(sorting), -1)").Get(&max); err != nil {
+		return 0, err
+	}
+	return max + 1, nil
+}
+
+// AddIssueToProject puts issue at the bottom of a board of a project, boardID 0 stands for uncategorized
+func AddIssueToProject(p *Project, issue *Issue, boardID int64) (*ProjectIssue, error) {
+	if err := issue.LoadRepo(); err != nil {
+		return nil, err
+	}
+	if !p.CanContainIssue(issue) {
+		return nil, ErrProjectIssueNotAllowed{ProjectID: p.ID, IssueID: issue.ID}
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return nil, err
+	}
+
+	if _, err := getProjectIssue(sess, p.ID, issue.ID); err == nil {
+		return nil, ErrProjectIssueAlreadyExist{ProjectID: p.ID, IssueID: issue.ID}
+	} else if !IsErrProjectIssueNotExist(err) {
+		return nil, err
+	}
+	if boardID != 0 {
+		if _, err := getProjectBoard(sess, p.ID, boardID); err != nil {
+			return nil, err
+		}
+	}
+
+	sorting, err := nextProjectIssueSorting(sess, p.ID, boardID)
+	if err != nil {
+		return nil, fmt.Errorf("nextProjectIssueSorting: %v", err)
+	}
+	pi := &ProjectIssue{
+		ProjectID:      p.ID,
+		IssueID:        issue.ID,
+		ProjectBoardID: boardID,
+		Sorting:        sorting,
+		Issue:          issue,
+	}
+	if _, err = sess.Insert(pi); err != nil {
+		return nil, err
+	}
+	return pi, sess.Commit()
+}
+
+// MoveProjectIssue moves a card to position sorting of a board of its project,
+// the cards below that position move down by one
+func MoveProjectIssue(pi *ProjectIssue, boardID int64, sorting int) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if boardID != 0 {
+		if _, err := getProjectBoard(sess, pi.ProjectID, boardID); err != nil {
+			return err
+		}
+	}
+	if sorting < 0 {
+		sorting = 0
+	}
+
+	if _, err := sess.Exec("UPDATE `project_issue` SET sorting = sorting + 1 WHERE project_id = ? AND project_board_id = ? AND sorting >= ? AND id <> ?",
+		pi.ProjectID, boardID, sorting, pi.ID); err != nil {
+		return err
+	}
+	pi.ProjectBoardID = boardID
+	pi.Sorting = sorting
+	if _, err := sess.ID(pi.ID).Cols("project_board_id", "sorting").Update(pi); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// RemoveIssueFromProject takes the card of an issue off a project
+func RemoveIssueFromProject(projectID, issueID int64) error {
+	_, err := x.Delete(&ProjectIssue{ProjectID: projectID, IssueID: issueID})
+	return err
+}
@@ -0,0 +1,154 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	api "github.com/masoodkamyab/gitea/modules/structs"
+	"github.com/masoodkamyab/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProject_State(t *testing.T) {
+	assert.Equal(t, api.StateOpen, (&Project{IsClosed: false}).State())
+	assert.Equal(t, api.StateClosed, (&Project{IsClosed: true}).State())
+}
+
+func TestNewProject(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	p := &Project{
+		Title:     "projectTitle",
+		RepoID:    1,
+		CreatorID: 2,
+		Type:      ProjectTypeRepository,
+	}
+	assert.NoError(t, NewProject(p))
+	AssertExistsAndLoadBean(t, p)
+
+	assert.Error(t, NewProject(&Project{Title: "invalid", CreatorID: 2}))
+}
+
+func TestGetProjectByID(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	p, err := GetProjectByID(1)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, p.RepoID)
+	assert.Equal(t, ProjectTypeRepository, p.Type)
+
+	_, err = GetProjectByID(NonexistentID)
+	assert.True(t, IsErrProjectNotExist(err))
+}
+
+func TestGetProjects(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	projects, count, err := GetProjects(&ProjectSearchOptions{RepoID: 1})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+	if assert.Len(t, projects, 1) {
+		assert.EqualValues(t, 1, projects[0].ID)
+	}
+
+	projects, count, err = GetProjects(&ProjectSearchOptions{OwnerID: 3})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+	if assert.Len(t, projects, 1) {
+		assert.EqualValues(t, 2, projects[0].ID)
+	}
+
+	projects, count, err = GetProjects(&ProjectSearchOptions{RepoID: 1, IsClosed: util.OptionalBoolTrue})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, count)
+	assert.Len(t, projects, 0)
+}
+
+func TestOrgProjectsAccessMode(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	org := AssertExistsAndLoadBean(t, &User{ID: 3}).(*User)
+
+	test := func(user *User, expected AccessMode) {
+		mode, err := OrgProjectsAccessMode(org, user)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, mode)
+	}
+	test(nil, AccessModeNone)
+	test(AssertExistsAndLoadBean(t, &User{ID: 1}).(*User), AccessModeOwner)
+	test(AssertExistsAndLoadBean(t, &User{ID: 2}).(*User), AccessModeOwner)
+	// member of a team without the projects unit
+	test(AssertExistsAndLoadBean(t, &User{ID: 4}).(*User), AccessModeNone)
+	test(AssertExistsAndLoadBean(t, &User{ID: 5}).(*User), AccessModeNone)
+}
+
+func TestGetProjectIssues(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	pis, err := GetProjectIssues(1)
+	assert.NoError(t, err)
+	if assert.Len(t, pis, 2) {
+		assert.EqualValues(t, 1, pis[0].Issue.ID)
+		assert.EqualValues(t, 2, pis[1].Issue.ID)
+		assert.NotNil(t, pis[0].Issue.Repo)
+	}
+}
+
+func TestAddIssueToProject(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	p := AssertExistsAndLoadBean(t, &Project{ID: 1}).(*Project)
+
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 3}).(*Issue)
+	pi, err := AddIssueToProject(p, issue, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, pi.Sorting)
+	AssertExistsAndLoadBean(t, &ProjectIssue{ProjectID: 1, IssueID: 3, ProjectBoardID: 1})
+
+	_, err = AddIssueToProject(p, issue, 2)
+	assert.True(t, IsErrProjectIssueAlreadyExist(err))
+
+	// issue of another repository
+	issue = AssertExistsAndLoadBean(t, &Issue{ID: 6}).(*Issue)
+	_, err = AddIssueToProject(p, issue, 0)
+	assert.True(t, IsErrProjectIssueNotAllowed(err))
+
+	issue = AssertExistsAndLoadBean(t, &Issue{ID: 5}).(*Issue)
+	_, err = AddIssueToProject(p, issue, NonexistentID)
+	assert.True(t, IsErrProjectBoardNotExist(err))
+}
+
+func TestMoveProjectIssue(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	pi := AssertExistsAndLoadBean(t, &ProjectIssue{ID: 2}).(*ProjectIssue)
+	assert.NoError(t, MoveProjectIssue(pi, 1, 0))
+	AssertExistsAndLoadBean(t, &ProjectIssue{ID: 2, Sorting: 0})
+	AssertExistsAndLoadBean(t, &ProjectIssue{ID: 1, Sorting: 1})
+
+	assert.NoError(t, MoveProjectIssue(pi, 2, 0))
+	AssertExistsAndLoadBean(t, &ProjectIssue{ID: 2, ProjectBoardID: 2})
+
+	assert.True(t, IsErrProjectBoardNotExist(MoveProjectIssue(pi, NonexistentID, 0)))
+}
+
+func TestDeleteProjectBoard(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	b := AssertExistsAndLoadBean(t, &ProjectBoard{ID: 1}).(*ProjectBoard)
+	assert.NoError(t, DeleteProjectBoard(b))
+	AssertNotExistsBean(t, &ProjectBoard{ID: 1})
+	pi := AssertExistsAndLoadBean(t, &ProjectIssue{ID: 1}).(*ProjectIssue)
+	assert.EqualValues(t, 0, pi.ProjectBoardID)
+}
+
+func TestDeleteProjectByID(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	assert.NoError(t, DeleteProjectByID(1))
+	AssertNotExistsBean(t, &Project{ID: 1})
+	AssertNotExistsBean(t, &ProjectBoard{ProjectID: 1})
+	AssertNotExistsBean(t, &ProjectIssue{ProjectID: 1})
+	AssertExistsAndLoadBean(t, &Issue{ID: 1})
+}
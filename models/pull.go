@@ -71,6 +71,7 @@ type PullRequest struct {
 	BaseBranch      string
 	ProtectedBranch *ProtectedBranch `xorm:"-"`
 	MergeBase       string           `xorm:"VARCHAR(40)"`
+	IsDraft         bool             `xorm:"NOT NULL DEFAULT false"`
 
 	HasMerged      bool           `xorm:"INDEX"`
 	MergedCommitID string         `xorm:"VARCHAR(40)"`
@@ -138,6 +139,9 @@ func (pr *PullRequest) GetDefaultMergeMessage() string {
 			return ""
 		}
 	}
+	if cfg := pr.getPullRequestsConfig(); cfg != nil && len(cfg.MergeMessageTemplate) > 0 {
+		return pr.expandMessageTemplate(cfg.MergeMessageTemplate)
+	}
 	return fmt.Sprintf("Merge branch '%s' of %s/%s into %s", pr.HeadBranch, pr.HeadUserName, pr.HeadRepo.Name, pr.BaseBranch)
 }
 
@@ -147,9 +151,115 @@ func (pr *PullRequest) GetDefaultSquashMessage() string {
 		log.Error("LoadIssue: %v", err)
 		return ""
 	}
+	if cfg := pr.getPullRequestsConfig(); cfg != nil && len(cfg.SquashMessageTemplate) > 0 {
+		return pr.expandMessageTemplate(cfg.SquashMessageTemplate)
+	}
 	return fmt.Sprintf("%s (#%d)", pr.Issue.Title, pr.Issue.Index)
 }
 
+// getPullRequestsConfig returns the pull request settings of the base repository or nil
+func (pr *PullRequest) getPullRequestsConfig() *PullRequestsConfig {
+	if err := pr.GetBaseRepo(); err != nil {
+		log.Error("GetBaseRepo: %v", err)
+		return nil
+	}
+	prUnit, err := pr.BaseRepo.GetUnit(UnitTypePullRequests)
+	if err != nil {
+		log.Error("GetUnit: %v", err)
+		return nil
+	}
+	return prUnit.PullRequestsConfig()
+}
+
+// expandMessageTemplate replaces the $Placeholders of a merge or squash message template
+func (pr *PullRequest) expandMessageTemplate(tmpl string) string {
+	if err := pr.LoadIssue(); err != nil {
+		log.Error("LoadIssue: %v", err)
+		return ""
+	}
+	if err := pr.Issue.LoadPoster(); err != nil {
+		log.Error("LoadPoster: %v", err)
+		return ""
+	}
+
+	return strings.TrimSpace(os.Expand(tmpl, func(name string) string {
+		switch name {
+		case "PullRequestTitle":
+			return pr.Issue.Title
+		case "PullRequestIndex":
+			return strconv.FormatInt(pr.Index, 10)
+		case "PullRequestReference":
+			return fmt.Sprintf("#%d", pr.Index)
+		case "PullRequestDescription":
+			return pr.Issue.Content
+		case "PullRequestPosterName":
+			return pr.Issue.Poster.Name
+		case "HeadBranch":
+			return pr.HeadBranch
+		case "BaseBranch":
+			return pr.BaseBranch
+		case "Reviewers":
+			return strings.Join(pr.getApproverNames(), ", ")
+		case "CoAuthors":
+			return strings.Join(pr.getCoAuthorTrailers(), "\n")
+		}
+		return "$" + name
+	}))
+}
+
+// getApproverNames returns the names of the users whose latest review approves the pull request
+func (pr *PullRequest) getApproverNames() []string {
+	reviewers, err := GetReviewersByPullID(pr.IssueID)
+	if err != nil {
+		log.Error("GetReviewersByPullID: %v", err)
+		return nil
+	}
+	names := make([]string, 0, len(reviewers))
+	for _, reviewer := range reviewers {
+		if reviewer.Type == ReviewTypeApprove {
+			names = append(names, reviewer.Name)
+		}
+	}
+	return names
+}
+
+// getCoAuthorTrailers returns a Co-authored-by trailer for every author of the commits
+// of the pull request except its poster
+func (pr *PullRequest) getCoAuthorTrailers() []string {
+	if err := pr.GetBaseRepo(); err != nil {
+		log.Error("GetBaseRepo: %v", err)
+		return nil
+	}
+	gitRepo, err := git.OpenRepository(pr.BaseRepo.RepoPath())
+	if err != nil {
+		log.Error("OpenRepository: %v", err)
+		return nil
+	}
+	headCommitID, err := gitRepo.GetRefCommitID(pr.GetGitRefName())
+	if err != nil {
+		log.Error("GetRefCommitID: %v", err)
+		return nil
+	}
+	commits, err := gitRepo.CommitsBetweenIDs(headCommitID, pr.MergeBase)
+	if err != nil {
+		log.Error("CommitsBetweenIDs: %v", err)
+		return nil
+	}
+
+	seen := map[string]bool{strings.ToLower(pr.Issue.Poster.Email): true}
+	trailers := make([]string, 0, commits.Len())
+	// The list is newest first, credit the authors in the order of their first commit
+	for e := commits.Back(); e != nil; e = e.Prev() {
+		author := e.Value.(*git.Commit).Author
+		if author == nil || seen[strings.ToLower(author.Email)] {
+			continue
+		}
+		seen[strings.ToLower(author.Email)] = true
+		trailers = append(trailers, fmt.Sprintf("Co-authored-by: %s <%s>", author.Name, author.Email))
+	}
+	return trailers
+}
+
 // GetGitRefName returns git ref for hidden pull request branch
 func (pr *PullRequest) GetGitRefName() string {
 	return fmt.Sprintf("refs/pull/%d/head", pr.Index)
@@ -212,6 +322,7 @@ func (pr *PullRequest) apiFormat(e Engine) *api.PullRequest {
 		DiffURL:   pr.Issue.DiffURL(),
 		PatchURL:  pr.Issue.PatchURL(),
 		HasMerged: pr.HasMerged,
+		IsDraft:   pr.IsDraft,
 		MergeBase: pr.MergeBase,
 		Deadline:  apiIssue.Deadline,
 		Created:   pr.Issue.CreatedUnix.AsTimePtr(),
@@ -365,6 +476,8 @@ const (
 	MergeStyleRebaseMerge MergeStyle = "rebase-merge"
 	// MergeStyleSquash squash commits into single commit before merging
 	MergeStyleSquash MergeStyle = "squash"
+	// MergeStyleFastForwardOnly fast-forward the base branch, refuse if that is not possible
+	MergeStyleFastForwardOnly MergeStyle = "fast-forward-only"
 )
 
 // CheckUserAllowedToMerge checks whether the user is allowed to merge
@@ -392,6 +505,41 @@ func (pr *PullRequest) CheckUserAllowedToMerge(doer *User) (err error) {
 	return nil
 }
 
+// CheckUserAllowedToUpdate checks whether the user is allowed to update the head branch
+// with the changes of the base branch
+func (pr *PullRequest) CheckUserAllowedToUpdate(doer *User) (err error) {
+	if doer == nil {
+		return ErrNotAllowedToUpdate{
+			"Not signed in",
+		}
+	}
+
+	if err = pr.GetHeadRepo(); err != nil {
+		return fmt.Errorf("GetHeadRepo: %v", err)
+	} else if pr.HeadRepo == nil {
+		return ErrPullRequestHeadRepoMissing{pr.ID, pr.HeadRepoID}
+	}
+
+	perm, err := GetUserRepoPermission(pr.HeadRepo, doer)
+	if err != nil {
+		return fmt.Errorf("GetUserRepoPermission: %v", err)
+	} else if !perm.CanWrite(UnitTypeCode) {
+		return ErrNotAllowedToUpdate{
+			"No write access to the head repository",
+		}
+	}
+
+	if protected, err := pr.HeadRepo.IsProtectedBranchForPush(pr.HeadBranch, doer); err != nil {
+		return fmt.Errorf("IsProtectedBranchForPush: %v", err)
+	} else if protected {
+		return ErrNotAllowedToUpdate{
+			"The head branch is protected",
+		}
+	}
+
+	return nil
+}
+
 // SetMerged sets a pull request to merged and closes the corresponding issue
 func (pr *PullRequest) SetMerged() (err error) {
 	if pr.HasMerged {
@@ -720,6 +868,7 @@ type PullRequestsOptions struct {
 	SortType    string
 	Labels      []string
 	MilestoneID int64
+	IsDraft     util.OptionalBool
 }
 
 func listPullRequestStatement(baseRepoID int64, opts *PullRequestsOptions) (*xorm.Session, error) {
@@ -742,6 +891,10 @@ func listPullRequestStatement(baseRepoID int64, opts *PullRequestsOptions) (*xor
 		sess.And("issue.milestone_id=?", opts.MilestoneID)
 	}
 
+	if !opts.IsDraft.IsNone() {
+		sess.And("pull_request.is_draft=?", opts.IsDraft.IsTrue())
+	}
+
 	return sess, nil
 }
 
@@ -1176,8 +1329,12 @@ func (pr *PullRequest) checkAndUpdateStatus() {
 	}
 }
 
-// IsWorkInProgress determine if the Pull Request is a Work In Progress by its title
+// IsWorkInProgress determine if the Pull Request is a Work In Progress by its draft flag or its title
 func (pr *PullRequest) IsWorkInProgress() bool {
+	if pr.IsDraft {
+		return true
+	}
+
 	if err := pr.LoadIssue(); err != nil {
 		log.Error("LoadIssue: %v", err)
 		return false
@@ -1191,6 +1348,66 @@ func (pr *PullRequest) IsWorkInProgress() bool {
 	return false
 }
 
+// SetDraft marks the pull request as a draft or as ready for review
+func (pr *PullRequest) SetDraft(doer *User, isDraft bool) (err error) {
+	if pr.IsDraft == isDraft {
+		return nil
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	if err = pr.loadIssue(sess); err != nil {
+		return err
+	}
+	if err = pr.Issue.loadRepo(sess); err != nil {
+		return err
+	}
+
+	pr.IsDraft = isDraft
+	if _, err = sess.ID(pr.ID).Cols("is_draft").Update(pr); err != nil {
+		return fmt.Errorf("update is_draft: %v", err)
+	}
+
+	commentType := CommentTypePullReadyForReview
+	if isDraft {
+		commentType = CommentTypePullConvertToDraft
+	}
+	if _, err = createComment(sess, &CreateCommentOptions{
+		Type:  commentType,
+		Doer:  doer,
+		Repo:  pr.Issue.Repo,
+		Issue: pr.Issue,
+	}); err != nil {
+		return fmt.Errorf("createComment: %v", err)
+	}
+
+	if err = sess.Commit(); err != nil {
+		return fmt.Errorf("Commit: %v", err)
+	}
+
+	action := api.HookIssueReadyForReview
+	if isDraft {
+		action = api.HookIssueConvertedToDraft
+	}
+	mode, _ := AccessLevel(doer, pr.Issue.Repo)
+	if err = PrepareWebhooks(pr.Issue.Repo, HookEventPullRequest, &api.PullRequestPayload{
+		Action:      action,
+		Index:       pr.Index,
+		PullRequest: pr.APIFormat(),
+		Repository:  pr.Issue.Repo.APIFormat(mode),
+		Sender:      doer.APIFormat(),
+	}); err != nil {
+		log.Error("PrepareWebhooks [is_draft: %t]: %v", isDraft, err)
+	} else {
+		go HookQueue.Add(pr.Issue.Repo.ID)
+	}
+	return nil
+}
+
 // IsFilesConflicted determines if the  Pull Request has changes conflicting with the target branch.
 func (pr *PullRequest) IsFilesConflicted() bool {
 	return len(pr.ConflictedFiles) > 0
@@ -0,0 +1,182 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+
+	"github.com/masoodkamyab/gitea/modules/util"
+)
+
+// MergeQueueRefPrefix is the prefix of the refs holding the speculative merge commits of queued pull requests
+const MergeQueueRefPrefix = "refs/merge-queue/"
+
+// Reasons for ejecting a pull request from the merge queue, stored as content of the comment
+const (
+	MergeQueueEjectReasonConflict     = "conflict"
+	MergeQueueEjectReasonChecksFailed = "checks_failed"
+)
+
+// MergeQueueEntry represents a pull request waiting in the merge queue of a protected branch.
+type MergeQueueEntry struct {
+	ID         int64        `xorm:"pk autoincr"`
+	RepoID     int64        `xorm:"INDEX(s)"`
+	BaseBranch string       `xorm:"INDEX(s)"`
+	PullID     int64        `xorm:"UNIQUE"`
+	Pull       *PullRequest `xorm:"-"`
+	DoerID     int64
+	Doer       *User      `xorm:"-"`
+	MergeStyle MergeStyle `xorm:"VARCHAR(20)"`
+	Message    string     `xorm:"TEXT"`
+
+	// BaseCommitID is the commit the speculative merge was created on top of,
+	// either the head of the base branch or the merge commit of the previous entry.
+	BaseCommitID string `xorm:"VARCHAR(40)"`
+	// HeadCommitID is the head of the pull request branch that was merged speculatively.
+	HeadCommitID string `xorm:"VARCHAR(40)"`
+	// CommitID is the speculative merge commit, empty while it has not been created yet.
+	CommitID string `xorm:"VARCHAR(40) INDEX"`
+
+	CreatedUnix util.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix util.TimeStamp `xorm:"INDEX updated"`
+}
+
+func (entry *MergeQueueEntry) loadAttributes(e Engine) (err error) {
+	if entry.Pull == nil {
+		if entry.Pull, err = getPullRequestByID(e, entry.PullID); err != nil {
+			return fmt.Errorf("getPullRequestByID [%d]: %v", entry.PullID, err)
+		}
+	}
+	if err = entry.Pull.loadIssue(e); err != nil {
+		return fmt.Errorf("loadIssue [%d]: %v", entry.PullID, err)
+	}
+	if err = entry.Pull.Issue.loadPoster(e); err != nil {
+		return fmt.Errorf("loadPoster [%d]: %v", entry.PullID, err)
+	}
+	if entry.Doer == nil {
+		entry.Doer, err = getUserByID(e, entry.DoerID)
+		if IsErrUserNotExist(err) {
+			entry.DoerID = -1
+			entry.Doer = NewGhostUser()
+		} else if err != nil {
+			return fmt.Errorf("getUserByID [%d]: %v", entry.DoerID, err)
+		}
+	}
+	return nil
+}
+
+// LoadAttributes loads the pull request and the user who queued it
+func (entry *MergeQueueEntry) LoadAttributes() error {
+	return entry.loadAttributes(x)
+}
+
+// UpdateSpeculativeCommit records the speculative merge commit of the entry
+func (entry *MergeQueueEntry) UpdateSpeculativeCommit(baseCommitID, headCommitID, commitID string) error {
+	entry.BaseCommitID = baseCommitID
+	entry.HeadCommitID = headCommitID
+	entry.CommitID = commitID
+	_, err := x.ID(entry.ID).Cols("base_commit_id, head_commit_id, commit_id").Update(entry)
+	return err
+}
+
+// AddToMergeQueue appends a pull request to the merge queue of its base branch
+func AddToMergeQueue(pr *PullRequest, doer *User, mergeStyle MergeStyle, message string) (*MergeQueueEntry, error) {
+	has, err := x.Exist(&MergeQueueEntry{PullID: pr.ID})
+	if err != nil {
+		return nil, err
+	} else if has {
+		return nil, ErrPullRequestAlreadyQueued{PullID: pr.ID}
+	}
+
+	entry := &MergeQueueEntry{
+		RepoID:     pr.BaseRepoID,
+		BaseBranch: pr.BaseBranch,
+		PullID:     pr.ID,
+		Pull:       pr,
+		DoerID:     doer.ID,
+		Doer:       doer,
+		MergeStyle: mergeStyle,
+		Message:    message,
+	}
+	if _, err = x.Insert(entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// RemoveFromMergeQueue removes the pull request with the given ID from the merge queue
+func RemoveFromMergeQueue(pullID int64) error {
+	_, err := x.Delete(&MergeQueueEntry{PullID: pullID})
+	return err
+}
+
+// GetMergeQueueEntryByPullID returns the merge queue entry of a pull request, or nil if it is not queued
+func GetMergeQueueEntryByPullID(pullID int64) (*MergeQueueEntry, error) {
+	entry := new(MergeQueueEntry)
+	has, err := x.Where("pull_id = ?", pullID).Get(entry)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+	return entry, nil
+}
+
+// GetMergeQueue returns the queued pull requests of a branch in the order they will be merged
+func GetMergeQueue(repoID int64, branch string) ([]*MergeQueueEntry, error) {
+	entries := make([]*MergeQueueEntry, 0, 5)
+	if err := x.Where("repo_id = ? AND base_branch = ?", repoID, branch).
+		Asc("id").
+		Find(&entries); err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if err := entry.loadAttributes(x); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+// GetMergeQueueEntriesByCommitID returns the merge queue entries whose speculative merge commit is commitID
+func GetMergeQueueEntriesByCommitID(repoID int64, commitID string) ([]*MergeQueueEntry, error) {
+	entries := make([]*MergeQueueEntry, 0, 1)
+	return entries, x.Where("repo_id = ? AND commit_id = ?", repoID, commitID).Find(&entries)
+}
+
+// GetAllMergeQueueEntries returns the first entry of every non-empty merge queue,
+// which is enough to identify each queue by its repository and branch.
+func GetAllMergeQueueEntries() ([]*MergeQueueEntry, error) {
+	entries := make([]*MergeQueueEntry, 0, 10)
+	return entries, x.Where("id IN (SELECT MIN(id) FROM merge_queue_entry GROUP BY repo_id, base_branch)").
+		Asc("id").
+		Find(&entries)
+}
+
+// GetMergeQueuePosition returns the 1-based position of the pull request in the
+// merge queue of its base branch, or 0 if it is not queued.
+func (pr *PullRequest) GetMergeQueuePosition() (int64, error) {
+	entry, err := GetMergeQueueEntryByPullID(pr.ID)
+	if err != nil || entry == nil {
+		return 0, err
+	}
+	return x.Where("repo_id = ? AND base_branch = ? AND id <= ?", entry.RepoID, entry.BaseBranch, entry.ID).
+		Count(new(MergeQueueEntry))
+}
+
+// GetMergeQueueRefName returns the ref holding the speculative merge commit of the pull request
+func (pr *PullRequest) GetMergeQueueRefName() string {
+	return fmt.Sprintf("%s%d", MergeQueueRefPrefix, pr.Index)
+}
+
+// IsMergeQueueEnabled returns true if pull requests into the base branch have to go through the merge queue
+func (pr *PullRequest) IsMergeQueueEnabled() (bool, error) {
+	if pr.ProtectedBranch == nil {
+		if err := pr.LoadProtectedBranch(); err != nil {
+			return false, err
+		}
+	}
+	return pr.ProtectedBranch != nil && pr.ProtectedBranch.EnableMergeQueue, nil
+}
@@ -0,0 +1,76 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddToMergeQueue(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	pr1 := AssertExistsAndLoadBean(t, &PullRequest{ID: 1}).(*PullRequest)
+	pr2 := AssertExistsAndLoadBean(t, &PullRequest{ID: 2}).(*PullRequest)
+
+	entry, err := AddToMergeQueue(pr2, doer, MergeStyleMerge, "merge message")
+	assert.NoError(t, err)
+	AssertExistsAndLoadBean(t, &MergeQueueEntry{ID: entry.ID, PullID: pr2.ID, RepoID: 1, BaseBranch: "master"})
+
+	_, err = AddToMergeQueue(pr2, doer, MergeStyleMerge, "merge message")
+	assert.True(t, IsErrPullRequestAlreadyQueued(err))
+
+	_, err = AddToMergeQueue(pr1, doer, MergeStyleSquash, "squash message")
+	assert.NoError(t, err)
+
+	position, err := pr2.GetMergeQueuePosition()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, position)
+	position, err = pr1.GetMergeQueuePosition()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, position)
+
+	queue, err := GetMergeQueue(1, "master")
+	assert.NoError(t, err)
+	if assert.Len(t, queue, 2) {
+		assert.EqualValues(t, pr2.ID, queue[0].PullID)
+		assert.EqualValues(t, pr1.ID, queue[1].PullID)
+		assert.NotNil(t, queue[0].Pull.Issue)
+		assert.EqualValues(t, doer.ID, queue[0].Doer.ID)
+	}
+
+	queues, err := GetAllMergeQueueEntries()
+	assert.NoError(t, err)
+	if assert.Len(t, queues, 1) {
+		assert.EqualValues(t, pr2.ID, queues[0].PullID)
+	}
+
+	assert.NoError(t, RemoveFromMergeQueue(pr2.ID))
+	position, err = pr1.GetMergeQueuePosition()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, position)
+	position, err = pr2.GetMergeQueuePosition()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, position)
+}
+
+func TestMergeQueueEntry_UpdateSpeculativeCommit(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	pr := AssertExistsAndLoadBean(t, &PullRequest{ID: 2}).(*PullRequest)
+
+	entry, err := AddToMergeQueue(pr, doer, MergeStyleMerge, "merge message")
+	assert.NoError(t, err)
+	assert.NoError(t, entry.UpdateSpeculativeCommit("base", "head", "speculative"))
+
+	entries, err := GetMergeQueueEntriesByCommitID(1, "speculative")
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 1) {
+		assert.EqualValues(t, "base", entries[0].BaseCommitID)
+		assert.EqualValues(t, "head", entries[0].HeadCommitID)
+	}
+	assert.Equal(t, "refs/merge-queue/3", pr.GetMergeQueueRefName())
+}
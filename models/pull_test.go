@@ -5,10 +5,13 @@
 package models
 
 import (
+	"fmt"
 	"strconv"
 	"testing"
 	"time"
 
+	"github.com/masoodkamyab/gitea/modules/util"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -96,6 +99,42 @@ func TestPullRequestsOldest(t *testing.T) {
 	}
 }
 
+func TestPullRequestsDraft(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	pr := AssertExistsAndLoadBean(t, &PullRequest{ID: 2}).(*PullRequest)
+	doer := AssertExistsAndLoadBean(t, &User{ID: 1}).(*User)
+	assert.NoError(t, pr.SetDraft(doer, true))
+	AssertExistsAndLoadBean(t, &PullRequest{ID: 2, IsDraft: true})
+	AssertExistsAndLoadBean(t, &Comment{IssueID: pr.IssueID, Type: CommentTypePullConvertToDraft, PosterID: doer.ID})
+
+	prs, count, err := PullRequests(1, &PullRequestsOptions{
+		Page:    1,
+		State:   "open",
+		IsDraft: util.OptionalBoolTrue,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	if assert.Len(t, prs, 1) {
+		assert.Equal(t, int64(2), prs[0].ID)
+	}
+
+	prs, count, err = PullRequests(1, &PullRequestsOptions{
+		Page:    1,
+		State:   "open",
+		IsDraft: util.OptionalBoolFalse,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	if assert.Len(t, prs, 1) {
+		assert.Equal(t, int64(1), prs[0].ID)
+	}
+
+	assert.NoError(t, pr.SetDraft(doer, false))
+	AssertExistsAndLoadBean(t, &Comment{IssueID: pr.IssueID, Type: CommentTypePullReadyForReview, PosterID: doer.ID})
+	pr = AssertExistsAndLoadBean(t, &PullRequest{ID: 2}).(*PullRequest)
+	assert.False(t, pr.IsDraft)
+}
+
 func TestGetUnmergedPullRequest(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 	pr, err := GetUnmergedPullRequest(1, 1, "branch2", "master")
@@ -259,6 +298,10 @@ func TestPullRequest_IsWorkInProgress(t *testing.T) {
 
 	pr.Issue.Title = "[wip]: " + pr.Issue.Title
 	assert.True(t, pr.IsWorkInProgress())
+
+	pr.Issue.Title = "Draft"
+	pr.IsDraft = true
+	assert.True(t, pr.IsWorkInProgress())
 }
 
 func TestPullRequest_GetWorkInProgressPrefixWorkInProgress(t *testing.T) {
@@ -276,3 +319,36 @@ func TestPullRequest_GetWorkInProgressPrefixWorkInProgress(t *testing.T) {
 	pr.Issue.Title = "[wip] " + original
 	assert.Equal(t, "[wip]", pr.GetWorkInProgressPrefix())
 }
+
+func TestPullRequest_CheckUserAllowedToUpdate(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	pr := AssertExistsAndLoadBean(t, &PullRequest{ID: 2}).(*PullRequest)
+	owner := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	other := AssertExistsAndLoadBean(t, &User{ID: 4}).(*User)
+
+	assert.NoError(t, pr.CheckUserAllowedToUpdate(owner))
+	assert.True(t, IsErrNotAllowedToUpdate(pr.CheckUserAllowedToUpdate(other)))
+	assert.True(t, IsErrNotAllowedToUpdate(pr.CheckUserAllowedToUpdate(nil)))
+
+	_, err := x.Insert(&ProtectedBranch{RepoID: pr.HeadRepoID, BranchName: pr.HeadBranch})
+	assert.NoError(t, err)
+	assert.True(t, IsErrNotAllowedToUpdate(pr.CheckUserAllowedToUpdate(owner)))
+}
+
+func TestPullRequest_GetDefaultMessageTemplates(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	pr := AssertExistsAndLoadBean(t, &PullRequest{ID: 2}).(*PullRequest)
+	assert.NoError(t, pr.LoadIssue())
+	assert.Equal(t, fmt.Sprintf("%s (#%d)", pr.Issue.Title, pr.Issue.Index), pr.GetDefaultSquashMessage())
+
+	prUnit := AssertExistsAndLoadBean(t, &RepoUnit{RepoID: pr.BaseRepoID, Type: UnitTypePullRequests}).(*RepoUnit)
+	cfg := prUnit.PullRequestsConfig()
+	cfg.MergeMessageTemplate = "Merge $PullRequestReference from $HeadBranch into $BaseBranch\n\n$Unknown"
+	cfg.SquashMessageTemplate = "$PullRequestTitle (#${PullRequestIndex})\n"
+	_, err := x.ID(prUnit.ID).Cols("config").Update(prUnit)
+	assert.NoError(t, err)
+
+	pr = AssertExistsAndLoadBean(t, &PullRequest{ID: 2}).(*PullRequest)
+	assert.Equal(t, fmt.Sprintf("Merge #%d from %s into %s\n\n$Unknown", pr.Index, pr.HeadBranch, pr.BaseBranch), pr.GetDefaultMergeMessage())
+	assert.Equal(t, fmt.Sprintf("%s (#%d)", pr.Issue.Title, pr.Index), pr.GetDefaultSquashMessage())
+}
@@ -281,12 +281,19 @@ func (repo *Repository) innerAPIFormat(e Engine, mode AccessMode, isParent bool)
 	if _, err := repo.getUnit(e, UnitTypeWiki); err == nil {
 		hasWiki = true
 	}
+	hasProjects := false
+	if _, err := repo.getUnit(e, UnitTypeProjects); err == nil {
+		hasProjects = true
+	}
 	hasPullRequests := false
 	ignoreWhitespaceConflicts := false
 	allowMerge := false
 	allowRebase := false
 	allowRebaseMerge := false
 	allowSquash := false
+	allowFastForwardOnly := false
+	mergeMessageTemplate := ""
+	squashMessageTemplate := ""
 	if unit, err := repo.getUnit(e, UnitTypePullRequests); err == nil {
 		config := unit.PullRequestsConfig()
 		hasPullRequests = true
@@ -295,6 +302,9 @@ func (repo *Repository) innerAPIFormat(e Engine, mode AccessMode, isParent bool)
 		allowRebase = config.AllowRebase
 		allowRebaseMerge = config.AllowRebaseMerge
 		allowSquash = config.AllowSquash
+		allowFastForwardOnly = config.AllowFastForwardOnly
+		mergeMessageTemplate = config.MergeMessageTemplate
+		squashMessageTemplate = config.SquashMessageTemplate
 	}
 
 	return &api.Repository{
@@ -325,11 +335,15 @@ func (repo *Repository) innerAPIFormat(e Engine, mode AccessMode, isParent bool)
 		HasIssues:                 hasIssues,
 		HasWiki:                   hasWiki,
 		HasPullRequests:           hasPullRequests,
+		HasProjects:               hasProjects,
 		IgnoreWhitespaceConflicts: ignoreWhitespaceConflicts,
 		AllowMerge:                allowMerge,
 		AllowRebase:               allowRebase,
 		AllowRebaseMerge:          allowRebaseMerge,
 		AllowSquash:               allowSquash,
+		AllowFastForwardOnly:      allowFastForwardOnly,
+		MergeMessageTemplate:      mergeMessageTemplate,
+		SquashMessageTemplate:     squashMessageTemplate,
 		AvatarURL:                 repo.avatarLink(e),
 	}
 }
@@ -1767,6 +1781,10 @@ func DeleteRepository(doer *User, uid, repoID int64) error {
 		}
 	}
 
+	if err = deleteMilestoneSnapshots(sess, builder.Eq{"repo_id": repoID}); err != nil {
+		return fmt.Errorf("deleteMilestoneSnapshots: %v", err)
+	}
+
 	if err = deleteBeans(sess,
 		&Access{RepoID: repo.ID},
 		&Action{RepoID: repo.ID},
@@ -1783,6 +1801,7 @@ func DeleteRepository(doer *User, uid, repoID int64) error {
 		&HookTask{RepoID: repoID},
 		&Notification{RepoID: repoID},
 		&CommitStatus{RepoID: repoID},
+		&IssueRedirect{OldRepoID: repoID},
 	); err != nil {
 		return fmt.Errorf("deleteBeans: %v", err)
 	}
@@ -1814,6 +1833,29 @@ func DeleteRepository(doer *User, uid, repoID int64) error {
 		return err
 	}
 
+	if _, err = sess.In("issue_id", deleteCond).
+		Delete(&ProjectIssue{}); err != nil {
+		return err
+	}
+
+	if _, err = sess.In("issue_id", deleteCond).
+		Delete(&IssueRedirect{}); err != nil {
+		return err
+	}
+
+	if _, err = sess.In("issue_id", deleteCond).
+		Delete(&IssueFieldValue{}); err != nil {
+		return err
+	}
+
+	if err = deleteProjects(sess, builder.Eq{"repo_id": repoID, "type": ProjectTypeRepository}); err != nil {
+		return fmt.Errorf("deleteProjects: %v", err)
+	}
+
+	if err = deleteIssueFields(sess, builder.Eq{"repo_id": repoID}); err != nil {
+		return fmt.Errorf("deleteIssueFields: %v", err)
+	}
+
 	attachmentPaths := make([]string, 0, 20)
 	attachments := make([]*Attachment, 0, len(attachmentPaths))
 	if err = sess.Join("INNER", "issue", "issue.id = attachment.issue_id").
@@ -112,6 +112,21 @@ func (repo *Repository) IsCollaborator(userID int64) (bool, error) {
 	return repo.isCollaborator(x, userID)
 }
 
+// GetCollaboration returns the collaboration of the user with the repository,
+// or nil if the user is not a collaborator.
+func (repo *Repository) GetCollaboration(uid int64) (*Collaboration, error) {
+	collaboration := &Collaboration{
+		RepoID: repo.ID,
+		UserID: uid,
+	}
+	if has, err := x.Get(collaboration); err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+	return collaboration, nil
+}
+
 // ChangeCollaborationAccessMode sets new access mode for the collaboration.
 func (repo *Repository) ChangeCollaborationAccessMode(uid int64, mode AccessMode) error {
 	// Discard invalid input
@@ -8,6 +8,7 @@ import (
 	"fmt"
 
 	"github.com/masoodkamyab/gitea/modules/log"
+	"github.com/masoodkamyab/gitea/modules/structs"
 )
 
 // Permission contains all the permissions related variables to a repository for a user
@@ -195,12 +196,39 @@ func getUserRepoPermission(e Engine, repo *Repository, user *User) (perm Permiss
 		return
 	}
 
+	// Users who did not enroll into the two-factor authentication the site
+	// requires get the access of anonymous users
+	var blocked bool
+	if blocked, err = isSiteTwoFactorBlocked(e, user); err != nil {
+		return
+	} else if blocked {
+		perm.AccessMode = AccessModeNone
+		if !repo.IsPrivate && repo.Owner.Visibility == structs.VisibleTypePublic {
+			perm.AccessMode = AccessModeRead
+		}
+		return
+	}
+
 	// Admin or the owner has super access to the repository
 	if user.IsAdmin || user.ID == repo.OwnerID {
 		perm.AccessMode = AccessModeOwner
 		return
 	}
 
+	// Users who did not enroll into the two-factor authentication the organization
+	// requires get the access of signed in users who are not members
+	if repo.Owner.IsOrganization() {
+		if blocked, err = isTwoFactorBlocked(e, repo.Owner, user); err != nil {
+			return
+		} else if blocked {
+			perm.AccessMode = AccessModeNone
+			if !repo.IsPrivate && repo.Owner.Visibility != structs.VisibleTypePrivate {
+				perm.AccessMode = AccessModeRead
+			}
+			return
+		}
+	}
+
 	// plain user
 	perm.AccessMode, err = accessLevel(e, user.ID, repo)
 	if err != nil {
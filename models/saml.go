@@ -0,0 +1,107 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"sort"
+
+	"github.com/masoodkamyab/gitea/modules/auth/saml"
+	"github.com/masoodkamyab/gitea/modules/log"
+
+	"github.com/markbates/goth"
+)
+
+// GetActiveSAMLLoginSources returns all actived LoginSAML sources
+func GetActiveSAMLLoginSources() ([]*LoginSource, error) {
+	sources := make([]*LoginSource, 0, 1)
+	if err := x.Where("is_actived = ? and type = ?", true, LoginSAML).Find(&sources); err != nil {
+		return nil, err
+	}
+	return sources, nil
+}
+
+// GetActiveSAMLLoginSourceByName returns the active SAML LoginSource with the given name
+func GetActiveSAMLLoginSourceByName(name string) (*LoginSource, error) {
+	loginSource := new(LoginSource)
+	has, err := x.Where("name = ? and type = ? and is_actived = ?", name, LoginSAML, true).Get(loginSource)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrLoginSourceNotExist{}
+	}
+	return loginSource, nil
+}
+
+// GetActiveSAMLProviders returns the sorted names of the active SAML login sources
+func GetActiveSAMLProviders() ([]string, error) {
+	loginSources, err := GetActiveSAMLLoginSources()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(loginSources))
+	for _, source := range loginSources {
+		names = append(names, source.Name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// InitSAML registers the service providers of all active SAML login sources. A source whose identity
+// provider metadata cannot be loaded is logged and left unregistered, so its logins fail until it is saved again.
+func InitSAML() error {
+	loginSources, err := GetActiveSAMLLoginSources()
+	if err != nil {
+		return err
+	}
+
+	for _, source := range loginSources {
+		samlConfig := source.SAML()
+		if err := saml.RegisterProvider(source.Name, samlConfig.IdentityProviderMetadataURL, samlConfig.IdentityProviderMetadata); err != nil {
+			log.Error("InitSAML: %v", ErrSAMLInitialize{ProviderName: source.Name, MetadataURL: samlConfig.IdentityProviderMetadataURL, Cause: err})
+		}
+	}
+	return nil
+}
+
+// SAMLUser returns the goth user of a verified SAML assertion, its ID is the NameID and
+// its raw data are the attributes of the assertion
+func SAMLUser(source *LoginSource, assertion *saml.Assertion) goth.User {
+	cfg := source.SAML()
+	gothUser := goth.User{
+		Provider: source.Name,
+		UserID:   assertion.NameID,
+		NickName: assertion.NameID,
+		RawData:  make(map[string]interface{}, len(assertion.Attributes)),
+	}
+	if len(cfg.UsernameAttribute) > 0 {
+		if username := assertion.Attribute(cfg.UsernameAttribute); len(username) > 0 {
+			gothUser.NickName = username
+		}
+	}
+	if len(cfg.EmailAttribute) > 0 {
+		gothUser.Email = assertion.Attribute(cfg.EmailAttribute)
+	}
+	if len(cfg.FullNameAttribute) > 0 {
+		gothUser.Name = assertion.Attribute(cfg.FullNameAttribute)
+	}
+	for name, values := range assertion.Attributes {
+		gothUser.RawData[name] = values
+	}
+	return gothUser
+}
+
+// SyncSAMLUser synchronizes the full name, email address, administrator status and team memberships
+// of a user with the attributes of a SAML login
+func SyncSAMLUser(source *LoginSource, user *User, gothUser goth.User) error {
+	cfg := source.SAML()
+	if err := syncExternalUserProfile(user, gothUser.Name, gothUser.Email); err != nil {
+		return err
+	}
+	if len(cfg.GroupAttribute) == 0 {
+		return nil
+	}
+	return syncExternalUserGroups(user, getExternalUserGroups(gothUser.RawData[cfg.GroupAttribute]), cfg.AdminGroup, cfg.GroupTeamMap, cfg.GroupTeamMapRemoval)
+}
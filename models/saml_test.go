@@ -0,0 +1,74 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/masoodkamyab/gitea/modules/auth/saml"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSAMLUser(t *testing.T) {
+	source := &LoginSource{Name: "idp", Type: LoginSAML, Cfg: &SAMLConfig{
+		UsernameAttribute: "uid",
+		EmailAttribute:    "mail",
+		FullNameAttribute: "displayName",
+	}}
+	assertion := &saml.Assertion{
+		NameID: "a1b2c3",
+		Attributes: map[string][]string{
+			"uid":         {"jdoe"},
+			"mail":        {"jdoe@example.com"},
+			"displayName": {"John Doe"},
+			"memberOf":    {"admins", "writers"},
+		},
+	}
+	gothUser := SAMLUser(source, assertion)
+	assert.Equal(t, "idp", gothUser.Provider)
+	assert.Equal(t, "a1b2c3", gothUser.UserID)
+	assert.Equal(t, "jdoe", gothUser.NickName)
+	assert.Equal(t, "jdoe@example.com", gothUser.Email)
+	assert.Equal(t, "John Doe", gothUser.Name)
+	assert.Equal(t, []string{"admins", "writers"}, gothUser.RawData["memberOf"])
+
+	// the username defaults to the NameID
+	delete(assertion.Attributes, "uid")
+	assert.Equal(t, "a1b2c3", SAMLUser(source, assertion).NickName)
+}
+
+func TestSyncSAMLUser(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	source := &LoginSource{Name: "idp", Type: LoginSAML, Cfg: &SAMLConfig{
+		EmailAttribute:    "mail",
+		FullNameAttribute: "displayName",
+		GroupAttribute:    "memberOf",
+		AdminGroup:        "admins",
+		GroupTeamMap:      `{"writers": {"user3": ["team1"]}}`,
+	}}
+	user := AssertExistsAndLoadBean(t, &User{ID: 5}).(*User)
+	assertion := &saml.Assertion{
+		NameID: "user5",
+		Attributes: map[string][]string{
+			"mail":        {"Synced@Example.com"},
+			"displayName": {"Synced Name"},
+			"memberOf":    {"admins", "writers"},
+		},
+	}
+	assert.NoError(t, SyncSAMLUser(source, user, SAMLUser(source, assertion)))
+	user = AssertExistsAndLoadBean(t, &User{ID: 5}).(*User)
+	assert.Equal(t, "Synced Name", user.FullName)
+	assert.Equal(t, "synced@example.com", user.Email)
+	assert.True(t, user.IsAdmin)
+	AssertExistsAndLoadBean(t, &TeamUser{TeamID: 2, UID: user.ID})
+
+	// a user without the group attribute loses the administrator status
+	delete(assertion.Attributes, "memberOf")
+	assert.NoError(t, SyncSAMLUser(source, user, SAMLUser(source, assertion)))
+	user = AssertExistsAndLoadBean(t, &User{ID: 5}).(*User)
+	assert.False(t, user.IsAdmin)
+}
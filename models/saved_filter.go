@@ -0,0 +1,183 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	api "github.com/masoodkamyab/gitea/modules/structs"
+	"github.com/masoodkamyab/gitea/modules/util"
+
+	"xorm.io/builder"
+)
+
+// SavedFilter is a named issue query of a user which is shown on the issue and pull request dashboards
+type SavedFilter struct {
+	ID     int64       `xorm:"pk autoincr"`
+	UserID int64       `xorm:"INDEX NOT NULL"`
+	User   *User       `xorm:"-"`
+	RepoID int64       `xorm:"INDEX"` // zero for a filter across all repositories
+	Repo   *Repository `xorm:"-"`
+	TeamID int64       `xorm:"INDEX"` // the organization team the filter is shared with
+	Team   *Team       `xorm:"-"`
+
+	Name     string `xorm:"NOT NULL"`
+	Query    string `xorm:"TEXT"`
+	SortType string
+
+	CreatedUnix util.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix util.TimeStamp `xorm:"INDEX updated"`
+}
+
+func (f *SavedFilter) loadAttributes(e Engine) (err error) {
+	if f.User == nil {
+		if f.User, err = getUserByID(e, f.UserID); err != nil {
+			return fmt.Errorf("getUserByID [%d]: %v", f.UserID, err)
+		}
+	}
+	if f.RepoID > 0 && f.Repo == nil {
+		if f.Repo, err = getRepositoryByID(e, f.RepoID); err != nil {
+			return fmt.Errorf("getRepositoryByID [%d]: %v", f.RepoID, err)
+		}
+	}
+	if f.TeamID > 0 && f.Team == nil {
+		if f.Team, err = getTeamByID(e, f.TeamID); err != nil {
+			return fmt.Errorf("getTeamByID [%d]: %v", f.TeamID, err)
+		}
+	}
+	return nil
+}
+
+// LoadAttributes loads the owner, the repository and the team of the filter
+func (f *SavedFilter) LoadAttributes() error {
+	return f.loadAttributes(x)
+}
+
+// IsOwnedBy returns true if the filter was saved by the given user
+func (f *SavedFilter) IsOwnedBy(userID int64) bool {
+	return f.UserID == userID
+}
+
+// APIFormat returns this SavedFilter in API format
+func (f *SavedFilter) APIFormat() *api.SavedFilter {
+	return &api.SavedFilter{
+		ID:      f.ID,
+		Name:    f.Name,
+		Query:   f.Query,
+		Sort:    f.SortType,
+		RepoID:  f.RepoID,
+		TeamID:  f.TeamID,
+		OwnerID: f.UserID,
+		Created: f.CreatedUnix.AsTime(),
+		Updated: f.UpdatedUnix.AsTime(),
+	}
+}
+
+// validate checks that the owner of the filter can read the repository and belongs to the team of the filter
+func (f *SavedFilter) validate(e Engine) error {
+	f.Name = strings.TrimSpace(f.Name)
+	if len(f.Name) == 0 {
+		return ErrSavedFilterInvalid{Reason: "name is empty"}
+	}
+	if f.SortType != "" && !isIssueSortType(f.SortType) {
+		return ErrSavedFilterInvalid{Reason: "unknown sort type: " + f.SortType}
+	}
+
+	if f.RepoID > 0 {
+		user, err := getUserByID(e, f.UserID)
+		if err != nil {
+			return fmt.Errorf("getUserByID [%d]: %v", f.UserID, err)
+		}
+		repo, err := getRepositoryByID(e, f.RepoID)
+		if err != nil {
+			return err
+		}
+		perm, err := getUserRepoPermission(e, repo, user)
+		if err != nil {
+			return err
+		}
+		if !perm.CanRead(UnitTypeIssues) && !perm.CanRead(UnitTypePullRequests) {
+			return ErrRepoNotExist{ID: f.RepoID}
+		}
+	}
+
+	if f.TeamID > 0 {
+		team, err := getTeamByID(e, f.TeamID)
+		if err != nil {
+			return err
+		}
+		isMember, err := isTeamMember(e, team.OrgID, team.ID, f.UserID)
+		if err != nil {
+			return err
+		} else if !isMember {
+			return ErrSavedFilterInvalid{Reason: "not a member of the team"}
+		}
+	}
+	return nil
+}
+
+// NewSavedFilter saves a new filter
+func NewSavedFilter(f *SavedFilter) error {
+	if err := f.validate(x); err != nil {
+		return err
+	}
+	_, err := x.Insert(f)
+	return err
+}
+
+// UpdateSavedFilter updates the name, the query, the sort type, the repository and the team of a filter
+func UpdateSavedFilter(f *SavedFilter) error {
+	if err := f.validate(x); err != nil {
+		return err
+	}
+	_, err := x.ID(f.ID).Cols("name", "query", "sort_type", "repo_id", "team_id").Update(f)
+	return err
+}
+
+// DeleteSavedFilter deletes a filter
+func DeleteSavedFilter(f *SavedFilter) error {
+	_, err := x.ID(f.ID).Delete(new(SavedFilter))
+	return err
+}
+
+// GetSavedFilterByID returns the filter by given ID
+func GetSavedFilterByID(id int64) (*SavedFilter, error) {
+	f := new(SavedFilter)
+	has, err := x.ID(id).Get(f)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrSavedFilterNotExist{ID: id}
+	}
+	return f, nil
+}
+
+// savedFiltersVisibleToCond returns the condition of the filters which the user saved
+// or which are shared with a team the user belongs to
+func savedFiltersVisibleToCond(userID int64) builder.Cond {
+	return builder.Or(
+		builder.Eq{"user_id": userID},
+		builder.In("team_id", builder.Select("team_id").From("team_user").Where(builder.Eq{"uid": userID})),
+	)
+}
+
+// GetSavedFilterForUser returns the filter by given ID if it is visible to the user
+func GetSavedFilterForUser(id, userID int64) (*SavedFilter, error) {
+	f := new(SavedFilter)
+	has, err := x.ID(id).Where(savedFiltersVisibleToCond(userID)).Get(f)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrSavedFilterNotExist{ID: id}
+	}
+	return f, nil
+}
+
+// GetSavedFiltersForUser returns the filters which the user saved or which are shared with the user, ordered by name
+func GetSavedFiltersForUser(userID int64) ([]*SavedFilter, error) {
+	filters := make([]*SavedFilter, 0, 5)
+	return filters, x.Where(savedFiltersVisibleToCond(userID)).Asc("name", "id").Find(&filters)
+}
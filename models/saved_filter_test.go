@@ -0,0 +1,85 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSavedFilter(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	f := &SavedFilter{UserID: 2, Name: " Pulls to review ", Query: "is:pr is:open", SortType: "oldest", RepoID: 1, TeamID: 1}
+	assert.NoError(t, NewSavedFilter(f))
+	AssertExistsAndLoadBean(t, &SavedFilter{ID: f.ID, Name: "Pulls to review"})
+
+	assert.True(t, IsErrSavedFilterInvalid(NewSavedFilter(&SavedFilter{UserID: 2, Name: " "})))
+	assert.True(t, IsErrSavedFilterInvalid(NewSavedFilter(&SavedFilter{UserID: 2, Name: "a", SortType: "random"})))
+	// user4 cannot read the private repository of user2
+	assert.True(t, IsErrRepoNotExist(NewSavedFilter(&SavedFilter{UserID: 4, Name: "a", RepoID: 2})))
+	assert.True(t, IsErrRepoNotExist(NewSavedFilter(&SavedFilter{UserID: 2, Name: "a", RepoID: NonexistentID})))
+	// user5 is no member of team 1
+	assert.True(t, IsErrSavedFilterInvalid(NewSavedFilter(&SavedFilter{UserID: 5, Name: "a", TeamID: 1})))
+	assert.Equal(t, ErrTeamNotExist, NewSavedFilter(&SavedFilter{UserID: 2, Name: "a", TeamID: NonexistentID}))
+}
+
+func TestUpdateSavedFilter(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	f := AssertExistsAndLoadBean(t, &SavedFilter{ID: 1}).(*SavedFilter)
+	f.Name = "Renamed"
+	f.Query = "is:closed"
+	f.TeamID = 2
+	assert.NoError(t, UpdateSavedFilter(f))
+	AssertExistsAndLoadBean(t, &SavedFilter{ID: 1, Name: "Renamed", Query: "is:closed", TeamID: 2})
+
+	f.TeamID = 3
+	assert.True(t, IsErrSavedFilterInvalid(UpdateSavedFilter(f)))
+}
+
+func TestDeleteSavedFilter(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	f := AssertExistsAndLoadBean(t, &SavedFilter{ID: 1}).(*SavedFilter)
+	assert.NoError(t, DeleteSavedFilter(f))
+	AssertNotExistsBean(t, &SavedFilter{ID: 1})
+}
+
+func TestGetSavedFiltersForUser(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	test := func(userID int64, expectedIDs ...int64) {
+		filters, err := GetSavedFiltersForUser(userID)
+		assert.NoError(t, err)
+		var ids []int64
+		for _, f := range filters {
+			ids = append(ids, f.ID)
+		}
+		assert.Equal(t, expectedIDs, ids)
+	}
+	test(2, 1, 2)
+	// filter 2 is shared with team 2 which user4 belongs to
+	test(4, 2)
+	test(5, 3)
+	test(1)
+}
+
+func TestGetSavedFilterForUser(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	f, err := GetSavedFilterForUser(2, 4)
+	assert.NoError(t, err)
+	assert.False(t, f.IsOwnedBy(4))
+	assert.NoError(t, f.LoadAttributes())
+	assert.EqualValues(t, 1, f.Repo.ID)
+	assert.EqualValues(t, 2, f.Team.ID)
+
+	_, err = GetSavedFilterForUser(1, 4)
+	assert.True(t, IsErrSavedFilterNotExist(err))
+	_, err = GetSavedFilterByID(NonexistentID)
+	assert.True(t, IsErrSavedFilterNotExist(err))
+}
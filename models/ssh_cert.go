@@ -0,0 +1,189 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/masoodkamyab/gitea/modules/setting"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	tplPrincipal = `command="%s serv cert-%d --config='%s'",no-port-forwarding,no-X11-forwarding,no-agent-forwarding,no-pty %s` + "\n"
+
+	sourceAddressCriticalOption = "source-address"
+)
+
+// trustedUserCAKeys parses the public keys of all certificate authorities
+// trusted to sign SSH user certificates.
+func trustedUserCAKeys() ([]ssh.PublicKey, error) {
+	keys := make([]ssh.PublicKey, 0, len(setting.SSH.TrustedUserCAKeys))
+	for _, content := range setting.SSH.TrustedUserCAKeys {
+		key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(content))
+		if err != nil {
+			return nil, fmt.Errorf("ParseAuthorizedKey [%s]: %v", content, err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// IsSSHCertificateAuthEnabled returns true if at least one trusted SSH certificate authority is configured.
+func IsSSHCertificateAuthEnabled() bool {
+	return len(setting.SSH.TrustedUserCAKeys) > 0
+}
+
+// getUserBySSHPrincipal returns the user a certificate principal maps to,
+// following the allowed kinds of principals in order.
+func getUserBySSHPrincipal(principal string) (*User, error) {
+	for _, kind := range setting.SSH.AuthorizedPrincipalsAllow {
+		var (
+			u   *User
+			err error
+		)
+		switch kind {
+		case setting.SSHPrincipalUsername:
+			if strings.Contains(principal, "@") {
+				continue
+			}
+			u, err = GetUserByName(principal)
+		case setting.SSHPrincipalEmail:
+			if !strings.Contains(principal, "@") {
+				continue
+			}
+			u, err = GetUserByEmail(principal)
+		default:
+			continue
+		}
+		if err == nil {
+			return u, nil
+		} else if !IsErrUserNotExist(err) {
+			return nil, err
+		}
+	}
+	return nil, ErrUserNotExist{0, principal, 0}
+}
+
+// checkSourceAddress checks that the remote address is allowed by
+// the comma-separated list of addresses and CIDR ranges.
+func checkSourceAddress(addr net.Addr, sourceAddrs string) error {
+	if addr == nil {
+		return fmt.Errorf("remote address is required by source-address option")
+	}
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return fmt.Errorf("remote address %v is not a TCP address", addr)
+	}
+
+	for _, sourceAddr := range strings.Split(sourceAddrs, ",") {
+		sourceAddr = strings.TrimSpace(sourceAddr)
+		if allowedIP := net.ParseIP(sourceAddr); allowedIP != nil {
+			if allowedIP.Equal(tcpAddr.IP) {
+				return nil
+			}
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(sourceAddr)
+		if err != nil {
+			return fmt.Errorf("invalid source-address %q: %v", sourceAddr, err)
+		}
+		if ipNet.Contains(tcpAddr.IP) {
+			return nil
+		}
+	}
+	return fmt.Errorf("remote address %v is not allowed by source-address %q", addr, sourceAddrs)
+}
+
+// CheckSSHCertificate verifies that the user certificate is signed by a trusted
+// certificate authority and currently valid, and returns the user its first
+// matching principal maps to. If remoteAddr is nil the source-address
+// critical option is left to the SSH server to enforce.
+func CheckSSHCertificate(cert *ssh.Certificate, remoteAddr net.Addr) (*User, string, error) {
+	invalid := func(format string, args ...interface{}) error {
+		return ErrSSHCertificateInvalid{KeyID: cert.KeyId, Reason: fmt.Sprintf(format, args...)}
+	}
+
+	if cert.CertType != ssh.UserCert {
+		return nil, "", invalid("not a user certificate")
+	}
+
+	cas, err := trustedUserCAKeys()
+	if err != nil {
+		return nil, "", err
+	}
+	checker := &ssh.CertChecker{
+		IsUserAuthority: func(auth ssh.PublicKey) bool {
+			for _, ca := range cas {
+				if bytes.Equal(ca.Marshal(), auth.Marshal()) {
+					return true
+				}
+			}
+			return false
+		},
+	}
+	if !checker.IsUserAuthority(cert.SignatureKey) {
+		return nil, "", invalid("not signed by a trusted certificate authority")
+	}
+
+	if len(cert.ValidPrincipals) == 0 {
+		return nil, "", invalid("no principals")
+	}
+
+	if sourceAddrs, has := cert.CriticalOptions[sourceAddressCriticalOption]; has && remoteAddr != nil {
+		if err = checkSourceAddress(remoteAddr, sourceAddrs); err != nil {
+			return nil, "", invalid("%v", err)
+		}
+	}
+
+	for _, principal := range cert.ValidPrincipals {
+		u, err := getUserBySSHPrincipal(principal)
+		if err != nil {
+			if IsErrUserNotExist(err) {
+				continue
+			}
+			return nil, "", err
+		}
+
+		if err = checker.CheckCert(principal, cert); err != nil {
+			return nil, "", invalid("%v", err)
+		}
+		if u.IsOrganization() || !u.IsActive || u.ProhibitLogin {
+			return nil, "", invalid("user %s is not allowed to sign in", u.Name)
+		}
+		return u, principal, nil
+	}
+	return nil, "", invalid("no principal matches a user")
+}
+
+// AuthorizedPrincipalString returns the AuthorizedPrincipalsCommand line
+// granting the certificate principal access as the given user.
+func AuthorizedPrincipalString(u *User, principal string) string {
+	return fmt.Sprintf(tplPrincipal, setting.AppPath, u.ID, setting.CustomConf, principal)
+}
+
+// RewriteTrustedUserCAKeys writes the trusted certificate authorities to the
+// file referenced by TrustedUserCAKeys in the sshd configuration.
+func RewriteTrustedUserCAKeys() error {
+	if setting.SSH.Disabled || setting.SSH.StartBuiltinServer || !IsSSHCertificateAuthEnabled() {
+		return nil
+	}
+
+	sshOpLocker.Lock()
+	defer sshOpLocker.Unlock()
+
+	fPath := setting.SSH.TrustedUserCAKeysFile
+	if err := os.MkdirAll(filepath.Dir(fPath), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(fPath, []byte(strings.Join(setting.SSH.TrustedUserCAKeys, "\n")+"\n"), 0600)
+}
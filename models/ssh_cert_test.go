@@ -0,0 +1,111 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/masoodkamyab/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+)
+
+func newTestSSHSigner(t *testing.T) ssh.Signer {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(key)
+	assert.NoError(t, err)
+	return signer
+}
+
+func newTestSSHCertificate(t *testing.T, ca ssh.Signer, principals ...string) *ssh.Certificate {
+	cert := &ssh.Certificate{
+		Key:             newTestSSHSigner(t).PublicKey(),
+		Serial:          42,
+		CertType:        ssh.UserCert,
+		KeyId:           "test-cert",
+		ValidPrincipals: principals,
+		ValidAfter:      uint64(time.Now().Add(-time.Hour).Unix()),
+		ValidBefore:     uint64(time.Now().Add(time.Hour).Unix()),
+	}
+	assert.NoError(t, cert.SignCert(rand.Reader, ca))
+	return cert
+}
+
+func TestCheckSSHCertificate(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	ca := newTestSSHSigner(t)
+	defer func(keys, allow []string) {
+		setting.SSH.TrustedUserCAKeys = keys
+		setting.SSH.AuthorizedPrincipalsAllow = allow
+	}(setting.SSH.TrustedUserCAKeys, setting.SSH.AuthorizedPrincipalsAllow)
+	setting.SSH.TrustedUserCAKeys = []string{strings.TrimSpace(string(ssh.MarshalAuthorizedKey(ca.PublicKey())))}
+	setting.SSH.AuthorizedPrincipalsAllow = []string{setting.SSHPrincipalUsername, setting.SSHPrincipalEmail}
+
+	// Principals are tried in order, skipping those matching no user
+	user, principal, err := CheckSSHCertificate(newTestSSHCertificate(t, ca, "nobody", "user2"), nil)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, user.ID)
+	assert.Equal(t, "user2", principal)
+
+	user, principal, err = CheckSSHCertificate(newTestSSHCertificate(t, ca, "user4@example.com"), nil)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 4, user.ID)
+	assert.Equal(t, "user4@example.com", principal)
+
+	setting.SSH.AuthorizedPrincipalsAllow = []string{setting.SSHPrincipalUsername}
+	_, _, err = CheckSSHCertificate(newTestSSHCertificate(t, ca, "user4@example.com"), nil)
+	assert.True(t, IsErrSSHCertificateInvalid(err))
+	setting.SSH.AuthorizedPrincipalsAllow = []string{setting.SSHPrincipalUsername, setting.SSHPrincipalEmail}
+
+	// Inactive users and organizations
+	_, _, err = CheckSSHCertificate(newTestSSHCertificate(t, ca, "user9"), nil)
+	assert.True(t, IsErrSSHCertificateInvalid(err))
+	_, _, err = CheckSSHCertificate(newTestSSHCertificate(t, ca, "user3"), nil)
+	assert.True(t, IsErrSSHCertificateInvalid(err))
+
+	// Certificates signed by an untrusted authority
+	_, _, err = CheckSSHCertificate(newTestSSHCertificate(t, newTestSSHSigner(t), "user2"), nil)
+	assert.True(t, IsErrSSHCertificateInvalid(err))
+
+	// Expired certificates
+	cert := newTestSSHCertificate(t, ca, "user2")
+	cert.ValidBefore = uint64(time.Now().Add(-time.Minute).Unix())
+	assert.NoError(t, cert.SignCert(rand.Reader, ca))
+	_, _, err = CheckSSHCertificate(cert, nil)
+	assert.True(t, IsErrSSHCertificateInvalid(err))
+
+	// Tampered certificates
+	cert = newTestSSHCertificate(t, ca, "user2")
+	cert.ValidPrincipals = []string{"user1"}
+	_, _, err = CheckSSHCertificate(cert, nil)
+	assert.True(t, IsErrSSHCertificateInvalid(err))
+
+	// Host certificates
+	cert = newTestSSHCertificate(t, ca, "user2")
+	cert.CertType = ssh.HostCert
+	assert.NoError(t, cert.SignCert(rand.Reader, ca))
+	_, _, err = CheckSSHCertificate(cert, nil)
+	assert.True(t, IsErrSSHCertificateInvalid(err))
+
+	// Source address restrictions
+	cert = newTestSSHCertificate(t, ca, "user2")
+	cert.CriticalOptions = map[string]string{"source-address": "10.0.0.0/8,192.168.1.1"}
+	assert.NoError(t, cert.SignCert(rand.Reader, ca))
+	_, _, err = CheckSSHCertificate(cert, &net.TCPAddr{IP: net.ParseIP("10.1.2.3"), Port: 2222})
+	assert.NoError(t, err)
+	_, _, err = CheckSSHCertificate(cert, &net.TCPAddr{IP: net.ParseIP("192.168.1.1"), Port: 2222})
+	assert.NoError(t, err)
+	_, _, err = CheckSSHCertificate(cert, &net.TCPAddr{IP: net.ParseIP("172.16.0.1"), Port: 2222})
+	assert.True(t, IsErrSSHCertificateInvalid(err))
+}
@@ -24,10 +24,12 @@ type AccessToken struct {
 	Token          string `xorm:"-"`
 	TokenHash      string `xorm:"UNIQUE"` // sha256 of token
 	TokenSalt      string
-	TokenLastEight string `xorm:"token_last_eight"`
+	TokenLastEight string           `xorm:"token_last_eight"`
+	Scope          AccessTokenScope `xorm:"NOT NULL DEFAULT 'all'"`
 
 	CreatedUnix       util.TimeStamp `xorm:"INDEX created"`
 	UpdatedUnix       util.TimeStamp `xorm:"INDEX updated"`
+	ExpiresUnix       util.TimeStamp // zero means the token never expires
 	HasRecentActivity bool           `xorm:"-"`
 	HasUsed           bool           `xorm:"-"`
 }
@@ -38,6 +40,11 @@ func (t *AccessToken) AfterLoad() {
 	t.HasRecentActivity = t.UpdatedUnix.AddDuration(7*24*time.Hour) > util.TimeStampNow()
 }
 
+// IsExpired returns true if the token has an expiry date in the past
+func (t *AccessToken) IsExpired() bool {
+	return t.ExpiresUnix > 0 && t.ExpiresUnix <= util.TimeStampNow()
+}
+
 // NewAccessToken creates new access token.
 func NewAccessToken(t *AccessToken) error {
 	salt, err := generate.GetRandomString(10)
@@ -48,11 +55,14 @@ func NewAccessToken(t *AccessToken) error {
 	t.Token = base.EncodeSha1(gouuid.NewV4().String())
 	t.TokenHash = hashToken(t.Token, t.TokenSalt)
 	t.TokenLastEight = t.Token[len(t.Token)-8:]
+	if t.Scope == "" {
+		t.Scope = AccessTokenScopeAll
+	}
 	_, err = x.Insert(t)
 	return err
 }
 
-// GetAccessTokenBySHA returns access token by given token value
+// GetAccessTokenBySHA returns access token by given token value, expired tokens do not exist
 func GetAccessTokenBySHA(token string) (*AccessToken, error) {
 	if token == "" {
 		return nil, ErrAccessTokenEmpty{}
@@ -71,6 +81,9 @@ func GetAccessTokenBySHA(token string) (*AccessToken, error) {
 	for _, t := range tokens {
 		tempHash := hashToken(token, t.TokenSalt)
 		if subtle.ConstantTimeCompare([]byte(t.TokenHash), []byte(tempHash)) == 1 {
+			if t.IsExpired() {
+				return nil, ErrAccessTokenNotExist{token}
+			}
 			return &t, nil
 		}
 	}
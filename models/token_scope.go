@@ -0,0 +1,157 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"strings"
+	"unicode"
+)
+
+// AccessTokenScope is a comma separated set of the permissions granted to an access token or an OAuth2 grant
+type AccessTokenScope string
+
+// The scopes an access token can have
+const (
+	// AccessTokenScopeAll grants the full power of the user, all tokens created before scopes existed have it
+	AccessTokenScopeAll AccessTokenScope = "all"
+	// AccessTokenScopeRepoRead grants read access to repositories, their code, issues and pull requests
+	AccessTokenScopeRepoRead AccessTokenScope = "repo:read"
+	// AccessTokenScopeIssue grants write access to issues, pull requests, labels, milestones and projects
+	AccessTokenScopeIssue AccessTokenScope = "issue"
+	// AccessTokenScopeRepoWrite grants write access to repositories and their settings
+	AccessTokenScopeRepoWrite AccessTokenScope = "repo:write"
+	// AccessTokenScopePackage grants reading and publishing packages. There is no package registry yet,
+	// the scope is accepted so that tokens for publishing jobs keep working once there is one.
+	// It is included in no scope but all and includes no other scope.
+	AccessTokenScopePackage AccessTokenScope = "package"
+	// AccessTokenScopeAdminOrg grants write access to organizations and their teams
+	AccessTokenScopeAdminOrg AccessTokenScope = "admin:org"
+	// AccessTokenScopeUser grants access to the account settings of the user
+	AccessTokenScopeUser AccessTokenScope = "user"
+	// AccessTokenScopeSudo grants the site administrator endpoints and acting as another user
+	AccessTokenScopeSudo AccessTokenScope = "sudo"
+)
+
+// AccessTokenScopes lists all single scopes in the order they are shown
+var AccessTokenScopes = []AccessTokenScope{
+	AccessTokenScopeAll,
+	AccessTokenScopeRepoRead,
+	AccessTokenScopeIssue,
+	AccessTokenScopeRepoWrite,
+	AccessTokenScopePackage,
+	AccessTokenScopeAdminOrg,
+	AccessTokenScopeUser,
+	AccessTokenScopeSudo,
+}
+
+// accessTokenScopeImplications lists the scopes included in a broader scope
+var accessTokenScopeImplications = map[AccessTokenScope][]AccessTokenScope{
+	AccessTokenScopeAll:       AccessTokenScopes[1:],
+	AccessTokenScopeRepoWrite: {AccessTokenScopeIssue, AccessTokenScopeRepoRead},
+	AccessTokenScopeIssue:     {AccessTokenScopeRepoRead},
+}
+
+func isAccessTokenScope(scope AccessTokenScope) bool {
+	for _, s := range AccessTokenScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseAccessTokenScope normalizes a comma or space separated list of scopes,
+// it returns an ErrInvalidAccessTokenScope for unknown scopes.
+func ParseAccessTokenScope(scope string) (AccessTokenScope, error) {
+	return parseAccessTokenScope(scope, true)
+}
+
+// ParseOAuth2Scope normalizes the scope requested by an OAuth2 client. Unknown scopes are
+// ignored as RFC 6749 allows to grant a different scope, no known scope means all scopes.
+// An OpenID Connect request without a known scope only signs the user in and gets no scope.
+func ParseOAuth2Scope(scope string) AccessTokenScope {
+	s, _ := parseAccessTokenScope(scope, false)
+	if s == "" && ParseOpenIDScope(scope) == "" {
+		return AccessTokenScopeAll
+	}
+	return s
+}
+
+func parseAccessTokenScope(scope string, strict bool) (AccessTokenScope, error) {
+	requested := make(map[AccessTokenScope]bool)
+	for _, field := range strings.FieldsFunc(scope, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	}) {
+		s := AccessTokenScope(strings.ToLower(field))
+		if !isAccessTokenScope(s) {
+			if strict {
+				return "", ErrInvalidAccessTokenScope{field}
+			}
+			continue
+		}
+		requested[s] = true
+	}
+
+	scopes := make([]string, 0, len(requested))
+	for _, s := range AccessTokenScopes {
+		if requested[s] {
+			scopes = append(scopes, string(s))
+		}
+	}
+	return AccessTokenScope(strings.Join(scopes, ",")), nil
+}
+
+// LocaleKey returns the locale key of the description of a single scope
+func (s AccessTokenScope) LocaleKey() string {
+	return "settings.token_scope_" + strings.Replace(string(s), ":", "_", -1)
+}
+
+// List returns the single scopes of the set
+func (s AccessTokenScope) List() []AccessTokenScope {
+	if s == "" {
+		return nil
+	}
+	fields := strings.Split(string(s), ",")
+	scopes := make([]AccessTokenScope, len(fields))
+	for i := range fields {
+		scopes[i] = AccessTokenScope(fields[i])
+	}
+	return scopes
+}
+
+// Has returns true if the set includes the scope, either directly or through a broader scope
+func (s AccessTokenScope) Has(scope AccessTokenScope) bool {
+	for _, granted := range s.List() {
+		if granted == scope {
+			return true
+		}
+		for _, implied := range accessTokenScopeImplications[granted] {
+			if implied == scope {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasAny returns true if the set includes at least one of the scopes
+func (s AccessTokenScope) HasAny(scopes ...AccessTokenScope) bool {
+	for _, scope := range scopes {
+		if s.Has(scope) {
+			return true
+		}
+	}
+	return false
+}
+
+// Contains returns true if the set includes all scopes of another set
+func (s AccessTokenScope) Contains(other AccessTokenScope) bool {
+	for _, scope := range other.List() {
+		if !s.Has(scope) {
+			return false
+		}
+	}
+	return true
+}
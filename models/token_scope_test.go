@@ -0,0 +1,56 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAccessTokenScope(t *testing.T) {
+	for scope, expected := range map[string]AccessTokenScope{
+		"":                          "",
+		"repo:read":                 AccessTokenScopeRepoRead,
+		"user, repo:read user":      "repo:read,user",
+		"SUDO,repo:write,admin:org": "repo:write,admin:org,sudo",
+		"user package":              "package,user",
+	} {
+		s, err := ParseAccessTokenScope(scope)
+		assert.NoError(t, err)
+		assert.EqualValues(t, expected, s, scope)
+	}
+
+	_, err := ParseAccessTokenScope("repo:read,repo:delete")
+	assert.True(t, IsErrInvalidAccessTokenScope(err))
+
+	assert.EqualValues(t, AccessTokenScopeAll, ParseOAuth2Scope(""))
+	assert.EqualValues(t, AccessTokenScopeAll, ParseOAuth2Scope("read"))
+	assert.EqualValues(t, "", ParseOAuth2Scope("openid profile"))
+	assert.EqualValues(t, "issue,user", ParseOAuth2Scope("openid user issue"))
+}
+
+func TestAccessTokenScope_Has(t *testing.T) {
+	for _, scope := range AccessTokenScopes {
+		assert.True(t, AccessTokenScopeAll.Has(scope), scope)
+	}
+
+	scope := AccessTokenScope("repo:write,user")
+	assert.True(t, scope.Has(AccessTokenScopeRepoRead))
+	assert.True(t, scope.Has(AccessTokenScopeIssue))
+	assert.True(t, scope.Has(AccessTokenScopeUser))
+	assert.False(t, scope.Has(AccessTokenScopeAdminOrg))
+	assert.False(t, scope.Has(AccessTokenScopeAll))
+	assert.True(t, scope.HasAny(AccessTokenScopeSudo, AccessTokenScopeUser))
+
+	assert.True(t, scope.Contains("issue,user"))
+	assert.False(t, scope.Contains("issue,sudo"))
+	assert.False(t, AccessTokenScope("").Has(AccessTokenScopeRepoRead))
+
+	// package is neither part of nor includes the repository scopes
+	assert.False(t, scope.Has(AccessTokenScopePackage))
+	assert.False(t, AccessTokenScopePackage.Has(AccessTokenScopeRepoRead))
+	assert.True(t, AccessTokenScopeAll.Contains("package,repo:write"))
+}
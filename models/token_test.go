@@ -7,6 +7,8 @@ package models
 import (
 	"testing"
 
+	"github.com/masoodkamyab/gitea/modules/util"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -18,6 +20,7 @@ func TestNewAccessToken(t *testing.T) {
 	}
 	assert.NoError(t, NewAccessToken(token))
 	AssertExistsAndLoadBean(t, token)
+	assert.Equal(t, AccessTokenScopeAll, token.Scope)
 
 	invalidToken := &AccessToken{
 		ID:   token.ID, // duplicate
@@ -43,6 +46,12 @@ func TestGetAccessTokenBySHA(t *testing.T) {
 	_, err = GetAccessTokenBySHA("")
 	assert.Error(t, err)
 	assert.True(t, IsErrAccessTokenEmpty(err))
+
+	// expired tokens cannot be used
+	token.ExpiresUnix = util.TimeStampNow().Add(-60)
+	assert.NoError(t, UpdateAccessToken(token))
+	_, err = GetAccessTokenBySHA("d2c6c1ba3890b309189a8e618c72a162e4efbf36")
+	assert.True(t, IsErrAccessTokenNotExist(err))
 }
 
 func TestListAccessTokens(t *testing.T) {
@@ -10,7 +10,6 @@ import (
 	"crypto/md5"
 	"crypto/rand"
 	"crypto/sha256"
-	"crypto/subtle"
 	"encoding/base64"
 	"errors"
 	"fmt"
@@ -24,27 +23,49 @@ import (
 	"github.com/masoodkamyab/gitea/modules/util"
 )
 
+// TwoFactorScratchCodeCount is the number of scratch codes generated at once.
+const TwoFactorScratchCodeCount = 10
+
 // TwoFactor represents a two-factor authentication token.
 type TwoFactor struct {
 	ID               int64 `xorm:"pk autoincr"`
 	UID              int64 `xorm:"UNIQUE"`
 	Secret           string
 	ScratchSalt      string
-	ScratchHash      string
 	LastUsedPasscode string         `xorm:"VARCHAR(10)"`
 	CreatedUnix      util.TimeStamp `xorm:"INDEX created"`
 	UpdatedUnix      util.TimeStamp `xorm:"INDEX updated"`
+
+	scratchHashes []string `xorm:"-"`
+}
+
+// TwoFactorScratchCode represents a single-use code to sign in without the
+// second factor, hashed with the scratch salt of the token.
+type TwoFactorScratchCode struct {
+	ID          int64          `xorm:"pk autoincr"`
+	UID         int64          `xorm:"INDEX"`
+	Hash        string         `xorm:"INDEX"`
+	CreatedUnix util.TimeStamp `xorm:"created"`
 }
 
-// GenerateScratchToken recreates the scratch token the user is using.
-func (t *TwoFactor) GenerateScratchToken() (string, error) {
-	token, err := generate.GetRandomString(8)
+// GenerateScratchCodes recreates the scratch codes the user is using, they
+// replace the former ones once the token is saved.
+func (t *TwoFactor) GenerateScratchCodes() ([]string, error) {
+	salt, err := generate.GetRandomString(10)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	codes := make([]string, TwoFactorScratchCodeCount)
+	hashes := make([]string, TwoFactorScratchCodeCount)
+	for i := range codes {
+		if codes[i], err = generate.GetRandomString(8); err != nil {
+			return nil, err
+		}
+		hashes[i] = hashToken(codes[i], salt)
 	}
-	t.ScratchSalt, _ = generate.GetRandomString(10)
-	t.ScratchHash = hashToken(token, t.ScratchSalt)
-	return token, nil
+	t.ScratchSalt = salt
+	t.scratchHashes = hashes
+	return codes, nil
 }
 
 func hashToken(token, salt string) string {
@@ -52,13 +73,36 @@ func hashToken(token, salt string) string {
 	return fmt.Sprintf("%x", tempHash)
 }
 
-// VerifyScratchToken verifies if the specified scratch token is valid.
-func (t *TwoFactor) VerifyScratchToken(token string) bool {
-	if len(token) == 0 {
-		return false
+func (t *TwoFactor) saveScratchCodes(e Engine) error {
+	if t.scratchHashes == nil {
+		return nil
+	}
+	if _, err := e.Delete(&TwoFactorScratchCode{UID: t.UID}); err != nil {
+		return err
+	}
+	codes := make([]*TwoFactorScratchCode, len(t.scratchHashes))
+	for i, hash := range t.scratchHashes {
+		codes[i] = &TwoFactorScratchCode{UID: t.UID, Hash: hash}
+	}
+	if _, err := e.Insert(&codes); err != nil {
+		return err
 	}
-	tempHash := hashToken(token, t.ScratchSalt)
-	return subtle.ConstantTimeCompare([]byte(t.ScratchHash), []byte(tempHash)) == 1
+	t.scratchHashes = nil
+	return nil
+}
+
+// UseScratchCode verifies the specified scratch code and invalidates it.
+func (t *TwoFactor) UseScratchCode(code string) (bool, error) {
+	if len(code) == 0 {
+		return false, nil
+	}
+	cnt, err := x.Delete(&TwoFactorScratchCode{UID: t.UID, Hash: hashToken(code, t.ScratchSalt)})
+	return cnt > 0, err
+}
+
+// CountScratchCodes returns the number of scratch codes the user has left.
+func (t *TwoFactor) CountScratchCodes() (int64, error) {
+	return x.Count(&TwoFactorScratchCode{UID: t.UID})
 }
 
 func (t *TwoFactor) getEncryptionKey() []byte {
@@ -129,14 +173,36 @@ func aesDecrypt(key, text []byte) ([]byte, error) {
 
 // NewTwoFactor creates a new two-factor authentication token.
 func NewTwoFactor(t *TwoFactor) error {
-	_, err := x.Insert(t)
-	return err
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if _, err := sess.Insert(t); err != nil {
+		return err
+	}
+	if err := t.saveScratchCodes(sess); err != nil {
+		return err
+	}
+	return sess.Commit()
 }
 
 // UpdateTwoFactor updates a two-factor authentication token.
 func UpdateTwoFactor(t *TwoFactor) error {
-	_, err := x.ID(t.ID).AllCols().Update(t)
-	return err
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if _, err := sess.ID(t.ID).AllCols().Update(t); err != nil {
+		return err
+	}
+	if err := t.saveScratchCodes(sess); err != nil {
+		return err
+	}
+	return sess.Commit()
 }
 
 // GetTwoFactorByUID returns the two-factor authentication token associated with
@@ -154,7 +220,13 @@ func GetTwoFactorByUID(uid int64) (*TwoFactor, error) {
 
 // DeleteTwoFactorByID deletes two-factor authentication token by given ID.
 func DeleteTwoFactorByID(id, userID int64) error {
-	cnt, err := x.ID(id).Delete(&TwoFactor{
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	cnt, err := sess.ID(id).Delete(&TwoFactor{
 		UID: userID,
 	})
 	if err != nil {
@@ -162,5 +234,147 @@ func DeleteTwoFactorByID(id, userID int64) error {
 	} else if cnt != 1 {
 		return ErrTwoFactorNotEnrolled{userID}
 	}
-	return nil
+	if _, err = sess.Delete(&TwoFactorScratchCode{UID: userID}); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// ResetTwoFactor removes all second factors of the user, who has to enroll
+// again within a new grace period when two-factor authentication is required.
+func ResetTwoFactor(u *User) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if err := deleteBeans(sess,
+		&TwoFactor{UID: u.ID},
+		&TwoFactorScratchCode{UID: u.ID},
+		&WebAuthnCredential{UserID: u.ID},
+	); err != nil {
+		return err
+	}
+	u.TwoFactorDeadlineUnix = 0
+	if err := updateUserCols(sess, u, "two_factor_deadline_unix"); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+func isTwoFactorEnrolled(e Engine, uid int64) (bool, error) {
+	cnt, err := e.Count(&TwoFactor{UID: uid})
+	return cnt > 0, err
+}
+
+// IsTwoFactorEnrolled returns whether the user has enrolled into two-factor authentication.
+func (u *User) IsTwoFactorEnrolled() (bool, error) {
+	return isTwoFactorEnrolled(x, u.ID)
+}
+
+// StartTwoFactorGracePeriod starts the grace period the user has to enroll into
+// the two-factor authentication required by the site, unless already started.
+func (u *User) StartTwoFactorGracePeriod() error {
+	if u.TwoFactorDeadlineUnix > 0 {
+		return nil
+	}
+	u.TwoFactorDeadlineUnix = util.TimeStampNow().AddDuration(setting.TwoFactorGracePeriod)
+	return UpdateUserCols(u, "two_factor_deadline_unix")
+}
+
+// SetRequireTwoFactor changes whether the members of the organization must enroll
+// into two-factor authentication, starting their grace period.
+func (u *User) SetRequireTwoFactor(require bool) {
+	if u.RequireTwoFactor == require {
+		return
+	}
+	u.RequireTwoFactor = require
+	u.TwoFactorDeadlineUnix = 0
+	if require {
+		u.TwoFactorDeadlineUnix = util.TimeStampNow().AddDuration(setting.TwoFactorGracePeriod)
+	}
+}
+
+// twoFactorDeadline returns the end of the grace period of a user to enroll into the
+// two-factor authentication an organization requires. Members who join after the
+// organization started to require it get a grace period of their own.
+func twoFactorDeadline(e Engine, org, user *User) (util.TimeStamp, error) {
+	ou := new(OrgUser)
+	has, err := e.Where("uid = ? AND org_id = ?", user.ID, org.ID).Get(ou)
+	if err != nil {
+		return 0, err
+	}
+	deadline := org.TwoFactorDeadlineUnix
+	if has && ou.CreatedUnix > 0 {
+		if memberDeadline := ou.CreatedUnix.AddDuration(setting.TwoFactorGracePeriod); memberDeadline > deadline {
+			deadline = memberDeadline
+		}
+	}
+	return deadline, nil
+}
+
+func isTwoFactorBlocked(e Engine, org, user *User) (bool, error) {
+	if !org.RequireTwoFactor || util.TimeStampNow() <= org.TwoFactorDeadlineUnix {
+		return false, nil
+	}
+	deadline, err := twoFactorDeadline(e, org, user)
+	if err != nil || util.TimeStampNow() <= deadline {
+		return false, err
+	}
+	enrolled, err := isTwoFactorEnrolled(e, user.ID)
+	return !enrolled, err
+}
+
+// IsTwoFactorBlocked returns whether the organization requires two-factor
+// authentication the user did not enroll into within the grace period.
+func IsTwoFactorBlocked(org, user *User) (bool, error) {
+	return isTwoFactorBlocked(x, org, user)
+}
+
+func isSiteTwoFactorBlocked(e Engine, user *User) (bool, error) {
+	if !setting.TwoFactorRequired || user.TwoFactorDeadlineUnix == 0 || util.TimeStampNow() <= user.TwoFactorDeadlineUnix {
+		return false, nil
+	}
+	enrolled, err := isTwoFactorEnrolled(e, user.ID)
+	return !enrolled, err
+}
+
+// IsSiteTwoFactorBlocked returns whether the site requires two-factor
+// authentication the user did not enroll into within the grace period. The
+// grace period of users who did not enroll is started unless already started.
+func IsSiteTwoFactorBlocked(user *User) (bool, error) {
+	if !setting.TwoFactorRequired {
+		return false, nil
+	}
+	if user.TwoFactorDeadlineUnix == 0 {
+		if enrolled, err := user.IsTwoFactorEnrolled(); err != nil || enrolled {
+			return false, err
+		}
+		if err := user.StartTwoFactorGracePeriod(); err != nil {
+			return false, err
+		}
+	}
+	return isSiteTwoFactorBlocked(x, user)
+}
+
+// GetTwoFactorStatus returns whether each of the users enrolled into two-factor authentication.
+func GetTwoFactorStatus(users []*User) (map[int64]bool, error) {
+	status := make(map[int64]bool, len(users))
+	if len(users) == 0 {
+		return status, nil
+	}
+	uids := make([]int64, len(users))
+	for i, u := range users {
+		uids[i] = u.ID
+		status[u.ID] = false
+	}
+	tokens := make([]*TwoFactor, 0, len(users))
+	if err := x.In("uid", uids).Cols("uid").Find(&tokens); err != nil {
+		return nil, err
+	}
+	for _, t := range tokens {
+		status[t.UID] = true
+	}
+	return status, nil
 }
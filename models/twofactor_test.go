@@ -0,0 +1,274 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/masoodkamyab/gitea/modules/setting"
+	"github.com/masoodkamyab/gitea/modules/structs"
+	"github.com/masoodkamyab/gitea/modules/util"
+	"github.com/masoodkamyab/gitea/modules/webauthn"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func enrollTwoFactor(t *testing.T, uid int64) (*TwoFactor, []string) {
+	twofa := &TwoFactor{UID: uid}
+	assert.NoError(t, twofa.SetSecret("JBSWY3DPEHPK3PXP"))
+	codes, err := twofa.GenerateScratchCodes()
+	assert.NoError(t, err)
+	assert.NoError(t, NewTwoFactor(twofa))
+	return twofa, codes
+}
+
+func TestTwoFactorScratchCodes(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	twofa, codes := enrollTwoFactor(t, 2)
+	assert.Len(t, codes, TwoFactorScratchCodeCount)
+	left, err := twofa.CountScratchCodes()
+	assert.NoError(t, err)
+	assert.EqualValues(t, TwoFactorScratchCodeCount, left)
+
+	// Each code can be used once
+	ok, err := twofa.UseScratchCode(codes[3])
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = twofa.UseScratchCode(codes[3])
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = twofa.UseScratchCode("")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	left, err = twofa.CountScratchCodes()
+	assert.NoError(t, err)
+	assert.EqualValues(t, TwoFactorScratchCodeCount-1, left)
+
+	// Codes of other users are not accepted
+	other, otherCodes := enrollTwoFactor(t, 4)
+	ok, err = twofa.UseScratchCode(otherCodes[0])
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// Regenerated codes replace the former ones
+	newCodes, err := twofa.GenerateScratchCodes()
+	assert.NoError(t, err)
+	assert.NoError(t, UpdateTwoFactor(twofa))
+	ok, err = twofa.UseScratchCode(codes[0])
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = twofa.UseScratchCode(newCodes[0])
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	assert.NoError(t, DeleteTwoFactorByID(twofa.ID, 2))
+	AssertNotExistsBean(t, &TwoFactorScratchCode{UID: 2})
+	AssertExistsAndLoadBean(t, &TwoFactorScratchCode{UID: other.UID})
+}
+
+func TestResetTwoFactor(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	enrollTwoFactor(t, 1)
+	user := AssertExistsAndLoadBean(t, &User{ID: 1}).(*User)
+	user.TwoFactorDeadlineUnix = util.TimeStampNow()
+	assert.NoError(t, UpdateUserCols(user, "two_factor_deadline_unix"))
+
+	assert.NoError(t, ResetTwoFactor(user))
+	enrolled, err := user.IsTwoFactorEnrolled()
+	assert.NoError(t, err)
+	assert.False(t, enrolled)
+	AssertNotExistsBean(t, &TwoFactorScratchCode{UID: 1})
+	AssertNotExistsBean(t, &WebAuthnCredential{UserID: 1})
+	AssertExistsAndLoadBean(t, &User{ID: 1}, "two_factor_deadline_unix = 0")
+}
+
+func TestStartTwoFactorGracePeriod(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	user := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	assert.NoError(t, user.StartTwoFactorGracePeriod())
+	deadline := user.TwoFactorDeadlineUnix
+	assert.True(t, deadline > util.TimeStampNow())
+	AssertExistsAndLoadBean(t, &User{ID: 2, TwoFactorDeadlineUnix: deadline})
+
+	// The grace period is only started once
+	user.TwoFactorDeadlineUnix = 1
+	assert.NoError(t, user.StartTwoFactorGracePeriod())
+	assert.EqualValues(t, 1, user.TwoFactorDeadlineUnix)
+}
+
+func TestIsTwoFactorBlocked(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	org := AssertExistsAndLoadBean(t, &User{ID: 3}).(*User)
+	user := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 3}).(*Repository)
+
+	blocked, err := IsTwoFactorBlocked(org, user)
+	assert.NoError(t, err)
+	assert.False(t, blocked)
+
+	// Members are not blocked during the grace period
+	org.SetRequireTwoFactor(true)
+	assert.True(t, org.TwoFactorDeadlineUnix > util.TimeStampNow())
+	assert.NoError(t, UpdateUser(org))
+	blocked, err = IsTwoFactorBlocked(org, user)
+	assert.NoError(t, err)
+	assert.False(t, blocked)
+
+	org.TwoFactorDeadlineUnix = util.TimeStampNow().Add(-1)
+	assert.NoError(t, UpdateUser(org))
+	blocked, err = IsTwoFactorBlocked(org, user)
+	assert.NoError(t, err)
+	assert.True(t, blocked)
+	perm, err := GetUserRepoPermission(repo, user)
+	assert.NoError(t, err)
+	assert.False(t, perm.HasAccess())
+
+	// Site admins are not subject to the policy
+	admin := AssertExistsAndLoadBean(t, &User{ID: 1}).(*User)
+	perm, err = GetUserRepoPermission(repo, admin)
+	assert.NoError(t, err)
+	assert.True(t, perm.IsOwner())
+
+	enrollTwoFactor(t, user.ID)
+	blocked, err = IsTwoFactorBlocked(org, user)
+	assert.NoError(t, err)
+	assert.False(t, blocked)
+	perm, err = GetUserRepoPermission(repo, user)
+	assert.NoError(t, err)
+	assert.True(t, perm.HasAccess())
+
+	org.SetRequireTwoFactor(false)
+	assert.EqualValues(t, 0, org.TwoFactorDeadlineUnix)
+}
+
+func TestIsTwoFactorBlocked_LateJoiner(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	org := AssertExistsAndLoadBean(t, &User{ID: 3}).(*User)
+	org.SetRequireTwoFactor(true)
+	org.TwoFactorDeadlineUnix = util.TimeStampNow().Add(-1)
+	assert.NoError(t, UpdateUser(org))
+
+	// Members who join after the grace period of the organization get their own
+	user := AssertExistsAndLoadBean(t, &User{ID: 5}).(*User)
+	assert.NoError(t, AddOrgUser(org.ID, user.ID))
+	blocked, err := IsTwoFactorBlocked(org, user)
+	assert.NoError(t, err)
+	assert.False(t, blocked)
+
+	ou := AssertExistsAndLoadBean(t, &OrgUser{OrgID: org.ID, UID: user.ID}).(*OrgUser)
+	ou.CreatedUnix = util.TimeStampNow().AddDuration(-setting.TwoFactorGracePeriod).Add(-1)
+	_, err = x.Exec("UPDATE org_user SET created_unix = ? WHERE id = ?", ou.CreatedUnix, ou.ID)
+	assert.NoError(t, err)
+	blocked, err = IsTwoFactorBlocked(org, user)
+	assert.NoError(t, err)
+	assert.True(t, blocked)
+
+	// Members who joined before it was recorded share the grace period of the organization
+	member := AssertExistsAndLoadBean(t, &User{ID: 4}).(*User)
+	blocked, err = IsTwoFactorBlocked(org, member)
+	assert.NoError(t, err)
+	assert.True(t, blocked)
+
+	// Blocked members keep the access every signed in user has
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 32}).(*Repository)
+	org.Visibility = structs.VisibleTypeLimited
+	assert.NoError(t, UpdateUserCols(org, "visibility"))
+	perm, err := GetUserRepoPermission(repo, member)
+	assert.NoError(t, err)
+	assert.EqualValues(t, AccessModeRead, perm.AccessMode)
+
+	org.Visibility = structs.VisibleTypePrivate
+	assert.NoError(t, UpdateUserCols(org, "visibility"))
+	repo = AssertExistsAndLoadBean(t, &Repository{ID: 32}).(*Repository)
+	perm, err = GetUserRepoPermission(repo, member)
+	assert.NoError(t, err)
+	assert.EqualValues(t, AccessModeNone, perm.AccessMode)
+}
+
+func TestGetTwoFactorStatus(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	enrollTwoFactor(t, 4)
+	users := []*User{
+		AssertExistsAndLoadBean(t, &User{ID: 2}).(*User),
+		AssertExistsAndLoadBean(t, &User{ID: 4}).(*User),
+	}
+	status, err := GetTwoFactorStatus(users)
+	assert.NoError(t, err)
+	assert.Equal(t, map[int64]bool{2: false, 4: true}, status)
+
+	found, _, err := SearchUsers(&SearchUserOptions{
+		Type:              UserTypeIndividual,
+		Page:              1,
+		PageSize:          10,
+		TwoFactorEnrolled: util.OptionalBoolTrue,
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, found, 1) {
+		assert.EqualValues(t, 4, found[0].ID)
+	}
+}
+
+func TestResetTwoFactorWebAuthn(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	authenticator := webauthn.NewSoftAuthenticator("http://localhost:3000")
+	id, pub, err := authenticator.RegisterU2F("http://localhost:3000")
+	assert.NoError(t, err)
+	publicKey, err := webauthn.MarshalECDSAPublicKey(pub)
+	assert.NoError(t, err)
+	_, err = CreateCredential(2, "Key", &webauthn.Credential{ID: id, PublicKey: publicKey})
+	assert.NoError(t, err)
+
+	user := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	assert.NoError(t, ResetTwoFactor(user))
+	AssertNotExistsBean(t, &WebAuthnCredential{UserID: 2})
+	AssertExistsAndLoadBean(t, &WebAuthnCredential{UserID: 1})
+}
+
+func TestIsSiteTwoFactorBlocked(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	defer func(required bool) {
+		setting.TwoFactorRequired = required
+	}(setting.TwoFactorRequired)
+	setting.TwoFactorRequired = true
+
+	user := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 2}).(*Repository)
+
+	// Users are not blocked during the grace period, which starts on first use
+	blocked, err := IsSiteTwoFactorBlocked(user)
+	assert.NoError(t, err)
+	assert.False(t, blocked)
+	assert.True(t, user.TwoFactorDeadlineUnix > util.TimeStampNow())
+
+	user.TwoFactorDeadlineUnix = util.TimeStampNow().Add(-1)
+	assert.NoError(t, UpdateUserCols(user, "two_factor_deadline_unix"))
+	blocked, err = IsSiteTwoFactorBlocked(user)
+	assert.NoError(t, err)
+	assert.True(t, blocked)
+
+	// Blocked users get the access of anonymous users, even to their own repositories
+	perm, err := GetUserRepoPermission(repo, user)
+	assert.NoError(t, err)
+	assert.False(t, perm.HasAccess())
+	perm, err = GetUserRepoPermission(AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository), user)
+	assert.NoError(t, err)
+	assert.Equal(t, AccessModeRead, perm.AccessMode)
+
+	enrollTwoFactor(t, user.ID)
+	blocked, err = IsSiteTwoFactorBlocked(user)
+	assert.NoError(t, err)
+	assert.False(t, blocked)
+	perm, err = GetUserRepoPermission(repo, user)
+	assert.NoError(t, err)
+	assert.True(t, perm.IsOwner())
+}
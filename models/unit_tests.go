@@ -58,6 +58,10 @@ func MainTest(m *testing.M, pathToGiteaRoot string) {
 	if err != nil {
 		fatalTestError("TempDir: %v\n", err)
 	}
+	setting.SSH.RootPath, err = ioutil.TempDir(os.TempDir(), "ssh")
+	if err != nil {
+		fatalTestError("TempDir: %v\n", err)
+	}
 	setting.AppWorkPath = pathToGiteaRoot
 	setting.StaticRootPath = pathToGiteaRoot
 	setting.GravatarSourceURL, err = url.Parse("https://secure.gravatar.com/avatar/")
@@ -72,6 +76,9 @@ func MainTest(m *testing.M, pathToGiteaRoot string) {
 	if err = removeAllWithRetry(setting.AppDataPath); err != nil {
 		fatalTestError("os.RemoveAll: %v\n", err)
 	}
+	if err = removeAllWithRetry(setting.SSH.RootPath); err != nil {
+		fatalTestError("os.RemoveAll: %v\n", err)
+	}
 	os.Exit(exitStatus)
 }
 
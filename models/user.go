@@ -118,6 +118,12 @@ type User struct {
 	AllowCreateOrganization bool `xorm:"DEFAULT true"`
 	ProhibitLogin           bool `xorm:"NOT NULL DEFAULT false"`
 
+	// Two-factor authentication policy. TwoFactorDeadlineUnix ends the grace period
+	// to enroll of the user when the site requires two-factor authentication, or of
+	// the members of an organization with RequireTwoFactor
+	RequireTwoFactor      bool           `xorm:"NOT NULL DEFAULT false"`
+	TwoFactorDeadlineUnix util.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+
 	// Avatar
 	Avatar          string `xorm:"VARCHAR(2048) NOT NULL"`
 	AvatarEmail     string `xorm:"NOT NULL"`
@@ -229,6 +235,11 @@ func (u *User) IsOAuth2() bool {
 	return u.LoginType == LoginOAuth2
 }
 
+// IsSAML returns true if user login type is LoginSAML.
+func (u *User) IsSAML() bool {
+	return u.LoginType == LoginSAML
+}
+
 // HasForkedRepo checks if user has already forked a repository with given ID.
 func (u *User) HasForkedRepo(repoID int64) bool {
 	_, has := HasForkedRepo(u.ID, repoID)
@@ -1067,6 +1078,10 @@ func deleteUser(e *xorm.Session, u *User) error {
 		&TeamUser{UID: u.ID},
 		&Collaboration{UserID: u.ID},
 		&Stopwatch{UserID: u.ID},
+		&WebAuthnCredential{UserID: u.ID},
+		&TwoFactor{UID: u.ID},
+		&TwoFactorScratchCode{UID: u.ID},
+		&UserSession{UID: u.ID},
 	); err != nil {
 		return fmt.Errorf("deleteBeans: %v", err)
 	}
@@ -1373,6 +1388,8 @@ type SearchUserOptions struct {
 	PageSize      int   // Can be smaller than or equal to setting.UI.ExplorePagingNum
 	IsActive      util.OptionalBool
 	SearchByEmail bool // Search by email as well as username/full name
+
+	TwoFactorEnrolled util.OptionalBool
 }
 
 func (opts *SearchUserOptions) toConds() builder.Cond {
@@ -1421,6 +1438,14 @@ func (opts *SearchUserOptions) toConds() builder.Cond {
 		cond = cond.And(builder.Eq{"is_active": opts.IsActive.IsTrue()})
 	}
 
+	if !opts.TwoFactorEnrolled.IsNone() {
+		enrolledCond := builder.In("id", builder.Select("uid").From("two_factor"))
+		if opts.TwoFactorEnrolled.IsFalse() {
+			enrolledCond = builder.NotIn("id", builder.Select("uid").From("two_factor"))
+		}
+		cond = cond.And(enrolledCond)
+	}
+
 	return cond
 }
 
@@ -1647,7 +1672,12 @@ func SyncExternalUsers() {
 				return
 			}
 
-			sr := s.LDAP().SearchEntries()
+			sr, err := s.LDAP().SearchEntries()
+			if err != nil {
+				// Without the entries every user would look missing and get deactivated
+				log.Error("SyncExternalUsers[%s]: Error searching LDAP entries: %v", s.Name, err)
+				continue
+			}
 			for _, su := range sr {
 				if len(su.Username) == 0 {
 					continue
@@ -1723,6 +1753,13 @@ func SyncExternalUsers() {
 						}
 					}
 				}
+
+				// Synchronize team memberships if groups are mapped to teams
+				if usr.ID > 0 {
+					if err = syncLDAPGroupsToTeams(s, usr, su.Groups); err != nil {
+						log.Error("SyncExternalUsers[%s]: Error synchronizing teams of user %s: %v", s.Name, usr.Name, err)
+					}
+				}
 			}
 
 			// Rewrite authorized_keys file if LDAP Public SSH Key attribute is set and any key was added or removed
@@ -1751,6 +1788,11 @@ func SyncExternalUsers() {
 						if err != nil {
 							log.Error("SyncExternalUsers[%s]: Error deactivating user %s: %v", s.Name, usr.Name, err)
 						}
+
+						// A user in no group leaves the mapped teams if removal is enabled
+						if err = syncLDAPGroupsToTeams(s, usr, nil); err != nil {
+							log.Error("SyncExternalUsers[%s]: Error synchronizing teams of user %s: %v", s.Name, usr.Name, err)
+						}
 					}
 				}
 			}
@@ -0,0 +1,234 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/masoodkamyab/gitea/modules/generate"
+	"github.com/masoodkamyab/gitea/modules/log"
+	"github.com/masoodkamyab/gitea/modules/setting"
+	"github.com/masoodkamyab/gitea/modules/util"
+
+	"xorm.io/builder"
+)
+
+// userSessionSeenInterval is the number of seconds the last seen time of a
+// session is at most behind, to not write to the database on every request.
+const userSessionSeenInterval = 60
+
+// UserSession represents a browser session a user is signed in with.
+// Only a hash of the session ID is stored, the session data itself is kept
+// by the configured session provider. A remembered session has a token the
+// browser signs in with again after the session provider dropped the session,
+// of which likewise only a hash is stored.
+type UserSession struct {
+	ID            int64          `xorm:"pk autoincr"`
+	UID           int64          `xorm:"INDEX"`
+	User          *User          `xorm:"-"`
+	SessionHash   string         `xorm:"UNIQUE VARCHAR(64)"`
+	RememberHash  string         `xorm:"INDEX VARCHAR(64)"`
+	RememberUntil util.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
+	IP            string         `xorm:"VARCHAR(64)"`
+	UserAgent     string         `xorm:"TEXT"`
+	CreatedUnix   util.TimeStamp `xorm:"INDEX created"`
+	LastSeenUnix  util.TimeStamp `xorm:"INDEX"`
+}
+
+func hashSessionID(sid string) string {
+	hash := sha256.Sum256([]byte(sid))
+	return hex.EncodeToString(hash[:])
+}
+
+// userSessionExpiry returns the time before which sessions have not been
+// used long enough for the session provider to have dropped them.
+func userSessionExpiry() util.TimeStamp {
+	return util.TimeStampNow().Add(-setting.SessionConfig.Maxlifetime - userSessionSeenInterval)
+}
+
+// activeUserSessionCond returns the condition of the sessions which are still
+// signed in or can be signed in to again with their remember token.
+func activeUserSessionCond() builder.Cond {
+	return builder.Gte{"last_seen_unix": userSessionExpiry()}.Or(builder.Gte{"remember_until": util.TimeStampNow()})
+}
+
+// IsSessionID returns whether the session has the given session ID.
+func (s *UserSession) IsSessionID(sid string) bool {
+	return s.SessionHash == hashSessionID(sid)
+}
+
+// LoadUser loads the user of the session.
+func (s *UserSession) LoadUser() (err error) {
+	if s.User == nil {
+		s.User, err = GetUserByID(s.UID)
+	}
+	return err
+}
+
+// Touch updates the last seen time and the origin of the session. The time is
+// only updated once in a while unless the origin changed.
+func (s *UserSession) Touch(ip, userAgent string) error {
+	if s.IP == ip && s.UserAgent == userAgent &&
+		util.TimeStampNow() < s.LastSeenUnix.Add(userSessionSeenInterval) {
+		return nil
+	}
+	s.IP = ip
+	s.UserAgent = userAgent
+	s.LastSeenUnix = util.TimeStampNow()
+	_, err := x.ID(s.ID).Cols("ip", "user_agent", "last_seen_unix").Update(s)
+	return err
+}
+
+// Remember creates a token to sign in to the session again for the given
+// number of days, replacing the former token of the session.
+func (s *UserSession) Remember(days int) (string, error) {
+	token, err := generate.GetRandomString(40)
+	if err != nil {
+		return "", err
+	}
+	s.RememberHash = hashSessionID(token)
+	s.RememberUntil = util.TimeStampNow().Add(int64(days) * 86400)
+	if _, err = x.ID(s.ID).Cols("remember_hash", "remember_until").Update(s); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// Resume continues the session in the browser session with given ID after
+// the user signed in again with the remember token.
+func (s *UserSession) Resume(sid, ip, userAgent string) error {
+	s.SessionHash = hashSessionID(sid)
+	s.IP = ip
+	s.UserAgent = userAgent
+	s.LastSeenUnix = util.TimeStampNow()
+	_, err := x.ID(s.ID).Cols("session_hash", "ip", "user_agent", "last_seen_unix").Update(s)
+	return err
+}
+
+// CreateUserSession records a session the given user has signed in with.
+func CreateUserSession(uid int64, sid, ip, userAgent string) (*UserSession, error) {
+	s := &UserSession{
+		UID:          uid,
+		SessionHash:  hashSessionID(sid),
+		IP:           ip,
+		UserAgent:    userAgent,
+		LastSeenUnix: util.TimeStampNow(),
+	}
+	if _, err := x.Insert(s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// GetUserSessionByID returns the session with given ID.
+func GetUserSessionByID(id int64) (*UserSession, error) {
+	s := new(UserSession)
+	if has, err := x.ID(id).Get(s); err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrUserSessionNotExist{ID: id}
+	}
+	return s, nil
+}
+
+// GetUserSessionBySessionID returns the session with given session ID.
+func GetUserSessionBySessionID(sid string) (*UserSession, error) {
+	s := new(UserSession)
+	if has, err := x.Where("session_hash = ?", hashSessionID(sid)).Get(s); err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrUserSessionNotExist{}
+	}
+	return s, nil
+}
+
+// GetUserSessionByRememberToken returns the remembered session of the given
+// user with the given remember token.
+func GetUserSessionByRememberToken(uid int64, token string) (*UserSession, error) {
+	s := new(UserSession)
+	if has, err := x.
+		Where("uid = ? AND remember_hash = ? AND remember_until >= ?", uid, hashSessionID(token), util.TimeStampNow()).
+		Get(s); err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrUserSessionNotExist{}
+	}
+	return s, nil
+}
+
+// GetUserSessionsByUID returns the active sessions of the given user, most
+// recently used first.
+func GetUserSessionsByUID(uid int64) ([]*UserSession, error) {
+	sessions := make([]*UserSession, 0, 5)
+	return sessions, x.
+		Where(builder.Eq{"uid": uid}.And(activeUserSessionCond())).
+		Desc("last_seen_unix").
+		Find(&sessions)
+}
+
+// CountUserSessions returns the number of active sessions of all users.
+func CountUserSessions() int64 {
+	count, _ := x.Where(activeUserSessionCond()).Count(new(UserSession))
+	return count
+}
+
+// UserSessions returns the active sessions of all users in given page, most
+// recently used first.
+func UserSessions(page, pageSize int) ([]*UserSession, error) {
+	sessions := make([]*UserSession, 0, pageSize)
+	if err := x.
+		Where(activeUserSessionCond()).
+		Limit(pageSize, (page-1)*pageSize).
+		Desc("last_seen_unix").
+		Find(&sessions); err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]int64, 0, len(sessions))
+	for _, s := range sessions {
+		userIDs = append(userIDs, s.UID)
+	}
+	users := make(map[int64]*User, len(userIDs))
+	if err := x.In("id", userIDs).Find(&users); err != nil {
+		return nil, err
+	}
+	for _, s := range sessions {
+		s.User = users[s.UID]
+	}
+	return sessions, nil
+}
+
+// DeleteUserSession signs the user out of the given session, its remember
+// token is forgotten with it.
+func DeleteUserSession(s *UserSession) error {
+	_, err := x.ID(s.ID).Delete(new(UserSession))
+	return err
+}
+
+// DeleteUserSessionsByUID signs the user out of all sessions, except the one
+// with the given session ID if it is not empty. Their remember tokens are
+// forgotten with them.
+func DeleteUserSessionsByUID(uid int64, exceptSID string) error {
+	_, err := x.Where("uid = ? AND session_hash <> ?", uid, hashSessionID(exceptSID)).Delete(new(UserSession))
+	return err
+}
+
+// DeleteUserSessionBySessionID forgets the session with the given session ID
+// after the user signed out of it.
+func DeleteUserSessionBySessionID(sid string) error {
+	_, err := x.Where("session_hash = ?", hashSessionID(sid)).Delete(new(UserSession))
+	return err
+}
+
+// DeleteExpiredUserSessions deletes the sessions dropped by the session provider
+// which cannot be signed in to again.
+func DeleteExpiredUserSessions() {
+	log.Trace("Doing: DeleteExpiredUserSessions")
+
+	if _, err := x.Where(builder.Not{activeUserSessionCond()}).Delete(new(UserSession)); err != nil {
+		log.Error("DeleteExpiredUserSessions: %v", err)
+	}
+}
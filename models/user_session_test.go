@@ -0,0 +1,174 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/masoodkamyab/gitea/modules/setting"
+	"github.com/masoodkamyab/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func prepareUserSessions(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	setting.SessionConfig.Maxlifetime = 86400
+	_, err := x.Where("1 = 1").Delete(new(UserSession))
+	assert.NoError(t, err)
+}
+
+func updateUserSessionSeen(t *testing.T, s *UserSession) {
+	_, err := x.ID(s.ID).Cols("last_seen_unix").Update(s)
+	assert.NoError(t, err)
+}
+
+func TestUserSession(t *testing.T) {
+	prepareUserSessions(t)
+
+	s, err := CreateUserSession(2, "session-a", "127.0.0.1", "Firefox")
+	assert.NoError(t, err)
+	AssertNotExistsBean(t, &UserSession{SessionHash: "session-a"})
+
+	got, err := GetUserSessionBySessionID("session-a")
+	assert.NoError(t, err)
+	assert.Equal(t, s.ID, got.ID)
+	assert.True(t, got.IsSessionID("session-a"))
+	assert.False(t, got.IsSessionID("session-b"))
+	_, err = GetUserSessionBySessionID("session-b")
+	assert.True(t, IsErrUserSessionNotExist(err))
+
+	// The last seen time is only updated once in a while
+	got.LastSeenUnix = util.TimeStampNow().Add(-10)
+	updateUserSessionSeen(t, got)
+	assert.NoError(t, got.Touch("127.0.0.1", "Firefox"))
+	AssertExistsAndLoadBean(t, &UserSession{ID: s.ID, LastSeenUnix: got.LastSeenUnix})
+	assert.NoError(t, got.Touch("10.0.0.1", "Firefox"))
+	touched := AssertExistsAndLoadBean(t, &UserSession{ID: s.ID, IP: "10.0.0.1"}).(*UserSession)
+	assert.True(t, touched.LastSeenUnix > util.TimeStampNow().Add(-10))
+
+	_, err = CreateUserSession(2, "session-a", "127.0.0.1", "Firefox")
+	assert.Error(t, err, "session IDs are unique")
+}
+
+func TestGetUserSessionsByUID(t *testing.T) {
+	prepareUserSessions(t)
+
+	old, err := CreateUserSession(2, "session-a", "127.0.0.1", "Firefox")
+	assert.NoError(t, err)
+	recent, err := CreateUserSession(2, "session-b", "127.0.0.1", "Chrome")
+	assert.NoError(t, err)
+	_, err = CreateUserSession(4, "session-c", "127.0.0.1", "Chrome")
+	assert.NoError(t, err)
+	expired, err := CreateUserSession(2, "session-d", "127.0.0.1", "Chrome")
+	assert.NoError(t, err)
+
+	old.LastSeenUnix = util.TimeStampNow().Add(-100)
+	updateUserSessionSeen(t, old)
+	expired.LastSeenUnix = util.TimeStampNow().Add(-setting.SessionConfig.Maxlifetime - 2*userSessionSeenInterval)
+	updateUserSessionSeen(t, expired)
+
+	sessions, err := GetUserSessionsByUID(2)
+	assert.NoError(t, err)
+	if assert.Len(t, sessions, 2) {
+		assert.Equal(t, recent.ID, sessions[0].ID)
+		assert.Equal(t, old.ID, sessions[1].ID)
+	}
+
+	assert.EqualValues(t, 3, CountUserSessions())
+	sessions, err = UserSessions(1, 10)
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 3)
+	for _, s := range sessions {
+		if assert.NotNil(t, s.User) {
+			assert.Equal(t, s.UID, s.User.ID)
+		}
+	}
+
+	DeleteExpiredUserSessions()
+	AssertNotExistsBean(t, &UserSession{ID: expired.ID})
+	AssertExistsAndLoadBean(t, &UserSession{ID: old.ID})
+}
+
+func TestRememberUserSession(t *testing.T) {
+	prepareUserSessions(t)
+
+	a, err := CreateUserSession(2, "session-a", "127.0.0.1", "Firefox")
+	assert.NoError(t, err)
+	b, err := CreateUserSession(2, "session-b", "127.0.0.1", "Chrome")
+	assert.NoError(t, err)
+	tokenA, err := a.Remember(7)
+	assert.NoError(t, err)
+	tokenB, err := b.Remember(7)
+	assert.NoError(t, err)
+	assert.NotEqual(t, tokenA, tokenB)
+	AssertNotExistsBean(t, &UserSession{RememberHash: tokenA})
+
+	got, err := GetUserSessionByRememberToken(2, tokenA)
+	assert.NoError(t, err)
+	assert.Equal(t, a.ID, got.ID)
+	_, err = GetUserSessionByRememberToken(4, tokenA)
+	assert.True(t, IsErrUserSessionNotExist(err))
+
+	// The remembered session is resumed in the new browser session
+	assert.NoError(t, got.Resume("session-c", "10.0.0.1", "Firefox"))
+	got, err = GetUserSessionBySessionID("session-c")
+	assert.NoError(t, err)
+	assert.Equal(t, a.ID, got.ID)
+	assert.Equal(t, "10.0.0.1", got.IP)
+
+	// Remembered sessions are kept after the session provider dropped them
+	a.LastSeenUnix = util.TimeStampNow().Add(-setting.SessionConfig.Maxlifetime - 2*userSessionSeenInterval)
+	updateUserSessionSeen(t, a)
+	DeleteExpiredUserSessions()
+	sessions, err := GetUserSessionsByUID(2)
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 2)
+
+	// Revoking a session forgets its remember token only
+	assert.NoError(t, DeleteUserSession(a))
+	_, err = GetUserSessionByRememberToken(2, tokenA)
+	assert.True(t, IsErrUserSessionNotExist(err))
+	got, err = GetUserSessionByRememberToken(2, tokenB)
+	assert.NoError(t, err)
+	assert.Equal(t, b.ID, got.ID)
+
+	// Expired remember tokens cannot be used
+	b.RememberUntil = util.TimeStampNow().Add(-1)
+	_, err = x.ID(b.ID).Cols("remember_until").Update(b)
+	assert.NoError(t, err)
+	_, err = GetUserSessionByRememberToken(2, tokenB)
+	assert.True(t, IsErrUserSessionNotExist(err))
+}
+
+func TestDeleteUserSessions(t *testing.T) {
+	prepareUserSessions(t)
+
+	a, err := CreateUserSession(2, "session-a", "127.0.0.1", "Firefox")
+	assert.NoError(t, err)
+	_, err = CreateUserSession(2, "session-b", "127.0.0.1", "Chrome")
+	assert.NoError(t, err)
+	_, err = CreateUserSession(2, "session-c", "127.0.0.1", "Chrome")
+	assert.NoError(t, err)
+	other, err := CreateUserSession(4, "session-d", "127.0.0.1", "Chrome")
+	assert.NoError(t, err)
+
+	assert.NoError(t, DeleteUserSession(a))
+	AssertNotExistsBean(t, &UserSession{ID: a.ID})
+
+	assert.NoError(t, DeleteUserSessionsByUID(2, "session-c"))
+	sessions, err := GetUserSessionsByUID(2)
+	assert.NoError(t, err)
+	if assert.Len(t, sessions, 1) {
+		assert.True(t, sessions[0].IsSessionID("session-c"))
+	}
+	AssertExistsAndLoadBean(t, &UserSession{ID: other.ID})
+
+	assert.NoError(t, DeleteUserSessionsByUID(2, ""))
+	AssertNotExistsBean(t, &UserSession{UID: 2})
+
+	assert.NoError(t, DeleteUserSessionBySessionID("session-d"))
+	AssertNotExistsBean(t, &UserSession{ID: other.ID})
+}
@@ -0,0 +1,180 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"encoding/base64"
+	"strings"
+
+	"github.com/masoodkamyab/gitea/modules/log"
+	"github.com/masoodkamyab/gitea/modules/setting"
+	"github.com/masoodkamyab/gitea/modules/util"
+	"github.com/masoodkamyab/gitea/modules/webauthn"
+)
+
+// WebAuthnConfig returns the relying party configuration of this instance
+func WebAuthnConfig() *webauthn.Config {
+	return &webauthn.Config{
+		RPID:          setting.WebAuthn.RPID,
+		RPDisplayName: setting.WebAuthn.RPDisplayName,
+		RPOrigin:      setting.WebAuthn.RPOrigin,
+		AppID:         setting.WebAuthn.AppID,
+		Timeout:       setting.WebAuthn.Timeout,
+	}
+}
+
+// WebAuthnCredential represents a public key credential of a security key or
+// platform authenticator used as second factor
+type WebAuthnCredential struct {
+	ID              int64 `xorm:"pk autoincr"`
+	Name            string
+	LowerName       string
+	UserID          int64  `xorm:"INDEX"`
+	CredentialID    string `xorm:"INDEX VARCHAR(410)"`
+	PublicKey       []byte
+	AttestationType string
+	AAGUID          []byte `xorm:"'aaguid'"`
+	SignCount       uint32 `xorm:"BIGINT"`
+	// LegacyU2F is set for security keys registered through the former U2F support
+	LegacyU2F   bool           `xorm:"'legacy_u2f' NOT NULL DEFAULT false"`
+	CreatedUnix util.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix util.TimeStamp `xorm:"INDEX updated"`
+}
+
+// TableName returns a better table name for WebAuthnCredential
+func (cred WebAuthnCredential) TableName() string {
+	return "webauthn_credential"
+}
+
+// BeforeInsert will be invoked by XORM before inserting a record
+func (cred *WebAuthnCredential) BeforeInsert() {
+	cred.LowerName = strings.ToLower(cred.Name)
+}
+
+// ToCredential converts the db entry to a webauthn.Credential
+func (cred *WebAuthnCredential) ToCredential() (*webauthn.Credential, error) {
+	id, err := base64.RawURLEncoding.DecodeString(cred.CredentialID)
+	if err != nil {
+		return nil, err
+	}
+	return &webauthn.Credential{
+		ID:              id,
+		PublicKey:       cred.PublicKey,
+		AttestationType: cred.AttestationType,
+		AAGUID:          cred.AAGUID,
+		SignCount:       cred.SignCount,
+		U2F:             cred.LegacyU2F,
+	}, nil
+}
+
+func (cred *WebAuthnCredential) updateSignCount(e Engine) error {
+	_, err := e.ID(cred.ID).Cols("sign_count").Update(cred)
+	return err
+}
+
+// UpdateSignCount will update the database value of the signature counter
+func (cred *WebAuthnCredential) UpdateSignCount() error {
+	return cred.updateSignCount(x)
+}
+
+// WebAuthnCredentialList is a list of *WebAuthnCredential
+type WebAuthnCredentialList []*WebAuthnCredential
+
+// ToCredentials will convert all WebAuthnCredentials to webauthn.Credentials
+func (list WebAuthnCredentialList) ToCredentials() []*webauthn.Credential {
+	creds := make([]*webauthn.Credential, 0, len(list))
+	for _, cred := range list {
+		c, err := cred.ToCredential()
+		if err != nil {
+			log.Error("parsing WebAuthn credential %d: %v", cred.ID, err)
+			continue
+		}
+		creds = append(creds, c)
+	}
+	return creds
+}
+
+// CredentialIDs returns the raw IDs of all credentials
+func (list WebAuthnCredentialList) CredentialIDs() [][]byte {
+	ids := make([][]byte, 0, len(list))
+	for _, cred := range list.ToCredentials() {
+		ids = append(ids, cred.ID)
+	}
+	return ids
+}
+
+func getWebAuthnCredentialsByUID(e Engine, uid int64) (WebAuthnCredentialList, error) {
+	creds := make(WebAuthnCredentialList, 0)
+	return creds, e.Where("user_id = ?", uid).Asc("id").Find(&creds)
+}
+
+// GetWebAuthnCredentialsByUID returns all WebAuthn credentials of the given user
+func GetWebAuthnCredentialsByUID(uid int64) (WebAuthnCredentialList, error) {
+	return getWebAuthnCredentialsByUID(x, uid)
+}
+
+// HasWebAuthnCredentialsByUID returns whether the given user has WebAuthn credentials
+func HasWebAuthnCredentialsByUID(uid int64) (bool, error) {
+	return x.Where("user_id = ?", uid).Exist(new(WebAuthnCredential))
+}
+
+// GetWebAuthnCredentialByID returns WebAuthn credential by id
+func GetWebAuthnCredentialByID(id int64) (*WebAuthnCredential, error) {
+	return getWebAuthnCredentialByID(x, id)
+}
+
+func getWebAuthnCredentialByID(e Engine, id int64) (*WebAuthnCredential, error) {
+	cred := new(WebAuthnCredential)
+	if found, err := e.ID(id).Get(cred); err != nil {
+		return nil, err
+	} else if !found {
+		return nil, ErrWebAuthnCredentialNotExist{ID: id}
+	}
+	return cred, nil
+}
+
+// GetWebAuthnCredentialByCredID returns the WebAuthn credential of the user by its raw credential ID
+func GetWebAuthnCredentialByCredID(uid int64, credID []byte) (*WebAuthnCredential, error) {
+	encoded := base64.RawURLEncoding.EncodeToString(credID)
+	cred := new(WebAuthnCredential)
+	if found, err := x.Where("user_id = ? AND credential_id = ?", uid, encoded).Get(cred); err != nil {
+		return nil, err
+	} else if !found {
+		return nil, ErrWebAuthnCredentialNotExist{CredentialID: encoded}
+	}
+	return cred, nil
+}
+
+func createCredential(e Engine, userID int64, name string, cred *webauthn.Credential) (*WebAuthnCredential, error) {
+	c := &WebAuthnCredential{
+		UserID:          userID,
+		Name:            name,
+		CredentialID:    base64.RawURLEncoding.EncodeToString(cred.ID),
+		PublicKey:       cred.PublicKey,
+		AttestationType: cred.AttestationType,
+		AAGUID:          cred.AAGUID,
+		SignCount:       cred.SignCount,
+		LegacyU2F:       cred.U2F,
+	}
+	if _, err := e.InsertOne(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// CreateCredential will create a new WebAuthnCredential from the given Credential
+func CreateCredential(userID int64, name string, cred *webauthn.Credential) (*WebAuthnCredential, error) {
+	return createCredential(x, userID, name, cred)
+}
+
+// DeleteCredential will delete WebAuthnCredential
+func DeleteCredential(cred *WebAuthnCredential) error {
+	return deleteCredential(x, cred)
+}
+
+func deleteCredential(e Engine, cred *WebAuthnCredential) error {
+	_, err := e.ID(cred.ID).Delete(new(WebAuthnCredential))
+	return err
+}
@@ -0,0 +1,90 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/masoodkamyab/gitea/modules/webauthn"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetWebAuthnCredentialByID(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	res, err := GetWebAuthnCredentialByID(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "WebAuthn Credential", res.Name)
+
+	_, err = GetWebAuthnCredentialByID(342432)
+	assert.Error(t, err)
+	assert.True(t, IsErrWebAuthnCredentialNotExist(err))
+}
+
+func TestGetWebAuthnCredentialsByUID(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	res, err := GetWebAuthnCredentialsByUID(1)
+	assert.NoError(t, err)
+	assert.Len(t, res, 1)
+	assert.Equal(t, "WebAuthn Credential", res[0].Name)
+
+	has, err := HasWebAuthnCredentialsByUID(1)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	has, err = HasWebAuthnCredentialsByUID(2)
+	assert.NoError(t, err)
+	assert.False(t, has)
+}
+
+func TestWebAuthnCredential_TableName(t *testing.T) {
+	assert.Equal(t, "webauthn_credential", WebAuthnCredential{}.TableName())
+}
+
+func TestWebAuthnCredential_UpdateSignCount(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	cred := AssertExistsAndLoadBean(t, &WebAuthnCredential{ID: 1}).(*WebAuthnCredential)
+	cred.SignCount = 1
+	assert.NoError(t, cred.UpdateSignCount())
+	AssertExistsIf(t, true, &WebAuthnCredential{ID: 1, SignCount: 1})
+}
+
+func TestWebAuthnCredential_UpdateLargeCounter(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	cred := AssertExistsAndLoadBean(t, &WebAuthnCredential{ID: 1}).(*WebAuthnCredential)
+	cred.SignCount = 0xffffffff
+	assert.NoError(t, cred.UpdateSignCount())
+	AssertExistsIf(t, true, &WebAuthnCredential{ID: 1, SignCount: 0xffffffff})
+}
+
+func TestCreateCredential(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	res, err := CreateCredential(1, "WebAuthn Created Credential", &webauthn.Credential{ID: []byte("Test"), PublicKey: []byte("Key")})
+	assert.NoError(t, err)
+	assert.Equal(t, "WebAuthn Created Credential", res.Name)
+	assert.Equal(t, "webauthn created credential", res.LowerName)
+
+	cred, err := GetWebAuthnCredentialByCredID(1, []byte("Test"))
+	assert.NoError(t, err)
+	assert.Equal(t, res.ID, cred.ID)
+	assert.Equal(t, []byte("Key"), cred.PublicKey)
+
+	_, err = GetWebAuthnCredentialByCredID(2, []byte("Test"))
+	assert.True(t, IsErrWebAuthnCredentialNotExist(err))
+
+	c, err := cred.ToCredential()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("Test"), c.ID)
+}
+
+func TestDeleteCredential(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	cred := AssertExistsAndLoadBean(t, &WebAuthnCredential{ID: 1}).(*WebAuthnCredential)
+
+	assert.NoError(t, DeleteCredential(cred))
+	AssertNotExistsBean(t, &WebAuthnCredential{ID: 1})
+}
@@ -167,6 +167,9 @@ func getDingtalkIssuesPayload(p *api.IssuePayload) (*DingtalkPayload, error) {
 	case api.HookIssueDemilestoned:
 		title = fmt.Sprintf("[%s] Issue clear milestone: #%d %s", p.Repository.FullName, p.Index, p.Issue.Title)
 		text = p.Issue.Body
+	case api.HookIssueProjectBoardChanged:
+		title = fmt.Sprintf("[%s] Issue moved to %s: #%d %s", p.Repository.FullName, projectBoardTitle(p.ProjectBoard), p.Index, p.Issue.Title)
+		text = p.Issue.Body
 	}
 
 	return &DingtalkPayload{
@@ -257,6 +260,9 @@ func getDingtalkPullRequestPayload(p *api.PullRequestPayload) (*DingtalkPayload,
 	case api.HookIssueDemilestoned:
 		title = fmt.Sprintf("[%s] Pull request clear milestone: #%d %s", p.Repository.FullName, p.Index, p.PullRequest.Title)
 		text = p.PullRequest.Body
+	case api.HookIssueProjectBoardChanged:
+		title = fmt.Sprintf("[%s] Pull request moved to %s: #%d %s", p.Repository.FullName, projectBoardTitle(p.ProjectBoard), p.Index, p.PullRequest.Title)
+		text = p.PullRequest.Body
 	}
 
 	return &DingtalkPayload{
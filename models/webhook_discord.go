@@ -260,6 +260,10 @@ func getDiscordIssuesPayload(p *api.IssuePayload, meta *DiscordMeta) (*DiscordPa
 		title = fmt.Sprintf("[%s] Issue clear milestone: #%d %s", p.Repository.FullName, p.Index, p.Issue.Title)
 		text = p.Issue.Body
 		color = warnColor
+	case api.HookIssueProjectBoardChanged:
+		title = fmt.Sprintf("[%s] Issue moved to %s: #%d %s", p.Repository.FullName, projectBoardTitle(p.ProjectBoard), p.Index, p.Issue.Title)
+		text = p.Issue.Body
+		color = warnColor
 	}
 
 	return &DiscordPayload{
@@ -380,6 +384,10 @@ func getDiscordPullRequestPayload(p *api.PullRequestPayload, meta *DiscordMeta)
 		title = fmt.Sprintf("[%s] Pull request clear milestone: #%d %s", p.Repository.FullName, p.Index, p.PullRequest.Title)
 		text = p.PullRequest.Body
 		color = warnColor
+	case api.HookIssueProjectBoardChanged:
+		title = fmt.Sprintf("[%s] Pull request moved to %s: #%d %s", p.Repository.FullName, projectBoardTitle(p.ProjectBoard), p.Index, p.PullRequest.Title)
+		text = p.PullRequest.Body
+		color = warnColor
 	}
 
 	return &DiscordPayload{
@@ -553,3 +561,11 @@ func parseHookPullRequestEventType(event HookEventType) (string, error) {
 		return "", errors.New("unknown event type")
 	}
 }
+
+// projectBoardTitle returns the title of the board an issue was moved to
+func projectBoardTitle(board *api.ProjectBoard) string {
+	if board == nil {
+		return "Uncategorized"
+	}
+	return board.Title
+}
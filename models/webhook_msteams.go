@@ -313,6 +313,10 @@ func getMSTeamsIssuesPayload(p *api.IssuePayload) (*MSTeamsPayload, error) {
 		title = fmt.Sprintf("[%s] Issue clear milestone: #%d %s", p.Repository.FullName, p.Index, p.Issue.Title)
 		text = p.Issue.Body
 		color = warnColor
+	case api.HookIssueProjectBoardChanged:
+		title = fmt.Sprintf("[%s] Issue moved to %s: #%d %s", p.Repository.FullName, projectBoardTitle(p.ProjectBoard), p.Index, p.Issue.Title)
+		text = p.Issue.Body
+		color = warnColor
 	}
 
 	return &MSTeamsPayload{
@@ -473,6 +477,10 @@ func getMSTeamsPullRequestPayload(p *api.PullRequestPayload) (*MSTeamsPayload, e
 		title = fmt.Sprintf("[%s] Pull request clear milestone: #%d %s", p.Repository.FullName, p.Index, p.PullRequest.Title)
 		text = p.PullRequest.Body
 		color = warnColor
+	case api.HookIssueProjectBoardChanged:
+		title = fmt.Sprintf("[%s] Pull request moved to %s: #%d %s", p.Repository.FullName, projectBoardTitle(p.ProjectBoard), p.Index, p.PullRequest.Title)
+		text = p.PullRequest.Body
+		color = warnColor
 	}
 
 	return &MSTeamsPayload{
@@ -164,6 +164,8 @@ func getSlackIssuesPayload(p *api.IssuePayload, slack *SlackMeta) (*SlackPayload
 		text = fmt.Sprintf("[%s] Issue milestoned: #%s %s", p.Repository.FullName, titleLink, senderLink)
 	case api.HookIssueDemilestoned:
 		text = fmt.Sprintf("[%s] Issue milestone cleared: #%s %s", p.Repository.FullName, titleLink, senderLink)
+	case api.HookIssueProjectBoardChanged:
+		text = fmt.Sprintf("[%s] Issue moved to %s: #%s %s", p.Repository.FullName, projectBoardTitle(p.ProjectBoard), titleLink, senderLink)
 	}
 
 	return &SlackPayload{
@@ -320,6 +322,8 @@ func getSlackPullRequestPayload(p *api.PullRequestPayload, slack *SlackMeta) (*S
 		text = fmt.Sprintf("[%s] Pull request milestoned: #%s %s", p.Repository.FullName, titleLink, senderLink)
 	case api.HookIssueDemilestoned:
 		text = fmt.Sprintf("[%s] Pull request milestone cleared: #%s %s", p.Repository.FullName, titleLink, senderLink)
+	case api.HookIssueProjectBoardChanged:
+		text = fmt.Sprintf("[%s] Pull request moved to %s: #%s %s", p.Repository.FullName, projectBoardTitle(p.ProjectBoard), titleLink, senderLink)
 	}
 
 	return &SlackPayload{
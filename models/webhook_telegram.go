@@ -161,6 +161,10 @@ func getTelegramIssuesPayload(p *api.IssuePayload) (*TelegramPayload, error) {
 		title = fmt.Sprintf(`[<a href="%s">%s</a>] Issue clear milestone: <a href="%s">#%d %s</a>`, p.Repository.HTMLURL, p.Repository.FullName,
 			p.Issue.URL, p.Index, p.Issue.Title)
 		text = p.Issue.Body
+	case api.HookIssueProjectBoardChanged:
+		title = fmt.Sprintf(`[<a href="%s">%s</a>] Issue moved to %s: <a href="%s">#%d %s</a>`, p.Repository.HTMLURL, p.Repository.FullName,
+			projectBoardTitle(p.ProjectBoard), p.Issue.URL, p.Index, p.Issue.Title)
+		text = p.Issue.Body
 	}
 
 	return &TelegramPayload{
@@ -245,6 +249,10 @@ func getTelegramPullRequestPayload(p *api.PullRequestPayload) (*TelegramPayload,
 		title = fmt.Sprintf(`[<a href="%s">%s</a>] Pull request clear milestone: <a href="%s">#%d %s</a>`, p.Repository.HTMLURL, p.Repository.FullName,
 			p.PullRequest.HTMLURL, p.Index, p.PullRequest.Title)
 		text = p.PullRequest.Body
+	case api.HookIssueProjectBoardChanged:
+		title = fmt.Sprintf(`[<a href="%s">%s</a>] Pull request moved to %s: <a href="%s">#%d %s</a>`, p.Repository.HTMLURL, p.Repository.FullName,
+			projectBoardTitle(p.ProjectBoard), p.PullRequest.HTMLURL, p.Index, p.PullRequest.Title)
+		text = p.PullRequest.Body
 	}
 
 	return &TelegramPayload{
@@ -55,9 +55,10 @@ func SignedInID(ctx *macaron.Context, sess session.Store) int64 {
 		// Let's see if token is valid.
 		if len(tokenSHA) > 0 {
 			if strings.Contains(tokenSHA, ".") {
-				uid := CheckOAuthAccessToken(tokenSHA)
+				uid, scope := CheckOAuthAccessToken(tokenSHA)
 				if uid != 0 {
 					ctx.Data["IsApiToken"] = true
+					ctx.Data["ApiTokenScope"] = scope
 				}
 				return uid
 			}
@@ -73,6 +74,7 @@ func SignedInID(ctx *macaron.Context, sess session.Store) int64 {
 				log.Error("UpdateAccessToken: %v", err)
 			}
 			ctx.Data["IsApiToken"] = true
+			ctx.Data["ApiTokenScope"] = t.Scope
 			return t.UID
 		}
 	}
@@ -80,34 +82,109 @@ func SignedInID(ctx *macaron.Context, sess session.Store) int64 {
 	uid := sess.Get("uid")
 	if uid == nil {
 		return 0
-	} else if id, ok := uid.(int64); ok {
+	} else if id, ok := uid.(int64); ok && checkUserSession(ctx, sess, id) {
 		return id
 	}
 	return 0
 }
 
-// CheckOAuthAccessToken returns uid of user from oauth token token
-func CheckOAuthAccessToken(accessToken string) int64 {
+// checkUserSession records the signed in session of the user and returns
+// whether the user has not been signed out of it meanwhile.
+func checkUserSession(ctx *macaron.Context, sess session.Store, uid int64) bool {
+	us, err := models.GetUserSessionBySessionID(sess.ID())
+	if err == nil && us.UID == uid {
+		if err = us.Touch(ctx.RemoteAddr(), ctx.Req.UserAgent()); err != nil {
+			log.Error("Touch: %v", err)
+		}
+		return true
+	} else if err != nil && !models.IsErrUserSessionNotExist(err) {
+		log.Error("GetUserSessionBySessionID: %v", err)
+		return false
+	}
+
+	// The session has been recorded before, so it has been revoked.
+	if tracked, ok := sess.Get("sessionUid").(int64); ok && tracked == uid {
+		log.Trace("Revoked session of user %d used from %s", uid, ctx.RemoteAddr())
+		_ = sess.Delete("uid")
+		_ = sess.Delete("uname")
+		_ = sess.Delete("sessionUid")
+		return false
+	}
+
+	// Sessions started before they were recorded or of another user who signed
+	// in with the same browser.
+	if err = StartUserSession(ctx, sess, uid); err != nil {
+		log.Error("StartUserSession: %v", err)
+	}
+	return true
+}
+
+// StartUserSession records the session the user has just signed in with.
+func StartUserSession(ctx *macaron.Context, sess session.Store, uid int64) error {
+	if err := models.DeleteUserSessionBySessionID(sess.ID()); err != nil {
+		return err
+	}
+	if _, err := models.CreateUserSession(uid, sess.ID(), ctx.RemoteAddr(), ctx.Req.UserAgent()); err != nil {
+		return err
+	}
+	return sess.Set("sessionUid", uid)
+}
+
+// RememberUserSession remembers the session the user has just signed in with
+// in the browser, so that the user is signed in to it again after the session
+// provider dropped it.
+func RememberUserSession(ctx *macaron.Context, sess session.Store, u *models.User) error {
+	us, err := models.GetUserSessionBySessionID(sess.ID())
+	if err != nil {
+		return err
+	}
+	token, err := us.Remember(setting.LogInRememberDays)
+	if err != nil {
+		return err
+	}
+
+	days := 86400 * setting.LogInRememberDays
+	ctx.SetCookie(setting.CookieUserName, u.Name, days, setting.AppSubURL, "", setting.SessionConfig.Secure, true)
+	ctx.SetSuperSecureCookie(base.EncodeMD5(u.Rands+u.Passwd),
+		setting.CookieRememberName, token, days, setting.AppSubURL, "", setting.SessionConfig.Secure, true)
+	return nil
+}
+
+// ResumeUserSession continues the remembered session the user has signed in
+// to again in the current browser session.
+func ResumeUserSession(ctx *macaron.Context, sess session.Store, us *models.UserSession) error {
+	if err := models.DeleteUserSessionBySessionID(sess.ID()); err != nil {
+		return err
+	}
+	if err := us.Resume(sess.ID(), ctx.RemoteAddr(), ctx.Req.UserAgent()); err != nil {
+		return err
+	}
+	return sess.Set("sessionUid", us.UID)
+}
+
+// CheckOAuthAccessToken returns uid of user and the scope the oauth token was issued for
+func CheckOAuthAccessToken(accessToken string) (int64, models.AccessTokenScope) {
 	// JWT tokens require a "."
 	if !strings.Contains(accessToken, ".") {
-		return 0
+		return 0, ""
 	}
 	token, err := models.ParseOAuth2Token(accessToken)
 	if err != nil {
 		log.Trace("ParseOAuth2Token: %v", err)
-		return 0
+		return 0, ""
 	}
 	var grant *models.OAuth2Grant
 	if grant, err = models.GetOAuth2GrantByID(token.GrantID); err != nil || grant == nil {
-		return 0
+		return 0, ""
 	}
 	if token.Type != models.TypeAccessToken {
-		return 0
+		return 0, ""
 	}
 	if token.ExpiresAt < time.Now().Unix() || token.IssuedAt > time.Now().Unix() {
-		return 0
+		return 0, ""
 	}
-	return grant.UserID
+	scope, _ := token.Scopes(grant)
+	return grant.UserID, scope
 }
 
 // SignedInUser returns the user object of signed user.
@@ -181,10 +258,11 @@ func SignedInUser(ctx *macaron.Context, sess session.Store) (*models.User, bool)
 				authToken = passwd
 			}
 
-			uid := CheckOAuthAccessToken(authToken)
+			uid, scope := CheckOAuthAccessToken(authToken)
 			if uid != 0 {
 				var err error
 				ctx.Data["IsApiToken"] = true
+				ctx.Data["ApiTokenScope"] = scope
 
 				u, err = models.GetUserByID(uid)
 				if err != nil {
@@ -214,6 +292,7 @@ func SignedInUser(ctx *macaron.Context, sess session.Store) (*models.User, bool)
 				if err = models.UpdateAccessToken(token); err != nil {
 					log.Error("UpdateAccessToken:  %v", err)
 				}
+				ctx.Data["ApiTokenScope"] = token.Scope
 			} else if !models.IsErrAccessTokenNotExist(err) && !models.IsErrAccessTokenEmpty(err) {
 				log.Error("GetAccessTokenBySha: %v", err)
 			}
@@ -12,7 +12,7 @@ import (
 // AuthenticationForm form for authentication
 type AuthenticationForm struct {
 	ID                            int64
-	Type                          int    `binding:"Range(2,6)"`
+	Type                          int    `binding:"Range(2,7)"`
 	Name                          string `binding:"Required;MaxSize(30)"`
 	Host                          string
 	Port                          int
@@ -30,6 +30,13 @@ type AuthenticationForm struct {
 	SearchPageSize                int
 	Filter                        string
 	AdminFilter                   string
+	AttributeMemberOf             string
+	GroupSearchBase               string
+	GroupFilter                   string
+	GroupMemberAttribute          string
+	GroupMemberUID                string
+	GroupTeamMap                  string
+	GroupTeamMapRemoval           bool
 	IsActive                      bool
 	IsSyncEnabled                 bool
 	SMTPAuth                      string
@@ -49,6 +56,24 @@ type AuthenticationForm struct {
 	Oauth2AuthURL                 string
 	Oauth2ProfileURL              string
 	Oauth2EmailURL                string
+	Oauth2Scopes                  string
+	Oauth2UsernameClaim           string
+	Oauth2EmailClaim              string
+	Oauth2FullNameClaim           string
+	Oauth2GroupClaimName          string
+	Oauth2AdminGroup              string
+	Oauth2GroupTeamMap            string
+	Oauth2GroupTeamMapRemoval     bool
+	SAMLMetadataURL               string
+	SAMLMetadata                  string
+	SAMLUsernameAttribute         string
+	SAMLEmailAttribute            string
+	SAMLFullNameAttribute         string
+	SAMLGroupAttribute            string
+	SAMLAdminGroup                string
+	SAMLGroupTeamMap              string
+	SAMLGroupTeamMapRemoval       bool
+	SAMLEnableAutoRegister        bool
 }
 
 // Validate validates fields
@@ -46,6 +46,11 @@ type Source struct {
 	SearchPageSize        uint32 // Search with paging page size
 	Filter                string // Query filter to validate entry
 	AdminFilter           string // Query filter to check if user is admin
+	AttributeMemberOf     string // Attribute of the user listing the DNs of its groups
+	GroupSearchBase       string // Base search path for groups
+	GroupFilter           string // Query filter for groups
+	GroupMemberAttribute  string // Attribute of a group listing its members
+	GroupMemberUID        string // Attribute of the user listed as group member, the DN if empty
 	Enabled               bool   // if this source is disabled
 }
 
@@ -57,6 +62,7 @@ type SearchResult struct {
 	Mail         string   // E-mail address
 	SSHPublicKey []string // SSH Public Key
 	IsAdmin      bool     // if user is administrator
+	Groups       []string // DNs of the groups of the user
 }
 
 func (ls *Source) sanitizedUserQuery(username string) (string, bool) {
@@ -172,6 +178,77 @@ func checkAdmin(l *ldap.Conn, ls *Source, userDN string) bool {
 	return false
 }
 
+// HasGroups returns if the groups of the users are looked up
+func (ls *Source) HasGroups() bool {
+	return len(ls.AttributeMemberOf) > 0 || (len(ls.GroupSearchBase) > 0 && len(ls.GroupMemberAttribute) > 0)
+}
+
+// userAttributes returns the attributes to fetch of a user entry
+func (ls *Source) userAttributes() []string {
+	attribs := []string{ls.AttributeUsername, ls.AttributeName, ls.AttributeSurname, ls.AttributeMail}
+	if len(strings.TrimSpace(ls.AttributeSSHPublicKey)) > 0 {
+		attribs = append(attribs, ls.AttributeSSHPublicKey)
+	}
+	if len(ls.AttributeMemberOf) > 0 {
+		attribs = append(attribs, ls.AttributeMemberOf)
+	}
+	if len(ls.GroupMemberUID) > 0 {
+		attribs = append(attribs, ls.GroupMemberUID)
+	}
+	return attribs
+}
+
+// listGroups returns the DNs of the groups of a user entry, listed by the member of attribute of
+// the user and found by searching the groups which list the user as member
+func listGroups(l *ldap.Conn, ls *Source, entry *ldap.Entry) []string {
+	groups := make([]string, 0, 5)
+	found := make(map[string]bool)
+	addGroup := func(dn string) {
+		if len(dn) > 0 && !found[strings.ToLower(dn)] {
+			found[strings.ToLower(dn)] = true
+			groups = append(groups, dn)
+		}
+	}
+
+	if len(ls.AttributeMemberOf) > 0 {
+		for _, dn := range entry.GetAttributeValues(ls.AttributeMemberOf) {
+			addGroup(dn)
+		}
+	}
+
+	if len(ls.GroupSearchBase) > 0 && len(ls.GroupMemberAttribute) > 0 {
+		member := entry.DN
+		if len(ls.GroupMemberUID) > 0 {
+			member = entry.GetAttributeValue(ls.GroupMemberUID)
+		}
+		if len(member) == 0 {
+			log.Debug("LDAP user %s has no attribute %s to search its groups with", entry.DN, ls.GroupMemberUID)
+			return groups
+		}
+
+		groupFilter := ls.GroupFilter
+		if len(groupFilter) == 0 {
+			groupFilter = "(objectClass=*)"
+		}
+		groupFilter = fmt.Sprintf("(&%s(%s=%s))", groupFilter, ls.GroupMemberAttribute, ldap.EscapeFilter(member))
+
+		log.Trace("Searching groups with filter %s and base %s", groupFilter, ls.GroupSearchBase)
+		search := ldap.NewSearchRequest(
+			ls.GroupSearchBase, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false, groupFilter,
+			[]string{"dn"}, nil)
+
+		sr, err := l.Search(search)
+		if err != nil {
+			log.Error("LDAP Group Search failed unexpectedly! (%v)", err)
+			return groups
+		}
+		for _, group := range sr.Entries {
+			addGroup(group.DN)
+		}
+	}
+	return groups
+}
+
 // SearchEntry : search an LDAP source if an entry (name, passwd) is valid and in the specific filter
 func (ls *Source) SearchEntry(name, passwd string, directBind bool) *SearchResult {
 	// See https://tools.ietf.org/search/rfc4513#section-5.1.2
@@ -249,10 +326,7 @@ func (ls *Source) SearchEntry(name, passwd string, directBind bool) *SearchResul
 
 	var isAttributeSSHPublicKeySet = len(strings.TrimSpace(ls.AttributeSSHPublicKey)) > 0
 
-	attribs := []string{ls.AttributeUsername, ls.AttributeName, ls.AttributeSurname, ls.AttributeMail}
-	if isAttributeSSHPublicKeySet {
-		attribs = append(attribs, ls.AttributeSSHPublicKey)
-	}
+	attribs := ls.userAttributes()
 
 	log.Trace("Fetching attributes '%v', '%v', '%v', '%v', '%v' with filter %s and base %s", ls.AttributeUsername, ls.AttributeName, ls.AttributeSurname, ls.AttributeMail, ls.AttributeSSHPublicKey, userFilter, userDN)
 	search := ldap.NewSearchRequest(
@@ -283,6 +357,10 @@ func (ls *Source) SearchEntry(name, passwd string, directBind bool) *SearchResul
 		sshPublicKey = sr.Entries[0].GetAttributeValues(ls.AttributeSSHPublicKey)
 	}
 	isAdmin := checkAdmin(l, ls, userDN)
+	var groups []string
+	if ls.HasGroups() {
+		groups = listGroups(l, ls, sr.Entries[0])
+	}
 
 	if !directBind && ls.AttributesInBind {
 		// binds user (checking password) after looking-up attributes in BindDN context
@@ -299,6 +377,7 @@ func (ls *Source) SearchEntry(name, passwd string, directBind bool) *SearchResul
 		Mail:         mail,
 		SSHPublicKey: sshPublicKey,
 		IsAdmin:      isAdmin,
+		Groups:       groups,
 	}
 }
 
@@ -308,12 +387,12 @@ func (ls *Source) UsePagedSearch() bool {
 }
 
 // SearchEntries : search an LDAP source for all users matching userFilter
-func (ls *Source) SearchEntries() []*SearchResult {
+func (ls *Source) SearchEntries() ([]*SearchResult, error) {
 	l, err := dial(ls)
 	if err != nil {
 		log.Error("LDAP Connect error, %s:%v", ls.Host, err)
 		ls.Enabled = false
-		return nil
+		return nil, err
 	}
 	defer l.Close()
 
@@ -321,7 +400,7 @@ func (ls *Source) SearchEntries() []*SearchResult {
 		err := l.Bind(ls.BindDN, ls.BindPassword)
 		if err != nil {
 			log.Debug("Failed to bind as BindDN[%s]: %v", ls.BindDN, err)
-			return nil
+			return nil, err
 		}
 		log.Trace("Bound as BindDN %s", ls.BindDN)
 	} else {
@@ -332,10 +411,7 @@ func (ls *Source) SearchEntries() []*SearchResult {
 
 	var isAttributeSSHPublicKeySet = len(strings.TrimSpace(ls.AttributeSSHPublicKey)) > 0
 
-	attribs := []string{ls.AttributeUsername, ls.AttributeName, ls.AttributeSurname, ls.AttributeMail}
-	if isAttributeSSHPublicKeySet {
-		attribs = append(attribs, ls.AttributeSSHPublicKey)
-	}
+	attribs := ls.userAttributes()
 
 	log.Trace("Fetching attributes '%v', '%v', '%v', '%v', '%v' with filter %s and base %s", ls.AttributeUsername, ls.AttributeName, ls.AttributeSurname, ls.AttributeMail, ls.AttributeSSHPublicKey, userFilter, ls.UserBase)
 	search := ldap.NewSearchRequest(
@@ -350,7 +426,7 @@ func (ls *Source) SearchEntries() []*SearchResult {
 	}
 	if err != nil {
 		log.Error("LDAP Search failed unexpectedly! (%v)", err)
-		return nil
+		return nil, err
 	}
 
 	result := make([]*SearchResult, len(sr.Entries))
@@ -366,7 +442,10 @@ func (ls *Source) SearchEntries() []*SearchResult {
 		if isAttributeSSHPublicKeySet {
 			result[i].SSHPublicKey = v.GetAttributeValues(ls.AttributeSSHPublicKey)
 		}
+		if ls.HasGroups() {
+			result[i].Groups = listGroups(l, ls, v)
+		}
 	}
 
-	return result
+	return result, nil
 }
@@ -40,6 +40,13 @@ type CustomURLMapping struct {
 	EmailURL   string
 }
 
+// ClaimMapping describes the OpenID Connect claims holding the details of a user, empty values keep the standard claims
+type ClaimMapping struct {
+	Username string
+	Email    string
+	FullName string
+}
+
 // Init initialize the setup of the OAuth2 library
 func Init(x *xorm.Engine) error {
 	store, err := xormstore.NewOptions(x, xormstore.Options{
@@ -99,9 +106,9 @@ func ProviderCallback(provider string, request *http.Request, response http.Resp
 	return user, nil
 }
 
-// RegisterProvider register a OAuth2 provider in goth lib
-func RegisterProvider(providerName, providerType, clientID, clientSecret, openIDConnectAutoDiscoveryURL string, customURLMapping *CustomURLMapping) error {
-	provider, err := createProvider(providerName, providerType, clientID, clientSecret, openIDConnectAutoDiscoveryURL, customURLMapping)
+// RegisterProvider register a OAuth2 provider in goth lib, the scopes and the claim mapping are only used by OpenID Connect
+func RegisterProvider(providerName, providerType, clientID, clientSecret, openIDConnectAutoDiscoveryURL string, customURLMapping *CustomURLMapping, scopes []string, claimMapping *ClaimMapping) error {
+	provider, err := createProvider(providerName, providerType, clientID, clientSecret, openIDConnectAutoDiscoveryURL, customURLMapping, scopes, claimMapping)
 
 	if err == nil && provider != nil {
 		goth.UseProviders(provider)
@@ -116,7 +123,7 @@ func RemoveProvider(providerName string) {
 }
 
 // used to create different types of goth providers
-func createProvider(providerName, providerType, clientID, clientSecret, openIDConnectAutoDiscoveryURL string, customURLMapping *CustomURLMapping) (goth.Provider, error) {
+func createProvider(providerName, providerType, clientID, clientSecret, openIDConnectAutoDiscoveryURL string, customURLMapping *CustomURLMapping, scopes []string, claimMapping *ClaimMapping) (goth.Provider, error) {
 	callbackURL := setting.AppURL + "user/oauth2/" + providerName + "/callback"
 
 	var provider goth.Provider
@@ -168,9 +175,23 @@ func createProvider(providerName, providerType, clientID, clientSecret, openIDCo
 	case "gplus":
 		provider = gplus.New(clientID, clientSecret, callbackURL, "email")
 	case "openidConnect":
-		if provider, err = openidConnect.New(clientID, clientSecret, callbackURL, openIDConnectAutoDiscoveryURL); err != nil {
+		var openIDProvider *openidConnect.Provider
+		if openIDProvider, err = openidConnect.New(clientID, clientSecret, callbackURL, openIDConnectAutoDiscoveryURL, scopes...); err != nil {
 			log.Warn("Failed to create OpenID Connect Provider with name '%s' with url '%s': %v", providerName, openIDConnectAutoDiscoveryURL, err)
+			break
+		}
+		if claimMapping != nil {
+			if len(claimMapping.Username) > 0 {
+				openIDProvider.NickNameClaims = []string{claimMapping.Username}
+			}
+			if len(claimMapping.Email) > 0 {
+				openIDProvider.EmailClaims = []string{claimMapping.Email}
+			}
+			if len(claimMapping.FullName) > 0 {
+				openIDProvider.NameClaims = []string{claimMapping.FullName}
+			}
 		}
+		provider = openIDProvider
 	case "twitter":
 		provider = twitter.NewAuthenticate(clientID, clientSecret, callbackURL)
 	case "discord":
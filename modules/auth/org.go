@@ -33,13 +33,14 @@ func (f *CreateOrgForm) Validate(ctx *macaron.Context, errs binding.Errors) bind
 
 // UpdateOrgSettingForm form for updating organization settings
 type UpdateOrgSettingForm struct {
-	Name            string `binding:"Required;AlphaDashDot;MaxSize(40)" locale:"org.org_name_holder"`
-	FullName        string `binding:"MaxSize(100)"`
-	Description     string `binding:"MaxSize(255)"`
-	Website         string `binding:"ValidUrl;MaxSize(255)"`
-	Location        string `binding:"MaxSize(50)"`
-	Visibility      structs.VisibleType
-	MaxRepoCreation int
+	Name             string `binding:"Required;AlphaDashDot;MaxSize(40)" locale:"org.org_name_holder"`
+	FullName         string `binding:"MaxSize(100)"`
+	Description      string `binding:"MaxSize(255)"`
+	Website          string `binding:"ValidUrl;MaxSize(255)"`
+	Location         string `binding:"MaxSize(50)"`
+	Visibility       structs.VisibleType
+	MaxRepoCreation  int
+	RequireTwoFactor bool
 }
 
 // Validate validates the fields
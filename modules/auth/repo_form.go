@@ -121,9 +121,13 @@ type RepoSettingForm struct {
 	PullsAllowRebase                 bool
 	PullsAllowRebaseMerge            bool
 	PullsAllowSquash                 bool
+	PullsAllowFastForwardOnly        bool
+	PullsMergeMessageTemplate        string `binding:"MaxSize(2048)"`
+	PullsSquashMessageTemplate       string `binding:"MaxSize(2048)"`
 	EnableTimetracker                bool
 	AllowOnlyContributorsToTrackTime bool
 	EnableIssueDependencies          bool
+	EnableProjects                   bool
 	IsArchived                       bool
 
 	// Admin settings
@@ -155,6 +159,7 @@ type ProtectBranchForm struct {
 	RequiredApprovals       int64
 	ApprovalsWhitelistUsers string
 	ApprovalsWhitelistTeams string
+	EnableMergeQueue        bool
 }
 
 // Validate validates the fields
@@ -310,6 +315,7 @@ type CreateIssueForm struct {
 	AssigneeID  int64
 	Content     string
 	Files       []string
+	Draft       bool
 }
 
 // Validate validates the fields
@@ -365,6 +371,56 @@ func (i IssueLockForm) HasValidReason() bool {
 	return false
 }
 
+// TransferIssueForm form for transferring an issue to another repository
+type TransferIssueForm struct {
+	NewRepo string `binding:"Required"`
+}
+
+// Validate validates the fields
+func (f *TransferIssueForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// BulkEditIssuesForm form for editing many issues at once, the IDs are comma separated
+type BulkEditIssuesForm struct {
+	IssueIDs          string `form:"issue_ids" binding:"Required"`
+	AddLabelIDs       string `form:"add_label_ids"`
+	RemoveLabelIDs    string `form:"remove_label_ids"`
+	MilestoneID       string `form:"milestone_id"` // empty keeps the milestones, 0 removes them
+	AddAssigneeIDs    string `form:"add_assignee_ids"`
+	RemoveAssigneeIDs string `form:"remove_assignee_ids"`
+	AddProjectID      int64  `form:"add_project_id"`
+	RemoveProjectID   int64  `form:"remove_project_id"`
+	State             string `binding:"In(,open,closed)"`
+	Lock              string `binding:"In(,lock,unlock)"`
+	LockReason        string
+	TransferTo        string
+	RedirectTo        string
+}
+
+// Validate validates the fields
+func (f *BulkEditIssuesForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// IssueFieldForm form for creating and editing a custom issue field, the options are separated by new lines
+type IssueFieldForm struct {
+	Name        string `binding:"Required;MaxSize(50)"`
+	Description string `binding:"MaxSize(255)"`
+	Type        string `binding:"In(,text,number,date,single_select,multi_select,user)"`
+	Options     string
+}
+
+// Validate validates the fields
+func (f *IssueFieldForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// OptionList returns the options of the field
+func (f *IssueFieldForm) OptionList() []string {
+	return strings.Split(strings.Replace(f.Options, "\r", "", -1), "\n")
+}
+
 //    _____  .__.__                   __
 //   /     \ |__|  |   ____   _______/  |_  ____   ____   ____
 //  /  \ /  \|  |  | _/ __ \ /  ___/\   __\/  _ \ /    \_/ __ \
@@ -384,6 +440,46 @@ func (f *CreateMilestoneForm) Validate(ctx *macaron.Context, errs binding.Errors
 	return validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+// __________                   __               __
+// \______   \_______  ____    |__| ____   _____/  |_
+//  |     ___/\_  __ \/  _ \   |  |/ __ \_/ ___\   __\
+//  |    |     |  | \(  <_> )  |  \  ___/\  \___|  |
+//  |____|     |__|   \____/\__|  |\___  >\___  >__|
+//                         \______|    \/     \/
+
+// CreateProjectForm form for creating a project
+type CreateProjectForm struct {
+	Title   string `binding:"Required;MaxSize(255)"`
+	Content string
+}
+
+// Validate validates the fields
+func (f *CreateProjectForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// CreateProjectBoardForm form for creating or renaming a board of a project
+type CreateProjectBoardForm struct {
+	Title string `binding:"Required;MaxSize(255)"`
+}
+
+// Validate validates the fields
+func (f *CreateProjectBoardForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// AddProjectIssueForm form for putting an issue on a project,
+// Issue is a reference like "#1" or "repo#1"
+type AddProjectIssueForm struct {
+	Issue   string `binding:"Required"`
+	BoardID int64
+}
+
+// Validate validates the fields
+func (f *AddProjectIssueForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 // .____          ___.          .__
 // |    |   _____ \_ |__   ____ |  |
 // |    |   \__  \ | __ \_/ __ \|  |
@@ -425,8 +521,8 @@ func (f *InitializeLabelsForm) Validate(ctx *macaron.Context, errs binding.Error
 // swagger:model MergePullRequestOption
 type MergePullRequestForm struct {
 	// required: true
-	// enum: merge,rebase,rebase-merge,squash
-	Do                string `binding:"Required;In(merge,rebase,rebase-merge,squash)"`
+	// enum: merge,rebase,rebase-merge,squash,fast-forward-only
+	Do                string `binding:"Required;In(merge,rebase,rebase-merge,squash,fast-forward-only)"`
 	MergeTitleField   string
 	MergeMessageField string
 }
@@ -0,0 +1,140 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package saml
+
+import (
+	"crypto/x509"
+	"encoding/xml"
+	"errors"
+	"fmt"
+)
+
+// IdentityProviderMetadata holds the parts of the metadata of an identity provider used by the service provider
+type IdentityProviderMetadata struct {
+	EntityID        string
+	SingleSignOnURL string
+	Certificates    []*x509.Certificate
+}
+
+type endpoint struct {
+	Binding  string `xml:",attr"`
+	Location string `xml:",attr"`
+}
+
+type keyDescriptor struct {
+	Use          string   `xml:"use,attr"`
+	Certificates []string `xml:"KeyInfo>X509Data>X509Certificate"`
+}
+
+type idpSSODescriptor struct {
+	KeyDescriptors       []keyDescriptor `xml:"KeyDescriptor"`
+	SingleSignOnServices []endpoint      `xml:"SingleSignOnService"`
+}
+
+// entityDescriptor is an EntityDescriptor or an EntitiesDescriptor grouping several of them
+type entityDescriptor struct {
+	XMLName           xml.Name
+	EntityID          string             `xml:"entityID,attr"`
+	IDPSSODescriptors []idpSSODescriptor `xml:"IDPSSODescriptor"`
+	EntityDescriptors []entityDescriptor `xml:"EntityDescriptor"`
+}
+
+// ParseIdentityProviderMetadata parses the metadata of an identity provider, it needs a single sign-on
+// service with the HTTP-Redirect binding and at least one signing certificate
+func ParseIdentityProviderMetadata(data []byte) (*IdentityProviderMetadata, error) {
+	var root entityDescriptor
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("invalid metadata: %v", err)
+	}
+	if root.XMLName.Space != nsMetadata {
+		return nil, errors.New("invalid metadata: not a SAML metadata document")
+	}
+
+	entities := []entityDescriptor{root}
+	if root.XMLName.Local == "EntitiesDescriptor" {
+		entities = root.EntityDescriptors
+	}
+	for _, entity := range entities {
+		if len(entity.IDPSSODescriptors) > 0 {
+			return parseIDPSSODescriptor(entity.EntityID, &entity.IDPSSODescriptors[0])
+		}
+	}
+	return nil, errors.New("invalid metadata: no IDPSSODescriptor found")
+}
+
+func parseIDPSSODescriptor(entityID string, descriptor *idpSSODescriptor) (*IdentityProviderMetadata, error) {
+	if len(entityID) == 0 {
+		return nil, errors.New("invalid metadata: entityID is missing")
+	}
+	metadata := &IdentityProviderMetadata{EntityID: entityID}
+	for _, service := range descriptor.SingleSignOnServices {
+		if service.Binding == bindingHTTPRedirect {
+			metadata.SingleSignOnURL = service.Location
+			break
+		}
+	}
+	if len(metadata.SingleSignOnURL) == 0 {
+		return nil, errors.New("invalid metadata: no SingleSignOnService with the HTTP-Redirect binding")
+	}
+
+	for _, key := range descriptor.KeyDescriptors {
+		if len(key.Use) > 0 && key.Use != "signing" {
+			continue
+		}
+		for _, data := range key.Certificates {
+			der, err := decodeBase64(data)
+			if err != nil {
+				return nil, fmt.Errorf("invalid metadata: %v", err)
+			}
+			cert, err := x509.ParseCertificate(der)
+			if err != nil {
+				return nil, fmt.Errorf("invalid metadata: %v", err)
+			}
+			metadata.Certificates = append(metadata.Certificates, cert)
+		}
+	}
+	if len(metadata.Certificates) == 0 {
+		return nil, errors.New("invalid metadata: no signing certificate")
+	}
+	return metadata, nil
+}
+
+type indexedEndpoint struct {
+	Binding   string `xml:",attr"`
+	Location  string `xml:",attr"`
+	Index     int    `xml:"index,attr"`
+	IsDefault bool   `xml:"isDefault,attr"`
+}
+
+type spEntityDescriptor struct {
+	XMLName         xml.Name `xml:"urn:oasis:names:tc:SAML:2.0:metadata EntityDescriptor"`
+	EntityID        string   `xml:"entityID,attr"`
+	SPSSODescriptor struct {
+		AuthnRequestsSigned        bool              `xml:",attr"`
+		WantAssertionsSigned       bool              `xml:",attr"`
+		ProtocolSupportEnumeration string            `xml:"protocolSupportEnumeration,attr"`
+		NameIDFormats              []string          `xml:"NameIDFormat"`
+		AssertionConsumerServices  []indexedEndpoint `xml:"AssertionConsumerService"`
+	}
+}
+
+// Metadata returns the metadata of the service provider to be imported by the identity provider
+func (sp *ServiceProvider) Metadata() ([]byte, error) {
+	descriptor := &spEntityDescriptor{EntityID: sp.EntityID}
+	descriptor.SPSSODescriptor.WantAssertionsSigned = true
+	descriptor.SPSSODescriptor.ProtocolSupportEnumeration = nsProtocol
+	descriptor.SPSSODescriptor.NameIDFormats = []string{nameIDFormatPersistent, nameIDFormatUnspecified}
+	descriptor.SPSSODescriptor.AssertionConsumerServices = []indexedEndpoint{{
+		Binding:   bindingHTTPPost,
+		Location:  sp.AssertionConsumerServiceURL,
+		IsDefault: true,
+	}}
+
+	data, err := xml.MarshalIndent(descriptor, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
@@ -0,0 +1,208 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package saml
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// clockSkew is the difference allowed between the clocks of the identity provider and Gitea
+const clockSkew = 3 * time.Minute
+
+// Assertion is the authenticated subject of a verified response and its attributes,
+// the attributes are stored by name and by friendly name
+type Assertion struct {
+	NameID       string
+	SessionIndex string
+	Attributes   map[string][]string
+}
+
+// Attribute returns the first value of an attribute
+func (a *Assertion) Attribute(name string) string {
+	if values := a.Attributes[name]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// ParseResponse verifies the base64 encoded response of the HTTP-POST binding to the authentication
+// request with the ID and returns its assertion
+func (sp *ServiceProvider) ParseResponse(samlResponse, requestID string) (*Assertion, error) {
+	data, err := decodeBase64(samlResponse)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SAMLResponse: %v", err)
+	}
+	return sp.parseResponse(data, requestID, time.Now())
+}
+
+func (sp *ServiceProvider) parseResponse(data []byte, requestID string, now time.Time) (*Assertion, error) {
+	if len(requestID) == 0 {
+		return nil, errors.New("unsolicited responses are not accepted")
+	}
+	response, err := parseXML(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SAMLResponse: %v", err)
+	}
+	if !response.is(nsProtocol, "Response") {
+		return nil, errors.New("SAMLResponse is not a Response")
+	}
+	if destination := response.attr("Destination"); len(destination) > 0 && destination != sp.AssertionConsumerServiceURL {
+		return nil, fmt.Errorf("response is sent to %s", destination)
+	}
+	if response.attr("InResponseTo") != requestID {
+		return nil, errors.New("response does not refer to the authentication request")
+	}
+	if issuer := response.element(nsAssertion, "Issuer"); issuer != nil && issuer.text() != sp.IdentityProvider.EntityID {
+		return nil, fmt.Errorf("response is issued by %s", issuer.text())
+	}
+
+	var statusCode, statusMessage *node
+	if status := response.element(nsProtocol, "Status"); status != nil {
+		statusCode = status.element(nsProtocol, "StatusCode")
+		statusMessage = status.element(nsProtocol, "StatusMessage")
+	}
+	if statusCode == nil {
+		return nil, errors.New("response has no status")
+	} else if statusCode.attr("Value") != statusSuccess {
+		if statusMessage != nil {
+			return nil, fmt.Errorf("identity provider returned %s: %s", statusCode.attr("Value"), statusMessage.text())
+		}
+		return nil, fmt.Errorf("identity provider returned %s", statusCode.attr("Value"))
+	}
+
+	if response.element(nsAssertion, "EncryptedAssertion") != nil {
+		return nil, errors.New("encrypted assertions are not supported")
+	}
+	assertions := response.elements(nsAssertion, "Assertion")
+	if len(assertions) != 1 {
+		return nil, fmt.Errorf("response has %d assertions", len(assertions))
+	}
+	assertion := assertions[0]
+
+	signed := false
+	if response.element(nsDSig, "Signature") != nil {
+		if err = verifySignature(response, sp.IdentityProvider.Certificates); err != nil {
+			return nil, err
+		}
+		signed = true
+	}
+	if assertion.element(nsDSig, "Signature") != nil {
+		if err = verifySignature(assertion, sp.IdentityProvider.Certificates); err != nil {
+			return nil, err
+		}
+		signed = true
+	}
+	if !signed {
+		return nil, errors.New("neither response nor assertion is signed")
+	}
+
+	return sp.parseAssertion(assertion, requestID, now)
+}
+
+func (sp *ServiceProvider) parseAssertion(assertion *node, requestID string, now time.Time) (*Assertion, error) {
+	if issuer := assertion.element(nsAssertion, "Issuer"); issuer == nil || issuer.text() != sp.IdentityProvider.EntityID {
+		return nil, errors.New("assertion is not issued by the identity provider")
+	}
+
+	subject := assertion.element(nsAssertion, "Subject")
+	if subject == nil {
+		return nil, errors.New("assertion has no subject")
+	}
+	nameID := subject.element(nsAssertion, "NameID")
+	if nameID == nil || len(nameID.text()) == 0 {
+		return nil, errors.New("assertion has no NameID")
+	}
+	if !sp.isSubjectConfirmed(subject, requestID, now) {
+		return nil, errors.New("assertion has no valid bearer subject confirmation")
+	}
+
+	if conditions := assertion.element(nsAssertion, "Conditions"); conditions != nil {
+		if err := sp.checkConditions(conditions, now); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &Assertion{
+		NameID:     nameID.text(),
+		Attributes: make(map[string][]string),
+	}
+	if statement := assertion.element(nsAssertion, "AuthnStatement"); statement != nil {
+		result.SessionIndex = statement.attr("SessionIndex")
+	}
+	for _, statement := range assertion.elements(nsAssertion, "AttributeStatement") {
+		for _, attribute := range statement.elements(nsAssertion, "Attribute") {
+			var values []string
+			for _, value := range attribute.elements(nsAssertion, "AttributeValue") {
+				values = append(values, value.text())
+			}
+			for _, name := range []string{attribute.attr("Name"), attribute.attr("FriendlyName")} {
+				if len(name) > 0 {
+					result.Attributes[name] = append(result.Attributes[name], values...)
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+// isSubjectConfirmed returns true if the subject has a bearer confirmation for this service provider and request
+func (sp *ServiceProvider) isSubjectConfirmed(subject *node, requestID string, now time.Time) bool {
+	for _, confirmation := range subject.elements(nsAssertion, "SubjectConfirmation") {
+		if confirmation.attr("Method") != subjectConfirmationBearer {
+			continue
+		}
+		data := confirmation.element(nsAssertion, "SubjectConfirmationData")
+		if data == nil || data.attr("Recipient") != sp.AssertionConsumerServiceURL {
+			continue
+		}
+		if inResponseTo := data.attr("InResponseTo"); len(inResponseTo) > 0 && inResponseTo != requestID {
+			continue
+		}
+		notOnOrAfter, err := time.Parse(time.RFC3339, data.attr("NotOnOrAfter"))
+		if err != nil || !now.Before(notOnOrAfter.Add(clockSkew)) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// checkConditions checks the validity period and the audience of an assertion
+func (sp *ServiceProvider) checkConditions(conditions *node, now time.Time) error {
+	if notBefore := conditions.attr("NotBefore"); len(notBefore) > 0 {
+		t, err := time.Parse(time.RFC3339, notBefore)
+		if err != nil {
+			return fmt.Errorf("invalid NotBefore: %v", err)
+		}
+		if now.Add(clockSkew).Before(t) {
+			return errors.New("assertion is not yet valid")
+		}
+	}
+	if notOnOrAfter := conditions.attr("NotOnOrAfter"); len(notOnOrAfter) > 0 {
+		t, err := time.Parse(time.RFC3339, notOnOrAfter)
+		if err != nil {
+			return fmt.Errorf("invalid NotOnOrAfter: %v", err)
+		}
+		if !now.Before(t.Add(clockSkew)) {
+			return errors.New("assertion has expired")
+		}
+	}
+
+	for _, restriction := range conditions.elements(nsAssertion, "AudienceRestriction") {
+		allowed := false
+		for _, audience := range restriction.elements(nsAssertion, "Audience") {
+			if audience.text() == sp.EntityID {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return errors.New("assertion is not intended for this service provider")
+		}
+	}
+	return nil
+}
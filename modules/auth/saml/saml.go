@@ -0,0 +1,187 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package saml implements the service provider side of the SAML 2.0 Web Browser SSO profile:
+// authentication requests are sent with the HTTP-Redirect binding and signed responses are
+// received with the HTTP-POST binding. Encrypted assertions are not supported.
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/masoodkamyab/gitea/modules/setting"
+)
+
+// The SAML namespaces, bindings and formats
+const (
+	nsMetadata  = "urn:oasis:names:tc:SAML:2.0:metadata"
+	nsProtocol  = "urn:oasis:names:tc:SAML:2.0:protocol"
+	nsAssertion = "urn:oasis:names:tc:SAML:2.0:assertion"
+
+	bindingHTTPRedirect = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect"
+	bindingHTTPPost     = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST"
+
+	nameIDFormatPersistent  = "urn:oasis:names:tc:SAML:2.0:nameid-format:persistent"
+	nameIDFormatUnspecified = "urn:oasis:names:tc:SAML:1.1:nameid-format:unspecified"
+
+	statusSuccess             = "urn:oasis:names:tc:SAML:2.0:status:Success"
+	subjectConfirmationBearer = "urn:oasis:names:tc:SAML:2.0:cm:bearer"
+
+	timeFormat = "2006-01-02T15:04:05Z"
+)
+
+// ServiceProvider is the Gitea side of a SAML login source trusting a single identity provider
+type ServiceProvider struct {
+	EntityID                    string
+	AssertionConsumerServiceURL string
+	IdentityProvider            *IdentityProviderMetadata
+}
+
+var (
+	providers     = make(map[string]*ServiceProvider)
+	providersLock sync.RWMutex
+
+	metadataClient = &http.Client{Timeout: 30 * time.Second}
+)
+
+// RegisterProvider registers the service provider of a SAML login source. The metadata of the
+// identity provider is given as XML or, if empty, fetched from the metadata URL.
+func RegisterProvider(providerName, metadataURL, metadata string) error {
+	data := []byte(metadata)
+	if len(data) == 0 {
+		var err error
+		if data, err = fetchMetadata(metadataURL); err != nil {
+			return err
+		}
+	}
+	idp, err := ParseIdentityProviderMetadata(data)
+	if err != nil {
+		return err
+	}
+
+	baseURL := setting.AppURL + "user/saml/" + url.PathEscape(providerName)
+	providersLock.Lock()
+	providers[providerName] = &ServiceProvider{
+		EntityID:                    baseURL + "/metadata",
+		AssertionConsumerServiceURL: baseURL + "/acs",
+		IdentityProvider:            idp,
+	}
+	providersLock.Unlock()
+	return nil
+}
+
+// RemoveProvider removes the service provider of a SAML login source
+func RemoveProvider(providerName string) {
+	providersLock.Lock()
+	delete(providers, providerName)
+	providersLock.Unlock()
+}
+
+// GetProvider returns the registered service provider of a SAML login source
+func GetProvider(providerName string) (*ServiceProvider, error) {
+	providersLock.RLock()
+	defer providersLock.RUnlock()
+	sp, ok := providers[providerName]
+	if !ok {
+		return nil, fmt.Errorf("SAML provider %s is not registered", providerName)
+	}
+	return sp, nil
+}
+
+func fetchMetadata(metadataURL string) ([]byte, error) {
+	if len(metadataURL) == 0 {
+		return nil, fmt.Errorf("neither metadata nor metadata URL of the identity provider is given")
+	}
+	resp, err := metadataClient.Get(metadataURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching metadata from %s: %s", metadataURL, resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+type authnRequest struct {
+	XMLName                     xml.Name `xml:"urn:oasis:names:tc:SAML:2.0:protocol AuthnRequest"`
+	ID                          string   `xml:",attr"`
+	Version                     string   `xml:",attr"`
+	IssueInstant                string   `xml:",attr"`
+	Destination                 string   `xml:",attr"`
+	AssertionConsumerServiceURL string   `xml:",attr"`
+	ProtocolBinding             string   `xml:",attr"`
+	Issuer                      string   `xml:"urn:oasis:names:tc:SAML:2.0:assertion Issuer"`
+	NameIDPolicy                struct {
+		AllowCreate bool `xml:",attr"`
+	}
+}
+
+// AuthnRequestURL returns the URL sending the user with a new authentication request to the
+// identity provider, and the ID of the request the response has to refer to
+func (sp *ServiceProvider) AuthnRequestURL(relayState string) (string, string, error) {
+	id, err := newID()
+	if err != nil {
+		return "", "", err
+	}
+	req := &authnRequest{
+		ID:                          id,
+		Version:                     "2.0",
+		IssueInstant:                time.Now().UTC().Format(timeFormat),
+		Destination:                 sp.IdentityProvider.SingleSignOnURL,
+		AssertionConsumerServiceURL: sp.AssertionConsumerServiceURL,
+		ProtocolBinding:             bindingHTTPPost,
+		Issuer:                      sp.EntityID,
+	}
+	req.NameIDPolicy.AllowCreate = true
+	data, err := xml.Marshal(req)
+	if err != nil {
+		return "", "", err
+	}
+
+	// the HTTP-Redirect binding deflates the request
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.DefaultCompression)
+	if err != nil {
+		return "", "", err
+	}
+	if _, err = w.Write(data); err != nil {
+		return "", "", err
+	}
+	if err = w.Close(); err != nil {
+		return "", "", err
+	}
+
+	u, err := url.Parse(sp.IdentityProvider.SingleSignOnURL)
+	if err != nil {
+		return "", "", err
+	}
+	query := u.Query()
+	query.Set("SAMLRequest", base64.StdEncoding.EncodeToString(buf.Bytes()))
+	if len(relayState) > 0 {
+		query.Set("RelayState", relayState)
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), id, nil
+}
+
+// newID returns a random ID, it starts with an underscore since an xs:ID must not start with a digit
+func newID() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "_" + hex.EncodeToString(b), nil
+}
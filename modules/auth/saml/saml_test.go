@@ -0,0 +1,197 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package saml
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/masoodkamyab/gitea/modules/auth/saml/samltest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonicalize(t *testing.T) {
+	kases := []struct {
+		xml, canonical string
+		prefixes       []string
+	}{
+		// attributes are sorted, empty elements expanded and unused namespaces dropped
+		{
+			`<a:root xmlns:a="urn:a" xmlns:b="urn:b" z="1" a="2"><a:child/></a:root>`,
+			`<a:root xmlns:a="urn:a" a="2" z="1"><a:child></a:child></a:root>`,
+			nil,
+		},
+		// namespaces are rendered where they are used and attributes sorted by namespace first
+		{
+			`<root xmlns="urn:d" xmlns:b="urn:b"><b:child b:y="1" x="2"/><child/></root>`,
+			`<root xmlns="urn:d"><b:child xmlns:b="urn:b" x="2" b:y="1"></b:child><child></child></root>`,
+			nil,
+		},
+		// the default namespace is undeclared again and inclusive prefixes are rendered at the apex
+		{
+			`<root xmlns="urn:d" xmlns:xs="urn:xs"><child xmlns=""/></root>`,
+			`<root xmlns="urn:d" xmlns:xs="urn:xs"><child xmlns=""></child></root>`,
+			[]string{"xs"},
+		},
+		// text and attributes are escaped, comments and processing instructions dropped
+		{
+			`<?xml version="1.0"?><root a="&quot;&lt;&#9;"><!-- comment --><?pi?>&amp;&lt;&gt;"'</root>`,
+			`<root a="&quot;&lt;&#x9;">&amp;&lt;&gt;"'</root>`,
+			nil,
+		},
+	}
+	for _, kase := range kases {
+		n, err := parseXML([]byte(kase.xml))
+		assert.NoError(t, err)
+		assert.Equal(t, kase.canonical, string(canonicalize(n, nil, kase.prefixes)))
+	}
+
+	_, err := parseXML([]byte(`<!DOCTYPE root [<!ENTITY e "e">]><root>&e;</root>`))
+	assert.Error(t, err)
+	_, err = parseXML([]byte(`<root><a></b></root>`))
+	assert.Error(t, err)
+}
+
+func newTestProvider(t *testing.T) (*ServiceProvider, *samltest.IdentityProvider) {
+	idp, err := samltest.NewIdentityProvider("https://idp.example.com/metadata", "https://idp.example.com/sso?tenant=1")
+	assert.NoError(t, err)
+	metadata, err := ParseIdentityProviderMetadata(idp.Metadata())
+	assert.NoError(t, err)
+	return &ServiceProvider{
+		EntityID:                    "https://try.gitea.io/user/saml/test/metadata",
+		AssertionConsumerServiceURL: "https://try.gitea.io/user/saml/test/acs",
+		IdentityProvider:            metadata,
+	}, idp
+}
+
+func TestParseIdentityProviderMetadata(t *testing.T) {
+	sp, idp := newTestProvider(t)
+	assert.Equal(t, idp.EntityID, sp.IdentityProvider.EntityID)
+	assert.Equal(t, idp.SingleSignOnURL, sp.IdentityProvider.SingleSignOnURL)
+	if assert.Len(t, sp.IdentityProvider.Certificates, 1) {
+		assert.Equal(t, idp.Certificate, sp.IdentityProvider.Certificates[0].Raw)
+	}
+
+	_, err := ParseIdentityProviderMetadata([]byte(`<EntityDescriptor xmlns="urn:oasis:names:tc:SAML:2.0:metadata" entityID="idp"></EntityDescriptor>`))
+	assert.Error(t, err)
+	_, err = ParseIdentityProviderMetadata(bytes.Replace(idp.Metadata(), []byte("HTTP-Redirect"), []byte("HTTP-POST"), 1))
+	assert.Error(t, err)
+}
+
+func TestServiceProvider_AuthnRequestURL(t *testing.T) {
+	sp, _ := newTestProvider(t)
+	redirectURL, id, err := sp.AuthnRequestURL("state")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(redirectURL, "https://idp.example.com/sso?"))
+	assert.Contains(t, redirectURL, "tenant=1")
+	assert.Contains(t, redirectURL, "RelayState=state")
+
+	req, err := samltest.ParseAuthnRequestURL(redirectURL)
+	assert.NoError(t, err)
+	assert.Equal(t, id, req.ID)
+	assert.Equal(t, sp.AssertionConsumerServiceURL, req.AssertionConsumerServiceURL)
+	assert.Equal(t, sp.EntityID, req.Issuer)
+}
+
+func TestServiceProvider_Metadata(t *testing.T) {
+	sp, _ := newTestProvider(t)
+	data, err := sp.Metadata()
+	assert.NoError(t, err)
+	n, err := parseXML(data)
+	assert.NoError(t, err)
+	assert.True(t, n.is(nsMetadata, "EntityDescriptor"))
+	assert.Equal(t, sp.EntityID, n.attr("entityID"))
+	descriptor := n.element(nsMetadata, "SPSSODescriptor")
+	if assert.NotNil(t, descriptor) {
+		acs := descriptor.element(nsMetadata, "AssertionConsumerService")
+		if assert.NotNil(t, acs) {
+			assert.Equal(t, sp.AssertionConsumerServiceURL, acs.attr("Location"))
+			assert.Equal(t, bindingHTTPPost, acs.attr("Binding"))
+		}
+	}
+}
+
+func TestServiceProvider_ParseResponse(t *testing.T) {
+	sp, idp := newTestProvider(t)
+	response := func() *samltest.Response {
+		return &samltest.Response{
+			InResponseTo: "_request",
+			Destination:  sp.AssertionConsumerServiceURL,
+			Audience:     sp.EntityID,
+			NameID:       "jdoe",
+			Attributes: map[string][]string{
+				"uid":    {"john"},
+				"groups": {"admins", "developers & testers"},
+			},
+		}
+	}
+	now := time.Now()
+
+	for _, r := range []*samltest.Response{
+		response(),
+		func() *samltest.Response { r := response(); r.SignResponse = true; return r }(),
+		func() *samltest.Response { r := response(); r.SignOnlyResponse = true; return r }(),
+	} {
+		assertion, err := sp.parseResponse(idp.ResponseXML(r), "_request", now)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "jdoe", assertion.NameID)
+			assert.Equal(t, "john", assertion.Attribute("uid"))
+			assert.Equal(t, []string{"admins", "developers & testers"}, assertion.Attributes["groups"])
+			assert.Equal(t, "", assertion.Attribute("missing"))
+		}
+	}
+
+	assertion, err := sp.ParseResponse(idp.Response(response()), "_request")
+	assert.NoError(t, err)
+	assert.Equal(t, "jdoe", assertion.NameID)
+
+	// the response has to refer to the request
+	_, err = sp.parseResponse(idp.ResponseXML(response()), "_other", now)
+	assert.Error(t, err)
+	_, err = sp.parseResponse(idp.ResponseXML(response()), "", now)
+	assert.Error(t, err)
+
+	// the assertion has to be valid now
+	_, err = sp.parseResponse(idp.ResponseXML(response()), "_request", now.Add(time.Hour))
+	assert.Error(t, err)
+	_, err = sp.parseResponse(idp.ResponseXML(response()), "_request", now.Add(-time.Hour))
+	assert.Error(t, err)
+
+	// the assertion has to be intended for the service provider
+	r := response()
+	r.Audience = "https://other.example.com"
+	_, err = sp.parseResponse(idp.ResponseXML(r), "_request", now)
+	assert.Error(t, err)
+	r = response()
+	r.Destination = "https://other.example.com/acs"
+	_, err = sp.parseResponse(idp.ResponseXML(r), "_request", now)
+	assert.Error(t, err)
+
+	// tampered assertions are rejected
+	data := bytes.Replace(idp.ResponseXML(response()), []byte(">jdoe<"), []byte(">admin<"), 1)
+	_, err = sp.parseResponse(data, "_request", now)
+	assert.Error(t, err)
+
+	// responses of other identity providers are rejected
+	other, err := samltest.NewIdentityProvider(idp.EntityID, idp.SingleSignOnURL)
+	assert.NoError(t, err)
+	_, err = sp.parseResponse(other.ResponseXML(response()), "_request", now)
+	assert.Error(t, err)
+
+	// a signed assertion wrapped into an element next to an unsigned one is not accepted
+	signed := idp.ResponseXML(response())
+	start := bytes.Index(signed, []byte("<saml:Assertion "))
+	end := bytes.Index(signed, []byte("</samlp:Response>"))
+	forged := bytes.Replace(signed[start:end], []byte(">jdoe<"), []byte(">admin<"), 1)
+	signatureStart := bytes.Index(forged, []byte("<ds:Signature "))
+	signatureEnd := bytes.Index(forged, []byte("</ds:Signature>")) + len("</ds:Signature>")
+	forged = append(forged[:signatureStart], forged[signatureEnd:]...)
+	wrapped := string(signed[:start]) + `<samlp:Extensions>` + string(signed[start:end]) + `</samlp:Extensions>` + string(forged) + string(signed[end:])
+	_, err = sp.parseResponse([]byte(wrapped), "_request", now)
+	assert.Error(t, err)
+}
@@ -0,0 +1,227 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package samltest provides a SAML identity provider stub for tests. It decodes the authentication
+// requests of the service provider and answers them with signed responses.
+package samltest
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const timeFormat = "2006-01-02T15:04:05Z"
+
+// IdentityProvider is an identity provider stub with a generated signing key
+type IdentityProvider struct {
+	EntityID        string
+	SingleSignOnURL string
+	Key             *rsa.PrivateKey
+	Certificate     []byte
+}
+
+// NewIdentityProvider creates an identity provider stub with a new key and self-signed certificate
+func NewIdentityProvider(entityID, singleSignOnURL string) (*IdentityProvider, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: entityID},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	return &IdentityProvider{
+		EntityID:        entityID,
+		SingleSignOnURL: singleSignOnURL,
+		Key:             key,
+		Certificate:     cert,
+	}, nil
+}
+
+// Metadata returns the metadata of the identity provider
+func (idp *IdentityProvider) Metadata() []byte {
+	return []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<md:EntityDescriptor xmlns:md="urn:oasis:names:tc:SAML:2.0:metadata" entityID="` + escape(idp.EntityID, true) + `">
+  <md:IDPSSODescriptor protocolSupportEnumeration="urn:oasis:names:tc:SAML:2.0:protocol">
+    <md:KeyDescriptor use="signing">
+      <ds:KeyInfo xmlns:ds="http://www.w3.org/2000/09/xmldsig#">
+        <ds:X509Data>
+          <ds:X509Certificate>` + base64.StdEncoding.EncodeToString(idp.Certificate) + `</ds:X509Certificate>
+        </ds:X509Data>
+      </ds:KeyInfo>
+    </md:KeyDescriptor>
+    <md:SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect" Location="` + escape(idp.SingleSignOnURL, true) + `"/>
+  </md:IDPSSODescriptor>
+</md:EntityDescriptor>
+`)
+}
+
+// AuthnRequest is the decoded authentication request of a service provider
+type AuthnRequest struct {
+	ID                          string `xml:",attr"`
+	Destination                 string `xml:",attr"`
+	AssertionConsumerServiceURL string `xml:",attr"`
+	Issuer                      string `xml:"Issuer"`
+}
+
+// ParseAuthnRequestURL decodes the authentication request of a URL redirecting to the identity provider
+func ParseAuthnRequestURL(redirectURL string) (*AuthnRequest, error) {
+	u, err := url.Parse(redirectURL)
+	if err != nil {
+		return nil, err
+	}
+	deflated, err := base64.StdEncoding.DecodeString(u.Query().Get("SAMLRequest"))
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadAll(flate.NewReader(bytes.NewReader(deflated)))
+	if err != nil {
+		return nil, err
+	}
+	req := new(AuthnRequest)
+	if err = xml.Unmarshal(data, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// Response describes the response to an authentication request, the assertion is signed unless only
+// the response is signed
+type Response struct {
+	InResponseTo     string
+	Destination      string
+	Audience         string
+	NameID           string
+	Attributes       map[string][]string
+	IssueInstant     time.Time
+	SignResponse     bool
+	SignOnlyResponse bool
+}
+
+// Response returns the base64 encoded response as posted to the service provider
+func (idp *IdentityProvider) Response(r *Response) string {
+	return base64.StdEncoding.EncodeToString(idp.ResponseXML(r))
+}
+
+// ResponseXML returns the response document. The elements are written in canonical form,
+// so the digests are computed over the written XML.
+func (idp *IdentityProvider) ResponseXML(r *Response) []byte {
+	issueInstant := r.IssueInstant
+	if issueInstant.IsZero() {
+		issueInstant = time.Now()
+	}
+	issued := issueInstant.UTC().Format(timeFormat)
+	expires := issueInstant.Add(5 * time.Minute).UTC().Format(timeFormat)
+	assertionID := "_assertion" + r.InResponseTo
+	responseID := "_response" + r.InResponseTo
+
+	var attributes strings.Builder
+	names := make([]string, 0, len(r.Attributes))
+	for name := range r.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		attributes.WriteString(`<saml:Attribute Name="` + escape(name, true) + `" NameFormat="urn:oasis:names:tc:SAML:2.0:attrname-format:basic">`)
+		for _, value := range r.Attributes[name] {
+			attributes.WriteString(`<saml:AttributeValue>` + escape(value, false) + `</saml:AttributeValue>`)
+		}
+		attributes.WriteString(`</saml:Attribute>`)
+	}
+
+	assertion := func(signature string) string {
+		return `<saml:Assertion xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" ID="` + assertionID + `" IssueInstant="` + issued + `" Version="2.0">` +
+			`<saml:Issuer>` + escape(idp.EntityID, false) + `</saml:Issuer>` +
+			signature +
+			`<saml:Subject>` +
+			`<saml:NameID Format="urn:oasis:names:tc:SAML:2.0:nameid-format:persistent">` + escape(r.NameID, false) + `</saml:NameID>` +
+			`<saml:SubjectConfirmation Method="urn:oasis:names:tc:SAML:2.0:cm:bearer">` +
+			`<saml:SubjectConfirmationData InResponseTo="` + escape(r.InResponseTo, true) + `" NotOnOrAfter="` + expires + `" Recipient="` + escape(r.Destination, true) + `"></saml:SubjectConfirmationData>` +
+			`</saml:SubjectConfirmation>` +
+			`</saml:Subject>` +
+			`<saml:Conditions NotBefore="` + issued + `" NotOnOrAfter="` + expires + `">` +
+			`<saml:AudienceRestriction><saml:Audience>` + escape(r.Audience, false) + `</saml:Audience></saml:AudienceRestriction>` +
+			`</saml:Conditions>` +
+			`<saml:AuthnStatement AuthnInstant="` + issued + `" SessionIndex="` + assertionID + `">` +
+			`<saml:AuthnContext><saml:AuthnContextClassRef>urn:oasis:names:tc:SAML:2.0:ac:classes:PasswordProtectedTransport</saml:AuthnContextClassRef></saml:AuthnContext>` +
+			`</saml:AuthnStatement>` +
+			`<saml:AttributeStatement>` + attributes.String() + `</saml:AttributeStatement>` +
+			`</saml:Assertion>`
+	}
+	assertionXML := assertion("")
+	if !r.SignOnlyResponse {
+		assertionXML = assertion(idp.signature(assertionID, assertion("")))
+	}
+
+	response := func(signature string) string {
+		return `<samlp:Response xmlns:samlp="urn:oasis:names:tc:SAML:2.0:protocol" Destination="` + escape(r.Destination, true) + `" ID="` + responseID + `" InResponseTo="` + escape(r.InResponseTo, true) + `" IssueInstant="` + issued + `" Version="2.0">` +
+			`<saml:Issuer xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion">` + escape(idp.EntityID, false) + `</saml:Issuer>` +
+			signature +
+			`<samlp:Status><samlp:StatusCode Value="urn:oasis:names:tc:SAML:2.0:status:Success"></samlp:StatusCode></samlp:Status>` +
+			assertionXML +
+			`</samlp:Response>`
+	}
+	if r.SignResponse || r.SignOnlyResponse {
+		return []byte(response(idp.signature(responseID, response(""))))
+	}
+	return []byte(response(""))
+}
+
+// signature returns the enveloped RSA-SHA256 signature of the canonical element with the ID
+func (idp *IdentityProvider) signature(id, canonical string) string {
+	digest := sha256.Sum256([]byte(canonical))
+	signedInfo := `<ds:SignedInfo xmlns:ds="http://www.w3.org/2000/09/xmldsig#">` +
+		`<ds:CanonicalizationMethod Algorithm="http://www.w3.org/2001/10/xml-exc-c14n#"></ds:CanonicalizationMethod>` +
+		`<ds:SignatureMethod Algorithm="http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"></ds:SignatureMethod>` +
+		`<ds:Reference URI="#` + id + `">` +
+		`<ds:Transforms>` +
+		`<ds:Transform Algorithm="http://www.w3.org/2000/09/xmldsig#enveloped-signature"></ds:Transform>` +
+		`<ds:Transform Algorithm="http://www.w3.org/2001/10/xml-exc-c14n#"></ds:Transform>` +
+		`</ds:Transforms>` +
+		`<ds:DigestMethod Algorithm="http://www.w3.org/2001/04/xmlenc#sha256"></ds:DigestMethod>` +
+		`<ds:DigestValue>` + base64.StdEncoding.EncodeToString(digest[:]) + `</ds:DigestValue>` +
+		`</ds:Reference>` +
+		`</ds:SignedInfo>`
+	hashed := sha256.Sum256([]byte(signedInfo))
+	value, err := rsa.SignPKCS1v15(rand.Reader, idp.Key, crypto.SHA256, hashed[:])
+	if err != nil {
+		panic(fmt.Sprintf("SignPKCS1v15: %v", err))
+	}
+	return `<ds:Signature xmlns:ds="http://www.w3.org/2000/09/xmldsig#">` +
+		strings.Replace(signedInfo, ` xmlns:ds="http://www.w3.org/2000/09/xmldsig#"`, "", 1) +
+		`<ds:SignatureValue>` + base64.StdEncoding.EncodeToString(value) + `</ds:SignatureValue>` +
+		`<ds:KeyInfo><ds:X509Data><ds:X509Certificate>` + base64.StdEncoding.EncodeToString(idp.Certificate) + `</ds:X509Certificate></ds:X509Data></ds:KeyInfo>` +
+		`</ds:Signature>`
+}
+
+// escape escapes text and attribute values like the canonical form
+func escape(s string, attribute bool) string {
+	s = strings.NewReplacer("&", "&amp;", "<", "&lt;").Replace(s)
+	if attribute {
+		return strings.Replace(s, `"`, "&quot;", -1)
+	}
+	return strings.Replace(s, ">", "&gt;", -1)
+}
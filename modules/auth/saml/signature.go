@@ -0,0 +1,163 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package saml
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rsa"
+	// registers the hash functions of the supported signature and digest algorithms
+	_ "crypto/sha1"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// The XML signature namespaces and algorithms
+const (
+	nsDSig                = "http://www.w3.org/2000/09/xmldsig#"
+	nsExcC14N             = "http://www.w3.org/2001/10/xml-exc-c14n#"
+	algExcC14N            = nsExcC14N
+	algEnvelopedSignature = nsDSig + "enveloped-signature"
+)
+
+var signatureAlgorithms = map[string]crypto.Hash{
+	nsDSig + "rsa-sha1": crypto.SHA1,
+	"http://www.w3.org/2001/04/xmldsig-more#rsa-sha256": crypto.SHA256,
+	"http://www.w3.org/2001/04/xmldsig-more#rsa-sha512": crypto.SHA512,
+}
+
+var digestAlgorithms = map[string]crypto.Hash{
+	nsDSig + "sha1": crypto.SHA1,
+	"http://www.w3.org/2001/04/xmlenc#sha256": crypto.SHA256,
+	"http://www.w3.org/2001/04/xmlenc#sha512": crypto.SHA512,
+}
+
+// verifySignature verifies the enveloped signature of an element with the certificates of the identity provider.
+// The signature has to be a direct child of the element and has to reference it by its ID, so no other part
+// of the document can be passed off as signed.
+func verifySignature(el *node, certificates []*x509.Certificate) error {
+	signatures := el.elements(nsDSig, "Signature")
+	if len(signatures) != 1 {
+		return fmt.Errorf("%s has %d signatures", el.local, len(signatures))
+	}
+	signature := signatures[0]
+	signedInfo := signature.element(nsDSig, "SignedInfo")
+	if signedInfo == nil {
+		return errors.New("signature has no SignedInfo")
+	}
+
+	c14nMethod := signedInfo.element(nsDSig, "CanonicalizationMethod")
+	if c14nMethod == nil || c14nMethod.attr("Algorithm") != algExcC14N {
+		return errors.New("unsupported canonicalization method")
+	}
+	signatureMethod := signedInfo.element(nsDSig, "SignatureMethod")
+	if signatureMethod == nil {
+		return errors.New("signature has no SignatureMethod")
+	}
+	signatureHash, ok := signatureAlgorithms[signatureMethod.attr("Algorithm")]
+	if !ok {
+		return fmt.Errorf("unsupported signature method %s", signatureMethod.attr("Algorithm"))
+	}
+
+	references := signedInfo.elements(nsDSig, "Reference")
+	if len(references) != 1 {
+		return fmt.Errorf("signature has %d references", len(references))
+	}
+	reference := references[0]
+	if id := el.attr("ID"); len(id) == 0 || reference.attr("URI") != "#"+id {
+		return fmt.Errorf("signature does not reference the %s", el.local)
+	}
+
+	var exclude *node
+	var prefixes []string
+	canonicalized := false
+	if transforms := reference.element(nsDSig, "Transforms"); transforms != nil {
+		for _, transform := range transforms.elements(nsDSig, "Transform") {
+			switch transform.attr("Algorithm") {
+			case algEnvelopedSignature:
+				exclude = signature
+			case algExcC14N:
+				canonicalized = true
+				prefixes = inclusivePrefixes(transform)
+			default:
+				return fmt.Errorf("unsupported transform %s", transform.attr("Algorithm"))
+			}
+		}
+	}
+	if !canonicalized {
+		return errors.New("signature reference is not canonicalized with exclusive canonicalization")
+	}
+
+	digestMethod := reference.element(nsDSig, "DigestMethod")
+	if digestMethod == nil {
+		return errors.New("signature reference has no DigestMethod")
+	}
+	digestHash, ok := digestAlgorithms[digestMethod.attr("Algorithm")]
+	if !ok {
+		return fmt.Errorf("unsupported digest method %s", digestMethod.attr("Algorithm"))
+	}
+	digestValue := reference.element(nsDSig, "DigestValue")
+	if digestValue == nil {
+		return errors.New("signature reference has no DigestValue")
+	}
+	digest, err := decodeBase64(digestValue.text())
+	if err != nil {
+		return fmt.Errorf("invalid DigestValue: %v", err)
+	}
+	h := digestHash.New()
+	h.Write(canonicalize(el, exclude, prefixes))
+	if !bytes.Equal(h.Sum(nil), digest) {
+		return fmt.Errorf("digest of the %s does not match", el.local)
+	}
+
+	signatureValue := signature.element(nsDSig, "SignatureValue")
+	if signatureValue == nil {
+		return errors.New("signature has no SignatureValue")
+	}
+	value, err := decodeBase64(signatureValue.text())
+	if err != nil {
+		return fmt.Errorf("invalid SignatureValue: %v", err)
+	}
+	h = signatureHash.New()
+	h.Write(canonicalize(signedInfo, nil, inclusivePrefixes(c14nMethod)))
+	hashed := h.Sum(nil)
+	for _, cert := range certificates {
+		if key, ok := cert.PublicKey.(*rsa.PublicKey); ok && rsa.VerifyPKCS1v15(key, signatureHash, hashed, value) == nil {
+			return nil
+		}
+	}
+	return errors.New("signature does not match any certificate of the identity provider")
+}
+
+// inclusivePrefixes returns the PrefixList of the exclusive canonicalization, #default is the default namespace
+func inclusivePrefixes(method *node) []string {
+	namespaces := method.element(nsExcC14N, "InclusiveNamespaces")
+	if namespaces == nil {
+		return nil
+	}
+	prefixes := strings.Fields(namespaces.attr("PrefixList"))
+	for i := range prefixes {
+		if prefixes[i] == "#default" {
+			prefixes[i] = ""
+		}
+	}
+	return prefixes
+}
+
+// decodeBase64 decodes base64 data that may be wrapped over several lines
+func decodeBase64(s string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '\r', '\n':
+			return -1
+		}
+		return r
+	}, s))
+}
@@ -0,0 +1,293 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package saml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+const nsXML = "http://www.w3.org/XML/1998/namespace"
+
+// node is an element of a parsed XML document. The names keep their raw prefixes
+// and the namespace declarations stay attributes, so the element can be canonicalized.
+type node struct {
+	parent   *node
+	prefix   string
+	local    string
+	attrs    []xml.Attr
+	children []interface{} // *node or string
+}
+
+// parseXML parses a document into a tree of nodes, comments and processing instructions are dropped
+func parseXML(data []byte) (*node, error) {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	var root, cur *node
+	for {
+		tok, err := d.RawToken()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := &node{
+				parent: cur,
+				prefix: t.Name.Space,
+				local:  t.Name.Local,
+				attrs:  append([]xml.Attr(nil), t.Attr...),
+			}
+			if cur != nil {
+				cur.children = append(cur.children, n)
+			} else if root != nil {
+				return nil, errors.New("multiple root elements")
+			} else {
+				root = n
+			}
+			cur = n
+		case xml.EndElement:
+			if cur == nil || cur.prefix != t.Name.Space || cur.local != t.Name.Local {
+				return nil, fmt.Errorf("unexpected end element %s", t.Name.Local)
+			}
+			cur = cur.parent
+		case xml.CharData:
+			if cur != nil {
+				cur.children = append(cur.children, string(t))
+			}
+		case xml.Directive:
+			return nil, errors.New("XML directives are not allowed")
+		}
+	}
+	if root == nil || cur != nil {
+		return nil, errors.New("incomplete XML document")
+	}
+	return root, nil
+}
+
+func isNamespaceDeclaration(attr xml.Attr) bool {
+	return attr.Name.Space == "xmlns" || (attr.Name.Space == "" && attr.Name.Local == "xmlns")
+}
+
+// namespace returns the namespace bound to the prefix in the scope of the element
+func (n *node) namespace(prefix string) string {
+	if prefix == "xml" {
+		return nsXML
+	}
+	for e := n; e != nil; e = e.parent {
+		for _, attr := range e.attrs {
+			if prefix == "" && attr.Name.Space == "" && attr.Name.Local == "xmlns" ||
+				prefix != "" && attr.Name.Space == "xmlns" && attr.Name.Local == prefix {
+				return attr.Value
+			}
+		}
+	}
+	return ""
+}
+
+// is returns true if the element has the namespace and local name
+func (n *node) is(space, local string) bool {
+	return n.local == local && n.namespace(n.prefix) == space
+}
+
+// elements returns the child elements with the namespace and local name
+func (n *node) elements(space, local string) []*node {
+	var elements []*node
+	for _, child := range n.children {
+		if e, ok := child.(*node); ok && e.is(space, local) {
+			elements = append(elements, e)
+		}
+	}
+	return elements
+}
+
+// element returns the first child element with the namespace and local name
+func (n *node) element(space, local string) *node {
+	if elements := n.elements(space, local); len(elements) > 0 {
+		return elements[0]
+	}
+	return nil
+}
+
+// attr returns the value of an attribute without namespace
+func (n *node) attr(local string) string {
+	for _, attr := range n.attrs {
+		if attr.Name.Space == "" && attr.Name.Local == local {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// text returns the trimmed character data of the element
+func (n *node) text() string {
+	var text strings.Builder
+	for _, child := range n.children {
+		if s, ok := child.(string); ok {
+			text.WriteString(s)
+		}
+	}
+	return strings.TrimSpace(text.String())
+}
+
+func (n *node) qualifiedName() string {
+	if n.prefix == "" {
+		return n.local
+	}
+	return n.prefix + ":" + n.local
+}
+
+// canonicalize returns the exclusive canonical form without comments of the element as specified
+// in https://www.w3.org/TR/xml-exc-c14n/. The excluded element is left out for the enveloped
+// signature transform, the inclusive prefixes are rendered like in inclusive canonicalization.
+func canonicalize(n, exclude *node, inclusivePrefixes []string) []byte {
+	inclusive := make(map[string]bool, len(inclusivePrefixes))
+	for _, prefix := range inclusivePrefixes {
+		inclusive[prefix] = true
+	}
+	var buf bytes.Buffer
+	writeCanonical(&buf, n, exclude, map[string]string{}, inclusive)
+	return buf.Bytes()
+}
+
+func writeCanonical(buf *bytes.Buffer, n, exclude *node, rendered map[string]string, inclusive map[string]bool) {
+	// the namespaces visibly utilized by the element and its attributes
+	prefixes := map[string]bool{n.prefix: true}
+	for _, attr := range n.attrs {
+		if !isNamespaceDeclaration(attr) && attr.Name.Space != "" {
+			prefixes[attr.Name.Space] = true
+		}
+	}
+	for prefix := range inclusive {
+		if prefix == "" || n.namespace(prefix) != "" {
+			prefixes[prefix] = true
+		}
+	}
+
+	var declarations []string
+	scope := rendered
+	for prefix := range prefixes {
+		if prefix == "xml" {
+			continue
+		}
+		uri := n.namespace(prefix)
+		if previous, ok := rendered[prefix]; ok && previous == uri || !ok && uri == "" {
+			continue
+		}
+		if len(declarations) == 0 {
+			scope = make(map[string]string, len(rendered)+len(prefixes))
+			for k, v := range rendered {
+				scope[k] = v
+			}
+		}
+		scope[prefix] = uri
+		declarations = append(declarations, prefix)
+	}
+	sort.Strings(declarations)
+
+	type attribute struct {
+		space, name, value string
+	}
+	attrs := make([]attribute, 0, len(n.attrs))
+	for _, attr := range n.attrs {
+		if isNamespaceDeclaration(attr) {
+			continue
+		}
+		a := attribute{name: attr.Name.Local, value: attr.Value}
+		if attr.Name.Space != "" {
+			a.space = n.namespace(attr.Name.Space)
+			a.name = attr.Name.Space + ":" + attr.Name.Local
+		}
+		attrs = append(attrs, a)
+	}
+	sort.Slice(attrs, func(i, j int) bool {
+		if attrs[i].space != attrs[j].space {
+			return attrs[i].space < attrs[j].space
+		}
+		return localName(attrs[i].name) < localName(attrs[j].name)
+	})
+
+	buf.WriteByte('<')
+	buf.WriteString(n.qualifiedName())
+	for _, prefix := range declarations {
+		if prefix == "" {
+			buf.WriteString(` xmlns="`)
+		} else {
+			buf.WriteString(` xmlns:` + prefix + `="`)
+		}
+		escapeAttribute(buf, scope[prefix])
+		buf.WriteByte('"')
+	}
+	for _, attr := range attrs {
+		buf.WriteString(" " + attr.name + `="`)
+		escapeAttribute(buf, attr.value)
+		buf.WriteByte('"')
+	}
+	buf.WriteByte('>')
+
+	for _, child := range n.children {
+		switch c := child.(type) {
+		case string:
+			escapeText(buf, c)
+		case *node:
+			if c != exclude {
+				writeCanonical(buf, c, exclude, scope, inclusive)
+			}
+		}
+	}
+
+	buf.WriteString("</" + n.qualifiedName() + ">")
+}
+
+func localName(name string) string {
+	if i := strings.IndexByte(name, ':'); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
+
+func escapeText(buf *bytes.Buffer, s string) {
+	for _, r := range s {
+		switch r {
+		case '&':
+			buf.WriteString("&amp;")
+		case '<':
+			buf.WriteString("&lt;")
+		case '>':
+			buf.WriteString("&gt;")
+		case '\r':
+			buf.WriteString("&#xD;")
+		default:
+			buf.WriteRune(r)
+		}
+	}
+}
+
+func escapeAttribute(buf *bytes.Buffer, s string) {
+	for _, r := range s {
+		switch r {
+		case '&':
+			buf.WriteString("&amp;")
+		case '<':
+			buf.WriteString("&lt;")
+		case '"':
+			buf.WriteString("&quot;")
+		case '\t':
+			buf.WriteString("&#x9;")
+		case '\n':
+			buf.WriteString("&#xA;")
+		case '\r':
+			buf.WriteString("&#xD;")
+		default:
+			buf.WriteRune(r)
+		}
+	}
+}
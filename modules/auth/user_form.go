@@ -145,6 +145,8 @@ type AuthorizationForm struct {
 	ClientID     string `binding:"Required"`
 	RedirectURI  string
 	State        string
+	Scope        string
+	Nonce        string
 
 	// PKCE support
 	CodeChallengeMethod string // S256, plain
@@ -300,7 +302,9 @@ func (f *AddKeyForm) Validate(ctx *macaron.Context, errs binding.Errors) binding
 
 // NewAccessTokenForm form for creating access token
 type NewAccessTokenForm struct {
-	Name string `binding:"Required;MaxSize(255)"`
+	Name    string `binding:"Required;MaxSize(255)"`
+	Scope   []string
+	Expires string
 }
 
 // Validate valideates the fields
@@ -310,8 +314,9 @@ func (f *NewAccessTokenForm) Validate(ctx *macaron.Context, errs binding.Errors)
 
 // EditOAuth2ApplicationForm form for editing oauth2 applications
 type EditOAuth2ApplicationForm struct {
-	Name        string `binding:"Required;MaxSize(255)" form:"application_name"`
-	RedirectURI string `binding:"Required" form:"redirect_uri"`
+	Name               string `binding:"Required;MaxSize(255)" form:"application_name"`
+	RedirectURI        string `binding:"Required" form:"redirect_uri"`
+	ConfidentialClient bool   `form:"confidential_client"`
 }
 
 // Validate valideates the fields
@@ -339,22 +344,37 @@ func (f *TwoFactorScratchAuthForm) Validate(ctx *macaron.Context, errs binding.E
 	return validate(errs, ctx.Data, f, ctx.Locale)
 }
 
-// U2FRegistrationForm for reserving an U2F name
-type U2FRegistrationForm struct {
-	Name string `binding:"Required"`
+// WebAuthnRegistrationForm for reserving a WebAuthn credential name
+type WebAuthnRegistrationForm struct {
+	Name string `binding:"Required;MaxSize(255)"`
 }
 
 // Validate valideates the fields
-func (f *U2FRegistrationForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+func (f *WebAuthnRegistrationForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
 	return validate(errs, ctx.Data, f, ctx.Locale)
 }
 
-// U2FDeleteForm for deleting U2F keys
-type U2FDeleteForm struct {
+// WebAuthnDeleteForm for deleting WebAuthn credentials
+type WebAuthnDeleteForm struct {
 	ID int64 `binding:"Required"`
 }
 
 // Validate valideates the fields
-func (f *U2FDeleteForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+func (f *WebAuthnDeleteForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// SaveFilterForm form for saving the query of an issue dashboard as a named filter
+type SaveFilterForm struct {
+	Name       string `binding:"Required;MaxSize(50)"`
+	Query      string
+	Sort       string
+	RepoID     int64
+	TeamID     int64
+	RedirectTo string
+}
+
+// Validate valideates the fields
+func (f *SaveFilterForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
 	return validate(errs, ctx.Data, f, ctx.Locale)
 }
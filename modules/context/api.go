@@ -78,6 +78,12 @@ func (ctx *APIContext) Error(status int, title string, obj interface{}) {
 	})
 }
 
+// HasScope returns true if the request may use the scope, only requests authenticated by an access token are limited
+func (ctx *APIContext) HasScope(scope models.AccessTokenScope) bool {
+	tokenScope, ok := ctx.Data["ApiTokenScope"].(models.AccessTokenScope)
+	return !ok || tokenScope.Has(scope)
+}
+
 func genAPILinks(curURL *url.URL, total, pageSize, curPage int) []string {
 	page := NewPagination(total, pageSize, curPage, 0)
 	paginater := page.Paginater
@@ -166,6 +172,20 @@ func APIContexter() macaron.Handler {
 		ctx := &APIContext{
 			Context: c,
 		}
+
+		// Users who did not enroll into the two-factor authentication the site
+		// requires can only enroll in the web interface
+		if c.IsSigned {
+			blocked, err := models.IsSiteTwoFactorBlocked(c.User)
+			if err != nil {
+				ctx.Error(500, "IsSiteTwoFactorBlocked", err)
+				return
+			} else if blocked {
+				ctx.Error(403, "", "two-factor authentication must be enabled")
+				return
+			}
+		}
+
 		c.Map(ctx)
 	}
 }
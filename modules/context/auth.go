@@ -6,10 +6,13 @@
 package context
 
 import (
+	"strings"
+
 	"github.com/masoodkamyab/gitea/models"
 	"github.com/masoodkamyab/gitea/modules/auth"
 	"github.com/masoodkamyab/gitea/modules/log"
 	"github.com/masoodkamyab/gitea/modules/setting"
+	"github.com/masoodkamyab/gitea/modules/util"
 	"github.com/go-macaron/csrf"
 	macaron "gopkg.in/macaron.v1"
 )
@@ -57,6 +60,13 @@ func Toggle(options *ToggleOptions) macaron.Handler {
 				ctx.Redirect(setting.AppSubURL + "/")
 				return
 			}
+
+			// The API and clients using basic authentication like git over HTTP
+			// are restricted where they are handled instead of being redirected
+			if setting.TwoFactorRequired && !auth.IsAPIPath(ctx.Req.URL.Path) && !ctx.IsBasicAuth &&
+				!CheckTwoFactorRequired(ctx) {
+				return
+			}
 		}
 
 		// Redirect to dashboard if user tries to visit any non-login page.
@@ -131,3 +141,27 @@ func Toggle(options *ToggleOptions) macaron.Handler {
 		}
 	}
 }
+
+// CheckTwoFactorRequired restricts users who did not enroll into the two-factor
+// authentication required by the site within their grace period to enrolling.
+func CheckTwoFactorRequired(ctx *Context) bool {
+	enrolled, err := ctx.User.IsTwoFactorEnrolled()
+	if err != nil {
+		ctx.ServerError("IsTwoFactorEnrolled", err)
+		return false
+	} else if enrolled {
+		return true
+	}
+	if err = ctx.User.StartTwoFactorGracePeriod(); err != nil {
+		ctx.ServerError("StartTwoFactorGracePeriod", err)
+		return false
+	}
+	ctx.Data["TwoFactorDeadline"] = ctx.User.TwoFactorDeadlineUnix
+
+	if util.TimeStampNow() <= ctx.User.TwoFactorDeadlineUnix ||
+		strings.HasPrefix(ctx.Req.URL.Path, "/user/settings/security") || ctx.Req.URL.Path == "/user/logout" {
+		return true
+	}
+	ctx.Redirect(setting.AppSubURL + "/user/settings/security")
+	return false
+}
@@ -204,6 +204,15 @@ func (ctx *Context) HandleText(status int, title string) {
 	ctx.PlainText(status, []byte(title))
 }
 
+// Audit records the audit event as done by the signed in user from the remote
+// address of the request. Failures are only logged, as the change has been
+// made already.
+func (ctx *Context) Audit(evt *models.AuditEvent) {
+	if err := models.CreateAuditEvent(ctx.User, ctx.RemoteAddr(), evt); err != nil {
+		log.Error("CreateAuditEvent: %v", err)
+	}
+}
+
 // ServeContent serves content to http request
 func (ctx *Context) ServeContent(name string, r io.ReadSeeker, params ...interface{}) {
 	modtime := time.Now()
@@ -288,6 +297,13 @@ func Contexter() macaron.Handler {
 		// Get user from session if logged in.
 		ctx.User, ctx.IsBasicAuth = auth.SignedInUser(ctx.Context, ctx.Session)
 
+		// Outside of the API an access token without the full power of its user may only read repositories
+		if scope, ok := ctx.Data["ApiTokenScope"].(models.AccessTokenScope); ok && !auth.IsAPIPath(ctx.Req.URL.Path) &&
+			!scope.Has(models.AccessTokenScopeAll) &&
+			!(scope.Has(models.AccessTokenScopeRepoRead) && (ctx.Req.Method == "GET" || ctx.Req.Method == "HEAD")) {
+			ctx.User, ctx.IsBasicAuth = nil, false
+		}
+
 		if ctx.User != nil {
 			ctx.IsSigned = true
 			ctx.Data["IsSigned"] = ctx.IsSigned
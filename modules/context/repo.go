@@ -16,7 +16,9 @@ import (
 	"github.com/masoodkamyab/gitea/modules/cache"
 	"github.com/masoodkamyab/gitea/modules/git"
 	"github.com/masoodkamyab/gitea/modules/log"
+	"github.com/masoodkamyab/gitea/modules/markup/markdown"
 	"github.com/masoodkamyab/gitea/modules/setting"
+	api "github.com/masoodkamyab/gitea/modules/structs"
 
 	"github.com/Unknwon/com"
 	"gopkg.in/editorconfig/editorconfig-core-go.v1"
@@ -169,6 +171,76 @@ func (r *Repository) GetEditorconfig() (*editorconfig.Editorconfig, error) {
 	return editorconfig.ParseBytes(data)
 }
 
+var (
+	// IssueTemplateDirCandidates issue template directories
+	IssueTemplateDirCandidates = []string{
+		".gitea/ISSUE_TEMPLATE",
+		".gitea/issue_template",
+		".github/ISSUE_TEMPLATE",
+		".github/issue_template",
+	}
+	// PullRequestTemplateDirCandidates pull request template directories
+	PullRequestTemplateDirCandidates = []string{
+		".gitea/PULL_REQUEST_TEMPLATE",
+		".gitea/pull_request_template",
+		".github/PULL_REQUEST_TEMPLATE",
+		".github/pull_request_template",
+	}
+)
+
+// GetTemplatesFromDefaultBranch returns the markdown templates with a valid front-matter of
+// the first directory of dirs which exists in the HEAD of the default repo branch.
+func (r *Repository) GetTemplatesFromDefaultBranch(dirs []string) ([]*api.IssueTemplate, error) {
+	commit, err := r.GitRepo.GetBranchCommit(r.Repository.DefaultBranch)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, dir := range dirs {
+		if entry, err := commit.GetTreeEntryByPath(dir); err != nil || !entry.IsDir() {
+			continue
+		}
+		tree, err := commit.SubTree(dir)
+		if err != nil {
+			return nil, err
+		}
+		entries, err := tree.ListEntries()
+		if err != nil {
+			return nil, err
+		}
+
+		templates := make([]*api.IssueTemplate, 0, len(entries))
+		for _, entry := range entries {
+			if !entry.IsRegular() || !markdown.IsMarkdownFile(entry.Name()) ||
+				entry.Blob().Size() >= setting.UI.MaxDisplayFileSize {
+				continue
+			}
+			reader, err := entry.Blob().DataAsync()
+			if err != nil {
+				return nil, err
+			}
+			data, err := ioutil.ReadAll(reader)
+			reader.Close()
+			if err != nil {
+				return nil, err
+			}
+
+			t := new(api.IssueTemplate)
+			if t.Content, err = markdown.ExtractMetadata(string(data), t); err != nil {
+				log.Debug("Template %s/%s of %s: %v", dir, entry.Name(), r.Repository.FullName(), err)
+				continue
+			}
+			if !t.Valid() {
+				continue
+			}
+			t.FileName = entry.Name()
+			templates = append(templates, t)
+		}
+		return templates, nil
+	}
+	return nil, nil
+}
+
 // RetrieveBaseRepo retrieves base repository
 func RetrieveBaseRepo(ctx *Context, repo *models.Repository) {
 	// Non-fork repository will not return error in this method.
@@ -251,6 +323,17 @@ func repoAssignment(ctx *Context, repo *models.Repository) {
 			EarlyResponseForGoGetMeta(ctx)
 			return
 		}
+		if ctx.IsSigned && repo.Owner.IsOrganization() {
+			blocked, err := models.IsTwoFactorBlocked(repo.Owner, ctx.User)
+			if err != nil {
+				ctx.ServerError("IsTwoFactorBlocked", err)
+				return
+			} else if blocked {
+				ctx.Flash.Error(ctx.Tr("org.settings.require_twofa_blocked", repo.Owner.Name))
+				ctx.Redirect(setting.AppSubURL + "/user/settings/security")
+				return
+			}
+		}
 		ctx.NotFound("no access right", nil)
 		return
 	}
@@ -688,5 +771,6 @@ func UnitTypes() macaron.Handler {
 		ctx.Data["UnitTypeWiki"] = models.UnitTypeWiki
 		ctx.Data["UnitTypeExternalWiki"] = models.UnitTypeExternalWiki
 		ctx.Data["UnitTypeExternalTracker"] = models.UnitTypeExternalTracker
+		ctx.Data["UnitTypeProjects"] = models.UnitTypeProjects
 	}
 }
@@ -11,6 +11,7 @@ import (
 
 	"github.com/masoodkamyab/gitea/models"
 	"github.com/masoodkamyab/gitea/modules/log"
+	"github.com/masoodkamyab/gitea/modules/pull"
 	"github.com/masoodkamyab/gitea/modules/setting"
 )
 
@@ -88,6 +89,50 @@ func NewContext() {
 			go models.RemoveOldDeletedBranches()
 		}
 	}
+	if setting.Cron.MergeQueue.Enabled {
+		entry, err = c.AddFunc("Process merge queues", setting.Cron.MergeQueue.Schedule, pull.ProcessMergeQueues)
+		if err != nil {
+			log.Fatal("Cron[Process merge queues]: %v", err)
+		}
+		if setting.Cron.MergeQueue.RunAtStart {
+			entry.Prev = time.Now()
+			entry.ExecTimes++
+			go pull.ProcessMergeQueues()
+		}
+	}
+	if setting.Cron.MilestoneSnapshots.Enabled {
+		entry, err = c.AddFunc("Record milestone snapshots", setting.Cron.MilestoneSnapshots.Schedule, models.CreateMilestoneSnapshots)
+		if err != nil {
+			log.Fatal("Cron[Record milestone snapshots]: %v", err)
+		}
+		if setting.Cron.MilestoneSnapshots.RunAtStart {
+			entry.Prev = time.Now()
+			entry.ExecTimes++
+			go models.CreateMilestoneSnapshots()
+		}
+	}
+	if setting.Cron.UserSessionsCleanup.Enabled {
+		entry, err = c.AddFunc("Clean up expired user sessions", setting.Cron.UserSessionsCleanup.Schedule, models.DeleteExpiredUserSessions)
+		if err != nil {
+			log.Fatal("Cron[Clean up expired user sessions]: %v", err)
+		}
+		if setting.Cron.UserSessionsCleanup.RunAtStart {
+			entry.Prev = time.Now()
+			entry.ExecTimes++
+			go models.DeleteExpiredUserSessions()
+		}
+	}
+	if setting.Cron.AuditLogCleanup.Enabled {
+		entry, err = c.AddFunc("Clean up old audit events", setting.Cron.AuditLogCleanup.Schedule, models.DeleteOldAuditEvents)
+		if err != nil {
+			log.Fatal("Cron[Clean up old audit events]: %v", err)
+		}
+		if setting.Cron.AuditLogCleanup.RunAtStart {
+			entry.Prev = time.Now()
+			entry.ExecTimes++
+			go models.DeleteOldAuditEvents()
+		}
+	}
 	c.Start()
 }
 
@@ -218,15 +218,22 @@ func (b *BleveIndexer) Delete(ids ...int64) error {
 
 // Search searches for issues by given conditions.
 // Returns the matching issue IDs
-func (b *BleveIndexer) Search(keyword string, repoID int64, limit, start int) (*SearchResult, error) {
+func (b *BleveIndexer) Search(keyword string, repoIDs []int64, limit, start int) (*SearchResult, error) {
 	indexerQuery := bleve.NewConjunctionQuery(
-		numericEqualityQuery(repoID, "RepoID"),
 		bleve.NewDisjunctionQuery(
 			newMatchPhraseQuery(keyword, "Title", issueIndexerAnalyzer),
 			newMatchPhraseQuery(keyword, "Content", issueIndexerAnalyzer),
 			newMatchPhraseQuery(keyword, "Comments", issueIndexerAnalyzer),
 		))
+	if len(repoIDs) > 0 {
+		repoQueries := make([]query.Query, 0, len(repoIDs))
+		for _, repoID := range repoIDs {
+			repoQueries = append(repoQueries, numericEqualityQuery(repoID, "RepoID"))
+		}
+		indexerQuery.AddQuery(bleve.NewDisjunctionQuery(repoQueries...))
+	}
 	search := bleve.NewSearchRequestOptions(indexerQuery, limit, start, false)
+	search.Fields = []string{"RepoID"}
 
 	result, err := b.indexer.Search(search)
 	if err != nil {
@@ -241,9 +248,10 @@ func (b *BleveIndexer) Search(keyword string, repoID int64, limit, start int) (*
 		if err != nil {
 			return nil, err
 		}
+		repoID, _ := hit.Fields["RepoID"].(float64)
 		ret.Hits = append(ret.Hits, Match{
 			ID:     id,
-			RepoID: repoID,
+			RepoID: int64(repoID),
 		})
 	}
 	return &ret, nil
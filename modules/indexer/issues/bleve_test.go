@@ -5,6 +5,7 @@
 package issues
 
 import (
+	"io/ioutil"
 	"os"
 	"testing"
 
@@ -12,11 +13,12 @@ import (
 )
 
 func TestBleveIndexAndSearch(t *testing.T) {
-	dir := "./bleve.index"
-	indexer := NewBleveIndexer(dir)
+	dir, err := ioutil.TempDir("", "bleve.index")
+	assert.NoError(t, err)
 	defer os.RemoveAll(dir)
+	indexer := NewBleveIndexer(dir)
 
-	_, err := indexer.Init()
+	_, err = indexer.Init()
 	assert.NoError(t, err)
 
 	err = indexer.Index([]*IndexerData{
@@ -76,7 +78,7 @@ func TestBleveIndexAndSearch(t *testing.T) {
 	)
 
 	for _, kw := range keywords {
-		res, err := indexer.Search(kw.Keyword, 2, 10, 0)
+		res, err := indexer.Search(kw.Keyword, []int64{2}, 10, 0)
 		assert.NoError(t, err)
 
 		var ids = make([]int64, 0, len(res.Hits))
@@ -26,8 +26,8 @@ func (db *DBIndexer) Delete(ids ...int64) error {
 }
 
 // Search dummy function
-func (db *DBIndexer) Search(kw string, repoID int64, limit, start int) (*SearchResult, error) {
-	total, ids, err := models.SearchIssueIDsByKeyword(kw, repoID, limit, start)
+func (db *DBIndexer) Search(kw string, repoIDs []int64, limit, start int) (*SearchResult, error) {
+	total, ids, err := models.SearchIssueIDsByKeyword(kw, repoIDs, limit, start)
 	if err != nil {
 		return nil, err
 	}
@@ -37,8 +37,7 @@ func (db *DBIndexer) Search(kw string, repoID int64, limit, start int) (*SearchR
 	}
 	for _, id := range ids {
 		result.Hits = append(result.Hits, Match{
-			ID:     id,
-			RepoID: repoID,
+			ID: id,
 		})
 	}
 	return &result, nil
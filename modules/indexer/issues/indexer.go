@@ -42,7 +42,7 @@ type Indexer interface {
 	Init() (bool, error)
 	Index(issue []*IndexerData) error
 	Delete(ids ...int64) error
-	Search(kw string, repoID int64, limit, start int) (*SearchResult, error)
+	Search(kw string, repoIDs []int64, limit, start int) (*SearchResult, error)
 }
 
 var (
@@ -194,10 +194,11 @@ func DeleteRepoIssueIndexer(repo *models.Repository) {
 	})
 }
 
-// SearchIssuesByKeyword search issue ids by keywords and repo id
-func SearchIssuesByKeyword(repoID int64, keyword string) ([]int64, error) {
+// SearchIssuesByKeyword search issue ids by keywords within the repositories of repoIDs,
+// an empty repoIDs searches all repositories
+func SearchIssuesByKeyword(repoIDs []int64, keyword string) ([]int64, error) {
 	var issueIDs []int64
-	res, err := issueIndexer.Search(keyword, repoID, 1000, 0)
+	res, err := issueIndexer.Search(keyword, repoIDs, 1000, 0)
 	if err != nil {
 		return nil, err
 	}
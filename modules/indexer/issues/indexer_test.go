@@ -6,6 +6,7 @@ package issues
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"testing"
@@ -29,8 +30,12 @@ func TestMain(m *testing.M) {
 func TestBleveSearchIssues(t *testing.T) {
 	assert.NoError(t, models.PrepareTestDatabase())
 
-	os.RemoveAll(setting.Indexer.IssueQueueDir)
-	os.RemoveAll(setting.Indexer.IssuePath)
+	tmpDir, err := ioutil.TempDir("", "issues-indexer")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	setting.Indexer.IssueQueueDir = filepath.Join(tmpDir, "issues.queue")
+	setting.Indexer.IssuePath = filepath.Join(tmpDir, "issues.bleve")
 	setting.Indexer.IssueType = "bleve"
 	if err := InitIssueIndexer(true); err != nil {
 		fatalTestError("Error InitIssueIndexer: %v\n", err)
@@ -38,19 +43,19 @@ func TestBleveSearchIssues(t *testing.T) {
 
 	time.Sleep(5 * time.Second)
 
-	ids, err := SearchIssuesByKeyword(1, "issue2")
+	ids, err := SearchIssuesByKeyword([]int64{1}, "issue2")
 	assert.NoError(t, err)
 	assert.EqualValues(t, []int64{2}, ids)
 
-	ids, err = SearchIssuesByKeyword(1, "first")
+	ids, err = SearchIssuesByKeyword([]int64{1}, "first")
 	assert.NoError(t, err)
 	assert.EqualValues(t, []int64{1}, ids)
 
-	ids, err = SearchIssuesByKeyword(1, "for")
+	ids, err = SearchIssuesByKeyword([]int64{1}, "for")
 	assert.NoError(t, err)
 	assert.EqualValues(t, []int64{1, 2, 3, 5}, ids)
 
-	ids, err = SearchIssuesByKeyword(1, "good")
+	ids, err = SearchIssuesByKeyword([]int64{1}, "good")
 	assert.NoError(t, err)
 	assert.EqualValues(t, []int64{1}, ids)
 }
@@ -63,19 +68,19 @@ func TestDBSearchIssues(t *testing.T) {
 		fatalTestError("Error InitIssueIndexer: %v\n", err)
 	}
 
-	ids, err := SearchIssuesByKeyword(1, "issue2")
+	ids, err := SearchIssuesByKeyword([]int64{1}, "issue2")
 	assert.NoError(t, err)
 	assert.EqualValues(t, []int64{2}, ids)
 
-	ids, err = SearchIssuesByKeyword(1, "first")
+	ids, err = SearchIssuesByKeyword([]int64{1}, "first")
 	assert.NoError(t, err)
 	assert.EqualValues(t, []int64{1}, ids)
 
-	ids, err = SearchIssuesByKeyword(1, "for")
+	ids, err = SearchIssuesByKeyword([]int64{1}, "for")
 	assert.NoError(t, err)
 	assert.EqualValues(t, []int64{1, 2, 3, 5}, ids)
 
-	ids, err = SearchIssuesByKeyword(1, "good")
+	ids, err = SearchIssuesByKeyword([]int64{1}, "good")
 	assert.NoError(t, err)
 	assert.EqualValues(t, []int64{1}, ids)
 }
@@ -0,0 +1,38 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package markdown
+
+import (
+	"errors"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+const frontMatterSeparator = "---"
+
+// ErrNoFrontMatter is returned by ExtractMetadata when the document does not start with a front-matter block
+var ErrNoFrontMatter = errors.New("document has no front-matter")
+
+// ExtractMetadata splits a document into its YAML front-matter, which is decoded into out,
+// and the markdown body which is returned
+func ExtractMetadata(contents string, out interface{}) (string, error) {
+	contents = strings.Replace(contents, "\r\n", "\n", -1)
+	lines := strings.Split(contents, "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != frontMatterSeparator {
+		return "", ErrNoFrontMatter
+	}
+
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) != frontMatterSeparator {
+			continue
+		}
+		if err := yaml.Unmarshal([]byte(strings.Join(lines[1:i], "\n")), out); err != nil {
+			return "", err
+		}
+		return strings.TrimLeft(strings.Join(lines[i+1:], "\n"), "\n"), nil
+	}
+	return "", ErrNoFrontMatter
+}
@@ -0,0 +1,40 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package markdown_test
+
+import (
+	"testing"
+
+	. "github.com/masoodkamyab/gitea/modules/markup/markdown"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type metaTest struct {
+	Name   string   `yaml:"name"`
+	Labels []string `yaml:"labels"`
+}
+
+func TestExtractMetadata(t *testing.T) {
+	var meta metaTest
+	body, err := ExtractMetadata("---\nname: Bug report\nlabels:\n  - bug\n  - triage\n---\n\n# Steps\n", &meta)
+	assert.NoError(t, err)
+	assert.Equal(t, "# Steps\n", body)
+	assert.Equal(t, metaTest{Name: "Bug report", Labels: []string{"bug", "triage"}}, meta)
+
+	body, err = ExtractMetadata("---\r\nname: Feature\r\n---\r\nbody", &meta)
+	assert.NoError(t, err)
+	assert.Equal(t, "body", body)
+	assert.Equal(t, "Feature", meta.Name)
+
+	_, err = ExtractMetadata("# No front-matter\n", &meta)
+	assert.Equal(t, ErrNoFrontMatter, err)
+
+	_, err = ExtractMetadata("---\nname: unterminated\n", &meta)
+	assert.Equal(t, ErrNoFrontMatter, err)
+
+	_, err = ExtractMetadata("---\nname: [broken\n---\n", &meta)
+	assert.Error(t, err)
+}
@@ -27,10 +27,14 @@ type Notifier interface {
 	NotifyIssueChangeTitle(doer *models.User, issue *models.Issue, oldTitle string)
 	NotifyIssueChangeLabels(doer *models.User, issue *models.Issue,
 		addedLabels []*models.Label, removedLabels []*models.Label)
+	NotifyIssueChangeProjectBoard(doer *models.User, issue *models.Issue, project *models.Project,
+		oldBoardID, newBoardID int64)
+	NotifyIssueTransfer(doer *models.User, issue *models.Issue, oldRepo *models.Repository)
 
 	NotifyNewPullRequest(*models.PullRequest)
 	NotifyMergePullRequest(*models.PullRequest, *models.User, *git.Repository)
 	NotifyPullRequestReview(*models.PullRequest, *models.Review, *models.Comment)
+	NotifyPullRequestReadyForReview(*models.User, *models.PullRequest)
 
 	NotifyCreateIssueComment(*models.User, *models.Repository,
 		*models.Issue, *models.Comment)
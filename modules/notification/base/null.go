@@ -42,6 +42,10 @@ func (*NullNotifier) NotifyNewPullRequest(pr *models.PullRequest) {
 func (*NullNotifier) NotifyPullRequestReview(pr *models.PullRequest, r *models.Review, comment *models.Comment) {
 }
 
+// NotifyPullRequestReadyForReview places a place holder function
+func (*NullNotifier) NotifyPullRequestReadyForReview(doer *models.User, pr *models.PullRequest) {
+}
+
 // NotifyMergePullRequest places a place holder function
 func (*NullNotifier) NotifyMergePullRequest(pr *models.PullRequest, doer *models.User, baseRepo *git.Repository) {
 }
@@ -99,6 +103,15 @@ func (*NullNotifier) NotifyIssueChangeLabels(doer *models.User, issue *models.Is
 	addedLabels []*models.Label, removedLabels []*models.Label) {
 }
 
+// NotifyIssueChangeProjectBoard places a place holder function
+func (*NullNotifier) NotifyIssueChangeProjectBoard(doer *models.User, issue *models.Issue, project *models.Project,
+	oldBoardID, newBoardID int64) {
+}
+
+// NotifyIssueTransfer places a place holder function
+func (*NullNotifier) NotifyIssueTransfer(doer *models.User, issue *models.Issue, oldRepo *models.Repository) {
+}
+
 // NotifyCreateRepository places a place holder function
 func (*NullNotifier) NotifyCreateRepository(doer *models.User, u *models.User, repo *models.Repository) {
 }
@@ -107,3 +107,7 @@ func (r *indexerNotifier) NotifyIssueChangeContent(doer *models.User, issue *mod
 func (r *indexerNotifier) NotifyIssueChangeTitle(doer *models.User, issue *models.Issue, oldTitle string) {
 	issue_indexer.UpdateIssueIndexer(issue)
 }
+
+func (r *indexerNotifier) NotifyIssueTransfer(doer *models.User, issue *models.Issue, oldRepo *models.Repository) {
+	issue_indexer.UpdateIssueIndexer(issue)
+}
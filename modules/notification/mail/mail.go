@@ -69,11 +69,21 @@ func (m *mailNotifier) NotifyIssueChangeStatus(doer *models.User, issue *models.
 }
 
 func (m *mailNotifier) NotifyNewPullRequest(pr *models.PullRequest) {
+	// Drafts are announced once they are ready for review
+	if pr.IsDraft {
+		return
+	}
 	if err := pr.Issue.MailParticipants(pr.Issue.Poster, models.ActionCreatePullRequest); err != nil {
 		log.Error("MailParticipants: %v", err)
 	}
 }
 
+func (m *mailNotifier) NotifyPullRequestReadyForReview(doer *models.User, pr *models.PullRequest) {
+	if err := pr.Issue.MailParticipants(doer, models.ActionCreatePullRequest); err != nil {
+		log.Error("MailParticipants: %v", err)
+	}
+}
+
 func (m *mailNotifier) NotifyPullRequestReview(pr *models.PullRequest, r *models.Review, comment *models.Comment) {
 	var act models.ActionType
 	if comment.Type == models.CommentTypeClose {
@@ -11,6 +11,7 @@ import (
 	"github.com/masoodkamyab/gitea/modules/notification/indexer"
 	"github.com/masoodkamyab/gitea/modules/notification/mail"
 	"github.com/masoodkamyab/gitea/modules/notification/ui"
+	"github.com/masoodkamyab/gitea/modules/notification/webhook"
 )
 
 var (
@@ -27,6 +28,7 @@ func init() {
 	RegisterNotifier(ui.NewNotifier())
 	RegisterNotifier(mail.NewNotifier())
 	RegisterNotifier(indexer.NewNotifier())
+	RegisterNotifier(webhook.NewNotifier())
 }
 
 // NotifyCreateIssueComment notifies issue comment related message to notifiers
@@ -65,6 +67,13 @@ func NotifyNewPullRequest(pr *models.PullRequest) {
 	}
 }
 
+// NotifyPullRequestReadyForReview notifies a draft pull request marked as ready for review to notifiers
+func NotifyPullRequestReadyForReview(doer *models.User, pr *models.PullRequest) {
+	for _, notifier := range notifiers {
+		notifier.NotifyPullRequestReadyForReview(doer, pr)
+	}
+}
+
 // NotifyPullRequestReview notifies new pull request review
 func NotifyPullRequestReview(pr *models.PullRequest, review *models.Review, comment *models.Comment) {
 	for _, notifier := range notifiers {
@@ -164,6 +173,21 @@ func NotifyIssueChangeLabels(doer *models.User, issue *models.Issue,
 	}
 }
 
+// NotifyIssueChangeProjectBoard notifies an issue moved between the boards of a project to notifiers
+func NotifyIssueChangeProjectBoard(doer *models.User, issue *models.Issue, project *models.Project,
+	oldBoardID, newBoardID int64) {
+	for _, notifier := range notifiers {
+		notifier.NotifyIssueChangeProjectBoard(doer, issue, project, oldBoardID, newBoardID)
+	}
+}
+
+// NotifyIssueTransfer notifies an issue transferred from another repository to notifiers
+func NotifyIssueTransfer(doer *models.User, issue *models.Issue, oldRepo *models.Repository) {
+	for _, notifier := range notifiers {
+		notifier.NotifyIssueTransfer(doer, issue, oldRepo)
+	}
+}
+
 // NotifyCreateRepository notifies create repository to notifiers
 func NotifyCreateRepository(doer *models.User, u *models.User, repo *models.Repository) {
 	for _, notifier := range notifiers {
@@ -64,6 +64,18 @@ func (ns *notificationService) NotifyIssueChangeStatus(doer *models.User, issue
 	}
 }
 
+func (ns *notificationService) NotifyIssueChangeProjectBoard(doer *models.User, issue *models.Issue, project *models.Project,
+	oldBoardID, newBoardID int64) {
+	// Reordering cards within a board is not a change worth announcing
+	if oldBoardID == newBoardID {
+		return
+	}
+	ns.issueQueue <- issueNotificationOpts{
+		issue,
+		doer.ID,
+	}
+}
+
 func (ns *notificationService) NotifyMergePullRequest(pr *models.PullRequest, doer *models.User, gitRepo *git.Repository) {
 	ns.issueQueue <- issueNotificationOpts{
 		pr.Issue,
@@ -72,12 +84,23 @@ func (ns *notificationService) NotifyMergePullRequest(pr *models.PullRequest, do
 }
 
 func (ns *notificationService) NotifyNewPullRequest(pr *models.PullRequest) {
+	// Drafts are announced once they are ready for review
+	if pr.IsDraft {
+		return
+	}
 	ns.issueQueue <- issueNotificationOpts{
 		pr.Issue,
 		pr.Issue.PosterID,
 	}
 }
 
+func (ns *notificationService) NotifyPullRequestReadyForReview(doer *models.User, pr *models.PullRequest) {
+	ns.issueQueue <- issueNotificationOpts{
+		pr.Issue,
+		doer.ID,
+	}
+}
+
 func (ns *notificationService) NotifyPullRequestReview(pr *models.PullRequest, r *models.Review, c *models.Comment) {
 	ns.issueQueue <- issueNotificationOpts{
 		pr.Issue,
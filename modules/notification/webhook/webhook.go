@@ -0,0 +1,102 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"github.com/masoodkamyab/gitea/models"
+	"github.com/masoodkamyab/gitea/modules/log"
+	"github.com/masoodkamyab/gitea/modules/notification/base"
+	api "github.com/masoodkamyab/gitea/modules/structs"
+)
+
+type webhookNotifier struct {
+	base.NullNotifier
+}
+
+var (
+	_ base.Notifier = &webhookNotifier{}
+)
+
+// NewNotifier create a new webhookNotifier notifier
+func NewNotifier() base.Notifier {
+	return &webhookNotifier{}
+}
+
+func (m *webhookNotifier) NotifyIssueChangeProjectBoard(doer *models.User, issue *models.Issue, project *models.Project,
+	oldBoardID, newBoardID int64) {
+	// Reordering cards within a board is not a change worth announcing
+	if oldBoardID == newBoardID {
+		return
+	}
+
+	var oldBoardTitle string
+	if oldBoardID > 0 {
+		oldBoard, err := models.GetProjectBoard(project.ID, oldBoardID)
+		if err != nil && !models.IsErrProjectBoardNotExist(err) {
+			log.Error("GetProjectBoard[%d]: %v", oldBoardID, err)
+			return
+		} else if err == nil {
+			oldBoardTitle = oldBoard.Title
+		}
+	}
+	var apiBoard *api.ProjectBoard
+	if newBoardID > 0 {
+		board, err := models.GetProjectBoard(project.ID, newBoardID)
+		if err != nil {
+			log.Error("GetProjectBoard[%d]: %v", newBoardID, err)
+			return
+		}
+		apiBoard = board.APIFormat()
+	}
+
+	if err := project.LoadAttributes(); err != nil {
+		log.Error("LoadAttributes: %v", err)
+		return
+	}
+	if err := issue.LoadAttributes(); err != nil {
+		log.Error("LoadAttributes: %v", err)
+		return
+	}
+
+	mode, _ := models.AccessLevel(doer, issue.Repo)
+	changes := &api.ChangesPayload{
+		ProjectBoard: &api.ChangesFromPayload{
+			From: oldBoardTitle,
+		},
+	}
+	var err error
+	if issue.IsPull {
+		if err = issue.PullRequest.LoadIssue(); err != nil {
+			log.Error("LoadIssue: %v", err)
+			return
+		}
+		err = models.PrepareWebhooks(issue.Repo, models.HookEventPullRequest, &api.PullRequestPayload{
+			Action:       api.HookIssueProjectBoardChanged,
+			Index:        issue.Index,
+			Changes:      changes,
+			PullRequest:  issue.PullRequest.APIFormat(),
+			Repository:   issue.Repo.APIFormat(mode),
+			Sender:       doer.APIFormat(),
+			Project:      project.APIFormat(),
+			ProjectBoard: apiBoard,
+		})
+	} else {
+		err = models.PrepareWebhooks(issue.Repo, models.HookEventIssues, &api.IssuePayload{
+			Action:       api.HookIssueProjectBoardChanged,
+			Index:        issue.Index,
+			Changes:      changes,
+			Issue:        issue.APIFormat(),
+			Repository:   issue.Repo.APIFormat(mode),
+			Sender:       doer.APIFormat(),
+			Project:      project.APIFormat(),
+			ProjectBoard: apiBoard,
+		})
+	}
+	if err != nil {
+		log.Error("PrepareWebhooks [is_pull: %v]: %v", issue.IsPull, err)
+	} else {
+		go models.HookQueue.Add(issue.RepoID)
+	}
+}
@@ -20,10 +20,11 @@ type KeyAndOwner struct {
 	Owner *models.User      `json:"user"`
 }
 
-// ServNoCommand returns information about the provided key
-func ServNoCommand(keyID int64) (*models.PublicKey, *models.User, error) {
-	reqURL := setting.LocalURL + fmt.Sprintf("api/internal/serv/none/%d",
-		keyID)
+// ServNoCommand returns information about the provided key,
+// or about the user of an SSH certificate if certUserID is set
+func ServNoCommand(keyID, certUserID int64) (*models.PublicKey, *models.User, error) {
+	reqURL := setting.LocalURL + fmt.Sprintf("api/internal/serv/none/%d?cert_user=%d",
+		keyID, certUserID)
 	resp, err := newInternalRequest(reqURL, "GET").Response()
 	if err != nil {
 		return nil, nil, err
@@ -71,13 +72,15 @@ func IsErrServCommand(err error) bool {
 	return ok
 }
 
-// ServCommand preps for a serv call
-func ServCommand(keyID int64, ownerName, repoName string, mode models.AccessMode, verbs ...string) (*ServCommandResults, error) {
-	reqURL := setting.LocalURL + fmt.Sprintf("api/internal/serv/command/%d/%s/%s?mode=%d",
+// ServCommand preps for a serv call, certUserID is set instead of keyID
+// for sessions authenticated by an SSH certificate
+func ServCommand(keyID, certUserID int64, ownerName, repoName string, mode models.AccessMode, verbs ...string) (*ServCommandResults, error) {
+	reqURL := setting.LocalURL + fmt.Sprintf("api/internal/serv/command/%d/%s/%s?mode=%d&cert_user=%d",
 		keyID,
 		url.PathEscape(ownerName),
 		url.PathEscape(repoName),
-		mode)
+		mode,
+		certUserID)
 	for _, verb := range verbs {
 		if verb != "" {
 			reqURL += fmt.Sprintf("&verb=%s", url.QueryEscape(verb))
@@ -0,0 +1,57 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"fmt"
+
+	"github.com/masoodkamyab/gitea/models"
+	"github.com/masoodkamyab/gitea/modules/git"
+	"github.com/masoodkamyab/gitea/modules/repofiles"
+)
+
+// GetConflicts returns the conflicts of a trial merge of the base branch of pr into its head branch
+func GetConflicts(pr *models.PullRequest) (*repofiles.MergeConflicts, error) {
+	if err := pr.GetHeadRepo(); err != nil {
+		return nil, fmt.Errorf("GetHeadRepo: %v", err)
+	} else if pr.HeadRepo == nil {
+		return nil, models.ErrPullRequestHeadRepoMissing{ID: pr.ID, HeadRepoID: pr.HeadRepoID}
+	} else if err = pr.GetBaseRepo(); err != nil {
+		return nil, fmt.Errorf("GetBaseRepo: %v", err)
+	}
+
+	baseCommitID, err := git.GetFullCommitID(pr.BaseRepo.RepoPath(), git.BranchPrefix+pr.BaseBranch)
+	if err != nil {
+		return nil, fmt.Errorf("GetFullCommitID: %v", err)
+	}
+
+	return repofiles.GetMergeConflicts(pr.HeadRepo, &repofiles.MergeOptions{
+		Branch:        pr.HeadBranch,
+		MergeRepo:     pr.BaseRepo,
+		MergeBranch:   pr.BaseBranch,
+		MergeCommitID: baseCommitID,
+	})
+}
+
+// ResolveConflicts merges baseCommitID into the head branch of pr, which must still be at
+// headCommitID, with the given resolutions of the conflicted files and pushes it as doer.
+func ResolveConflicts(pr *models.PullRequest, doer *models.User, headCommitID, baseCommitID, message string, resolutions map[string]string) error {
+	if err := pr.CheckUserAllowedToUpdate(doer); err != nil {
+		return err
+	} else if err = pr.GetBaseRepo(); err != nil {
+		return fmt.Errorf("GetBaseRepo: %v", err)
+	}
+
+	_, err := repofiles.ResolveMergeConflicts(pr.HeadRepo, doer, &repofiles.MergeOptions{
+		Branch:        pr.HeadBranch,
+		LastCommitID:  headCommitID,
+		MergeRepo:     pr.BaseRepo,
+		MergeBranch:   pr.BaseBranch,
+		MergeCommitID: baseCommitID,
+		Message:       message,
+		Resolutions:   resolutions,
+	})
+	return err
+}
@@ -0,0 +1,38 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"fmt"
+
+	"github.com/masoodkamyab/gitea/models"
+	"github.com/masoodkamyab/gitea/modules/notification"
+)
+
+// SetDraft marks pr as a draft or as ready for review on behalf of doer.
+// A pull request converted to a draft leaves the merge queue, one marked as
+// ready for review is announced to the participants.
+func SetDraft(pr *models.PullRequest, doer *models.User, isDraft bool) error {
+	if pr.IsDraft == isDraft {
+		return nil
+	}
+
+	if err := pr.SetDraft(doer, isDraft); err != nil {
+		return err
+	}
+
+	if !isDraft {
+		notification.NotifyPullRequestReadyForReview(doer, pr)
+		return nil
+	}
+
+	entry, err := models.GetMergeQueueEntryByPullID(pr.ID)
+	if err != nil {
+		return fmt.Errorf("GetMergeQueueEntryByPullID: %v", err)
+	} else if entry != nil {
+		return RemoveFromMergeQueue(pr)
+	}
+	return nil
+}
@@ -52,10 +52,27 @@ func Merge(pr *models.PullRequest, doer *models.User, baseGitRepo *git.Repositor
 		go models.AddTestPullRequestTask(doer, pr.BaseRepo.ID, pr.BaseBranch, false)
 	}()
 
+	if _, err = rawMerge(pr, doer, baseGitRepo, mergeStyle, message, "", git.BranchPrefix+pr.BaseBranch); err != nil {
+		return err
+	}
+
+	pr.MergedCommitID, err = baseGitRepo.GetBranchCommitID(pr.BaseBranch)
+	if err != nil {
+		return fmt.Errorf("GetBranchCommit: %v", err)
+	}
+
+	finishMerge(pr, doer, baseGitRepo, mergeStyle)
+	return nil
+}
+
+// rawMerge merges the head branch of pr onto startCommitID, or onto the base branch if
+// startCommitID is empty, inside a temporary repository and pushes the result to targetRef
+// of the base repository. It returns the ID of the pushed commit.
+func rawMerge(pr *models.PullRequest, doer *models.User, baseGitRepo *git.Repository, mergeStyle models.MergeStyle, message, startCommitID, targetRef string) (string, error) {
 	// Clone base repo.
 	tmpBasePath, err := models.CreateTemporaryPath("merge")
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	defer func() {
@@ -71,154 +88,130 @@ func Merge(pr *models.PullRequest, doer *models.User, baseGitRepo *git.Repositor
 		NoCheckout: true,
 		Branch:     pr.BaseBranch,
 	}); err != nil {
-		return fmt.Errorf("git clone: %v", err)
+		return "", fmt.Errorf("git clone: %v", err)
 	}
 
-	remoteRepoName := "head_repo"
-
-	// Add head repo remote.
-	addCacheRepo := func(staging, cache string) error {
-		p := filepath.Join(staging, ".git", "objects", "info", "alternates")
-		f, err := os.OpenFile(p, os.O_APPEND|os.O_WRONLY, 0600)
-		if err != nil {
-			return err
-		}
-		defer f.Close()
-		data := filepath.Join(cache, "objects")
-		if _, err := fmt.Fprintln(f, data); err != nil {
-			return err
+	var errbuf strings.Builder
+	if len(startCommitID) > 0 {
+		// Merge on top of another commit of the base repository, whose objects are shared with the clone
+		if err := git.NewCommand("update-ref", git.BranchPrefix+pr.BaseBranch, startCommitID).RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
+			return "", fmt.Errorf("git update-ref [%s -> %s]: %s", pr.BaseBranch, startCommitID, errbuf.String())
 		}
-		return nil
 	}
 
+	remoteRepoName := "head_repo"
+
+	// Add head repo remote.
 	if err := addCacheRepo(tmpBasePath, headRepoPath); err != nil {
-		return fmt.Errorf("addCacheRepo [%s -> %s]: %v", headRepoPath, tmpBasePath, err)
+		return "", fmt.Errorf("addCacheRepo [%s -> %s]: %v", headRepoPath, tmpBasePath, err)
 	}
 
-	var errbuf strings.Builder
 	if err := git.NewCommand("remote", "add", remoteRepoName, headRepoPath).RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
-		return fmt.Errorf("git remote add [%s -> %s]: %s", headRepoPath, tmpBasePath, errbuf.String())
+		return "", fmt.Errorf("git remote add [%s -> %s]: %s", headRepoPath, tmpBasePath, errbuf.String())
 	}
 
 	// Fetch head branch
 	if err := git.NewCommand("fetch", remoteRepoName, pr.HeadBranch).RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
-		return fmt.Errorf("git fetch [%s -> %s]: %s", headRepoPath, tmpBasePath, errbuf.String())
+		return "", fmt.Errorf("git fetch [%s -> %s]: %s", headRepoPath, tmpBasePath, errbuf.String())
 	}
 
 	trackingBranch := path.Join(remoteRepoName, pr.HeadBranch)
 	stagingBranch := fmt.Sprintf("%s_%s", remoteRepoName, pr.HeadBranch)
 
-	// Enable sparse-checkout
-	sparseCheckoutList, err := getDiffTree(tmpBasePath, pr.BaseBranch, trackingBranch)
-	if err != nil {
-		return fmt.Errorf("getDiffTree: %v", err)
-	}
-
-	infoPath := filepath.Join(tmpBasePath, ".git", "info")
-	if err := os.MkdirAll(infoPath, 0700); err != nil {
-		return fmt.Errorf("creating directory failed [%s]: %v", infoPath, err)
-	}
-	sparseCheckoutListPath := filepath.Join(infoPath, "sparse-checkout")
-	if err := ioutil.WriteFile(sparseCheckoutListPath, []byte(sparseCheckoutList), 0600); err != nil {
-		return fmt.Errorf("Writing sparse-checkout file to %s: %v", sparseCheckoutListPath, err)
-	}
-
-	// Switch off LFS process (set required, clean and smudge here also)
-	if err := git.NewCommand("config", "--local", "filter.lfs.process", "").RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
-		return fmt.Errorf("git config [filter.lfs.process -> <> ]: %v", errbuf.String())
-	}
-	if err := git.NewCommand("config", "--local", "filter.lfs.required", "false").RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
-		return fmt.Errorf("git config [filter.lfs.required -> <false> ]: %v", errbuf.String())
-	}
-	if err := git.NewCommand("config", "--local", "filter.lfs.clean", "").RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
-		return fmt.Errorf("git config [filter.lfs.clean -> <> ]: %v", errbuf.String())
-	}
-	if err := git.NewCommand("config", "--local", "filter.lfs.smudge", "").RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
-		return fmt.Errorf("git config [filter.lfs.smudge -> <> ]: %v", errbuf.String())
-	}
-
-	if err := git.NewCommand("config", "--local", "core.sparseCheckout", "true").RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
-		return fmt.Errorf("git config [core.sparsecheckout -> true]: %v", errbuf.String())
-	}
-
-	// Read base branch index
-	if err := git.NewCommand("read-tree", "HEAD").RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
-		return fmt.Errorf("git read-tree HEAD: %s", errbuf.String())
+	if err := prepareSparseCheckout(tmpBasePath, pr.BaseBranch, trackingBranch); err != nil {
+		return "", err
 	}
 
 	// Merge commits.
 	switch mergeStyle {
 	case models.MergeStyleMerge:
 		if err := git.NewCommand("merge", "--no-ff", "--no-commit", trackingBranch).RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
-			return fmt.Errorf("git merge --no-ff --no-commit [%s]: %v - %s", tmpBasePath, err, errbuf.String())
+			return "", fmt.Errorf("git merge --no-ff --no-commit [%s]: %v - %s", tmpBasePath, err, errbuf.String())
 		}
 
 		sig := doer.NewGitSig()
 		if err := git.NewCommand("commit", fmt.Sprintf("--author='%s <%s>'", sig.Name, sig.Email), "-m", message).RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
-			return fmt.Errorf("git commit [%s]: %v - %s", tmpBasePath, err, errbuf.String())
+			return "", fmt.Errorf("git commit [%s]: %v - %s", tmpBasePath, err, errbuf.String())
 		}
 	case models.MergeStyleRebase:
 		// Checkout head branch
 		if err := git.NewCommand("checkout", "-b", stagingBranch, trackingBranch).RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
-			return fmt.Errorf("git checkout: %s", errbuf.String())
+			return "", fmt.Errorf("git checkout: %s", errbuf.String())
 		}
 		// Rebase before merging
 		if err := git.NewCommand("rebase", "-q", pr.BaseBranch).RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
-			return fmt.Errorf("git rebase [%s -> %s]: %s", headRepoPath, tmpBasePath, errbuf.String())
+			return "", fmt.Errorf("git rebase [%s -> %s]: %s", headRepoPath, tmpBasePath, errbuf.String())
 		}
 		// Checkout base branch again
 		if err := git.NewCommand("checkout", pr.BaseBranch).RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
-			return fmt.Errorf("git checkout: %s", errbuf.String())
+			return "", fmt.Errorf("git checkout: %s", errbuf.String())
 		}
 		// Merge fast forward
 		if err := git.NewCommand("merge", "--ff-only", "-q", stagingBranch).RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
-			return fmt.Errorf("git merge --ff-only [%s -> %s]: %s", headRepoPath, tmpBasePath, errbuf.String())
+			return "", fmt.Errorf("git merge --ff-only [%s -> %s]: %s", headRepoPath, tmpBasePath, errbuf.String())
 		}
 	case models.MergeStyleRebaseMerge:
 		// Checkout head branch
 		if err := git.NewCommand("checkout", "-b", stagingBranch, trackingBranch).RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
-			return fmt.Errorf("git checkout: %s", errbuf.String())
+			return "", fmt.Errorf("git checkout: %s", errbuf.String())
 		}
 		// Rebase before merging
 		if err := git.NewCommand("rebase", "-q", pr.BaseBranch).RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
-			return fmt.Errorf("git rebase [%s -> %s]: %s", headRepoPath, tmpBasePath, errbuf.String())
+			return "", fmt.Errorf("git rebase [%s -> %s]: %s", headRepoPath, tmpBasePath, errbuf.String())
 		}
 		// Checkout base branch again
 		if err := git.NewCommand("checkout", pr.BaseBranch).RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
-			return fmt.Errorf("git checkout: %s", errbuf.String())
+			return "", fmt.Errorf("git checkout: %s", errbuf.String())
 		}
 		// Prepare merge with commit
 		if err := git.NewCommand("merge", "--no-ff", "--no-commit", "-q", stagingBranch).RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
-			return fmt.Errorf("git merge --no-ff [%s -> %s]: %s", headRepoPath, tmpBasePath, errbuf.String())
+			return "", fmt.Errorf("git merge --no-ff [%s -> %s]: %s", headRepoPath, tmpBasePath, errbuf.String())
 		}
 
 		// Set custom message and author and create merge commit
 		sig := doer.NewGitSig()
 		if err := git.NewCommand("commit", fmt.Sprintf("--author='%s <%s>'", sig.Name, sig.Email), "-m", message).RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
-			return fmt.Errorf("git commit [%s]: %v - %s", tmpBasePath, err, errbuf.String())
+			return "", fmt.Errorf("git commit [%s]: %v - %s", tmpBasePath, err, errbuf.String())
 		}
 
 	case models.MergeStyleSquash:
 		// Merge with squash
 		if err := git.NewCommand("merge", "-q", "--squash", trackingBranch).RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
-			return fmt.Errorf("git merge --squash [%s -> %s]: %s", headRepoPath, tmpBasePath, errbuf.String())
+			return "", fmt.Errorf("git merge --squash [%s -> %s]: %s", headRepoPath, tmpBasePath, errbuf.String())
 		}
 		sig := pr.Issue.Poster.NewGitSig()
 		if err := git.NewCommand("commit", fmt.Sprintf("--author='%s <%s>'", sig.Name, sig.Email), "-m", message).RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
-			return fmt.Errorf("git commit [%s]: %v - %s", tmpBasePath, err, errbuf.String())
+			return "", fmt.Errorf("git commit [%s]: %v - %s", tmpBasePath, err, errbuf.String())
+		}
+	case models.MergeStyleFastForwardOnly:
+		// Refuse unless the base branch is an ancestor of the head branch
+		var ancestorErrbuf strings.Builder
+		if err := git.NewCommand("merge-base", "--is-ancestor", "HEAD", trackingBranch).RunInDirPipeline(tmpBasePath, nil, &ancestorErrbuf); err != nil {
+			if ancestorErrbuf.Len() > 0 {
+				return "", fmt.Errorf("git merge-base --is-ancestor [%s]: %v - %s", tmpBasePath, err, ancestorErrbuf.String())
+			}
+			return "", models.ErrMergeNotFastForward{ID: pr.ID}
+		}
+		if err := git.NewCommand("merge", "--ff-only", "-q", trackingBranch).RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
+			return "", fmt.Errorf("git merge --ff-only [%s -> %s]: %s", headRepoPath, tmpBasePath, errbuf.String())
 		}
 	default:
-		return models.ErrInvalidMergeStyle{ID: pr.BaseRepo.ID, Style: mergeStyle}
+		return "", models.ErrInvalidMergeStyle{ID: pr.BaseRepo.ID, Style: mergeStyle}
 	}
 
 	// OK we should cache our current head and origin/headbranch
 	mergeHeadSHA, err := git.GetFullCommitID(tmpBasePath, "HEAD")
 	if err != nil {
-		return fmt.Errorf("Failed to get full commit id for HEAD: %v", err)
+		return "", fmt.Errorf("Failed to get full commit id for HEAD: %v", err)
 	}
-	mergeBaseSHA, err := git.GetFullCommitID(tmpBasePath, "origin/"+pr.BaseBranch)
-	if err != nil {
-		return fmt.Errorf("Failed to get full commit id for origin/%s: %v", pr.BaseBranch, err)
+	// The merge was computed on top of startCommitID, which is not necessarily
+	// the current head of the base branch.
+	mergeBaseSHA := startCommitID
+	if len(mergeBaseSHA) == 0 {
+		mergeBaseSHA, err = git.GetFullCommitID(tmpBasePath, "origin/"+pr.BaseBranch)
+		if err != nil {
+			return "", fmt.Errorf("Failed to get full commit id for origin/%s: %v", pr.BaseBranch, err)
+		}
 	}
 
 	// Now it's questionable about where this should go - either after or before the push
@@ -226,47 +219,60 @@ func Merge(pr *models.PullRequest, doer *models.User, baseGitRepo *git.Repositor
 	// the merge as you can always remerge.
 	if setting.LFS.StartServer {
 		if err := LFSPush(tmpBasePath, mergeHeadSHA, mergeBaseSHA, pr); err != nil {
-			return err
+			return "", err
 		}
 	}
 
+	env, err := pushingEnvironment(pr, doer)
+	if err != nil {
+		return "", err
+	}
+
+	// Push back to upstream. Refs outside of refs/heads/ are scratch refs
+	// which may be rewritten at will.
+	refspec := pr.BaseBranch + ":" + targetRef
+	if !strings.HasPrefix(targetRef, git.BranchPrefix) {
+		refspec = "+" + refspec
+	}
+	if err := git.NewCommand("push", "origin", refspec).RunInDirTimeoutEnvPipeline(env, -1, tmpBasePath, nil, &errbuf); err != nil {
+		return "", fmt.Errorf("git push: %s", errbuf.String())
+	}
+
+	return mergeHeadSHA, nil
+}
+
+// pushingEnvironment returns the environment used to push the merge of pr on behalf of doer
+func pushingEnvironment(pr *models.PullRequest, doer *models.User) ([]string, error) {
 	headUser, err := models.GetUserByName(pr.HeadUserName)
 	if err != nil {
 		if !models.IsErrUserNotExist(err) {
 			log.Error("Can't find user: %s for head repository - %v", pr.HeadUserName, err)
-			return err
+			return nil, err
 		}
 		log.Error("Can't find user: %s for head repository - defaulting to doer: %s - %v", pr.HeadUserName, doer.Name, err)
 		headUser = doer
 	}
 
-	env := models.FullPushingEnvironment(
+	return models.FullPushingEnvironment(
 		headUser,
 		doer,
 		pr.BaseRepo,
 		pr.BaseRepo.Name,
 		pr.ID,
-	)
-
-	// Push back to upstream.
-	if err := git.NewCommand("push", "origin", pr.BaseBranch).RunInDirTimeoutEnvPipeline(env, -1, tmpBasePath, nil, &errbuf); err != nil {
-		return fmt.Errorf("git push: %s", errbuf.String())
-	}
-
-	pr.MergedCommitID, err = baseGitRepo.GetBranchCommitID(pr.BaseBranch)
-	if err != nil {
-		return fmt.Errorf("GetBranchCommit: %v", err)
-	}
+	), nil
+}
 
+// finishMerge marks pr as merged into pr.MergedCommitID and fires the corresponding actions and webhooks
+func finishMerge(pr *models.PullRequest, doer *models.User, baseGitRepo *git.Repository, mergeStyle models.MergeStyle) {
 	pr.MergedUnix = util.TimeStampNow()
 	pr.Merger = doer
 	pr.MergerID = doer.ID
 
-	if err = pr.SetMerged(); err != nil {
+	if err := pr.SetMerged(); err != nil {
 		log.Error("setMerged [%d]: %v", pr.ID, err)
 	}
 
-	if err = models.MergePullRequestAction(doer, pr.Issue.Repo, pr.Issue); err != nil {
+	if err := models.MergePullRequestAction(doer, pr.Issue.Repo, pr.Issue); err != nil {
 		log.Error("MergePullRequestAction [%d]: %v", pr.ID, err)
 	}
 
@@ -274,13 +280,13 @@ func Merge(pr *models.PullRequest, doer *models.User, baseGitRepo *git.Repositor
 	cache.Remove(pr.Issue.Repo.GetCommitsCountCacheKey(pr.BaseBranch, true))
 
 	// Reload pull request information.
-	if err = pr.LoadAttributes(); err != nil {
+	if err := pr.LoadAttributes(); err != nil {
 		log.Error("LoadAttributes: %v", err)
-		return nil
+		return
 	}
 
 	mode, _ := models.AccessLevel(doer, pr.Issue.Repo)
-	if err = models.PrepareWebhooks(pr.Issue.Repo, models.HookEventPullRequest, &api.PullRequestPayload{
+	if err := models.PrepareWebhooks(pr.Issue.Repo, models.HookEventPullRequest, &api.PullRequestPayload{
 		Action:      api.HookIssueClosed,
 		Index:       pr.Index,
 		PullRequest: pr.APIFormat(),
@@ -295,7 +301,7 @@ func Merge(pr *models.PullRequest, doer *models.User, baseGitRepo *git.Repositor
 	l, err := baseGitRepo.CommitsBetweenIDs(pr.MergedCommitID, pr.MergeBase)
 	if err != nil {
 		log.Error("CommitsBetweenIDs: %v", err)
-		return nil
+		return
 	}
 
 	// It is possible that head branch is not fully sync with base branch for merge commits,
@@ -304,7 +310,7 @@ func Merge(pr *models.PullRequest, doer *models.User, baseGitRepo *git.Repositor
 	mergeCommit, err := baseGitRepo.GetBranchCommit(pr.BaseBranch)
 	if err != nil {
 		log.Error("GetBranchCommit: %v", err)
-		return nil
+		return
 	}
 	if mergeStyle == models.MergeStyleMerge {
 		l.PushFront(mergeCommit)
@@ -325,6 +331,65 @@ func Merge(pr *models.PullRequest, doer *models.User, baseGitRepo *git.Repositor
 	} else {
 		go models.HookQueue.Add(pr.BaseRepo.ID)
 	}
+}
+
+// addCacheRepo makes the objects of the repository at cache available to the repository at staging
+func addCacheRepo(staging, cache string) error {
+	p := filepath.Join(staging, ".git", "objects", "info", "alternates")
+	f, err := os.OpenFile(p, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	data := filepath.Join(cache, "objects")
+	if _, err := fmt.Fprintln(f, data); err != nil {
+		return err
+	}
+	return nil
+}
+
+// prepareSparseCheckout restricts the working tree of the temporary repository at tmpBasePath
+// to the files which differ between baseBranch and headBranch and reads the index of HEAD.
+func prepareSparseCheckout(tmpBasePath, baseBranch, headBranch string) error {
+	var errbuf strings.Builder
+
+	// Enable sparse-checkout
+	sparseCheckoutList, err := getDiffTree(tmpBasePath, baseBranch, headBranch)
+	if err != nil {
+		return fmt.Errorf("getDiffTree: %v", err)
+	}
+
+	infoPath := filepath.Join(tmpBasePath, ".git", "info")
+	if err := os.MkdirAll(infoPath, 0700); err != nil {
+		return fmt.Errorf("creating directory failed [%s]: %v", infoPath, err)
+	}
+	sparseCheckoutListPath := filepath.Join(infoPath, "sparse-checkout")
+	if err := ioutil.WriteFile(sparseCheckoutListPath, []byte(sparseCheckoutList), 0600); err != nil {
+		return fmt.Errorf("Writing sparse-checkout file to %s: %v", sparseCheckoutListPath, err)
+	}
+
+	// Switch off LFS process (set required, clean and smudge here also)
+	if err := git.NewCommand("config", "--local", "filter.lfs.process", "").RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
+		return fmt.Errorf("git config [filter.lfs.process -> <> ]: %v", errbuf.String())
+	}
+	if err := git.NewCommand("config", "--local", "filter.lfs.required", "false").RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
+		return fmt.Errorf("git config [filter.lfs.required -> <false> ]: %v", errbuf.String())
+	}
+	if err := git.NewCommand("config", "--local", "filter.lfs.clean", "").RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
+		return fmt.Errorf("git config [filter.lfs.clean -> <> ]: %v", errbuf.String())
+	}
+	if err := git.NewCommand("config", "--local", "filter.lfs.smudge", "").RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
+		return fmt.Errorf("git config [filter.lfs.smudge -> <> ]: %v", errbuf.String())
+	}
+
+	if err := git.NewCommand("config", "--local", "core.sparseCheckout", "true").RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
+		return fmt.Errorf("git config [core.sparsecheckout -> true]: %v", errbuf.String())
+	}
+
+	// Read base branch index
+	if err := git.NewCommand("read-tree", "HEAD").RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
+		return fmt.Errorf("git read-tree HEAD: %s", errbuf.String())
+	}
 	return nil
 }
 
@@ -0,0 +1,277 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/masoodkamyab/gitea/models"
+	"github.com/masoodkamyab/gitea/modules/git"
+	"github.com/masoodkamyab/gitea/modules/log"
+	"github.com/masoodkamyab/gitea/modules/notification"
+	"github.com/masoodkamyab/gitea/modules/sync"
+)
+
+// mergeQueueWorkingPool makes sure a merge queue is only processed by one goroutine at a time.
+var mergeQueueWorkingPool = sync.NewExclusivePool()
+
+// AddToMergeQueue checks that doer is allowed to merge pr with the given style and
+// appends it to the merge queue of its base branch.
+func AddToMergeQueue(pr *models.PullRequest, doer *models.User, mergeStyle models.MergeStyle, message string) (*models.MergeQueueEntry, error) {
+	if err := pr.GetHeadRepo(); err != nil {
+		return nil, fmt.Errorf("GetHeadRepo: %v", err)
+	} else if err = pr.GetBaseRepo(); err != nil {
+		return nil, fmt.Errorf("GetBaseRepo: %v", err)
+	}
+
+	prUnit, err := pr.BaseRepo.GetUnit(models.UnitTypePullRequests)
+	if err != nil {
+		return nil, err
+	}
+	prConfig := prUnit.PullRequestsConfig()
+
+	if err := pr.CheckUserAllowedToMerge(doer); err != nil {
+		return nil, fmt.Errorf("CheckUserAllowedToMerge: %v", err)
+	}
+
+	// Check if merge style is correct and allowed
+	if !prConfig.IsMergeStyleAllowed(mergeStyle) {
+		return nil, models.ErrInvalidMergeStyle{ID: pr.BaseRepo.ID, Style: mergeStyle}
+	}
+
+	entry, err := models.AddToMergeQueue(pr, doer, mergeStyle, message)
+	if err != nil {
+		return nil, err
+	}
+
+	go ProcessMergeQueue(pr.BaseRepo, pr.BaseBranch)
+	return entry, nil
+}
+
+// RemoveFromMergeQueue removes pr from the merge queue of its base branch.
+// The pull requests queued behind it are merged speculatively again.
+func RemoveFromMergeQueue(pr *models.PullRequest) error {
+	if err := pr.GetBaseRepo(); err != nil {
+		return fmt.Errorf("GetBaseRepo: %v", err)
+	}
+
+	mergeQueueWorkingPool.CheckIn(mergeQueueIdentity(pr.BaseRepo, pr.BaseBranch))
+	err := removeMergeQueueEntry(pr.BaseRepo, pr)
+	mergeQueueWorkingPool.CheckOut(mergeQueueIdentity(pr.BaseRepo, pr.BaseBranch))
+	if err != nil {
+		return err
+	}
+
+	go ProcessMergeQueue(pr.BaseRepo, pr.BaseBranch)
+	return nil
+}
+
+// CheckMergeQueuesForCommit processes the merge queues with a speculative merge commit
+// commitID, it is called when a new commit status has been reported.
+func CheckMergeQueuesForCommit(repo *models.Repository, commitID string) {
+	entries, err := models.GetMergeQueueEntriesByCommitID(repo.ID, commitID)
+	if err != nil {
+		log.Error("GetMergeQueueEntriesByCommitID [%d, %s]: %v", repo.ID, commitID, err)
+		return
+	}
+	for _, entry := range entries {
+		go ProcessMergeQueue(repo, entry.BaseBranch)
+	}
+}
+
+// ProcessMergeQueues processes the merge queues of all branches
+func ProcessMergeQueues() {
+	entries, err := models.GetAllMergeQueueEntries()
+	if err != nil {
+		log.Error("GetAllMergeQueueEntries: %v", err)
+		return
+	}
+	for _, entry := range entries {
+		repo, err := models.GetRepositoryByID(entry.RepoID)
+		if err != nil {
+			log.Error("GetRepositoryByID [%d]: %v", entry.RepoID, err)
+			continue
+		}
+		ProcessMergeQueue(repo, entry.BaseBranch)
+	}
+}
+
+// ProcessMergeQueue merges the queued pull requests of a branch speculatively onto the
+// merge queue refs, and fast-forwards the branch to the entries whose checks passed.
+func ProcessMergeQueue(repo *models.Repository, branch string) {
+	mergeQueueWorkingPool.CheckIn(mergeQueueIdentity(repo, branch))
+	defer mergeQueueWorkingPool.CheckOut(mergeQueueIdentity(repo, branch))
+
+	if err := processMergeQueue(repo, branch); err != nil {
+		log.Error("processMergeQueue [%-v, %s]: %v", repo, branch, err)
+	}
+}
+
+func mergeQueueIdentity(repo *models.Repository, branch string) string {
+	return fmt.Sprintf("%d:%s", repo.ID, branch)
+}
+
+func processMergeQueue(repo *models.Repository, branch string) error {
+	gitRepo, err := git.OpenRepository(repo.RepoPath())
+	if err != nil {
+		return fmt.Errorf("OpenRepository: %v", err)
+	}
+
+	for {
+		entries, err := models.GetMergeQueue(repo.ID, branch)
+		if err != nil {
+			return fmt.Errorf("GetMergeQueue: %v", err)
+		} else if len(entries) == 0 {
+			return nil
+		}
+
+		baseCommitID, err := gitRepo.GetBranchCommitID(branch)
+		if err != nil {
+			return fmt.Errorf("GetBranchCommitID [%s]: %v", branch, err)
+		}
+
+		// Make sure every entry is merged on top of the one queued before it.
+		queue := make([]*models.MergeQueueEntry, 0, len(entries))
+		startCommitID := baseCommitID
+		for _, entry := range entries {
+			pr := entry.Pull
+			pr.BaseRepo = repo
+			if pr.HasMerged || pr.Issue.IsClosed || pr.IsDraft {
+				if err := removeMergeQueueEntry(repo, pr); err != nil {
+					return err
+				}
+				continue
+			}
+
+			headCommitID, err := getHeadCommitID(pr)
+			if err != nil {
+				log.Trace("Unable to merge pull request %d speculatively: %v", pr.ID, err)
+				if err := ejectMergeQueueEntry(repo, entry, models.MergeQueueEjectReasonConflict); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if entry.CommitID == "" || entry.BaseCommitID != startCommitID || entry.HeadCommitID != headCommitID {
+				commitID, err := rawMerge(pr, entry.Doer, gitRepo, entry.MergeStyle, entry.Message, startCommitID, pr.GetMergeQueueRefName())
+				if err != nil {
+					log.Trace("Unable to merge pull request %d speculatively: %v", pr.ID, err)
+					if err := ejectMergeQueueEntry(repo, entry, models.MergeQueueEjectReasonConflict); err != nil {
+						return err
+					}
+					continue
+				}
+				if err := entry.UpdateSpeculativeCommit(startCommitID, headCommitID, commitID); err != nil {
+					return fmt.Errorf("UpdateSpeculativeCommit: %v", err)
+				}
+			}
+			startCommitID = entry.CommitID
+			queue = append(queue, entry)
+		}
+
+		// Land the entries at the front of the queue whose checks have passed.
+		rebuild := false
+		for _, entry := range queue {
+			statuses, err := models.GetLatestCommitStatus(repo, entry.CommitID, 0)
+			if err != nil {
+				return fmt.Errorf("GetLatestCommitStatus: %v", err)
+			}
+			if len(statuses) == 0 {
+				return nil
+			}
+
+			state := models.CalcCommitStatus(statuses).State
+			if state == models.CommitStatusPending {
+				return nil
+			} else if state != models.CommitStatusSuccess {
+				if err := ejectMergeQueueEntry(repo, entry, models.MergeQueueEjectReasonChecksFailed); err != nil {
+					return err
+				}
+				rebuild = true
+				break
+			}
+
+			if err := landMergeQueueEntry(repo, gitRepo, entry); err != nil {
+				return err
+			}
+		}
+		if !rebuild {
+			return nil
+		}
+	}
+}
+
+func getHeadCommitID(pr *models.PullRequest) (string, error) {
+	if err := pr.GetHeadRepo(); err != nil {
+		return "", err
+	} else if pr.HeadRepo == nil {
+		return "", models.ErrPullRequestHeadRepoMissing{ID: pr.ID, HeadRepoID: pr.HeadRepoID}
+	}
+
+	headGitRepo, err := git.OpenRepository(pr.HeadRepo.RepoPath())
+	if err != nil {
+		return "", err
+	}
+	return headGitRepo.GetBranchCommitID(pr.HeadBranch)
+}
+
+// landMergeQueueEntry fast-forwards the base branch to the speculative merge commit of entry
+func landMergeQueueEntry(repo *models.Repository, gitRepo *git.Repository, entry *models.MergeQueueEntry) error {
+	pr := entry.Pull
+	pr.Issue.Repo = repo
+
+	env, err := pushingEnvironment(pr, entry.Doer)
+	if err != nil {
+		return err
+	}
+
+	var errbuf strings.Builder
+	if err := git.NewCommand("push", ".", entry.CommitID+":"+git.BranchPrefix+pr.BaseBranch).RunInDirTimeoutEnvPipeline(env, -1, repo.RepoPath(), nil, &errbuf); err != nil {
+		return fmt.Errorf("git push [%s -> %s]: %s", entry.CommitID, pr.BaseBranch, errbuf.String())
+	}
+
+	if err := removeMergeQueueEntry(repo, pr); err != nil {
+		return err
+	}
+
+	pr.MergedCommitID = entry.CommitID
+	finishMerge(pr, entry.Doer, gitRepo, entry.MergeStyle)
+	notification.NotifyMergePullRequest(pr, entry.Doer, gitRepo)
+
+	go models.AddTestPullRequestTask(entry.Doer, repo.ID, pr.BaseBranch, false)
+	log.Trace("Pull request merged from merge queue: %d", pr.ID)
+	return nil
+}
+
+// ejectMergeQueueEntry removes entry from the merge queue and explains why on the pull request
+func ejectMergeQueueEntry(repo *models.Repository, entry *models.MergeQueueEntry, reason string) error {
+	if err := removeMergeQueueEntry(repo, entry.Pull); err != nil {
+		return err
+	}
+
+	if _, err := models.CreateComment(&models.CreateCommentOptions{
+		Type:    models.CommentTypeMergeQueueEject,
+		Doer:    entry.Doer,
+		Repo:    repo,
+		Issue:   entry.Pull.Issue,
+		Content: reason,
+	}); err != nil {
+		return fmt.Errorf("CreateComment: %v", err)
+	}
+	return nil
+}
+
+func removeMergeQueueEntry(repo *models.Repository, pr *models.PullRequest) error {
+	if err := models.RemoveFromMergeQueue(pr.ID); err != nil {
+		return fmt.Errorf("RemoveFromMergeQueue: %v", err)
+	}
+
+	refName := pr.GetMergeQueueRefName()
+	if _, err := git.NewCommand("update-ref", "-d", refName).RunInDir(repo.RepoPath()); err != nil {
+		log.Error("Unable to delete merge queue ref %s of %-v: %v", refName, repo, err)
+	}
+	return nil
+}
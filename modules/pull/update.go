@@ -0,0 +1,159 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/masoodkamyab/gitea/models"
+	"github.com/masoodkamyab/gitea/modules/git"
+	"github.com/masoodkamyab/gitea/modules/log"
+)
+
+// CommitsBehind returns the number of commits of the base branch which are missing from the head branch of pr
+func CommitsBehind(pr *models.PullRequest) (int64, error) {
+	if err := pr.GetBaseRepo(); err != nil {
+		return 0, fmt.Errorf("GetBaseRepo: %v", err)
+	}
+	return git.CommitsCount(pr.BaseRepo.RepoPath(), pr.GetGitRefName()+".."+git.BranchPrefix+pr.BaseBranch)
+}
+
+// updateAttempts is the number of times the head branch is updated before giving up on
+// commits being pushed to it meanwhile
+const updateAttempts = 3
+
+// errHeadBranchChanged is returned when commits were pushed to the head branch while it was updated
+var errHeadBranchChanged = errors.New("head branch changed while updating")
+
+// Update brings the head branch of pr up to date with its base branch, either by merging the
+// base branch into it or by rebasing it onto the base branch, and pushes the result as doer.
+func Update(pr *models.PullRequest, doer *models.User, style models.MergeStyle) error {
+	if err := pr.GetHeadRepo(); err != nil {
+		return fmt.Errorf("GetHeadRepo: %v", err)
+	} else if err = pr.GetBaseRepo(); err != nil {
+		return fmt.Errorf("GetBaseRepo: %v", err)
+	}
+
+	if style != models.MergeStyleMerge && style != models.MergeStyleRebase {
+		return models.ErrInvalidMergeStyle{ID: pr.BaseRepo.ID, Style: style}
+	}
+
+	if err := pr.CheckUserAllowedToUpdate(doer); err != nil {
+		return err
+	}
+
+	for attempt := 1; ; attempt++ {
+		behind, err := CommitsBehind(pr)
+		if err != nil {
+			return fmt.Errorf("CommitsBehind: %v", err)
+		} else if behind == 0 {
+			return nil
+		}
+
+		if err = updateHeadBranch(pr, doer, style); err != errHeadBranchChanged {
+			return err
+		} else if attempt == updateAttempts {
+			return models.ErrPullRequestUpdateConflict{ID: pr.ID, Style: style, StdErr: err.Error()}
+		}
+		log.Trace("Head branch of pull request %d changed while updating, retrying", pr.ID)
+	}
+}
+
+// updateHeadBranch updates the head branch of pr once. The branch is only pushed if it still
+// points to the commit it was updated from, errHeadBranchChanged is returned otherwise.
+func updateHeadBranch(pr *models.PullRequest, doer *models.User, style models.MergeStyle) error {
+	// Clone head repo.
+	tmpBasePath, err := models.CreateTemporaryPath("update")
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err := models.RemoveTemporaryPath(tmpBasePath); err != nil {
+			log.Error("Update: RemoveTemporaryPath: %s", err)
+		}
+	}()
+
+	baseRepoPath := pr.BaseRepo.RepoPath()
+
+	if err := git.Clone(pr.HeadRepo.RepoPath(), tmpBasePath, git.CloneRepoOptions{
+		Shared:     true,
+		NoCheckout: true,
+		Branch:     pr.HeadBranch,
+	}); err != nil {
+		return fmt.Errorf("git clone: %v", err)
+	}
+
+	remoteRepoName := "base_repo"
+
+	// Add base repo remote.
+	if err := addCacheRepo(tmpBasePath, baseRepoPath); err != nil {
+		return fmt.Errorf("addCacheRepo [%s -> %s]: %v", baseRepoPath, tmpBasePath, err)
+	}
+
+	var errbuf strings.Builder
+	if err := git.NewCommand("remote", "add", remoteRepoName, baseRepoPath).RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
+		return fmt.Errorf("git remote add [%s -> %s]: %s", baseRepoPath, tmpBasePath, errbuf.String())
+	}
+
+	// Fetch base branch
+	if err := git.NewCommand("fetch", remoteRepoName, pr.BaseBranch).RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
+		return fmt.Errorf("git fetch [%s -> %s]: %s", baseRepoPath, tmpBasePath, errbuf.String())
+	}
+
+	trackingBranch := path.Join(remoteRepoName, pr.BaseBranch)
+
+	if err := prepareSparseCheckout(tmpBasePath, pr.HeadBranch, trackingBranch); err != nil {
+		return err
+	}
+
+	headCommitID, err := git.NewCommand("rev-parse", "HEAD").RunInDir(tmpBasePath)
+	if err != nil {
+		return fmt.Errorf("git rev-parse HEAD: %v", err)
+	}
+	headCommitID = strings.TrimSpace(headCommitID)
+
+	switch style {
+	case models.MergeStyleMerge:
+		if err := git.NewCommand("merge", "--no-ff", "--no-commit", trackingBranch).RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
+			return models.ErrPullRequestUpdateConflict{ID: pr.ID, Style: style, StdErr: errbuf.String()}
+		}
+
+		sig := doer.NewGitSig()
+		message := fmt.Sprintf("Merge branch '%s' into %s", pr.BaseBranch, pr.HeadBranch)
+		if err := git.NewCommand("commit", fmt.Sprintf("--author='%s <%s>'", sig.Name, sig.Email), "-m", message).RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
+			return fmt.Errorf("git commit [%s]: %v - %s", tmpBasePath, err, errbuf.String())
+		}
+	case models.MergeStyleRebase:
+		// Populate the sparse working tree, rebase refuses to run on missing files
+		if err := git.NewCommand("checkout", "-f", pr.HeadBranch).RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
+			return fmt.Errorf("git checkout: %s", errbuf.String())
+		}
+		if err := git.NewCommand("rebase", "-q", trackingBranch).RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
+			return models.ErrPullRequestUpdateConflict{ID: pr.ID, Style: style, StdErr: errbuf.String()}
+		}
+	}
+
+	env := models.FullPushingEnvironment(doer, doer, pr.HeadRepo, pr.HeadRepo.Name, 0)
+
+	// Push back to the head repository, its hooks enforce the branch protection
+	// and retest the pull requests of the head branch. The rebased commits replace
+	// the old ones, but not commits pushed to the head branch in the meantime.
+	headRef := git.BranchPrefix + pr.HeadBranch
+	errbuf.Reset()
+	if err := git.NewCommand("push", "--force-with-lease="+headRef+":"+headCommitID, "origin", "HEAD:"+headRef).
+		RunInDirTimeoutEnvPipeline(env, -1, tmpBasePath, nil, &errbuf); err != nil {
+		if strings.Contains(errbuf.String(), "stale info") {
+			return errHeadBranchChanged
+		}
+		return fmt.Errorf("git push: %s", errbuf.String())
+	}
+
+	log.Trace("Pull request head branch updated: %d", pr.ID)
+	return nil
+}
@@ -0,0 +1,270 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repofiles
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/masoodkamyab/gitea/models"
+	"github.com/masoodkamyab/gitea/modules/base"
+)
+
+const (
+	conflictMarkerOurs   = "<<<<<<< "
+	conflictMarkerSep    = "======="
+	conflictMarkerTheirs = ">>>>>>> "
+)
+
+// MergeOptions holds the options to merge a commit into a branch
+type MergeOptions struct {
+	Branch        string // the branch receiving the merge
+	LastCommitID  string // the expected head of Branch, empty for its current head
+	MergeRepo     *models.Repository
+	MergeBranch   string // used for the conflict labels and the default message
+	MergeCommitID string
+	Message       string
+	Resolutions   map[string]string // resolved content by tree path
+}
+
+// ConflictSection is a part of a conflicted file, either common to both sides or a conflict
+type ConflictSection struct {
+	IsConflict bool
+	Lines      string
+	Ours       string
+	Theirs     string
+}
+
+// ConflictedFile represents a file which could not be merged automatically
+type ConflictedFile struct {
+	TreePath   string
+	CanResolve bool // false for binary files and files deleted on one side
+	Content    string
+	Sections   []*ConflictSection
+}
+
+// MergeConflicts represents the conflicts of merging a commit into a branch
+type MergeConflicts struct {
+	CommitID      string
+	MergeCommitID string
+	Files         []*ConflictedFile
+}
+
+// ParseConflictSections splits content with conflict markers into its sections
+func ParseConflictSections(content string) []*ConflictSection {
+	sections := make([]*ConflictSection, 0, 5)
+	common := &ConflictSection{}
+	var conflict *ConflictSection
+	inTheirs := false
+	for _, line := range strings.SplitAfter(content, "\n") {
+		switch {
+		case conflict == nil && strings.HasPrefix(line, conflictMarkerOurs):
+			if len(common.Lines) > 0 {
+				sections = append(sections, common)
+			}
+			conflict = &ConflictSection{IsConflict: true}
+			inTheirs = false
+		case conflict != nil && !inTheirs && strings.TrimRight(line, "\r\n") == conflictMarkerSep:
+			inTheirs = true
+		case conflict != nil && inTheirs && strings.HasPrefix(line, conflictMarkerTheirs):
+			sections = append(sections, conflict)
+			conflict = nil
+			common = &ConflictSection{}
+		case conflict != nil && inTheirs:
+			conflict.Theirs += line
+		case conflict != nil:
+			conflict.Ours += line
+		default:
+			common.Lines += line
+		}
+	}
+	if conflict != nil {
+		// Unterminated conflict, keep the text as it is
+		common.Lines += conflictMarkerOurs + conflict.Ours + conflictMarkerSep + conflict.Theirs
+	}
+	if len(common.Lines) > 0 {
+		sections = append(sections, common)
+	}
+	return sections
+}
+
+// HasConflictMarkers returns whether content still contains conflict markers
+func HasConflictMarkers(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(line, conflictMarkerOurs) || strings.HasPrefix(line, conflictMarkerTheirs) {
+			return true
+		}
+	}
+	return false
+}
+
+// prepareMerge clones repo and merges opts.MergeCommitID into opts.Branch as far as possible in the index
+func prepareMerge(repo *models.Repository, opts *MergeOptions) (*TemporaryUploadRepository, []*UnmergedFile, error) {
+	t, err := NewTemporaryUploadRepository(repo)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := t.Clone(opts.Branch); err != nil {
+		t.Close()
+		return nil, nil, err
+	}
+
+	commitID, err := t.GetLastCommit()
+	if err != nil {
+		t.Close()
+		return nil, nil, err
+	}
+	if opts.LastCommitID == "" {
+		opts.LastCommitID = commitID
+	} else if opts.LastCommitID != commitID {
+		t.Close()
+		return nil, nil, models.ErrCommitIDDoesNotMatch{
+			GivenCommitID:   opts.LastCommitID,
+			CurrentCommitID: commitID,
+		}
+	}
+
+	if opts.MergeRepo.ID != repo.ID {
+		if err := t.AddAlternate(opts.MergeRepo.RepoPath()); err != nil {
+			t.Close()
+			return nil, nil, fmt.Errorf("AddAlternate: %v", err)
+		}
+	}
+
+	mergeBase, err := t.MergeBase(commitID, opts.MergeCommitID)
+	if err != nil {
+		t.Close()
+		return nil, nil, err
+	}
+	if err := t.SetDefaultIndex(); err != nil {
+		t.Close()
+		return nil, nil, err
+	}
+	if err := t.ReadTreeMerge(mergeBase, commitID, opts.MergeCommitID); err != nil {
+		t.Close()
+		return nil, nil, err
+	}
+
+	files, err := t.UnmergedFiles()
+	if err != nil {
+		t.Close()
+		return nil, nil, err
+	}
+	return t, files, nil
+}
+
+// mergeUnmergedFile merges the content of file, it returns a nil ConflictedFile if the merge is clean
+func mergeUnmergedFile(t *TemporaryUploadRepository, file *UnmergedFile, opts *MergeOptions) ([]byte, *ConflictedFile, error) {
+	conflicted := &ConflictedFile{TreePath: file.TreePath}
+	if file.Ours == nil || file.Theirs == nil {
+		return nil, conflicted, nil
+	}
+
+	ours, err := t.CatFileBlob(file.Ours.Hash)
+	if err != nil {
+		return nil, nil, err
+	}
+	theirs, err := t.CatFileBlob(file.Theirs.Hash)
+	if err != nil {
+		return nil, nil, err
+	}
+	var common []byte
+	if file.Base != nil {
+		if common, err = t.CatFileBlob(file.Base.Hash); err != nil {
+			return nil, nil, err
+		}
+	}
+	if !base.IsTextFile(ours) || !base.IsTextFile(theirs) {
+		return nil, conflicted, nil
+	}
+
+	merged, conflicts, err := t.MergeFile(ours, common, theirs, opts.Branch, "base", opts.MergeBranch)
+	if err != nil {
+		return nil, nil, err
+	} else if conflicts == 0 {
+		return merged, nil, nil
+	}
+
+	conflicted.CanResolve = true
+	conflicted.Content = string(merged)
+	conflicted.Sections = ParseConflictSections(conflicted.Content)
+	return merged, conflicted, nil
+}
+
+// GetMergeConflicts returns the files which conflict when merging opts.MergeCommitID into opts.Branch of repo
+func GetMergeConflicts(repo *models.Repository, opts *MergeOptions) (*MergeConflicts, error) {
+	t, files, err := prepareMerge(repo, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer t.Close()
+
+	conflicts := &MergeConflicts{
+		CommitID:      opts.LastCommitID,
+		MergeCommitID: opts.MergeCommitID,
+		Files:         make([]*ConflictedFile, 0, len(files)),
+	}
+	for _, file := range files {
+		_, conflicted, err := mergeUnmergedFile(t, file, opts)
+		if err != nil {
+			return nil, err
+		} else if conflicted != nil {
+			conflicts.Files = append(conflicts.Files, conflicted)
+		}
+	}
+	return conflicts, nil
+}
+
+// ResolveMergeConflicts merges opts.MergeCommitID into opts.Branch of repo using
+// opts.Resolutions for the conflicted files and pushes the merge commit as doer
+func ResolveMergeConflicts(repo *models.Repository, doer *models.User, opts *MergeOptions) (string, error) {
+	t, files, err := prepareMerge(repo, opts)
+	if err != nil {
+		return "", err
+	}
+	defer t.Close()
+
+	for _, file := range files {
+		content, conflicted, err := mergeUnmergedFile(t, file, opts)
+		if err != nil {
+			return "", err
+		}
+		if conflicted != nil {
+			resolution, ok := opts.Resolutions[file.TreePath]
+			if !ok || !conflicted.CanResolve || HasConflictMarkers(resolution) {
+				return "", models.ErrMergeConflictUnresolved{TreePath: file.TreePath}
+			}
+			content = []byte(resolution)
+		}
+
+		objectHash, err := t.HashObject(bytes.NewReader(content))
+		if err != nil {
+			return "", err
+		}
+		if err := t.AddObjectToIndex(file.Ours.Mode, objectHash, file.TreePath); err != nil {
+			return "", err
+		}
+	}
+
+	treeHash, err := t.WriteTree()
+	if err != nil {
+		return "", err
+	}
+
+	message := strings.TrimSpace(opts.Message)
+	if message == "" {
+		message = fmt.Sprintf("Merge branch '%s' into %s", opts.MergeBranch, opts.Branch)
+	}
+	commitHash, err := t.CommitTreeWithParents(doer, doer, treeHash, message, opts.LastCommitID, opts.MergeCommitID)
+	if err != nil {
+		return "", err
+	}
+
+	if err := t.Push(doer, commitHash, opts.Branch); err != nil {
+		return "", err
+	}
+	return commitHash, nil
+}
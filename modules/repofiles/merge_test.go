@@ -0,0 +1,29 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repofiles
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseConflictSections(t *testing.T) {
+	content := "a\n<<<<<<< feature\nb\n=======\nc\nd\n>>>>>>> master\ne\n"
+	sections := ParseConflictSections(content)
+	if assert.Len(t, sections, 3) {
+		assert.Equal(t, &ConflictSection{Lines: "a\n"}, sections[0])
+		assert.Equal(t, &ConflictSection{IsConflict: true, Ours: "b\n", Theirs: "c\nd\n"}, sections[1])
+		assert.Equal(t, &ConflictSection{Lines: "e\n"}, sections[2])
+	}
+
+	sections = ParseConflictSections("<<<<<<< feature\n=======\nc\n>>>>>>> master\n")
+	if assert.Len(t, sections, 1) {
+		assert.Equal(t, &ConflictSection{IsConflict: true, Theirs: "c\n"}, sections[0])
+	}
+
+	assert.True(t, HasConflictMarkers(content))
+	assert.False(t, HasConflictMarkers("a\n=======\nb\n"))
+}
@@ -9,8 +9,10 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"time"
@@ -249,6 +251,11 @@ func (t *TemporaryUploadRepository) GetLastCommitByRef(ref string) (string, erro
 
 // CommitTree creates a commit from a given tree for the user with provided message
 func (t *TemporaryUploadRepository) CommitTree(author, committer *models.User, treeHash string, message string) (string, error) {
+	return t.CommitTreeWithParents(author, committer, treeHash, message, "HEAD")
+}
+
+// CommitTreeWithParents creates a commit from a given tree with the given parents for the user with provided message
+func (t *TemporaryUploadRepository) CommitTreeWithParents(author, committer *models.User, treeHash string, message string, parents ...string) (string, error) {
 	commitTimeStr := time.Now().Format(time.UnixDate)
 	authorSig := author.NewGitSig()
 	committerSig := committer.NewGitSig()
@@ -263,11 +270,17 @@ func (t *TemporaryUploadRepository) CommitTree(author, committer *models.User, t
 		"GIT_COMMITTER_EMAIL="+committerSig.Email,
 		"GIT_COMMITTER_DATE="+commitTimeStr,
 	)
+	args := []string{"commit-tree", treeHash}
+	for _, parent := range parents {
+		args = append(args, "-p", parent)
+	}
+	args = append(args, "-m", message)
+
 	commitHash, stderr, err := process.GetManager().ExecDirEnv(5*time.Minute,
 		t.basePath,
 		fmt.Sprintf("commitTree (git commit-tree): %s", t.basePath),
 		env,
-		git.GitExecutable, "commit-tree", treeHash, "-p", "HEAD", "-m", message)
+		git.GitExecutable, args...)
 	if err != nil {
 		return "", fmt.Errorf("git commit-tree: %s", stderr)
 	}
@@ -398,3 +411,154 @@ func (t *TemporaryUploadRepository) GetCommit(commitID string) (*git.Commit, err
 	}
 	return t.gitRepo.GetCommit(commitID)
 }
+
+// AddAlternate makes the objects of the repository at repoPath available to the temporary repository
+func (t *TemporaryUploadRepository) AddAlternate(repoPath string) error {
+	p := filepath.Join(t.basePath, "objects", "info", "alternates")
+	f, err := os.OpenFile(p, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = fmt.Fprintln(f, filepath.Join(repoPath, "objects"))
+	return err
+}
+
+// MergeBase returns the best common ancestor of the given commits
+func (t *TemporaryUploadRepository) MergeBase(commitA, commitB string) (string, error) {
+	mergeBase, stderr, err := process.GetManager().ExecDir(5*time.Minute,
+		t.basePath,
+		fmt.Sprintf("MergeBase (git merge-base %s %s): %s", commitA, commitB, t.basePath),
+		git.GitExecutable, "merge-base", commitA, commitB)
+	if err != nil {
+		return "", fmt.Errorf("git merge-base: %v %s", err, stderr)
+	}
+	return strings.TrimSpace(mergeBase), nil
+}
+
+// ReadTreeMerge performs a three-way merge of the given commits into the index,
+// paths which cannot be merged trivially are left unmerged. Paths removed on one
+// side and unchanged on the other are removed, like git merge does.
+func (t *TemporaryUploadRepository) ReadTreeMerge(base, ours, theirs string) error {
+	if _, stderr, err := process.GetManager().ExecDir(5*time.Minute,
+		t.basePath,
+		fmt.Sprintf("ReadTreeMerge (git read-tree -m): %s", t.basePath),
+		git.GitExecutable, "read-tree", "-m", "-i", "--aggressive", base, ours, theirs); err != nil {
+		return fmt.Errorf("git read-tree -m: %v %s", err, stderr)
+	}
+	return nil
+}
+
+// IndexStage represents one stage of an unmerged index entry
+type IndexStage struct {
+	Mode string
+	Hash string
+}
+
+// UnmergedFile represents a path of the index which has not been merged,
+// a stage is nil if the path does not exist on that side
+type UnmergedFile struct {
+	TreePath string
+	Base     *IndexStage
+	Ours     *IndexStage
+	Theirs   *IndexStage
+}
+
+// UnmergedFiles returns the unmerged paths of the index
+func (t *TemporaryUploadRepository) UnmergedFiles() ([]*UnmergedFile, error) {
+	stdout, stderr, err := process.GetManager().ExecDir(5*time.Minute,
+		t.basePath,
+		fmt.Sprintf("UnmergedFiles (git ls-files -u): %s", t.basePath),
+		git.GitExecutable, "ls-files", "-u", "-z")
+	if err != nil {
+		return nil, fmt.Errorf("git ls-files -u: %v %s", err, stderr)
+	}
+
+	files := make([]*UnmergedFile, 0, 5)
+	for _, line := range strings.Split(stdout, "\000") {
+		// <mode> SP <hash> SP <stage> TAB <path>
+		tab := strings.IndexByte(line, '\t')
+		if tab < 0 {
+			continue
+		}
+		fields := strings.Fields(line[:tab])
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("unexpected line from git ls-files -u: %q", line)
+		}
+		treePath := line[tab+1:]
+		if len(files) == 0 || files[len(files)-1].TreePath != treePath {
+			files = append(files, &UnmergedFile{TreePath: treePath})
+		}
+		file := files[len(files)-1]
+		stage := &IndexStage{Mode: fields[0], Hash: fields[1]}
+		switch fields[2] {
+		case "1":
+			file.Base = stage
+		case "2":
+			file.Ours = stage
+		case "3":
+			file.Theirs = stage
+		}
+	}
+	return files, nil
+}
+
+// CatFileBlob returns the content of the blob with the given hash
+func (t *TemporaryUploadRepository) CatFileBlob(hash string) ([]byte, error) {
+	stdout, stderr, err := process.GetManager().ExecDir(5*time.Minute,
+		t.basePath,
+		fmt.Sprintf("CatFileBlob (git cat-file blob %s): %s", hash, t.basePath),
+		git.GitExecutable, "cat-file", "blob", hash)
+	if err != nil {
+		return nil, fmt.Errorf("git cat-file: %v %s", err, stderr)
+	}
+	return []byte(stdout), nil
+}
+
+// MergeFile merges the changes from base to theirs into ours, the conflicts
+// are marked using the given labels. It returns the merged content and the
+// number of conflicts.
+func (t *TemporaryUploadRepository) MergeFile(ours, base, theirs []byte, oursLabel, baseLabel, theirsLabel string) ([]byte, int, error) {
+	dir, err := ioutil.TempDir(t.basePath, "merge-file")
+	if err != nil {
+		return nil, 0, err
+	}
+	defer os.RemoveAll(dir)
+
+	names := []string{"ours", "base", "theirs"}
+	for i, content := range [][]byte{ours, base, theirs} {
+		if err := ioutil.WriteFile(filepath.Join(dir, names[i]), content, 0600); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	timeout := 5 * time.Minute
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	stdOut := new(bytes.Buffer)
+	stdErr := new(bytes.Buffer)
+	cmd := exec.CommandContext(ctx, git.GitExecutable, "merge-file", "-p",
+		"-L", oursLabel, "-L", baseLabel, "-L", theirsLabel,
+		names[0], names[1], names[2])
+	desc := fmt.Sprintf("mergeFile: (git merge-file) %s", dir)
+	cmd.Dir = dir
+	cmd.Stdout = stdOut
+	cmd.Stderr = stdErr
+
+	if err := cmd.Start(); err != nil {
+		return nil, 0, fmt.Errorf("exec(%s) failed: %v(%v)", desc, err, ctx.Err())
+	}
+
+	pid := process.GetManager().Add(desc, cmd)
+	err = cmd.Wait()
+	process.GetManager().Remove(pid)
+
+	// A positive exit status is the number of conflicts
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() > 0 && exitErr.ExitCode() < 128 {
+		return stdOut.Bytes(), exitErr.ExitCode(), nil
+	} else if err != nil {
+		return nil, 0, fmt.Errorf("exec(%d:%s) failed: %v(%v) stderr: %v", pid, desc, err, ctx.Err(), stdErr)
+	}
+	return stdOut.Bytes(), 0, nil
+}
@@ -49,6 +49,27 @@ var (
 			Schedule   string
 			OlderThan  time.Duration
 		} `ini:"cron.deleted_branches_cleanup"`
+		MergeQueue struct {
+			Enabled    bool
+			RunAtStart bool
+			Schedule   string
+		} `ini:"cron.merge_queue"`
+		MilestoneSnapshots struct {
+			Enabled    bool
+			RunAtStart bool
+			Schedule   string
+		} `ini:"cron.milestone_snapshots"`
+		UserSessionsCleanup struct {
+			Enabled    bool
+			RunAtStart bool
+			Schedule   string
+		} `ini:"cron.user_sessions_cleanup"`
+		AuditLogCleanup struct {
+			Enabled    bool
+			RunAtStart bool
+			Schedule   string
+			OlderThan  time.Duration
+		} `ini:"cron.audit_log_cleanup"`
 	}{
 		UpdateMirror: struct {
 			Enabled    bool
@@ -114,6 +135,44 @@ var (
 			Schedule:   "@every 24h",
 			OlderThan:  24 * time.Hour,
 		},
+		MergeQueue: struct {
+			Enabled    bool
+			RunAtStart bool
+			Schedule   string
+		}{
+			Enabled:    true,
+			RunAtStart: true,
+			Schedule:   "@every 5m",
+		},
+		MilestoneSnapshots: struct {
+			Enabled    bool
+			RunAtStart bool
+			Schedule   string
+		}{
+			Enabled:    true,
+			RunAtStart: true,
+			Schedule:   "@midnight",
+		},
+		UserSessionsCleanup: struct {
+			Enabled    bool
+			RunAtStart bool
+			Schedule   string
+		}{
+			Enabled:    true,
+			RunAtStart: true,
+			Schedule:   "@every 24h",
+		},
+		AuditLogCleanup: struct {
+			Enabled    bool
+			RunAtStart bool
+			Schedule   string
+			OlderThan  time.Duration
+		}{
+			Enabled:    true,
+			RunAtStart: true,
+			Schedule:   "@every 24h",
+			OlderThan:  365 * 24 * time.Hour,
+		},
 	}
 )
 
@@ -37,7 +37,6 @@ import (
 	_ "github.com/go-macaron/session/nodb"      // nodb plugin for session store
 	_ "github.com/go-macaron/session/postgres"  // postgres plugin for session store
 	_ "github.com/go-macaron/session/redis"     // redis plugin for store session
-	shellquote "github.com/kballard/go-shellquote"
 	version "github.com/mcuadros/go-version"
 	ini "gopkg.in/ini.v1"
 	"strk.kbt.io/projects/go/libravatar"
@@ -70,6 +69,12 @@ const (
 	ReCaptcha    = "recaptcha"
 )
 
+// enumerates all the kinds of SSH certificate principals mapped to users
+const (
+	SSHPrincipalUsername = "username"
+	SSHPrincipalEmail    = "email"
+)
+
 // settings
 var (
 	// AppVer settings
@@ -106,24 +111,27 @@ var (
 	LetsEncryptEmail     string
 
 	SSH = struct {
-		Disabled                 bool           `ini:"DISABLE_SSH"`
-		StartBuiltinServer       bool           `ini:"START_SSH_SERVER"`
-		BuiltinServerUser        string         `ini:"BUILTIN_SSH_SERVER_USER"`
-		Domain                   string         `ini:"SSH_DOMAIN"`
-		Port                     int            `ini:"SSH_PORT"`
-		ListenHost               string         `ini:"SSH_LISTEN_HOST"`
-		ListenPort               int            `ini:"SSH_LISTEN_PORT"`
-		RootPath                 string         `ini:"SSH_ROOT_PATH"`
-		ServerCiphers            []string       `ini:"SSH_SERVER_CIPHERS"`
-		ServerKeyExchanges       []string       `ini:"SSH_SERVER_KEY_EXCHANGES"`
-		ServerMACs               []string       `ini:"SSH_SERVER_MACS"`
-		KeyTestPath              string         `ini:"SSH_KEY_TEST_PATH"`
-		KeygenPath               string         `ini:"SSH_KEYGEN_PATH"`
-		AuthorizedKeysBackup     bool           `ini:"SSH_AUTHORIZED_KEYS_BACKUP"`
-		MinimumKeySizeCheck      bool           `ini:"-"`
-		MinimumKeySizes          map[string]int `ini:"-"`
-		CreateAuthorizedKeysFile bool           `ini:"SSH_CREATE_AUTHORIZED_KEYS_FILE"`
-		ExposeAnonymous          bool           `ini:"SSH_EXPOSE_ANONYMOUS"`
+		Disabled                  bool           `ini:"DISABLE_SSH"`
+		StartBuiltinServer        bool           `ini:"START_SSH_SERVER"`
+		BuiltinServerUser         string         `ini:"BUILTIN_SSH_SERVER_USER"`
+		Domain                    string         `ini:"SSH_DOMAIN"`
+		Port                      int            `ini:"SSH_PORT"`
+		ListenHost                string         `ini:"SSH_LISTEN_HOST"`
+		ListenPort                int            `ini:"SSH_LISTEN_PORT"`
+		RootPath                  string         `ini:"SSH_ROOT_PATH"`
+		ServerCiphers             []string       `ini:"SSH_SERVER_CIPHERS"`
+		ServerKeyExchanges        []string       `ini:"SSH_SERVER_KEY_EXCHANGES"`
+		ServerMACs                []string       `ini:"SSH_SERVER_MACS"`
+		KeyTestPath               string         `ini:"SSH_KEY_TEST_PATH"`
+		KeygenPath                string         `ini:"SSH_KEYGEN_PATH"`
+		AuthorizedKeysBackup      bool           `ini:"SSH_AUTHORIZED_KEYS_BACKUP"`
+		MinimumKeySizeCheck       bool           `ini:"-"`
+		MinimumKeySizes           map[string]int `ini:"-"`
+		CreateAuthorizedKeysFile  bool           `ini:"SSH_CREATE_AUTHORIZED_KEYS_FILE"`
+		ExposeAnonymous           bool           `ini:"SSH_EXPOSE_ANONYMOUS"`
+		TrustedUserCAKeys         []string       `ini:"SSH_TRUSTED_USER_CA_KEYS"`
+		TrustedUserCAKeysFile     string         `ini:"SSH_TRUSTED_USER_CA_KEYS_FILENAME"`
+		AuthorizedPrincipalsAllow []string       `ini:"SSH_AUTHORIZED_PRINCIPALS_ALLOW"`
 	}{
 		Disabled:           false,
 		StartBuiltinServer: false,
@@ -154,6 +162,8 @@ var (
 	MinPasswordLength     int
 	ImportLocalPaths      bool
 	DisableGitHooks       bool
+	TwoFactorRequired     bool
+	TwoFactorGracePeriod  time.Duration
 
 	// Database settings
 	UseSQLite3       bool
@@ -318,16 +328,21 @@ var (
 		InvalidateRefreshTokens    bool
 		JWTSecretBytes             []byte `ini:"-"`
 		JWTSecretBase64            string `ini:"JWT_SECRET"`
+		JWTSigningPrivateKeyFile   string `ini:"JWT_SIGNING_PRIVATE_KEY_FILE"`
 	}{
 		Enable:                     true,
 		AccessTokenExpirationTime:  3600,
 		RefreshTokenExpirationTime: 730,
 		InvalidateRefreshTokens:    false,
+		JWTSigningPrivateKeyFile:   "jwt/private.pem",
 	}
 
-	U2F = struct {
+	WebAuthn = struct {
+		RPID          string
+		RPDisplayName string
+		RPOrigin      string
 		AppID         string
-		TrustedFacets []string
+		Timeout       time.Duration
 	}{}
 
 	// Metrics settings
@@ -685,6 +700,17 @@ func NewContext() {
 	SSH.AuthorizedKeysBackup = sec.Key("SSH_AUTHORIZED_KEYS_BACKUP").MustBool(true)
 	SSH.CreateAuthorizedKeysFile = sec.Key("SSH_CREATE_AUTHORIZED_KEYS_FILE").MustBool(true)
 	SSH.ExposeAnonymous = sec.Key("SSH_EXPOSE_ANONYMOUS").MustBool(false)
+	SSH.TrustedUserCAKeys = sec.Key("SSH_TRUSTED_USER_CA_KEYS").Strings(",")
+	SSH.TrustedUserCAKeysFile = sec.Key("SSH_TRUSTED_USER_CA_KEYS_FILENAME").MustString(filepath.Join(SSH.RootPath, "gitea-trusted-user-ca-keys.pem"))
+	SSH.AuthorizedPrincipalsAllow = sec.Key("SSH_AUTHORIZED_PRINCIPALS_ALLOW").Strings(",")
+	if len(SSH.AuthorizedPrincipalsAllow) == 0 {
+		SSH.AuthorizedPrincipalsAllow = []string{SSHPrincipalUsername, SSHPrincipalEmail}
+	}
+	for _, allow := range SSH.AuthorizedPrincipalsAllow {
+		if allow != SSHPrincipalUsername && allow != SSHPrincipalEmail {
+			log.Fatal("Invalid SSH_AUTHORIZED_PRINCIPALS_ALLOW value '%s', it has to be %s or %s", allow, SSHPrincipalUsername, SSHPrincipalEmail)
+		}
+	}
 
 	sec = Cfg.Section("server")
 	if err = sec.MapTo(&LFS); err != nil {
@@ -738,6 +764,10 @@ func NewContext() {
 		return
 	}
 
+	if !filepath.IsAbs(OAuth2.JWTSigningPrivateKeyFile) {
+		OAuth2.JWTSigningPrivateKeyFile = filepath.Join(AppDataPath, OAuth2.JWTSigningPrivateKeyFile)
+	}
+
 	if OAuth2.Enable {
 		OAuth2.JWTSecretBytes = make([]byte, 32)
 		n, err := base64.RawURLEncoding.Decode(OAuth2.JWTSecretBytes, []byte(OAuth2.JWTSecretBase64))
@@ -779,6 +809,8 @@ func NewContext() {
 	MinPasswordLength = sec.Key("MIN_PASSWORD_LENGTH").MustInt(6)
 	ImportLocalPaths = sec.Key("IMPORT_LOCAL_PATHS").MustBool(false)
 	DisableGitHooks = sec.Key("DISABLE_GIT_HOOKS").MustBool(false)
+	TwoFactorRequired = sec.Key("TWO_FACTOR_REQUIRED").MustBool(false)
+	TwoFactorGracePeriod = sec.Key("TWO_FACTOR_GRACE_PERIOD").MustDuration(7 * 24 * time.Hour)
 	InternalToken = loadInternalToken(sec)
 	IterateBufferSize = Cfg.Section("database").Key("ITERATE_BUFFER_SIZE").MustInt(50)
 	LogSQL = Cfg.Section("database").Key("LOG_SQL").MustBool(true)
@@ -948,9 +980,13 @@ func NewContext() {
 
 	newMarkup()
 
-	sec = Cfg.Section("U2F")
-	U2F.TrustedFacets, _ = shellquote.Split(sec.Key("TRUSTED_FACETS").MustString(strings.TrimRight(AppURL, "/")))
-	U2F.AppID = sec.Key("APP_ID").MustString(strings.TrimRight(AppURL, "/"))
+	sec = Cfg.Section("webauthn")
+	WebAuthn.RPID = sec.Key("RP_ID").MustString(urlHostname)
+	WebAuthn.RPDisplayName = sec.Key("RP_DISPLAY_NAME").MustString(AppName)
+	WebAuthn.RPOrigin = sec.Key("RP_ORIGIN").MustString(appURL.Scheme + "://" + appURL.Host)
+	WebAuthn.Timeout = sec.Key("TIMEOUT").MustDuration(time.Minute)
+	// Security keys registered through the former U2F support are scoped to its AppID
+	WebAuthn.AppID = Cfg.Section("U2F").Key("APP_ID").MustString(strings.TrimRight(AppURL, "/"))
 
 	zip.Verbose = false
 }
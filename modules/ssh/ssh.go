@@ -34,7 +34,7 @@ func cleanCommand(cmd string) string {
 	return cmd[i:]
 }
 
-func handleServerConn(keyID string, chans <-chan ssh.NewChannel) {
+func handleServerConn(keyArg string, chans <-chan ssh.NewChannel) {
 	for newChan := range chans {
 		if newChan.ChannelType() != "session" {
 			err := newChan.Reject(ssh.UnknownChannelType, "unknown channel type")
@@ -63,7 +63,7 @@ func handleServerConn(keyID string, chans <-chan ssh.NewChannel) {
 					cmdName := strings.TrimLeft(payload, "'()")
 					log.Trace("SSH: Payload: %v", cmdName)
 
-					args := []string{"serv", "key-" + keyID, "--config=" + setting.CustomConf}
+					args := []string{"serv", keyArg, "--config=" + setting.CustomConf}
 					log.Trace("SSH: Arguments: %v", args)
 					cmd := exec.Command(setting.AppPath, args...)
 					cmd.Env = append(
@@ -162,7 +162,11 @@ func listen(config *ssh.ServerConfig, host string, port int) {
 			log.Trace("SSH: Connection from %s (%s)", sConn.RemoteAddr(), sConn.ClientVersion())
 			// The incoming Request channel must be serviced.
 			go ssh.DiscardRequests(reqs)
-			go handleServerConn(sConn.Permissions.Extensions["key-id"], chans)
+			keyArg := "key-" + sConn.Permissions.Extensions["key-id"]
+			if userID, has := sConn.Permissions.Extensions["cert-user-id"]; has {
+				keyArg = "cert-" + userID
+			}
+			go handleServerConn(keyArg, chans)
 		}()
 	}
 }
@@ -176,6 +180,16 @@ func Listen(host string, port int, ciphers []string, keyExchanges []string, macs
 			MACs:         macs,
 		},
 		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if cert, ok := key.(*ssh.Certificate); ok && models.IsSSHCertificateAuthEnabled() {
+				user, principal, err := models.CheckSSHCertificate(cert, conn.RemoteAddr())
+				if err != nil {
+					log.Warn("SSH: Certificate %q (serial %d) from %s rejected: %v", cert.KeyId, cert.Serial, conn.RemoteAddr(), err)
+					return nil, err
+				}
+				log.Info("SSH: Certificate %q (serial %d) from %s accepted for principal %s as user %s", cert.KeyId, cert.Serial, conn.RemoteAddr(), principal, user.Name)
+				return &ssh.Permissions{Extensions: map[string]string{"cert-user-id": com.ToStr(user.ID)}}, nil
+			}
+
 			pkey, err := models.SearchPublicKeyByContent(strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key))))
 			if err != nil {
 				log.Error("SearchPublicKeyByContent: %v", err)
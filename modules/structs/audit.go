@@ -0,0 +1,35 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// AuditEvent a recorded administrative or permission change
+type AuditEvent struct {
+	ID int64 `json:"id"`
+	// name of the change, e.g. `repo_collaborator_mode`
+	Action    string `json:"action"`
+	ActorID   int64  `json:"actor_id"`
+	ActorName string `json:"actor_name"`
+	IP        string `json:"ip"`
+	// organization or user the target belongs to, zero for site wide changes
+	OwnerID  int64  `json:"owner_id"`
+	RepoID   int64  `json:"repo_id"`
+	RepoName string `json:"repo_name"`
+	// name of the changed collaborator, key, branch, team or user
+	Target string `json:"target"`
+	// changed fields with their old and new values
+	Diff map[string]*AuditChange `json:"diff"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+}
+
+// AuditChange the value of a field before and after a change
+type AuditChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
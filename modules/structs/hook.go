@@ -366,6 +366,12 @@ const (
 	HookIssueMilestoned HookIssueAction = "milestoned"
 	// HookIssueDemilestoned is an issue action for when a milestone is cleared on an issue.
 	HookIssueDemilestoned HookIssueAction = "demilestoned"
+	// HookIssueReadyForReview is a pull request action for when a draft is marked as ready for review.
+	HookIssueReadyForReview HookIssueAction = "ready_for_review"
+	// HookIssueConvertedToDraft is a pull request action for when a pull request is converted to a draft.
+	HookIssueConvertedToDraft HookIssueAction = "converted_to_draft"
+	// HookIssueProjectBoardChanged is an issue action for when an issue is moved to another board of a project.
+	HookIssueProjectBoardChanged HookIssueAction = "project_board_changed"
 )
 
 // IssuePayload represents the payload information that is sent along with an issue event.
@@ -377,6 +383,10 @@ type IssuePayload struct {
 	Issue      *Issue          `json:"issue"`
 	Repository *Repository     `json:"repository"`
 	Sender     *User           `json:"sender"`
+	// Project and ProjectBoard are only set for project_board_changed,
+	// ProjectBoard is nil when the issue was moved to uncategorized
+	Project      *Project      `json:"project,omitempty"`
+	ProjectBoard *ProjectBoard `json:"project_board,omitempty"`
 }
 
 // SetSecret modifies the secret of the IssuePayload.
@@ -398,6 +408,8 @@ type ChangesFromPayload struct {
 type ChangesPayload struct {
 	Title *ChangesFromPayload `json:"title,omitempty"`
 	Body  *ChangesFromPayload `json:"body,omitempty"`
+	// ProjectBoard holds the title of the board the issue was moved from
+	ProjectBoard *ChangesFromPayload `json:"project_board,omitempty"`
 }
 
 // __________      .__  .__    __________                                     __
@@ -416,6 +428,10 @@ type PullRequestPayload struct {
 	PullRequest *PullRequest    `json:"pull_request"`
 	Repository  *Repository     `json:"repository"`
 	Sender      *User           `json:"sender"`
+	// Project and ProjectBoard are only set for project_board_changed,
+	// ProjectBoard is nil when the pull request was moved to uncategorized
+	Project      *Project      `json:"project,omitempty"`
+	ProjectBoard *ProjectBoard `json:"project_board,omitempty"`
 }
 
 // SetSecret modifies the secret of the PullRequestPayload.
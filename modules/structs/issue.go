@@ -53,8 +53,12 @@ type Issue struct {
 	Closed *time.Time `json:"closed_at"`
 	// swagger:strfmt date-time
 	Deadline *time.Time `json:"due_date"`
+	// planned effort in seconds
+	EstimatedTime int64 `json:"estimated_time"`
 
 	PullRequest *PullRequestMeta `json:"pull_request"`
+	// values of the custom issue fields
+	Fields []*IssueFieldValue `json:"fields"`
 }
 
 // ListIssueOption list issue options
@@ -90,6 +94,36 @@ type EditIssueOption struct {
 	State     *string  `json:"state"`
 	// swagger:strfmt date-time
 	Deadline *time.Time `json:"due_date"`
+	// planned effort in seconds, 0 removes the estimate
+	EstimatedTime *int64 `json:"estimated_time"`
+}
+
+// TransferIssueOption options for transferring an issue to another repository
+type TransferIssueOption struct {
+	// required: true
+	NewOwner string `json:"new_owner" binding:"Required"`
+	// required: true
+	NewRepo string `json:"new_repo" binding:"Required"`
+}
+
+// EditIssuesOption options for editing many issues at once, omitted fields are left unchanged
+type EditIssuesOption struct {
+	// indices of the issues to edit
+	// required: true
+	Indices      []int64 `json:"indices" binding:"Required"`
+	AddLabels    []int64 `json:"add_labels"`
+	RemoveLabels []int64 `json:"remove_labels"`
+	// zero removes the milestone
+	Milestone       *int64   `json:"milestone"`
+	AddAssignees    []string `json:"add_assignees"`
+	RemoveAssignees []string `json:"remove_assignees"`
+	AddProject      int64    `json:"add_project"`
+	RemoveProject   int64    `json:"remove_project"`
+	// enum: open,closed
+	State      *string              `json:"state"`
+	Lock       *bool                `json:"lock"`
+	LockReason string               `json:"lock_reason"`
+	TransferTo *TransferIssueOption `json:"transfer_to"`
 }
 
 // EditDeadlineOption options for creating a deadline
@@ -0,0 +1,62 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// IssueField issue field is a custom field of the issues of a repository or an organization
+type IssueField struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// enum: text,number,date,single_select,multi_select,user
+	Type string `json:"type"`
+	// choices of select fields
+	Options []string `json:"options"`
+	// zero for organization fields
+	RepoID int64 `json:"repo_id"`
+	// zero for repository fields
+	OwnerID int64 `json:"owner_id"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+}
+
+// CreateIssueFieldOption options for creating a custom issue field
+type CreateIssueFieldOption struct {
+	// required:true
+	Name        string `json:"name" binding:"Required;MaxSize(50)"`
+	Description string `json:"description" binding:"MaxSize(255)"`
+	// required:true
+	// enum: text,number,date,single_select,multi_select,user
+	Type    string   `json:"type" binding:"Required;In(text,number,date,single_select,multi_select,user)"`
+	Options []string `json:"options"`
+}
+
+// EditIssueFieldOption options for editing a custom issue field, its type cannot be changed
+type EditIssueFieldOption struct {
+	Name        string  `json:"name" binding:"MaxSize(50)"`
+	Description *string `json:"description"`
+	// values of removed options are removed from the issues
+	Options []string `json:"options"`
+}
+
+// IssueFieldValue issue field value is the value of a custom field set on an issue
+type IssueFieldValue struct {
+	FieldID int64  `json:"field_id"`
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	// dates are formatted as YYYY-MM-DD and users by their login name
+	Values []string `json:"values"`
+}
+
+// SetIssueFieldValueOption options for setting the value of a custom field on an issue
+type SetIssueFieldValueOption struct {
+	// an empty list removes the value, only multi select fields take more than one value
+	Values []string `json:"values"`
+}
@@ -37,3 +37,26 @@ type EditMilestoneOption struct {
 	State       *string    `json:"state"`
 	Deadline    *time.Time `json:"due_on"`
 }
+
+// MilestoneSnapshot the state of a milestone on a day
+type MilestoneSnapshot struct {
+	// swagger:strfmt date-time
+	Date         time.Time `json:"date"`
+	OpenIssues   int       `json:"open_issues"`
+	ClosedIssues int       `json:"closed_issues"`
+	// tracked time in seconds
+	TrackedTime int64 `json:"tracked_time"`
+	// estimated time of the issues in seconds
+	EstimatedTime int64 `json:"estimated_time"`
+}
+
+// MilestoneBurndown the history of a milestone
+type MilestoneBurndown struct {
+	// snapshots ordered by date, the last one of an open milestone is its current state
+	Snapshots []*MilestoneSnapshot `json:"snapshots"`
+	// swagger:strfmt date-time
+	Deadline *time.Time `json:"due_on"`
+	// the date all issues are expected to be closed at the closing rate so far
+	// swagger:strfmt date-time
+	Forecast *time.Time `json:"forecast"`
+}
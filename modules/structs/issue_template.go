@@ -0,0 +1,26 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+// IssueTemplate represents an issue or pull request template of a repository,
+// the fields but Content and FileName are read from the YAML front-matter of the file
+// swagger:model
+type IssueTemplate struct {
+	Name  string `json:"name" yaml:"name"`
+	About string `json:"about" yaml:"about"`
+	// prefix of the title of new issues
+	Title string `json:"title" yaml:"title"`
+	// names of the labels applied to new issues
+	Labels []string `json:"labels" yaml:"labels"`
+	// user names of the default assignees of new issues
+	Assignees []string `json:"assignees" yaml:"assignees"`
+	Content   string   `json:"content" yaml:"-"`
+	FileName  string   `json:"file_name" yaml:"-"`
+}
+
+// Valid returns whether the template has the front-matter required to list it
+func (t *IssueTemplate) Valid() bool {
+	return len(t.Name) > 0 && len(t.About) > 0
+}
@@ -28,3 +28,29 @@ type AddTimeOption struct {
 	// required: true
 	Time int64 `json:"time" binding:"Required"`
 }
+
+// EditTimeOption options for changing a tracked time
+type EditTimeOption struct {
+	// time in seconds
+	// required: true
+	Time int64 `json:"time" binding:"Required"`
+}
+
+// TrackedTimeGroup total time tracked for a user, an issue, a milestone or a label
+type TrackedTimeGroup struct {
+	// empty for the times of issues without a milestone or a label
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	// Time in seconds
+	Time int64 `json:"time"`
+}
+
+// TrackedTimeReport tracked times with their totals grouped by user, issue, milestone or label
+type TrackedTimeReport struct {
+	// enum: user,issue,milestone,label
+	GroupBy string `json:"group_by"`
+	// Total time in seconds
+	Total  int64               `json:"total"`
+	Groups []*TrackedTimeGroup `json:"groups"`
+	Times  []*TrackedTime      `json:"times"`
+}
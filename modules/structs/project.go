@@ -0,0 +1,91 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// Project project is a kanban board of a repository or an organization
+type Project struct {
+	ID          int64     `json:"id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	State       StateType `json:"state"`
+	// zero for organization projects
+	RepoID int64 `json:"repo_id"`
+	// zero for repository projects
+	OwnerID int64 `json:"owner_id"`
+	Creator *User `json:"creator"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+	// swagger:strfmt date-time
+	Closed *time.Time `json:"closed_at"`
+}
+
+// CreateProjectOption options for creating a project
+type CreateProjectOption struct {
+	// required:true
+	Title       string `json:"title" binding:"Required"`
+	Description string `json:"description"`
+}
+
+// EditProjectOption options for editing a project
+type EditProjectOption struct {
+	Title       string  `json:"title"`
+	Description *string `json:"description"`
+	State       *string `json:"state"`
+}
+
+// ProjectBoard project board is a column of a project
+type ProjectBoard struct {
+	ID        int64  `json:"id"`
+	ProjectID int64  `json:"project_id"`
+	Title     string `json:"title"`
+	Sorting   int    `json:"sorting"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+}
+
+// CreateProjectBoardOption options for creating a project board
+type CreateProjectBoardOption struct {
+	// required:true
+	Title   string `json:"title" binding:"Required"`
+	Sorting int    `json:"sorting"`
+}
+
+// EditProjectBoardOption options for editing a project board
+type EditProjectBoardOption struct {
+	Title   string `json:"title"`
+	Sorting *int   `json:"sorting"`
+}
+
+// ProjectIssue project issue is the card of an issue or a pull request on a project
+type ProjectIssue struct {
+	ProjectID int64 `json:"project_id"`
+	// zero if the issue is on no board
+	BoardID int64  `json:"board_id"`
+	Sorting int    `json:"sorting"`
+	Issue   *Issue `json:"issue"`
+}
+
+// AddProjectIssueOption options for putting an issue on a project
+type AddProjectIssueOption struct {
+	// required:true
+	IssueID int64 `json:"issue_id" binding:"Required"`
+	// zero to leave the issue uncategorized
+	BoardID int64 `json:"board_id"`
+}
+
+// MoveProjectIssueOption options for moving an issue between the boards of a project
+type MoveProjectIssueOption struct {
+	// zero to leave the issue uncategorized
+	BoardID int64 `json:"board_id"`
+	Sorting int   `json:"sorting"`
+}
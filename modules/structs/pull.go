@@ -29,6 +29,7 @@ type PullRequest struct {
 
 	Mergeable bool `json:"mergeable"`
 	HasMerged bool `json:"merged"`
+	IsDraft   bool `json:"draft"`
 	// swagger:strfmt date-time
 	Merged         *time.Time `json:"merged_at"`
 	MergedCommitID *string    `json:"merge_commit_sha"`
@@ -74,6 +75,7 @@ type CreatePullRequestOption struct {
 	Assignees []string `json:"assignees"`
 	Milestone int64    `json:"milestone"`
 	Labels    []int64  `json:"labels"`
+	Draft     bool     `json:"draft"`
 	// swagger:strfmt date-time
 	Deadline *time.Time `json:"due_date"`
 }
@@ -87,6 +89,8 @@ type EditPullRequestOption struct {
 	Milestone int64    `json:"milestone"`
 	Labels    []int64  `json:"labels"`
 	State     *string  `json:"state"`
+	// set to `false` to mark a draft as ready for review, or `true` to convert the pull request to a draft
+	Draft *bool `json:"draft"`
 	// swagger:strfmt date-time
 	Deadline *time.Time `json:"due_date"`
 }
@@ -46,11 +46,15 @@ type Repository struct {
 	HasIssues                 bool        `json:"has_issues"`
 	HasWiki                   bool        `json:"has_wiki"`
 	HasPullRequests           bool        `json:"has_pull_requests"`
+	HasProjects               bool        `json:"has_projects"`
 	IgnoreWhitespaceConflicts bool        `json:"ignore_whitespace_conflicts"`
 	AllowMerge                bool        `json:"allow_merge_commits"`
 	AllowRebase               bool        `json:"allow_rebase"`
 	AllowRebaseMerge          bool        `json:"allow_rebase_explicit"`
 	AllowSquash               bool        `json:"allow_squash_merge"`
+	AllowFastForwardOnly      bool        `json:"allow_fast_forward_only_merge"`
+	MergeMessageTemplate      string      `json:"merge_message_template"`
+	SquashMessageTemplate     string      `json:"squash_message_template"`
 	AvatarURL                 string      `json:"avatar_url"`
 }
 
@@ -94,6 +98,8 @@ type EditRepoOption struct {
 	HasIssues *bool `json:"has_issues,omitempty"`
 	// either `true` to enable the wiki for this repository or `false` to disable it.
 	HasWiki *bool `json:"has_wiki,omitempty"`
+	// either `true` to enable projects for this repository or `false` to disable them.
+	HasProjects *bool `json:"has_projects,omitempty"`
 	// sets the default branch for this repository.
 	DefaultBranch *string `json:"default_branch,omitempty"`
 	// either `true` to allow pull requests, or `false` to prevent pull request.
@@ -108,6 +114,12 @@ type EditRepoOption struct {
 	AllowRebaseMerge *bool `json:"allow_rebase_explicit,omitempty"`
 	// either `true` to allow squash-merging pull requests, or `false` to prevent squash-merging. `has_pull_requests` must be `true`.
 	AllowSquash *bool `json:"allow_squash_merge,omitempty"`
+	// either `true` to allow fast-forward-only merging of pull requests, or `false` to prevent it. `has_pull_requests` must be `true`.
+	AllowFastForwardOnly *bool `json:"allow_fast_forward_only_merge,omitempty"`
+	// template of the default merge commit message, empty for the built-in message. `has_pull_requests` must be `true`.
+	MergeMessageTemplate *string `json:"merge_message_template,omitempty"`
+	// template of the default squash commit message, empty for the built-in message. `has_pull_requests` must be `true`.
+	SquashMessageTemplate *string `json:"squash_message_template,omitempty"`
 	// set to `true` to archive this repository.
 	Archived *bool `json:"archived,omitempty"`
 }
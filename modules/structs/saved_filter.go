@@ -0,0 +1,47 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// SavedFilter saved filter is a named issue search query of a user
+type SavedFilter struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	// issue search query, e.g. `is:open label:bug assignee:@me`
+	Query string `json:"query"`
+	// sort type of the issue list, e.g. `recentupdate`
+	Sort string `json:"sort"`
+	// zero for a filter across all repositories
+	RepoID int64 `json:"repo_id"`
+	// ID of the organization team the filter is shared with, zero if not shared
+	TeamID  int64 `json:"team_id"`
+	OwnerID int64 `json:"owner_id"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+}
+
+// CreateSavedFilterOption options for saving a filter
+type CreateSavedFilterOption struct {
+	// required:true
+	Name   string `json:"name" binding:"Required;MaxSize(50)"`
+	Query  string `json:"query"`
+	Sort   string `json:"sort"`
+	RepoID int64  `json:"repo_id"`
+	TeamID int64  `json:"team_id"`
+}
+
+// EditSavedFilterOption options for editing a saved filter
+type EditSavedFilterOption struct {
+	Name   *string `json:"name" binding:"MaxSize(50)"`
+	Query  *string `json:"query"`
+	Sort   *string `json:"sort"`
+	RepoID *int64  `json:"repo_id"`
+	TeamID *int64  `json:"team_id"`
+}
@@ -7,6 +7,7 @@ package structs
 
 import (
 	"encoding/base64"
+	"time"
 )
 
 // BasicAuthEncode generate base64 of basic auth head
@@ -17,10 +18,13 @@ func BasicAuthEncode(user, pass string) string {
 // AccessToken represents an API access token.
 // swagger:response AccessToken
 type AccessToken struct {
-	ID             int64  `json:"id"`
-	Name           string `json:"name"`
-	Token          string `json:"sha1"`
-	TokenLastEight string `json:"token_last_eight"`
+	ID             int64    `json:"id"`
+	Name           string   `json:"name"`
+	Token          string   `json:"sha1"`
+	TokenLastEight string   `json:"token_last_eight"`
+	Scopes         []string `json:"scopes"`
+	// swagger:strfmt date-time
+	ExpiresAt *time.Time `json:"expires_at"`
 }
 
 // AccessTokenList represents a list of API access token.
@@ -31,4 +35,9 @@ type AccessTokenList []*AccessToken
 // swagger:parameters userCreateToken
 type CreateAccessTokenOption struct {
 	Name string `json:"name" binding:"Required"`
+	// scopes of the token, one of `all`, `repo:read`, `issue`, `repo:write`, `package`, `admin:org`, `user` or `sudo`.
+	// Without scopes the token gets the scopes of the token authenticating the request or `all`.
+	Scopes []string `json:"scopes"`
+	// swagger:strfmt date-time
+	ExpiresAt *time.Time `json:"expires_at"`
 }
@@ -38,6 +38,7 @@ func MockContext(t *testing.T, path string) *context.Context {
 		Flash: &session.Flash{
 			Values: make(url.Values),
 		},
+		Session: &mockStore{sid: "mock-session", values: map[interface{}]interface{}{}},
 	}
 }
 
@@ -85,6 +86,57 @@ func (l mockLocale) Tr(s string, _ ...interface{}) string {
 	return s
 }
 
+type mockStore struct {
+	sid    string
+	values map[interface{}]interface{}
+}
+
+func (s *mockStore) Set(key, value interface{}) error {
+	s.values[key] = value
+	return nil
+}
+
+func (s *mockStore) Get(key interface{}) interface{} {
+	return s.values[key]
+}
+
+func (s *mockStore) Delete(key interface{}) error {
+	delete(s.values, key)
+	return nil
+}
+
+func (s *mockStore) ID() string {
+	return s.sid
+}
+
+func (s *mockStore) Release() error {
+	return nil
+}
+
+func (s *mockStore) Flush() error {
+	s.values = map[interface{}]interface{}{}
+	return nil
+}
+
+func (s *mockStore) Read(string) (session.RawStore, error) {
+	return s, nil
+}
+
+func (s *mockStore) Destory(*macaron.Context) error {
+	return s.Flush()
+}
+
+func (s *mockStore) RegenerateId(*macaron.Context) (session.RawStore, error) {
+	return s, nil
+}
+
+func (s *mockStore) Count() int {
+	return 1
+}
+
+func (s *mockStore) GC() {
+}
+
 type mockResponseWriter struct {
 	httptest.ResponseRecorder
 	size int
@@ -0,0 +1,219 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webauthn
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// The CBOR (RFC 7049) subset used by WebAuthn authenticators: definite length
+// integers, byte and text strings, arrays and maps, tags and simple values.
+// Integers are decoded as int64 so map keys can be looked up directly.
+
+const (
+	cborUnsigned = iota
+	cborNegative
+	cborBytes
+	cborText
+	cborArray
+	cborMap
+	cborTag
+	cborSimple
+)
+
+// maximum nesting of arrays and maps, authenticators never come close
+const cborMaxDepth = 16
+
+var errCBORTruncated = errors.New("cbor: unexpected end of data")
+
+// cborPair is a map entry, maps are encoded in the order of their entries
+type cborPair struct {
+	Key   interface{}
+	Value interface{}
+}
+
+// cborMapValue is a map to encode as CBOR with its entries in order
+type cborMapValue []cborPair
+
+// decodeCBOR decodes the first CBOR item of data and returns it with the remaining bytes
+func decodeCBOR(data []byte) (interface{}, []byte, error) {
+	return decodeCBORItem(data, 0)
+}
+
+func decodeCBORHead(data []byte) (major byte, arg uint64, rest []byte, err error) {
+	if len(data) == 0 {
+		return 0, 0, nil, errCBORTruncated
+	}
+	major, info := data[0]>>5, data[0]&0x1f
+	data = data[1:]
+	switch {
+	case info < 24:
+		return major, uint64(info), data, nil
+	case info == 24:
+		if len(data) < 1 {
+			return 0, 0, nil, errCBORTruncated
+		}
+		return major, uint64(data[0]), data[1:], nil
+	case info == 25:
+		if len(data) < 2 {
+			return 0, 0, nil, errCBORTruncated
+		}
+		return major, uint64(binary.BigEndian.Uint16(data)), data[2:], nil
+	case info == 26:
+		if len(data) < 4 {
+			return 0, 0, nil, errCBORTruncated
+		}
+		return major, uint64(binary.BigEndian.Uint32(data)), data[4:], nil
+	case info == 27:
+		if len(data) < 8 {
+			return 0, 0, nil, errCBORTruncated
+		}
+		return major, binary.BigEndian.Uint64(data), data[8:], nil
+	}
+	return 0, 0, nil, fmt.Errorf("cbor: unsupported additional information %d", info)
+}
+
+func decodeCBORItem(data []byte, depth int) (interface{}, []byte, error) {
+	if depth > cborMaxDepth {
+		return nil, nil, errors.New("cbor: nesting too deep")
+	}
+	major, arg, data, err := decodeCBORHead(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch major {
+	case cborUnsigned:
+		if arg > 1<<63-1 {
+			return nil, nil, errors.New("cbor: integer overflow")
+		}
+		return int64(arg), data, nil
+	case cborNegative:
+		if arg > 1<<63-1 {
+			return nil, nil, errors.New("cbor: integer overflow")
+		}
+		return -1 - int64(arg), data, nil
+	case cborBytes, cborText:
+		if uint64(len(data)) < arg {
+			return nil, nil, errCBORTruncated
+		}
+		if major == cborText {
+			return string(data[:arg]), data[arg:], nil
+		}
+		return append([]byte{}, data[:arg]...), data[arg:], nil
+	case cborArray:
+		if uint64(len(data)) < arg {
+			return nil, nil, errCBORTruncated
+		}
+		items := make([]interface{}, 0, arg)
+		for i := uint64(0); i < arg; i++ {
+			var item interface{}
+			if item, data, err = decodeCBORItem(data, depth+1); err != nil {
+				return nil, nil, err
+			}
+			items = append(items, item)
+		}
+		return items, data, nil
+	case cborMap:
+		if uint64(len(data)) < arg*2 {
+			return nil, nil, errCBORTruncated
+		}
+		m := make(map[interface{}]interface{}, arg)
+		for i := uint64(0); i < arg; i++ {
+			var key, value interface{}
+			if key, data, err = decodeCBORItem(data, depth+1); err != nil {
+				return nil, nil, err
+			}
+			switch key.(type) {
+			case int64, string:
+			default:
+				return nil, nil, fmt.Errorf("cbor: unsupported map key type %T", key)
+			}
+			if value, data, err = decodeCBORItem(data, depth+1); err != nil {
+				return nil, nil, err
+			}
+			m[key] = value
+		}
+		return m, data, nil
+	case cborTag:
+		// Tags carry no meaning for WebAuthn, return the tagged item
+		return decodeCBORItem(data, depth+1)
+	default:
+		switch arg {
+		case 20:
+			return false, data, nil
+		case 21:
+			return true, data, nil
+		case 22, 23:
+			return nil, data, nil
+		}
+		return nil, nil, fmt.Errorf("cbor: unsupported simple value %d", arg)
+	}
+}
+
+func encodeCBORHead(buf []byte, major byte, arg uint64) []byte {
+	switch {
+	case arg < 24:
+		return append(buf, major<<5|byte(arg))
+	case arg <= 0xff:
+		return append(buf, major<<5|24, byte(arg))
+	case arg <= 0xffff:
+		buf = append(buf, major<<5|25, 0, 0)
+		binary.BigEndian.PutUint16(buf[len(buf)-2:], uint16(arg))
+		return buf
+	case arg <= 0xffffffff:
+		buf = append(buf, major<<5|26, 0, 0, 0, 0)
+		binary.BigEndian.PutUint32(buf[len(buf)-4:], uint32(arg))
+		return buf
+	}
+	buf = append(buf, major<<5|27, 0, 0, 0, 0, 0, 0, 0, 0)
+	binary.BigEndian.PutUint64(buf[len(buf)-8:], arg)
+	return buf
+}
+
+// encodeCBOR appends the CBOR encoding of v to buf
+func encodeCBOR(buf []byte, v interface{}) ([]byte, error) {
+	var err error
+	switch v := v.(type) {
+	case int:
+		return encodeCBOR(buf, int64(v))
+	case int64:
+		if v < 0 {
+			return encodeCBORHead(buf, cborNegative, uint64(-1-v)), nil
+		}
+		return encodeCBORHead(buf, cborUnsigned, uint64(v)), nil
+	case []byte:
+		return append(encodeCBORHead(buf, cborBytes, uint64(len(v))), v...), nil
+	case string:
+		return append(encodeCBORHead(buf, cborText, uint64(len(v))), v...), nil
+	case bool:
+		if v {
+			return append(buf, cborSimple<<5|21), nil
+		}
+		return append(buf, cborSimple<<5|20), nil
+	case []interface{}:
+		buf = encodeCBORHead(buf, cborArray, uint64(len(v)))
+		for _, item := range v {
+			if buf, err = encodeCBOR(buf, item); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	case cborMapValue:
+		buf = encodeCBORHead(buf, cborMap, uint64(len(v)))
+		for _, pair := range v {
+			if buf, err = encodeCBOR(buf, pair.Key); err != nil {
+				return nil, err
+			}
+			if buf, err = encodeCBOR(buf, pair.Value); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	}
+	return nil, fmt.Errorf("cbor: unsupported type %T", v)
+}
@@ -0,0 +1,192 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+// COSE (RFC 8152) key parameters and algorithms supported for credentials
+const (
+	coseKeyType      = 1
+	coseKeyAlgorithm = 3
+	coseKeyCurve     = -1 // EC2 and OKP
+	coseKeyX         = -2 // EC2 and OKP
+	coseKeyY         = -3 // EC2
+	coseKeyN         = -1 // RSA
+	coseKeyE         = -2 // RSA
+
+	coseKeyTypeOKP = 1
+	coseKeyTypeEC2 = 2
+	coseKeyTypeRSA = 3
+
+	coseCurveP256    = 1
+	coseCurveP384    = 2
+	coseCurveP521    = 3
+	coseCurveEd25519 = 6
+)
+
+// COSEAlgorithm identifies a signature algorithm of a credential public key
+type COSEAlgorithm int64
+
+// enumerates all the signature algorithms accepted for credentials, most preferred first
+const (
+	AlgES256 COSEAlgorithm = -7
+	AlgEdDSA COSEAlgorithm = -8
+	AlgES384 COSEAlgorithm = -35
+	AlgES512 COSEAlgorithm = -36
+	AlgRS256 COSEAlgorithm = -257
+)
+
+var supportedAlgorithms = []COSEAlgorithm{AlgES256, AlgEdDSA, AlgES384, AlgES512, AlgRS256}
+
+// PublicKey is a parsed COSE credential public key
+type PublicKey struct {
+	Algorithm COSEAlgorithm
+	Key       crypto.PublicKey
+}
+
+func coseInt(m map[interface{}]interface{}, label int64) (int64, bool) {
+	v, ok := m[label].(int64)
+	return v, ok
+}
+
+func coseBytes(m map[interface{}]interface{}, label int64) ([]byte, bool) {
+	v, ok := m[label].([]byte)
+	return v, ok && len(v) > 0
+}
+
+// ParsePublicKey parses a COSE encoded credential public key
+func ParsePublicKey(data []byte) (*PublicKey, error) {
+	v, _, err := decodeCBOR(data)
+	if err != nil {
+		return nil, err
+	}
+	return parsePublicKey(v)
+}
+
+func parsePublicKey(v interface{}) (*PublicKey, error) {
+	m, ok := v.(map[interface{}]interface{})
+	if !ok {
+		return nil, errors.New("cose: key is not a map")
+	}
+	kty, _ := coseInt(m, coseKeyType)
+	alg, _ := coseInt(m, coseKeyAlgorithm)
+	key := &PublicKey{Algorithm: COSEAlgorithm(alg)}
+
+	switch kty {
+	case coseKeyTypeEC2:
+		var curve elliptic.Curve
+		crv, _ := coseInt(m, coseKeyCurve)
+		switch {
+		case crv == coseCurveP256 && key.Algorithm == AlgES256:
+			curve = elliptic.P256()
+		case crv == coseCurveP384 && key.Algorithm == AlgES384:
+			curve = elliptic.P384()
+		case crv == coseCurveP521 && key.Algorithm == AlgES512:
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("cose: unsupported EC2 curve %d with algorithm %d", crv, alg)
+		}
+		x, okX := coseBytes(m, coseKeyX)
+		y, okY := coseBytes(m, coseKeyY)
+		if !okX || !okY {
+			return nil, errors.New("cose: missing EC2 coordinates")
+		}
+		pub := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(pub.X, pub.Y) {
+			return nil, errors.New("cose: EC2 point is not on the curve")
+		}
+		key.Key = pub
+	case coseKeyTypeOKP:
+		crv, _ := coseInt(m, coseKeyCurve)
+		x, okX := coseBytes(m, coseKeyX)
+		if crv != coseCurveEd25519 || key.Algorithm != AlgEdDSA || !okX || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("cose: unsupported OKP curve %d with algorithm %d", crv, alg)
+		}
+		key.Key = ed25519.PublicKey(x)
+	case coseKeyTypeRSA:
+		n, okN := coseBytes(m, coseKeyN)
+		e, okE := coseBytes(m, coseKeyE)
+		if key.Algorithm != AlgRS256 || !okN || !okE || len(e) > 4 {
+			return nil, fmt.Errorf("cose: unsupported RSA key with algorithm %d", alg)
+		}
+		key.Key = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	default:
+		return nil, fmt.Errorf("cose: unsupported key type %d", kty)
+	}
+	return key, nil
+}
+
+// Verify checks the signature of message made by the private key of the credential
+func (key *PublicKey) Verify(message, sig []byte) error {
+	switch pub := key.Key.(type) {
+	case *ecdsa.PublicKey:
+		var digest []byte
+		switch key.Algorithm {
+		case AlgES384:
+			sum := sha512.Sum384(message)
+			digest = sum[:]
+		case AlgES512:
+			sum := sha512.Sum512(message)
+			digest = sum[:]
+		default:
+			sum := sha256.Sum256(message)
+			digest = sum[:]
+		}
+		var esig struct {
+			R, S *big.Int
+		}
+		if rest, err := asn1.Unmarshal(sig, &esig); err != nil || len(rest) != 0 {
+			return errors.New("webauthn: malformed ECDSA signature")
+		}
+		if !ecdsa.Verify(pub, digest, esig.R, esig.S) {
+			return errors.New("webauthn: invalid signature")
+		}
+		return nil
+	case ed25519.PublicKey:
+		if !ed25519.Verify(pub, message, sig) {
+			return errors.New("webauthn: invalid signature")
+		}
+		return nil
+	case *rsa.PublicKey:
+		digest := sha256.Sum256(message)
+		if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig); err != nil {
+			return errors.New("webauthn: invalid signature")
+		}
+		return nil
+	}
+	return fmt.Errorf("webauthn: unsupported public key %T", key.Key)
+}
+
+// MarshalECDSAPublicKey encodes a P-256 ECDSA public key as COSE ES256 key,
+// the format used by FIDO U2F security keys.
+func MarshalECDSAPublicKey(pub *ecdsa.PublicKey) ([]byte, error) {
+	if pub.Curve != elliptic.P256() {
+		return nil, errors.New("cose: only P-256 keys are supported")
+	}
+	coord := func(n *big.Int) []byte {
+		b := n.Bytes()
+		return append(make([]byte, 32-len(b)), b...)
+	}
+	return encodeCBOR(nil, cborMapValue{
+		{int64(coseKeyType), int64(coseKeyTypeEC2)},
+		{int64(coseKeyAlgorithm), int64(AlgES256)},
+		{int64(coseKeyCurve), int64(coseCurveP256)},
+		{int64(coseKeyX), coord(pub.X)},
+		{int64(coseKeyY), coord(pub.Y)},
+	})
+}
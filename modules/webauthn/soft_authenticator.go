@@ -0,0 +1,175 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webauthn
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+)
+
+// SoftAuthenticator is an authenticator and client implemented in software,
+// keeping its P-256 credentials in memory. It allows testing the ceremonies
+// without security keys or browsers.
+type SoftAuthenticator struct {
+	Origin      string
+	credentials []*softCredential
+}
+
+type softCredential struct {
+	id        []byte
+	key       *ecdsa.PrivateKey
+	scope     string
+	u2f       bool
+	signCount uint32
+}
+
+// NewSoftAuthenticator returns an authenticator used by a client at the given origin
+func NewSoftAuthenticator(origin string) *SoftAuthenticator {
+	return &SoftAuthenticator{Origin: origin}
+}
+
+func (a *SoftAuthenticator) newCredential(scope string, u2f bool) (*softCredential, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	id := make([]byte, 32)
+	if _, err = rand.Read(id); err != nil {
+		return nil, err
+	}
+	cred := &softCredential{id: id, key: key, scope: scope, u2f: u2f}
+	a.credentials = append(a.credentials, cred)
+	return cred, nil
+}
+
+// RegisterU2F creates a credential the way the legacy FIDO U2F API did,
+// returning its key handle and public key.
+func (a *SoftAuthenticator) RegisterU2F(appID string) ([]byte, *ecdsa.PublicKey, error) {
+	cred, err := a.newCredential(appID, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cred.id, &cred.key.PublicKey, nil
+}
+
+func (a *SoftAuthenticator) clientData(ceremony string, challenge []byte) ([]byte, error) {
+	return json.Marshal(&collectedClientData{
+		Type:      ceremony,
+		Challenge: base64.RawURLEncoding.EncodeToString(challenge),
+		Origin:    a.Origin,
+	})
+}
+
+func (a *SoftAuthenticator) authenticatorData(scope string, flags byte, signCount uint32) []byte {
+	rpIDHash := sha256.Sum256([]byte(scope))
+	data := append(rpIDHash[:], flags, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(data[33:], signCount)
+	return data
+}
+
+func (a *SoftAuthenticator) sign(key *ecdsa.PrivateKey, authData, clientData []byte) ([]byte, error) {
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+	return key.Sign(rand.Reader, digest[:], nil)
+}
+
+// Create makes a new credential, like navigator.credentials.create()
+func (a *SoftAuthenticator) Create(options *CredentialCreationOptions) (*CredentialCreationResponse, error) {
+	supported := false
+	for _, param := range options.Parameters {
+		supported = supported || param.Algorithm == AlgES256
+	}
+	if !supported {
+		return nil, errors.New("soft authenticator: ES256 not accepted")
+	}
+	for _, desc := range options.ExcludeCredentials {
+		for _, cred := range a.credentials {
+			if bytes.Equal(desc.ID, cred.id) {
+				return nil, errors.New("soft authenticator: credential already registered")
+			}
+		}
+	}
+
+	cred, err := a.newCredential(options.RelyingParty.ID, false)
+	if err != nil {
+		return nil, err
+	}
+	publicKey, err := MarshalECDSAPublicKey(&cred.key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	authData := a.authenticatorData(cred.scope, flagUserPresent|flagAttestedCredentialData, cred.signCount)
+	authData = append(authData, make([]byte, 16)...) // AAGUID
+	authData = append(authData, byte(len(cred.id)>>8), byte(len(cred.id)))
+	authData = append(append(authData, cred.id...), publicKey...)
+
+	attestationObject, err := encodeCBOR(nil, cborMapValue{
+		{"fmt", AttestationNone},
+		{"attStmt", cborMapValue{}},
+		{"authData", authData},
+	})
+	if err != nil {
+		return nil, err
+	}
+	clientData, err := a.clientData(ceremonyCreate, options.Challenge)
+	if err != nil {
+		return nil, err
+	}
+	return &CredentialCreationResponse{
+		ID:    base64.RawURLEncoding.EncodeToString(cred.id),
+		RawID: cred.id,
+		Type:  publicKeyCredentialType,
+		Response: AuthenticatorAttestationResponse{
+			ClientDataJSON:    clientData,
+			AttestationObject: attestationObject,
+		},
+	}, nil
+}
+
+// Get signs the challenge with the first allowed credential, like navigator.credentials.get()
+func (a *SoftAuthenticator) Get(options *CredentialAssertionOptions) (*CredentialAssertionResponse, error) {
+	for _, desc := range options.AllowCredentials {
+		for _, cred := range a.credentials {
+			if !bytes.Equal(desc.ID, cred.id) {
+				continue
+			}
+			// U2F credentials are scoped to the AppID, the appid extension selects it
+			usedAppID := cred.u2f && options.Extensions != nil && options.Extensions.AppID == cred.scope
+			if !usedAppID && cred.scope != options.RPID {
+				continue
+			}
+
+			cred.signCount++
+			authData := a.authenticatorData(cred.scope, flagUserPresent, cred.signCount)
+			clientData, err := a.clientData(ceremonyGet, options.Challenge)
+			if err != nil {
+				return nil, err
+			}
+			sig, err := a.sign(cred.key, authData, clientData)
+			if err != nil {
+				return nil, err
+			}
+			return &CredentialAssertionResponse{
+				ID:    base64.RawURLEncoding.EncodeToString(cred.id),
+				RawID: cred.id,
+				Type:  publicKeyCredentialType,
+				Response: AuthenticatorAssertionResponse{
+					ClientDataJSON:    clientData,
+					AuthenticatorData: authData,
+					Signature:         sig,
+				},
+				ClientExtensionResults: ClientExtensionResults{AppID: usedAppID},
+			}, nil
+		}
+	}
+	return nil, errors.New("soft authenticator: no allowed credential")
+}
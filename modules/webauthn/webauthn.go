@@ -0,0 +1,521 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package webauthn implements the relying party side of the registration and
+// authentication ceremonies of Web Authentication (https://www.w3.org/TR/webauthn/).
+package webauthn
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	publicKeyCredentialType = "public-key"
+
+	ceremonyCreate = "webauthn.create"
+	ceremonyGet    = "webauthn.get"
+
+	// UserVerificationDiscouraged lets authenticators skip verifying the user,
+	// credentials are used as second factor after the password.
+	UserVerificationDiscouraged = "discouraged"
+
+	// AttestationNone asks browsers not to reveal the make and model of authenticators.
+	AttestationNone = "none"
+
+	challengeLength = 32
+)
+
+// flags of the authenticator data
+const (
+	flagUserPresent            = 0x01
+	flagAttestedCredentialData = 0x40
+	flagExtensionData          = 0x80
+)
+
+// URLEncodedBase64 is binary data represented as unpadded base64url in JSON
+type URLEncodedBase64 []byte
+
+// MarshalJSON encodes the data as unpadded base64url string
+func (b URLEncodedBase64) MarshalJSON() ([]byte, error) {
+	return json.Marshal(base64.RawURLEncoding.EncodeToString(b))
+}
+
+// UnmarshalJSON decodes a base64url string, padded or not
+func (b *URLEncodedBase64) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	v, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return err
+	}
+	*b = v
+	return nil
+}
+
+// Config describes the relying party, the Gitea instance users authenticate to
+type Config struct {
+	RPID          string
+	RPDisplayName string
+	RPOrigin      string
+	// AppID is the FIDO U2F application ID that credentials registered through
+	// the legacy U2F API are scoped to.
+	AppID   string
+	Timeout time.Duration
+}
+
+// RelyingPartyEntity identifies the relying party to the authenticator
+type RelyingPartyEntity struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// UserEntity identifies the user account a credential is created for
+type UserEntity struct {
+	ID          URLEncodedBase64 `json:"id"`
+	Name        string           `json:"name"`
+	DisplayName string           `json:"displayName"`
+}
+
+// CredentialParameter is a credential type and algorithm accepted by the relying party
+type CredentialParameter struct {
+	Type      string        `json:"type"`
+	Algorithm COSEAlgorithm `json:"alg"`
+}
+
+// CredentialDescriptor references an existing credential
+type CredentialDescriptor struct {
+	Type string           `json:"type"`
+	ID   URLEncodedBase64 `json:"id"`
+}
+
+// AuthenticatorSelection are the requirements on authenticators creating credentials.
+// No attachment is required, so both roaming and platform authenticators are allowed.
+type AuthenticatorSelection struct {
+	RequireResidentKey bool   `json:"requireResidentKey"`
+	UserVerification   string `json:"userVerification"`
+}
+
+// CredentialCreationOptions are the publicKey options for navigator.credentials.create()
+type CredentialCreationOptions struct {
+	Challenge              URLEncodedBase64       `json:"challenge"`
+	RelyingParty           RelyingPartyEntity     `json:"rp"`
+	User                   UserEntity             `json:"user"`
+	Parameters             []CredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int64                  `json:"timeout"`
+	ExcludeCredentials     []CredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection AuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                 `json:"attestation"`
+}
+
+// AssertionExtensions are the client extensions requested for an assertion
+type AssertionExtensions struct {
+	AppID string `json:"appid,omitempty"`
+}
+
+// CredentialAssertionOptions are the publicKey options for navigator.credentials.get()
+type CredentialAssertionOptions struct {
+	Challenge        URLEncodedBase64       `json:"challenge"`
+	Timeout          int64                  `json:"timeout"`
+	RPID             string                 `json:"rpId"`
+	AllowCredentials []CredentialDescriptor `json:"allowCredentials"`
+	UserVerification string                 `json:"userVerification"`
+	Extensions       *AssertionExtensions   `json:"extensions,omitempty"`
+}
+
+// SessionData is kept on the server between beginning and finishing a ceremony
+type SessionData struct {
+	Challenge            []byte
+	AllowedCredentialIDs [][]byte
+}
+
+// AuthenticatorAttestationResponse is the response of an authenticator creating a credential
+type AuthenticatorAttestationResponse struct {
+	ClientDataJSON    URLEncodedBase64 `json:"clientDataJSON"`
+	AttestationObject URLEncodedBase64 `json:"attestationObject"`
+}
+
+// CredentialCreationResponse is the credential returned by navigator.credentials.create()
+type CredentialCreationResponse struct {
+	ID       string                           `json:"id"`
+	RawID    URLEncodedBase64                 `json:"rawId"`
+	Type     string                           `json:"type"`
+	Response AuthenticatorAttestationResponse `json:"response"`
+}
+
+// AuthenticatorAssertionResponse is the response of an authenticator signing a challenge
+type AuthenticatorAssertionResponse struct {
+	ClientDataJSON    URLEncodedBase64 `json:"clientDataJSON"`
+	AuthenticatorData URLEncodedBase64 `json:"authenticatorData"`
+	Signature         URLEncodedBase64 `json:"signature"`
+	UserHandle        URLEncodedBase64 `json:"userHandle"`
+}
+
+// ClientExtensionResults are the outputs of the client extensions
+type ClientExtensionResults struct {
+	AppID bool `json:"appid"`
+}
+
+// CredentialAssertionResponse is the credential returned by navigator.credentials.get()
+type CredentialAssertionResponse struct {
+	ID                     string                         `json:"id"`
+	RawID                  URLEncodedBase64               `json:"rawId"`
+	Type                   string                         `json:"type"`
+	Response               AuthenticatorAssertionResponse `json:"response"`
+	ClientExtensionResults ClientExtensionResults         `json:"clientExtensionResults"`
+}
+
+// Credential is a registered public key credential
+type Credential struct {
+	ID              []byte
+	PublicKey       []byte
+	AttestationType string
+	AAGUID          []byte
+	SignCount       uint32
+	// U2F is set for credentials registered through the legacy FIDO U2F API
+	U2F bool
+}
+
+type collectedClientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+type authenticatorData struct {
+	Raw                 []byte
+	RPIDHash            []byte
+	Flags               byte
+	SignCount           uint32
+	AAGUID              []byte
+	CredentialID        []byte
+	CredentialPublicKey []byte
+}
+
+func newChallenge() ([]byte, error) {
+	challenge := make([]byte, challengeLength)
+	if _, err := rand.Read(challenge); err != nil {
+		return nil, err
+	}
+	return challenge, nil
+}
+
+func (c *Config) timeout() int64 {
+	if c.Timeout <= 0 {
+		return int64(time.Minute / time.Millisecond)
+	}
+	return int64(c.Timeout / time.Millisecond)
+}
+
+func descriptors(ids [][]byte) []CredentialDescriptor {
+	descs := make([]CredentialDescriptor, 0, len(ids))
+	for _, id := range ids {
+		descs = append(descs, CredentialDescriptor{Type: publicKeyCredentialType, ID: id})
+	}
+	return descs
+}
+
+// BeginRegistration returns the options to create a new credential for the user,
+// excluding the authenticators holding one of the existing credentials.
+func (c *Config) BeginRegistration(user UserEntity, existing [][]byte) (*CredentialCreationOptions, *SessionData, error) {
+	challenge, err := newChallenge()
+	if err != nil {
+		return nil, nil, err
+	}
+	params := make([]CredentialParameter, 0, len(supportedAlgorithms))
+	for _, alg := range supportedAlgorithms {
+		params = append(params, CredentialParameter{Type: publicKeyCredentialType, Algorithm: alg})
+	}
+	options := &CredentialCreationOptions{
+		Challenge:          challenge,
+		RelyingParty:       RelyingPartyEntity{ID: c.RPID, Name: c.RPDisplayName},
+		User:               user,
+		Parameters:         params,
+		Timeout:            c.timeout(),
+		ExcludeCredentials: descriptors(existing),
+		AuthenticatorSelection: AuthenticatorSelection{
+			UserVerification: UserVerificationDiscouraged,
+		},
+		Attestation: AttestationNone,
+	}
+	return options, &SessionData{Challenge: challenge}, nil
+}
+
+// FinishRegistration verifies the newly created credential
+func (c *Config) FinishRegistration(session *SessionData, resp *CredentialCreationResponse) (*Credential, error) {
+	if resp.Type != publicKeyCredentialType {
+		return nil, fmt.Errorf("webauthn: unexpected credential type %q", resp.Type)
+	}
+	if err := c.verifyClientData(resp.Response.ClientDataJSON, ceremonyCreate, session.Challenge); err != nil {
+		return nil, err
+	}
+
+	v, _, err := decodeCBOR(resp.Response.AttestationObject)
+	if err != nil {
+		return nil, fmt.Errorf("webauthn: attestation object: %v", err)
+	}
+	attObj, ok := v.(map[interface{}]interface{})
+	if !ok {
+		return nil, errors.New("webauthn: attestation object is not a map")
+	}
+	format, _ := attObj["fmt"].(string)
+	rawAuthData, _ := attObj["authData"].([]byte)
+	attStmt, _ := attObj["attStmt"].(map[interface{}]interface{})
+
+	authData, err := parseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.verifyAuthenticatorData(authData, false); err != nil {
+		return nil, err
+	}
+	if authData.Flags&flagAttestedCredentialData == 0 {
+		return nil, errors.New("webauthn: no attested credential data")
+	}
+	if len(resp.RawID) > 0 && !bytes.Equal(resp.RawID, authData.CredentialID) {
+		return nil, errors.New("webauthn: credential ID mismatch")
+	}
+	pub, err := ParsePublicKey(authData.CredentialPublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	clientDataHash := sha256.Sum256(resp.Response.ClientDataJSON)
+	if err = verifyAttestationStatement(format, attStmt, authData, clientDataHash[:], pub); err != nil {
+		return nil, err
+	}
+
+	return &Credential{
+		ID:              authData.CredentialID,
+		PublicKey:       authData.CredentialPublicKey,
+		AttestationType: format,
+		AAGUID:          authData.AAGUID,
+		SignCount:       authData.SignCount,
+	}, nil
+}
+
+// BeginLogin returns the options to get an assertion from one of the credentials of the user
+func (c *Config) BeginLogin(credentials []*Credential) (*CredentialAssertionOptions, *SessionData, error) {
+	if len(credentials) == 0 {
+		return nil, nil, errors.New("webauthn: no credentials")
+	}
+	challenge, err := newChallenge()
+	if err != nil {
+		return nil, nil, err
+	}
+	ids := make([][]byte, 0, len(credentials))
+	hasU2F := false
+	for _, cred := range credentials {
+		ids = append(ids, cred.ID)
+		hasU2F = hasU2F || cred.U2F
+	}
+	options := &CredentialAssertionOptions{
+		Challenge:        challenge,
+		Timeout:          c.timeout(),
+		RPID:             c.RPID,
+		AllowCredentials: descriptors(ids),
+		UserVerification: UserVerificationDiscouraged,
+	}
+	if hasU2F && c.AppID != "" {
+		options.Extensions = &AssertionExtensions{AppID: c.AppID}
+	}
+	return options, &SessionData{Challenge: challenge, AllowedCredentialIDs: ids}, nil
+}
+
+// FinishLogin verifies the assertion of the credential and returns its new signature counter
+func (c *Config) FinishLogin(session *SessionData, resp *CredentialAssertionResponse, cred *Credential) (uint32, error) {
+	if resp.Type != publicKeyCredentialType {
+		return 0, fmt.Errorf("webauthn: unexpected credential type %q", resp.Type)
+	}
+	if !bytes.Equal(resp.RawID, cred.ID) {
+		return 0, errors.New("webauthn: credential ID mismatch")
+	}
+	allowed := false
+	for _, id := range session.AllowedCredentialIDs {
+		if bytes.Equal(id, cred.ID) {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return 0, errors.New("webauthn: credential not allowed")
+	}
+	if err := c.verifyClientData(resp.Response.ClientDataJSON, ceremonyGet, session.Challenge); err != nil {
+		return 0, err
+	}
+
+	authData, err := parseAuthenticatorData(resp.Response.AuthenticatorData)
+	if err != nil {
+		return 0, err
+	}
+	if err = c.verifyAuthenticatorData(authData, cred.U2F && resp.ClientExtensionResults.AppID); err != nil {
+		return 0, err
+	}
+
+	pub, err := ParsePublicKey(cred.PublicKey)
+	if err != nil {
+		return 0, err
+	}
+	clientDataHash := sha256.Sum256(resp.Response.ClientDataJSON)
+	if err = pub.Verify(append(append([]byte{}, authData.Raw...), clientDataHash[:]...), resp.Response.Signature); err != nil {
+		return 0, err
+	}
+
+	if (authData.SignCount != 0 || cred.SignCount != 0) && authData.SignCount <= cred.SignCount {
+		return 0, fmt.Errorf("webauthn: signature counter %d not greater than %d, the authenticator may be cloned", authData.SignCount, cred.SignCount)
+	}
+	return authData.SignCount, nil
+}
+
+func (c *Config) verifyClientData(raw []byte, ceremony string, challenge []byte) error {
+	var clientData collectedClientData
+	if err := json.Unmarshal(raw, &clientData); err != nil {
+		return fmt.Errorf("webauthn: client data: %v", err)
+	}
+	if clientData.Type != ceremony {
+		return fmt.Errorf("webauthn: unexpected ceremony %q", clientData.Type)
+	}
+	got, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(clientData.Challenge, "="))
+	if err != nil || len(challenge) == 0 || subtle.ConstantTimeCompare(got, challenge) != 1 {
+		return errors.New("webauthn: challenge mismatch")
+	}
+	if strings.TrimRight(clientData.Origin, "/") != strings.TrimRight(c.RPOrigin, "/") {
+		return fmt.Errorf("webauthn: unexpected origin %q", clientData.Origin)
+	}
+	return nil
+}
+
+func (c *Config) verifyAuthenticatorData(authData *authenticatorData, appID bool) error {
+	scope := c.RPID
+	if appID {
+		scope = c.AppID
+	}
+	rpIDHash := sha256.Sum256([]byte(scope))
+	if subtle.ConstantTimeCompare(authData.RPIDHash, rpIDHash[:]) != 1 {
+		return errors.New("webauthn: relying party ID mismatch")
+	}
+	if authData.Flags&flagUserPresent == 0 {
+		return errors.New("webauthn: user not present")
+	}
+	return nil
+}
+
+func parseAuthenticatorData(data []byte) (*authenticatorData, error) {
+	if len(data) < 37 {
+		return nil, errors.New("webauthn: authenticator data too short")
+	}
+	authData := &authenticatorData{
+		Raw:       data,
+		RPIDHash:  data[:32],
+		Flags:     data[32],
+		SignCount: binary.BigEndian.Uint32(data[33:37]),
+	}
+	rest := data[37:]
+
+	if authData.Flags&flagAttestedCredentialData != 0 {
+		if len(rest) < 18 {
+			return nil, errors.New("webauthn: attested credential data too short")
+		}
+		authData.AAGUID = rest[:16]
+		idLen := int(binary.BigEndian.Uint16(rest[16:18]))
+		rest = rest[18:]
+		if len(rest) < idLen {
+			return nil, errors.New("webauthn: credential ID too short")
+		}
+		authData.CredentialID = rest[:idLen]
+		rest = rest[idLen:]
+
+		_, after, err := decodeCBOR(rest)
+		if err != nil {
+			return nil, fmt.Errorf("webauthn: credential public key: %v", err)
+		}
+		authData.CredentialPublicKey = rest[:len(rest)-len(after)]
+		rest = after
+	}
+
+	if authData.Flags&flagExtensionData != 0 {
+		_, after, err := decodeCBOR(rest)
+		if err != nil {
+			return nil, fmt.Errorf("webauthn: extensions: %v", err)
+		}
+		rest = after
+	}
+	if len(rest) != 0 {
+		return nil, errors.New("webauthn: trailing authenticator data")
+	}
+	return authData, nil
+}
+
+// verifyAttestationStatement checks the signature of the "packed" and "fido-u2f"
+// attestation statements. As with the former U2F support the attestation
+// certificates are not checked against a list of trusted vendors, statements in
+// other formats are accepted as they only prove the make of the authenticator.
+func verifyAttestationStatement(format string, attStmt map[interface{}]interface{}, authData *authenticatorData, clientDataHash []byte, pub *PublicKey) error {
+	switch format {
+	case AttestationNone:
+		if len(attStmt) != 0 {
+			return errors.New("webauthn: unexpected attestation statement")
+		}
+		return nil
+	case "packed":
+		alg, _ := attStmt["alg"].(int64)
+		sig, _ := attStmt["sig"].([]byte)
+		message := append(append([]byte{}, authData.Raw...), clientDataHash...)
+		if x5c, ok := attStmt["x5c"].([]interface{}); ok && len(x5c) > 0 {
+			cert, err := parseAttestationCertificate(x5c[0])
+			if err != nil {
+				return err
+			}
+			return (&PublicKey{Algorithm: COSEAlgorithm(alg), Key: cert.PublicKey}).Verify(message, sig)
+		}
+		// self attestation is signed by the credential itself
+		if COSEAlgorithm(alg) != pub.Algorithm {
+			return errors.New("webauthn: attestation algorithm mismatch")
+		}
+		return pub.Verify(message, sig)
+	case "fido-u2f":
+		sig, _ := attStmt["sig"].([]byte)
+		x5c, _ := attStmt["x5c"].([]interface{})
+		if len(x5c) != 1 || pub.Algorithm != AlgES256 {
+			return errors.New("webauthn: malformed fido-u2f attestation")
+		}
+		cert, err := parseAttestationCertificate(x5c[0])
+		if err != nil {
+			return err
+		}
+		m, _, _ := decodeCBOR(authData.CredentialPublicKey)
+		x, _ := coseBytes(m.(map[interface{}]interface{}), coseKeyX)
+		y, _ := coseBytes(m.(map[interface{}]interface{}), coseKeyY)
+		message := append([]byte{0x00}, authData.RPIDHash...)
+		message = append(message, clientDataHash...)
+		message = append(message, authData.CredentialID...)
+		message = append(append(append(message, 0x04), x...), y...)
+		return (&PublicKey{Algorithm: AlgES256, Key: cert.PublicKey}).Verify(message, sig)
+	}
+	return nil
+}
+
+func parseAttestationCertificate(v interface{}) (*x509.Certificate, error) {
+	der, ok := v.([]byte)
+	if !ok {
+		return nil, errors.New("webauthn: malformed attestation certificate")
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("webauthn: attestation certificate: %v", err)
+	}
+	return cert, nil
+}
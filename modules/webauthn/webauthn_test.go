@@ -0,0 +1,144 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webauthn
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testConfig = &Config{
+	RPID:          "localhost",
+	RPDisplayName: "Gitea",
+	RPOrigin:      "http://localhost:3000",
+	AppID:         "http://localhost:3000",
+}
+
+func register(t *testing.T, a *SoftAuthenticator) *Credential {
+	options, session, err := testConfig.BeginRegistration(UserEntity{ID: []byte("1"), Name: "user1", DisplayName: "User One"}, nil)
+	assert.NoError(t, err)
+	resp, err := a.Create(options)
+	assert.NoError(t, err)
+	cred, err := testConfig.FinishRegistration(session, resp)
+	assert.NoError(t, err)
+	return cred
+}
+
+func TestCBOR(t *testing.T) {
+	data, err := encodeCBOR(nil, cborMapValue{
+		{int64(1), int64(2)},
+		{int64(-3), []byte{1, 2, 3}},
+		{"text", []interface{}{true, false, int64(1000000)}},
+	})
+	assert.NoError(t, err)
+
+	v, rest, err := decodeCBOR(append(data, 0xff))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0xff}, rest)
+	assert.Equal(t, map[interface{}]interface{}{
+		int64(1):  int64(2),
+		int64(-3): []byte{1, 2, 3},
+		"text":    []interface{}{true, false, int64(1000000)},
+	}, v)
+
+	_, _, err = decodeCBOR(data[:len(data)-1])
+	assert.Error(t, err)
+}
+
+func TestRegistration(t *testing.T) {
+	a := NewSoftAuthenticator(testConfig.RPOrigin)
+	cred := register(t, a)
+	assert.Equal(t, AttestationNone, cred.AttestationType)
+	pub, err := ParsePublicKey(cred.PublicKey)
+	assert.NoError(t, err)
+	assert.Equal(t, AlgES256, pub.Algorithm)
+
+	// Authenticators holding an existing credential are excluded
+	options, _, err := testConfig.BeginRegistration(UserEntity{ID: []byte("1"), Name: "user1"}, [][]byte{cred.ID})
+	assert.NoError(t, err)
+	_, err = a.Create(options)
+	assert.Error(t, err)
+
+	// The options survive being sent to the browser
+	data, err := json.Marshal(options)
+	assert.NoError(t, err)
+	var decoded CredentialCreationOptions
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, *options, decoded)
+
+	options, session, err := testConfig.BeginRegistration(UserEntity{ID: []byte("1"), Name: "user1"}, nil)
+	assert.NoError(t, err)
+	resp, err := NewSoftAuthenticator("http://evil.example.com").Create(options)
+	assert.NoError(t, err)
+	_, err = testConfig.FinishRegistration(session, resp)
+	assert.Error(t, err, "origin must match")
+
+	resp, err = a.Create(options)
+	assert.NoError(t, err)
+	_, session, err = testConfig.BeginRegistration(UserEntity{ID: []byte("1"), Name: "user1"}, nil)
+	assert.NoError(t, err)
+	_, err = testConfig.FinishRegistration(session, resp)
+	assert.Error(t, err, "challenge must match")
+}
+
+func TestLogin(t *testing.T) {
+	a := NewSoftAuthenticator(testConfig.RPOrigin)
+	cred := register(t, a)
+
+	options, session, err := testConfig.BeginLogin([]*Credential{cred})
+	assert.NoError(t, err)
+	assert.Nil(t, options.Extensions)
+	resp, err := a.Get(options)
+	assert.NoError(t, err)
+
+	// A response can be used only with its own credential
+	other := register(t, NewSoftAuthenticator(testConfig.RPOrigin))
+	_, err = testConfig.FinishLogin(session, resp, other)
+	assert.Error(t, err)
+
+	signCount, err := testConfig.FinishLogin(session, resp, cred)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, signCount)
+	cred.SignCount = signCount
+
+	// Replayed or cloned signature counters are rejected
+	_, err = testConfig.FinishLogin(session, resp, cred)
+	assert.Error(t, err)
+
+	options, session, err = testConfig.BeginLogin([]*Credential{cred})
+	assert.NoError(t, err)
+	resp, err = a.Get(options)
+	assert.NoError(t, err)
+	resp.Response.Signature[len(resp.Response.Signature)-1] ^= 0xff
+	_, err = testConfig.FinishLogin(session, resp, cred)
+	assert.Error(t, err, "signature must verify")
+}
+
+func TestLoginU2F(t *testing.T) {
+	a := NewSoftAuthenticator(testConfig.RPOrigin)
+	id, pub, err := a.RegisterU2F(testConfig.AppID)
+	assert.NoError(t, err)
+	publicKey, err := MarshalECDSAPublicKey(pub)
+	assert.NoError(t, err)
+	cred := &Credential{ID: id, PublicKey: publicKey, U2F: true}
+
+	options, session, err := testConfig.BeginLogin([]*Credential{cred})
+	assert.NoError(t, err)
+	assert.Equal(t, testConfig.AppID, options.Extensions.AppID)
+	resp, err := a.Get(options)
+	assert.NoError(t, err)
+	assert.True(t, resp.ClientExtensionResults.AppID)
+	signCount, err := testConfig.FinishLogin(session, resp, cred)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, signCount)
+
+	// The AppID scope is only accepted for U2F credentials
+	cred.U2F = false
+	cred.SignCount = 0
+	_, err = testConfig.FinishLogin(session, resp, cred)
+	assert.Error(t, err)
+}
@@ -1536,7 +1536,7 @@ function initAdmin() {
     }
 
     function onOAuth2Change() {
-        $('.open_id_connect_auto_discovery_url, .oauth2_use_custom_url').hide();
+        $('.open_id_connect_auto_discovery_url, .open_id_connect_field, .oauth2_use_custom_url').hide();
         $('.open_id_connect_auto_discovery_url input[required]').removeAttr('required');
 
         var provider = $('#oauth2_provider').val();
@@ -1547,7 +1547,7 @@ function initAdmin() {
                 break;
             case 'openidConnect':
                 $('.open_id_connect_auto_discovery_url input').attr('required', 'required');
-                $('.open_id_connect_auto_discovery_url').show();
+                $('.open_id_connect_auto_discovery_url, .open_id_connect_field').show();
                 break;
         }
         onOAuth2UseCustomURLChange();
@@ -1588,7 +1588,7 @@ function initAdmin() {
     // New authentication
     if ($('.admin.new.authentication').length > 0) {
         $('#auth_type').change(function () {
-            $('.ldap, .dldap, .smtp, .pam, .oauth2, .has-tls .search-page-size').hide();
+            $('.ldap, .dldap, .smtp, .pam, .oauth2, .saml, .has-tls .search-page-size').hide();
 
             $('.ldap input[required], .binddnrequired input[required], .dldap input[required], .smtp input[required], .pam input[required], .oauth2 input[required], .has-tls input[required]').removeAttr('required');
             $('.binddnrequired').removeClass("required");
@@ -1618,6 +1618,9 @@ function initAdmin() {
                     $('.oauth2 div.required:not(.oauth2_use_custom_url,.oauth2_use_custom_url_field,.open_id_connect_auto_discovery_url) input').attr('required', 'required');
                     onOAuth2Change();
                     break;
+                case '7':     // SAML
+                    $('.saml').show();
+                    break;
             }
             if (authType == '2' || authType == '5') {
                 onSecurityProtocolChange()
@@ -1778,123 +1781,146 @@ function initCodeView() {
     }
 }
 
-function initU2FAuth() {
+function webAuthnDecode(value) {
+    var str = atob(value.replace(/-/g, '+').replace(/_/g, '/'));
+    var bytes = new Uint8Array(str.length);
+    for (var i = 0; i < str.length; i++) {
+        bytes[i] = str.charCodeAt(i);
+    }
+    return bytes.buffer;
+}
+
+function webAuthnEncode(buffer) {
+    if (!buffer) {
+        return null;
+    }
+    var bytes = new Uint8Array(buffer);
+    var str = '';
+    for (var i = 0; i < bytes.length; i++) {
+        str += String.fromCharCode(bytes[i]);
+    }
+    return btoa(str).replace(/\+/g, '-').replace(/\//g, '_').replace(/=+$/, '');
+}
+
+function webAuthnDecodeCredentials(descriptors) {
+    return (descriptors || []).map(function (desc) {
+        return {type: desc.type, id: webAuthnDecode(desc.id)};
+    });
+}
+
+function webAuthnSupported() {
+    return window.PublicKeyCredential !== undefined && navigator.credentials !== undefined;
+}
+
+function initWebAuthnAuth() {
     if($('#wait-for-key').length === 0) {
         return
     }
-    u2fApi.ensureSupport()
-        .then(function () {
-            $.getJSON(suburl + '/user/u2f/challenge').success(function(req) {
-                u2fApi.sign(req.appId, req.challenge, req.registeredKeys, 30)
-                    .then(u2fSigned)
-                    .catch(function (err) {
-                        if(err === undefined) {
-                            u2fError(1);
-                            return
-                        }
-                        u2fError(err.metaData.code);
-                    });
-            });
-        }).catch(function () {
-            // Fallback in case browser do not support U2F
-            window.location.href = suburl + "/user/two_factor"
-        })
+    if (!webAuthnSupported()) {
+        // Fallback in case browser do not support WebAuthn
+        window.location.href = suburl + "/user/two_factor";
+        return
+    }
+    $.getJSON(suburl + '/user/webauthn/assertion').success(function(options) {
+        options.challenge = webAuthnDecode(options.challenge);
+        options.allowCredentials = webAuthnDecodeCredentials(options.allowCredentials);
+        navigator.credentials.get({publicKey: options})
+            .then(webAuthnAsserted)
+            .catch(webAuthnError);
+    });
 }
-function u2fSigned(resp) {
+
+function webAuthnAsserted(credential) {
     $.ajax({
-        url: suburl + '/user/u2f/sign',
+        url: suburl + '/user/webauthn/assertion',
         type: "POST",
         headers: {"X-Csrf-Token": csrf},
-        data: JSON.stringify(resp),
+        data: JSON.stringify({
+            id: credential.id,
+            rawId: webAuthnEncode(credential.rawId),
+            type: credential.type,
+            response: {
+                clientDataJSON: webAuthnEncode(credential.response.clientDataJSON),
+                authenticatorData: webAuthnEncode(credential.response.authenticatorData),
+                signature: webAuthnEncode(credential.response.signature),
+                userHandle: webAuthnEncode(credential.response.userHandle)
+            },
+            clientExtensionResults: credential.getClientExtensionResults()
+        }),
         contentType: "application/json; charset=utf-8",
     }).done(function(res){
         window.location.replace(res);
     }).fail(function () {
-        u2fError(1);
+        webAuthnError('rejected');
     });
 }
 
-function u2fRegistered(resp) {
-    if (checkError(resp)) {
-        return;
-    }
+function webAuthnRegistered(credential) {
     $.ajax({
-        url: suburl + '/user/settings/security/u2f/register',
+        url: suburl + '/user/settings/security/webauthn/register',
         type: "POST",
         headers: {"X-Csrf-Token": csrf},
-        data: JSON.stringify(resp),
+        data: JSON.stringify({
+            id: credential.id,
+            rawId: webAuthnEncode(credential.rawId),
+            type: credential.type,
+            response: {
+                clientDataJSON: webAuthnEncode(credential.response.clientDataJSON),
+                attestationObject: webAuthnEncode(credential.response.attestationObject)
+            }
+        }),
         contentType: "application/json; charset=utf-8",
-        success: function(){
-            reload();
-        },
-        fail: function () {
-            u2fError(1);
-        }
+    }).done(function(){
+        reload();
+    }).fail(function () {
+        webAuthnError('rejected');
     });
 }
 
-function checkError(resp) {
-    if (!('errorCode' in resp)) {
-        return false;
-    }
-    if (resp.errorCode === 0) {
-        return false;
+function webAuthnError(err) {
+    var errorType = 'unknown';
+    if (typeof err === 'string') {
+        errorType = err;
+    } else if (err && $('#webauthn-error-' + err.name).length > 0) {
+        errorType = err.name;
     }
-    u2fError(resp.errorCode);
-    return true;
-}
-
-
-function u2fError(errorType) {
-    var u2fErrors = {
-        'browser': $('#unsupported-browser'),
-        1: $('#u2f-error-1'),
-        2: $('#u2f-error-2'),
-        3: $('#u2f-error-3'),
-        4: $('#u2f-error-4'),
-        5: $('.u2f-error-5')
-    };
-    u2fErrors[errorType].removeClass('hide');
-    for(var type in u2fErrors){
-        if(type != errorType){
-            u2fErrors[type].addClass('hide');
-        }
+    $('#webauthn-error .negative.message > div:not(.header)').addClass('hide');
+    if (errorType === 'rejected') {
+        $('.webauthn-error-rejected').removeClass('hide');
+    } else {
+        $('.webauthn-error-rejected').addClass('hide');
+        $('#webauthn-error-' + errorType).removeClass('hide');
     }
-    $('#u2f-error').modal('show');
+    $('#register-device').modal('hide');
+    $('#webauthn-error').modal('show');
 }
 
-function initU2FRegister() {
+function initWebAuthnRegister() {
     $('#register-device').modal({allowMultiple: false});
-    $('#u2f-error').modal({allowMultiple: false});
+    $('#webauthn-error').modal({allowMultiple: false});
     $('#register-security-key').on('click', function(e) {
         e.preventDefault();
-        u2fApi.ensureSupport()
-            .then(u2fRegisterRequest)
-            .catch(function() {
-                u2fError('browser');
-            })
+        if (!webAuthnSupported()) {
+            webAuthnError('unsupported');
+            return
+        }
+        webAuthnRegisterRequest();
     })
 }
 
-function u2fRegisterRequest() {
-    $.post(suburl + "/user/settings/security/u2f/request_register", {
+function webAuthnRegisterRequest() {
+    $.post(suburl + "/user/settings/security/webauthn/request_register", {
         "_csrf": csrf,
         "name": $('#nickname').val()
-    }).success(function(req) {
+    }).success(function(options) {
         $("#nickname").closest("div.field").removeClass("error");
         $('#register-device').modal('show');
-        if(req.registeredKeys === null) {
-            req.registeredKeys = []
-        }
-        u2fApi.register(req.appId, req.registerRequests, req.registeredKeys, 30)
-            .then(u2fRegistered)
-            .catch(function (reason) {
-                if(reason === undefined) {
-                    u2fError(1);
-                    return
-                }
-                u2fError(reason.metaData.code);
-            });
+        options.challenge = webAuthnDecode(options.challenge);
+        options.user.id = webAuthnDecode(options.user.id);
+        options.excludeCredentials = webAuthnDecodeCredentials(options.excludeCredentials);
+        navigator.credentials.create({publicKey: options})
+            .then(webAuthnRegistered)
+            .catch(webAuthnError);
     }).fail(function(xhr) {
         if(xhr.status === 409) {
             $("#nickname").closest("div.field").addClass("error");
@@ -1920,6 +1946,52 @@ function initWipTitle() {
     });
 }
 
+function initProjectBoards() {
+    var $boards = $('.project-boards');
+    if ($boards.length === 0 || !$boards.data('url')) {
+        return;
+    }
+
+    var $dragged = null;
+    $boards.find('.card[draggable]').on('dragstart', function (e) {
+        $dragged = $(this);
+        e.originalEvent.dataTransfer.effectAllowed = 'move';
+        e.originalEvent.dataTransfer.setData('text/plain', $dragged.data('issue'));
+    }).on('dragend', function () {
+        $dragged = null;
+    });
+
+    $boards.find('.project-board').on('dragover', function (e) {
+        if ($dragged) {
+            e.preventDefault();
+        }
+    }).on('drop', function (e) {
+        e.preventDefault();
+        if (!$dragged) {
+            return;
+        }
+        var $cards = $(this).find('.cards');
+        var $target = $(e.target).closest('.card');
+        if ($target.length > 0 && $target[0] !== $dragged[0]) {
+            $target.before($dragged);
+        } else if ($target.length === 0) {
+            $cards.append($dragged);
+        }
+
+        $.post($boards.data('url'), {
+            "_csrf": csrf,
+            "issue_id": $dragged.data('issue'),
+            "board_id": $(this).data('id'),
+            "sorting": $cards.children('.card').index($dragged)
+        }).fail(function () {
+            window.location.reload();
+        });
+        $boards.find('.project-board').each(function () {
+            $(this).find('.header .label').text($(this).find('.cards .card').length);
+        });
+    });
+}
+
 $(document).ready(function () {
     csrf = $('meta[name=_csrf]').attr("content");
     suburl = $('meta[name=_suburl]').attr("content");
@@ -2124,6 +2196,13 @@ $(document).ready(function () {
         updateIssuesMeta(url, action, issueIDs, elementId).then(reload);
     });
 
+    $('#bulk-edit-issues-form').submit(function () {
+        const issueIDs = $('.issue-checkbox').children('input:checked').map(function() {
+            return this.dataset.issueId;
+        }).get().join();
+        $(this).find('input[name=issue_ids]').val(issueIDs);
+    });
+
     buttonsClickOnEnter();
     searchUsers();
     searchRepositories();
@@ -2144,11 +2223,12 @@ $(document).ready(function () {
     initCtrlEnterSubmit();
     initNavbarContentToggle();
     initTopicbar();
-    initU2FAuth();
-    initU2FRegister();
+    initWebAuthnAuth();
+    initWebAuthnRegister();
     initIssueList();
     initWipTitle();
     initPullRequestReview();
+    initProjectBoards();
 
     // Repo clone url.
     if ($('#repo-clone-url').length > 0) {
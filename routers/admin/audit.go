@@ -0,0 +1,48 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"github.com/masoodkamyab/gitea/models"
+	"github.com/masoodkamyab/gitea/modules/base"
+	"github.com/masoodkamyab/gitea/modules/context"
+	"github.com/masoodkamyab/gitea/modules/setting"
+)
+
+const (
+	tplAuditEvents base.TplName = "admin/audit"
+)
+
+// AuditEvents show the audit log of the whole site
+func AuditEvents(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.audit")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminAuditEvents"] = true
+
+	page := ctx.QueryInt("page")
+	if page <= 1 {
+		page = 1
+	}
+
+	opts := &models.FindAuditEventsOptions{
+		Page:     page,
+		PageSize: setting.UI.Admin.NoticePagingNum,
+	}
+	total, err := models.CountAuditEvents(opts)
+	if err != nil {
+		ctx.ServerError("CountAuditEvents", err)
+		return
+	}
+	events, err := models.FindAuditEvents(opts)
+	if err != nil {
+		ctx.ServerError("FindAuditEvents", err)
+		return
+	}
+	ctx.Data["AuditEvents"] = events
+	ctx.Data["Total"] = total
+	ctx.Data["Page"] = context.NewPagination(int(total), setting.UI.Admin.NoticePagingNum, page, 5)
+
+	ctx.HTML(200, tplAuditEvents)
+}
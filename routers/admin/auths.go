@@ -6,6 +6,7 @@ package admin
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/masoodkamyab/gitea/models"
 	"github.com/masoodkamyab/gitea/modules/auth"
@@ -21,9 +22,10 @@ import (
 )
 
 const (
-	tplAuths    base.TplName = "admin/auth/list"
-	tplAuthNew  base.TplName = "admin/auth/new"
-	tplAuthEdit base.TplName = "admin/auth/edit"
+	tplAuths                base.TplName = "admin/auth/list"
+	tplAuthNew              base.TplName = "admin/auth/new"
+	tplAuthEdit             base.TplName = "admin/auth/edit"
+	tplAuthGroupSyncPreview base.TplName = "admin/auth/group_sync_preview"
 )
 
 // Authentications show authentication config page
@@ -55,6 +57,7 @@ var (
 		{models.LoginNames[models.LoginSMTP], models.LoginSMTP},
 		{models.LoginNames[models.LoginPAM], models.LoginPAM},
 		{models.LoginNames[models.LoginOAuth2], models.LoginOAuth2},
+		{models.LoginNames[models.LoginSAML], models.LoginSAML},
 	}
 	securityProtocols = []dropdownItem{
 		{models.SecurityProtocolNames[ldap.SecurityProtocolUnencrypted], ldap.SecurityProtocolUnencrypted},
@@ -115,8 +118,15 @@ func parseLDAPConfig(form auth.AuthenticationForm) *models.LDAPConfig {
 			SearchPageSize:        pageSize,
 			Filter:                form.Filter,
 			AdminFilter:           form.AdminFilter,
+			AttributeMemberOf:     form.AttributeMemberOf,
+			GroupSearchBase:       form.GroupSearchBase,
+			GroupFilter:           form.GroupFilter,
+			GroupMemberAttribute:  form.GroupMemberAttribute,
+			GroupMemberUID:        form.GroupMemberUID,
 			Enabled:               true,
 		},
+		GroupTeamMap:        form.GroupTeamMap,
+		GroupTeamMapRemoval: form.GroupTeamMapRemoval,
 	}
 }
 
@@ -143,12 +153,41 @@ func parseOAuth2Config(form auth.AuthenticationForm) *models.OAuth2Config {
 	} else {
 		customURLMapping = nil
 	}
+	var claimMapping *oauth2.ClaimMapping
+	if len(form.Oauth2UsernameClaim) > 0 || len(form.Oauth2EmailClaim) > 0 || len(form.Oauth2FullNameClaim) > 0 {
+		claimMapping = &oauth2.ClaimMapping{
+			Username: form.Oauth2UsernameClaim,
+			Email:    form.Oauth2EmailClaim,
+			FullName: form.Oauth2FullNameClaim,
+		}
+	}
 	return &models.OAuth2Config{
 		Provider:                      form.Oauth2Provider,
 		ClientID:                      form.Oauth2Key,
 		ClientSecret:                  form.Oauth2Secret,
 		OpenIDConnectAutoDiscoveryURL: form.OpenIDConnectAutoDiscoveryURL,
 		CustomURLMapping:              customURLMapping,
+		Scopes:                        strings.Fields(form.Oauth2Scopes),
+		ClaimMapping:                  claimMapping,
+		GroupClaimName:                form.Oauth2GroupClaimName,
+		AdminGroup:                    form.Oauth2AdminGroup,
+		GroupTeamMap:                  form.Oauth2GroupTeamMap,
+		GroupTeamMapRemoval:           form.Oauth2GroupTeamMapRemoval,
+	}
+}
+
+func parseSAMLConfig(form auth.AuthenticationForm) *models.SAMLConfig {
+	return &models.SAMLConfig{
+		IdentityProviderMetadataURL: form.SAMLMetadataURL,
+		IdentityProviderMetadata:    strings.TrimSpace(form.SAMLMetadata),
+		UsernameAttribute:           form.SAMLUsernameAttribute,
+		EmailAttribute:              form.SAMLEmailAttribute,
+		FullNameAttribute:           form.SAMLFullNameAttribute,
+		GroupAttribute:              form.SAMLGroupAttribute,
+		AdminGroup:                  form.SAMLAdminGroup,
+		GroupTeamMap:                form.SAMLGroupTeamMap,
+		GroupTeamMapRemoval:         form.SAMLGroupTeamMapRemoval,
+		EnableAutoRegister:          form.SAMLEnableAutoRegister,
 	}
 }
 
@@ -181,6 +220,8 @@ func NewAuthSourcePost(ctx *context.Context, form auth.AuthenticationForm) {
 		}
 	case models.LoginOAuth2:
 		config = parseOAuth2Config(form)
+	case models.LoginSAML:
+		config = parseSAMLConfig(form)
 	default:
 		ctx.Error(400)
 		return
@@ -192,6 +233,22 @@ func NewAuthSourcePost(ctx *context.Context, form auth.AuthenticationForm) {
 		return
 	}
 
+	if _, err := models.ParseGroupTeamMap(form.Oauth2GroupTeamMap); err != nil {
+		ctx.Data["Err_Oauth2GroupTeamMap"] = true
+		ctx.RenderWithErr(ctx.Tr("admin.auths.oauth2_group_team_map_invalid", err), tplAuthNew, form)
+		return
+	}
+	if _, err := models.ParseGroupTeamMap(form.SAMLGroupTeamMap); err != nil {
+		ctx.Data["Err_SAMLGroupTeamMap"] = true
+		ctx.RenderWithErr(ctx.Tr("admin.auths.oauth2_group_team_map_invalid", err), tplAuthNew, form)
+		return
+	}
+	if _, err := models.ParseGroupTeamMap(form.GroupTeamMap); err != nil {
+		ctx.Data["Err_GroupTeamMap"] = true
+		ctx.RenderWithErr(ctx.Tr("admin.auths.oauth2_group_team_map_invalid", err), tplAuthNew, form)
+		return
+	}
+
 	if err := models.CreateLoginSource(&models.LoginSource{
 		Type:          models.LoginType(form.Type),
 		Name:          form.Name,
@@ -202,6 +259,9 @@ func NewAuthSourcePost(ctx *context.Context, form auth.AuthenticationForm) {
 		if models.IsErrLoginSourceAlreadyExist(err) {
 			ctx.Data["Err_Name"] = true
 			ctx.RenderWithErr(ctx.Tr("admin.auths.login_source_exist", err.(models.ErrLoginSourceAlreadyExist).Name), tplAuthNew, form)
+		} else if models.IsErrSAMLInitialize(err) {
+			ctx.Data["Err_SAMLMetadata"] = true
+			ctx.RenderWithErr(ctx.Tr("admin.auths.saml_metadata_invalid", err.(models.ErrSAMLInitialize).Cause), tplAuthNew, form)
 		} else {
 			ctx.ServerError("CreateSource", err)
 		}
@@ -274,11 +334,29 @@ func EditAuthSourcePost(ctx *context.Context, form auth.AuthenticationForm) {
 		}
 	case models.LoginOAuth2:
 		config = parseOAuth2Config(form)
+	case models.LoginSAML:
+		config = parseSAMLConfig(form)
 	default:
 		ctx.Error(400)
 		return
 	}
 
+	if _, err := models.ParseGroupTeamMap(form.Oauth2GroupTeamMap); err != nil {
+		ctx.Data["Err_Oauth2GroupTeamMap"] = true
+		ctx.RenderWithErr(ctx.Tr("admin.auths.oauth2_group_team_map_invalid", err), tplAuthEdit, form)
+		return
+	}
+	if _, err := models.ParseGroupTeamMap(form.SAMLGroupTeamMap); err != nil {
+		ctx.Data["Err_SAMLGroupTeamMap"] = true
+		ctx.RenderWithErr(ctx.Tr("admin.auths.oauth2_group_team_map_invalid", err), tplAuthEdit, form)
+		return
+	}
+	if _, err := models.ParseGroupTeamMap(form.GroupTeamMap); err != nil {
+		ctx.Data["Err_GroupTeamMap"] = true
+		ctx.RenderWithErr(ctx.Tr("admin.auths.oauth2_group_team_map_invalid", err), tplAuthEdit, form)
+		return
+	}
+
 	source.Name = form.Name
 	source.IsActived = form.IsActive
 	source.IsSyncEnabled = form.IsSyncEnabled
@@ -287,6 +365,9 @@ func EditAuthSourcePost(ctx *context.Context, form auth.AuthenticationForm) {
 		if models.IsErrOpenIDConnectInitialize(err) {
 			ctx.Flash.Error(err.Error(), true)
 			ctx.HTML(200, tplAuthEdit)
+		} else if models.IsErrSAMLInitialize(err) {
+			ctx.Data["Err_SAMLMetadata"] = true
+			ctx.RenderWithErr(ctx.Tr("admin.auths.saml_metadata_invalid", err.(models.ErrSAMLInitialize).Cause), tplAuthEdit, form)
 		} else {
 			ctx.ServerError("UpdateSource", err)
 		}
@@ -298,6 +379,34 @@ func EditAuthSourcePost(ctx *context.Context, form auth.AuthenticationForm) {
 	ctx.Redirect(setting.AppSubURL + "/admin/auths/" + com.ToStr(form.ID))
 }
 
+// GroupSyncPreview render the team memberships the next synchronization of an LDAP source would change
+func GroupSyncPreview(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.auths.group_sync_preview")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminAuthentications"] = true
+
+	source, err := models.GetLoginSourceByID(ctx.ParamsInt64(":authid"))
+	if err != nil {
+		ctx.ServerError("GetLoginSourceByID", err)
+		return
+	}
+	if !source.IsLDAP() {
+		ctx.NotFound("GroupSyncPreview", nil)
+		return
+	}
+	ctx.Data["Source"] = source
+
+	changes, err := models.PreviewLDAPGroupSync(source)
+	if err != nil {
+		ctx.Flash.Error(ctx.Tr("admin.auths.group_sync_preview_failed", err))
+		ctx.Redirect(setting.AppSubURL + "/admin/auths/" + ctx.Params(":authid"))
+		return
+	}
+	ctx.Data["Changes"] = changes
+
+	ctx.HTML(200, tplAuthGroupSyncPreview)
+}
+
 // DeleteAuthSource response for deleting an auth source
 func DeleteAuthSource(ctx *context.Context) {
 	source, err := models.GetLoginSourceByID(ctx.ParamsInt64(":authid"))
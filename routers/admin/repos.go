@@ -42,6 +42,7 @@ func DeleteRepo(ctx *context.Context) {
 		ctx.ServerError("DeleteRepository", err)
 		return
 	}
+	ctx.Audit(models.NewRepoAuditEvent(models.AuditRepoDelete, repo, repo.FullName(), nil))
 	log.Trace("Repository deleted: %s/%s", repo.MustOwner().Name, repo.Name)
 
 	ctx.Flash.Success(ctx.Tr("repo.settings.deletion_success"))
@@ -0,0 +1,67 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"github.com/masoodkamyab/gitea/models"
+	"github.com/masoodkamyab/gitea/modules/base"
+	"github.com/masoodkamyab/gitea/modules/context"
+	"github.com/masoodkamyab/gitea/modules/log"
+	"github.com/masoodkamyab/gitea/modules/setting"
+)
+
+const (
+	tplSessions base.TplName = "admin/sessions"
+)
+
+// Sessions show the active sessions of all users
+func Sessions(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.sessions")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminSessions"] = true
+
+	total := models.CountUserSessions()
+	page := ctx.QueryInt("page")
+	if page <= 1 {
+		page = 1
+	}
+
+	sessions, err := models.UserSessions(page, setting.UI.Admin.UserPagingNum)
+	if err != nil {
+		ctx.ServerError("UserSessions", err)
+		return
+	}
+	ctx.Data["CurrentUserSessionID"] = int64(0)
+	for _, s := range sessions {
+		if s.IsSessionID(ctx.Session.ID()) {
+			ctx.Data["CurrentUserSessionID"] = s.ID
+		}
+	}
+	ctx.Data["UserSessions"] = sessions
+	ctx.Data["Total"] = total
+	ctx.Data["Page"] = context.NewPagination(int(total), setting.UI.Admin.UserPagingNum, page, 5)
+
+	ctx.HTML(200, tplSessions)
+}
+
+// DeleteSession signs a user out of a session
+func DeleteSession(ctx *context.Context) {
+	s, err := models.GetUserSessionByID(ctx.QueryInt64("id"))
+	if err != nil && !models.IsErrUserSessionNotExist(err) {
+		ctx.ServerError("GetUserSessionByID", err)
+		return
+	} else if err == nil {
+		if err = models.DeleteUserSession(s); err != nil {
+			ctx.ServerError("DeleteUserSession", err)
+			return
+		}
+		log.Trace("Session %d of user %d revoked by admin (%s)", s.ID, s.UID, ctx.User.Name)
+		ctx.Flash.Success(ctx.Tr("admin.sessions.revoke_success"))
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": setting.AppSubURL + "/admin/sessions?page=" + ctx.Query("page"),
+	})
+}
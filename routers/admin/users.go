@@ -15,6 +15,7 @@ import (
 	"github.com/masoodkamyab/gitea/modules/context"
 	"github.com/masoodkamyab/gitea/modules/log"
 	"github.com/masoodkamyab/gitea/modules/setting"
+	"github.com/masoodkamyab/gitea/modules/util"
 	"github.com/masoodkamyab/gitea/routers"
 )
 
@@ -30,11 +31,21 @@ func Users(ctx *context.Context) {
 	ctx.Data["PageIsAdmin"] = true
 	ctx.Data["PageIsAdminUsers"] = true
 
-	routers.RenderUserSearch(ctx, &models.SearchUserOptions{
+	opts := &models.SearchUserOptions{
 		Type:          models.UserTypeIndividual,
 		PageSize:      setting.UI.Admin.UserPagingNum,
 		SearchByEmail: true,
-	}, tplUsers)
+	}
+	switch ctx.Query("twofa") {
+	case "enrolled":
+		opts.TwoFactorEnrolled = util.OptionalBoolTrue
+		ctx.Data["TwoFactorFilter"] = "enrolled"
+	case "not_enrolled":
+		opts.TwoFactorEnrolled = util.OptionalBoolFalse
+		ctx.Data["TwoFactorFilter"] = "not_enrolled"
+	}
+
+	routers.RenderUserSearch(ctx, opts, tplUsers)
 }
 
 // NewUser render adding a new user page
@@ -149,6 +160,18 @@ func prepareUserInfo(ctx *context.Context) *models.User {
 	}
 	ctx.Data["Sources"] = sources
 
+	ctx.Data["TwofaRequired"] = setting.TwoFactorRequired
+	ctx.Data["TwofaEnrolled"], err = u.IsTwoFactorEnrolled()
+	if err != nil {
+		ctx.ServerError("IsTwoFactorEnrolled", err)
+		return nil
+	}
+	ctx.Data["WebAuthnCredentials"], err = models.GetWebAuthnCredentialsByUID(u.ID)
+	if err != nil {
+		ctx.ServerError("GetWebAuthnCredentialsByUID", err)
+		return nil
+	}
+
 	return u
 }
 
@@ -210,6 +233,7 @@ func EditUserPost(ctx *context.Context, form auth.AdminEditUserForm) {
 	u.Location = form.Location
 	u.MaxRepoCreation = form.MaxRepoCreation
 	u.IsActive = form.Active
+	wasAdmin := u.IsAdmin
 	u.IsAdmin = form.Admin
 	u.AllowGitHook = form.AllowGitHook
 	u.AllowImportLocal = form.AllowImportLocal
@@ -225,6 +249,17 @@ func EditUserPost(ctx *context.Context, form auth.AdminEditUserForm) {
 		}
 		return
 	}
+	if len(form.Password) > 0 {
+		if err := models.DeleteUserSessionsByUID(u.ID, ctx.Session.ID()); err != nil {
+			ctx.ServerError("DeleteUserSessionsByUID", err)
+			return
+		}
+	}
+	if wasAdmin != u.IsAdmin {
+		ctx.Audit(models.NewSiteAuditEvent(models.AuditUserAdmin, u.Name, models.AuditDiff{
+			"is_admin": {Old: wasAdmin, New: u.IsAdmin},
+		}))
+	}
 	log.Trace("Account profile updated by admin (%s): %s", ctx.User.Name, u.Name)
 
 	ctx.Flash.Success(ctx.Tr("admin.users.update_profile_success"))
@@ -256,6 +291,7 @@ func DeleteUser(ctx *context.Context) {
 		}
 		return
 	}
+	ctx.Audit(models.NewSiteAuditEvent(models.AuditUserDelete, u.Name, nil))
 	log.Trace("Account deleted by admin (%s): %s", ctx.User.Name, u.Name)
 
 	ctx.Flash.Success(ctx.Tr("admin.users.deletion_success"))
@@ -263,3 +299,23 @@ func DeleteUser(ctx *context.Context) {
 		"redirect": setting.AppSubURL + "/admin/users",
 	})
 }
+
+// ResetTwoFactor removes the second factors of a user who lost them
+func ResetTwoFactor(ctx *context.Context) {
+	u, err := models.GetUserByID(ctx.ParamsInt64(":userid"))
+	if err != nil {
+		ctx.ServerError("GetUserByID", err)
+		return
+	}
+
+	if err = models.ResetTwoFactor(u); err != nil {
+		ctx.ServerError("ResetTwoFactor", err)
+		return
+	}
+	log.Trace("Two-factor authentication reset by admin (%s): %s", ctx.User.Name, u.Name)
+
+	ctx.Flash.Success(ctx.Tr("admin.users.reset_twofa_success"))
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": setting.AppSubURL + "/admin/users/" + ctx.Params(":userid"),
+	})
+}
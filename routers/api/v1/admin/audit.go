@@ -0,0 +1,46 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"github.com/masoodkamyab/gitea/modules/context"
+	"github.com/masoodkamyab/gitea/routers/api/v1/utils"
+)
+
+// ListAuditEvents api for exporting the audit log of the whole site
+func ListAuditEvents(ctx *context.APIContext) {
+	// swagger:operation GET /admin/audit admin adminListAuditEvents
+	// ---
+	// summary: List the audit events of administrative and permission changes, most recent first
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: since
+	//   in: query
+	//   description: Only show events recorded after the given time. This is a timestamp in RFC 3339 format
+	//   type: string
+	//   format: date-time
+	// - name: before
+	//   in: query
+	//   description: Only show events recorded before the given time. This is a timestamp in RFC 3339 format
+	//   type: string
+	//   format: date-time
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results, maximum page size is 50
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/AuditEventList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	utils.ListAuditEvents(ctx, 0)
+}
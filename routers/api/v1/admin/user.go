@@ -151,6 +151,7 @@ func EditUser(ctx *context.APIContext, form api.EditUserOption) {
 	if form.Active != nil {
 		u.IsActive = *form.Active
 	}
+	wasAdmin := u.IsAdmin
 	if form.Admin != nil {
 		u.IsAdmin = *form.Admin
 	}
@@ -178,6 +179,17 @@ func EditUser(ctx *context.APIContext, form api.EditUserOption) {
 		}
 		return
 	}
+	if len(form.Password) > 0 {
+		if err := models.DeleteUserSessionsByUID(u.ID, ctx.Session.ID()); err != nil {
+			ctx.Error(500, "DeleteUserSessionsByUID", err)
+			return
+		}
+	}
+	if wasAdmin != u.IsAdmin {
+		ctx.Audit(models.NewSiteAuditEvent(models.AuditUserAdmin, u.Name, models.AuditDiff{
+			"is_admin": {Old: wasAdmin, New: u.IsAdmin},
+		}))
+	}
 	log.Trace("Account profile updated by admin (%s): %s", ctx.User.Name, u.Name)
 
 	ctx.JSON(200, convert.ToUser(u, ctx.IsSigned, ctx.User.IsAdmin))
@@ -217,6 +229,7 @@ func DeleteUser(ctx *context.APIContext) {
 		}
 		return
 	}
+	ctx.Audit(models.NewSiteAuditEvent(models.AuditUserDelete, u.Name, nil))
 	log.Trace("Account deleted by admin(%s): %s", ctx.User.Name, u.Name)
 
 	ctx.Status(204)
@@ -58,6 +58,7 @@
 package v1
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/masoodkamyab/gitea/models"
@@ -86,6 +87,10 @@ func sudo() macaron.Handler {
 		}
 
 		if len(sudo) > 0 {
+			if !ctx.HasScope(models.AccessTokenScopeSudo) {
+				ctx.Error(403, "", "token does not have the sudo scope")
+				return
+			}
 			if ctx.IsSigned && ctx.User.IsAdmin {
 				user, err := models.GetUserByName(sudo)
 				if err != nil {
@@ -186,6 +191,31 @@ func reqToken() macaron.Handler {
 	}
 }
 
+// reqScope a request authenticated by an access token should have the scope
+func reqScope(scope models.AccessTokenScope) macaron.Handler {
+	return func(ctx *context.APIContext) {
+		if !ctx.HasScope(scope) {
+			ctx.Error(403, "", fmt.Sprintf("token does not have the %s scope", scope))
+			return
+		}
+	}
+}
+
+// reqMethodScope a request authenticated by an access token should have the read scope to read
+// and the write scope to change something, an empty scope is not required
+func reqMethodScope(readScope, writeScope models.AccessTokenScope) macaron.Handler {
+	return func(ctx *context.APIContext) {
+		scope := writeScope
+		if ctx.Req.Method == "GET" || ctx.Req.Method == "HEAD" {
+			scope = readScope
+		}
+		if len(scope) > 0 && !ctx.HasScope(scope) {
+			ctx.Error(403, "", fmt.Sprintf("token does not have the %s scope", scope))
+			return
+		}
+	}
+}
+
 func reqBasicAuth() macaron.Handler {
 	return func(ctx *context.APIContext) {
 		if !ctx.Context.IsBasicAuth {
@@ -356,6 +386,24 @@ func reqOrgMembership() macaron.Handler {
 	}
 }
 
+// reqOrgProjectsAccess user should have the given access mode to the projects of an organization
+func reqOrgProjectsAccess(mode models.AccessMode) macaron.Handler {
+	return func(ctx *context.APIContext) {
+		userMode, err := models.OrgProjectsAccessMode(ctx.Org.Organization, ctx.User)
+		if err != nil {
+			ctx.Error(500, "OrgProjectsAccessMode", err)
+			return
+		}
+		if userMode < models.AccessModeRead {
+			ctx.NotFound()
+			return
+		} else if userMode < mode {
+			ctx.Error(403, "", "Must have write access to the projects of the organization")
+			return
+		}
+	}
+}
+
 func reqGitHook() macaron.Handler {
 	return func(ctx *context.APIContext) {
 		if !ctx.User.CanEditGitHook() {
@@ -365,6 +413,42 @@ func reqGitHook() macaron.Handler {
 	}
 }
 
+// projectAssignment assigns the project given by :id if the user may read it
+func projectAssignment() macaron.Handler {
+	return func(ctx *context.APIContext) {
+		p, err := models.GetProjectByID(ctx.ParamsInt64(":id"))
+		if err != nil {
+			if models.IsErrProjectNotExist(err) {
+				ctx.NotFound()
+			} else {
+				ctx.Error(500, "GetProjectByID", err)
+			}
+			return
+		}
+
+		mode, err := p.UserAccessMode(ctx.User)
+		if err != nil {
+			ctx.Error(500, "UserAccessMode", err)
+			return
+		} else if mode < models.AccessModeRead {
+			ctx.NotFound()
+			return
+		}
+		ctx.Data["ProjectAccessMode"] = mode
+		ctx.Map(p)
+	}
+}
+
+// reqProjectWriter user should have write access to the assigned project
+func reqProjectWriter() macaron.Handler {
+	return func(ctx *context.APIContext) {
+		if mode, _ := ctx.Data["ProjectAccessMode"].(models.AccessMode); mode < models.AccessModeWrite {
+			ctx.Error(403, "", "Must have write access to the project")
+			return
+		}
+	}
+}
+
 func orgAssignment(args ...bool) macaron.Handler {
 	var (
 		assignOrg  bool
@@ -529,7 +613,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 					m.Combo("").Get(user.ListAccessTokens).
 						Post(bind(api.CreateAccessTokenOption{}), user.CreateAccessToken)
 					m.Combo("/:id").Delete(user.DeleteAccessToken)
-				}, reqBasicAuth())
+				}, reqBasicAuth(), reqScope(models.AccessTokenScopeUser))
 			})
 		})
 
@@ -552,31 +636,31 @@ func RegisterRoutes(m *macaron.Macaron) {
 
 		m.Group("/user", func() {
 			m.Get("", user.GetAuthenticatedUser)
-			m.Combo("/emails").Get(user.ListEmails).
+			m.Combo("/emails", reqScope(models.AccessTokenScopeUser)).Get(user.ListEmails).
 				Post(bind(api.CreateEmailOption{}), user.AddEmail).
 				Delete(bind(api.DeleteEmailOption{}), user.DeleteEmail)
 
-			m.Get("/followers", user.ListMyFollowers)
+			m.Get("/followers", reqScope(models.AccessTokenScopeUser), user.ListMyFollowers)
 			m.Group("/following", func() {
 				m.Get("", user.ListMyFollowing)
 				m.Combo("/:username").Get(user.CheckMyFollowing).Put(user.Follow).Delete(user.Unfollow)
-			})
+			}, reqScope(models.AccessTokenScopeUser))
 
 			m.Group("/keys", func() {
 				m.Combo("").Get(user.ListMyPublicKeys).
 					Post(bind(api.CreateKeyOption{}), user.CreatePublicKey)
 				m.Combo("/:id").Get(user.GetPublicKey).
 					Delete(user.DeletePublicKey)
-			})
+			}, reqScope(models.AccessTokenScopeUser))
 
 			m.Group("/gpg_keys", func() {
 				m.Combo("").Get(user.ListMyGPGKeys).
 					Post(bind(api.CreateGPGKeyOption{}), user.CreateGPGKey)
 				m.Combo("/:id").Get(user.GetGPGKey).
 					Delete(user.DeleteGPGKey)
-			})
+			}, reqScope(models.AccessTokenScopeUser))
 
-			m.Combo("/repos").Get(user.ListMyRepos).
+			m.Combo("/repos", reqMethodScope(models.AccessTokenScopeRepoRead, models.AccessTokenScopeRepoWrite)).Get(user.ListMyRepos).
 				Post(bind(api.CreateRepoOption{}), repo.Create)
 
 			m.Group("/starred", func() {
@@ -586,25 +670,34 @@ func RegisterRoutes(m *macaron.Macaron) {
 					m.Put("", user.Star)
 					m.Delete("", user.Unstar)
 				}, repoAssignment())
-			})
-			m.Get("/times", repo.ListMyTrackedTimes)
+			}, reqScope(models.AccessTokenScopeUser))
+			m.Get("/times", reqScope(models.AccessTokenScopeRepoRead), repo.ListMyTrackedTimes)
+
+			m.Get("/subscriptions", reqScope(models.AccessTokenScopeUser), user.GetMyWatchedRepos)
 
-			m.Get("/subscriptions", user.GetMyWatchedRepos)
+			m.Get("/teams", reqScope(models.AccessTokenScopeUser), org.ListUserTeams)
 
-			m.Get("/teams", org.ListUserTeams)
+			m.Group("/filters", func() {
+				m.Combo("").Get(user.ListMySavedFilters).
+					Post(bind(api.CreateSavedFilterOption{}), user.CreateSavedFilter)
+				m.Combo("/:id").Get(user.GetSavedFilter).
+					Patch(bind(api.EditSavedFilterOption{}), user.EditSavedFilter).
+					Delete(user.DeleteSavedFilter)
+			}, reqScope(models.AccessTokenScopeUser))
 		}, reqToken())
 
 		// Repositories
-		m.Post("/org/:org/repos", reqToken(), bind(api.CreateRepoOption{}), repo.CreateOrgRepo)
+		m.Post("/org/:org/repos", reqToken(), reqScope(models.AccessTokenScopeRepoWrite), bind(api.CreateRepoOption{}), repo.CreateOrgRepo)
 
 		m.Group("/repos", func() {
 			m.Get("/search", repo.Search)
-		})
+			m.Get("/issues/search", repo.SearchIssues)
+		}, reqScope(models.AccessTokenScopeRepoRead))
 
-		m.Combo("/repositories/:id", reqToken()).Get(repo.GetByID)
+		m.Combo("/repositories/:id", reqToken(), reqScope(models.AccessTokenScopeRepoRead)).Get(repo.GetByID)
 
 		m.Group("/repos", func() {
-			m.Post("/migrate", reqToken(), bind(auth.MigrateRepoForm{}), repo.Migrate)
+			m.Post("/migrate", reqToken(), reqScope(models.AccessTokenScopeRepoWrite), bind(auth.MigrateRepoForm{}), repo.Migrate)
 
 			m.Group("/:username/:reponame", func() {
 				m.Combo("").Get(reqAnyRepoReader(), repo.Get).
@@ -651,13 +744,18 @@ func RegisterRoutes(m *macaron.Macaron) {
 					m.Combo("/:id").Get(repo.GetDeployKey).
 						Delete(repo.DeleteDeploykey)
 				}, reqToken(), reqAdmin())
+			}, repoAssignment(), reqMethodScope(models.AccessTokenScopeRepoRead, models.AccessTokenScopeRepoWrite))
+
+			// Issues and their metadata can also be changed with the issue scope
+			m.Group("/:username/:reponame", func() {
 				m.Group("/times", func() {
 					m.Combo("").Get(repo.ListTrackedTimesByRepository)
 					m.Combo("/:timetrackingusername").Get(repo.ListTrackedTimesByUser)
 				}, mustEnableIssues)
 				m.Group("/issues", func() {
 					m.Combo("").Get(repo.ListIssues).
-						Post(reqToken(), mustNotBeArchived, bind(api.CreateIssueOption{}), repo.CreateIssue)
+						Post(reqToken(), mustNotBeArchived, bind(api.CreateIssueOption{}), repo.CreateIssue).
+						Patch(reqToken(), mustNotBeArchived, bind(api.EditIssuesOption{}), repo.BulkEditIssues)
 					m.Group("/comments", func() {
 						m.Get("", repo.ListRepoIssueComments)
 						m.Combo("/:id", reqToken()).
@@ -686,6 +784,8 @@ func RegisterRoutes(m *macaron.Macaron) {
 						m.Group("/times", func() {
 							m.Combo("").Get(repo.ListTrackedTimes).
 								Post(reqToken(), bind(api.AddTimeOption{}), repo.AddTime)
+							m.Combo("/:id", reqToken(), mustNotBeArchived).Patch(bind(api.EditTimeOption{}), repo.EditTrackedTime).
+								Delete(repo.DeleteTrackedTime)
 						})
 
 						m.Combo("/deadline").Post(reqToken(), bind(api.EditDeadlineOption{}), repo.UpdateIssueDeadline)
@@ -693,6 +793,8 @@ func RegisterRoutes(m *macaron.Macaron) {
 							m.Post("/start", reqToken(), repo.StartIssueStopwatch)
 							m.Post("/stop", reqToken(), repo.StopIssueStopwatch)
 						})
+						m.Post("/transfer", reqToken(), mustNotBeArchived, reqRepoWriter(models.UnitTypeIssues), bind(api.TransferIssueOption{}), repo.TransferIssue)
+						m.Put("/fields/:id", reqToken(), mustNotBeArchived, bind(api.SetIssueFieldValueOption{}), repo.SetIssueFieldValue)
 					})
 				}, mustEnableIssuesOrPulls)
 				m.Group("/labels", func() {
@@ -702,6 +804,13 @@ func RegisterRoutes(m *macaron.Macaron) {
 						Patch(reqToken(), reqRepoWriter(models.UnitTypeIssues, models.UnitTypePullRequests), bind(api.EditLabelOption{}), repo.EditLabel).
 						Delete(reqToken(), reqRepoWriter(models.UnitTypeIssues, models.UnitTypePullRequests), repo.DeleteLabel)
 				})
+				m.Group("/issue_fields", func() {
+					m.Combo("").Get(repo.ListIssueFields).
+						Post(reqToken(), reqAdmin(), bind(api.CreateIssueFieldOption{}), repo.CreateIssueField)
+					m.Combo("/:id").Get(repo.GetIssueField).
+						Patch(reqToken(), reqAdmin(), bind(api.EditIssueFieldOption{}), repo.EditIssueField).
+						Delete(reqToken(), reqAdmin(), repo.DeleteIssueField)
+				}, mustEnableIssuesOrPulls)
 				m.Post("/markdown", bind(api.MarkdownOption{}), misc.Markdown)
 				m.Post("/markdown/raw", misc.MarkdownRaw)
 				m.Group("/milestones", func() {
@@ -710,7 +819,15 @@ func RegisterRoutes(m *macaron.Macaron) {
 					m.Combo("/:id").Get(repo.GetMilestone).
 						Patch(reqToken(), reqRepoWriter(models.UnitTypeIssues, models.UnitTypePullRequests), bind(api.EditMilestoneOption{}), repo.EditMilestone).
 						Delete(reqToken(), reqRepoWriter(models.UnitTypeIssues, models.UnitTypePullRequests), repo.DeleteMilestone)
+					m.Get("/:id/burndown", repo.GetMilestoneBurndown)
 				})
+				m.Get("/issue_templates", reqRepoReader(models.UnitTypeIssues), context.ReferencesGitRepo(false), repo.GetIssueTemplates)
+				m.Get("/pull_request_templates", mustAllowPulls, context.ReferencesGitRepo(false), repo.GetPullRequestTemplates)
+				m.Combo("/projects", reqRepoReader(models.UnitTypeProjects)).Get(repo.ListProjects).
+					Post(reqToken(), mustNotBeArchived, reqRepoWriter(models.UnitTypeProjects), bind(api.CreateProjectOption{}), repo.CreateProject)
+			}, repoAssignment(), reqMethodScope(models.AccessTokenScopeRepoRead, models.AccessTokenScopeIssue))
+
+			m.Group("/:username/:reponame", func() {
 				m.Get("/stargazers", repo.ListStargazers)
 				m.Get("/subscribers", repo.ListSubscribers)
 				m.Group("/subscription", func() {
@@ -744,6 +861,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 							Patch(reqToken(), reqRepoWriter(models.UnitTypePullRequests), bind(api.EditPullRequestOption{}), repo.EditPullRequest)
 						m.Combo("/merge").Get(repo.IsPullRequestMerged).
 							Post(reqToken(), mustNotBeArchived, reqRepoWriter(models.UnitTypePullRequests), bind(auth.MergePullRequestForm{}), repo.MergePullRequest)
+						m.Post("/update", reqToken(), mustNotBeArchived, repo.UpdatePullRequest)
 					})
 				}, mustAllowPulls, reqRepoReader(models.UnitTypeCode), context.ReferencesGitRepo(false))
 				m.Group("/statuses", func() {
@@ -774,13 +892,13 @@ func RegisterRoutes(m *macaron.Macaron) {
 						m.Delete("", bind(api.DeleteFileOptions{}), repo.DeleteFile)
 					}, reqRepoWriter(models.UnitTypeCode), reqToken())
 				}, reqRepoReader(models.UnitTypeCode))
-			}, repoAssignment())
+			}, repoAssignment(), reqMethodScope(models.AccessTokenScopeRepoRead, models.AccessTokenScopeRepoWrite))
 		})
 
 		// Organizations
 		m.Get("/user/orgs", reqToken(), org.ListMyOrgs)
 		m.Get("/users/:username/orgs", org.ListUserOrgs)
-		m.Post("/orgs", reqToken(), bind(api.CreateOrgOption{}), org.Create)
+		m.Post("/orgs", reqToken(), reqScope(models.AccessTokenScopeAdminOrg), bind(api.CreateOrgOption{}), org.Create)
 		m.Group("/orgs/:orgname", func() {
 			m.Get("/repos", user.ListOrgRepos)
 			m.Combo("").Get(org.Get).
@@ -799,6 +917,17 @@ func RegisterRoutes(m *macaron.Macaron) {
 			})
 			m.Combo("/teams", reqToken(), reqOrgMembership()).Get(org.ListTeams).
 				Post(reqOrgOwnership(), bind(api.CreateTeamOption{}), org.CreateTeam)
+			m.Combo("/projects", reqToken(), reqOrgProjectsAccess(models.AccessModeRead)).Get(org.ListProjects).
+				Post(reqOrgProjectsAccess(models.AccessModeWrite), bind(api.CreateProjectOption{}), org.CreateProject)
+			m.Get("/times", reqToken(), org.ListTrackedTimes)
+			m.Get("/audit", reqToken(), reqScope(models.AccessTokenScopeAdminOrg), reqOrgOwnership(), org.ListAuditEvents)
+			m.Group("/issue_fields", func() {
+				m.Combo("").Get(org.ListIssueFields).
+					Post(reqToken(), reqOrgOwnership(), bind(api.CreateIssueFieldOption{}), org.CreateIssueField)
+				m.Combo("/:id", reqToken(), reqOrgOwnership()).
+					Patch(bind(api.EditIssueFieldOption{}), org.EditIssueField).
+					Delete(org.DeleteIssueField)
+			})
 			m.Group("/hooks", func() {
 				m.Combo("").Get(org.ListHooks).
 					Post(bind(api.CreateHookOption{}), org.CreateHook)
@@ -806,7 +935,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 					Patch(bind(api.EditHookOption{}), org.EditHook).
 					Delete(org.DeleteHook)
 			}, reqToken(), reqOrgOwnership())
-		}, orgAssignment(true))
+		}, orgAssignment(true), reqMethodScope("", models.AccessTokenScopeAdminOrg))
 		m.Group("/teams/:teamid", func() {
 			m.Combo("").Get(org.GetTeam).
 				Patch(reqOrgOwnership(), bind(api.EditTeamOption{}), org.EditTeam).
@@ -824,7 +953,27 @@ func RegisterRoutes(m *macaron.Macaron) {
 					Put(org.AddTeamRepository).
 					Delete(org.RemoveTeamRepository)
 			})
-		}, orgAssignment(false, true), reqToken(), reqTeamMembership())
+		}, orgAssignment(false, true), reqToken(), reqTeamMembership(), reqMethodScope("", models.AccessTokenScopeAdminOrg))
+
+		m.Group("/projects/:id", func() {
+			m.Combo("").Get(repo.GetProject).
+				Patch(reqToken(), reqProjectWriter(), bind(api.EditProjectOption{}), repo.EditProject).
+				Delete(reqToken(), reqProjectWriter(), repo.DeleteProject)
+			m.Group("/boards", func() {
+				m.Combo("").Get(repo.ListProjectBoards).
+					Post(reqToken(), reqProjectWriter(), bind(api.CreateProjectBoardOption{}), repo.CreateProjectBoard)
+				m.Combo("/:boardid").
+					Patch(reqToken(), reqProjectWriter(), bind(api.EditProjectBoardOption{}), repo.EditProjectBoard).
+					Delete(reqToken(), reqProjectWriter(), repo.DeleteProjectBoard)
+			})
+			m.Group("/issues", func() {
+				m.Combo("").Get(repo.ListProjectIssues).
+					Post(reqToken(), reqProjectWriter(), bind(api.AddProjectIssueOption{}), repo.AddProjectIssue)
+				m.Combo("/:issueid").
+					Patch(reqToken(), reqProjectWriter(), bind(api.MoveProjectIssueOption{}), repo.MoveProjectIssue).
+					Delete(reqToken(), reqProjectWriter(), repo.RemoveProjectIssue)
+			})
+		}, projectAssignment(), reqMethodScope(models.AccessTokenScopeRepoRead, models.AccessTokenScopeIssue))
 
 		m.Any("/*", func(ctx *context.APIContext) {
 			ctx.NotFound()
@@ -832,6 +981,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 
 		m.Group("/admin", func() {
 			m.Get("/orgs", admin.GetAllOrgs)
+			m.Get("/audit", admin.ListAuditEvents)
 			m.Group("/users", func() {
 				m.Get("", admin.GetAllUsers)
 				m.Post("", bind(api.CreateUserOption{}), admin.CreateUser)
@@ -847,7 +997,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 					m.Post("/repos", bind(api.CreateRepoOption{}), admin.CreateRepo)
 				})
 			})
-		}, reqToken(), reqSiteAdmin())
+		}, reqToken(), reqSiteAdmin(), reqScope(models.AccessTokenScopeSudo))
 
 		m.Group("/topics", func() {
 			m.Get("/search", repo.TopicSearch)
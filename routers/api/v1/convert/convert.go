@@ -203,6 +203,23 @@ func ToDeployKey(apiLink string, key *models.DeployKey) *api.DeployKey {
 	}
 }
 
+// ToAccessToken convert models.AccessToken to api.AccessToken
+func ToAccessToken(t *models.AccessToken) *api.AccessToken {
+	apiToken := &api.AccessToken{
+		ID:             t.ID,
+		Name:           t.Name,
+		Token:          t.Token,
+		TokenLastEight: t.TokenLastEight,
+	}
+	for _, scope := range t.Scope.List() {
+		apiToken.Scopes = append(apiToken.Scopes, string(scope))
+	}
+	if t.ExpiresUnix > 0 {
+		apiToken.ExpiresAt = t.ExpiresUnix.AsTimePtr()
+	}
+	return apiToken
+}
+
 // ToOrganization convert models.User to api.Organization
 func ToOrganization(org *models.User) *api.Organization {
 	return &api.Organization{
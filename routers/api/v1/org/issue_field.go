@@ -0,0 +1,137 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	api "github.com/masoodkamyab/gitea/modules/structs"
+
+	"github.com/masoodkamyab/gitea/models"
+	"github.com/masoodkamyab/gitea/modules/context"
+	"github.com/masoodkamyab/gitea/routers/api/v1/utils"
+)
+
+// ListIssueFields list the custom issue fields of an organization
+func ListIssueFields(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/issue_fields organization orgListIssueFields
+	// ---
+	// summary: List the custom issue fields of an organization
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/IssueFieldList"
+	fields, err := models.GetIssueFieldsByOwnerID(ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.Error(500, "GetIssueFieldsByOwnerID", err)
+		return
+	}
+
+	apiFields := make([]*api.IssueField, len(fields))
+	for i := range fields {
+		apiFields[i] = fields[i].APIFormat()
+	}
+	ctx.JSON(200, &apiFields)
+}
+
+// CreateIssueField create a custom issue field for all repositories of an organization
+func CreateIssueField(ctx *context.APIContext, form api.CreateIssueFieldOption) {
+	// swagger:operation POST /orgs/{org}/issue_fields organization orgCreateIssueField
+	// ---
+	// summary: Create a custom issue field for all repositories of an organization
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateIssueFieldOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/IssueField"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	utils.AddIssueField(ctx, &form, ctx.Org.Organization.ID, 0)
+}
+
+// EditIssueField modify a custom issue field of an organization
+func EditIssueField(ctx *context.APIContext, form api.EditIssueFieldOption) {
+	// swagger:operation PATCH /orgs/{org}/issue_fields/{id} organization orgEditIssueField
+	// ---
+	// summary: Update a custom issue field of an organization
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the field to edit
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditIssueFieldOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/IssueField"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	field := utils.GetIssueField(ctx, ctx.Org.Organization.ID, 0, ctx.ParamsInt64(":id"))
+	if ctx.Written() {
+		return
+	}
+	utils.EditIssueField(ctx, &form, field)
+}
+
+// DeleteIssueField delete a custom issue field of an organization with its values
+func DeleteIssueField(ctx *context.APIContext) {
+	// swagger:operation DELETE /orgs/{org}/issue_fields/{id} organization orgDeleteIssueField
+	// ---
+	// summary: Delete a custom issue field of an organization and its values
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the field to delete
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	field := utils.GetIssueField(ctx, ctx.Org.Organization.ID, 0, ctx.ParamsInt64(":id"))
+	if ctx.Written() {
+		return
+	}
+	utils.DeleteIssueField(ctx, field)
+}
@@ -0,0 +1,83 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	api "github.com/masoodkamyab/gitea/modules/structs"
+
+	"github.com/masoodkamyab/gitea/models"
+	"github.com/masoodkamyab/gitea/modules/context"
+	"github.com/masoodkamyab/gitea/routers/api/v1/utils"
+)
+
+// ListTrackedTimes lists the tracked times of the repositories of an organization
+func ListTrackedTimes(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/times organization orgTrackedTimes
+	// ---
+	// summary: List the tracked times of the repositories of an organization the user can see
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: user
+	//   in: query
+	//   description: optional filter by user
+	//   type: string
+	// - name: since
+	//   in: query
+	//   description: Only show times tracked after the given time. This is a timestamp in RFC 3339 format
+	//   type: string
+	//   format: date-time
+	// - name: before
+	//   in: query
+	//   description: Only show times tracked before the given time. This is a timestamp in RFC 3339 format
+	//   type: string
+	//   format: date-time
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/TrackedTimeList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	repoIDs, err := models.GetTimetrackerRepoIDs(ctx.Org.Organization, ctx.User)
+	if err != nil {
+		ctx.Error(500, "GetTimetrackerRepoIDs", err)
+		return
+	}
+	opts := models.FindTrackedTimesOptions{RepositoryIDs: repoIDs}
+
+	if userName := ctx.Query("user"); len(userName) > 0 {
+		user, err := models.GetUserByName(userName)
+		if err != nil {
+			if models.IsErrUserNotExist(err) {
+				ctx.NotFound(err)
+			} else {
+				ctx.Error(500, "GetUserByName", err)
+			}
+			return
+		}
+		opts.UserID = user.ID
+	}
+	if opts.CreatedBeforeUnix, opts.CreatedAfterUnix, err = utils.GetQueryBeforeSince(ctx); err != nil {
+		ctx.Error(422, "GetQueryBeforeSince", err)
+		return
+	}
+
+	trackedTimes, err := models.GetTrackedTimes(opts)
+	if err != nil {
+		ctx.Error(500, "GetTrackedTimes", err)
+		return
+	}
+	apiTrackedTimes := make([]*api.TrackedTime, len(trackedTimes))
+	for i, trackedTime := range trackedTimes {
+		apiTrackedTimes[i] = trackedTime.APIFormat()
+	}
+	ctx.JSON(200, &apiTrackedTimes)
+}
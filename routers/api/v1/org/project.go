@@ -0,0 +1,71 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	api "github.com/masoodkamyab/gitea/modules/structs"
+
+	"github.com/masoodkamyab/gitea/models"
+	"github.com/masoodkamyab/gitea/modules/context"
+	"github.com/masoodkamyab/gitea/routers/api/v1/repo"
+)
+
+// ListProjects list an organization's projects
+func ListProjects(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/projects project projectListOrgProjects
+	// ---
+	// summary: List an organization's projects
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: state
+	//   in: query
+	//   description: Project state, Recognised values are open, closed and all. Defaults to "open"
+	//   type: string
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ProjectList"
+	repo.ListProjectsByOptions(ctx, &models.ProjectSearchOptions{
+		OwnerID: ctx.Org.Organization.ID,
+	})
+}
+
+// CreateProject create a project for an organization
+func CreateProject(ctx *context.APIContext, form api.CreateProjectOption) {
+	// swagger:operation POST /orgs/{org}/projects project projectCreateOrgProject
+	// ---
+	// summary: Create a project for an organization
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateProjectOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/Project"
+	repo.CreateProjectByOptions(ctx, &models.Project{
+		OwnerID: ctx.Org.Organization.ID,
+		Owner:   ctx.Org.Organization,
+		Type:    models.ProjectTypeOrganization,
+	}, form)
+}
@@ -153,6 +153,7 @@ func CreateTeam(ctx *context.APIContext, form api.CreateTeamOption) {
 		}
 		return
 	}
+	ctx.Audit(models.NewTeamAuditEvent(models.AuditOrgTeamCreate, team, models.NewAuditDiff(nil, team.AuditFields())))
 
 	ctx.JSON(201, convert.ToTeam(team))
 }
@@ -180,6 +181,7 @@ func EditTeam(ctx *context.APIContext, form api.EditTeamOption) {
 	//   "200":
 	//     "$ref": "#/responses/Team"
 	team := ctx.Org.Team
+	before := team.AuditFields()
 	team.Name = form.Name
 	team.Description = form.Description
 	team.Authorize = models.ParseAccessMode(form.Permission)
@@ -200,6 +202,9 @@ func EditTeam(ctx *context.APIContext, form api.EditTeamOption) {
 		ctx.Error(500, "EditTeam", err)
 		return
 	}
+	if diff := models.NewAuditDiff(before, team.AuditFields()); len(diff) > 0 {
+		ctx.Audit(models.NewTeamAuditEvent(models.AuditOrgTeamUpdate, team, diff))
+	}
 	ctx.JSON(200, convert.ToTeam(team))
 }
 
@@ -218,10 +223,12 @@ func DeleteTeam(ctx *context.APIContext) {
 	// responses:
 	//   "204":
 	//     description: team deleted
+	before := ctx.Org.Team.AuditFields()
 	if err := models.DeleteTeam(ctx.Org.Team); err != nil {
 		ctx.Error(500, "DeleteTeam", err)
 		return
 	}
+	ctx.Audit(models.NewTeamAuditEvent(models.AuditOrgTeamDelete, ctx.Org.Team, models.NewAuditDiff(before, nil)))
 	ctx.Status(204)
 }
 
@@ -317,10 +324,17 @@ func AddTeamMember(ctx *context.APIContext) {
 	if ctx.Written() {
 		return
 	}
+	if ctx.Org.Team.IsMember(u.ID) {
+		ctx.Status(204)
+		return
+	}
 	if err := ctx.Org.Team.AddMember(u.ID); err != nil {
 		ctx.Error(500, "AddMember", err)
 		return
 	}
+	ctx.Audit(models.NewTeamAuditEvent(models.AuditOrgTeamMemberAdd, ctx.Org.Team, models.AuditDiff{
+		"member": {New: u.Name},
+	}))
 	ctx.Status(204)
 }
 
@@ -351,10 +365,17 @@ func RemoveTeamMember(ctx *context.APIContext) {
 		return
 	}
 
+	if !ctx.Org.Team.IsMember(u.ID) {
+		ctx.Status(204)
+		return
+	}
 	if err := ctx.Org.Team.RemoveMember(u.ID); err != nil {
 		ctx.Error(500, "RemoveMember", err)
 		return
 	}
+	ctx.Audit(models.NewTeamAuditEvent(models.AuditOrgTeamMemberRemove, ctx.Org.Team, models.AuditDiff{
+		"member": {Old: u.Name},
+	}))
 	ctx.Status(204)
 }
 
@@ -443,10 +464,17 @@ func AddTeamRepository(ctx *context.APIContext) {
 		ctx.Error(403, "", "Must have admin-level access to the repository")
 		return
 	}
+	if ctx.Org.Team.HasRepository(repo.ID) {
+		ctx.Status(204)
+		return
+	}
 	if err := ctx.Org.Team.AddRepository(repo); err != nil {
 		ctx.Error(500, "AddRepository", err)
 		return
 	}
+	ctx.Audit(models.NewTeamAuditEvent(models.AuditOrgTeamRepoAdd, ctx.Org.Team, models.AuditDiff{
+		"repository": {New: repo.Name},
+	}))
 	ctx.Status(204)
 }
 
@@ -490,9 +518,16 @@ func RemoveTeamRepository(ctx *context.APIContext) {
 		ctx.Error(403, "", "Must have admin-level access to the repository")
 		return
 	}
+	if !ctx.Org.Team.HasRepository(repo.ID) {
+		ctx.Status(204)
+		return
+	}
 	if err := ctx.Org.Team.RemoveRepository(repo.ID); err != nil {
 		ctx.Error(500, "RemoveRepository", err)
 		return
 	}
+	ctx.Audit(models.NewTeamAuditEvent(models.AuditOrgTeamRepoRemove, ctx.Org.Team, models.AuditDiff{
+		"repository": {Old: repo.Name},
+	}))
 	ctx.Status(204)
 }
@@ -142,6 +142,12 @@ func AddCollaborator(ctx *context.APIContext, form api.AddCollaboratorOption) {
 		return
 	}
 
+	before, err := ctx.Repo.Repository.GetCollaboration(collaborator.ID)
+	if err != nil {
+		ctx.Error(500, "GetCollaboration", err)
+		return
+	}
+
 	if err := ctx.Repo.Repository.AddCollaborator(collaborator); err != nil {
 		ctx.Error(500, "AddCollaborator", err)
 		return
@@ -154,6 +160,21 @@ func AddCollaborator(ctx *context.APIContext, form api.AddCollaboratorOption) {
 		}
 	}
 
+	after, err := ctx.Repo.Repository.GetCollaboration(collaborator.ID)
+	if err != nil {
+		ctx.Error(500, "GetCollaboration", err)
+		return
+	}
+	if before == nil {
+		ctx.Audit(models.NewRepoAuditEvent(models.AuditRepoCollaboratorAdd, ctx.Repo.Repository, collaborator.Name, models.AuditDiff{
+			"mode": {New: after.Mode.String()},
+		}))
+	} else if before.Mode != after.Mode {
+		ctx.Audit(models.NewRepoAuditEvent(models.AuditRepoCollaboratorMode, ctx.Repo.Repository, collaborator.Name, models.AuditDiff{
+			"mode": {Old: before.Mode.String(), New: after.Mode.String()},
+		}))
+	}
+
 	ctx.Status(204)
 }
 
@@ -193,9 +214,20 @@ func DeleteCollaborator(ctx *context.APIContext) {
 		return
 	}
 
+	collaboration, err := ctx.Repo.Repository.GetCollaboration(collaborator.ID)
+	if err != nil {
+		ctx.Error(500, "GetCollaboration", err)
+		return
+	}
+
 	if err := ctx.Repo.Repository.DeleteCollaboration(collaborator.ID); err != nil {
 		ctx.Error(500, "DeleteCollaboration", err)
 		return
 	}
+	if collaboration != nil {
+		ctx.Audit(models.NewRepoAuditEvent(models.AuditRepoCollaboratorRemove, ctx.Repo.Repository, collaborator.Name, models.AuditDiff{
+			"mode": {Old: collaboration.Mode.String()},
+		}))
+	}
 	ctx.Status(204)
 }
@@ -21,6 +21,107 @@ import (
 	api "github.com/masoodkamyab/gitea/modules/structs"
 )
 
+// SearchIssues searches for issues across the repositories that the user has access to
+func SearchIssues(ctx *context.APIContext) {
+	// swagger:operation GET /repos/issues/search issue issueSearchIssues
+	// ---
+	// summary: Search for issues across the repositories that the user has access to
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: q
+	//   in: query
+	//   description: search query, e.g. `is:open is:pr label:bug author:@me text`
+	//   type: string
+	// - name: state
+	//   in: query
+	//   description: whether issue is open or closed, overridden by an `is:open` or `is:closed` qualifier
+	//   type: string
+	// - name: page
+	//   in: query
+	//   description: page number of requested issues
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/IssueList"
+	opts := &models.IssuesOptions{
+		Page:     ctx.QueryInt("page"),
+		PageSize: setting.UI.IssuePagingNum,
+	}
+	switch ctx.Query("state") {
+	case "closed":
+		opts.IsClosed = util.OptionalBoolTrue
+	case "all":
+		opts.IsClosed = util.OptionalBoolNone
+	default:
+		opts.IsClosed = util.OptionalBoolFalse
+	}
+
+	// find the repositories the user can see
+	for page := 1; ; page++ {
+		repos, count, err := models.SearchRepositoryByName(&models.SearchRepoOptions{
+			Page:        page,
+			PageSize:    setting.API.MaxResponseItems,
+			Private:     ctx.IsSigned,
+			AllPublic:   true,
+			UserID:      ctx.Data["SignedUserID"].(int64),
+			UserIsAdmin: ctx.IsUserSiteAdmin(),
+			OrderBy:     models.SearchOrderByID,
+		})
+		if err != nil {
+			ctx.Error(500, "SearchRepositoryByName", err)
+			return
+		}
+		for _, repo := range repos {
+			opts.RepoIDs = append(opts.RepoIDs, repo.ID)
+		}
+		if len(repos) == 0 || int64(len(opts.RepoIDs)) >= count {
+			break
+		}
+	}
+
+	keyword := strings.Trim(ctx.Query("q"), " ")
+	if strings.IndexByte(keyword, 0) >= 0 {
+		keyword = ""
+	}
+	query := models.ParseIssueQuery(keyword)
+	matchable, err := query.Apply(opts, ctx.User)
+	if err != nil {
+		ctx.Error(500, "IssueQuery.Apply", err)
+		return
+	}
+	if matchable && len(query.Keyword) > 0 && len(opts.RepoIDs) > 0 {
+		opts.IssueIDs, err = issue_indexer.SearchIssuesByKeyword(opts.RepoIDs, query.Keyword)
+		if err != nil {
+			ctx.Error(500, "SearchIssuesByKeyword", err)
+			return
+		}
+		matchable = len(opts.IssueIDs) > 0
+	}
+
+	var issues []*models.Issue
+	var count int64
+	// an empty repository list would match the issues of all repositories
+	if matchable && len(opts.RepoIDs) > 0 {
+		if count, err = models.CountIssues(opts); err != nil {
+			ctx.Error(500, "CountIssues", err)
+			return
+		}
+		if issues, err = models.Issues(opts); err != nil {
+			ctx.Error(500, "Issues", err)
+			return
+		}
+	}
+
+	apiIssues := make([]*api.Issue, len(issues))
+	for i := range issues {
+		apiIssues[i] = issues[i].APIFormat()
+	}
+
+	ctx.SetLinkHeader(int(count), setting.UI.IssuePagingNum)
+	ctx.JSON(200, &apiIssues)
+}
+
 // ListIssues list the issues of a repository
 func ListIssues(ctx *context.APIContext) {
 	// swagger:operation GET /repos/{owner}/{repo}/issues issue issueListIssues
@@ -78,7 +179,7 @@ func ListIssues(ctx *context.APIContext) {
 	var labelIDs []int64
 	var err error
 	if len(keyword) > 0 {
-		issueIDs, err = issue_indexer.SearchIssuesByKeyword(ctx.Repo.Repository.ID, keyword)
+		issueIDs, err = issue_indexer.SearchIssuesByKeyword([]int64{ctx.Repo.Repository.ID}, keyword)
 	}
 
 	if splitted := strings.Split(ctx.Query("labels"), ","); len(splitted) > 0 {
@@ -143,9 +244,14 @@ func GetIssue(ctx *context.APIContext) {
 	// responses:
 	//   "200":
 	//     "$ref": "#/responses/Issue"
+	//   "301":
+	//     description: The issue was transferred to another repository
 	issue, err := models.GetIssueWithAttrsByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
 	if err != nil {
 		if models.IsErrIssueNotExist(err) {
+			if redirectTransferredIssue(ctx, ctx.ParamsInt64(":index")) {
+				return
+			}
 			ctx.NotFound()
 		} else {
 			ctx.Error(500, "GetIssueByIndex", err)
@@ -280,6 +386,8 @@ func EditIssue(ctx *context.APIContext, form api.EditIssueOption) {
 	// responses:
 	//   "201":
 	//     "$ref": "#/responses/Issue"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
 	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
 	if err != nil {
 		if models.IsErrIssueNotExist(err) {
@@ -320,6 +428,18 @@ func EditIssue(ctx *context.APIContext, form api.EditIssueOption) {
 		return
 	}
 
+	// Update the estimate
+	if form.EstimatedTime != nil && ctx.Repo.Repository.IsTimetrackerEnabled() && ctx.Repo.CanWrite(models.UnitTypeIssues) {
+		if *form.EstimatedTime < 0 {
+			ctx.Error(422, "", "estimated time must not be negative")
+			return
+		}
+		if err := models.UpdateIssueEstimate(issue, *form.EstimatedTime, ctx.User); err != nil {
+			ctx.Error(500, "UpdateIssueEstimate", err)
+			return
+		}
+	}
+
 	// Add/delete assignees
 
 	// Deleting is done the GitHub way (quote from their api documentation):
@@ -0,0 +1,168 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"fmt"
+
+	"github.com/masoodkamyab/gitea/models"
+	"github.com/masoodkamyab/gitea/modules/auth"
+	"github.com/masoodkamyab/gitea/modules/context"
+	"github.com/masoodkamyab/gitea/routers/repo"
+
+	api "github.com/masoodkamyab/gitea/modules/structs"
+)
+
+// getBulkEditUserIDs looks up the IDs of the given user names
+func getBulkEditUserIDs(names []string) ([]int64, error) {
+	ids := make([]int64, 0, len(names))
+	for _, name := range names {
+		u, err := models.GetUserByName(name)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, u.ID)
+	}
+	return ids, nil
+}
+
+// toBulkEditIssuesOptions converts the API options to the options of a bulk edit,
+// it writes the error response and returns nil if the options are invalid
+func toBulkEditIssuesOptions(ctx *context.APIContext, form api.EditIssuesOption) *models.BulkEditIssuesOptions {
+	opts := &models.BulkEditIssuesOptions{
+		AddLabelIDs:     form.AddLabels,
+		RemoveLabelIDs:  form.RemoveLabels,
+		MilestoneID:     form.Milestone,
+		AddProjectID:    form.AddProject,
+		RemoveProjectID: form.RemoveProject,
+		IsLocked:        form.Lock,
+		LockReason:      form.LockReason,
+	}
+
+	var err error
+	if opts.AddAssigneeIDs, err = getBulkEditUserIDs(form.AddAssignees); err != nil {
+		if models.IsErrUserNotExist(err) {
+			ctx.Error(422, "", fmt.Sprintf("assignee does not exist: %v", err))
+		} else {
+			ctx.Error(500, "GetUserByName", err)
+		}
+		return nil
+	}
+	if opts.RemoveAssigneeIDs, err = getBulkEditUserIDs(form.RemoveAssignees); err != nil {
+		if models.IsErrUserNotExist(err) {
+			ctx.Error(422, "", fmt.Sprintf("assignee does not exist: %v", err))
+		} else {
+			ctx.Error(500, "GetUserByName", err)
+		}
+		return nil
+	}
+
+	if form.State != nil {
+		switch api.StateType(*form.State) {
+		case api.StateOpen, api.StateClosed:
+			isClosed := api.StateType(*form.State) == api.StateClosed
+			opts.IsClosed = &isClosed
+		default:
+			ctx.Error(422, "", "state must be open or closed")
+			return nil
+		}
+	}
+	if form.Lock != nil && *form.Lock && !(auth.IssueLockForm{Reason: form.LockReason}).HasValidReason() {
+		ctx.Error(422, "", "unknown lock reason")
+		return nil
+	}
+
+	if form.TransferTo != nil {
+		if opts.TransferTo, err = models.GetRepositoryByOwnerAndName(form.TransferTo.NewOwner, form.TransferTo.NewRepo); err != nil {
+			if models.IsErrRepoNotExist(err) {
+				ctx.Error(422, "", "repository does not exist")
+			} else {
+				ctx.Error(500, "GetRepositoryByOwnerAndName", err)
+			}
+			return nil
+		}
+	}
+	return opts
+}
+
+// BulkEditIssues applies the same changes to many issues of a repository at once
+func BulkEditIssues(ctx *context.APIContext, form api.EditIssuesOption) {
+	// swagger:operation PATCH /repos/{owner}/{repo}/issues issue issueBulkEditIssues
+	// ---
+	// summary: Edit many issues at once. Either all issues are changed or none.
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditIssuesOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/IssueList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	issues := make([]*models.Issue, 0, len(form.Indices))
+	for _, index := range form.Indices {
+		issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, index)
+		if err != nil {
+			if models.IsErrIssueNotExist(err) {
+				ctx.NotFound()
+			} else {
+				ctx.Error(500, "GetIssueByIndex", err)
+			}
+			return
+		}
+		if !ctx.Repo.CanWriteIssuesOrPulls(issue.IsPull) {
+			ctx.NotFound()
+			return
+		}
+		issues = append(issues, issue)
+	}
+
+	opts := toBulkEditIssuesOptions(ctx, form)
+	if ctx.Written() {
+		return
+	}
+
+	changes, err := models.BulkEditIssues(ctx.User, ctx.Repo.Repository, issues, opts)
+	if err != nil {
+		switch {
+		case models.IsErrIssueBulkEditInvalid(err),
+			models.IsErrIssueCannotTransfer(err),
+			models.IsErrDependenciesLeft(err),
+			models.IsErrLabelNotExist(err),
+			models.IsErrMilestoneNotExist(err),
+			models.IsErrUserNotExist(err),
+			models.IsErrProjectNotExist(err),
+			models.IsErrProjectIssueNotAllowed(err):
+			ctx.Error(422, "", err)
+		default:
+			ctx.Error(500, "BulkEditIssues", err)
+		}
+		return
+	}
+	repo.NotifyIssueChanges(ctx.User, changes)
+
+	apiIssues := make([]*api.Issue, len(changes))
+	for i, change := range changes {
+		apiIssues[i] = change.Issue.APIFormat()
+	}
+	ctx.JSON(200, &apiIssues)
+}
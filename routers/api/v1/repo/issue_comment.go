@@ -179,6 +179,10 @@ func CreateIssueComment(ctx *context.APIContext, form api.CreateIssueCommentOpti
 	// responses:
 	//   "201":
 	//     "$ref": "#/responses/Comment"
+	//   "204":
+	//     description: the body only consisted of `/estimate` quick actions, no comment was created
+	//   "422":
+	//     "$ref": "#/responses/validationError"
 	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
 	if err != nil {
 		ctx.Error(500, "GetIssueByIndex", err)
@@ -190,6 +194,30 @@ func CreateIssueComment(ctx *context.APIContext, form api.CreateIssueCommentOpti
 		return
 	}
 
+	// `/estimate 3h` lines of writers set the estimate instead of being posted
+	if ctx.Repo.Repository.IsTimetrackerEnabled() && ctx.Repo.CanWriteIssuesOrPulls(issue.IsPull) {
+		content, estimate, err := models.ExtractEstimateQuickAction(form.Body)
+		if err != nil {
+			if models.IsErrInvalidEstimate(err) {
+				ctx.Error(422, "ExtractEstimateQuickAction", err)
+			} else {
+				ctx.Error(500, "ExtractEstimateQuickAction", err)
+			}
+			return
+		}
+		if estimate >= 0 {
+			if err = models.UpdateIssueEstimate(issue, estimate, ctx.User); err != nil {
+				ctx.Error(500, "UpdateIssueEstimate", err)
+				return
+			}
+			if len(content) == 0 {
+				ctx.Status(204)
+				return
+			}
+			form.Body = content
+		}
+	}
+
 	comment, err := models.CreateIssueComment(ctx.User, ctx.Repo.Repository, issue, form.Body, nil)
 	if err != nil {
 		ctx.Error(500, "CreateIssueComment", err)
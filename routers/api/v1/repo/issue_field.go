@@ -0,0 +1,282 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"github.com/masoodkamyab/gitea/models"
+	"github.com/masoodkamyab/gitea/modules/context"
+	"github.com/masoodkamyab/gitea/routers/api/v1/utils"
+
+	api "github.com/masoodkamyab/gitea/modules/structs"
+)
+
+// ListIssueFields list the custom issue fields of a repository, including those of its organization
+func ListIssueFields(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/issue_fields issue issueListIssueFields
+	// ---
+	// summary: List the custom issue fields available in a repository, including those of its organization
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/IssueFieldList"
+	fields, err := models.GetIssueFieldsOfRepo(ctx.Repo.Repository)
+	if err != nil {
+		ctx.Error(500, "GetIssueFieldsOfRepo", err)
+		return
+	}
+
+	apiFields := make([]*api.IssueField, len(fields))
+	for i := range fields {
+		apiFields[i] = fields[i].APIFormat()
+	}
+	ctx.JSON(200, &apiFields)
+}
+
+// GetIssueField get a custom issue field of a repository
+func GetIssueField(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/issue_fields/{id} issue issueGetIssueField
+	// ---
+	// summary: Get a custom issue field of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the field to get
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/IssueField"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	field := utils.GetIssueField(ctx, 0, ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if ctx.Written() {
+		return
+	}
+	ctx.JSON(200, field.APIFormat())
+}
+
+// CreateIssueField create a custom issue field for a repository
+func CreateIssueField(ctx *context.APIContext, form api.CreateIssueFieldOption) {
+	// swagger:operation POST /repos/{owner}/{repo}/issue_fields issue issueCreateIssueField
+	// ---
+	// summary: Create a custom issue field
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateIssueFieldOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/IssueField"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	utils.AddIssueField(ctx, &form, 0, ctx.Repo.Repository.ID)
+}
+
+// EditIssueField modify a custom issue field of a repository
+func EditIssueField(ctx *context.APIContext, form api.EditIssueFieldOption) {
+	// swagger:operation PATCH /repos/{owner}/{repo}/issue_fields/{id} issue issueEditIssueField
+	// ---
+	// summary: Update a custom issue field
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the field to edit
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditIssueFieldOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/IssueField"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	field := utils.GetIssueField(ctx, 0, ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if ctx.Written() {
+		return
+	}
+	utils.EditIssueField(ctx, &form, field)
+}
+
+// DeleteIssueField delete a custom issue field of a repository with its values
+func DeleteIssueField(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/issue_fields/{id} issue issueDeleteIssueField
+	// ---
+	// summary: Delete a custom issue field and its values
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the field to delete
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	field := utils.GetIssueField(ctx, 0, ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if ctx.Written() {
+		return
+	}
+	utils.DeleteIssueField(ctx, field)
+}
+
+// SetIssueFieldValue set the value of a custom field on an issue
+func SetIssueFieldValue(ctx *context.APIContext, form api.SetIssueFieldValueOption) {
+	// swagger:operation PUT /repos/{owner}/{repo}/issues/{index}/fields/{id} issue issueSetFieldValue
+	// ---
+	// summary: Set the value of a custom field on an issue
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the issue
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the field
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/SetIssueFieldValueOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Issue"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if models.IsErrIssueNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(500, "GetIssueByIndex", err)
+		}
+		return
+	}
+	if !ctx.Repo.CanWriteIssuesOrPulls(issue.IsPull) {
+		ctx.Status(403)
+		return
+	}
+
+	field, err := models.GetIssueFieldByID(ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrIssueFieldNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(500, "GetIssueFieldByID", err)
+		}
+		return
+	}
+
+	if err = models.SetIssueFieldValues(issue, field, form.Values); err != nil {
+		switch {
+		case models.IsErrIssueFieldNotExist(err):
+			ctx.NotFound()
+		case models.IsErrIssueFieldValueInvalid(err):
+			ctx.Error(422, "", err)
+		default:
+			ctx.Error(500, "SetIssueFieldValues", err)
+		}
+		return
+	}
+
+	issue, err = models.GetIssueWithAttrsByIndex(ctx.Repo.Repository.ID, issue.Index)
+	if err != nil {
+		ctx.Error(500, "GetIssueWithAttrsByIndex", err)
+		return
+	}
+	ctx.JSON(200, issue.APIFormat())
+}
@@ -0,0 +1,71 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"github.com/masoodkamyab/gitea/modules/context"
+	api "github.com/masoodkamyab/gitea/modules/structs"
+)
+
+func listTemplates(ctx *context.APIContext, dirs []string) {
+	templates := make([]*api.IssueTemplate, 0)
+	if !ctx.Repo.Repository.IsEmpty {
+		found, err := ctx.Repo.GetTemplatesFromDefaultBranch(dirs)
+		if err != nil {
+			ctx.Error(500, "GetTemplatesFromDefaultBranch", err)
+			return
+		}
+		templates = append(templates, found...)
+	}
+	ctx.JSON(200, &templates)
+}
+
+// GetIssueTemplates list the issue templates of a repository
+func GetIssueTemplates(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/issue_templates repository repoGetIssueTemplates
+	// ---
+	// summary: Get the issue templates of a repository's default branch
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/IssueTemplates"
+	listTemplates(ctx, context.IssueTemplateDirCandidates)
+}
+
+// GetPullRequestTemplates list the pull request templates of a repository
+func GetPullRequestTemplates(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/pull_request_templates repository repoGetPullRequestTemplates
+	// ---
+	// summary: Get the pull request templates of a repository's default branch
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/IssueTemplates"
+	listTemplates(ctx, context.PullRequestTemplateDirCandidates)
+}
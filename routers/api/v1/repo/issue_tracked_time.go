@@ -7,6 +7,7 @@ package repo
 import (
 	"github.com/masoodkamyab/gitea/models"
 	"github.com/masoodkamyab/gitea/modules/context"
+	"github.com/masoodkamyab/gitea/routers/api/v1/utils"
 
 	api "github.com/masoodkamyab/gitea/modules/structs"
 )
@@ -19,6 +20,19 @@ func trackedTimesToAPIFormat(trackedTimes []*models.TrackedTime) []*api.TrackedT
 	return apiTrackedTimes
 }
 
+// setTrackedTimesDateRange restricts the options to the `since` and `before` query parameters.
+// If they are invalid, write to `ctx` accordingly and return false
+func setTrackedTimesDateRange(ctx *context.APIContext, opts *models.FindTrackedTimesOptions) bool {
+	before, since, err := utils.GetQueryBeforeSince(ctx)
+	if err != nil {
+		ctx.Error(422, "GetQueryBeforeSince", err)
+		return false
+	}
+	opts.CreatedBeforeUnix = before
+	opts.CreatedAfterUnix = since
+	return true
+}
+
 // ListTrackedTimes list all the tracked times of an issue
 func ListTrackedTimes(ctx *context.APIContext) {
 	// swagger:operation GET /repos/{owner}/{repo}/issues/{id}/times issue issueTrackedTimes
@@ -43,9 +57,21 @@ func ListTrackedTimes(ctx *context.APIContext) {
 	//   type: integer
 	//   format: int64
 	//   required: true
+	// - name: since
+	//   in: query
+	//   description: Only show times tracked after the given time. This is a timestamp in RFC 3339 format
+	//   type: string
+	//   format: date-time
+	// - name: before
+	//   in: query
+	//   description: Only show times tracked before the given time. This is a timestamp in RFC 3339 format
+	//   type: string
+	//   format: date-time
 	// responses:
 	//   "200":
 	//     "$ref": "#/responses/TrackedTimeList"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
 	if !ctx.Repo.Repository.IsTimetrackerEnabled() {
 		ctx.NotFound("Timetracker is disabled")
 		return
@@ -60,7 +86,11 @@ func ListTrackedTimes(ctx *context.APIContext) {
 		return
 	}
 
-	trackedTimes, err := models.GetTrackedTimes(models.FindTrackedTimesOptions{IssueID: issue.ID})
+	opts := models.FindTrackedTimesOptions{IssueID: issue.ID}
+	if !setTrackedTimesDateRange(ctx, &opts) {
+		return
+	}
+	trackedTimes, err := models.GetTrackedTimes(opts)
 	if err != nil {
 		ctx.Error(500, "GetTrackedTimesByIssue", err)
 		return
@@ -155,9 +185,21 @@ func ListTrackedTimesByUser(ctx *context.APIContext) {
 	//   description: username of user
 	//   type: string
 	//   required: true
+	// - name: since
+	//   in: query
+	//   description: Only show times tracked after the given time. This is a timestamp in RFC 3339 format
+	//   type: string
+	//   format: date-time
+	// - name: before
+	//   in: query
+	//   description: Only show times tracked before the given time. This is a timestamp in RFC 3339 format
+	//   type: string
+	//   format: date-time
 	// responses:
 	//   "200":
 	//     "$ref": "#/responses/TrackedTimeList"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
 	if !ctx.Repo.Repository.IsTimetrackerEnabled() {
 		ctx.JSON(400, struct{ Message string }{Message: "time tracking disabled"})
 		return
@@ -175,9 +217,13 @@ func ListTrackedTimesByUser(ctx *context.APIContext) {
 		ctx.NotFound()
 		return
 	}
-	trackedTimes, err := models.GetTrackedTimes(models.FindTrackedTimesOptions{
+	opts := models.FindTrackedTimesOptions{
 		UserID:       user.ID,
-		RepositoryID: ctx.Repo.Repository.ID})
+		RepositoryID: ctx.Repo.Repository.ID}
+	if !setTrackedTimesDateRange(ctx, &opts) {
+		return
+	}
+	trackedTimes, err := models.GetTrackedTimes(opts)
 	if err != nil {
 		ctx.Error(500, "GetTrackedTimesByUser", err)
 		return
@@ -204,15 +250,49 @@ func ListTrackedTimesByRepository(ctx *context.APIContext) {
 	//   description: name of the repo
 	//   type: string
 	//   required: true
+	// - name: user
+	//   in: query
+	//   description: optional filter by user
+	//   type: string
+	// - name: since
+	//   in: query
+	//   description: Only show times tracked after the given time. This is a timestamp in RFC 3339 format
+	//   type: string
+	//   format: date-time
+	// - name: before
+	//   in: query
+	//   description: Only show times tracked before the given time. This is a timestamp in RFC 3339 format
+	//   type: string
+	//   format: date-time
 	// responses:
 	//   "200":
 	//     "$ref": "#/responses/TrackedTimeList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
 	if !ctx.Repo.Repository.IsTimetrackerEnabled() {
 		ctx.JSON(400, struct{ Message string }{Message: "time tracking disabled"})
 		return
 	}
-	trackedTimes, err := models.GetTrackedTimes(models.FindTrackedTimesOptions{
-		RepositoryID: ctx.Repo.Repository.ID})
+	opts := models.FindTrackedTimesOptions{
+		RepositoryID: ctx.Repo.Repository.ID}
+	if userName := ctx.Query("user"); len(userName) > 0 {
+		user, err := models.GetUserByName(userName)
+		if err != nil {
+			if models.IsErrUserNotExist(err) {
+				ctx.NotFound(err)
+			} else {
+				ctx.Error(500, "GetUserByName", err)
+			}
+			return
+		}
+		opts.UserID = user.ID
+	}
+	if !setTrackedTimesDateRange(ctx, &opts) {
+		return
+	}
+	trackedTimes, err := models.GetTrackedTimes(opts)
 	if err != nil {
 		ctx.Error(500, "GetTrackedTimesByUser", err)
 		return
@@ -228,10 +308,27 @@ func ListMyTrackedTimes(ctx *context.APIContext) {
 	// summary: List the current user's tracked times
 	// produces:
 	// - application/json
+	// parameters:
+	// - name: since
+	//   in: query
+	//   description: Only show times tracked after the given time. This is a timestamp in RFC 3339 format
+	//   type: string
+	//   format: date-time
+	// - name: before
+	//   in: query
+	//   description: Only show times tracked before the given time. This is a timestamp in RFC 3339 format
+	//   type: string
+	//   format: date-time
 	// responses:
 	//   "200":
 	//     "$ref": "#/responses/TrackedTimeList"
-	trackedTimes, err := models.GetTrackedTimes(models.FindTrackedTimesOptions{UserID: ctx.User.ID})
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	opts := models.FindTrackedTimesOptions{UserID: ctx.User.ID}
+	if !setTrackedTimesDateRange(ctx, &opts) {
+		return
+	}
+	trackedTimes, err := models.GetTrackedTimes(opts)
 	if err != nil {
 		ctx.Error(500, "GetTrackedTimesByUser", err)
 		return
@@ -239,3 +336,153 @@ func ListMyTrackedTimes(ctx *context.APIContext) {
 	apiTrackedTimes := trackedTimesToAPIFormat(trackedTimes)
 	ctx.JSON(200, &apiTrackedTimes)
 }
+
+// getActionTrackedTime returns the tracked time of the issue of the request if the user may change it:
+// repository administrators may change all times, users their own ones. If not, write to `ctx` accordingly
+func getActionTrackedTime(ctx *context.APIContext) *models.TrackedTime {
+	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if models.IsErrIssueNotExist(err) {
+			ctx.NotFound(err)
+		} else {
+			ctx.Error(500, "GetIssueByIndex", err)
+		}
+		return nil
+	}
+	if !ctx.Repo.Repository.IsTimetrackerEnabled() {
+		ctx.JSON(400, struct{ Message string }{Message: "time tracking disabled"})
+		return nil
+	}
+
+	trackedTime, err := models.GetTrackedTimeByID(ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrTrackedTimeNotExist(err) {
+			ctx.NotFound(err)
+		} else {
+			ctx.Error(500, "GetTrackedTimeByID", err)
+		}
+		return nil
+	}
+	if trackedTime.IssueID != issue.ID {
+		ctx.NotFound()
+		return nil
+	}
+	if !ctx.Repo.IsAdmin() && (trackedTime.UserID != ctx.User.ID || !ctx.Repo.CanUseTimetracker(issue, ctx.User)) {
+		ctx.Status(403)
+		return nil
+	}
+	return trackedTime
+}
+
+// EditTrackedTime changes the amount of a tracked time
+func EditTrackedTime(ctx *context.APIContext, form api.EditTimeOption) {
+	// swagger:operation PATCH /repos/{owner}/{repo}/issues/{index}/times/{id} issue issueEditTime
+	// ---
+	// summary: Change the amount of a tracked time
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the issue
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the tracked time
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditTimeOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/TrackedTime"
+	//   "400":
+	//     "$ref": "#/responses/error"
+	//   "403":
+	//     "$ref": "#/responses/error"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	trackedTime := getActionTrackedTime(ctx)
+	if ctx.Written() {
+		return
+	}
+	if form.Time <= 0 {
+		ctx.Error(422, "", "time must be positive")
+		return
+	}
+
+	trackedTime.Time = form.Time
+	if err := models.UpdateTrackedTime(trackedTime); err != nil {
+		ctx.Error(500, "UpdateTrackedTime", err)
+		return
+	}
+	ctx.JSON(200, trackedTime.APIFormat())
+}
+
+// DeleteTrackedTime deletes a tracked time
+func DeleteTrackedTime(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/issues/{index}/times/{id} issue issueDeleteTime
+	// ---
+	// summary: Delete a tracked time
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the issue
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the tracked time
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "400":
+	//     "$ref": "#/responses/error"
+	//   "403":
+	//     "$ref": "#/responses/error"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	trackedTime := getActionTrackedTime(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.DeleteTrackedTime(trackedTime); err != nil {
+		ctx.Error(500, "DeleteTrackedTime", err)
+		return
+	}
+	ctx.Status(204)
+}
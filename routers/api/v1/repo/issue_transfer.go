@@ -0,0 +1,130 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	"github.com/masoodkamyab/gitea/models"
+	"github.com/masoodkamyab/gitea/modules/context"
+	"github.com/masoodkamyab/gitea/modules/notification"
+
+	api "github.com/masoodkamyab/gitea/modules/structs"
+)
+
+// TransferIssue transfer an issue to another repository
+func TransferIssue(ctx *context.APIContext, form api.TransferIssueOption) {
+	// swagger:operation POST /repos/{owner}/{repo}/issues/{index}/transfer issue issueTransferIssue
+	// ---
+	// summary: Transfer an issue to another repository. Labels and the milestone are mapped by name.
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the issue to transfer
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/TransferIssueOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/Issue"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if models.IsErrIssueNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(500, "GetIssueByIndex", err)
+		}
+		return
+	}
+	if issue.IsPull {
+		ctx.Error(422, "", "pull requests cannot be transferred")
+		return
+	}
+
+	newRepo, err := models.GetRepositoryByOwnerAndName(form.NewOwner, form.NewRepo)
+	if err != nil {
+		if models.IsErrRepoNotExist(err) {
+			ctx.Error(422, "", "repository does not exist")
+		} else {
+			ctx.Error(500, "GetRepositoryByOwnerAndName", err)
+		}
+		return
+	}
+	if canTransfer, err := models.CanTransferIssueTo(ctx.User, newRepo); err != nil {
+		ctx.Error(500, "CanTransferIssueTo", err)
+		return
+	} else if !canTransfer {
+		// Do not reveal whether a private repository exists
+		ctx.Error(422, "", "repository does not exist")
+		return
+	}
+
+	oldRepo := ctx.Repo.Repository
+	issue.Repo = oldRepo
+	if err = models.TransferIssue(ctx.User, issue, newRepo); err != nil {
+		if models.IsErrIssueCannotTransfer(err) {
+			ctx.Error(422, "", err.Error())
+		} else {
+			ctx.Error(500, "TransferIssue", err)
+		}
+		return
+	}
+	notification.NotifyIssueTransfer(ctx.User, issue, oldRepo)
+
+	issue, err = models.GetIssueByID(issue.ID)
+	if err != nil {
+		ctx.Error(500, "GetIssueByID", err)
+		return
+	}
+	ctx.JSON(201, issue.APIFormat())
+}
+
+// redirectTransferredIssue redirects to the issue which was transferred away from the requested index,
+// it returns false if there is no such issue or the user cannot see it
+func redirectTransferredIssue(ctx *context.APIContext, index int64) bool {
+	issue, err := models.LookupIssueRedirect(ctx.Repo.Repository.ID, index)
+	if err != nil {
+		if !models.IsErrIssueNotExist(err) {
+			ctx.Error(500, "LookupIssueRedirect", err)
+			return true
+		}
+		return false
+	}
+	if err = issue.LoadRepo(); err != nil {
+		ctx.Error(500, "LoadRepo", err)
+		return true
+	}
+	perm, err := models.GetUserRepoPermission(issue.Repo, ctx.User)
+	if err != nil {
+		ctx.Error(500, "GetUserRepoPermission", err)
+		return true
+	} else if !perm.CanRead(models.UnitTypeIssues) {
+		return false
+	}
+	ctx.Redirect(issue.APIURL(), http.StatusMovedPermanently)
+	return true
+}
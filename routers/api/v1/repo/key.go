@@ -209,6 +209,8 @@ func CreateDeployKey(ctx *context.APIContext, form api.CreateKeyOption) {
 		return
 	}
 
+	ctx.Audit(models.NewRepoAuditEvent(models.AuditRepoDeployKeyAdd, ctx.Repo.Repository, key.Name, models.NewAuditDiff(nil, key.AuditFields())))
+
 	key.Content = content
 	apiLink := composeDeployKeysAPILink(ctx.Repo.Owner.Name + "/" + ctx.Repo.Repository.Name)
 	ctx.JSON(201, convert.ToDeployKey(apiLink, key))
@@ -239,7 +241,24 @@ func DeleteDeploykey(ctx *context.APIContext) {
 	// responses:
 	//   "204":
 	//     "$ref": "#/responses/empty"
-	if err := models.DeleteDeployKey(ctx.User, ctx.ParamsInt64(":id")); err != nil {
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	key, err := models.GetDeployKeyByID(ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrDeployKeyNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(500, "GetDeployKeyByID", err)
+		}
+		return
+	} else if key.RepoID != ctx.Repo.Repository.ID {
+		ctx.NotFound()
+		return
+	}
+
+	if err := models.DeleteDeployKey(ctx.User, key.ID); err != nil {
 		if models.IsErrKeyAccessDenied(err) {
 			ctx.Error(403, "", "You do not have access to this key")
 		} else {
@@ -247,6 +266,7 @@ func DeleteDeploykey(ctx *context.APIContext) {
 		}
 		return
 	}
+	ctx.Audit(models.NewRepoAuditEvent(models.AuditRepoDeployKeyRemove, ctx.Repo.Repository, key.Name, models.NewAuditDiff(key.AuditFields(), nil)))
 
 	ctx.Status(204)
 }
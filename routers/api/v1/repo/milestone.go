@@ -91,6 +91,52 @@ func GetMilestone(ctx *context.APIContext) {
 	ctx.JSON(200, milestone.APIFormat())
 }
 
+// GetMilestoneBurndown get the history of a milestone
+func GetMilestoneBurndown(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/milestones/{id}/burndown issue issueGetMilestoneBurndown
+	// ---
+	// summary: Get the daily open and closed issue counts of a milestone and its forecast due date
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the milestone
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/MilestoneBurndown"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	milestone, err := models.GetMilestoneByRepoID(ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrMilestoneNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(500, "GetMilestoneByRepoID", err)
+		}
+		return
+	}
+	burndown, err := models.GetMilestoneBurndown(milestone)
+	if err != nil {
+		ctx.Error(500, "GetMilestoneBurndown", err)
+		return
+	}
+	ctx.JSON(200, burndown.APIFormat())
+}
+
 // CreateMilestone create a milestone for a repository
 func CreateMilestone(ctx *context.APIContext, form api.CreateMilestoneOption) {
 	// swagger:operation POST /repos/{owner}/{repo}/milestones issue issueCreateMilestone
@@ -0,0 +1,567 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"github.com/masoodkamyab/gitea/models"
+	"github.com/masoodkamyab/gitea/modules/context"
+	"github.com/masoodkamyab/gitea/modules/notification"
+	"github.com/masoodkamyab/gitea/modules/util"
+
+	api "github.com/masoodkamyab/gitea/modules/structs"
+)
+
+// ListProjects list the projects of a repository
+func ListProjects(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/projects project projectListRepoProjects
+	// ---
+	// summary: List a repository's projects
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: state
+	//   in: query
+	//   description: Project state, Recognised values are open, closed and all. Defaults to "open"
+	//   type: string
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ProjectList"
+	ListProjectsByOptions(ctx, &models.ProjectSearchOptions{
+		RepoID: ctx.Repo.Repository.ID,
+	})
+}
+
+// ListProjectsByOptions responds with the projects matching opts, the state and the page are taken from the query
+func ListProjectsByOptions(ctx *context.APIContext, opts *models.ProjectSearchOptions) {
+	switch api.StateType(ctx.Query("state")) {
+	case api.StateClosed:
+		opts.IsClosed = util.OptionalBoolTrue
+	case api.StateAll:
+		opts.IsClosed = util.OptionalBoolNone
+	default:
+		opts.IsClosed = util.OptionalBoolFalse
+	}
+	opts.Page = ctx.QueryInt("page")
+	if opts.Page <= 0 {
+		opts.Page = 1
+	}
+
+	projects, _, err := models.GetProjects(opts)
+	if err != nil {
+		ctx.Error(500, "GetProjects", err)
+		return
+	}
+
+	apiProjects := make([]*api.Project, len(projects))
+	for i := range projects {
+		if err = projects[i].LoadAttributes(); err != nil {
+			ctx.Error(500, "LoadAttributes", err)
+			return
+		}
+		apiProjects[i] = projects[i].APIFormat()
+	}
+	ctx.JSON(200, &apiProjects)
+}
+
+// CreateProject create a project for a repository
+func CreateProject(ctx *context.APIContext, form api.CreateProjectOption) {
+	// swagger:operation POST /repos/{owner}/{repo}/projects project projectCreateRepoProject
+	// ---
+	// summary: Create a project for a repository
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateProjectOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/Project"
+	CreateProjectByOptions(ctx, &models.Project{
+		RepoID: ctx.Repo.Repository.ID,
+		Repo:   ctx.Repo.Repository,
+		Type:   models.ProjectTypeRepository,
+	}, form)
+}
+
+// CreateProjectByOptions creates project p with the title and the description given by form
+func CreateProjectByOptions(ctx *context.APIContext, p *models.Project, form api.CreateProjectOption) {
+	p.Title = form.Title
+	p.Description = form.Description
+	p.CreatorID = ctx.User.ID
+	p.Creator = ctx.User
+	if err := models.NewProject(p); err != nil {
+		ctx.Error(500, "NewProject", err)
+		return
+	}
+	ctx.JSON(201, p.APIFormat())
+}
+
+// GetProject get a project
+func GetProject(ctx *context.APIContext, p *models.Project) {
+	// swagger:operation GET /projects/{id} project projectGet
+	// ---
+	// summary: Get a project
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Project"
+	ctx.JSON(200, p.APIFormat())
+}
+
+// EditProject modify a project
+func EditProject(ctx *context.APIContext, p *models.Project, form api.EditProjectOption) {
+	// swagger:operation PATCH /projects/{id} project projectEdit
+	// ---
+	// summary: Update a project
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditProjectOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Project"
+	if len(form.Title) > 0 {
+		p.Title = form.Title
+	}
+	if form.Description != nil {
+		p.Description = *form.Description
+	}
+	if err := models.UpdateProject(p); err != nil {
+		ctx.Error(500, "UpdateProject", err)
+		return
+	}
+
+	if form.State != nil {
+		if isClosed := api.StateType(*form.State) == api.StateClosed; isClosed != p.IsClosed {
+			if err := models.ChangeProjectStatus(p, isClosed); err != nil {
+				ctx.Error(500, "ChangeProjectStatus", err)
+				return
+			}
+		}
+	}
+	ctx.JSON(200, p.APIFormat())
+}
+
+// DeleteProject delete a project
+func DeleteProject(ctx *context.APIContext, p *models.Project) {
+	// swagger:operation DELETE /projects/{id} project projectDelete
+	// ---
+	// summary: Delete a project
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the project to delete
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	if err := models.DeleteProjectByID(p.ID); err != nil {
+		ctx.Error(500, "DeleteProjectByID", err)
+		return
+	}
+	ctx.Status(204)
+}
+
+// ListProjectBoards list the boards of a project
+func ListProjectBoards(ctx *context.APIContext, p *models.Project) {
+	// swagger:operation GET /projects/{id}/boards project projectListBoards
+	// ---
+	// summary: List a project's boards
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ProjectBoardList"
+	boards, err := models.GetProjectBoards(p.ID)
+	if err != nil {
+		ctx.Error(500, "GetProjectBoards", err)
+		return
+	}
+
+	apiBoards := make([]*api.ProjectBoard, len(boards))
+	for i := range boards {
+		apiBoards[i] = boards[i].APIFormat()
+	}
+	ctx.JSON(200, &apiBoards)
+}
+
+// CreateProjectBoard create a board of a project
+func CreateProjectBoard(ctx *context.APIContext, p *models.Project, form api.CreateProjectBoardOption) {
+	// swagger:operation POST /projects/{id}/boards project projectCreateBoard
+	// ---
+	// summary: Create a board of a project
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateProjectBoardOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/ProjectBoard"
+	b := &models.ProjectBoard{
+		ProjectID: p.ID,
+		Title:     form.Title,
+		Sorting:   form.Sorting,
+		CreatorID: ctx.User.ID,
+	}
+	if err := models.NewProjectBoard(b); err != nil {
+		ctx.Error(500, "NewProjectBoard", err)
+		return
+	}
+	ctx.JSON(201, b.APIFormat())
+}
+
+func getProjectBoard(ctx *context.APIContext, p *models.Project) *models.ProjectBoard {
+	b, err := models.GetProjectBoard(p.ID, ctx.ParamsInt64(":boardid"))
+	if err != nil {
+		if models.IsErrProjectBoardNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(500, "GetProjectBoard", err)
+		}
+		return nil
+	}
+	return b
+}
+
+// EditProjectBoard modify a board of a project
+func EditProjectBoard(ctx *context.APIContext, p *models.Project, form api.EditProjectBoardOption) {
+	// swagger:operation PATCH /projects/{id}/boards/{board_id} project projectEditBoard
+	// ---
+	// summary: Update a board of a project
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: board_id
+	//   in: path
+	//   description: id of the board
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditProjectBoardOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ProjectBoard"
+	b := getProjectBoard(ctx, p)
+	if ctx.Written() {
+		return
+	}
+
+	if len(form.Title) > 0 {
+		b.Title = form.Title
+	}
+	if form.Sorting != nil {
+		b.Sorting = *form.Sorting
+	}
+	if err := models.UpdateProjectBoard(b); err != nil {
+		ctx.Error(500, "UpdateProjectBoard", err)
+		return
+	}
+	ctx.JSON(200, b.APIFormat())
+}
+
+// DeleteProjectBoard delete a board of a project
+func DeleteProjectBoard(ctx *context.APIContext, p *models.Project) {
+	// swagger:operation DELETE /projects/{id}/boards/{board_id} project projectDeleteBoard
+	// ---
+	// summary: Delete a board of a project, its issues become uncategorized
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: board_id
+	//   in: path
+	//   description: id of the board to delete
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	b := getProjectBoard(ctx, p)
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.DeleteProjectBoard(b); err != nil {
+		ctx.Error(500, "DeleteProjectBoard", err)
+		return
+	}
+	ctx.Status(204)
+}
+
+// ListProjectIssues list the issues of a project
+func ListProjectIssues(ctx *context.APIContext, p *models.Project) {
+	// swagger:operation GET /projects/{id}/issues project projectListIssues
+	// ---
+	// summary: List the issues and pull requests on a project
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ProjectIssueList"
+	pis, err := models.GetProjectIssues(p.ID)
+	if err != nil {
+		ctx.Error(500, "GetProjectIssues", err)
+		return
+	}
+	if pis, err = models.FilterProjectIssuesByUser(pis, ctx.User); err != nil {
+		ctx.Error(500, "FilterProjectIssuesByUser", err)
+		return
+	}
+
+	apiIssues := make([]*api.ProjectIssue, len(pis))
+	for i := range pis {
+		apiIssues[i] = pis[i].APIFormat()
+	}
+	ctx.JSON(200, &apiIssues)
+}
+
+// AddProjectIssue put an issue on a project
+func AddProjectIssue(ctx *context.APIContext, p *models.Project, form api.AddProjectIssueOption) {
+	// swagger:operation POST /projects/{id}/issues project projectAddIssue
+	// ---
+	// summary: Put an issue or a pull request on a project
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/AddProjectIssueOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/ProjectIssue"
+	//   "409":
+	//     "$ref": "#/responses/error"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	issue, err := models.GetIssueByID(form.IssueID)
+	if err != nil {
+		if models.IsErrIssueNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(500, "GetIssueByID", err)
+		}
+		return
+	}
+	if err = issue.LoadRepo(); err != nil {
+		ctx.Error(500, "LoadRepo", err)
+		return
+	}
+	perm, err := models.GetUserRepoPermission(issue.Repo, ctx.User)
+	if err != nil {
+		ctx.Error(500, "GetUserRepoPermission", err)
+		return
+	} else if !perm.CanReadIssuesOrPulls(issue.IsPull) {
+		ctx.NotFound()
+		return
+	}
+
+	pi, err := models.AddIssueToProject(p, issue, form.BoardID)
+	if err != nil {
+		switch {
+		case models.IsErrProjectIssueAlreadyExist(err):
+			ctx.Error(409, "AddIssueToProject", err)
+		case models.IsErrProjectIssueNotAllowed(err), models.IsErrProjectBoardNotExist(err):
+			ctx.Error(422, "AddIssueToProject", err)
+		default:
+			ctx.Error(500, "AddIssueToProject", err)
+		}
+		return
+	}
+	ctx.JSON(201, pi.APIFormat())
+}
+
+// MoveProjectIssue move an issue between the boards of a project
+func MoveProjectIssue(ctx *context.APIContext, p *models.Project, form api.MoveProjectIssueOption) {
+	// swagger:operation PATCH /projects/{id}/issues/{issue_id} project projectMoveIssue
+	// ---
+	// summary: Move an issue or a pull request to a position on a board of a project
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: issue_id
+	//   in: path
+	//   description: id of the issue
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/MoveProjectIssueOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ProjectIssue"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	pi, err := models.GetProjectIssue(p.ID, ctx.ParamsInt64(":issueid"))
+	if err != nil {
+		if models.IsErrProjectIssueNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(500, "GetProjectIssue", err)
+		}
+		return
+	}
+	if pi.Issue, err = models.GetIssueByID(pi.IssueID); err != nil {
+		ctx.Error(500, "GetIssueByID", err)
+		return
+	}
+
+	oldBoardID := pi.ProjectBoardID
+	if err = models.MoveProjectIssue(pi, form.BoardID, form.Sorting); err != nil {
+		if models.IsErrProjectBoardNotExist(err) {
+			ctx.Error(422, "MoveProjectIssue", err)
+		} else {
+			ctx.Error(500, "MoveProjectIssue", err)
+		}
+		return
+	}
+	notification.NotifyIssueChangeProjectBoard(ctx.User, pi.Issue, p, oldBoardID, pi.ProjectBoardID)
+
+	ctx.JSON(200, pi.APIFormat())
+}
+
+// RemoveProjectIssue take an issue off a project
+func RemoveProjectIssue(ctx *context.APIContext, p *models.Project) {
+	// swagger:operation DELETE /projects/{id}/issues/{issue_id} project projectRemoveIssue
+	// ---
+	// summary: Take an issue or a pull request off a project
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: issue_id
+	//   in: path
+	//   description: id of the issue
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	if _, err := models.GetProjectIssue(p.ID, ctx.ParamsInt64(":issueid")); err != nil {
+		if models.IsErrProjectIssueNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(500, "GetProjectIssue", err)
+		}
+		return
+	}
+
+	if err := models.RemoveIssueFromProject(p.ID, ctx.ParamsInt64(":issueid")); err != nil {
+		ctx.Error(500, "RemoveIssueFromProject", err)
+		return
+	}
+	ctx.Status(204)
+}
@@ -65,16 +65,25 @@ func ListPullRequests(ctx *context.APIContext, form api.ListPullRequestsOptions)
 	//   items:
 	//     type: integer
 	//     format: int64
+	// - name: draft
+	//   in: query
+	//   description: "whether to list only drafts (`true`) or only pull requests ready for review (`false`), both if omitted"
+	//   type: boolean
 	// responses:
 	//   "200":
 	//     "$ref": "#/responses/PullRequestList"
-	prs, maxResults, err := models.PullRequests(ctx.Repo.Repository.ID, &models.PullRequestsOptions{
+	opts := &models.PullRequestsOptions{
 		Page:        ctx.QueryInt("page"),
 		State:       ctx.QueryTrim("state"),
 		SortType:    ctx.QueryTrim("sort"),
 		Labels:      ctx.QueryStrings("labels"),
 		MilestoneID: ctx.QueryInt64("milestone"),
-	})
+	}
+	if len(ctx.QueryTrim("draft")) > 0 {
+		opts.IsDraft = util.OptionalBoolOf(ctx.QueryBool("draft"))
+	}
+
+	prs, maxResults, err := models.PullRequests(ctx.Repo.Repository.ID, opts)
 
 	if err != nil {
 		ctx.Error(500, "PullRequests", err)
@@ -280,6 +289,7 @@ func CreatePullRequest(ctx *context.APIContext, form api.CreatePullRequestOption
 		BaseRepo:     repo,
 		MergeBase:    compareInfo.MergeBase,
 		Type:         models.PullRequestGitea,
+		IsDraft:      form.Draft,
 	}
 
 	// Get all assignee IDs
@@ -444,6 +454,13 @@ func EditPullRequest(ctx *context.APIContext, form api.EditPullRequestOption) {
 		notification.NotifyIssueChangeStatus(ctx.User, issue, api.StateClosed == api.StateType(*form.State))
 	}
 
+	if form.Draft != nil && !issue.IsClosed && !pr.HasMerged {
+		if err = pull.SetDraft(pr, ctx.User, *form.Draft); err != nil {
+			ctx.Error(500, "SetDraft", err)
+			return
+		}
+	}
+
 	// Refetch from database
 	pr, err = models.GetPullRequestByIndex(ctx.Repo.Repository.ID, pr.Index)
 	if err != nil {
@@ -535,8 +552,12 @@ func MergePullRequest(ctx *context.APIContext, form auth.MergePullRequestForm) {
 	// responses:
 	//   "200":
 	//     "$ref": "#/responses/empty"
+	//   "202":
+	//     "$ref": "#/responses/empty"
 	//   "405":
 	//     "$ref": "#/responses/empty"
+	//   "409":
+	//     "$ref": "#/responses/empty"
 	pr, err := models.GetPullRequestByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
 	if err != nil {
 		if models.IsErrPullRequestNotExist(err) {
@@ -596,10 +617,36 @@ func MergePullRequest(ctx *context.APIContext, form auth.MergePullRequestForm) {
 		message += "\n\n" + form.MergeMessageField
 	}
 
+	queued, err := pr.IsMergeQueueEnabled()
+	if err != nil {
+		ctx.Error(500, "IsMergeQueueEnabled", err)
+		return
+	}
+	if queued {
+		if _, err := pull.AddToMergeQueue(pr, ctx.User, models.MergeStyle(form.Do), message); err != nil {
+			if models.IsErrInvalidMergeStyle(err) {
+				ctx.Status(405)
+				return
+			} else if models.IsErrPullRequestAlreadyQueued(err) {
+				ctx.Status(409)
+				return
+			}
+			ctx.Error(500, "AddToMergeQueue", err)
+			return
+		}
+
+		log.Trace("Pull request added to merge queue: %d", pr.ID)
+		ctx.Status(202)
+		return
+	}
+
 	if err := pull.Merge(pr, ctx.User, ctx.Repo.GitRepo, models.MergeStyle(form.Do), message); err != nil {
 		if models.IsErrInvalidMergeStyle(err) {
 			ctx.Status(405)
 			return
+		} else if models.IsErrMergeNotFastForward(err) {
+			ctx.Status(409)
+			return
 		}
 		ctx.Error(500, "Merge", err)
 		return
@@ -609,6 +656,87 @@ func MergePullRequest(ctx *context.APIContext, form auth.MergePullRequestForm) {
 	ctx.Status(200)
 }
 
+// UpdatePullRequest merges the base branch of a PR into its head branch, or rebases the head branch onto it
+func UpdatePullRequest(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/pulls/{index}/update repository repoUpdatePullRequest
+	// ---
+	// summary: Update the head branch of a pull request with the changes of its base branch
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request to update
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: style
+	//   in: query
+	//   description: how to update the head branch, either "merge" (default) or "rebase"
+	//   type: string
+	//   enum: [merge, rebase]
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "409":
+	//     "$ref": "#/responses/error"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	pr, err := models.GetPullRequestByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if models.IsErrPullRequestNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(500, "GetPullRequestByIndex", err)
+		}
+		return
+	}
+
+	if err = pr.LoadIssue(); err != nil {
+		ctx.Error(500, "LoadIssue", err)
+		return
+	}
+
+	if pr.HasMerged || pr.Issue.IsClosed {
+		ctx.Error(422, "", "pull request is closed")
+		return
+	}
+
+	style := models.MergeStyle(ctx.Query("style"))
+	if len(style) == 0 {
+		style = models.MergeStyleMerge
+	}
+
+	if err = pull.Update(pr, ctx.User, style); err != nil {
+		if models.IsErrInvalidMergeStyle(err) {
+			ctx.Error(422, "", err)
+		} else if models.IsErrNotAllowedToUpdate(err) || models.IsErrErrPullRequestHeadRepoMissing(err) {
+			ctx.Error(403, "", err)
+		} else if models.IsErrPullRequestUpdateConflict(err) {
+			ctx.Error(409, "", "the base branch cannot be applied to the head branch without conflicts")
+		} else {
+			ctx.Error(500, "Update", err)
+		}
+		return
+	}
+
+	ctx.Status(200)
+}
+
 func parseCompareInfo(ctx *context.APIContext, form api.CreatePullRequestOption) (*models.User, *models.Repository, *git.Repository, *git.CompareInfo, string, string) {
 	baseRepo := ctx.Repo.Repository
 
@@ -722,6 +722,15 @@ func updateRepoUnits(ctx *context.APIContext, opts api.EditRepoOption) error {
 			if opts.AllowSquash != nil {
 				config.AllowSquash = *opts.AllowSquash
 			}
+			if opts.AllowFastForwardOnly != nil {
+				config.AllowFastForwardOnly = *opts.AllowFastForwardOnly
+			}
+			if opts.MergeMessageTemplate != nil {
+				config.MergeMessageTemplate = strings.TrimSpace(*opts.MergeMessageTemplate)
+			}
+			if opts.SquashMessageTemplate != nil {
+				config.SquashMessageTemplate = strings.TrimSpace(*opts.SquashMessageTemplate)
+			}
 
 			units = append(units, models.RepoUnit{
 				RepoID: repo.ID,
@@ -731,6 +740,15 @@ func updateRepoUnits(ctx *context.APIContext, opts api.EditRepoOption) error {
 		}
 	}
 
+	if opts.HasProjects != nil && *opts.HasProjects ||
+		opts.HasProjects == nil && repo.UnitEnabled(models.UnitTypeProjects) {
+		units = append(units, models.RepoUnit{
+			RepoID: repo.ID,
+			Type:   models.UnitTypeProjects,
+			Config: new(models.UnitConfig),
+		})
+	}
+
 	if err := models.UpdateRepositoryUnits(repo, units); err != nil {
 		ctx.Error(http.StatusInternalServerError, "UpdateRepositoryUnits", err)
 		return err
@@ -810,6 +828,7 @@ func Delete(ctx *context.APIContext) {
 		ctx.Error(500, "DeleteRepository", err)
 		return
 	}
+	ctx.Audit(models.NewRepoAuditEvent(models.AuditRepoDelete, repo, repo.FullName(), nil))
 
 	log.Trace("Repository deleted: %s/%s", owner.Name, repo.Name)
 	ctx.Status(204)
@@ -9,6 +9,7 @@ import (
 
 	"github.com/masoodkamyab/gitea/models"
 	"github.com/masoodkamyab/gitea/modules/context"
+	"github.com/masoodkamyab/gitea/modules/pull"
 	"github.com/masoodkamyab/gitea/modules/repofiles"
 
 	api "github.com/masoodkamyab/gitea/modules/structs"
@@ -63,6 +64,8 @@ func NewCommitStatus(ctx *context.APIContext, form api.CreateStatusOption) {
 		return
 	}
 
+	go pull.CheckMergeQueuesForCommit(ctx.Repo.Repository, sha)
+
 	ctx.JSON(201, status.APIFormat())
 }
 
@@ -64,6 +64,13 @@ type swaggerResponseMilestoneList struct {
 	Body []api.Milestone `json:"body"`
 }
 
+// MilestoneBurndown
+// swagger:response MilestoneBurndown
+type swaggerResponseMilestoneBurndown struct {
+	// in:body
+	Body api.MilestoneBurndown `json:"body"`
+}
+
 // TrackedTime
 // swagger:response TrackedTime
 type swaggerResponseTrackedTime struct {
@@ -84,3 +91,38 @@ type swaggerIssueDeadline struct {
 	// in:body
 	Body api.IssueDeadline `json:"body"`
 }
+
+// IssueTemplates
+// swagger:response IssueTemplates
+type swaggerIssueTemplates struct {
+	// in:body
+	Body []api.IssueTemplate `json:"body"`
+}
+
+// SavedFilter
+// swagger:response SavedFilter
+type swaggerResponseSavedFilter struct {
+	// in:body
+	Body api.SavedFilter `json:"body"`
+}
+
+// SavedFilterList
+// swagger:response SavedFilterList
+type swaggerResponseSavedFilterList struct {
+	// in:body
+	Body []api.SavedFilter `json:"body"`
+}
+
+// IssueField
+// swagger:response IssueField
+type swaggerResponseIssueField struct {
+	// in:body
+	Body api.IssueField `json:"body"`
+}
+
+// IssueFieldList
+// swagger:response IssueFieldList
+type swaggerResponseIssueFieldList struct {
+	// in:body
+	Body []api.IssueField `json:"body"`
+}
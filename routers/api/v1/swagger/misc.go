@@ -14,3 +14,10 @@ type swaggerResponseServerVersion struct {
 	// in:body
 	Body api.ServerVersion `json:"body"`
 }
+
+// AuditEventList
+// swagger:response AuditEventList
+type swaggerResponseAuditEventList struct {
+	// in:body
+	Body []api.AuditEvent `json:"body"`
+}
@@ -117,4 +117,38 @@ type swaggerParameterBodies struct {
 
 	// in:body
 	DeleteFileOptions api.DeleteFileOptions
+
+	// in:body
+	CreateProjectOption api.CreateProjectOption
+	// in:body
+	EditProjectOption api.EditProjectOption
+	// in:body
+	CreateProjectBoardOption api.CreateProjectBoardOption
+	// in:body
+	EditProjectBoardOption api.EditProjectBoardOption
+	// in:body
+	AddProjectIssueOption api.AddProjectIssueOption
+	// in:body
+	MoveProjectIssueOption api.MoveProjectIssueOption
+
+	// in:body
+	CreateSavedFilterOption api.CreateSavedFilterOption
+	// in:body
+	EditSavedFilterOption api.EditSavedFilterOption
+
+	// in:body
+	TransferIssueOption api.TransferIssueOption
+
+	// in:body
+	EditIssuesOption api.EditIssuesOption
+
+	// in:body
+	CreateIssueFieldOption api.CreateIssueFieldOption
+	// in:body
+	EditIssueFieldOption api.EditIssueFieldOption
+	// in:body
+	SetIssueFieldValueOption api.SetIssueFieldValueOption
+
+	// in:body
+	EditTimeOption api.EditTimeOption
 }
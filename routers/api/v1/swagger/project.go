@@ -0,0 +1,51 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package swagger
+
+import (
+	api "github.com/masoodkamyab/gitea/modules/structs"
+)
+
+// Project
+// swagger:response Project
+type swaggerResponseProject struct {
+	// in:body
+	Body api.Project `json:"body"`
+}
+
+// ProjectList
+// swagger:response ProjectList
+type swaggerResponseProjectList struct {
+	// in:body
+	Body []api.Project `json:"body"`
+}
+
+// ProjectBoard
+// swagger:response ProjectBoard
+type swaggerResponseProjectBoard struct {
+	// in:body
+	Body api.ProjectBoard `json:"body"`
+}
+
+// ProjectBoardList
+// swagger:response ProjectBoardList
+type swaggerResponseProjectBoardList struct {
+	// in:body
+	Body []api.ProjectBoard `json:"body"`
+}
+
+// ProjectIssue
+// swagger:response ProjectIssue
+type swaggerResponseProjectIssue struct {
+	// in:body
+	Body api.ProjectIssue `json:"body"`
+}
+
+// ProjectIssueList
+// swagger:response ProjectIssueList
+type swaggerResponseProjectIssueList struct {
+	// in:body
+	Body []api.ProjectIssue `json:"body"`
+}
@@ -6,10 +6,15 @@
 package user
 
 import (
+	"strings"
+	"time"
+
 	api "github.com/masoodkamyab/gitea/modules/structs"
 
 	"github.com/masoodkamyab/gitea/models"
 	"github.com/masoodkamyab/gitea/modules/context"
+	"github.com/masoodkamyab/gitea/modules/util"
+	"github.com/masoodkamyab/gitea/routers/api/v1/convert"
 )
 
 // ListAccessTokens list all the access tokens
@@ -39,11 +44,7 @@ func ListAccessTokens(ctx *context.APIContext) {
 		if tokens[i].Name == "drone" {
 			tokens[i].Name = "drone-legacy-use-oauth2-instead"
 		}
-		apiTokens[i] = &api.AccessToken{
-			ID:             tokens[i].ID,
-			Name:           tokens[i].Name,
-			TokenLastEight: tokens[i].TokenLastEight,
-		}
+		apiTokens[i] = convert.ToAccessToken(tokens[i])
 	}
 	ctx.JSON(200, &apiTokens)
 }
@@ -72,12 +73,54 @@ func CreateAccessToken(ctx *context.APIContext, form api.CreateAccessTokenOption
 	//     properties:
 	//       name:
 	//         type: string
+	//       scopes:
+	//         type: array
+	//         items:
+	//           type: string
+	//       expires_at:
+	//         type: string
+	//         format: date-time
 	// responses:
-	//   "200":
+	//   "201":
 	//     "$ref": "#/responses/AccessToken"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	scope, err := models.ParseAccessTokenScope(strings.Join(form.Scopes, ","))
+	if err != nil {
+		if models.IsErrInvalidAccessTokenScope(err) {
+			ctx.Error(422, "", err)
+		} else {
+			ctx.Error(500, "ParseAccessTokenScope", err)
+		}
+		return
+	}
+
+	// a token can only create tokens with its own scopes
+	tokenScope, isToken := ctx.Data["ApiTokenScope"].(models.AccessTokenScope)
+	if len(scope) == 0 {
+		scope = models.AccessTokenScopeAll
+		if isToken {
+			scope = tokenScope
+		}
+	}
+	if isToken && !tokenScope.Contains(scope) {
+		ctx.Error(403, "", "token cannot create a token with more scopes than its own")
+		return
+	}
+
 	t := &models.AccessToken{
-		UID:  ctx.User.ID,
-		Name: form.Name,
+		UID:   ctx.User.ID,
+		Name:  form.Name,
+		Scope: scope,
+	}
+	if form.ExpiresAt != nil {
+		if !form.ExpiresAt.After(time.Now()) {
+			ctx.Error(422, "", "expiry date must be in the future")
+			return
+		}
+		t.ExpiresUnix = util.TimeStamp(form.ExpiresAt.Unix())
 	}
 	if t.Name == "drone" {
 		t.Name = "drone-legacy-use-oauth2-instead"
@@ -86,12 +129,7 @@ func CreateAccessToken(ctx *context.APIContext, form api.CreateAccessTokenOption
 		ctx.Error(500, "NewAccessToken", err)
 		return
 	}
-	ctx.JSON(201, &api.AccessToken{
-		Name:           t.Name,
-		Token:          t.Token,
-		ID:             t.ID,
-		TokenLastEight: t.TokenLastEight,
-	})
+	ctx.JSON(201, convert.ToAccessToken(t))
 }
 
 // DeleteAccessToken delete access tokens
@@ -0,0 +1,219 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"github.com/masoodkamyab/gitea/models"
+	"github.com/masoodkamyab/gitea/modules/context"
+
+	api "github.com/masoodkamyab/gitea/modules/structs"
+)
+
+// ListMySavedFilters list the saved filters of the authenticated user and those shared with the user's teams
+func ListMySavedFilters(ctx *context.APIContext) {
+	// swagger:operation GET /user/filters user userCurrentListSavedFilters
+	// ---
+	// summary: List the saved issue filters of the authenticated user, including those shared with the user's teams
+	// produces:
+	// - application/json
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/SavedFilterList"
+	filters, err := models.GetSavedFiltersForUser(ctx.User.ID)
+	if err != nil {
+		ctx.Error(500, "GetSavedFiltersForUser", err)
+		return
+	}
+
+	apiFilters := make([]*api.SavedFilter, len(filters))
+	for i := range filters {
+		apiFilters[i] = filters[i].APIFormat()
+	}
+	ctx.JSON(200, &apiFilters)
+}
+
+// getSavedFilterByParams returns the filter named by the :id parameter if the authenticated user can see it
+func getSavedFilterByParams(ctx *context.APIContext) *models.SavedFilter {
+	f, err := models.GetSavedFilterForUser(ctx.ParamsInt64(":id"), ctx.User.ID)
+	if err != nil {
+		if models.IsErrSavedFilterNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(500, "GetSavedFilterForUser", err)
+		}
+		return nil
+	}
+	return f
+}
+
+// handleSavedFilterError responds to an error of saving a filter
+func handleSavedFilterError(ctx *context.APIContext, err error) {
+	switch {
+	case models.IsErrSavedFilterInvalid(err):
+		ctx.Error(422, "", err.Error())
+	case models.IsErrRepoNotExist(err):
+		ctx.Error(422, "", "repository does not exist")
+	case err == models.ErrTeamNotExist:
+		ctx.Error(422, "", "team does not exist")
+	default:
+		ctx.Error(500, "SaveFilter", err)
+	}
+}
+
+// GetSavedFilter get a saved filter
+func GetSavedFilter(ctx *context.APIContext) {
+	// swagger:operation GET /user/filters/{id} user userCurrentGetSavedFilter
+	// ---
+	// summary: Get a saved issue filter
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the filter to get
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/SavedFilter"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	f := getSavedFilterByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.JSON(200, f.APIFormat())
+}
+
+// CreateSavedFilter save a filter for the authenticated user
+func CreateSavedFilter(ctx *context.APIContext, form api.CreateSavedFilterOption) {
+	// swagger:operation POST /user/filters user userCurrentCreateSavedFilter
+	// ---
+	// summary: Save an issue filter
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateSavedFilterOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/SavedFilter"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	f := &models.SavedFilter{
+		UserID:   ctx.User.ID,
+		Name:     form.Name,
+		Query:    form.Query,
+		SortType: form.Sort,
+		RepoID:   form.RepoID,
+		TeamID:   form.TeamID,
+	}
+	if err := models.NewSavedFilter(f); err != nil {
+		handleSavedFilterError(ctx, err)
+		return
+	}
+	ctx.JSON(201, f.APIFormat())
+}
+
+// EditSavedFilter edit a saved filter of the authenticated user
+func EditSavedFilter(ctx *context.APIContext, form api.EditSavedFilterOption) {
+	// swagger:operation PATCH /user/filters/{id} user userCurrentEditSavedFilter
+	// ---
+	// summary: Edit a saved issue filter, only the owner of the filter may edit it
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the filter to edit
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditSavedFilterOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/SavedFilter"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	f := getSavedFilterByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+	if !f.IsOwnedBy(ctx.User.ID) {
+		ctx.Error(403, "", "You do not own this filter")
+		return
+	}
+
+	if form.Name != nil {
+		f.Name = *form.Name
+	}
+	if form.Query != nil {
+		f.Query = *form.Query
+	}
+	if form.Sort != nil {
+		f.SortType = *form.Sort
+	}
+	if form.RepoID != nil {
+		f.RepoID = *form.RepoID
+	}
+	if form.TeamID != nil {
+		f.TeamID = *form.TeamID
+	}
+	if err := models.UpdateSavedFilter(f); err != nil {
+		handleSavedFilterError(ctx, err)
+		return
+	}
+	ctx.JSON(200, f.APIFormat())
+}
+
+// DeleteSavedFilter delete a saved filter of the authenticated user
+func DeleteSavedFilter(ctx *context.APIContext) {
+	// swagger:operation DELETE /user/filters/{id} user userCurrentDeleteSavedFilter
+	// ---
+	// summary: Delete a saved issue filter, only the owner of the filter may delete it
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the filter to delete
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	f := getSavedFilterByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+	if !f.IsOwnedBy(ctx.User.ID) {
+		ctx.Error(403, "", "You do not own this filter")
+		return
+	}
+	if err := models.DeleteSavedFilter(f); err != nil {
+		ctx.Error(500, "DeleteSavedFilter", err)
+		return
+	}
+	ctx.Status(204)
+}
@@ -0,0 +1,52 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package utils
+
+import (
+	"fmt"
+
+	"github.com/masoodkamyab/gitea/models"
+	"github.com/masoodkamyab/gitea/modules/context"
+	"github.com/masoodkamyab/gitea/modules/setting"
+	api "github.com/masoodkamyab/gitea/modules/structs"
+)
+
+// ListAuditEvents responds with the page of audit events of the owner given by
+// the `page`, `limit`, `since` and `before` query parameters, all events if
+// the owner is zero
+func ListAuditEvents(ctx *context.APIContext, ownerID int64) {
+	opts := &models.FindAuditEventsOptions{
+		OwnerID:  ownerID,
+		Page:     ctx.QueryInt("page"),
+		PageSize: ctx.QueryInt("limit"),
+	}
+	if opts.PageSize <= 0 || opts.PageSize > setting.API.MaxResponseItems {
+		opts.PageSize = setting.API.MaxResponseItems
+	}
+	var err error
+	if opts.Before, opts.Since, err = GetQueryBeforeSince(ctx); err != nil {
+		ctx.Error(422, "GetQueryBeforeSince", err)
+		return
+	}
+
+	count, err := models.CountAuditEvents(opts)
+	if err != nil {
+		ctx.Error(500, "CountAuditEvents", err)
+		return
+	}
+	events, err := models.FindAuditEvents(opts)
+	if err != nil {
+		ctx.Error(500, "FindAuditEvents", err)
+		return
+	}
+
+	apiEvents := make([]*api.AuditEvent, len(events))
+	for i := range events {
+		apiEvents[i] = events[i].APIFormat()
+	}
+	ctx.SetLinkHeader(int(count), opts.PageSize)
+	ctx.Header().Set("X-Total-Count", fmt.Sprintf("%d", count))
+	ctx.JSON(200, &apiEvents)
+}
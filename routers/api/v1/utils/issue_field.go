@@ -0,0 +1,94 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package utils
+
+import (
+	"net/http"
+
+	"github.com/masoodkamyab/gitea/models"
+	"github.com/masoodkamyab/gitea/modules/context"
+	api "github.com/masoodkamyab/gitea/modules/structs"
+)
+
+// GetIssueField get a custom issue field of an organization or a repository. If there is
+// an error, write to `ctx` accordingly and return nil
+func GetIssueField(ctx *context.APIContext, ownerID, repoID, fieldID int64) *models.IssueField {
+	field, err := models.GetIssueFieldByID(fieldID)
+	if err != nil {
+		if models.IsErrIssueFieldNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(500, "GetIssueFieldByID", err)
+		}
+		return nil
+	}
+	if field.OwnerID != ownerID || field.RepoID != repoID {
+		ctx.NotFound()
+		return nil
+	}
+	return field
+}
+
+// handleIssueFieldError writes the error of creating or updating a custom issue field
+// to `ctx`. Return whether there was an error
+func handleIssueFieldError(ctx *context.APIContext, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case models.IsErrIssueFieldAlreadyExist(err), models.IsErrIssueFieldInvalid(err):
+		ctx.Error(422, "", err)
+	default:
+		ctx.Error(500, "IssueField", err)
+	}
+	return true
+}
+
+// AddIssueField add the custom issue field specified by `form`, `ownerID` and `repoID`.
+// Writes to `ctx` accordingly
+func AddIssueField(ctx *context.APIContext, form *api.CreateIssueFieldOption, ownerID, repoID int64) {
+	fieldType, ok := models.ParseIssueFieldType(form.Type)
+	if !ok {
+		ctx.Error(422, "", "Invalid field type")
+		return
+	}
+	field := &models.IssueField{
+		OwnerID:     ownerID,
+		RepoID:      repoID,
+		Name:        form.Name,
+		Description: form.Description,
+		Type:        fieldType,
+		Options:     form.Options,
+	}
+	if handleIssueFieldError(ctx, models.NewIssueField(field)) {
+		return
+	}
+	ctx.JSON(http.StatusCreated, field.APIFormat())
+}
+
+// EditIssueField edit the custom issue field `field` according to `form`. Writes to `ctx` accordingly
+func EditIssueField(ctx *context.APIContext, form *api.EditIssueFieldOption, field *models.IssueField) {
+	if len(form.Name) > 0 {
+		field.Name = form.Name
+	}
+	if form.Description != nil {
+		field.Description = *form.Description
+	}
+	if form.Options != nil {
+		field.Options = form.Options
+	}
+	if handleIssueFieldError(ctx, models.UpdateIssueField(field)) {
+		return
+	}
+	ctx.JSON(200, field.APIFormat())
+}
+
+// DeleteIssueField delete the custom issue field `field` and its values. Writes to `ctx` accordingly
+func DeleteIssueField(ctx *context.APIContext, field *models.IssueField) {
+	if err := models.DeleteIssueField(field.ID); err != nil {
+		ctx.Error(500, "DeleteIssueField", err)
+		return
+	}
+	ctx.Status(204)
+}
@@ -4,7 +4,11 @@
 
 package utils
 
-import "github.com/masoodkamyab/gitea/modules/context"
+import (
+	"time"
+
+	"github.com/masoodkamyab/gitea/modules/context"
+)
 
 // UserID user ID of authenticated user, or 0 if not authenticated
 func UserID(ctx *context.APIContext) int64 {
@@ -13,3 +17,23 @@ func UserID(ctx *context.APIContext) int64 {
 	}
 	return ctx.User.ID
 }
+
+// GetQueryBeforeSince returns the `before` and `since` date-time query parameters as unix timestamps,
+// a parameter that is not given is zero
+func GetQueryBeforeSince(ctx *context.APIContext) (before, since int64, err error) {
+	if qBefore := ctx.Query("before"); len(qBefore) > 0 {
+		t, err := time.Parse(time.RFC3339, qBefore)
+		if err != nil {
+			return 0, 0, err
+		}
+		before = t.Unix()
+	}
+	if qSince := ctx.Query("since"); len(qSince) > 0 {
+		t, err := time.Parse(time.RFC3339, qSince)
+		if err != nil {
+			return 0, 0, err
+		}
+		since = t.Unix()
+	}
+	return before, since, nil
+}
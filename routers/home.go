@@ -48,6 +48,9 @@ func Home(ctx *context.Context) {
 			ctx.SetCookie("redirect_to", setting.AppSubURL+ctx.Req.RequestURI, 0, setting.AppSubURL)
 			ctx.Redirect(setting.AppSubURL + "/user/settings/change_password")
 		} else {
+			if setting.TwoFactorRequired && !context.CheckTwoFactorRequired(ctx) {
+				return
+			}
 			user.Dashboard(ctx)
 		}
 		return
@@ -90,6 +90,10 @@ func GlobalInit() {
 			log.Fatal("Failed to initialize OAuth2 support: %v", err)
 		}
 
+		if err := models.InitSAML(); err != nil {
+			log.Fatal("Failed to initialize SAML support: %v", err)
+		}
+
 		models.NewRepoContext()
 
 		// Booting long running goroutines.
@@ -113,5 +117,9 @@ func GlobalInit() {
 	if setting.InstallLock && setting.SSH.StartBuiltinServer {
 		ssh.Listen(setting.SSH.ListenHost, setting.SSH.ListenPort, setting.SSH.ServerCiphers, setting.SSH.ServerKeyExchanges, setting.SSH.ServerMACs)
 		log.Info("SSH server started on %s:%d. Cipher list (%v), key exchange algorithms (%v), MACs (%v)", setting.SSH.ListenHost, setting.SSH.ListenPort, setting.SSH.ServerCiphers, setting.SSH.ServerKeyExchanges, setting.SSH.ServerMACs)
+	} else if setting.InstallLock && !setting.SSH.Disabled {
+		if err := models.RewriteTrustedUserCAKeys(); err != nil {
+			log.Error("Failed to write trusted SSH user CA keys to %s: %v", setting.SSH.TrustedUserCAKeysFile, err)
+		}
 	}
 }
@@ -31,6 +31,15 @@ func Members(ctx *context.Context) {
 	}
 	ctx.Data["Members"] = org.Members
 
+	if ctx.Org.IsOwner {
+		status, err := models.GetTwoFactorStatus(org.Members)
+		if err != nil {
+			ctx.ServerError("GetTwoFactorStatus", err)
+			return
+		}
+		ctx.Data["MembersTwoFactor"] = status
+	}
+
 	ctx.HTML(200, tplMembers)
 }
 
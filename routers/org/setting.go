@@ -25,6 +25,8 @@ const (
 	tplSettingsDelete base.TplName = "org/settings/delete"
 	// tplSettingsHooks template path for render hook settings
 	tplSettingsHooks base.TplName = "org/settings/hooks"
+	// tplSettingsAudit template path for render the audit log
+	tplSettingsAudit base.TplName = "org/settings/audit"
 )
 
 // Settings render the main settings page
@@ -48,6 +50,18 @@ func SettingsPost(ctx *context.Context, form auth.UpdateOrgSettingForm) {
 
 	org := ctx.Org.Organization
 
+	if form.RequireTwoFactor && !org.RequireTwoFactor {
+		// Owners enabling the policy must comply with it
+		enrolled, err := ctx.User.IsTwoFactorEnrolled()
+		if err != nil {
+			ctx.ServerError("IsTwoFactorEnrolled", err)
+			return
+		} else if !enrolled {
+			ctx.RenderWithErr(ctx.Tr("org.settings.require_twofa_not_enrolled"), tplSettingsOptions, &form)
+			return
+		}
+	}
+
 	// Check if organization name has been changed.
 	if org.LowerName != strings.ToLower(form.Name) {
 		isExist, err := models.IsUserExist(org.ID, form.Name)
@@ -84,6 +98,9 @@ func SettingsPost(ctx *context.Context, form auth.UpdateOrgSettingForm) {
 	org.Website = form.Website
 	org.Location = form.Location
 	org.Visibility = form.Visibility
+
+	org.SetRequireTwoFactor(form.RequireTwoFactor)
+
 	if err := models.UpdateUser(org); err != nil {
 		ctx.ServerError("UpdateUser", err)
 		return
@@ -176,3 +193,36 @@ func DeleteWebhook(ctx *context.Context) {
 		"redirect": ctx.Org.OrgLink + "/settings/hooks",
 	})
 }
+
+// AuditEvents render the audit log of the teams and repositories of the
+// organization
+func AuditEvents(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("org.settings")
+	ctx.Data["PageIsSettingsAudit"] = true
+
+	page := ctx.QueryInt("page")
+	if page <= 1 {
+		page = 1
+	}
+
+	opts := &models.FindAuditEventsOptions{
+		OwnerID:  ctx.Org.Organization.ID,
+		Page:     page,
+		PageSize: setting.UI.Admin.NoticePagingNum,
+	}
+	total, err := models.CountAuditEvents(opts)
+	if err != nil {
+		ctx.ServerError("CountAuditEvents", err)
+		return
+	}
+	events, err := models.FindAuditEvents(opts)
+	if err != nil {
+		ctx.ServerError("FindAuditEvents", err)
+		return
+	}
+	ctx.Data["AuditEvents"] = events
+	ctx.Data["Total"] = total
+	ctx.Data["Page"] = context.NewPagination(int(total), setting.UI.Admin.NoticePagingNum, page, 5)
+
+	ctx.HTML(200, tplSettingsAudit)
+}
@@ -57,6 +57,8 @@ func TeamsAction(ctx *context.Context) {
 
 	page := ctx.Query("page")
 	var err error
+	var auditAction models.AuditAction
+	memberID := ctx.User.ID
 	switch ctx.Params(":action") {
 	case "join":
 		if !ctx.Org.IsOwner {
@@ -64,14 +66,17 @@ func TeamsAction(ctx *context.Context) {
 			return
 		}
 		err = ctx.Org.Team.AddMember(ctx.User.ID)
+		auditAction = models.AuditOrgTeamMemberAdd
 	case "leave":
 		err = ctx.Org.Team.RemoveMember(ctx.User.ID)
+		auditAction = models.AuditOrgTeamMemberRemove
 	case "remove":
 		if !ctx.Org.IsOwner {
 			ctx.Error(404)
 			return
 		}
 		err = ctx.Org.Team.RemoveMember(uid)
+		auditAction, memberID = models.AuditOrgTeamMemberRemove, uid
 		page = "team"
 	case "add":
 		if !ctx.Org.IsOwner {
@@ -101,11 +106,16 @@ func TeamsAction(ctx *context.Context) {
 			ctx.Flash.Error(ctx.Tr("org.teams.add_duplicate_users"))
 		} else {
 			err = ctx.Org.Team.AddMember(u.ID)
+			auditAction, memberID = models.AuditOrgTeamMemberAdd, u.ID
 		}
 
 		page = "team"
 	}
 
+	if err == nil && auditAction > 0 {
+		auditTeamMember(ctx, auditAction, memberID)
+	}
+
 	if err != nil {
 		if models.IsErrLastOrgOwner(err) {
 			ctx.Flash.Error(ctx.Tr("form.last_org_owner"))
@@ -129,6 +139,21 @@ func TeamsAction(ctx *context.Context) {
 	}
 }
 
+// auditTeamMember records the change of the membership of the user in the team
+// in the audit log
+func auditTeamMember(ctx *context.Context, action models.AuditAction, uid int64) {
+	u, err := models.GetUserByID(uid)
+	if err != nil {
+		log.Error("GetUserByID: %v", err)
+		return
+	}
+	change := models.AuditChange{New: u.Name}
+	if action == models.AuditOrgTeamMemberRemove {
+		change = models.AuditChange{Old: u.Name}
+	}
+	ctx.Audit(models.NewTeamAuditEvent(action, ctx.Org.Team, models.AuditDiff{"member": change}))
+}
+
 // TeamsRepoAction operate team's repository
 func TeamsRepoAction(ctx *context.Context) {
 	if !ctx.Org.IsOwner {
@@ -151,9 +176,25 @@ func TeamsRepoAction(ctx *context.Context) {
 			ctx.ServerError("GetRepositoryByName", err)
 			return
 		}
-		err = ctx.Org.Team.AddRepository(repo)
+		if !ctx.Org.Team.HasRepository(repo.ID) {
+			if err = ctx.Org.Team.AddRepository(repo); err == nil {
+				ctx.Audit(models.NewTeamAuditEvent(models.AuditOrgTeamRepoAdd, ctx.Org.Team, models.AuditDiff{
+					"repository": {New: repo.Name},
+				}))
+			}
+		}
 	case "remove":
-		err = ctx.Org.Team.RemoveRepository(com.StrTo(ctx.Query("repoid")).MustInt64())
+		repoID := com.StrTo(ctx.Query("repoid")).MustInt64()
+		if ctx.Org.Team.HasRepository(repoID) {
+			var repo *models.Repository
+			if repo, err = models.GetRepositoryByID(repoID); err == nil {
+				if err = ctx.Org.Team.RemoveRepository(repoID); err == nil {
+					ctx.Audit(models.NewTeamAuditEvent(models.AuditOrgTeamRepoRemove, ctx.Org.Team, models.AuditDiff{
+						"repository": {Old: repo.Name},
+					}))
+				}
+			}
+		}
 	}
 
 	if err != nil {
@@ -221,6 +262,7 @@ func NewTeamPost(ctx *context.Context, form auth.CreateTeamForm) {
 		}
 		return
 	}
+	ctx.Audit(models.NewTeamAuditEvent(models.AuditOrgTeamCreate, t, models.NewAuditDiff(nil, t.AuditFields())))
 	log.Trace("Team created: %s/%s", ctx.Org.Organization.Name, t.Name)
 	ctx.Redirect(ctx.Org.OrgLink + "/teams/" + t.LowerName)
 }
@@ -266,6 +308,7 @@ func EditTeamPost(ctx *context.Context, form auth.CreateTeamForm) {
 	ctx.Data["PageIsOrgTeams"] = true
 	ctx.Data["Team"] = t
 	ctx.Data["Units"] = models.Units
+	before := t.AuditFields()
 
 	isAuthChanged := false
 	if !t.IsOwnerTeam() {
@@ -315,14 +358,19 @@ func EditTeamPost(ctx *context.Context, form auth.CreateTeamForm) {
 		}
 		return
 	}
+	if diff := models.NewAuditDiff(before, t.AuditFields()); len(diff) > 0 {
+		ctx.Audit(models.NewTeamAuditEvent(models.AuditOrgTeamUpdate, t, diff))
+	}
 	ctx.Redirect(ctx.Org.OrgLink + "/teams/" + t.LowerName)
 }
 
 // DeleteTeam response for the delete team request
 func DeleteTeam(ctx *context.Context) {
+	before := ctx.Org.Team.AuditFields()
 	if err := models.DeleteTeam(ctx.Org.Team); err != nil {
 		ctx.Flash.Error("DeleteTeam: " + err.Error())
 	} else {
+		ctx.Audit(models.NewTeamAuditEvent(models.AuditOrgTeamDelete, ctx.Org.Team, models.NewAuditDiff(before, nil)))
 		ctx.Flash.Success(ctx.Tr("org.teams.delete_team_success"))
 	}
 
@@ -18,15 +18,42 @@ import (
 	macaron "gopkg.in/macaron.v1"
 )
 
+// certificateKeyName is the key name reported for sessions authenticated by an SSH certificate
+const certificateKeyName = "SSH certificate"
+
 // ServNoCommand returns information about the provided keyid
 func ServNoCommand(ctx *macaron.Context) {
 	keyID := ctx.ParamsInt64(":keyid")
+	certUserID := ctx.QueryInt64("cert_user")
+	results := private.KeyAndOwner{}
+
+	if certUserID > 0 {
+		user, err := models.GetUserByID(certUserID)
+		if err != nil {
+			if models.IsErrUserNotExist(err) {
+				ctx.JSON(http.StatusUnauthorized, map[string]interface{}{
+					"err": fmt.Sprintf("Cannot find user with id: %d for SSH certificate", certUserID),
+				})
+				return
+			}
+			log.Error("Unable to get user with id: %d for SSH certificate Error: %v", certUserID, err)
+			ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
+				"err": err.Error(),
+			})
+			return
+		}
+		results.Key = &models.PublicKey{OwnerID: user.ID, Name: certificateKeyName, Type: models.KeyTypeUser}
+		results.Owner = user
+		ctx.JSON(http.StatusOK, &results)
+		return
+	}
+
 	if keyID <= 0 {
 		ctx.JSON(http.StatusBadRequest, map[string]interface{}{
 			"err": fmt.Sprintf("Bad key id: %d", keyID),
 		})
+		return
 	}
-	results := private.KeyAndOwner{}
 
 	key, err := models.GetPublicKeyByID(keyID)
 	if err != nil {
@@ -68,6 +95,7 @@ func ServNoCommand(ctx *macaron.Context) {
 func ServCommand(ctx *macaron.Context) {
 	// Although we provide the verbs we don't need them at present they're just for logging purposes
 	keyID := ctx.ParamsInt64(":keyid")
+	certUserID := ctx.QueryInt64("cert_user")
 	ownerName := ctx.Params(":owner")
 	repoName := ctx.Params(":repo")
 	mode := models.AccessMode(ctx.QueryInt("mode"))
@@ -129,8 +157,14 @@ func ServCommand(ctx *macaron.Context) {
 		return
 	}
 
-	// Get the Public Key represented by the keyID
-	key, err := models.GetPublicKeyByID(keyID)
+	// Get the Public Key represented by the keyID, sessions authenticated
+	// by an SSH certificate have no stored key and act as the certificate user
+	var key *models.PublicKey
+	if certUserID > 0 {
+		key = &models.PublicKey{OwnerID: certUserID, Name: certificateKeyName, Type: models.KeyTypeUser}
+	} else {
+		key, err = models.GetPublicKeyByID(keyID)
+	}
 	if err != nil {
 		if models.IsErrKeyNotExist(err) {
 			ctx.JSON(http.StatusUnauthorized, map[string]interface{}{
@@ -208,6 +242,24 @@ func ServCommand(ctx *macaron.Context) {
 			return
 		}
 		results.UserName = user.Name
+
+		blocked, err := models.IsSiteTwoFactorBlocked(user)
+		if err != nil {
+			log.Error("Unable to check the two-factor authentication of %-v Error: %v", user, err)
+			ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
+				"results": results,
+				"type":    "InternalServerError",
+				"err":     fmt.Sprintf("Unable to check the two-factor authentication of user %d:%s Error: %v", user.ID, user.Name, err),
+			})
+			return
+		} else if blocked {
+			ctx.JSON(http.StatusUnauthorized, map[string]interface{}{
+				"results": results,
+				"type":    "ErrTwoFactorRequired",
+				"err":     fmt.Sprintf("User: %d:%s must enable two-factor authentication to %s %s/%s.", user.ID, user.Name, modeString, results.OwnerName, results.RepoName),
+			})
+			return
+		}
 	}
 
 	// Don't allow pushing if the repo is archived
@@ -331,6 +331,9 @@ func CompareDiff(ctx *context.Context) {
 	ctx.Data["RequireTribute"] = true
 	ctx.Data["PullRequestWorkInProgressPrefixes"] = setting.Repository.PullRequest.WorkInProgressPrefixes
 	setTemplateIfExists(ctx, pullRequestTemplateKey, pullRequestTemplateCandidates)
+	templates := getTemplatesFromDefaultBranch(ctx, context.PullRequestTemplateDirCandidates)
+	ctx.Data["PullRequestTemplates"] = templates
+	setTemplateFromQuery(ctx, pullRequestTemplateKey, templates)
 	renderAttachmentSettings(ctx)
 
 	ctx.HTML(200, tplCompare)
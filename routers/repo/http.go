@@ -124,6 +124,8 @@ func HTTP(ctx *context.Context) {
 	var (
 		askAuth      = !isPublicPull || setting.Service.RequireSignInView
 		authUser     *models.User
+		authScope    models.AccessTokenScope
+		isToken      bool
 		authUsername string
 		authPasswd   string
 		environ      []string
@@ -169,9 +171,11 @@ func HTTP(ctx *context.Context) {
 				// Assume password is token
 				authToken = authPasswd
 			}
-			uid := auth.CheckOAuthAccessToken(authToken)
+			uid, scope := auth.CheckOAuthAccessToken(authToken)
 			if uid != 0 {
 				ctx.Data["IsApiToken"] = true
+				authScope = scope
+				isToken = true
 
 				authUser, err = models.GetUserByID(uid)
 				if err != nil {
@@ -207,6 +211,8 @@ func HTTP(ctx *context.Context) {
 				if err = models.UpdateAccessToken(token); err != nil {
 					ctx.ServerError("UpdateAccessToken", err)
 				}
+				authScope = token.Scope
+				isToken = true
 			} else if !models.IsErrAccessTokenNotExist(err) && !models.IsErrAccessTokenEmpty(err) {
 				log.Error("GetAccessTokenBySha: %v", err)
 			}
@@ -241,6 +247,15 @@ func HTTP(ctx *context.Context) {
 			}
 		}
 
+		blocked, err := models.IsSiteTwoFactorBlocked(authUser)
+		if err != nil {
+			ctx.ServerError("IsSiteTwoFactorBlocked", err)
+			return
+		} else if blocked {
+			ctx.HandleText(http.StatusForbidden, "Two-factor authentication must be enabled")
+			return
+		}
+
 		perm, err := models.GetUserRepoPermission(repo, authUser)
 		if err != nil {
 			ctx.ServerError("GetUserRepoPermission", err)
@@ -252,6 +267,16 @@ func HTTP(ctx *context.Context) {
 			return
 		}
 
+		// an access token needs the scope to read or write repositories
+		requiredScope := models.AccessTokenScopeRepoWrite
+		if isPull {
+			requiredScope = models.AccessTokenScopeRepoRead
+		}
+		if isToken && !authScope.Has(requiredScope) {
+			ctx.HandleText(http.StatusForbidden, fmt.Sprintf("token does not have the %s scope", requiredScope))
+			return
+		}
+
 		if !isPull && repo.IsMirror {
 			ctx.HandleText(http.StatusForbidden, "mirror repository is read-only")
 			return
@@ -23,6 +23,7 @@ import (
 	"github.com/masoodkamyab/gitea/modules/log"
 	"github.com/masoodkamyab/gitea/modules/markup/markdown"
 	"github.com/masoodkamyab/gitea/modules/notification"
+	pull_service "github.com/masoodkamyab/gitea/modules/pull"
 	"github.com/masoodkamyab/gitea/modules/setting"
 	api "github.com/masoodkamyab/gitea/modules/structs"
 	"github.com/masoodkamyab/gitea/modules/util"
@@ -31,9 +32,10 @@ import (
 )
 
 const (
-	tplIssues    base.TplName = "repo/issue/list"
-	tplIssueNew  base.TplName = "repo/issue/new"
-	tplIssueView base.TplName = "repo/issue/view"
+	tplIssues      base.TplName = "repo/issue/list"
+	tplIssueNew    base.TplName = "repo/issue/new"
+	tplIssueChoose base.TplName = "repo/issue/choose"
+	tplIssueView   base.TplName = "repo/issue/view"
 
 	tplReactions base.TplName = "repo/issue/view_content/reactions"
 
@@ -144,34 +146,48 @@ func issues(ctx *context.Context, milestoneID int64, isPullOption util.OptionalB
 		keyword = ""
 	}
 
-	var issueIDs []int64
-	if len(keyword) > 0 {
-		issueIDs, err = issue_indexer.SearchIssuesByKeyword(repo.ID, keyword)
+	opts := &models.IssuesOptions{
+		RepoIDs:     []int64{repo.ID},
+		AssigneeID:  assigneeID,
+		PosterID:    posterID,
+		MentionedID: mentionedID,
+		MilestoneID: milestoneID,
+		IsPull:      isPullOption,
+		LabelIDs:    labelIDs,
+		SortType:    sortType,
+	}
+	query := models.ParseIssueQuery(keyword)
+	matchable, err := query.Apply(opts, ctx.User)
+	if err != nil {
+		ctx.ServerError("IssueQuery.Apply", err)
+		return
+	}
+	forceEmpty = !matchable
+	if matchable && len(query.Keyword) > 0 {
+		opts.IssueIDs, err = issue_indexer.SearchIssuesByKeyword(opts.RepoIDs, query.Keyword)
 		if err != nil {
 			ctx.ServerError("issueIndexer.Search", err)
 			return
 		}
-		if len(issueIDs) == 0 {
+		if len(opts.IssueIDs) == 0 {
 			forceEmpty = true
 		}
 	}
+	if !opts.IsClosed.IsNone() {
+		isShowClosed = opts.IsClosed.IsTrue()
+	}
 
-	var issueStats *models.IssueStats
-	if forceEmpty {
-		issueStats = &models.IssueStats{}
-	} else {
-		issueStats, err = models.GetIssueStats(&models.IssueStatsOptions{
-			RepoID:      repo.ID,
-			Labels:      selectLabels,
-			MilestoneID: milestoneID,
-			AssigneeID:  assigneeID,
-			MentionedID: mentionedID,
-			PosterID:    posterID,
-			IsPull:      isPullOption,
-			IssueIDs:    issueIDs,
-		})
-		if err != nil {
-			ctx.ServerError("GetIssueStats", err)
+	issueStats := &models.IssueStats{}
+	if !forceEmpty {
+		statsOpts := *opts
+		statsOpts.IsClosed = util.OptionalBoolFalse
+		if issueStats.OpenCount, err = models.CountIssues(&statsOpts); err != nil {
+			ctx.ServerError("CountIssues", err)
+			return
+		}
+		statsOpts.IsClosed = util.OptionalBoolTrue
+		if issueStats.ClosedCount, err = models.CountIssues(&statsOpts); err != nil {
+			ctx.ServerError("CountIssues", err)
 			return
 		}
 	}
@@ -192,20 +208,10 @@ func issues(ctx *context.Context, milestoneID int64, isPullOption util.OptionalB
 	if forceEmpty {
 		issues = []*models.Issue{}
 	} else {
-		issues, err = models.Issues(&models.IssuesOptions{
-			RepoIDs:     []int64{repo.ID},
-			AssigneeID:  assigneeID,
-			PosterID:    posterID,
-			MentionedID: mentionedID,
-			MilestoneID: milestoneID,
-			Page:        pager.Paginater.Current(),
-			PageSize:    setting.UI.IssuePagingNum,
-			IsClosed:    util.OptionalBoolOf(isShowClosed),
-			IsPull:      isPullOption,
-			LabelIDs:    labelIDs,
-			SortType:    sortType,
-			IssueIDs:    issueIDs,
-		})
+		opts.Page = pager.Paginater.Current()
+		opts.PageSize = setting.UI.IssuePagingNum
+		opts.IsClosed = util.OptionalBoolOf(isShowClosed)
+		issues, err = models.Issues(opts)
 		if err != nil {
 			ctx.ServerError("Issues", err)
 			return
@@ -319,9 +325,52 @@ func Issues(ctx *context.Context) {
 	}
 	ctx.Data["CanWriteIssuesOrPulls"] = perm.CanWriteIssuesOrPulls(isPullList)
 
+	if perm.CanWriteIssuesOrPulls(isPullList) {
+		retrieveBulkEditProjects(ctx, perm)
+		if ctx.Written() {
+			return
+		}
+		ctx.Data["LockReasons"] = setting.Repository.Issue.LockReasons
+	}
+
 	ctx.HTML(200, tplIssues)
 }
 
+// retrieveBulkEditProjects finds the open projects which the issues of the repository can be added to
+func retrieveBulkEditProjects(ctx *context.Context, perm models.Permission) {
+	var projects []*models.Project
+	if perm.CanWrite(models.UnitTypeProjects) {
+		repoProjects, _, err := models.GetProjects(&models.ProjectSearchOptions{
+			RepoID:   ctx.Repo.Repository.ID,
+			IsClosed: util.OptionalBoolFalse,
+		})
+		if err != nil {
+			ctx.ServerError("GetProjects", err)
+			return
+		}
+		projects = append(projects, repoProjects...)
+	}
+	if ctx.Repo.Owner.IsOrganization() {
+		mode, err := models.OrgProjectsAccessMode(ctx.Repo.Owner, ctx.User)
+		if err != nil {
+			ctx.ServerError("OrgProjectsAccessMode", err)
+			return
+		}
+		if mode >= models.AccessModeWrite {
+			orgProjects, _, err := models.GetProjects(&models.ProjectSearchOptions{
+				OwnerID:  ctx.Repo.Owner.ID,
+				IsClosed: util.OptionalBoolFalse,
+			})
+			if err != nil {
+				ctx.ServerError("GetProjects", err)
+				return
+			}
+			projects = append(projects, orgProjects...)
+		}
+	}
+	ctx.Data["Projects"] = projects
+}
+
 // RetrieveRepoMilestonesAndAssignees find all the milestones and assignees of a repository
 func RetrieveRepoMilestonesAndAssignees(ctx *context.Context, repo *models.Repository) {
 	var err error
@@ -341,6 +390,7 @@ func RetrieveRepoMilestonesAndAssignees(ctx *context.Context, repo *models.Repos
 		ctx.ServerError("GetAssignees", err)
 		return
 	}
+	ctx.Data["SelectedAssignees"] = map[int64]bool{}
 }
 
 // RetrieveRepoMetas find all the meta information of a repository
@@ -414,6 +464,84 @@ func setTemplateIfExists(ctx *context.Context, ctxDataKey string, possibleFiles
 	}
 }
 
+func getTemplatesFromDefaultBranch(ctx *context.Context, dirs []string) []*api.IssueTemplate {
+	if ctx.Repo.Repository.IsEmpty {
+		return nil
+	}
+	templates, err := ctx.Repo.GetTemplatesFromDefaultBranch(dirs)
+	if err != nil {
+		log.Error("GetTemplatesFromDefaultBranch: %v", err)
+		return nil
+	}
+	return templates
+}
+
+// setTemplateFromQuery fills the new issue or pull request form from the template named
+// by the template query parameter, the labels and assignees must have been retrieved before
+func setTemplateFromQuery(ctx *context.Context, ctxDataKey string, templates []*api.IssueTemplate) {
+	fileName := ctx.Query("template")
+	if len(fileName) == 0 {
+		return
+	}
+
+	var template *api.IssueTemplate
+	for _, t := range templates {
+		if t.FileName == fileName {
+			template = t
+			break
+		}
+	}
+	if template == nil {
+		return
+	}
+
+	ctx.Data["TemplateFileName"] = template.FileName
+	ctx.Data[ctxDataKey] = template.Content
+	if title, _ := ctx.Data["title"].(string); !strings.HasPrefix(title, template.Title) {
+		ctx.Data["title"] = template.Title + title
+	}
+
+	labels, _ := ctx.Data["Labels"].([]*models.Label)
+	labelIDs := make([]string, 0, len(template.Labels))
+	for _, label := range labels {
+		if com.IsSliceContainsStr(template.Labels, label.Name) {
+			label.IsChecked = true
+			labelIDs = append(labelIDs, com.ToStr(label.ID))
+		}
+	}
+	ctx.Data["HasSelectedLabel"] = len(labelIDs) > 0
+	ctx.Data["label_ids"] = strings.Join(labelIDs, ",")
+
+	assignees, _ := ctx.Data["Assignees"].([]*models.User)
+	assigneeIDs := make([]string, 0, len(template.Assignees))
+	selectedAssignees := make(map[int64]bool, len(template.Assignees))
+	for _, assignee := range assignees {
+		if com.IsSliceContainsStr(template.Assignees, assignee.Name) {
+			selectedAssignees[assignee.ID] = true
+			assigneeIDs = append(assigneeIDs, com.ToStr(assignee.ID))
+		}
+	}
+	ctx.Data["SelectedAssignees"] = selectedAssignees
+	ctx.Data["HasSelectedAssignee"] = len(assigneeIDs) > 0
+	ctx.Data["assignee_ids"] = strings.Join(assigneeIDs, ",")
+}
+
+// NewIssueChooseTemplate render choosing issue template page
+func NewIssueChooseTemplate(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.issues.new")
+	ctx.Data["PageIsIssueList"] = true
+
+	templates := getTemplatesFromDefaultBranch(ctx, context.IssueTemplateDirCandidates)
+	if len(templates) == 0 {
+		ctx.Redirect(ctx.Repo.RepoLink + "/issues/new?" + ctx.Req.URL.RawQuery)
+		return
+	}
+	ctx.Data["IssueTemplates"] = templates
+	ctx.Data["milestone"] = ctx.QueryInt64("milestone")
+
+	ctx.HTML(200, tplIssueChoose)
+}
+
 // NewIssue render creating issue page
 func NewIssue(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("repo.issues.new")
@@ -443,6 +571,7 @@ func NewIssue(ctx *context.Context) {
 	if ctx.Written() {
 		return
 	}
+	setTemplateFromQuery(ctx, issueTemplateKey, getTemplatesFromDefaultBranch(ctx, context.IssueTemplateDirCandidates))
 
 	ctx.HTML(200, tplIssueNew)
 }
@@ -608,6 +737,9 @@ func ViewIssue(ctx *context.Context) {
 	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
 	if err != nil {
 		if models.IsErrIssueNotExist(err) {
+			if redirectTransferredIssue(ctx, ctx.ParamsInt64(":index")) {
+				return
+			}
 			ctx.NotFound("GetIssueByIndex", err)
 		} else {
 			ctx.ServerError("GetIssueByIndex", err)
@@ -921,6 +1053,8 @@ func ViewIssue(ctx *context.Context) {
 				ctx.Data["MergeStyle"] = models.MergeStyleRebaseMerge
 			} else if prConfig.AllowSquash {
 				ctx.Data["MergeStyle"] = models.MergeStyleSquash
+			} else if prConfig.AllowFastForwardOnly {
+				ctx.Data["MergeStyle"] = models.MergeStyleFastForwardOnly
 			} else {
 				ctx.Data["MergeStyle"] = ""
 			}
@@ -933,7 +1067,27 @@ func ViewIssue(ctx *context.Context) {
 			cnt := pull.ProtectedBranch.GetGrantedApprovalsCount(pull)
 			ctx.Data["IsBlockedByApprovals"] = pull.ProtectedBranch.RequiredApprovals > 0 && cnt < pull.ProtectedBranch.RequiredApprovals
 			ctx.Data["GrantedApprovals"] = cnt
+			ctx.Data["IsMergeQueueEnabled"] = pull.ProtectedBranch.EnableMergeQueue
 		}
+		ctx.Data["MergeQueuePosition"], err = pull.GetMergeQueuePosition()
+		if err != nil {
+			ctx.ServerError("GetMergeQueuePosition", err)
+			return
+		}
+		if ctx.IsSigned && !pull.HasMerged && !issue.IsClosed {
+			if err := pull.CheckUserAllowedToUpdate(ctx.User); err == nil {
+				behind, err := pull_service.CommitsBehind(pull)
+				if err != nil {
+					log.Error("CommitsBehind: %v", err)
+				}
+				ctx.Data["PullCommitsBehind"] = behind
+			} else if !models.IsErrNotAllowedToUpdate(err) && !models.IsErrErrPullRequestHeadRepoMissing(err) {
+				ctx.ServerError("CheckUserAllowedToUpdate", err)
+				return
+			}
+		}
+		ctx.Data["CanChangeDraft"] = ctx.IsSigned && !pull.HasMerged && !issue.IsClosed &&
+			(issue.IsPoster(ctx.User.ID) || ctx.Repo.CanWrite(models.UnitTypePullRequests))
 		ctx.Data["IsPullBranchDeletable"] = canDelete && pull.HeadRepo != nil && git.IsBranchExist(pull.HeadRepo.RepoPath(), pull.HeadBranch)
 
 		ctx.Data["PullReviewersWithType"], err = models.GetReviewersByPullID(issue.ID)
@@ -955,6 +1109,13 @@ func ViewIssue(ctx *context.Context) {
 		return
 	}
 
+	// Get the custom fields the issue can hold
+	ctx.Data["IssueFields"], err = models.GetIssueFieldsOfRepo(repo)
+	if err != nil {
+		ctx.ServerError("GetIssueFieldsOfRepo", err)
+		return
+	}
+
 	ctx.Data["Participants"] = participants
 	ctx.Data["NumParticipants"] = len(participants)
 	ctx.Data["Issue"] = issue
@@ -1222,6 +1383,27 @@ func NewComment(ctx *context.Context, form auth.CreateCommentForm) {
 		return
 	}
 
+	// `/estimate 3h` lines of writers set the estimate instead of being posted
+	if ctx.Repo.Repository.IsTimetrackerEnabled() && ctx.Repo.CanWriteIssuesOrPulls(issue.IsPull) {
+		content, estimate, err := models.ExtractEstimateQuickAction(form.Content)
+		if err != nil {
+			if models.IsErrInvalidEstimate(err) {
+				ctx.Flash.Error(ctx.Tr("repo.issues.estimate_invalid", err.(models.ErrInvalidEstimate).Estimate))
+				ctx.Redirect(issue.HTMLURL(), http.StatusSeeOther)
+			} else {
+				ctx.ServerError("ExtractEstimateQuickAction", err)
+			}
+			return
+		}
+		if estimate >= 0 {
+			if err = models.UpdateIssueEstimate(issue, estimate, ctx.User); err != nil {
+				ctx.ServerError("UpdateIssueEstimate", err)
+				return
+			}
+			form.Content = content
+		}
+	}
+
 	var comment *models.Comment
 	defer func() {
 		// Check if issue admin/poster changes the status of issue.
@@ -0,0 +1,153 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"strings"
+
+	"github.com/masoodkamyab/gitea/models"
+	"github.com/masoodkamyab/gitea/modules/auth"
+	"github.com/masoodkamyab/gitea/modules/base"
+	"github.com/masoodkamyab/gitea/modules/context"
+	"github.com/masoodkamyab/gitea/modules/notification"
+)
+
+// splitInt64s parses a comma separated list of IDs
+func splitInt64s(s string) ([]int64, error) {
+	s = strings.TrimSpace(s)
+	if len(s) == 0 {
+		return nil, nil
+	}
+	return base.StringsToInt64s(strings.Split(s, ","))
+}
+
+// parseBulkEditIssuesForm converts the form to the options of a bulk edit,
+// it returns false if the form is invalid
+func parseBulkEditIssuesForm(ctx *context.Context, form auth.BulkEditIssuesForm) (*models.BulkEditIssuesOptions, bool) {
+	opts := &models.BulkEditIssuesOptions{
+		AddProjectID:    form.AddProjectID,
+		RemoveProjectID: form.RemoveProjectID,
+		LockReason:      form.LockReason,
+	}
+	var err error
+	if opts.AddLabelIDs, err = splitInt64s(form.AddLabelIDs); err != nil {
+		return nil, false
+	}
+	if opts.RemoveLabelIDs, err = splitInt64s(form.RemoveLabelIDs); err != nil {
+		return nil, false
+	}
+	if opts.AddAssigneeIDs, err = splitInt64s(form.AddAssigneeIDs); err != nil {
+		return nil, false
+	}
+	if opts.RemoveAssigneeIDs, err = splitInt64s(form.RemoveAssigneeIDs); err != nil {
+		return nil, false
+	}
+	if len(form.MilestoneID) > 0 {
+		milestoneID, err := base.StringsToInt64s([]string{form.MilestoneID})
+		if err != nil {
+			return nil, false
+		}
+		opts.MilestoneID = &milestoneID[0]
+	}
+
+	if len(form.State) > 0 {
+		isClosed := form.State == "closed"
+		opts.IsClosed = &isClosed
+	}
+	if len(form.Lock) > 0 {
+		if !(auth.IssueLockForm{Reason: form.LockReason}).HasValidReason() {
+			return nil, false
+		}
+		isLocked := form.Lock == "lock"
+		opts.IsLocked = &isLocked
+	}
+
+	if newRepo := strings.TrimSpace(form.TransferTo); len(newRepo) > 0 {
+		parts := strings.SplitN(newRepo, "/", 2)
+		if len(parts) != 2 {
+			return nil, false
+		}
+		if opts.TransferTo, err = models.GetRepositoryByOwnerAndName(parts[0], parts[1]); err != nil {
+			if !models.IsErrRepoNotExist(err) {
+				ctx.ServerError("GetRepositoryByOwnerAndName", err)
+			}
+			return nil, false
+		}
+	}
+	return opts, true
+}
+
+// NotifyIssueChanges sends the notifications of the changes of a bulk edit
+func NotifyIssueChanges(doer *models.User, changes []*models.IssueChange) {
+	for _, change := range changes {
+		issue := change.Issue
+		if len(change.AddedLabels) > 0 || len(change.RemovedLabels) > 0 {
+			notification.NotifyIssueChangeLabels(doer, issue, change.AddedLabels, change.RemovedLabels)
+		}
+		if change.MilestoneChanged {
+			notification.NotifyIssueChangeMilestone(doer, issue)
+		}
+		for range change.AddedAssignees {
+			notification.NotifyIssueChangeAssignee(doer, issue, false)
+		}
+		for range change.RemovedAssignees {
+			notification.NotifyIssueChangeAssignee(doer, issue, true)
+		}
+		if change.StatusChanged {
+			notification.NotifyIssueChangeStatus(doer, issue, issue.IsClosed)
+		}
+		if change.TransferredFrom != nil {
+			notification.NotifyIssueTransfer(doer, issue, change.TransferredFrom)
+		}
+	}
+}
+
+// BulkEditIssues applies the same changes to all selected issues
+func BulkEditIssues(ctx *context.Context, form auth.BulkEditIssuesForm) {
+	issues := getActionIssues(ctx)
+	if ctx.Written() {
+		return
+	}
+	redirectTo := ctx.Repo.RepoLink + "/issues"
+
+	for _, issue := range issues {
+		if !ctx.Repo.CanWriteIssuesOrPulls(issue.IsPull) {
+			ctx.NotFound("CanWriteIssuesOrPulls", nil)
+			return
+		}
+	}
+
+	opts, ok := parseBulkEditIssuesForm(ctx, form)
+	if ctx.Written() {
+		return
+	} else if ctx.HasError() || !ok || len(issues) == 0 {
+		ctx.Flash.Error(ctx.Tr("repo.issues.bulk_edit.invalid"))
+		ctx.RedirectToFirst(form.RedirectTo, redirectTo)
+		return
+	}
+
+	changes, err := models.BulkEditIssues(ctx.User, ctx.Repo.Repository, issues, opts)
+	if err != nil {
+		switch {
+		case models.IsErrIssueBulkEditInvalid(err),
+			models.IsErrIssueCannotTransfer(err),
+			models.IsErrDependenciesLeft(err),
+			models.IsErrLabelNotExist(err),
+			models.IsErrMilestoneNotExist(err),
+			models.IsErrUserNotExist(err),
+			models.IsErrProjectNotExist(err),
+			models.IsErrProjectIssueNotAllowed(err):
+			ctx.Flash.Error(ctx.Tr("repo.issues.bulk_edit.invalid"))
+			ctx.RedirectToFirst(form.RedirectTo, redirectTo)
+		default:
+			ctx.ServerError("BulkEditIssues", err)
+		}
+		return
+	}
+	NotifyIssueChanges(ctx.User, changes)
+
+	ctx.Flash.Success(ctx.Tr("repo.issues.bulk_edit.success", len(changes)))
+	ctx.RedirectToFirst(form.RedirectTo, redirectTo)
+}
@@ -0,0 +1,137 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/masoodkamyab/gitea/models"
+	"github.com/masoodkamyab/gitea/modules/context"
+	issue_indexer "github.com/masoodkamyab/gitea/modules/indexer/issues"
+	"github.com/masoodkamyab/gitea/modules/util"
+)
+
+// ExportIssues downloads the issues or pull requests matching the search query as CSV,
+// with a column for every custom issue field of the repository
+func ExportIssues(ctx *context.Context) {
+	isPullList := ctx.Params(":type") == "pulls"
+	if isPullList {
+		MustAllowPulls(ctx)
+	} else {
+		MustEnableIssues(ctx)
+	}
+	if ctx.Written() {
+		return
+	}
+
+	repo := ctx.Repo.Repository
+	opts := &models.IssuesOptions{
+		RepoIDs:  []int64{repo.ID},
+		IsPull:   util.OptionalBoolOf(isPullList),
+		SortType: "oldest",
+	}
+	switch ctx.Query("state") {
+	case "open":
+		opts.IsClosed = util.OptionalBoolFalse
+	case "closed":
+		opts.IsClosed = util.OptionalBoolTrue
+	}
+
+	var issues []*models.Issue
+	keyword := strings.TrimSpace(ctx.Query("q"))
+	if strings.IndexByte(keyword, 0) >= 0 {
+		keyword = ""
+	}
+	query := models.ParseIssueQuery(keyword)
+	matchable, err := query.Apply(opts, ctx.User)
+	if err != nil {
+		ctx.ServerError("IssueQuery.Apply", err)
+		return
+	}
+	if matchable && len(query.Keyword) > 0 {
+		if opts.IssueIDs, err = issue_indexer.SearchIssuesByKeyword(opts.RepoIDs, query.Keyword); err != nil {
+			ctx.ServerError("SearchIssuesByKeyword", err)
+			return
+		}
+		matchable = len(opts.IssueIDs) > 0
+	}
+	if matchable {
+		if issues, err = models.Issues(opts); err != nil {
+			ctx.ServerError("Issues", err)
+			return
+		}
+	}
+
+	fields, err := models.GetIssueFieldsOfRepo(repo)
+	if err != nil {
+		ctx.ServerError("GetIssueFieldsOfRepo", err)
+		return
+	}
+
+	ctx.Resp.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	ctx.Resp.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-%s.csv"`, repo.Name, ctx.Params(":type")))
+	w := csv.NewWriter(ctx.Resp)
+	header := []string{"Index", "Title", "State", "Author", "Assignees", "Labels", "Milestone", "Created", "Updated", "Closed"}
+	for _, field := range fields {
+		header = append(header, field.Name)
+	}
+	if err = w.Write(header); err != nil {
+		ctx.ServerError("Write", err)
+		return
+	}
+
+	formatTime := func(t util.TimeStamp) string {
+		if t == 0 {
+			return ""
+		}
+		return t.AsTime().UTC().Format(time.RFC3339)
+	}
+	for _, issue := range issues {
+		assignees := make([]string, len(issue.Assignees))
+		for i, assignee := range issue.Assignees {
+			assignees[i] = assignee.Name
+		}
+		labels := make([]string, len(issue.Labels))
+		for i, label := range issue.Labels {
+			labels[i] = label.Name
+		}
+		milestone := ""
+		if issue.Milestone != nil {
+			milestone = issue.Milestone.Name
+		}
+		closed := ""
+		if issue.IsClosed {
+			closed = formatTime(issue.ClosedUnix)
+		}
+
+		record := []string{
+			strconv.FormatInt(issue.Index, 10),
+			issue.Title,
+			string(issue.State()),
+			issue.Poster.Name,
+			strings.Join(assignees, ", "),
+			strings.Join(labels, ", "),
+			milestone,
+			formatTime(issue.CreatedUnix),
+			formatTime(issue.UpdatedUnix),
+			closed,
+		}
+		for _, field := range fields {
+			record = append(record, strings.Join(issue.FieldValuesOf(field.ID), ", "))
+		}
+		if err = w.Write(record); err != nil {
+			ctx.ServerError("Write", err)
+			return
+		}
+	}
+	w.Flush()
+	if err = w.Error(); err != nil {
+		ctx.ServerError("Flush", err)
+	}
+}
@@ -0,0 +1,206 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	"github.com/masoodkamyab/gitea/models"
+	"github.com/masoodkamyab/gitea/modules/auth"
+	"github.com/masoodkamyab/gitea/modules/base"
+	"github.com/masoodkamyab/gitea/modules/context"
+)
+
+const (
+	tplSettingsIssueFields    base.TplName = "repo/settings/issue_fields/base"
+	tplOrgSettingsIssueFields base.TplName = "org/settings/issue_fields"
+)
+
+// issueFieldsCtx tells whether the custom issue fields of a repository or of an organization are managed
+type issueFieldsCtx struct {
+	OwnerID  int64
+	RepoID   int64
+	Link     string
+	Template base.TplName
+}
+
+func getIssueFieldsCtx(ctx *context.Context) *issueFieldsCtx {
+	if len(ctx.Repo.RepoLink) > 0 {
+		return &issueFieldsCtx{
+			RepoID:   ctx.Repo.Repository.ID,
+			Link:     ctx.Repo.RepoLink + "/settings/issue_fields",
+			Template: tplSettingsIssueFields,
+		}
+	}
+	return &issueFieldsCtx{
+		OwnerID:  ctx.Org.Organization.ID,
+		Link:     ctx.Org.OrgLink + "/settings/issue_fields",
+		Template: tplOrgSettingsIssueFields,
+	}
+}
+
+// getIssueField returns the field of the request, it must belong to the repository or organization being managed
+func (fc *issueFieldsCtx) getIssueField(ctx *context.Context, id int64) *models.IssueField {
+	field, err := models.GetIssueFieldByID(id)
+	if err != nil {
+		if models.IsErrIssueFieldNotExist(err) {
+			ctx.NotFound("GetIssueFieldByID", err)
+		} else {
+			ctx.ServerError("GetIssueFieldByID", err)
+		}
+		return nil
+	}
+	if field.OwnerID != fc.OwnerID || field.RepoID != fc.RepoID {
+		ctx.NotFound("GetIssueFieldByID", nil)
+		return nil
+	}
+	return field
+}
+
+func renderIssueFields(ctx *context.Context, fc *issueFieldsCtx) {
+	ctx.Data["Title"] = ctx.Tr("repo.settings.issue_fields")
+	ctx.Data["PageIsSettingsIssueFields"] = true
+	ctx.Data["BaseLink"] = fc.Link
+
+	var (
+		fields []*models.IssueField
+		err    error
+	)
+	if fc.RepoID > 0 {
+		fields, err = models.GetIssueFieldsByRepoID(fc.RepoID)
+	} else {
+		fields, err = models.GetIssueFieldsByOwnerID(fc.OwnerID)
+	}
+	if err != nil {
+		ctx.ServerError("GetIssueFields", err)
+		return
+	}
+	ctx.Data["IssueFields"] = fields
+	ctx.HTML(200, fc.Template)
+}
+
+// IssueFields render the custom issue fields of a repository or an organization
+func IssueFields(ctx *context.Context) {
+	renderIssueFields(ctx, getIssueFieldsCtx(ctx))
+}
+
+// handleIssueFieldError shows the errors of invalid field definitions and reports if there was an error
+func handleIssueFieldError(ctx *context.Context, fc *issueFieldsCtx, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case models.IsErrIssueFieldAlreadyExist(err):
+		ctx.Flash.Error(ctx.Tr("repo.settings.issue_fields.name_been_taken"))
+	case models.IsErrIssueFieldInvalid(err):
+		ctx.Flash.Error(ctx.Tr("repo.settings.issue_fields.invalid", err.(models.ErrIssueFieldInvalid).Reason))
+	default:
+		ctx.ServerError("IssueField", err)
+		return true
+	}
+	ctx.Redirect(fc.Link)
+	return true
+}
+
+// NewIssueFieldPost creates a custom issue field
+func NewIssueFieldPost(ctx *context.Context, form auth.IssueFieldForm) {
+	fc := getIssueFieldsCtx(ctx)
+	fieldType, ok := models.ParseIssueFieldType(form.Type)
+	if ctx.HasError() || !ok {
+		ctx.Flash.Error(ctx.Tr("repo.settings.issue_fields.invalid", form.Type))
+		ctx.Redirect(fc.Link)
+		return
+	}
+
+	field := &models.IssueField{
+		OwnerID:     fc.OwnerID,
+		RepoID:      fc.RepoID,
+		Name:        form.Name,
+		Description: form.Description,
+		Type:        fieldType,
+		Options:     form.OptionList(),
+	}
+	if handleIssueFieldError(ctx, fc, models.NewIssueField(field)) {
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("repo.settings.issue_fields.create_success", field.Name))
+	ctx.Redirect(fc.Link)
+}
+
+// EditIssueFieldPost updates the name, description and options of a custom issue field
+func EditIssueFieldPost(ctx *context.Context, form auth.IssueFieldForm) {
+	fc := getIssueFieldsCtx(ctx)
+	field := fc.getIssueField(ctx, ctx.ParamsInt64(":id"))
+	if ctx.Written() {
+		return
+	}
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.GetErrMsg())
+		ctx.Redirect(fc.Link)
+		return
+	}
+
+	field.Name = form.Name
+	field.Description = form.Description
+	field.Options = form.OptionList()
+	if handleIssueFieldError(ctx, fc, models.UpdateIssueField(field)) {
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("repo.settings.issue_fields.update_success", field.Name))
+	ctx.Redirect(fc.Link)
+}
+
+// DeleteIssueField deletes a custom issue field with its values
+func DeleteIssueField(ctx *context.Context) {
+	fc := getIssueFieldsCtx(ctx)
+	field := fc.getIssueField(ctx, ctx.QueryInt64("id"))
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.DeleteIssueField(field.ID); err != nil {
+		ctx.Flash.Error("DeleteIssueField: " + err.Error())
+	} else {
+		ctx.Flash.Success(ctx.Tr("repo.settings.issue_fields.deletion_success"))
+	}
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": fc.Link,
+	})
+}
+
+// UpdateIssueFieldValues sets the values of a custom field on an issue
+func UpdateIssueFieldValues(ctx *context.Context) {
+	issue := GetActionIssue(ctx)
+	if ctx.Written() {
+		return
+	}
+	if !ctx.Repo.CanWriteIssuesOrPulls(issue.IsPull) {
+		ctx.Error(403)
+		return
+	}
+
+	field, err := models.GetIssueFieldByID(ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrIssueFieldNotExist(err) {
+			ctx.NotFound("GetIssueFieldByID", err)
+		} else {
+			ctx.ServerError("GetIssueFieldByID", err)
+		}
+		return
+	}
+
+	if err = models.SetIssueFieldValues(issue, field, ctx.QueryStrings("values")); err != nil {
+		switch {
+		case models.IsErrIssueFieldNotExist(err):
+			ctx.NotFound("SetIssueFieldValues", err)
+			return
+		case models.IsErrIssueFieldValueInvalid(err):
+			ctx.Flash.Error(ctx.Tr("repo.issues.fields.invalid_value", field.Name))
+		default:
+			ctx.ServerError("SetIssueFieldValues", err)
+			return
+		}
+	}
+	ctx.Redirect(issue.HTMLURL(), http.StatusSeeOther)
+}
@@ -0,0 +1,27 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/masoodkamyab/gitea/models"
+	"github.com/masoodkamyab/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewIssueChooseTemplate(t *testing.T) {
+	models.PrepareTestEnv(t)
+	ctx := test.MockContext(t, "user2/repo1/issues/new/choose?milestone=1")
+	test.LoadUser(t, ctx, 2)
+	test.LoadRepo(t, ctx, 1)
+	test.LoadGitRepo(t, ctx)
+	NewIssueChooseTemplate(ctx)
+	// repo1 has no issue templates, the blank issue form is opened right away
+	assert.EqualValues(t, http.StatusFound, ctx.Resp.Status())
+	assert.Equal(t, "/user2/repo1/issues/new?milestone=1", test.RedirectURL(ctx.Resp))
+}
@@ -46,3 +46,30 @@ func AddTimeManually(c *context.Context, form auth.AddTimeManuallyForm) {
 
 	c.Redirect(url, http.StatusSeeOther)
 }
+
+// UpdateIssueEstimate sets or removes the estimate of an issue
+func UpdateIssueEstimate(c *context.Context) {
+	issue := GetActionIssue(c)
+	if c.Written() {
+		return
+	}
+	if !c.Repo.Repository.IsTimetrackerEnabled() {
+		c.NotFound("IsTimetrackerEnabled", nil)
+		return
+	}
+	url := issue.HTMLURL()
+
+	estimate, err := models.ParseEstimate(c.Query("estimate"))
+	if err != nil {
+		c.Flash.Error(c.Tr("repo.issues.estimate_invalid", c.Query("estimate")))
+		c.Redirect(url, http.StatusSeeOther)
+		return
+	}
+
+	if err = models.UpdateIssueEstimate(issue, estimate, c.User); err != nil {
+		c.ServerError("UpdateIssueEstimate", err)
+		return
+	}
+
+	c.Redirect(url, http.StatusSeeOther)
+}
@@ -0,0 +1,220 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"encoding/csv"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/masoodkamyab/gitea/models"
+	"github.com/masoodkamyab/gitea/modules/auth"
+	"github.com/masoodkamyab/gitea/modules/base"
+	"github.com/masoodkamyab/gitea/modules/context"
+	"github.com/masoodkamyab/gitea/modules/setting"
+)
+
+const (
+	tplTrackedTimes base.TplName = "repo/issue/times"
+
+	trackedTimesDateFormat = "2006-01-02"
+)
+
+// TrackedTimesReport renders the tracked times matching the options and the filters of the request
+// with the given template, or exports them as CSV or JSON if a format is requested
+func TrackedTimesReport(ctx *context.Context, opts models.FindTrackedTimesOptions, tpl base.TplName) {
+	groupBy := ctx.Query("group")
+	if !models.IsValidTrackedTimeGroupBy(groupBy) {
+		groupBy = models.TrackedTimeGroupByUser
+	}
+
+	since := ctx.Query("since")
+	if t, err := time.ParseInLocation(trackedTimesDateFormat, since, setting.UILocation); err == nil {
+		opts.CreatedAfterUnix = t.Unix()
+	} else {
+		since = ""
+	}
+	// the report includes the whole day the range ends with
+	until := ctx.Query("until")
+	if t, err := time.ParseInLocation(trackedTimesDateFormat, until, setting.UILocation); err == nil {
+		opts.CreatedBeforeUnix = t.AddDate(0, 0, 1).Unix()
+	} else {
+		until = ""
+	}
+
+	var (
+		report = &models.TrackedTimeReport{GroupBy: groupBy}
+		err    error
+	)
+	userName := ctx.Query("user")
+	if len(userName) > 0 && opts.UserID == 0 {
+		var u *models.User
+		if u, err = models.GetUserByName(userName); err != nil && !models.IsErrUserNotExist(err) {
+			ctx.ServerError("GetUserByName", err)
+			return
+		} else if u != nil {
+			opts.UserID = u.ID
+		}
+	}
+	// times of unknown users are never found
+	if len(userName) == 0 || opts.UserID != 0 {
+		if report, err = models.GetTrackedTimeReport(opts, groupBy); err != nil {
+			ctx.ServerError("GetTrackedTimeReport", err)
+			return
+		}
+	}
+
+	switch ctx.Query("format") {
+	case "csv":
+		exportTrackedTimesCSV(ctx, report)
+		return
+	case "json":
+		ctx.JSON(200, report.APIFormat())
+		return
+	}
+
+	ctx.Data["Report"] = report
+	ctx.Data["GroupBy"] = groupBy
+	ctx.Data["Since"] = since
+	ctx.Data["Until"] = until
+	ctx.Data["FilterUser"] = userName
+	ctx.Data["ExportLink"] = ctx.Link + "?" + url.Values{
+		"group": {groupBy},
+		"since": {since},
+		"until": {until},
+		"user":  {userName},
+	}.Encode()
+	ctx.HTML(200, tpl)
+}
+
+// exportTrackedTimesCSV writes one line per tracked time of the report
+func exportTrackedTimesCSV(ctx *context.Context, report *models.TrackedTimeReport) {
+	ctx.Resp.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	ctx.Resp.Header().Set("Content-Disposition", `attachment; filename="tracked-times.csv"`)
+	w := csv.NewWriter(ctx.Resp)
+	if err := w.Write([]string{"Date", "User", "Repository", "Issue", "Title", "Milestone", "Labels", "Seconds", "Hours"}); err != nil {
+		ctx.ServerError("Write", err)
+		return
+	}
+	for _, t := range report.Times {
+		milestone := ""
+		if t.Issue.Milestone != nil {
+			milestone = t.Issue.Milestone.Name
+		}
+		labels := make([]string, len(t.Issue.Labels))
+		for i, label := range t.Issue.Labels {
+			labels[i] = label.Name
+		}
+		if err := w.Write([]string{
+			t.Created.Format(time.RFC3339),
+			t.User.Name,
+			t.Issue.Repo.FullName(),
+			strconv.FormatInt(t.Issue.Index, 10),
+			t.Issue.Title,
+			milestone,
+			strings.Join(labels, ", "),
+			strconv.FormatInt(t.Time, 10),
+			strconv.FormatFloat(float64(t.Time)/3600, 'f', 2, 64),
+		}); err != nil {
+			ctx.ServerError("Write", err)
+			return
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		ctx.ServerError("Flush", err)
+	}
+}
+
+// TrackedTimes renders the time tracking report of a repository
+func TrackedTimes(ctx *context.Context) {
+	if !ctx.Repo.Repository.IsTimetrackerEnabled() {
+		ctx.NotFound("TrackedTimes", nil)
+		return
+	}
+	ctx.Data["Title"] = ctx.Tr("repo.times")
+	ctx.Data["PageIsTrackedTimes"] = true
+	ctx.Data["CanEditAllTrackedTimes"] = ctx.Repo.IsAdmin()
+	TrackedTimesReport(ctx, models.FindTrackedTimesOptions{RepositoryID: ctx.Repo.Repository.ID}, tplTrackedTimes)
+}
+
+// getActionTrackedTime returns the tracked time of the request if it belongs to the repository
+// and the doer may change it: repository administrators may change all times, users their own ones
+func getActionTrackedTime(ctx *context.Context) *models.TrackedTime {
+	t, err := models.GetTrackedTimeByID(ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrTrackedTimeNotExist(err) {
+			ctx.NotFound("GetTrackedTimeByID", err)
+		} else {
+			ctx.ServerError("GetTrackedTimeByID", err)
+		}
+		return nil
+	}
+	issue, err := models.GetIssueByID(t.IssueID)
+	if err != nil {
+		if models.IsErrIssueNotExist(err) {
+			ctx.NotFound("GetIssueByID", err)
+		} else {
+			ctx.ServerError("GetIssueByID", err)
+		}
+		return nil
+	}
+	if issue.RepoID != ctx.Repo.Repository.ID {
+		ctx.NotFound("GetTrackedTimeByID", nil)
+		return nil
+	}
+	if !ctx.Repo.IsAdmin() && (t.UserID != ctx.User.ID || !ctx.Repo.CanUseTimetracker(issue, ctx.User)) {
+		ctx.Error(403)
+		return nil
+	}
+	return t
+}
+
+// EditTrackedTime changes the amount of a tracked time
+func EditTrackedTime(ctx *context.Context, form auth.AddTimeManuallyForm) {
+	t := getActionTrackedTime(ctx)
+	if ctx.Written() {
+		return
+	}
+	redirectTo := ctx.Repo.RepoLink + "/times"
+
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.GetErrMsg())
+		ctx.RedirectToFirst(ctx.Query("redirect_to"), redirectTo)
+		return
+	}
+
+	total := time.Duration(form.Hours)*time.Hour + time.Duration(form.Minutes)*time.Minute
+	if total <= 0 {
+		ctx.Flash.Error(ctx.Tr("repo.issues.add_time_sum_to_small"))
+		ctx.RedirectToFirst(ctx.Query("redirect_to"), redirectTo)
+		return
+	}
+
+	t.Time = int64(total.Seconds())
+	if err := models.UpdateTrackedTime(t); err != nil {
+		ctx.ServerError("UpdateTrackedTime", err)
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("repo.times.update_success"))
+	ctx.RedirectToFirst(ctx.Query("redirect_to"), redirectTo)
+}
+
+// DeleteTrackedTime deletes a tracked time
+func DeleteTrackedTime(ctx *context.Context) {
+	t := getActionTrackedTime(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.DeleteTrackedTime(t); err != nil {
+		ctx.ServerError("DeleteTrackedTime", err)
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("repo.times.deletion_success"))
+	ctx.RedirectToFirst(ctx.Query("redirect_to"), ctx.Repo.RepoLink+"/times")
+}
@@ -0,0 +1,92 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/masoodkamyab/gitea/models"
+	"github.com/masoodkamyab/gitea/modules/auth"
+	"github.com/masoodkamyab/gitea/modules/context"
+	"github.com/masoodkamyab/gitea/modules/notification"
+)
+
+// TransferIssue moves an issue to another repository the user can write issues to
+func TransferIssue(ctx *context.Context, form auth.TransferIssueForm) {
+	issue := GetActionIssue(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if ctx.HasError() || issue.IsPull {
+		ctx.Flash.Error(ctx.Tr("repo.issues.transfer.invalid_repo"))
+		ctx.Redirect(issue.HTMLURL())
+		return
+	}
+
+	parts := strings.SplitN(strings.TrimSpace(form.NewRepo), "/", 2)
+	if len(parts) != 2 {
+		ctx.Flash.Error(ctx.Tr("repo.issues.transfer.invalid_repo"))
+		ctx.Redirect(issue.HTMLURL())
+		return
+	}
+	newRepo, err := models.GetRepositoryByOwnerAndName(parts[0], parts[1])
+	if err != nil {
+		if models.IsErrRepoNotExist(err) {
+			ctx.Flash.Error(ctx.Tr("repo.issues.transfer.invalid_repo"))
+			ctx.Redirect(issue.HTMLURL())
+		} else {
+			ctx.ServerError("GetRepositoryByOwnerAndName", err)
+		}
+		return
+	}
+
+	if canTransfer, err := models.CanTransferIssueTo(ctx.User, newRepo); err != nil {
+		ctx.ServerError("CanTransferIssueTo", err)
+		return
+	} else if !canTransfer || newRepo.ID == issue.RepoID {
+		// Do not reveal whether a private repository exists
+		ctx.Flash.Error(ctx.Tr("repo.issues.transfer.invalid_repo"))
+		ctx.Redirect(issue.HTMLURL())
+		return
+	}
+
+	oldRepo := issue.Repo
+	if err = models.TransferIssue(ctx.User, issue, newRepo); err != nil {
+		ctx.ServerError("TransferIssue", err)
+		return
+	}
+	notification.NotifyIssueTransfer(ctx.User, issue, oldRepo)
+
+	ctx.Flash.Success(ctx.Tr("repo.issues.transfer.success", newRepo.FullName()))
+	ctx.Redirect(issue.HTMLURL(), http.StatusSeeOther)
+}
+
+// redirectTransferredIssue redirects to the issue which was transferred away from the requested index,
+// it returns false if there is no such issue or the user cannot see it
+func redirectTransferredIssue(ctx *context.Context, index int64) bool {
+	issue, err := models.LookupIssueRedirect(ctx.Repo.Repository.ID, index)
+	if err != nil {
+		if !models.IsErrIssueNotExist(err) {
+			ctx.ServerError("LookupIssueRedirect", err)
+			return true
+		}
+		return false
+	}
+	if err = issue.LoadRepo(); err != nil {
+		ctx.ServerError("LoadRepo", err)
+		return true
+	}
+	perm, err := models.GetUserRepoPermission(issue.Repo, ctx.User)
+	if err != nil {
+		ctx.ServerError("GetUserRepoPermission", err)
+		return true
+	} else if !perm.CanRead(models.UnitTypeIssues) {
+		return false
+	}
+	ctx.Redirect(issue.HTMLURL(), http.StatusMovedPermanently)
+	return true
+}
@@ -60,6 +60,10 @@ func Milestones(ctx *context.Context) {
 			ctx.ServerError("LoadTotalTrackedTimes", err)
 			return
 		}
+		if err := miles.LoadTotalEstimatedTimes(); err != nil {
+			ctx.ServerError("LoadTotalEstimatedTimes", err)
+			return
+		}
 	}
 	for _, m := range miles {
 		m.RenderedContent = string(markdown.Render([]byte(m.Content), ctx.Repo.RepoLink, ctx.Repo.Repository.ComposeMetas()))
@@ -260,6 +264,16 @@ func MilestoneIssuesAndPulls(ctx *context.Context) {
 	ctx.Data["Title"] = milestone.Name
 	ctx.Data["Milestone"] = milestone
 
+	burndown, err := models.GetMilestoneBurndown(milestone)
+	if err != nil {
+		ctx.ServerError("GetMilestoneBurndown", err)
+		return
+	}
+	ctx.Data["Burndown"] = burndown
+	if len(burndown.Snapshots) > 0 {
+		ctx.Data["BurndownChart"] = newBurndownChart(burndown)
+	}
+
 	issues(ctx, milestoneID, util.OptionalBoolNone)
 
 	perm, err := models.GetUserRepoPermission(ctx.Repo.Repository, ctx.User)
@@ -0,0 +1,97 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/masoodkamyab/gitea/models"
+	"github.com/masoodkamyab/gitea/modules/util"
+)
+
+// Size of the burndown charts in SVG user units, the plot is surrounded by the margins for the axis labels
+const (
+	burndownChartWidth  = 400
+	burndownChartHeight = 200
+	burndownChartMargin = 30
+)
+
+// burndownChart holds the lines of the burndown and burnup charts of a milestone as SVG polyline points
+type burndownChart struct {
+	Width, Height int
+	Left, Right   int
+	Top, Bottom   int
+	MaxIssues     int
+	Start, End    string
+	Open, Ideal   string
+	Forecast      string
+	Total, Closed string
+	DeadlineX     string
+}
+
+func newBurndownChart(b *models.MilestoneBurndown) *burndownChart {
+	first, last := b.Snapshots[0], b.Snapshots[len(b.Snapshots)-1]
+	hasDeadline := b.Milestone.DeadlineUnix.Year() < 9999
+
+	start, end := first.DayUnix, last.DayUnix
+	if hasDeadline && b.Milestone.DeadlineUnix > end {
+		end = b.Milestone.DeadlineUnix
+	}
+	if b.Forecast > end {
+		end = b.Forecast
+	}
+	if end <= start {
+		end = start.Add(24 * 60 * 60)
+	}
+
+	chart := &burndownChart{
+		Width:     burndownChartWidth,
+		Height:    burndownChartHeight,
+		Left:      burndownChartMargin,
+		Right:     burndownChartWidth - burndownChartMargin,
+		Top:       burndownChartMargin / 2,
+		Bottom:    burndownChartHeight - burndownChartMargin,
+		MaxIssues: 1,
+		Start:     start.Format("2006-01-02"),
+		End:       end.Format("2006-01-02"),
+	}
+	for _, s := range b.Snapshots {
+		if s.NumIssues > chart.MaxIssues {
+			chart.MaxIssues = s.NumIssues
+		}
+	}
+
+	x := func(t util.TimeStamp) float64 {
+		return float64(chart.Left) + float64(t-start)/float64(end-start)*float64(chart.Right-chart.Left)
+	}
+	y := func(n int) float64 {
+		return float64(chart.Bottom) - float64(n)/float64(chart.MaxIssues)*float64(chart.Bottom-chart.Top)
+	}
+	point := func(t util.TimeStamp, n int) string {
+		return fmt.Sprintf("%.1f,%.1f", x(t), y(n))
+	}
+
+	open := make([]string, len(b.Snapshots))
+	total := make([]string, len(b.Snapshots))
+	closed := make([]string, len(b.Snapshots))
+	for i, s := range b.Snapshots {
+		open[i] = point(s.DayUnix, s.NumOpenIssues)
+		total[i] = point(s.DayUnix, s.NumIssues)
+		closed[i] = point(s.DayUnix, s.NumClosedIssues)
+	}
+	chart.Open = strings.Join(open, " ")
+	chart.Total = strings.Join(total, " ")
+	chart.Closed = strings.Join(closed, " ")
+
+	if hasDeadline && b.Milestone.DeadlineUnix >= start {
+		chart.Ideal = point(start, first.NumOpenIssues) + " " + point(b.Milestone.DeadlineUnix, 0)
+		chart.DeadlineX = fmt.Sprintf("%.1f", x(b.Milestone.DeadlineUnix))
+	}
+	if b.Forecast > last.DayUnix {
+		chart.Forecast = point(last.DayUnix, last.NumOpenIssues) + " " + point(b.Forecast, 0)
+	}
+	return chart
+}
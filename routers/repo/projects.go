@@ -0,0 +1,543 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"errors"
+	"path"
+	"strings"
+
+	"github.com/masoodkamyab/gitea/models"
+	"github.com/masoodkamyab/gitea/modules/auth"
+	"github.com/masoodkamyab/gitea/modules/base"
+	"github.com/masoodkamyab/gitea/modules/context"
+	"github.com/masoodkamyab/gitea/modules/markup/markdown"
+	"github.com/masoodkamyab/gitea/modules/notification"
+	"github.com/masoodkamyab/gitea/modules/setting"
+	"github.com/masoodkamyab/gitea/modules/util"
+
+	"github.com/Unknwon/com"
+	macaron "gopkg.in/macaron.v1"
+)
+
+const (
+	tplProjects       base.TplName = "repo/projects/list"
+	tplProjectNew     base.TplName = "repo/projects/new"
+	tplProjectView    base.TplName = "repo/projects/view"
+	tplOrgProjects    base.TplName = "org/projects/list"
+	tplOrgProjectNew  base.TplName = "org/projects/new"
+	tplOrgProjectView base.TplName = "org/projects/view"
+)
+
+type projectsCtx struct {
+	RepoID       int64
+	OwnerID      int64
+	Type         models.ProjectType
+	Link         string
+	CanRead      bool
+	CanWrite     bool
+	ListTemplate base.TplName
+	NewTemplate  base.TplName
+	ViewTemplate base.TplName
+}
+
+// getProjectsCtx determines whether the projects of a repository or of an organization are shown.
+func getProjectsCtx(ctx *context.Context) (*projectsCtx, error) {
+	if len(ctx.Repo.RepoLink) > 0 {
+		return &projectsCtx{
+			RepoID:       ctx.Repo.Repository.ID,
+			Type:         models.ProjectTypeRepository,
+			Link:         path.Join(ctx.Repo.RepoLink, "projects"),
+			CanRead:      ctx.Repo.CanRead(models.UnitTypeProjects),
+			CanWrite:     ctx.Repo.CanWrite(models.UnitTypeProjects) && !ctx.Repo.Repository.IsArchived,
+			ListTemplate: tplProjects,
+			NewTemplate:  tplProjectNew,
+			ViewTemplate: tplProjectView,
+		}, nil
+	}
+
+	if len(ctx.Org.OrgLink) > 0 {
+		mode, err := models.OrgProjectsAccessMode(ctx.Org.Organization, ctx.User)
+		if err != nil {
+			return nil, err
+		}
+		return &projectsCtx{
+			OwnerID:      ctx.Org.Organization.ID,
+			Type:         models.ProjectTypeOrganization,
+			Link:         path.Join(ctx.Org.OrgLink, "projects"),
+			CanRead:      mode >= models.AccessModeRead,
+			CanWrite:     mode >= models.AccessModeWrite,
+			ListTemplate: tplOrgProjects,
+			NewTemplate:  tplOrgProjectNew,
+			ViewTemplate: tplOrgProjectView,
+		}, nil
+	}
+
+	return nil, errors.New("Unable to set projects context")
+}
+
+// ProjectsAssignment checks the access to the projects of a repository or of an organization
+// and sets up the projects context
+func ProjectsAssignment(requireWrite bool) macaron.Handler {
+	return func(ctx *context.Context) {
+		pctx, err := getProjectsCtx(ctx)
+		if err != nil {
+			ctx.ServerError("getProjectsCtx", err)
+			return
+		}
+		if !pctx.CanRead || requireWrite && !pctx.CanWrite {
+			ctx.NotFound("ProjectsAssignment", nil)
+			return
+		}
+
+		ctx.Data["ProjectsLink"] = pctx.Link
+		ctx.Data["CanWriteProjects"] = pctx.CanWrite
+		ctx.Data["PageIsProjects"] = true
+		ctx.Map(pctx)
+	}
+}
+
+// getProject returns the project given by the :id parameter if it belongs to the projects context
+func getProject(ctx *context.Context, pctx *projectsCtx) *models.Project {
+	p, err := models.GetProjectByID(ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrProjectNotExist(err) {
+			ctx.NotFound("GetProjectByID", err)
+		} else {
+			ctx.ServerError("GetProjectByID", err)
+		}
+		return nil
+	}
+	if p.Type != pctx.Type || p.RepoID != pctx.RepoID || p.OwnerID != pctx.OwnerID {
+		ctx.NotFound("GetProjectByID", nil)
+		return nil
+	}
+	if err = p.LoadAttributes(); err != nil {
+		ctx.ServerError("LoadAttributes", err)
+		return nil
+	}
+	return p
+}
+
+// Projects renders the projects of a repository or of an organization
+func Projects(ctx *context.Context, pctx *projectsCtx) {
+	ctx.Data["Title"] = ctx.Tr("repo.projects")
+
+	isShowClosed := ctx.Query("state") == "closed"
+	page := ctx.QueryInt("page")
+	if page <= 1 {
+		page = 1
+	}
+
+	projects, total, err := models.GetProjects(&models.ProjectSearchOptions{
+		RepoID:   pctx.RepoID,
+		OwnerID:  pctx.OwnerID,
+		IsClosed: util.OptionalBoolOf(isShowClosed),
+		Page:     page,
+	})
+	if err != nil {
+		ctx.ServerError("GetProjects", err)
+		return
+	}
+	for _, p := range projects {
+		p.RenderedContent = renderProjectContent(ctx, p)
+	}
+	ctx.Data["Projects"] = projects
+
+	if isShowClosed {
+		ctx.Data["State"] = "closed"
+	} else {
+		ctx.Data["State"] = "open"
+	}
+	ctx.Data["IsShowClosed"] = isShowClosed
+
+	pager := context.NewPagination(int(total), setting.UI.IssuePagingNum, page, 5)
+	pager.AddParam(ctx, "state", "State")
+	ctx.Data["Page"] = pager
+
+	ctx.HTML(200, pctx.ListTemplate)
+}
+
+func renderProjectContent(ctx *context.Context, p *models.Project) string {
+	if p.Type == models.ProjectTypeRepository {
+		return string(markdown.Render([]byte(p.Description), ctx.Repo.RepoLink, ctx.Repo.Repository.ComposeMetas()))
+	}
+	return string(markdown.Render([]byte(p.Description), ctx.Org.OrgLink, nil))
+}
+
+// NewProject renders the page to create a project
+func NewProject(ctx *context.Context, pctx *projectsCtx) {
+	ctx.Data["Title"] = ctx.Tr("repo.projects.new")
+	ctx.HTML(200, pctx.NewTemplate)
+}
+
+// NewProjectPost creates a project
+func NewProjectPost(ctx *context.Context, pctx *projectsCtx, form auth.CreateProjectForm) {
+	ctx.Data["Title"] = ctx.Tr("repo.projects.new")
+
+	if ctx.HasError() {
+		ctx.HTML(200, pctx.NewTemplate)
+		return
+	}
+
+	p := &models.Project{
+		RepoID:      pctx.RepoID,
+		OwnerID:     pctx.OwnerID,
+		Type:        pctx.Type,
+		Title:       form.Title,
+		Description: form.Content,
+		CreatorID:   ctx.User.ID,
+	}
+	if err := models.NewProject(p); err != nil {
+		ctx.ServerError("NewProject", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.projects.create_success", form.Title))
+	ctx.Redirect(pctx.Link + "/" + com.ToStr(p.ID))
+}
+
+// EditProject renders the page to edit a project
+func EditProject(ctx *context.Context, pctx *projectsCtx) {
+	ctx.Data["Title"] = ctx.Tr("repo.projects.edit")
+	ctx.Data["PageIsEditProject"] = true
+
+	p := getProject(ctx, pctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["title"] = p.Title
+	ctx.Data["content"] = p.Description
+	ctx.HTML(200, pctx.NewTemplate)
+}
+
+// EditProjectPost updates a project
+func EditProjectPost(ctx *context.Context, pctx *projectsCtx, form auth.CreateProjectForm) {
+	ctx.Data["Title"] = ctx.Tr("repo.projects.edit")
+	ctx.Data["PageIsEditProject"] = true
+
+	if ctx.HasError() {
+		ctx.HTML(200, pctx.NewTemplate)
+		return
+	}
+
+	p := getProject(ctx, pctx)
+	if ctx.Written() {
+		return
+	}
+	p.Title = form.Title
+	p.Description = form.Content
+	if err := models.UpdateProject(p); err != nil {
+		ctx.ServerError("UpdateProject", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.projects.edit_success", p.Title))
+	ctx.Redirect(pctx.Link + "/" + com.ToStr(p.ID))
+}
+
+// ChangeProjectStatus opens or closes a project
+func ChangeProjectStatus(ctx *context.Context, pctx *projectsCtx) {
+	p := getProject(ctx, pctx)
+	if ctx.Written() {
+		return
+	}
+
+	isClosed := ctx.Params(":action") == "close"
+	if p.IsClosed != isClosed {
+		if err := models.ChangeProjectStatus(p, isClosed); err != nil {
+			ctx.ServerError("ChangeProjectStatus", err)
+			return
+		}
+	}
+	if isClosed {
+		ctx.Redirect(pctx.Link + "?state=closed")
+	} else {
+		ctx.Redirect(pctx.Link + "?state=open")
+	}
+}
+
+// DeleteProject deletes a project
+func DeleteProject(ctx *context.Context, pctx *projectsCtx) {
+	p, err := models.GetProjectByID(ctx.QueryInt64("id"))
+	if err != nil && !models.IsErrProjectNotExist(err) {
+		ctx.ServerError("GetProjectByID", err)
+		return
+	}
+	if p == nil || p.Type != pctx.Type || p.RepoID != pctx.RepoID || p.OwnerID != pctx.OwnerID {
+		ctx.Flash.Error(ctx.Tr("repo.projects.not_exist"))
+	} else if err = models.DeleteProjectByID(p.ID); err != nil {
+		ctx.Flash.Error("DeleteProjectByID: " + err.Error())
+	} else {
+		ctx.Flash.Success(ctx.Tr("repo.projects.deletion_success"))
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": pctx.Link,
+	})
+}
+
+// ViewProject renders the boards of a project with their issues
+func ViewProject(ctx *context.Context, pctx *projectsCtx) {
+	p := getProject(ctx, pctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["Title"] = p.Title
+	p.RenderedContent = renderProjectContent(ctx, p)
+	ctx.Data["Project"] = p
+
+	boards, err := models.GetProjectBoards(p.ID)
+	if err != nil {
+		ctx.ServerError("GetProjectBoards", err)
+		return
+	}
+	uncategorized := &models.ProjectBoard{
+		ProjectID: p.ID,
+		Title:     ctx.Tr("repo.projects.board.uncategorized"),
+	}
+	boardMap := make(map[int64]*models.ProjectBoard, len(boards)+1)
+	boardMap[0] = uncategorized
+	for _, b := range boards {
+		boardMap[b.ID] = b
+	}
+
+	pis, err := models.GetProjectIssues(p.ID)
+	if err != nil {
+		ctx.ServerError("GetProjectIssues", err)
+		return
+	}
+	if pis, err = models.FilterProjectIssuesByUser(pis, ctx.User); err != nil {
+		ctx.ServerError("FilterProjectIssuesByUser", err)
+		return
+	}
+	for _, pi := range pis {
+		b, ok := boardMap[pi.ProjectBoardID]
+		if !ok {
+			b = uncategorized
+		}
+		b.Issues = append(b.Issues, pi.Issue)
+	}
+
+	ctx.Data["Boards"] = append([]*models.ProjectBoard{uncategorized}, boards...)
+	ctx.Data["IsOrgProject"] = p.Type == models.ProjectTypeOrganization
+	ctx.HTML(200, pctx.ViewTemplate)
+}
+
+// NewProjectBoardPost appends a board to a project
+func NewProjectBoardPost(ctx *context.Context, pctx *projectsCtx, form auth.CreateProjectBoardForm) {
+	p := getProject(ctx, pctx)
+	if ctx.Written() {
+		return
+	}
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.Data["ErrorMsg"].(string))
+		ctx.Redirect(pctx.Link + "/" + com.ToStr(p.ID))
+		return
+	}
+
+	boards, err := models.GetProjectBoards(p.ID)
+	if err != nil {
+		ctx.ServerError("GetProjectBoards", err)
+		return
+	}
+	if err = models.NewProjectBoard(&models.ProjectBoard{
+		ProjectID: p.ID,
+		Title:     form.Title,
+		Sorting:   len(boards),
+		CreatorID: ctx.User.ID,
+	}); err != nil {
+		ctx.ServerError("NewProjectBoard", err)
+		return
+	}
+	ctx.Redirect(pctx.Link + "/" + com.ToStr(p.ID))
+}
+
+func getProjectBoard(ctx *context.Context, p *models.Project) *models.ProjectBoard {
+	b, err := models.GetProjectBoard(p.ID, ctx.ParamsInt64(":boardID"))
+	if err != nil {
+		if models.IsErrProjectBoardNotExist(err) {
+			ctx.NotFound("GetProjectBoard", err)
+		} else {
+			ctx.ServerError("GetProjectBoard", err)
+		}
+		return nil
+	}
+	return b
+}
+
+// EditProjectBoardPost renames a board of a project
+func EditProjectBoardPost(ctx *context.Context, pctx *projectsCtx, form auth.CreateProjectBoardForm) {
+	p := getProject(ctx, pctx)
+	if ctx.Written() {
+		return
+	}
+	b := getProjectBoard(ctx, p)
+	if ctx.Written() {
+		return
+	}
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.Data["ErrorMsg"].(string))
+		ctx.Redirect(pctx.Link + "/" + com.ToStr(p.ID))
+		return
+	}
+
+	b.Title = form.Title
+	if err := models.UpdateProjectBoard(b); err != nil {
+		ctx.ServerError("UpdateProjectBoard", err)
+		return
+	}
+	ctx.Redirect(pctx.Link + "/" + com.ToStr(p.ID))
+}
+
+// DeleteProjectBoardPost deletes a board of a project, its issues become uncategorized
+func DeleteProjectBoardPost(ctx *context.Context, pctx *projectsCtx) {
+	p := getProject(ctx, pctx)
+	if ctx.Written() {
+		return
+	}
+	b := getProjectBoard(ctx, p)
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.DeleteProjectBoard(b); err != nil {
+		ctx.ServerError("DeleteProjectBoard", err)
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("repo.projects.board.deletion_success", b.Title))
+	ctx.Redirect(pctx.Link + "/" + com.ToStr(p.ID))
+}
+
+// resolveProjectIssueRef looks up an issue reference like "#1" or "repo#1"
+// among the repositories the project may contain issues of
+func resolveProjectIssueRef(ctx *context.Context, p *models.Project, ref string) (*models.Issue, error) {
+	ref = strings.TrimSpace(ref)
+	repoName := ""
+	if pos := strings.LastIndex(ref, "#"); pos >= 0 {
+		repoName, ref = ref[:pos], ref[pos+1:]
+	}
+	index, err := com.StrTo(ref).Int64()
+	if err != nil {
+		return nil, models.ErrIssueNotExist{}
+	}
+
+	repo := p.Repo
+	if p.Type == models.ProjectTypeOrganization {
+		if pos := strings.LastIndex(repoName, "/"); pos >= 0 {
+			repoName = repoName[pos+1:]
+		}
+		if repo, err = models.GetRepositoryByName(p.OwnerID, repoName); err != nil {
+			if models.IsErrRepoNotExist(err) {
+				return nil, models.ErrIssueNotExist{}
+			}
+			return nil, err
+		}
+	}
+
+	issue, err := models.GetIssueByIndex(repo.ID, index)
+	if err != nil {
+		return nil, err
+	}
+	issue.Repo = repo
+
+	perm, err := models.GetUserRepoPermission(repo, ctx.User)
+	if err != nil {
+		return nil, err
+	} else if !perm.CanReadIssuesOrPulls(issue.IsPull) {
+		return nil, models.ErrIssueNotExist{}
+	}
+	return issue, nil
+}
+
+// AddProjectIssuePost puts an issue or a pull request on a project
+func AddProjectIssuePost(ctx *context.Context, pctx *projectsCtx, form auth.AddProjectIssueForm) {
+	p := getProject(ctx, pctx)
+	if ctx.Written() {
+		return
+	}
+	projectLink := pctx.Link + "/" + com.ToStr(p.ID)
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.Data["ErrorMsg"].(string))
+		ctx.Redirect(projectLink)
+		return
+	}
+
+	issue, err := resolveProjectIssueRef(ctx, p, form.Issue)
+	if err != nil {
+		if models.IsErrIssueNotExist(err) {
+			ctx.Flash.Error(ctx.Tr("repo.projects.issue_not_exist", form.Issue))
+			ctx.Redirect(projectLink)
+		} else {
+			ctx.ServerError("resolveProjectIssueRef", err)
+		}
+		return
+	}
+
+	if _, err = models.AddIssueToProject(p, issue, form.BoardID); err != nil {
+		switch {
+		case models.IsErrProjectIssueAlreadyExist(err):
+			ctx.Flash.Error(ctx.Tr("repo.projects.issue_already_added", form.Issue))
+		case models.IsErrProjectIssueNotAllowed(err), models.IsErrProjectBoardNotExist(err):
+			ctx.Flash.Error(ctx.Tr("repo.projects.issue_not_allowed", form.Issue))
+		default:
+			ctx.ServerError("AddIssueToProject", err)
+			return
+		}
+	}
+	ctx.Redirect(projectLink)
+}
+
+// RemoveProjectIssuePost takes an issue off a project
+func RemoveProjectIssuePost(ctx *context.Context, pctx *projectsCtx) {
+	p := getProject(ctx, pctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.RemoveIssueFromProject(p.ID, ctx.QueryInt64("issue_id")); err != nil {
+		ctx.ServerError("RemoveIssueFromProject", err)
+		return
+	}
+	ctx.Redirect(pctx.Link + "/" + com.ToStr(p.ID))
+}
+
+// MoveProjectIssuePost moves an issue to a position on a board of a project
+func MoveProjectIssuePost(ctx *context.Context, pctx *projectsCtx) {
+	p := getProject(ctx, pctx)
+	if ctx.Written() {
+		return
+	}
+
+	pi, err := models.GetProjectIssue(p.ID, ctx.QueryInt64("issue_id"))
+	if err != nil {
+		if models.IsErrProjectIssueNotExist(err) {
+			ctx.NotFound("GetProjectIssue", err)
+		} else {
+			ctx.ServerError("GetProjectIssue", err)
+		}
+		return
+	}
+	issue, err := models.GetIssueByID(pi.IssueID)
+	if err != nil {
+		ctx.ServerError("GetIssueByID", err)
+		return
+	}
+
+	oldBoardID := pi.ProjectBoardID
+	if err = models.MoveProjectIssue(pi, ctx.QueryInt64("board_id"), ctx.QueryInt("sorting")); err != nil {
+		if models.IsErrProjectBoardNotExist(err) {
+			ctx.NotFound("MoveProjectIssue", err)
+		} else {
+			ctx.ServerError("MoveProjectIssue", err)
+		}
+		return
+	}
+	notification.NotifyIssueChangeProjectBoard(ctx.User, issue, p, oldBoardID, pi.ProjectBoardID)
+
+	ctx.JSON(200, map[string]interface{}{
+		"ok": true,
+	})
+}
@@ -34,6 +34,8 @@ const (
 	tplPullCommits base.TplName = "repo/pulls/commits"
 	tplPullFiles   base.TplName = "repo/pulls/files"
 
+	tplPullConflicts base.TplName = "repo/pulls/conflicts"
+
 	pullRequestTemplateKey = "PullRequestTemplate"
 )
 
@@ -620,11 +622,41 @@ func MergePullRequest(ctx *context.Context, form auth.MergePullRequestForm) {
 		return
 	}
 
+	queued, err := pr.IsMergeQueueEnabled()
+	if err != nil {
+		ctx.ServerError("IsMergeQueueEnabled", err)
+		return
+	}
+	if queued {
+		if _, err = pull.AddToMergeQueue(pr, ctx.User, models.MergeStyle(form.Do), message); err != nil {
+			if models.IsErrInvalidMergeStyle(err) {
+				ctx.Flash.Error(ctx.Tr("repo.pulls.invalid_merge_option"))
+				ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
+				return
+			} else if models.IsErrPullRequestAlreadyQueued(err) {
+				ctx.Flash.Error(ctx.Tr("repo.pulls.merge_queue_already_queued"))
+				ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
+				return
+			}
+			ctx.ServerError("AddToMergeQueue", err)
+			return
+		}
+
+		log.Trace("Pull request added to merge queue: %d", pr.ID)
+		ctx.Flash.Success(ctx.Tr("repo.pulls.merge_queue_added"))
+		ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
+		return
+	}
+
 	if err = pull.Merge(pr, ctx.User, ctx.Repo.GitRepo, models.MergeStyle(form.Do), message); err != nil {
 		if models.IsErrInvalidMergeStyle(err) {
 			ctx.Flash.Error(ctx.Tr("repo.pulls.invalid_merge_option"))
 			ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
 			return
+		} else if models.IsErrMergeNotFastForward(err) {
+			ctx.Flash.Error(ctx.Tr("repo.pulls.merge_not_fast_forward"))
+			ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
+			return
 		}
 		ctx.ServerError("Merge", err)
 		return
@@ -641,6 +673,194 @@ func MergePullRequest(ctx *context.Context, form auth.MergePullRequestForm) {
 	ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
 }
 
+// RemovePullRequestFromMergeQueue removes a pull request from the merge queue of its base branch
+func RemovePullRequestFromMergeQueue(ctx *context.Context) {
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := pull.RemoveFromMergeQueue(issue.PullRequest); err != nil {
+		ctx.ServerError("RemoveFromMergeQueue", err)
+		return
+	}
+
+	log.Trace("Pull request removed from merge queue: %d", issue.PullRequest.ID)
+	ctx.Flash.Success(ctx.Tr("repo.pulls.merge_queue_removed"))
+	ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(issue.Index))
+}
+
+// UpdatePullRequest brings the head branch of a pull request up to date with its base branch
+func UpdatePullRequest(ctx *context.Context) {
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+	if issue.IsClosed || issue.PullRequest.HasMerged {
+		ctx.NotFound("UpdatePullRequest", nil)
+		return
+	}
+
+	style := models.MergeStyle(ctx.Query("style"))
+	if len(style) == 0 {
+		style = models.MergeStyleMerge
+	}
+
+	if err := pull.Update(issue.PullRequest, ctx.User, style); err != nil {
+		if models.IsErrInvalidMergeStyle(err) {
+			ctx.Flash.Error(ctx.Tr("repo.pulls.invalid_merge_option"))
+		} else if models.IsErrNotAllowedToUpdate(err) {
+			ctx.Flash.Error(ctx.Tr("repo.pulls.update_not_allowed"))
+		} else if models.IsErrPullRequestUpdateConflict(err) {
+			ctx.Flash.Error(ctx.Tr("repo.pulls.update_conflict"))
+		} else if models.IsErrErrPullRequestHeadRepoMissing(err) {
+			ctx.Flash.Error(ctx.Tr("repo.pulls.update_head_repo_missing"))
+		} else {
+			ctx.ServerError("Update", err)
+			return
+		}
+		ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(issue.Index))
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.pulls.update_success"))
+	ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(issue.Index))
+}
+
+// SetPullDraft marks a pull request as a draft or as ready for review
+func SetPullDraft(ctx *context.Context) {
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+	if issue.IsClosed || issue.PullRequest.HasMerged ||
+		!ctx.IsSigned || (!issue.IsPoster(ctx.User.ID) && !ctx.Repo.CanWrite(models.UnitTypePullRequests)) {
+		ctx.NotFound("SetPullDraft", nil)
+		return
+	}
+
+	if err := pull.SetDraft(issue.PullRequest, ctx.User, ctx.QueryBool("draft")); err != nil {
+		ctx.ServerError("SetDraft", err)
+		return
+	}
+	ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(issue.Index))
+}
+
+// ViewPullConflicts render the conflicts of merging the base branch into the head branch of a pull request
+func ViewPullConflicts(ctx *context.Context) {
+	ctx.Data["PageIsPullList"] = true
+
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+	pr := issue.PullRequest
+	if issue.IsClosed || pr.HasMerged {
+		ctx.NotFound("ViewPullConflicts", nil)
+		return
+	}
+
+	if PrepareViewPullInfo(ctx, issue); ctx.Written() {
+		return
+	}
+
+	conflicts, err := pull.GetConflicts(pr)
+	if err != nil {
+		if models.IsErrErrPullRequestHeadRepoMissing(err) {
+			ctx.NotFound("GetConflicts", err)
+			return
+		}
+		ctx.ServerError("GetConflicts", err)
+		return
+	}
+	ctx.Data["Conflicts"] = conflicts
+	ctx.Data["CanResolveConflicts"] = ctx.IsSigned && pr.CheckUserAllowedToUpdate(ctx.User) == nil
+	ctx.Data["DefaultConflictsMessage"] = fmt.Sprintf("Merge branch '%s' into %s", pr.BaseBranch, pr.HeadBranch)
+
+	ctx.HTML(200, tplPullConflicts)
+}
+
+// ResolvePullConflicts commits the conflict resolutions of a pull request to its head branch
+func ResolvePullConflicts(ctx *context.Context) {
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+	pr := issue.PullRequest
+	if issue.IsClosed || pr.HasMerged {
+		ctx.NotFound("ResolvePullConflicts", nil)
+		return
+	}
+
+	conflictsLink := ctx.Repo.RepoLink + "/pulls/" + com.ToStr(issue.Index) + "/conflicts"
+
+	conflicts, err := pull.GetConflicts(pr)
+	if err != nil {
+		ctx.ServerError("GetConflicts", err)
+		return
+	}
+	if conflicts.CommitID != ctx.Query("commit_id") || conflicts.MergeCommitID != ctx.Query("merge_commit_id") {
+		ctx.Flash.Error(ctx.Tr("repo.pulls.conflicts_outdated"))
+		ctx.Redirect(conflictsLink)
+		return
+	}
+
+	resolutions := make(map[string]string, len(conflicts.Files))
+	for i, file := range conflicts.Files {
+		if ctx.Query(fmt.Sprintf("file_%d_path", i)) != file.TreePath {
+			ctx.Flash.Error(ctx.Tr("repo.pulls.conflicts_outdated"))
+			ctx.Redirect(conflictsLink)
+			return
+		}
+
+		if ctx.Query(fmt.Sprintf("file_%d_mode", i)) == "edit" {
+			resolutions[file.TreePath] = strings.Replace(ctx.Query(fmt.Sprintf("file_%d_content", i)), "\r\n", "\n", -1)
+			continue
+		}
+
+		var content strings.Builder
+		for j, section := range file.Sections {
+			if !section.IsConflict {
+				content.WriteString(section.Lines)
+				continue
+			}
+			switch ctx.Query(fmt.Sprintf("file_%d_section_%d", i, j)) {
+			case "ours":
+				content.WriteString(section.Ours)
+			case "theirs":
+				content.WriteString(section.Theirs)
+			case "both":
+				content.WriteString(section.Ours)
+				content.WriteString(section.Theirs)
+			default:
+				ctx.Flash.Error(ctx.Tr("repo.pulls.conflicts_unresolved", file.TreePath))
+				ctx.Redirect(conflictsLink)
+				return
+			}
+		}
+		resolutions[file.TreePath] = content.String()
+	}
+
+	if err = pull.ResolveConflicts(pr, ctx.User, conflicts.CommitID, conflicts.MergeCommitID, ctx.Query("message"), resolutions); err != nil {
+		if models.IsErrNotAllowedToUpdate(err) {
+			ctx.Flash.Error(ctx.Tr("repo.pulls.update_not_allowed"))
+		} else if models.IsErrCommitIDDoesNotMatch(err) {
+			ctx.Flash.Error(ctx.Tr("repo.pulls.conflicts_outdated"))
+		} else if models.IsErrMergeConflictUnresolved(err) {
+			ctx.Flash.Error(ctx.Tr("repo.pulls.conflicts_unresolved", err.(models.ErrMergeConflictUnresolved).TreePath))
+		} else {
+			ctx.ServerError("ResolveConflicts", err)
+			return
+		}
+		ctx.Redirect(conflictsLink)
+		return
+	}
+
+	log.Trace("Pull request conflicts resolved: %d", pr.ID)
+	ctx.Flash.Success(ctx.Tr("repo.pulls.conflicts_resolved"))
+	ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(issue.Index))
+}
+
 func stopTimerIfAvailable(user *models.User, issue *models.Issue) error {
 
 	if models.StopwatchExists(user.ID, issue.ID) {
@@ -736,6 +956,7 @@ func CompareAndPullRequestPost(ctx *context.Context, form auth.CreateIssueForm)
 		BaseRepo:     repo,
 		MergeBase:    prInfo.MergeBase,
 		Type:         models.PullRequestGitea,
+		IsDraft:      form.Draft,
 	}
 	// FIXME: check error in the case two people send pull request at almost same time, give nice error prompt
 	// instead of 500.
@@ -286,10 +286,21 @@ func SettingsPost(ctx *context.Context, form auth.RepoSettingForm) {
 					AllowRebase:               form.PullsAllowRebase,
 					AllowRebaseMerge:          form.PullsAllowRebaseMerge,
 					AllowSquash:               form.PullsAllowSquash,
+					AllowFastForwardOnly:      form.PullsAllowFastForwardOnly,
+					MergeMessageTemplate:      strings.TrimSpace(form.PullsMergeMessageTemplate),
+					SquashMessageTemplate:     strings.TrimSpace(form.PullsSquashMessageTemplate),
 				},
 			})
 		}
 
+		if form.EnableProjects {
+			units = append(units, models.RepoUnit{
+				RepoID: repo.ID,
+				Type:   models.UnitTypeProjects,
+				Config: new(models.UnitConfig),
+			})
+		}
+
 		if err := models.UpdateRepositoryUnits(repo, units); err != nil {
 			ctx.ServerError("UpdateRepositoryUnits", err)
 			return
@@ -404,6 +415,7 @@ func SettingsPost(ctx *context.Context, form auth.RepoSettingForm) {
 			ctx.ServerError("DeleteRepository", err)
 			return
 		}
+		ctx.Audit(models.NewRepoAuditEvent(models.AuditRepoDelete, repo, repo.FullName(), nil))
 		log.Trace("Repository deleted: %s/%s", ctx.Repo.Owner.Name, repo.Name)
 
 		ctx.Flash.Success(ctx.Tr("repo.settings.deletion_success"))
@@ -531,6 +543,9 @@ func CollaborationPost(ctx *context.Context) {
 		ctx.ServerError("AddCollaborator", err)
 		return
 	}
+	ctx.Audit(models.NewRepoAuditEvent(models.AuditRepoCollaboratorAdd, ctx.Repo.Repository, u.Name, models.AuditDiff{
+		"mode": {New: models.AccessModeWrite.String()},
+	}))
 
 	if setting.Service.EnableNotifyMail {
 		models.SendCollaboratorMail(u, ctx.User, ctx.Repo.Repository)
@@ -540,20 +555,52 @@ func CollaborationPost(ctx *context.Context) {
 	ctx.Redirect(setting.AppSubURL + ctx.Req.URL.Path)
 }
 
+// auditCollaboration records a change of the collaboration of the user with
+// the repository in the audit log
+func auditCollaboration(ctx *context.Context, action models.AuditAction, uid int64, diff models.AuditDiff) {
+	u, err := models.GetUserByID(uid)
+	if err != nil {
+		log.Error("GetUserByID: %v", err)
+		return
+	}
+	ctx.Audit(models.NewRepoAuditEvent(action, ctx.Repo.Repository, u.Name, diff))
+}
+
 // ChangeCollaborationAccessMode response for changing access of a collaboration
 func ChangeCollaborationAccessMode(ctx *context.Context) {
-	if err := ctx.Repo.Repository.ChangeCollaborationAccessMode(
-		ctx.QueryInt64("uid"),
-		models.AccessMode(ctx.QueryInt("mode"))); err != nil {
+	uid := ctx.QueryInt64("uid")
+	mode := models.AccessMode(ctx.QueryInt("mode"))
+	collaboration, err := ctx.Repo.Repository.GetCollaboration(uid)
+	if err != nil {
+		log.Error("GetCollaboration: %v", err)
+		return
+	}
+	if err := ctx.Repo.Repository.ChangeCollaborationAccessMode(uid, mode); err != nil {
 		log.Error("ChangeCollaborationAccessMode: %v", err)
+		return
+	}
+	if collaboration != nil && collaboration.Mode != mode && mode > models.AccessModeNone && mode <= models.AccessModeOwner {
+		auditCollaboration(ctx, models.AuditRepoCollaboratorMode, uid, models.AuditDiff{
+			"mode": {Old: collaboration.Mode.String(), New: mode.String()},
+		})
 	}
 }
 
 // DeleteCollaboration delete a collaboration for a repository
 func DeleteCollaboration(ctx *context.Context) {
-	if err := ctx.Repo.Repository.DeleteCollaboration(ctx.QueryInt64("id")); err != nil {
+	uid := ctx.QueryInt64("id")
+	collaboration, err := ctx.Repo.Repository.GetCollaboration(uid)
+	if err == nil {
+		err = ctx.Repo.Repository.DeleteCollaboration(uid)
+	}
+	if err != nil {
 		ctx.Flash.Error("DeleteCollaboration: " + err.Error())
 	} else {
+		if collaboration != nil {
+			auditCollaboration(ctx, models.AuditRepoCollaboratorRemove, uid, models.AuditDiff{
+				"mode": {Old: collaboration.Mode.String()},
+			})
+		}
 		ctx.Flash.Success(ctx.Tr("repo.settings.remove_collaborator_success"))
 	}
 
@@ -708,6 +755,7 @@ func DeployKeysPost(ctx *context.Context, form auth.AddKeyForm) {
 		return
 	}
 
+	ctx.Audit(models.NewRepoAuditEvent(models.AuditRepoDeployKeyAdd, ctx.Repo.Repository, key.Name, models.NewAuditDiff(nil, key.AuditFields())))
 	log.Trace("Deploy key added: %d", ctx.Repo.Repository.ID)
 	ctx.Flash.Success(ctx.Tr("repo.settings.add_key_success", key.Name))
 	ctx.Redirect(ctx.Repo.RepoLink + "/settings/keys")
@@ -715,7 +763,15 @@ func DeployKeysPost(ctx *context.Context, form auth.AddKeyForm) {
 
 // DeleteDeployKey response for deleting a deploy key
 func DeleteDeployKey(ctx *context.Context) {
-	if err := models.DeleteDeployKey(ctx.User, ctx.QueryInt64("id")); err != nil {
+	key, err := models.GetDeployKeyByID(ctx.QueryInt64("id"))
+	if err == nil && key.RepoID == ctx.Repo.Repository.ID {
+		if err = models.DeleteDeployKey(ctx.User, key.ID); err == nil {
+			ctx.Audit(models.NewRepoAuditEvent(models.AuditRepoDeployKeyRemove, ctx.Repo.Repository, key.Name, models.NewAuditDiff(key.AuditFields(), nil)))
+		}
+	} else if models.IsErrDeployKeyNotExist(err) {
+		err = nil
+	}
+	if err != nil {
 		ctx.Flash.Error("DeleteDeployKey: " + err.Error())
 	} else {
 		ctx.Flash.Success(ctx.Tr("repo.settings.deploy_key_deletion_success"))
@@ -157,6 +157,7 @@ func SettingsProtectedBranchPost(ctx *context.Context, f auth.ProtectBranchForm)
 			return
 		}
 	}
+	before := protectBranch.AuditFields()
 
 	if f.Protected {
 		if protectBranch == nil {
@@ -187,6 +188,7 @@ func SettingsProtectedBranchPost(ctx *context.Context, f auth.ProtectBranchForm)
 			mergeWhitelistTeams, _ = base.StringsToInt64s(strings.Split(f.MergeWhitelistTeams, ","))
 		}
 		protectBranch.RequiredApprovals = f.RequiredApprovals
+		protectBranch.EnableMergeQueue = f.EnableMergeQueue
 		if strings.TrimSpace(f.ApprovalsWhitelistUsers) != "" {
 			approvalsWhitelistUsers, _ = base.StringsToInt64s(strings.Split(f.ApprovalsWhitelistUsers, ","))
 		}
@@ -205,6 +207,9 @@ func SettingsProtectedBranchPost(ctx *context.Context, f auth.ProtectBranchForm)
 			ctx.ServerError("UpdateProtectBranch", err)
 			return
 		}
+		if diff := models.NewAuditDiff(before, protectBranch.AuditFields()); len(diff) > 0 {
+			ctx.Audit(models.NewRepoAuditEvent(models.AuditRepoBranchProtect, ctx.Repo.Repository, branch, diff))
+		}
 		ctx.Flash.Success(ctx.Tr("repo.settings.update_protect_branch_success", branch))
 		ctx.Redirect(fmt.Sprintf("%s/settings/branches/%s", ctx.Repo.RepoLink, branch))
 	} else {
@@ -213,6 +218,7 @@ func SettingsProtectedBranchPost(ctx *context.Context, f auth.ProtectBranchForm)
 				ctx.ServerError("DeleteProtectedBranch", err)
 				return
 			}
+			ctx.Audit(models.NewRepoAuditEvent(models.AuditRepoBranchUnprotect, ctx.Repo.Repository, branch, models.NewAuditDiff(before, nil)))
 		}
 		ctx.Flash.Success(ctx.Tr("repo.settings.remove_protected_branch_success", branch))
 		ctx.Redirect(fmt.Sprintf("%s/settings/branches", ctx.Repo.RepoLink))
@@ -25,6 +25,7 @@ import (
 	"github.com/masoodkamyab/gitea/modules/setting"
 	"github.com/masoodkamyab/gitea/modules/templates"
 	"github.com/masoodkamyab/gitea/modules/validation"
+	"github.com/masoodkamyab/gitea/modules/webauthn"
 	"github.com/masoodkamyab/gitea/routers"
 	"github.com/masoodkamyab/gitea/routers/admin"
 	apiv1 "github.com/masoodkamyab/gitea/routers/api/v1"
@@ -43,7 +44,6 @@ import (
 	"github.com/go-macaron/session"
 	"github.com/go-macaron/toolbox"
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/tstranex/u2f"
 	macaron "gopkg.in/macaron.v1"
 )
 
@@ -104,7 +104,7 @@ func RouterHandler(level log.Level) func(ctx *macaron.Context) {
 
 // NewMacaron initializes Macaron instance.
 func NewMacaron() *macaron.Macaron {
-	gob.Register(&u2f.Challenge{})
+	gob.Register(&webauthn.SessionData{})
 	var m *macaron.Macaron
 	if setting.RedirectMacaronLog {
 		loggerAsWriter := log.NewLoggerAsWriter("INFO", log.GetLogger("macaron"))
@@ -269,6 +269,11 @@ func RegisterRoutes(m *macaron.Macaron) {
 	m.Combo("/install", routers.InstallInit).Get(routers.Install).
 		Post(bindIgnErr(auth.InstallForm{}), routers.InstallPost)
 	m.Get("/^:type(issues|pulls)$", reqSignIn, user.Issues)
+	m.Get("/user/times", reqSignIn, user.TrackedTimes)
+	m.Group("/user/filters", func() {
+		m.Post("", bindIgnErr(auth.SaveFilterForm{}), user.SaveFilter)
+		m.Post("/:id/delete", user.DeleteSavedFilter)
+	}, reqSignIn)
 
 	// ***** START: User *****
 	m.Group("/user", func() {
@@ -295,6 +300,10 @@ func RegisterRoutes(m *macaron.Macaron) {
 			m.Get("/:provider", user.SignInOAuth)
 			m.Get("/:provider/callback", user.SignInOAuthCallback)
 		})
+		m.Group("/saml", func() {
+			m.Get("/:provider", user.SignInSAML)
+			m.Post("/:provider/acs", user.SAMLAssertionConsumerService)
+		})
 		m.Get("/link_account", user.LinkAccount)
 		m.Post("/link_account_signin", bindIgnErr(auth.SignInForm{}), user.LinkAccountPostSignIn)
 		m.Post("/link_account_signup", bindIgnErr(auth.RegisterForm{}), user.LinkAccountPostRegister)
@@ -304,10 +313,10 @@ func RegisterRoutes(m *macaron.Macaron) {
 			m.Get("/scratch", user.TwoFactorScratch)
 			m.Post("/scratch", bindIgnErr(auth.TwoFactorScratchAuthForm{}), user.TwoFactorScratchPost)
 		})
-		m.Group("/u2f", func() {
-			m.Get("", user.U2F)
-			m.Get("/challenge", user.U2FChallenge)
-			m.Post("/sign", bindIgnErr(u2f.SignResponse{}), user.U2FSign)
+		m.Group("/webauthn", func() {
+			m.Get("", user.WebAuthn)
+			m.Get("/assertion", user.WebAuthnAssertion)
+			m.Post("/assertion", bindIgnErr(webauthn.CredentialAssertionResponse{}), user.WebAuthnAssertionPost)
 
 		})
 	}, reqSignOut)
@@ -319,6 +328,10 @@ func RegisterRoutes(m *macaron.Macaron) {
 		m.Post("/authorize", bindIgnErr(auth.AuthorizationForm{}), user.AuthorizeOAuth)
 	}, ignSignInAndCsrf, reqSignIn)
 	m.Post("/login/oauth/access_token", bindIgnErr(auth.AccessTokenForm{}), ignSignInAndCsrf, user.AccessTokenOAuth)
+	m.Combo("/login/oauth/userinfo", ignSignInAndCsrf).Get(user.InfoOAuth).Post(user.InfoOAuth)
+	m.Get("/login/oauth/keys", ignSignInAndCsrf, user.OIDCKeys)
+	m.Get("/.well-known/openid-configuration", ignSignInAndCsrf, user.OIDCWellKnown)
+	m.Get("/user/saml/:provider/metadata", ignSignInAndCsrf, user.SAMLMetadata)
 
 	m.Group("/user/settings", func() {
 		m.Get("", userSetting.Profile)
@@ -342,10 +355,10 @@ func RegisterRoutes(m *macaron.Macaron) {
 				m.Get("/enroll", userSetting.EnrollTwoFactor)
 				m.Post("/enroll", bindIgnErr(auth.TwoFactorAuthForm{}), userSetting.EnrollTwoFactorPost)
 			})
-			m.Group("/u2f", func() {
-				m.Post("/request_register", bindIgnErr(auth.U2FRegistrationForm{}), userSetting.U2FRegister)
-				m.Post("/register", bindIgnErr(u2f.RegisterResponse{}), userSetting.U2FRegisterPost)
-				m.Post("/delete", bindIgnErr(auth.U2FDeleteForm{}), userSetting.U2FDelete)
+			m.Group("/webauthn", func() {
+				m.Post("/request_register", bindIgnErr(auth.WebAuthnRegistrationForm{}), userSetting.WebAuthnRegister)
+				m.Post("/register", bindIgnErr(webauthn.CredentialCreationResponse{}), userSetting.WebAuthnRegisterPost)
+				m.Post("/delete", bindIgnErr(auth.WebAuthnDeleteForm{}), userSetting.WebAuthnDelete)
 			})
 			m.Group("/openid", func() {
 				m.Post("", bindIgnErr(auth.AddOpenIDForm{}), userSetting.OpenIDPost)
@@ -353,6 +366,10 @@ func RegisterRoutes(m *macaron.Macaron) {
 				m.Post("/toggle_visibility", userSetting.ToggleOpenIDVisibility)
 			}, openIDSignInEnabled)
 			m.Post("/account_link", userSetting.DeleteAccountLink)
+			m.Group("/sessions", func() {
+				m.Post("/delete", userSetting.DeleteSession)
+				m.Post("/delete_others", userSetting.DeleteOtherSessions)
+			})
 		})
 		m.Group("/applications/oauth2", func() {
 			m.Get("/:id", userSetting.OAuth2ApplicationShow)
@@ -420,6 +437,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 			m.Combo("/new").Get(admin.NewUser).Post(bindIgnErr(auth.AdminCreateUserForm{}), admin.NewUserPost)
 			m.Combo("/:userid").Get(admin.EditUser).Post(bindIgnErr(auth.AdminEditUserForm{}), admin.EditUserPost)
 			m.Post("/:userid/delete", admin.DeleteUser)
+			m.Post("/:userid/reset_twofa", admin.ResetTwoFactor)
 		})
 
 		m.Group("/orgs", func() {
@@ -431,6 +449,13 @@ func RegisterRoutes(m *macaron.Macaron) {
 			m.Post("/delete", admin.DeleteRepo)
 		})
 
+		m.Group("/sessions", func() {
+			m.Get("", admin.Sessions)
+			m.Post("/delete", admin.DeleteSession)
+		})
+
+		m.Get("/audit", admin.AuditEvents)
+
 		m.Group("/hooks", func() {
 			m.Get("", admin.DefaultWebhooks)
 			m.Post("/delete", admin.DeleteDefaultWebhook)
@@ -456,6 +481,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 			m.Combo("/:authid").Get(admin.EditAuthSource).
 				Post(bindIgnErr(auth.AuthenticationForm{}), admin.EditAuthSourcePost)
 			m.Post("/:authid/delete", admin.DeleteAuthSource)
+			m.Get("/:authid/group_sync_preview", admin.GroupSyncPreview)
 		})
 
 		m.Group("/notices", func() {
@@ -544,12 +570,35 @@ func RegisterRoutes(m *macaron.Macaron) {
 		m.Group("/:org", func() {
 			m.Get("/dashboard", user.Dashboard)
 			m.Get("/^:type(issues|pulls)$", user.Issues)
+			m.Get("/times", user.TrackedTimes)
 			m.Get("/members", org.Members)
 			m.Get("/members/action/:action", org.MembersAction)
 
 			m.Get("/teams", org.Teams)
 		}, context.OrgAssignment(true))
 
+		m.Group("/:org/projects", func() {
+			m.Get("", repo.Projects)
+			m.Get("/:id", repo.ViewProject)
+		}, context.OrgAssignment(true), repo.ProjectsAssignment(false))
+
+		m.Group("/:org/projects", func() {
+			m.Combo("/new").Get(repo.NewProject).
+				Post(bindIgnErr(auth.CreateProjectForm{}), repo.NewProjectPost)
+			m.Post("/delete", repo.DeleteProject)
+			m.Group("/:id", func() {
+				m.Combo("/edit").Get(repo.EditProject).
+					Post(bindIgnErr(auth.CreateProjectForm{}), repo.EditProjectPost)
+				m.Post("/^:action(open|close)$", repo.ChangeProjectStatus)
+				m.Post("/boards/new", bindIgnErr(auth.CreateProjectBoardForm{}), repo.NewProjectBoardPost)
+				m.Post("/boards/:boardID/edit", bindIgnErr(auth.CreateProjectBoardForm{}), repo.EditProjectBoardPost)
+				m.Post("/boards/:boardID/delete", repo.DeleteProjectBoardPost)
+				m.Post("/issues/add", bindIgnErr(auth.AddProjectIssueForm{}), repo.AddProjectIssuePost)
+				m.Post("/issues/remove", repo.RemoveProjectIssuePost)
+				m.Post("/move", repo.MoveProjectIssuePost)
+			})
+		}, context.OrgAssignment(true), repo.ProjectsAssignment(true))
+
 		m.Group("/:org", func() {
 			m.Get("/teams/:team", org.TeamMembers)
 			m.Get("/teams/:team/repositories", org.TeamRepositories)
@@ -589,6 +638,14 @@ func RegisterRoutes(m *macaron.Macaron) {
 					m.Post("/telegram/:id", bindIgnErr(auth.NewTelegramHookForm{}), repo.TelegramHooksEditPost)
 				})
 
+				m.Group("/issue_fields", func() {
+					m.Combo("").Get(repo.IssueFields).
+						Post(bindIgnErr(auth.IssueFieldForm{}), repo.NewIssueFieldPost)
+					m.Post("/delete", repo.DeleteIssueField)
+					m.Post("/:id", bindIgnErr(auth.IssueFieldForm{}), repo.EditIssueFieldPost)
+				})
+
+				m.Get("/audit", org.AuditEvents)
 				m.Route("/delete", "GET,POST", org.SettingsDelete)
 			})
 		}, context.OrgAssignment(true, true))
@@ -662,6 +719,13 @@ func RegisterRoutes(m *macaron.Macaron) {
 				m.Post("/delete", repo.DeleteDeployKey)
 			})
 
+			m.Group("/issue_fields", func() {
+				m.Combo("").Get(repo.IssueFields).
+					Post(bindIgnErr(auth.IssueFieldForm{}), repo.NewIssueFieldPost)
+				m.Post("/delete", repo.DeleteIssueField)
+				m.Post("/:id", bindIgnErr(auth.IssueFieldForm{}), repo.EditIssueFieldPost)
+			})
+
 		}, func(ctx *context.Context) {
 			ctx.Data["PageIsSettings"] = true
 		})
@@ -673,6 +737,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 		m.Group("/issues", func() {
 			m.Combo("/new").Get(context.RepoRef(), repo.NewIssue).
 				Post(bindIgnErr(auth.CreateIssueForm{}), repo.NewIssuePost)
+			m.Get("/new/choose", context.RepoRef(), repo.NewIssueChooseTemplate)
 		}, context.RepoMustNotBeArchived(), reqRepoIssueReader)
 		// FIXME: should use different URLs but mostly same logic for comments of issue and pull reuqest.
 		// So they can apply their own enable/disable logic on routers.
@@ -686,6 +751,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 					m.Post("/delete", repo.RemoveDependency)
 				})
 				m.Combo("/comments").Post(repo.MustAllowUserComment, bindIgnErr(auth.CreateCommentForm{}), repo.NewComment)
+				m.Post("/estimate", reqRepoIssuesOrPullsWriter, repo.UpdateIssueEstimate)
 				m.Group("/times", func() {
 					m.Post("/add", bindIgnErr(auth.AddTimeManuallyForm{}), repo.AddTimeManually)
 					m.Group("/stopwatch", func() {
@@ -696,12 +762,15 @@ func RegisterRoutes(m *macaron.Macaron) {
 				m.Post("/reactions/:action", bindIgnErr(auth.ReactionForm{}), repo.ChangeIssueReaction)
 				m.Post("/lock", reqRepoIssueWriter, bindIgnErr(auth.IssueLockForm{}), repo.LockIssue)
 				m.Post("/unlock", reqRepoIssueWriter, repo.UnlockIssue)
+				m.Post("/transfer", reqRepoIssueWriter, bindIgnErr(auth.TransferIssueForm{}), repo.TransferIssue)
+				m.Post("/fields/:id", reqRepoIssuesOrPullsWriter, repo.UpdateIssueFieldValues)
 			}, context.RepoMustNotBeArchived())
 
 			m.Post("/labels", reqRepoIssuesOrPullsWriter, repo.UpdateIssueLabel)
 			m.Post("/milestone", reqRepoIssuesOrPullsWriter, repo.UpdateIssueMilestone)
 			m.Post("/assignee", reqRepoIssuesOrPullsWriter, repo.UpdateIssueAssignee)
 			m.Post("/status", reqRepoIssuesOrPullsWriter, repo.UpdateIssueStatus)
+			m.Post("/bulk", reqRepoIssuesOrPullsWriter, bindIgnErr(auth.BulkEditIssuesForm{}), repo.BulkEditIssues)
 		}, context.RepoMustNotBeArchived())
 		m.Group("/comments/:id", func() {
 			m.Post("", repo.UpdateCommentContent)
@@ -725,6 +794,26 @@ func RegisterRoutes(m *macaron.Macaron) {
 		m.Group("/milestone", func() {
 			m.Get("/:id", repo.MilestoneIssuesAndPulls)
 		}, reqRepoIssuesOrPullsReader, context.RepoRef())
+		m.Group("/times/:id", func() {
+			m.Post("/edit", bindIgnErr(auth.AddTimeManuallyForm{}), repo.EditTrackedTime)
+			m.Post("/delete", repo.DeleteTrackedTime)
+		}, context.RepoMustNotBeArchived(), reqRepoIssuesOrPullsReader)
+		m.Group("/projects", func() {
+			m.Combo("/new").Get(repo.NewProject).
+				Post(bindIgnErr(auth.CreateProjectForm{}), repo.NewProjectPost)
+			m.Post("/delete", repo.DeleteProject)
+			m.Group("/:id", func() {
+				m.Combo("/edit").Get(repo.EditProject).
+					Post(bindIgnErr(auth.CreateProjectForm{}), repo.EditProjectPost)
+				m.Post("/^:action(open|close)$", repo.ChangeProjectStatus)
+				m.Post("/boards/new", bindIgnErr(auth.CreateProjectBoardForm{}), repo.NewProjectBoardPost)
+				m.Post("/boards/:boardID/edit", bindIgnErr(auth.CreateProjectBoardForm{}), repo.EditProjectBoardPost)
+				m.Post("/boards/:boardID/delete", repo.DeleteProjectBoardPost)
+				m.Post("/issues/add", bindIgnErr(auth.AddProjectIssueForm{}), repo.AddProjectIssuePost)
+				m.Post("/issues/remove", repo.RemoveProjectIssuePost)
+				m.Post("/move", repo.MoveProjectIssuePost)
+			})
+		}, context.RepoMustNotBeArchived(), repo.ProjectsAssignment(true), context.RepoRef())
 		m.Combo("/compare/*", repo.MustBeNotEmpty, reqRepoCodeReader, repo.SetEditorconfigIfExists).
 			Get(repo.SetDiffViewStyle, repo.CompareDiff).
 			Post(context.RepoMustNotBeArchived(), reqRepoPullsReader, repo.MustAllowPulls, bindIgnErr(auth.CreateIssueForm{}), repo.CompareAndPullRequestPost)
@@ -796,11 +885,18 @@ func RegisterRoutes(m *macaron.Macaron) {
 	m.Group("/:username/:reponame", func() {
 		m.Group("", func() {
 			m.Get("/^:type(issues|pulls)$", repo.Issues)
+			m.Get("/^:type(issues|pulls)$/export", repo.ExportIssues)
 			m.Get("/^:type(issues|pulls)$/:index", repo.ViewIssue)
 			m.Get("/labels/", reqRepoIssuesOrPullsReader, repo.RetrieveLabels, repo.Labels)
 			m.Get("/milestones", reqRepoIssuesOrPullsReader, repo.Milestones)
+			m.Get("/times", reqRepoIssuesOrPullsReader, repo.TrackedTimes)
 		}, context.RepoRef())
 
+		m.Group("/projects", func() {
+			m.Get("", repo.Projects)
+			m.Get("/:id", repo.ViewProject)
+		}, repo.ProjectsAssignment(false), context.RepoRef())
+
 		m.Group("/wiki", func() {
 			m.Get("/?:page", repo.Wiki)
 			m.Get("/_pages", repo.WikiPages)
@@ -839,6 +935,11 @@ func RegisterRoutes(m *macaron.Macaron) {
 			m.Get(".patch", repo.DownloadPullPatch)
 			m.Get("/commits", context.RepoRef(), repo.ViewPullCommits)
 			m.Post("/merge", context.RepoMustNotBeArchived(), reqRepoPullsWriter, bindIgnErr(auth.MergePullRequestForm{}), repo.MergePullRequest)
+			m.Post("/merge_queue/remove", context.RepoMustNotBeArchived(), reqRepoPullsWriter, repo.RemovePullRequestFromMergeQueue)
+			m.Post("/update", context.RepoMustNotBeArchived(), repo.UpdatePullRequest)
+			m.Post("/draft", context.RepoMustNotBeArchived(), repo.SetPullDraft)
+			m.Combo("/conflicts").Get(repo.ViewPullConflicts).
+				Post(context.RepoMustNotBeArchived(), repo.ResolvePullConflicts)
 			m.Post("/cleanup", context.RepoMustNotBeArchived(), context.RepoRef(), repo.CleanUpPullRequest)
 			m.Group("/files", func() {
 				m.Get("", context.RepoRef(), repo.SetEditorconfigIfExists, repo.SetDiffViewStyle, repo.SetWhitespaceBehavior, repo.ViewPullFiles)
@@ -20,10 +20,10 @@ import (
 	"github.com/masoodkamyab/gitea/modules/recaptcha"
 	"github.com/masoodkamyab/gitea/modules/setting"
 	"github.com/masoodkamyab/gitea/modules/util"
+	"github.com/masoodkamyab/gitea/modules/webauthn"
 
 	"github.com/go-macaron/captcha"
 	"github.com/markbates/goth"
-	"github.com/tstranex/u2f"
 )
 
 const (
@@ -40,7 +40,7 @@ const (
 	tplTwofa          base.TplName = "user/auth/twofa"
 	tplTwofaScratch   base.TplName = "user/auth/twofa_scratch"
 	tplLinkAccount    base.TplName = "user/auth/link_account"
-	tplU2F            base.TplName = "user/auth/u2f"
+	tplWebAuthn       base.TplName = "user/auth/webauthn"
 )
 
 // AutoSignIn reads cookie and try to auto-login.
@@ -71,8 +71,15 @@ func AutoSignIn(ctx *context.Context) (bool, error) {
 		return false, nil
 	}
 
-	if val, ok := ctx.GetSuperSecureCookie(
-		base.EncodeMD5(u.Rands+u.Passwd), setting.CookieRememberName); !ok || val != u.Name {
+	token, ok := ctx.GetSuperSecureCookie(base.EncodeMD5(u.Rands+u.Passwd), setting.CookieRememberName)
+	if !ok {
+		return false, nil
+	}
+	us, err := models.GetUserSessionByRememberToken(u.ID, token)
+	if err != nil {
+		if !models.IsErrUserSessionNotExist(err) {
+			return false, fmt.Errorf("GetUserSessionByRememberToken: %v", err)
+		}
 		return false, nil
 	}
 
@@ -85,6 +92,9 @@ func AutoSignIn(ctx *context.Context) (bool, error) {
 	if err != nil {
 		return false, err
 	}
+	if err = auth.ResumeUserSession(ctx.Context, ctx.Session, us); err != nil {
+		return false, err
+	}
 	ctx.SetCookie(setting.CSRFCookieName, "", -1, setting.AppSubURL, "", setting.SessionConfig.Secure, true)
 	return true, nil
 }
@@ -129,6 +139,12 @@ func SignIn(ctx *context.Context) {
 	}
 	ctx.Data["OrderedOAuth2Names"] = orderedOAuth2Names
 	ctx.Data["OAuth2Providers"] = oauth2Providers
+	samlProviders, err := models.GetActiveSAMLProviders()
+	if err != nil {
+		ctx.ServerError("UserSignIn", err)
+		return
+	}
+	ctx.Data["SAMLProviders"] = samlProviders
 	ctx.Data["Title"] = ctx.Tr("sign_in")
 	ctx.Data["SignInLink"] = setting.AppSubURL + "/user/login"
 	ctx.Data["PageIsSignIn"] = true
@@ -148,6 +164,12 @@ func SignInPost(ctx *context.Context, form auth.SignInForm) {
 	}
 	ctx.Data["OrderedOAuth2Names"] = orderedOAuth2Names
 	ctx.Data["OAuth2Providers"] = oauth2Providers
+	samlProviders, err := models.GetActiveSAMLProviders()
+	if err != nil {
+		ctx.ServerError("UserSignIn", err)
+		return
+	}
+	ctx.Data["SAMLProviders"] = samlProviders
 	ctx.Data["Title"] = ctx.Tr("sign_in")
 	ctx.Data["SignInLink"] = setting.AppSubURL + "/user/login"
 	ctx.Data["PageIsSignIn"] = true
@@ -208,9 +230,8 @@ func SignInPost(ctx *context.Context, form auth.SignInForm) {
 		return
 	}
 
-	regs, err := models.GetU2FRegistrationsByUID(u.ID)
-	if err == nil && len(regs) > 0 {
-		ctx.Redirect(setting.AppSubURL + "/user/u2f")
+	if has, err := models.HasWebAuthnCredentialsByUID(u.ID); err == nil && has {
+		ctx.Redirect(setting.AppSubURL + "/user/webauthn")
 		return
 	}
 
@@ -331,18 +352,18 @@ func TwoFactorScratchPost(ctx *context.Context, form auth.TwoFactorScratchAuthFo
 		return
 	}
 
-	// Validate the passcode with the stored TOTP secret.
-	if twofa.VerifyScratchToken(form.Token) {
-		// Invalidate the scratch token.
-		_, err = twofa.GenerateScratchToken()
+	// Validate and invalidate the scratch code.
+	ok, err := twofa.UseScratchCode(form.Token)
+	if err != nil {
+		ctx.ServerError("UserSignIn", err)
+		return
+	}
+	if ok {
+		left, err := twofa.CountScratchCodes()
 		if err != nil {
 			ctx.ServerError("UserSignIn", err)
 			return
 		}
-		if err = models.UpdateTwoFactor(twofa); err != nil {
-			ctx.ServerError("UserSignIn", err)
-			return
-		}
 
 		remember := ctx.Session.Get("twofaRemember").(bool)
 		u, err := models.GetUserByID(id)
@@ -352,7 +373,7 @@ func TwoFactorScratchPost(ctx *context.Context, form auth.TwoFactorScratchAuthFo
 		}
 
 		handleSignInFull(ctx, u, remember, false)
-		ctx.Flash.Info(ctx.Tr("auth.twofa_scratch_used"))
+		ctx.Flash.Info(ctx.Tr("auth.twofa_scratch_used", left))
 		ctx.Redirect(setting.AppSubURL + "/user/settings/security")
 		return
 	}
@@ -360,10 +381,9 @@ func TwoFactorScratchPost(ctx *context.Context, form auth.TwoFactorScratchAuthFo
 	ctx.RenderWithErr(ctx.Tr("auth.twofa_scratch_token_incorrect"), tplTwofaScratch, auth.TwoFactorScratchAuthForm{})
 }
 
-// U2F shows the U2F login page
-func U2F(ctx *context.Context) {
+// WebAuthn shows the WebAuthn login page
+func WebAuthn(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("twofa")
-	ctx.Data["RequireU2F"] = true
 	// Check auto-login.
 	if checkAutoLogin(ctx) {
 		return
@@ -371,100 +391,107 @@ func U2F(ctx *context.Context) {
 
 	// Ensure user is in a 2FA session.
 	if ctx.Session.Get("twofaUid") == nil {
-		ctx.ServerError("UserSignIn", errors.New("not in U2F session"))
+		ctx.ServerError("UserSignIn", errors.New("not in WebAuthn session"))
 		return
 	}
 
-	ctx.HTML(200, tplU2F)
+	ctx.HTML(200, tplWebAuthn)
 }
 
-// U2FChallenge submits a sign challenge to the browser
-func U2FChallenge(ctx *context.Context) {
-	// Ensure user is in a U2F session.
+// WebAuthnAssertion submits the options of an assertion to the browser
+func WebAuthnAssertion(ctx *context.Context) {
+	// Ensure user is in a 2FA session.
 	idSess := ctx.Session.Get("twofaUid")
 	if idSess == nil {
-		ctx.ServerError("UserSignIn", errors.New("not in U2F session"))
+		ctx.ServerError("UserSignIn", errors.New("not in WebAuthn session"))
 		return
 	}
 	id := idSess.(int64)
-	regs, err := models.GetU2FRegistrationsByUID(id)
+	creds, err := models.GetWebAuthnCredentialsByUID(id)
 	if err != nil {
 		ctx.ServerError("UserSignIn", err)
 		return
 	}
-	if len(regs) == 0 {
-		ctx.ServerError("UserSignIn", errors.New("no device registered"))
+	if len(creds) == 0 {
+		ctx.ServerError("UserSignIn", errors.New("no credential registered"))
 		return
 	}
-	challenge, err := u2f.NewChallenge(setting.U2F.AppID, setting.U2F.TrustedFacets)
+	options, session, err := models.WebAuthnConfig().BeginLogin(creds.ToCredentials())
 	if err != nil {
-		ctx.ServerError("u2f.NewChallenge", err)
+		ctx.ServerError("BeginLogin", err)
 		return
 	}
-	if err = ctx.Session.Set("u2fChallenge", challenge); err != nil {
+	if err = ctx.Session.Set("webauthnSession", session); err != nil {
 		ctx.ServerError("UserSignIn", err)
 		return
 	}
-	ctx.JSON(200, challenge.SignRequest(regs.ToRegistrations()))
+	ctx.JSON(200, options)
 }
 
-// U2FSign authenticates the user by signResp
-func U2FSign(ctx *context.Context, signResp u2f.SignResponse) {
-	challSess := ctx.Session.Get("u2fChallenge")
+// WebAuthnAssertionPost authenticates the user by the assertion of one of their credentials
+func WebAuthnAssertionPost(ctx *context.Context, resp webauthn.CredentialAssertionResponse) {
+	sessData := ctx.Session.Get("webauthnSession")
 	idSess := ctx.Session.Get("twofaUid")
-	if challSess == nil || idSess == nil {
-		ctx.ServerError("UserSignIn", errors.New("not in U2F session"))
+	if sessData == nil || idSess == nil {
+		ctx.ServerError("UserSignIn", errors.New("not in WebAuthn session"))
 		return
 	}
-	challenge := challSess.(*u2f.Challenge)
+	session := sessData.(*webauthn.SessionData)
 	id := idSess.(int64)
-	regs, err := models.GetU2FRegistrationsByUID(id)
+
+	cred, err := models.GetWebAuthnCredentialByCredID(id, resp.RawID)
 	if err != nil {
+		if models.IsErrWebAuthnCredentialNotExist(err) {
+			ctx.Error(401)
+			return
+		}
 		ctx.ServerError("UserSignIn", err)
 		return
 	}
-	for _, reg := range regs {
-		r, err := reg.Parse()
-		if err != nil {
-			log.Fatal("parsing u2f registration: %v", err)
-			continue
-		}
-		newCounter, authErr := r.Authenticate(signResp, *challenge, reg.Counter)
-		if authErr == nil {
-			reg.Counter = newCounter
-			user, err := models.GetUserByID(id)
-			if err != nil {
-				ctx.ServerError("UserSignIn", err)
-				return
-			}
-			remember := ctx.Session.Get("twofaRemember").(bool)
-			if err := reg.UpdateCounter(); err != nil {
-				ctx.ServerError("UserSignIn", err)
-				return
-			}
+	c, err := cred.ToCredential()
+	if err != nil {
+		ctx.ServerError("UserSignIn", err)
+		return
+	}
+	signCount, err := models.WebAuthnConfig().FinishLogin(session, &resp, c)
+	if err != nil {
+		log.Warn("WebAuthn assertion of credential %d for user %d failed: %v", cred.ID, id, err)
+		ctx.Error(401)
+		return
+	}
+	// Each challenge is signed only once
+	_ = ctx.Session.Delete("webauthnSession")
 
-			if ctx.Session.Get("linkAccount") != nil {
-				gothUser := ctx.Session.Get("linkAccountGothUser")
-				if gothUser == nil {
-					ctx.ServerError("UserSignIn", errors.New("not in LinkAccount session"))
-					return
-				}
+	cred.SignCount = signCount
+	if err = cred.UpdateSignCount(); err != nil {
+		ctx.ServerError("UserSignIn", err)
+		return
+	}
+	user, err := models.GetUserByID(id)
+	if err != nil {
+		ctx.ServerError("UserSignIn", err)
+		return
+	}
+	remember := ctx.Session.Get("twofaRemember").(bool)
 
-				err = models.LinkAccountToUser(user, gothUser.(goth.User))
-				if err != nil {
-					ctx.ServerError("UserSignIn", err)
-					return
-				}
-			}
-			redirect := handleSignInFull(ctx, user, remember, false)
-			if redirect == "" {
-				redirect = setting.AppSubURL + "/"
-			}
-			ctx.PlainText(200, []byte(redirect))
+	if ctx.Session.Get("linkAccount") != nil {
+		gothUser := ctx.Session.Get("linkAccountGothUser")
+		if gothUser == nil {
+			ctx.ServerError("UserSignIn", errors.New("not in LinkAccount session"))
+			return
+		}
+
+		err = models.LinkAccountToUser(user, gothUser.(goth.User))
+		if err != nil {
+			ctx.ServerError("UserSignIn", err)
 			return
 		}
 	}
-	ctx.Error(401)
+	redirect := handleSignInFull(ctx, user, remember, false)
+	if redirect == "" {
+		redirect = setting.AppSubURL + "/"
+	}
+	ctx.PlainText(200, []byte(redirect))
 }
 
 // This handles the final part of the sign-in process of the user.
@@ -473,20 +500,13 @@ func handleSignIn(ctx *context.Context, u *models.User, remember bool) {
 }
 
 func handleSignInFull(ctx *context.Context, u *models.User, remember bool, obeyRedirect bool) string {
-	if remember {
-		days := 86400 * setting.LogInRememberDays
-		ctx.SetCookie(setting.CookieUserName, u.Name, days, setting.AppSubURL, "", setting.SessionConfig.Secure, true)
-		ctx.SetSuperSecureCookie(base.EncodeMD5(u.Rands+u.Passwd),
-			setting.CookieRememberName, u.Name, days, setting.AppSubURL, "", setting.SessionConfig.Secure, true)
-	}
-
 	_ = ctx.Session.Delete("openid_verified_uri")
 	_ = ctx.Session.Delete("openid_signin_remember")
 	_ = ctx.Session.Delete("openid_determined_email")
 	_ = ctx.Session.Delete("openid_determined_username")
 	_ = ctx.Session.Delete("twofaUid")
 	_ = ctx.Session.Delete("twofaRemember")
-	_ = ctx.Session.Delete("u2fChallenge")
+	_ = ctx.Session.Delete("webauthnSession")
 	_ = ctx.Session.Delete("linkAccount")
 	err := ctx.Session.Set("uid", u.ID)
 	if err != nil {
@@ -496,6 +516,13 @@ func handleSignInFull(ctx *context.Context, u *models.User, remember bool, obeyR
 	if err != nil {
 		log.Error(fmt.Sprintf("Error setting session: %v", err))
 	}
+	if err = auth.StartUserSession(ctx.Context, ctx.Session, u.ID); err != nil {
+		log.Error("StartUserSession: %v", err)
+	} else if remember {
+		if err = auth.RememberUserSession(ctx.Context, ctx.Session, u); err != nil {
+			log.Error("RememberUserSession: %v", err)
+		}
+	}
 
 	// Language setting of the user overwrites the one previously set
 	// If the user does not have a locale set, we save the current one.
@@ -608,6 +635,9 @@ func handleOAuth2SignIn(u *models.User, gothUser goth.User, ctx *context.Context
 			if err != nil {
 				log.Error(fmt.Sprintf("Error setting session: %v", err))
 			}
+			if err = auth.StartUserSession(ctx.Context, ctx.Session, u.ID); err != nil {
+				log.Error("StartUserSession: %v", err)
+			}
 
 			// Clear whatever CSRF has right now, force to generate a new one
 			ctx.SetCookie(setting.CSRFCookieName, "", -1, setting.AppSubURL, "", setting.SessionConfig.Secure, true)
@@ -642,10 +672,9 @@ func handleOAuth2SignIn(u *models.User, gothUser goth.User, ctx *context.Context
 		log.Error(fmt.Sprintf("Error setting session: %v", err))
 	}
 
-	// If U2F is enrolled -> Redirect to U2F instead
-	regs, err := models.GetU2FRegistrationsByUID(u.ID)
-	if err == nil && len(regs) > 0 {
-		ctx.Redirect(setting.AppSubURL + "/user/u2f")
+	// If WebAuthn is enrolled -> Redirect to WebAuthn instead
+	if has, err := models.HasWebAuthnCredentialsByUID(u.ID); err == nil && has {
+		ctx.Redirect(setting.AppSubURL + "/user/webauthn")
 		return
 	}
 
@@ -673,6 +702,9 @@ func oAuth2UserLoginCallback(loginSource *models.LoginSource, request *http.Requ
 	}
 
 	if hasUser {
+		if err = models.SyncOAuth2User(loginSource, user, gothUser); err != nil {
+			return nil, goth.User{}, err
+		}
 		return user, goth.User{}, nil
 	}
 
@@ -687,7 +719,13 @@ func oAuth2UserLoginCallback(loginSource *models.LoginSource, request *http.Requ
 	}
 	if hasUser {
 		user, err = models.GetUserByID(externalLoginUser.UserID)
-		return user, goth.User{}, err
+		if err != nil {
+			return nil, goth.User{}, err
+		}
+		if err = models.SyncOAuth2User(loginSource, user, gothUser); err != nil {
+			return nil, goth.User{}, err
+		}
+		return user, goth.User{}, nil
 	}
 
 	// no user found to login
@@ -814,10 +852,9 @@ func LinkAccountPostSignIn(ctx *context.Context, signInForm auth.SignInForm) {
 		log.Error(fmt.Sprintf("Error setting session: %v", err))
 	}
 
-	// If U2F is enrolled -> Redirect to U2F instead
-	regs, err := models.GetU2FRegistrationsByUID(u.ID)
-	if err == nil && len(regs) > 0 {
-		ctx.Redirect(setting.AppSubURL + "/user/u2f")
+	// If WebAuthn is enrolled -> Redirect to WebAuthn instead
+	if has, err := models.HasWebAuthnCredentialsByUID(u.ID); err == nil && has {
+		ctx.Redirect(setting.AppSubURL + "/user/webauthn")
 		return
 	}
 
@@ -898,17 +935,19 @@ func LinkAccountPostRegister(ctx *context.Context, cpt *captcha.Captcha, form au
 		}
 	}
 
-	loginSource, err := models.GetActiveOAuth2LoginSourceByName(gothUser.(goth.User).Provider)
+	loginSource, err := models.GetActiveExternalLoginSourceByName(gothUser.(goth.User).Provider)
 	if err != nil {
 		ctx.ServerError("CreateUser", err)
+		return
 	}
 
 	u := &models.User{
 		Name:        form.UserName,
+		FullName:    gothUser.(goth.User).Name,
 		Email:       form.Email,
 		Passwd:      form.Password,
 		IsActive:    !setting.Service.RegisterEmailConfirm,
-		LoginType:   models.LoginOAuth2,
+		LoginType:   loginSource.Type,
 		LoginSource: loginSource.ID,
 		LoginName:   gothUser.(goth.User).UserID,
 	}
@@ -945,6 +984,11 @@ func LinkAccountPostRegister(ctx *context.Context, cpt *captcha.Captcha, form au
 		}
 	}
 
+	if err := models.SyncExternalUser(loginSource, u, gothUser.(goth.User)); err != nil {
+		ctx.ServerError("SyncExternalUser", err)
+		return
+	}
+
 	// Send confirmation email
 	if setting.Service.RegisterEmailConfirm && u.ID > 1 {
 		models.SendActivateAccountMail(ctx.Context, u)
@@ -963,8 +1007,12 @@ func LinkAccountPostRegister(ctx *context.Context, cpt *captcha.Captcha, form au
 }
 
 func handleSignOut(ctx *context.Context) {
+	if err := models.DeleteUserSessionBySessionID(ctx.Session.ID()); err != nil {
+		log.Error("DeleteUserSessionBySessionID: %v", err)
+	}
 	_ = ctx.Session.Delete("uid")
 	_ = ctx.Session.Delete("uname")
+	_ = ctx.Session.Delete("sessionUid")
 	_ = ctx.Session.Delete("socialId")
 	_ = ctx.Session.Delete("socialName")
 	_ = ctx.Session.Delete("socialEmail")
@@ -1164,6 +1212,9 @@ func Activate(ctx *context.Context) {
 		if err != nil {
 			log.Error(fmt.Sprintf("Error setting session: %v", err))
 		}
+		if err = auth.StartUserSession(ctx.Context, ctx.Session, user.ID); err != nil {
+			log.Error("StartUserSession: %v", err)
+		}
 		ctx.Flash.Success(ctx.Tr("auth.account_activated"))
 		ctx.Redirect(setting.AppSubURL + "/")
 		return
@@ -1234,7 +1285,7 @@ func ForgotPasswdPost(ctx *context.Context) {
 		return
 	}
 
-	if !u.IsLocal() && !u.IsOAuth2() {
+	if !u.IsLocal() && !u.IsOAuth2() && !u.IsSAML() {
 		ctx.Data["Err_Email"] = true
 		ctx.RenderWithErr(ctx.Tr("auth.non_local_account"), tplForgotPassword, nil)
 		return
@@ -1329,6 +1380,10 @@ func ResetPasswdPost(ctx *context.Context) {
 
 	u.HashPassword(passwd)
 	u.MustChangePassword = false
+	if err := models.DeleteUserSessionsByUID(u.ID, ""); err != nil {
+		ctx.ServerError("DeleteUserSessionsByUID", err)
+		return
+	}
 	if err := models.UpdateUserCols(u, "must_change_password", "passwd", "rands", "salt"); err != nil {
 		ctx.ServerError("UpdateUser", err)
 		return
@@ -1395,6 +1450,10 @@ func MustChangePasswordPost(ctx *context.Context, cpt *captcha.Captcha, form aut
 		ctx.ServerError("UpdateUser", err)
 		return
 	}
+	if err := models.DeleteUserSessionsByUID(u.ID, ctx.Session.ID()); err != nil {
+		ctx.ServerError("DeleteUserSessionsByUID", err)
+		return
+	}
 
 	ctx.Flash.Success(ctx.Tr("settings.change_password_success"))
 
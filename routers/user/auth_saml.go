@@ -0,0 +1,164 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"fmt"
+
+	"github.com/masoodkamyab/gitea/models"
+	"github.com/masoodkamyab/gitea/modules/auth/saml"
+	"github.com/masoodkamyab/gitea/modules/context"
+	"github.com/masoodkamyab/gitea/modules/log"
+	"github.com/masoodkamyab/gitea/modules/setting"
+
+	"github.com/markbates/goth"
+)
+
+// samlRequestIDSession is the session key of the ID of the pending authentication request,
+// the response of the identity provider has to refer to it
+const samlRequestIDSession = "samlRequestID"
+
+// getSAMLProvider returns the active SAML login source of the request and its service provider
+func getSAMLProvider(ctx *context.Context) (*models.LoginSource, *saml.ServiceProvider) {
+	loginSource, err := models.GetActiveSAMLLoginSourceByName(ctx.Params(":provider"))
+	if err != nil {
+		ctx.NotFoundOrServerError("GetActiveSAMLLoginSourceByName", models.IsErrLoginSourceNotExist, err)
+		return nil, nil
+	}
+
+	sp, err := saml.GetProvider(loginSource.Name)
+	if err != nil {
+		ctx.ServerError("GetProvider", err)
+		return nil, nil
+	}
+	return loginSource, sp
+}
+
+// SignInSAML sends the user with an authentication request to the identity provider of a SAML login source
+func SignInSAML(ctx *context.Context) {
+	_, sp := getSAMLProvider(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	redirectURL, requestID, err := sp.AuthnRequestURL("")
+	if err != nil {
+		ctx.ServerError("AuthnRequestURL", err)
+		return
+	}
+	if err = ctx.Session.Set(samlRequestIDSession, requestID); err != nil {
+		ctx.ServerError("SignInSAML", err)
+		return
+	}
+	ctx.Redirect(redirectURL)
+}
+
+// SAMLMetadata returns the metadata of the service provider of a SAML login source
+func SAMLMetadata(ctx *context.Context) {
+	_, sp := getSAMLProvider(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	data, err := sp.Metadata()
+	if err != nil {
+		ctx.ServerError("Metadata", err)
+		return
+	}
+	ctx.Resp.Header().Set("Content-Type", "application/samlmetadata+xml")
+	if _, err = ctx.Resp.Write(data); err != nil {
+		log.Error("SAMLMetadata: %v", err)
+	}
+}
+
+// SAMLAssertionConsumerService verifies the response of the identity provider and signs the user in
+func SAMLAssertionConsumerService(ctx *context.Context) {
+	loginSource, sp := getSAMLProvider(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	// a request ID is only used once, so a response cannot be replayed
+	requestID, _ := ctx.Session.Get(samlRequestIDSession).(string)
+	_ = ctx.Session.Delete(samlRequestIDSession)
+
+	assertion, err := sp.ParseResponse(ctx.Query("SAMLResponse"), requestID)
+	if err != nil {
+		log.Warn("Failed SAML authentication attempt via %s from %s: %v", loginSource.Name, ctx.RemoteAddr(), err)
+		ctx.Flash.Error(ctx.Tr("auth.saml_login_failed"))
+		ctx.Redirect(setting.AppSubURL + "/user/login")
+		return
+	}
+
+	gothUser := models.SAMLUser(loginSource, assertion)
+	u, err := samlUserLogin(loginSource, gothUser)
+	handleOAuth2SignIn(u, gothUser, ctx, err)
+}
+
+// samlUserLogin returns the user of a SAML login, it is created if the login source enables auto registration.
+// No user is returned if it has to be linked to an existing account or registered by the user.
+func samlUserLogin(loginSource *models.LoginSource, gothUser goth.User) (*models.User, error) {
+	user := &models.User{
+		LoginName:   gothUser.UserID,
+		LoginType:   models.LoginSAML,
+		LoginSource: loginSource.ID,
+	}
+	hasUser, err := models.GetUser(user)
+	if err != nil {
+		return nil, err
+	}
+
+	if !hasUser {
+		// search in external linked users
+		externalLoginUser := &models.ExternalLoginUser{
+			ExternalID:    gothUser.UserID,
+			LoginSourceID: loginSource.ID,
+		}
+		hasUser, err = models.GetExternalLogin(externalLoginUser)
+		if err != nil {
+			return nil, err
+		}
+		if hasUser {
+			if user, err = models.GetUserByID(externalLoginUser.UserID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if !hasUser {
+		if !loginSource.SAML().EnableAutoRegister {
+			return nil, nil
+		}
+
+		email := gothUser.Email
+		if len(email) == 0 {
+			email = fmt.Sprintf("%s@localhost", gothUser.NickName)
+		}
+		user = &models.User{
+			Name:        gothUser.NickName,
+			FullName:    gothUser.Name,
+			Email:       email,
+			IsActive:    true,
+			LoginType:   models.LoginSAML,
+			LoginSource: loginSource.ID,
+			LoginName:   gothUser.UserID,
+		}
+		if err = models.CreateUser(user); err != nil {
+			if models.IsErrUserAlreadyExist(err) || models.IsErrEmailAlreadyUsed(err) ||
+				models.IsErrNameReserved(err) || models.IsErrNamePatternNotAllowed(err) {
+				// let the user link the account or choose another name
+				log.Info("SAML auto registration of %s via %s failed: %v", gothUser.NickName, loginSource.Name, err)
+				return nil, nil
+			}
+			return nil, err
+		}
+		log.Trace("Account created: %s", user.Name)
+	}
+
+	if err = models.SyncSAMLUser(loginSource, user, gothUser); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
@@ -12,8 +12,10 @@ import (
 	"strings"
 
 	"github.com/masoodkamyab/gitea/models"
+	"github.com/masoodkamyab/gitea/modules/auth"
 	"github.com/masoodkamyab/gitea/modules/base"
 	"github.com/masoodkamyab/gitea/modules/context"
+	issue_indexer "github.com/masoodkamyab/gitea/modules/indexer/issues"
 	"github.com/masoodkamyab/gitea/modules/log"
 	"github.com/masoodkamyab/gitea/modules/setting"
 	"github.com/masoodkamyab/gitea/modules/util"
@@ -195,6 +197,11 @@ func Issues(ctx *context.Context) {
 	repoID := ctx.QueryInt64("repo")
 	isShowClosed := ctx.Query("state") == "closed"
 
+	keyword := strings.Trim(ctx.Query("q"), " ")
+	if strings.IndexByte(keyword, 0) >= 0 {
+		keyword = ""
+	}
+
 	// Get repositories.
 	var err error
 	var userRepoIDs []int64
@@ -224,6 +231,24 @@ func Issues(ctx *context.Context) {
 		userRepoIDs = []int64{-1}
 	}
 
+	// A saved filter replaces the query, the sorting and the repository of the view
+	savedFilters, err := models.GetSavedFiltersForUser(ctx.User.ID)
+	if err != nil {
+		ctx.ServerError("GetSavedFiltersForUser", err)
+		return
+	}
+	savedFilterID := ctx.QueryInt64("filter")
+	for _, f := range savedFilters {
+		if f.ID == savedFilterID {
+			keyword = f.Query
+			if len(f.SortType) > 0 {
+				sortType = f.SortType
+			}
+			repoID = f.RepoID
+			break
+		}
+	}
+
 	opts := &models.IssuesOptions{
 		IsClosed: util.OptionalBoolOf(isShowClosed),
 		IsPull:   util.OptionalBoolOf(isPullList),
@@ -252,6 +277,32 @@ func Issues(ctx *context.Context) {
 		opts.MentionedID = ctxUser.ID
 	}
 
+	query := models.ParseIssueQuery(keyword)
+	matchable, err := query.Apply(opts, ctx.User)
+	if err != nil {
+		ctx.ServerError("IssueQuery.Apply", err)
+		return
+	}
+	if matchable && len(query.Keyword) > 0 {
+		searchRepoIDs := opts.RepoIDs
+		if len(searchRepoIDs) == 0 {
+			searchRepoIDs = userRepoIDs
+		}
+		opts.IssueIDs, err = issue_indexer.SearchIssuesByKeyword(searchRepoIDs, query.Keyword)
+		if err != nil {
+			ctx.ServerError("issueIndexer.Search", err)
+			return
+		}
+		matchable = len(opts.IssueIDs) > 0
+	}
+	if !matchable {
+		// force an empty result
+		opts.RepoIDs = []int64{-1}
+	}
+	if !opts.IsClosed.IsNone() {
+		isShowClosed = opts.IsClosed.IsTrue()
+	}
+
 	counts, err := models.CountIssuesByRepo(opts)
 	if err != nil {
 		ctx.ServerError("CountIssuesByRepo", err)
@@ -350,6 +401,19 @@ func Issues(ctx *context.Context) {
 		ctx.ServerError("GetUserIssueStats", err)
 		return
 	}
+	if len(keyword) > 0 {
+		statsOpts := *opts
+		statsOpts.IsClosed = util.OptionalBoolFalse
+		if issueStats.OpenCount, err = models.CountIssues(&statsOpts); err != nil {
+			ctx.ServerError("CountIssues", err)
+			return
+		}
+		statsOpts.IsClosed = util.OptionalBoolTrue
+		if issueStats.ClosedCount, err = models.CountIssues(&statsOpts); err != nil {
+			ctx.ServerError("CountIssues", err)
+			return
+		}
+	}
 
 	var total int
 	if !isShowClosed {
@@ -367,6 +431,13 @@ func Issues(ctx *context.Context) {
 	ctx.Data["SortType"] = sortType
 	ctx.Data["RepoID"] = repoID
 	ctx.Data["IsShowClosed"] = isShowClosed
+	ctx.Data["Keyword"] = keyword
+	ctx.Data["SavedFilters"] = savedFilters
+	ctx.Data["SavedFilterID"] = savedFilterID
+	if err = prepareSaveFilterForm(ctx); err != nil {
+		ctx.ServerError("prepareSaveFilterForm", err)
+		return
+	}
 
 	if isShowClosed {
 		ctx.Data["State"] = "closed"
@@ -375,6 +446,8 @@ func Issues(ctx *context.Context) {
 	}
 
 	pager := context.NewPagination(total, setting.UI.IssuePagingNum, page, 5)
+	pager.AddParam(ctx, "q", "Keyword")
+	pager.AddParam(ctx, "filter", "SavedFilterID")
 	pager.AddParam(ctx, "type", "ViewType")
 	pager.AddParam(ctx, "repo", "RepoID")
 	pager.AddParam(ctx, "sort", "SortType")
@@ -387,6 +460,82 @@ func Issues(ctx *context.Context) {
 	ctx.HTML(200, tplIssues)
 }
 
+// prepareSaveFilterForm sets the teams which the signed in user may share a filter with, grouped by organization
+func prepareSaveFilterForm(ctx *context.Context) error {
+	if err := ctx.User.GetOrganizations(true); err != nil {
+		return err
+	}
+	orgTeams := make(map[int64][]*models.Team, len(ctx.User.Orgs))
+	for _, org := range ctx.User.Orgs {
+		teams, err := org.GetUserTeams(ctx.User.ID)
+		if err != nil {
+			return err
+		}
+		orgTeams[org.ID] = teams
+	}
+	ctx.Data["Orgs"] = ctx.User.Orgs
+	ctx.Data["OrgTeams"] = orgTeams
+	return nil
+}
+
+// SaveFilter saves the query of an issue dashboard as a named filter
+func SaveFilter(ctx *context.Context, form auth.SaveFilterForm) {
+	redirectTo := form.RedirectTo
+	if len(redirectTo) == 0 {
+		redirectTo = setting.AppSubURL + "/issues"
+	}
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.Data["ErrorMsg"].(string))
+		ctx.RedirectToFirst(redirectTo)
+		return
+	}
+
+	f := &models.SavedFilter{
+		UserID:   ctx.User.ID,
+		Name:     form.Name,
+		Query:    form.Query,
+		SortType: form.Sort,
+		RepoID:   form.RepoID,
+		TeamID:   form.TeamID,
+	}
+	if err := models.NewSavedFilter(f); err != nil {
+		if models.IsErrSavedFilterInvalid(err) || models.IsErrRepoNotExist(err) || err == models.ErrTeamNotExist {
+			ctx.Flash.Error(ctx.Tr("home.filters.save_failed", err.Error()))
+			ctx.RedirectToFirst(redirectTo)
+			return
+		}
+		ctx.ServerError("NewSavedFilter", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("home.filters.save_success", f.Name))
+	ctx.RedirectToFirst(fmt.Sprintf("%s?filter=%d", redirectTo, f.ID))
+}
+
+// DeleteSavedFilter deletes a saved filter of the signed in user
+func DeleteSavedFilter(ctx *context.Context) {
+	f, err := models.GetSavedFilterByID(ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrSavedFilterNotExist(err) {
+			ctx.NotFound("GetSavedFilterByID", err)
+		} else {
+			ctx.ServerError("GetSavedFilterByID", err)
+		}
+		return
+	}
+	if !f.IsOwnedBy(ctx.User.ID) {
+		ctx.NotFound("IsOwnedBy", nil)
+		return
+	}
+	if err = models.DeleteSavedFilter(f); err != nil {
+		ctx.ServerError("DeleteSavedFilter", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("home.filters.deletion_success", f.Name))
+	ctx.RedirectToFirst(ctx.Query("redirect_to"), setting.AppSubURL+"/issues")
+}
+
 // ShowSSHKeys output all the ssh keys of user by uid
 func ShowSSHKeys(ctx *context.Context, uid int64) {
 	keys, err := models.ListPublicKeys(uid)
@@ -5,10 +5,12 @@
 package user
 
 import (
+	"fmt"
 	"net/http"
 	"testing"
 
 	"github.com/masoodkamyab/gitea/models"
+	"github.com/masoodkamyab/gitea/modules/auth"
 	"github.com/masoodkamyab/gitea/modules/test"
 
 	"github.com/masoodkamyab/gitea/modules/setting"
@@ -31,3 +33,87 @@ func TestIssues(t *testing.T) {
 	assert.Len(t, ctx.Data["Issues"], 1)
 	assert.Len(t, ctx.Data["Repos"], 1)
 }
+
+func TestIssuesWithQuery(t *testing.T) {
+	setting.UI.IssuePagingNum = 1
+	assert.NoError(t, models.LoadFixtures())
+
+	ctx := test.MockContext(t, "issues")
+	test.LoadUser(t, ctx, 2)
+	ctx.SetParams(":type", "issues")
+	ctx.Req.Form.Set("state", "closed")
+	ctx.Req.Form.Set("q", "is:open label:label1")
+	Issues(ctx)
+	assert.EqualValues(t, http.StatusOK, ctx.Resp.Status())
+
+	assert.EqualValues(t, map[int64]int64{1: 1}, ctx.Data["Counts"])
+	assert.EqualValues(t, false, ctx.Data["IsShowClosed"])
+	assert.EqualValues(t, 1, ctx.Data["IssueStats"].(*models.IssueStats).OpenCount)
+	if assert.Len(t, ctx.Data["Issues"], 1) {
+		assert.EqualValues(t, 1, ctx.Data["Issues"].([]*models.Issue)[0].ID)
+	}
+}
+
+func TestIssuesWithSavedFilter(t *testing.T) {
+	setting.UI.IssuePagingNum = 10
+	assert.NoError(t, models.LoadFixtures())
+
+	ctx := test.MockContext(t, "pulls")
+	test.LoadUser(t, ctx, 2)
+	ctx.SetParams(":type", "pulls")
+	ctx.Req.Form.Set("filter", "2")
+	Issues(ctx)
+	assert.EqualValues(t, http.StatusOK, ctx.Resp.Status())
+
+	assert.Len(t, ctx.Data["SavedFilters"], 2)
+	assert.EqualValues(t, 2, ctx.Data["SavedFilterID"])
+	assert.EqualValues(t, "is:pr", ctx.Data["Keyword"])
+	assert.EqualValues(t, 1, ctx.Data["RepoID"])
+	for _, issue := range ctx.Data["Issues"].([]*models.Issue) {
+		assert.EqualValues(t, 1, issue.RepoID)
+	}
+}
+
+func TestSaveFilter(t *testing.T) {
+	assert.NoError(t, models.LoadFixtures())
+
+	ctx := test.MockContext(t, "user/filters")
+	test.LoadUser(t, ctx, 2)
+	SaveFilter(ctx, auth.SaveFilterForm{
+		Name:       "Recently updated",
+		Query:      "is:open",
+		Sort:       "recentupdate",
+		TeamID:     2,
+		RedirectTo: "/issues",
+	})
+	f := models.AssertExistsAndLoadBean(t, &models.SavedFilter{UserID: 2, Name: "Recently updated"}).(*models.SavedFilter)
+	assert.EqualValues(t, "recentupdate", f.SortType)
+	assert.EqualValues(t, 2, f.TeamID)
+	assert.EqualValues(t, fmt.Sprintf("/issues?filter=%d", f.ID), test.RedirectURL(ctx.Resp))
+
+	ctx = test.MockContext(t, "user/filters")
+	test.LoadUser(t, ctx, 5)
+	SaveFilter(ctx, auth.SaveFilterForm{Name: "Not a member", TeamID: 2, RedirectTo: "/issues"})
+	models.AssertNotExistsBean(t, &models.SavedFilter{Name: "Not a member"})
+	assert.NotEmpty(t, ctx.Flash.ErrorMsg)
+}
+
+func TestDeleteSavedFilter(t *testing.T) {
+	assert.NoError(t, models.LoadFixtures())
+
+	// only the owner may delete a shared filter
+	ctx := test.MockContext(t, "user/filters/2/delete")
+	test.LoadUser(t, ctx, 4)
+	test.LoadRepo(t, ctx, 1)
+	ctx.SetParams(":id", "2")
+	DeleteSavedFilter(ctx)
+	assert.EqualValues(t, http.StatusNotFound, ctx.Resp.Status())
+	models.AssertExistsAndLoadBean(t, &models.SavedFilter{ID: 2})
+
+	ctx = test.MockContext(t, "user/filters/2/delete")
+	test.LoadUser(t, ctx, 2)
+	ctx.SetParams(":id", "2")
+	DeleteSavedFilter(ctx)
+	assert.EqualValues(t, http.StatusFound, ctx.Resp.Status())
+	models.AssertNotExistsBean(t, &models.SavedFilter{ID: 2})
+}
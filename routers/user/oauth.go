@@ -106,9 +106,10 @@ type AccessTokenResponse struct {
 	TokenType    TokenType `json:"token_type"`
 	ExpiresIn    int64     `json:"expires_in"`
 	RefreshToken string    `json:"refresh_token"`
+	IDToken      string    `json:"id_token,omitempty"`
 }
 
-func newAccessTokenResponse(grant *models.OAuth2Grant) (*AccessTokenResponse, *AccessTokenError) {
+func newAccessTokenResponse(grant *models.OAuth2Grant, scope models.AccessTokenScope, openIDScope models.OpenIDScope, nonce string) (*AccessTokenResponse, *AccessTokenError) {
 	if setting.OAuth2.InvalidateRefreshTokens {
 		if err := grant.IncreaseCounter(); err != nil {
 			return nil, &AccessTokenError{
@@ -120,8 +121,10 @@ func newAccessTokenResponse(grant *models.OAuth2Grant) (*AccessTokenResponse, *A
 	// generate access token to access the API
 	expirationDate := util.TimeStampNow().Add(setting.OAuth2.AccessTokenExpirationTime)
 	accessToken := &models.OAuth2Token{
-		GrantID: grant.ID,
-		Type:    models.TypeAccessToken,
+		GrantID:     grant.ID,
+		Type:        models.TypeAccessToken,
+		Scope:       scope,
+		OpenIDScope: openIDScope,
 		StandardClaims: jwt.StandardClaims{
 			ExpiresAt: expirationDate.AsTime().Unix(),
 		},
@@ -137,9 +140,11 @@ func newAccessTokenResponse(grant *models.OAuth2Grant) (*AccessTokenResponse, *A
 	// generate refresh token to request an access token after it expired later
 	refreshExpirationDate := util.TimeStampNow().Add(setting.OAuth2.RefreshTokenExpirationTime * 60 * 60).AsTime().Unix()
 	refreshToken := &models.OAuth2Token{
-		GrantID: grant.ID,
-		Counter: grant.Counter,
-		Type:    models.TypeRefreshToken,
+		GrantID:     grant.ID,
+		Counter:     grant.Counter,
+		Type:        models.TypeRefreshToken,
+		Scope:       scope,
+		OpenIDScope: openIDScope,
 		StandardClaims: jwt.StandardClaims{
 			ExpiresAt: refreshExpirationDate,
 		},
@@ -152,11 +157,54 @@ func newAccessTokenResponse(grant *models.OAuth2Grant) (*AccessTokenResponse, *A
 		}
 	}
 
+	// generate the id_token to authenticate the user for OpenID Connect requests
+	var signedIDToken string
+	if openIDScope.Has(models.OpenIDScopeOpenID) {
+		idToken, err := newIDToken(grant, openIDScope, nonce, expirationDate.AsTime().Unix())
+		if err != nil {
+			log.Error("newIDToken: %v", err)
+			return nil, &AccessTokenError{
+				ErrorCode:        AccessTokenErrorCodeInvalidRequest,
+				ErrorDescription: "cannot generate id token",
+			}
+		}
+		if signedIDToken, err = idToken.SignToken(); err != nil {
+			log.Error("SignToken: %v", err)
+			return nil, &AccessTokenError{
+				ErrorCode:        AccessTokenErrorCodeInvalidRequest,
+				ErrorDescription: "cannot sign token",
+			}
+		}
+	}
+
 	return &AccessTokenResponse{
 		AccessToken:  signedAccessToken,
 		TokenType:    TokenTypeBearer,
 		ExpiresIn:    setting.OAuth2.AccessTokenExpirationTime,
 		RefreshToken: signedRefreshToken,
+		IDToken:      signedIDToken,
+	}, nil
+}
+
+func newIDToken(grant *models.OAuth2Grant, openIDScope models.OpenIDScope, nonce string, expiresAt int64) (*models.OIDCToken, error) {
+	app, err := models.GetOAuth2ApplicationByID(grant.ApplicationID)
+	if err != nil {
+		return nil, err
+	}
+	user, err := models.GetUserByID(grant.UserID)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := models.GetOIDCClaims(user, openIDScope)
+	if err != nil {
+		return nil, err
+	}
+	return &models.OIDCToken{
+		Issuer:     models.OAuth2Issuer(),
+		Audience:   app.ClientID,
+		ExpiresAt:  expiresAt,
+		Nonce:      nonce,
+		OIDCClaims: claims,
 	}, nil
 }
 
@@ -211,8 +259,7 @@ func AuthorizeOAuth(ctx *context.Context, form auth.AuthorizationForm) {
 
 	// pkce support
 	switch form.CodeChallengeMethod {
-	case "S256":
-	case "plain":
+	case "S256", "plain":
 		if err := ctx.Session.Set("CodeChallengeMethod", form.CodeChallengeMethod); err != nil {
 			handleAuthorizeError(ctx, AuthorizeError{
 				ErrorCode:        ErrorCodeServerError,
@@ -221,7 +268,7 @@ func AuthorizeOAuth(ctx *context.Context, form auth.AuthorizationForm) {
 			}, form.RedirectURI)
 			return
 		}
-		if err := ctx.Session.Set("CodeChallengeMethod", form.CodeChallenge); err != nil {
+		if err := ctx.Session.Set("CodeChallenge", form.CodeChallenge); err != nil {
 			handleAuthorizeError(ctx, AuthorizeError{
 				ErrorCode:        ErrorCodeServerError,
 				ErrorDescription: "cannot set code challenge",
@@ -230,7 +277,17 @@ func AuthorizeOAuth(ctx *context.Context, form auth.AuthorizationForm) {
 			return
 		}
 	case "":
-		break
+		// public clients cannot keep a secret and authenticate with PKCE
+		if !app.ConfidentialClient {
+			handleAuthorizeError(ctx, AuthorizeError{
+				ErrorCode:        ErrorCodeInvalidRequest,
+				ErrorDescription: "PKCE is required for public clients",
+				State:            form.State,
+			}, form.RedirectURI)
+			return
+		}
+		_ = ctx.Session.Delete("CodeChallengeMethod")
+		_ = ctx.Session.Delete("CodeChallenge")
 	default:
 		handleAuthorizeError(ctx, AuthorizeError{
 			ErrorCode:        ErrorCodeInvalidRequest,
@@ -246,9 +303,12 @@ func AuthorizeOAuth(ctx *context.Context, form auth.AuthorizationForm) {
 		return
 	}
 
-	// Redirect if user already granted access
-	if grant != nil {
-		code, err := grant.GenerateNewAuthorizationCode(form.RedirectURI, form.CodeChallenge, form.CodeChallengeMethod)
+	// Redirect if user already granted access to the requested scope, the code
+	// is still limited to the requested scope
+	scope := models.ParseOAuth2Scope(form.Scope)
+	openIDScope := models.ParseOpenIDScope(form.Scope)
+	if grant != nil && grant.Scope.Contains(scope) && grant.OpenIDScope.Contains(openIDScope) {
+		code, err := grant.GenerateNewAuthorizationCode(form.RedirectURI, form.CodeChallenge, form.CodeChallengeMethod, form.Nonce, scope, openIDScope)
 		if err != nil {
 			handleServerError(ctx, form.State, form.RedirectURI)
 			return
@@ -266,6 +326,8 @@ func AuthorizeOAuth(ctx *context.Context, form auth.AuthorizationForm) {
 	ctx.Data["Application"] = app
 	ctx.Data["RedirectURI"] = form.RedirectURI
 	ctx.Data["State"] = form.State
+	ctx.Data["Scopes"] = scope.List()
+	ctx.Data["OpenIDScopes"] = openIDScope.List()
 	ctx.Data["ApplicationUserLink"] = "<a href=\"" + setting.AppURL + app.User.LowerName + "\">@" + app.User.Name + "</a>"
 	ctx.Data["ApplicationRedirectDomainHTML"] = "<strong>" + form.RedirectURI + "</strong>"
 	// TODO document SESSION <=> FORM
@@ -287,6 +349,24 @@ func AuthorizeOAuth(ctx *context.Context, form auth.AuthorizationForm) {
 		log.Error(err.Error())
 		return
 	}
+	err = ctx.Session.Set("scope", string(scope))
+	if err != nil {
+		handleServerError(ctx, form.State, form.RedirectURI)
+		log.Error(err.Error())
+		return
+	}
+	err = ctx.Session.Set("openid_scope", string(openIDScope))
+	if err != nil {
+		handleServerError(ctx, form.State, form.RedirectURI)
+		log.Error(err.Error())
+		return
+	}
+	err = ctx.Session.Set("nonce", form.Nonce)
+	if err != nil {
+		handleServerError(ctx, form.State, form.RedirectURI)
+		log.Error(err.Error())
+		return
+	}
 	ctx.HTML(200, tplGrantAccess)
 }
 
@@ -302,7 +382,19 @@ func GrantApplicationOAuth(ctx *context.Context, form auth.GrantApplicationForm)
 		ctx.ServerError("GetOAuth2ApplicationByClientID", err)
 		return
 	}
-	grant, err := app.CreateGrant(ctx.User.ID)
+	scope, _ := ctx.Session.Get("scope").(string)
+	openIDScope, _ := ctx.Session.Get("openid_scope").(string)
+	grant, err := app.GetGrantByUserID(ctx.User.ID)
+	if err != nil {
+		handleServerError(ctx, form.State, form.RedirectURI)
+		return
+	}
+	if grant == nil {
+		grant, err = app.CreateGrant(ctx.User.ID, models.AccessTokenScope(scope), models.OpenIDScope(openIDScope))
+	} else {
+		// the user granted access to a different scope
+		err = grant.UpdateScope(models.AccessTokenScope(scope), models.OpenIDScope(openIDScope))
+	}
 	if err != nil {
 		handleAuthorizeError(ctx, AuthorizeError{
 			State:            form.State,
@@ -312,11 +404,13 @@ func GrantApplicationOAuth(ctx *context.Context, form auth.GrantApplicationForm)
 		return
 	}
 
-	var codeChallenge, codeChallengeMethod string
+	var codeChallenge, codeChallengeMethod, nonce string
 	codeChallenge, _ = ctx.Session.Get("CodeChallenge").(string)
 	codeChallengeMethod, _ = ctx.Session.Get("CodeChallengeMethod").(string)
+	nonce, _ = ctx.Session.Get("nonce").(string)
 
-	code, err := grant.GenerateNewAuthorizationCode(form.RedirectURI, codeChallenge, codeChallengeMethod)
+	code, err := grant.GenerateNewAuthorizationCode(form.RedirectURI, codeChallenge, codeChallengeMethod, nonce,
+		models.AccessTokenScope(scope), models.OpenIDScope(openIDScope))
 	if err != nil {
 		handleServerError(ctx, form.State, form.RedirectURI)
 		return
@@ -398,7 +492,8 @@ func handleRefreshToken(ctx *context.Context, form auth.AccessTokenForm) {
 		log.Warn("A client tried to use a refresh token for grant_id = %d was used twice!", grant.ID)
 		return
 	}
-	accessToken, tokenErr := newAccessTokenResponse(grant)
+	scope, openIDScope := token.Scopes(grant)
+	accessToken, tokenErr := newAccessTokenResponse(grant, scope, openIDScope, "")
 	if tokenErr != nil {
 		handleAccessTokenError(ctx, *tokenErr)
 		return
@@ -415,7 +510,8 @@ func handleAuthorizationCode(ctx *context.Context, form auth.AccessTokenForm) {
 		})
 		return
 	}
-	if !app.ValidateClientSecret([]byte(form.ClientSecret)) {
+	// public clients have no secret, they are authorized by the PKCE code verifier
+	if app.ConfidentialClient && !app.ValidateClientSecret([]byte(form.ClientSecret)) {
 		handleAccessTokenError(ctx, AccessTokenError{
 			ErrorCode:        AccessTokenErrorCodeUnauthorizedClient,
 			ErrorDescription: "client is not authorized",
@@ -438,7 +534,8 @@ func handleAuthorizationCode(ctx *context.Context, form auth.AccessTokenForm) {
 		return
 	}
 	// check if code verifier authorizes the client, PKCE support
-	if !authorizationCode.ValidateCodeChallenge(form.CodeVerifier) {
+	if (!app.ConfidentialClient && authorizationCode.CodeChallengeMethod == "") ||
+		!authorizationCode.ValidateCodeChallenge(form.CodeVerifier) {
 		handleAccessTokenError(ctx, AccessTokenError{
 			ErrorCode:        AccessTokenErrorCodeUnauthorizedClient,
 			ErrorDescription: "client is not authorized",
@@ -460,7 +557,8 @@ func handleAuthorizationCode(ctx *context.Context, form auth.AccessTokenForm) {
 			ErrorDescription: "cannot proceed your request",
 		})
 	}
-	resp, tokenErr := newAccessTokenResponse(authorizationCode.Grant)
+	scope, openIDScope := authorizationCode.Scopes()
+	resp, tokenErr := newAccessTokenResponse(authorizationCode.Grant, scope, openIDScope, authorizationCode.Nonce)
 	if tokenErr != nil {
 		handleAccessTokenError(ctx, *tokenErr)
 		return
@@ -469,6 +567,86 @@ func handleAuthorizationCode(ctx *context.Context, form auth.AccessTokenForm) {
 	ctx.JSON(200, resp)
 }
 
+// OIDCWellKnown describes the OpenID Connect provider, see https://openid.net/specs/openid-connect-discovery-1_0.html
+func OIDCWellKnown(ctx *context.Context) {
+	scopes := make([]string, 0, len(models.OpenIDScopes)+len(models.AccessTokenScopes))
+	for _, scope := range models.OpenIDScopes {
+		scopes = append(scopes, string(scope))
+	}
+	for _, scope := range models.AccessTokenScopes {
+		scopes = append(scopes, string(scope))
+	}
+	ctx.JSON(200, map[string]interface{}{
+		"issuer":                                models.OAuth2Issuer(),
+		"authorization_endpoint":                setting.AppURL + "login/oauth/authorize",
+		"token_endpoint":                        setting.AppURL + "login/oauth/access_token",
+		"userinfo_endpoint":                     setting.AppURL + "login/oauth/userinfo",
+		"jwks_uri":                              setting.AppURL + "login/oauth/keys",
+		"scopes_supported":                      scopes,
+		"response_types_supported":              []string{"code"},
+		"grant_types_supported":                 []string{"authorization_code", "refresh_token"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"token_endpoint_auth_methods_supported": []string{"client_secret_basic", "client_secret_post", "none"},
+		"code_challenge_methods_supported":      []string{"plain", "S256"},
+		"claims_supported": []string{
+			"aud", "exp", "iat", "iss", "nonce", "sub",
+			"name", "preferred_username", "profile", "picture", "website", "updated_at",
+			"email", "email_verified", "groups",
+		},
+	})
+}
+
+// OIDCKeys returns the public keys verifying the id_tokens as JSON Web Key Set
+func OIDCKeys(ctx *context.Context) {
+	ctx.JSON(200, models.GetOAuth2SigningKeySet())
+}
+
+// InfoOAuth returns the claims about the user of an OpenID Connect access token
+func InfoOAuth(ctx *context.Context) {
+	authContent := strings.SplitN(ctx.Req.Header.Get("Authorization"), " ", 2)
+	if len(authContent) != 2 || !strings.EqualFold(authContent[0], "Bearer") {
+		handleUserInfoError(ctx, 401, "invalid_token")
+		return
+	}
+	token, err := models.ParseOAuth2Token(authContent[1])
+	if err != nil || token.Type != models.TypeAccessToken {
+		handleUserInfoError(ctx, 401, "invalid_token")
+		return
+	}
+	grant, err := models.GetOAuth2GrantByID(token.GrantID)
+	if err != nil || grant == nil {
+		handleUserInfoError(ctx, 401, "invalid_token")
+		return
+	}
+	_, openIDScope := token.Scopes(grant)
+	if !openIDScope.Has(models.OpenIDScopeOpenID) {
+		handleUserInfoError(ctx, 403, "insufficient_scope")
+		return
+	}
+	user, err := models.GetUserByID(grant.UserID)
+	if err != nil {
+		if models.IsErrUserNotExist(err) {
+			handleUserInfoError(ctx, 401, "invalid_token")
+			return
+		}
+		ctx.ServerError("GetUserByID", err)
+		return
+	}
+	claims, err := models.GetOIDCClaims(user, openIDScope)
+	if err != nil {
+		ctx.ServerError("GetOIDCClaims", err)
+		return
+	}
+	ctx.JSON(200, claims)
+}
+
+// handleUserInfoError responds with a bearer token error as specified in RFC 6750
+func handleUserInfoError(ctx *context.Context, status int, errorCode string) {
+	ctx.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer error="%s"`, errorCode))
+	ctx.JSON(status, map[string]string{"error": errorCode})
+}
+
 func handleAccessTokenError(ctx *context.Context, acErr AccessTokenError) {
 	ctx.JSON(400, acErr)
 }
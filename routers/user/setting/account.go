@@ -58,6 +58,10 @@ func AccountPost(ctx *context.Context, form auth.ChangePasswordForm) {
 			ctx.ServerError("UpdateUser", err)
 			return
 		}
+		if err := models.DeleteUserSessionsByUID(ctx.User.ID, ctx.Session.ID()); err != nil {
+			ctx.ServerError("DeleteUserSessionsByUID", err)
+			return
+		}
 		log.Trace("User password updated: %s", ctx.User.Name)
 		ctx.Flash.Success(ctx.Tr("settings.change_password_success"))
 	}
@@ -6,11 +6,15 @@
 package setting
 
 import (
+	"strings"
+	"time"
+
 	"github.com/masoodkamyab/gitea/models"
 	"github.com/masoodkamyab/gitea/modules/auth"
 	"github.com/masoodkamyab/gitea/modules/base"
 	"github.com/masoodkamyab/gitea/modules/context"
 	"github.com/masoodkamyab/gitea/modules/setting"
+	"github.com/masoodkamyab/gitea/modules/util"
 )
 
 const (
@@ -39,9 +43,29 @@ func ApplicationsPost(ctx *context.Context, form auth.NewAccessTokenForm) {
 		return
 	}
 
+	scope, err := models.ParseAccessTokenScope(strings.Join(form.Scope, ","))
+	if err != nil || len(scope) == 0 {
+		loadApplicationsData(ctx)
+		ctx.Data["Err_Scope"] = true
+		ctx.RenderWithErr(ctx.Tr("settings.token_scope_required"), tplSettingsApplications, &form)
+		return
+	}
+
 	t := &models.AccessToken{
-		UID:  ctx.User.ID,
-		Name: form.Name,
+		UID:   ctx.User.ID,
+		Name:  form.Name,
+		Scope: scope,
+	}
+	if len(form.Expires) > 0 {
+		// the token can be used until the end of the chosen day
+		expires, err := time.ParseInLocation("2006-01-02", form.Expires, setting.UILocation)
+		if err != nil || !expires.AddDate(0, 0, 1).After(time.Now()) {
+			loadApplicationsData(ctx)
+			ctx.Data["Err_Expires"] = true
+			ctx.RenderWithErr(ctx.Tr("settings.token_expires_invalid"), tplSettingsApplications, &form)
+			return
+		}
+		t.ExpiresUnix = util.TimeStamp(expires.AddDate(0, 0, 1).Unix())
 	}
 	if err := models.NewAccessToken(t); err != nil {
 		ctx.ServerError("NewAccessToken", err)
@@ -74,6 +98,7 @@ func loadApplicationsData(ctx *context.Context) {
 		return
 	}
 	ctx.Data["Tokens"] = tokens
+	ctx.Data["TokenScopes"] = models.AccessTokenScopes
 	ctx.Data["EnableOAuth2"] = setting.OAuth2.Enable
 	if setting.OAuth2.Enable {
 		ctx.Data["Applications"], err = models.GetOAuth2ApplicationsByUserID(ctx.User.ID)
@@ -32,9 +32,10 @@ func OAuthApplicationsPost(ctx *context.Context, form auth.EditOAuth2Application
 	}
 	// TODO validate redirect URI
 	app, err := models.CreateOAuth2Application(models.CreateOAuth2ApplicationOptions{
-		Name:         form.Name,
-		RedirectURIs: []string{form.RedirectURI},
-		UserID:       ctx.User.ID,
+		Name:               form.Name,
+		RedirectURIs:       []string{form.RedirectURI},
+		UserID:             ctx.User.ID,
+		ConfidentialClient: form.ConfidentialClient,
 	})
 	if err != nil {
 		ctx.ServerError("CreateOAuth2Application", err)
@@ -63,10 +64,11 @@ func OAuthApplicationsEdit(ctx *context.Context, form auth.EditOAuth2Application
 	}
 	// TODO validate redirect URI
 	if err := models.UpdateOAuth2Application(models.UpdateOAuth2ApplicationOptions{
-		ID:           ctx.ParamsInt64("id"),
-		Name:         form.Name,
-		RedirectURIs: []string{form.RedirectURI},
-		UserID:       ctx.User.ID,
+		ID:                 ctx.ParamsInt64("id"),
+		Name:               form.Name,
+		RedirectURIs:       []string{form.RedirectURI},
+		UserID:             ctx.User.ID,
+		ConfidentialClient: form.ConfidentialClient,
 	}); err != nil {
 		ctx.ServerError("UpdateOAuth2Application", err)
 		return
@@ -52,7 +52,7 @@ func DeleteAccountLink(ctx *context.Context) {
 
 func loadSecurityData(ctx *context.Context) {
 	enrolled := true
-	_, err := models.GetTwoFactorByUID(ctx.User.ID)
+	twofa, err := models.GetTwoFactorByUID(ctx.User.ID)
 	if err != nil {
 		if models.IsErrTwoFactorNotEnrolled(err) {
 			enrolled = false
@@ -62,13 +62,18 @@ func loadSecurityData(ctx *context.Context) {
 		}
 	}
 	ctx.Data["TwofaEnrolled"] = enrolled
+	ctx.Data["TwofaRequired"] = setting.TwoFactorRequired
 	if enrolled {
-		ctx.Data["U2FRegistrations"], err = models.GetU2FRegistrationsByUID(ctx.User.ID)
+		ctx.Data["ScratchCodesLeft"], err = twofa.CountScratchCodes()
 		if err != nil {
-			ctx.ServerError("GetU2FRegistrationsByUID", err)
+			ctx.ServerError("CountScratchCodes", err)
+			return
+		}
+		ctx.Data["WebAuthnCredentials"], err = models.GetWebAuthnCredentialsByUID(ctx.User.ID)
+		if err != nil {
+			ctx.ServerError("GetWebAuthnCredentialsByUID", err)
 			return
 		}
-		ctx.Data["RequireU2F"] = true
 	}
 
 	tokens, err := models.ListAccessTokens(ctx.User.ID)
@@ -106,4 +111,6 @@ func loadSecurityData(ctx *context.Context) {
 		return
 	}
 	ctx.Data["OpenIDs"] = openid
+
+	loadSessionsData(ctx)
 }
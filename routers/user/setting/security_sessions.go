@@ -0,0 +1,68 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"github.com/masoodkamyab/gitea/models"
+	"github.com/masoodkamyab/gitea/modules/context"
+	"github.com/masoodkamyab/gitea/modules/log"
+	"github.com/masoodkamyab/gitea/modules/setting"
+)
+
+func loadSessionsData(ctx *context.Context) {
+	sessions, err := models.GetUserSessionsByUID(ctx.User.ID)
+	if err != nil {
+		ctx.ServerError("GetUserSessionsByUID", err)
+		return
+	}
+	ctx.Data["CurrentUserSessionID"] = int64(0)
+	for _, s := range sessions {
+		if s.IsSessionID(ctx.Session.ID()) {
+			ctx.Data["CurrentUserSessionID"] = s.ID
+		}
+	}
+	ctx.Data["UserSessions"] = sessions
+}
+
+// DeleteSession signs the user out of one of their other sessions
+func DeleteSession(ctx *context.Context) {
+	s, err := models.GetUserSessionByID(ctx.QueryInt64("id"))
+	if err != nil {
+		if models.IsErrUserSessionNotExist(err) {
+			ctx.Status(200)
+			return
+		}
+		ctx.ServerError("GetUserSessionByID", err)
+		return
+	}
+	if s.UID != ctx.User.ID {
+		ctx.Status(401)
+		return
+	}
+	if err = models.DeleteUserSession(s); err != nil {
+		ctx.ServerError("DeleteUserSession", err)
+		return
+	}
+	log.Trace("User %s revoked session %d", ctx.User.Name, s.ID)
+
+	ctx.Flash.Success(ctx.Tr("settings.revoke_session_success"))
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": setting.AppSubURL + "/user/settings/security",
+	})
+}
+
+// DeleteOtherSessions signs the user out of all sessions except the current one
+func DeleteOtherSessions(ctx *context.Context) {
+	if err := models.DeleteUserSessionsByUID(ctx.User.ID, ctx.Session.ID()); err != nil {
+		ctx.ServerError("DeleteUserSessionsByUID", err)
+		return
+	}
+	log.Trace("User %s revoked all other sessions", ctx.User.Name)
+
+	ctx.Flash.Success(ctx.Tr("settings.revoke_other_sessions_success"))
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": setting.AppSubURL + "/user/settings/security",
+	})
+}
@@ -21,7 +21,7 @@ import (
 	"github.com/pquerna/otp/totp"
 )
 
-// RegenerateScratchTwoFactor regenerates the user's 2FA scratch code.
+// RegenerateScratchTwoFactor regenerates the user's 2FA scratch codes.
 func RegenerateScratchTwoFactor(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("settings")
 	ctx.Data["PageIsSettingsSecurity"] = true
@@ -32,7 +32,7 @@ func RegenerateScratchTwoFactor(ctx *context.Context) {
 		return
 	}
 
-	token, err := t.GenerateScratchToken()
+	codes, err := t.GenerateScratchCodes()
 	if err != nil {
 		ctx.ServerError("SettingsTwoFactor", err)
 		return
@@ -43,7 +43,7 @@ func RegenerateScratchTwoFactor(ctx *context.Context) {
 		return
 	}
 
-	ctx.Flash.Success(ctx.Tr("settings.twofa_scratch_token_regenerated", token))
+	ctx.Flash.Success(ctx.Tr("settings.twofa_scratch_token_regenerated", strings.Join(codes, " ")))
 	ctx.Redirect(setting.AppSubURL + "/user/settings/security")
 }
 
@@ -52,6 +52,12 @@ func DisableTwoFactor(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("settings")
 	ctx.Data["PageIsSettingsSecurity"] = true
 
+	if setting.TwoFactorRequired {
+		ctx.Flash.Error(ctx.Tr("settings.twofa_required_cannot_disable"))
+		ctx.Redirect(setting.AppSubURL + "/user/settings/security")
+		return
+	}
+
 	t, err := models.GetTwoFactorByUID(ctx.User.ID)
 	if err != nil {
 		ctx.ServerError("SettingsTwoFactor", err)
@@ -185,7 +191,7 @@ func EnrollTwoFactorPost(ctx *context.Context, form auth.TwoFactorAuthForm) {
 		ctx.ServerError("SettingsTwoFactor", err)
 		return
 	}
-	token, err := t.GenerateScratchToken()
+	codes, err := t.GenerateScratchCodes()
 	if err != nil {
 		ctx.ServerError("SettingsTwoFactor", err)
 		return
@@ -206,6 +212,6 @@ func EnrollTwoFactorPost(ctx *context.Context, form auth.TwoFactorAuthForm) {
 		ctx.ServerError("SettingsTwoFactor", err)
 		return
 	}
-	ctx.Flash.Success(ctx.Tr("settings.twofa_enrolled", token))
+	ctx.Flash.Success(ctx.Tr("settings.twofa_enrolled", strings.Join(codes, " ")))
 	ctx.Redirect(setting.AppSubURL + "/user/settings/security")
 }
@@ -0,0 +1,108 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/masoodkamyab/gitea/models"
+	"github.com/masoodkamyab/gitea/modules/auth"
+	"github.com/masoodkamyab/gitea/modules/context"
+	"github.com/masoodkamyab/gitea/modules/log"
+	"github.com/masoodkamyab/gitea/modules/setting"
+	"github.com/masoodkamyab/gitea/modules/webauthn"
+)
+
+// WebAuthnRegister initializes the WebAuthn registration procedure
+func WebAuthnRegister(ctx *context.Context, form auth.WebAuthnRegistrationForm) {
+	if form.Name == "" {
+		ctx.Error(409)
+		return
+	}
+	creds, err := models.GetWebAuthnCredentialsByUID(ctx.User.ID)
+	if err != nil {
+		ctx.ServerError("GetWebAuthnCredentialsByUID", err)
+		return
+	}
+	for _, cred := range creds {
+		if cred.LowerName == strings.ToLower(form.Name) {
+			ctx.Error(409, "Name already taken")
+			return
+		}
+	}
+
+	user := webauthn.UserEntity{
+		ID:          []byte(strconv.FormatInt(ctx.User.ID, 10)),
+		Name:        ctx.User.Name,
+		DisplayName: ctx.User.DisplayName(),
+	}
+	options, session, err := models.WebAuthnConfig().BeginRegistration(user, creds.CredentialIDs())
+	if err != nil {
+		ctx.ServerError("BeginRegistration", err)
+		return
+	}
+	if err = ctx.Session.Set("webauthnSession", session); err != nil {
+		ctx.ServerError("Session.Set", err)
+		return
+	}
+	if err = ctx.Session.Set("webauthnName", form.Name); err != nil {
+		ctx.ServerError("Session.Set", err)
+		return
+	}
+	ctx.JSON(200, options)
+}
+
+// WebAuthnRegisterPost receives the new credential of the authenticator
+func WebAuthnRegisterPost(ctx *context.Context, resp webauthn.CredentialCreationResponse) {
+	sessData := ctx.Session.Get("webauthnSession")
+	nameSess := ctx.Session.Get("webauthnName")
+	if sessData == nil || nameSess == nil {
+		ctx.ServerError("WebAuthnRegisterPost", errors.New("not in WebAuthn session"))
+		return
+	}
+	session := sessData.(*webauthn.SessionData)
+	name := nameSess.(string)
+
+	cred, err := models.WebAuthnConfig().FinishRegistration(session, &resp)
+	if err != nil {
+		log.Warn("WebAuthn registration for user %d failed: %v", ctx.User.ID, err)
+		ctx.Error(400)
+		return
+	}
+	_ = ctx.Session.Delete("webauthnSession")
+	_ = ctx.Session.Delete("webauthnName")
+
+	if _, err = models.CreateCredential(ctx.User.ID, name, cred); err != nil {
+		ctx.ServerError("CreateCredential", err)
+		return
+	}
+	ctx.Status(200)
+}
+
+// WebAuthnDelete deletes a WebAuthn credential by id
+func WebAuthnDelete(ctx *context.Context, form auth.WebAuthnDeleteForm) {
+	cred, err := models.GetWebAuthnCredentialByID(form.ID)
+	if err != nil {
+		if models.IsErrWebAuthnCredentialNotExist(err) {
+			ctx.Status(200)
+			return
+		}
+		ctx.ServerError("GetWebAuthnCredentialByID", err)
+		return
+	}
+	if cred.UserID != ctx.User.ID {
+		ctx.Status(401)
+		return
+	}
+	if err := models.DeleteCredential(cred); err != nil {
+		ctx.ServerError("DeleteCredential", err)
+		return
+	}
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": setting.AppSubURL + "/user/settings/security",
+	})
+}
@@ -0,0 +1,45 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"github.com/masoodkamyab/gitea/models"
+	"github.com/masoodkamyab/gitea/modules/base"
+	"github.com/masoodkamyab/gitea/modules/context"
+	"github.com/masoodkamyab/gitea/modules/setting"
+	"github.com/masoodkamyab/gitea/routers/repo"
+)
+
+const (
+	tplTrackedTimes base.TplName = "user/dashboard/times"
+)
+
+// TrackedTimes renders the time tracking report of the signed in user or of an organization
+func TrackedTimes(ctx *context.Context) {
+	if !setting.Service.EnableTimetracking {
+		ctx.NotFound("TrackedTimes", nil)
+		return
+	}
+	ctxUser := getDashboardContextUser(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["Title"] = ctx.Tr("repo.times")
+	ctx.Data["PageIsTrackedTimes"] = true
+
+	var opts models.FindTrackedTimesOptions
+	if ctxUser.IsOrganization() {
+		repoIDs, err := models.GetTimetrackerRepoIDs(ctxUser, ctx.User)
+		if err != nil {
+			ctx.ServerError("GetTimetrackerRepoIDs", err)
+			return
+		}
+		opts.RepositoryIDs = repoIDs
+	} else {
+		ctx.Data["IsOwnTrackedTimes"] = true
+		opts.UserID = ctx.User.ID
+	}
+	repo.TrackedTimesReport(ctx, opts, tplTrackedTimes)
+}